@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bastio-ai/bast/internal/ai"
+	"github.com/bastio-ai/bast/internal/files"
+	"github.com/bastio-ai/bast/internal/safety"
+	"github.com/bastio-ai/bast/internal/shell"
+	"github.com/bastio-ai/bast/internal/stdin"
+	"github.com/bastio-ai/bast/internal/units"
+)
+
+var askCmd = &cobra.Command{
+	Use:   "ask <question>",
+	Short: "Ask a question, optionally about piped input",
+	Long: `Ask a one-off question and print the answer.
+
+Piped input is attached as reference content, so answers can draw on it
+and cite its lines. It is summarized when large and credentials in it are
+redacted before it is sent.
+
+Examples:
+  bast ask "what does HTTP 409 mean?"
+  git diff | bast ask "is this safe to merge?"
+  cat config.yaml | bast ask "which of these settings affect caching?"
+  bast ask "how is the config loaded in @main.go?"`,
+	RunE: runAsk,
+}
+
+func init() {
+	rootCmd.AddCommand(askCmd)
+	addQuietFlag(askCmd)
+}
+
+// stdinSource is the path piped input is attached under
+const stdinSource = "stdin"
+
+func runAsk(cmd *cobra.Command, args []string) error {
+	question := strings.TrimSpace(strings.Join(args, " "))
+	if question == "" {
+		return withExitCode(ExitUsage, fmt.Errorf("no question given, e.g. bast ask \"what does this do?\""))
+	}
+
+	provider, err := newExplainProvider()
+	if err != nil {
+		return err
+	}
+
+	shellCtx := shell.GetContext()
+	shellCtx, question = withReferencedFiles(shellCtx, question)
+
+	// Chat reads attached content from the chat context
+	chatCtx := ai.ChatContext{Files: shellCtx.Files}
+	shellCtx.Files = nil
+
+	if stdin.IsPiped() {
+		input, err := readPipedInput()
+		if err != nil {
+			return err
+		}
+		if input != "" {
+			chatCtx.Files = append([]files.FileContent{pipedReference(input)}, chatCtx.Files...)
+		}
+	}
+
+	ctx := context.Background()
+	result, err := provider.Chat(ctx, question, shellCtx, chatCtx)
+	if err != nil {
+		return withExitCode(ExitProvider, fmt.Errorf("failed to answer: %w", err))
+	}
+
+	printResult(result.Response)
+	return nil
+}
+
+// pipedReference prepares piped input for the prompt: summarized to the
+// input budget, with credentials redacted
+func pipedReference(input string) files.FileContent {
+	total := len(input)
+	input = stdin.Summarize(input, stdin.MaxInputSize)
+	if len(input) < total {
+		status("Input is %s; sending a %s summary.\n", units.Bytes(int64(total)), units.Bytes(int64(len(input))))
+	}
+
+	input, redacted := safety.RedactSecrets(input)
+	if len(redacted) > 0 {
+		status("Redacted from the input: %s\n", strings.Join(redacted, ", "))
+	}
+	return files.FileContent{Path: stdinSource, Content: input}
+}
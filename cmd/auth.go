@@ -3,6 +3,7 @@ package cmd
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -10,6 +11,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/bastio-ai/bast/internal/auth"
+	"github.com/bastio-ai/bast/internal/config"
 )
 
 var authCmd = &cobra.Command{
@@ -32,13 +34,28 @@ var logoutCmd = &cobra.Command{
 	RunE:  runLogout,
 }
 
+var repairCmd = &cobra.Command{
+	Use:   "repair",
+	Short: "Fix a damaged credentials file",
+	Long: `Check the credentials file, explain what is wrong with it, and log in
+again to replace it. The device ID recorded in the damaged file, or in its
+backup, is kept so Bastio still sees the same device.`,
+	RunE: runRepair,
+}
+
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show authentication status",
-	Long:  `Display current Bastio authentication status and proxy information.`,
-	RunE:  runStatus,
+	Long: `Display current Bastio authentication status and proxy information.
+
+Also probes the API, the gateway, and the security endpoint and reports each
+one's latency, version, and policy bundle age, so a rejected key can be told
+apart from an unreachable service. Use --offline to skip the probes.`,
+	RunE: runStatus,
 }
 
+var statusOfflineFlag bool
+
 // Aliases at root level
 var loginAliasCmd = &cobra.Command{
 	Use:    "login",
@@ -62,6 +79,9 @@ func init() {
 	authCmd.AddCommand(loginCmd)
 	authCmd.AddCommand(logoutCmd)
 	authCmd.AddCommand(statusCmd)
+	authCmd.AddCommand(repairCmd)
+
+	statusCmd.Flags().BoolVar(&statusOfflineFlag, "offline", false, "Don't probe the Bastio endpoints")
 
 	// Add aliases to root
 	rootCmd.AddCommand(loginAliasCmd)
@@ -69,11 +89,15 @@ func init() {
 }
 
 func runLogin(cmd *cobra.Command, args []string) error {
+	return login(auth.NewAuthenticator())
+}
+
+// login runs the device flow, saves the credentials, and offers to store an
+// Anthropic API key with Bastio
+func login(authenticator *auth.Authenticator) error {
 	ctx, cancel := context.WithTimeout(context.Background(), auth.DefaultDeviceFlowTimeout)
 	defer cancel()
 
-	authenticator := auth.NewAuthenticator()
-
 	fmt.Println("Logging in to Bastio...")
 	fmt.Println()
 
@@ -171,6 +195,46 @@ func runLogout(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runRepair(cmd *cobra.Command, args []string) error {
+	credPath, err := auth.CredentialsPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(credPath)
+	switch {
+	case os.IsNotExist(err):
+		fmt.Println("No credentials file found.")
+	case err != nil:
+		return fmt.Errorf("failed to read credentials: %w", err)
+	default:
+		if verr := auth.ValidateCredentials(credPath, data); verr != nil {
+			var newer *config.NewerSchemaError
+			if errors.As(verr, &newer) {
+				return withExitCode(ExitConfig, verr)
+			}
+			var credErr *auth.CredentialsError
+			if errors.As(verr, &credErr) {
+				fmt.Printf("Problem: %s\n", credErr.Problem)
+			}
+		} else {
+			fmt.Println("The credentials file is valid.")
+			if !confirm("Log in again anyway? [y/N]: ") {
+				return nil
+			}
+		}
+	}
+
+	authenticator := auth.NewAuthenticator()
+	if deviceID := auth.DeviceID(); deviceID != "" {
+		fmt.Printf("Keeping device ID: %s\n", deviceID)
+		authenticator.KeepDeviceID(deviceID)
+	}
+	fmt.Println()
+
+	return login(authenticator)
+}
+
 func runStatus(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 	authenticator := auth.NewAuthenticator()
@@ -184,10 +248,24 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	fmt.Println("────────────────────────────")
 	fmt.Println()
 
+	if status.CredentialsErr != nil {
+		var newer *config.NewerSchemaError
+		if errors.As(status.CredentialsErr, &newer) {
+			fmt.Println("Status: Credentials file written by a newer bast")
+		} else {
+			fmt.Println("Status: Credentials file damaged")
+		}
+		fmt.Println()
+		fmt.Println(status.CredentialsErr)
+		printHealth(ctx, authenticator)
+		return nil
+	}
+
 	if !status.LoggedIn {
 		fmt.Println("Status: Not logged in")
 		fmt.Println()
 		fmt.Println("Run 'bast auth login' or 'bast init' to get started.")
+		printHealth(ctx, authenticator)
 		return nil
 	}
 
@@ -209,5 +287,22 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	fmt.Println()
 	fmt.Println("Dashboard: https://bastio.com/dashboard/cli")
 
+	printHealth(ctx, authenticator)
 	return nil
 }
+
+// printHealth probes the Bastio endpoints and prints one line per endpoint,
+// unless --offline was given
+func printHealth(ctx context.Context, authenticator *auth.Authenticator) {
+	if statusOfflineFlag {
+		return
+	}
+	creds, _ := auth.LoadCredentials()
+
+	fmt.Println()
+	fmt.Println("Endpoint Health")
+	fmt.Println("───────────────")
+	for _, h := range authenticator.CheckHealth(ctx, creds) {
+		fmt.Printf("%-9s %s\n", h.Name+":", h.Summary())
+	}
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bastio-ai/bast/internal/ai"
+	"github.com/bastio-ai/bast/internal/git"
+	"github.com/bastio-ai/bast/internal/shell"
+)
+
+var changelogCmd = &cobra.Command{
+	Use:   "changelog",
+	Short: "Write release notes from git history",
+	Long: `Write release notes from the commits since a tag.
+
+Commits are grouped into Breaking Changes, Features, Fixes, and Other and
+printed as a Markdown section. With --update, the section is inserted at
+the top of a changelog file after you confirm the preview.
+
+Examples:
+  bast changelog                          # Since the latest tag
+  bast changelog --since v1.2.0
+  bast changelog --version v1.3.0 --update CHANGELOG.md`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: runChangelog,
+}
+
+var (
+	changelogSinceFlag   string
+	changelogVersionFlag string
+	changelogUpdateFlag  string
+	changelogYesFlag     bool
+)
+
+func init() {
+	rootCmd.AddCommand(changelogCmd)
+	addQuietFlag(changelogCmd)
+	changelogCmd.Flags().StringVar(&changelogSinceFlag, "since", "", "Tag or commit to start after (default: the latest tag)")
+	changelogCmd.Flags().StringVar(&changelogVersionFlag, "version", "Unreleased", "Heading for the new section")
+	changelogCmd.Flags().StringVar(&changelogUpdateFlag, "update", "", "Insert the section at the top of this changelog file")
+	changelogCmd.Flags().BoolVarP(&changelogYesFlag, "yes", "y", false, "Update the file without asking")
+}
+
+func runChangelog(cmd *cobra.Command, args []string) error {
+	shellCtx := shell.GetContext()
+
+	since := changelogSinceFlag
+	if since == "" {
+		since = git.LatestTag(shellCtx.CWD)
+	}
+	commits, err := git.CommitsSince(shellCtx.CWD, since)
+	if err != nil {
+		return withExitCode(ExitUsage, err)
+	}
+	if len(commits) == 0 {
+		status("No commits since %s.\n", since)
+		return nil
+	}
+
+	provider, err := newExplainProvider()
+	if err != nil {
+		return err
+	}
+
+	if since == "" {
+		status("Summarizing %d commits (no tags yet)...\n\n", len(commits))
+	} else {
+		status("Summarizing %d commits since %s...\n\n", len(commits), since)
+	}
+	notes, err := provider.GenerateChangelog(context.Background(), changelogCommits(commits))
+	if err != nil {
+		return withExitCode(ExitProvider, err)
+	}
+
+	section := changelogSection(changelogVersionFlag, time.Now(), notes)
+	fmt.Println(section)
+
+	if changelogUpdateFlag == "" {
+		return nil
+	}
+	status("\n")
+	if !changelogYesFlag && !confirm(fmt.Sprintf("Insert this at the top of %s? [y/N]: ", changelogUpdateFlag)) {
+		status("Not updated.\n")
+		return nil
+	}
+	if err := prependChangelog(changelogUpdateFlag, section); err != nil {
+		return err
+	}
+	status("Updated %s\n", changelogUpdateFlag)
+	return nil
+}
+
+// changelogCommits converts git commits for the provider
+func changelogCommits(commits []git.Commit) []ai.ChangelogCommit {
+	out := make([]ai.ChangelogCommit, len(commits))
+	for i, c := range commits {
+		out[i] = ai.ChangelogCommit{Hash: c.Hash, Subject: c.Subject, Body: c.Body}
+	}
+	return out
+}
+
+// changelogSection adds the version heading to the generated notes, dated
+// unless the version is still unreleased
+func changelogSection(version string, date time.Time, notes string) string {
+	heading := "## " + version
+	if !strings.EqualFold(version, "unreleased") {
+		heading += " - " + date.Format("2006-01-02")
+	}
+	return fmt.Sprintf("%s\n\n%s\n", heading, strings.TrimSpace(notes))
+}
+
+// prependChangelog inserts section above the first existing "## " section
+// of path, keeping any title and introduction. A missing or empty file gets
+// a "# Changelog" title.
+func prependChangelog(path, section string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	existing := string(data)
+	var updated string
+	switch i := strings.Index(existing, "\n## "); {
+	case strings.TrimSpace(existing) == "":
+		updated = "# Changelog\n\n" + section
+	case strings.HasPrefix(existing, "## "):
+		updated = section + "\n" + existing
+	case i >= 0:
+		updated = existing[:i+1] + section + "\n" + existing[i+1:]
+	default:
+		updated = strings.TrimRight(existing, "\n") + "\n\n" + section
+	}
+	if err := os.WriteFile(path, []byte(updated), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
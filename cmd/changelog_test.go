@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestChangelogSection(t *testing.T) {
+	date := time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{"Unreleased", "## Unreleased\n\n### Fixes\n- a\n"},
+		{"unreleased", "## unreleased\n\n### Fixes\n- a\n"},
+		{"v1.2.0", "## v1.2.0 - 2026-03-14\n\n### Fixes\n- a\n"},
+	}
+	for _, tt := range tests {
+		if got := changelogSection(tt.version, date, "\n### Fixes\n- a\n\n"); got != tt.want {
+			t.Errorf("changelogSection(%q) = %q, want %q", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestPrependChangelog(t *testing.T) {
+	const section = "## v1.1.0\n\n- new\n"
+	tests := []struct {
+		name     string
+		missing  bool
+		existing string
+		want     string
+	}{
+		{"missing", true, "", "# Changelog\n\n" + section},
+		{"empty", false, "", "# Changelog\n\n" + section},
+		{"blank lines only", false, "\n\n", "# Changelog\n\n" + section},
+		{"title and sections", false, "# Changelog\n\nNotable changes.\n\n## v1.0.0\n\n- old\n",
+			"# Changelog\n\nNotable changes.\n\n" + section + "\n## v1.0.0\n\n- old\n"},
+		{"sections only", false, "## v1.0.0\n\n- old\n", section + "\n## v1.0.0\n\n- old\n"},
+		{"title only", false, "# Changelog\n", "# Changelog\n\n" + section},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "CHANGELOG.md")
+			if !tt.missing {
+				if err := os.WriteFile(path, []byte(tt.existing), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := prependChangelog(path, section); err != nil {
+				t.Fatalf("prependChangelog() error = %v", err)
+			}
+			got, _ := os.ReadFile(path)
+			if string(got) != tt.want {
+				t.Errorf("CHANGELOG.md = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// confirmSave asks whether to write path, mentioning when it would be replaced
+func confirmSave(path string) bool {
+	if _, err := os.Stat(path); err == nil {
+		return confirm(fmt.Sprintf("%s exists. Overwrite it? [y/N]: ", path))
+	}
+	return confirm(fmt.Sprintf("Save to %s? [y/N]: ", path))
+}
+
+// confirm prints prompt and reports whether the user answered yes
+func confirm(prompt string) bool {
+	fmt.Print(prompt)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.TrimSpace(strings.ToLower(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bastio-ai/bast/internal/debug"
+)
+
+var (
+	tailLinesFlag  int
+	tailFollowFlag bool
+)
+
+var debugCmd = &cobra.Command{
+	Use:   "debug",
+	Short: "Debugging utilities",
+	Long:  `Inspect the debug log written when bast runs with --debug (or debug: true in config).`,
+}
+
+var debugTailCmd = &cobra.Command{
+	Use:   "tail",
+	Short: "Show the end of the debug log",
+	Long: `Print the last lines of the debug log (~/.config/bast/debug.log).
+Use -f to keep printing new entries as they are written.`,
+	RunE: runDebugTail,
+}
+
+func init() {
+	rootCmd.AddCommand(debugCmd)
+	debugCmd.AddCommand(debugTailCmd)
+	debugTailCmd.Flags().IntVarP(&tailLinesFlag, "lines", "n", 50, "Number of lines to show")
+	debugTailCmd.Flags().BoolVarP(&tailFollowFlag, "follow", "f", false, "Follow the log as it grows")
+}
+
+func runDebugTail(cmd *cobra.Command, args []string) error {
+	path, err := debug.DefaultLogPath()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no debug log at %s (run bast with --debug first)", path)
+		}
+		return fmt.Errorf("failed to open debug log: %w", err)
+	}
+	defer func() {
+		if f != nil {
+			f.Close()
+		}
+	}()
+
+	// Keep only the last N lines
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > tailLinesFlag {
+			lines = lines[1:]
+		}
+	}
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+
+	if !tailFollowFlag {
+		return nil
+	}
+
+	if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		return fmt.Errorf("failed to read debug log: %w", err)
+	}
+	for {
+		time.Sleep(500 * time.Millisecond)
+
+		// The log was rotated; start over on the new file once it exists
+		if f != nil {
+			info, err := os.Stat(path)
+			cur, ferr := f.Stat()
+			if err == nil && ferr == nil && !os.SameFile(info, cur) {
+				f.Close()
+				f = nil
+			}
+		}
+		if f == nil {
+			if f, err = os.Open(path); err != nil {
+				f = nil
+				continue
+			}
+		}
+
+		if _, err := io.Copy(os.Stdout, f); err != nil {
+			return fmt.Errorf("failed to read debug log: %w", err)
+		}
+	}
+}
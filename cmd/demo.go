@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+
+	"github.com/bastio-ai/bast/internal/demo"
+	"github.com/bastio-ai/bast/internal/shell"
+	"github.com/bastio-ai/bast/internal/tui"
+)
+
+var demoCmd = &cobra.Command{
+	Use:   "demo",
+	Short: "Try bast without an API key",
+	Long: `Walk through bast with canned answers instead of a model, so no API key
+or account is needed.
+
+The tour generates and explains a command, confirms a dangerous one, and
+runs a small agent task. Each step opens the full TUI in a throwaway
+project under a temporary directory, which is deleted afterwards.
+Accepted commands are shown rather than run, and nothing is saved to
+your bast config.`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: runDemo,
+}
+
+func init() {
+	rootCmd.AddCommand(demoCmd)
+}
+
+func runDemo(cmd *cobra.Command, args []string) error {
+	root, project, err := demo.NewSandbox()
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(root)
+
+	// The agent's tools and the TUI's context work from the current directory
+	previous, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	if err := os.Chdir(project); err != nil {
+		return fmt.Errorf("failed to enter demo sandbox: %w", err)
+	}
+	defer os.Chdir(previous)
+
+	fmt.Println("bast demo")
+	fmt.Println()
+	fmt.Printf("Answers come from a script, not a model. Everything happens in a sample\nproject at %s, deleted when the demo ends.\n", project)
+
+	reader := bufio.NewReader(os.Stdin)
+	handoffPath := filepath.Join(root, "handoff")
+	for i, step := range demo.Steps {
+		fmt.Printf("\nStep %d of %d: %s\n", i+1, len(demo.Steps), step.Title)
+		fmt.Println(step.Intro)
+		fmt.Printf("Request: %q\n", step.Query)
+		fmt.Print("Press Enter to start, or q to quit: ")
+		answer, _ := reader.ReadString('\n')
+		if strings.EqualFold(strings.TrimSpace(answer), "q") {
+			return nil
+		}
+
+		if err := runDemoStep(step, project, handoffPath); err != nil {
+			return err
+		}
+		printDemoHandoff(handoffPath)
+	}
+
+	fmt.Println()
+	fmt.Println("That's the tour. To use bast for real, run `bast init` to set up your API key,")
+	fmt.Println("then add `eval \"$(bast hook zsh)\"` (or bash) to your shell config and press Ctrl+A.")
+	return nil
+}
+
+// runDemoStep opens the TUI on one step's request with the canned provider
+func runDemoStep(step demo.Step, project, handoffPath string) error {
+	os.Remove(handoffPath)
+
+	model := tui.NewModel(demo.NewProvider(), step.Query, handoffPath)
+	model.SetForcedIntent(step.Intent)
+	model.SetHandoffVersion(shell.HandoffV2)
+	model.SetLaunchDir(project)
+	model.SetSessionSaving(false)
+	model.SetIsolated(true)
+	model.SetSessionInfo(demo.Model, "", "")
+
+	if _, err := tea.NewProgram(model, tea.WithAltScreen()).Run(); err != nil {
+		return fmt.Errorf("TUI error: %w", err)
+	}
+	return nil
+}
+
+// printDemoHandoff shows the command a step handed back, which a real
+// session would put on the shell prompt
+func printDemoHandoff(path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	h, err := shell.ParseHandoff(data)
+	if err != nil {
+		return
+	}
+	fmt.Printf("\nWith the shell integration, bast would now put this on your prompt, unrun:\n  %s\n", h.Command)
+	if h.Dangerous {
+		fmt.Println("The shell hook would also mark it as dangerous.")
+	}
+}
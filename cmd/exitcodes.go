@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bastio-ai/bast/internal/ai"
+	"github.com/bastio-ai/bast/internal/safety"
+)
+
+// Exit codes returned by bast so scripts and shell hooks can branch on the
+// category of failure
+const (
+	ExitOK       = 0
+	ExitError    = 1   // Unclassified failure
+	ExitUsage    = 2   // Bad arguments, flags, or missing input
+	ExitConfig   = 3   // Missing or invalid config, credentials, or authentication
+	ExitPolicy   = 4   // Blocked by a safety or sudo policy
+	ExitProvider = 5   // The AI provider or gateway returned an error
+	ExitTimeout  = 124 // A request timed out (matches timeout(1))
+)
+
+// exitError attaches an exit code to an error
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// withExitCode tags err with the exit code bast should return for it
+func withExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{code: code, err: err}
+}
+
+// exitCode maps an error returned by a command to its exit code.
+// Timeouts and provider auth failures win over the tag added at the call site.
+func exitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ExitTimeout
+	}
+
+	var authErr *ai.ErrAuth
+	if errors.As(err, &authErr) {
+		return ExitConfig
+	}
+
+	if errors.Is(err, safety.ErrSudoForbidden) || errors.Is(err, safety.ErrSudoUnstrippable) {
+		return ExitPolicy
+	}
+
+	var tagged *exitError
+	if errors.As(err, &tagged) {
+		return tagged.code
+	}
+
+	var rateLimited *ai.ErrRateLimited
+	var overloaded *ai.ErrOverloaded
+	var tooLong *ai.ErrContextTooLong
+	var network *ai.ErrNetwork
+	if errors.As(err, &rateLimited) || errors.As(err, &overloaded) ||
+		errors.As(err, &tooLong) || errors.As(err, &network) {
+		return ExitProvider
+	}
+	return ExitError
+}
+
+// usageArgs tags argument validation failures as usage errors
+func usageArgs(validate cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		return withExitCode(ExitUsage, validate(cmd, args))
+	}
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bastio-ai/bast/internal/ai"
+	"github.com/bastio-ai/bast/internal/safety"
+)
+
+func TestExitCode(t *testing.T) {
+	base := errors.New("boom")
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, ExitOK},
+		{"unclassified", base, ExitError},
+		{"usage", withExitCode(ExitUsage, base), ExitUsage},
+		{"config", withExitCode(ExitConfig, base), ExitConfig},
+		{"policy", withExitCode(ExitPolicy, base), ExitPolicy},
+		{"provider", withExitCode(ExitProvider, base), ExitProvider},
+		{"wrapped tag", fmt.Errorf("run: %w", withExitCode(ExitUsage, base)), ExitUsage},
+		{"deadline", context.DeadlineExceeded, ExitTimeout},
+		{"wrapped deadline", fmt.Errorf("request: %w", context.DeadlineExceeded), ExitTimeout},
+		{"deadline beats tag", withExitCode(ExitProvider, fmt.Errorf("request: %w", context.DeadlineExceeded)), ExitTimeout},
+		{"auth", &ai.ErrAuth{Err: base}, ExitConfig},
+		{"auth beats tag", withExitCode(ExitProvider, &ai.ErrAuth{Err: base}), ExitConfig},
+		{"sudo forbidden", fmt.Errorf("%w: sudo ls", safety.ErrSudoForbidden), ExitPolicy},
+		{"sudo unstrippable", fmt.Errorf("%w: sudo -i", safety.ErrSudoUnstrippable), ExitPolicy},
+		{"rate limited", &ai.ErrRateLimited{Err: base}, ExitProvider},
+		{"overloaded", &ai.ErrOverloaded{Err: base}, ExitProvider},
+		{"context too long", &ai.ErrContextTooLong{Err: base}, ExitProvider},
+		{"network", fmt.Errorf("generate: %w", &ai.ErrNetwork{Err: base}), ExitProvider},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUsageArgs(t *testing.T) {
+	validate := usageArgs(cobra.ExactArgs(1))
+	if err := validate(&cobra.Command{}, []string{"one"}); err != nil {
+		t.Errorf("usageArgs() with valid args = %v, want nil", err)
+	}
+	err := validate(&cobra.Command{}, nil)
+	if err == nil {
+		t.Fatal("usageArgs() with missing args = nil, want an error")
+	}
+	if code := exitCode(err); code != ExitUsage {
+		t.Errorf("exitCode() = %d, want %d", code, ExitUsage)
+	}
+}
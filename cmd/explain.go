@@ -3,7 +3,7 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -11,8 +11,11 @@ import (
 	"github.com/bastio-ai/bast/internal/ai"
 	"github.com/bastio-ai/bast/internal/auth"
 	"github.com/bastio-ai/bast/internal/config"
+	"github.com/bastio-ai/bast/internal/files"
+	"github.com/bastio-ai/bast/internal/safety"
 	"github.com/bastio-ai/bast/internal/shell"
 	"github.com/bastio-ai/bast/internal/stdin"
+	"github.com/bastio-ai/bast/internal/units"
 )
 
 var explainCmd = &cobra.Command{
@@ -24,38 +27,73 @@ Command mode (no pipe):
   bast explain "git stash"                          # Explain what command does
   bast explain "find . -name '*.go' -exec wc -l {}"  # Break down complex command
 
+Diff mode:
+  bast explain --diff "rm -r build" "rm -rf build"   # What changed, and is it riskier?
+
+File mode:
+  bast explain --file deploy.sh                      # Explain a script
+  bast explain --file main.go "where is the config?" # Ask about a file
+  bast explain --file main.go "does it match @config.yaml?"  # @mention other files
+
+Exit code mode:
+  bast explain --exit 137                            # Decode an exit status
+  bast explain --exit 22 curl                        # Use a tool's own conventions
+
 Output mode (with pipe):
   kubectl get pods | bast explain                    # Explain the output
   kubectl get pods | bast explain "any failing?"     # Ask specific question
   cat error.log | bast explain "why is it crashing"  # Analyze logs
-  docker ps | bast explain                           # Explain container status`,
+  docker ps | bast explain                           # Explain container status
+  cat huge.log | bast explain --lines 500            # Send only the last 500 lines`,
 	RunE: runExplain,
 }
 
+var (
+	explainFileFlag  string
+	explainExitFlag  int
+	explainDiffFlag  bool
+	explainLinesFlag int
+	explainBytesFlag int
+)
+
 func init() {
 	rootCmd.AddCommand(explainCmd)
+	addQuietFlag(explainCmd)
+	explainCmd.Flags().StringVarP(&explainFileFlag, "file", "f", "", "Explain a script or source file")
+	explainCmd.Flags().IntVar(&explainExitFlag, "exit", 0, "Explain an exit status, optionally for a given command")
+	explainCmd.Flags().BoolVar(&explainDiffFlag, "diff", false, "Explain the differences between two commands")
+	explainCmd.Flags().IntVar(&explainLinesFlag, "lines", 0, "Keep only the last N lines of piped input")
+	explainCmd.Flags().IntVar(&explainBytesFlag, "bytes", 0, "Keep only the last N bytes of piped input")
 }
 
 func runExplain(cmd *cobra.Command, args []string) error {
-	// Load config first
-	cfg, err := config.Load()
-	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+	// Exit code mode: well-known codes are answered without the provider
+	if cmd.Flags().Changed("exit") {
+		return explainExit(explainExitFlag, args)
 	}
 
-	// Resolve credentials
-	providerCfg, err := auth.ResolveProviderConfig(cfg)
+	if explainDiffFlag && len(args) != 2 {
+		return withExitCode(ExitUsage, fmt.Errorf("--diff takes two commands, e.g. bast explain --diff \"rm -r build\" \"rm -rf build\""))
+	}
+
+	provider, err := newExplainProvider()
 	if err != nil {
-		fmt.Println(auth.FormatSetupInstructions(err))
 		return err
 	}
 
-	// Create provider
-	provider := ai.NewAnthropicProviderWithConfig(providerCfg)
+	// Diff mode: compare two commands
+	if explainDiffFlag {
+		return explainDiff(args[0], args[1], provider)
+	}
 
 	// Get shell context
 	shellCtx := shell.GetContext()
 
+	// File mode: explain a file, with an optional question
+	if explainFileFlag != "" {
+		return explainFile(explainFileFlag, provider, shellCtx, args)
+	}
+
 	// Determine mode: command mode vs output mode
 	if len(args) > 0 && !stdin.IsPiped() {
 		// Command mode: explain what the command does (no execution)
@@ -69,6 +107,9 @@ func runExplain(cmd *cobra.Command, args []string) error {
 	}
 
 	// No input - show usage
+	if quietFlag {
+		return withExitCode(ExitUsage, fmt.Errorf("no command or piped input provided"))
+	}
 	fmt.Println("No command or piped input provided.")
 	fmt.Println("\nUsage:")
 	fmt.Println("  bast explain \"git stash\"              # Explain what a command does")
@@ -77,50 +118,233 @@ func runExplain(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// newExplainProvider loads config and credentials and creates the provider
+func newExplainProvider() (*ai.AnthropicProvider, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, withExitCode(ExitConfig, fmt.Errorf("failed to load config: %w", err))
+	}
+
+	// Resolve credentials
+	providerCfg, err := auth.ResolveProviderConfig(cfg)
+	if err != nil {
+		fmt.Println(auth.FormatSetupInstructions(err))
+		return nil, withExitCode(ExitConfig, err)
+	}
+
+	return ai.NewAnthropicProviderWithConfig(providerCfg), nil
+}
+
+// explainExit explains an exit status, looking it up locally first and
+// asking the model only for tool-specific codes bast does not know
+func explainExit(code int, args []string) error {
+	if code < 0 || code > 255 {
+		return withExitCode(ExitUsage, fmt.Errorf("exit status must be between 0 and 255, got %d", code))
+	}
+
+	// Default to the last command when it is the one that exited with code
+	shellCtx := shell.GetContext()
+	command := strings.Join(args, " ")
+	if command == "" && shellCtx.LastCommand != "" && shellCtx.ExitStatus == code {
+		command = shellCtx.LastCommand
+	}
+
+	if desc, ok := shell.DescribeExitCode(code, command); ok {
+		printResult(fmt.Sprintf("Exit %d: %s", code, desc))
+		return nil
+	}
+
+	provider, err := newExplainProvider()
+	if err != nil {
+		return err
+	}
+
+	output := fmt.Sprintf("exit status %d", code)
+	if command != "" {
+		output = fmt.Sprintf("`%s` exited with status %d", command, code)
+	}
+	ctx := context.Background()
+	result, err := provider.ExplainOutput(ctx, output, "", "What does this exit status mean for this tool, and what usually causes it? Answer in 1-3 sentences.", shellCtx)
+	if err != nil {
+		return withExitCode(ExitProvider, fmt.Errorf("failed to explain exit status: %w", err))
+	}
+
+	printResult(result.Response)
+	return nil
+}
+
 // explainCommand explains what a command does without executing it
 func explainCommand(command string, provider *ai.AnthropicProvider, shellCtx ai.ShellContext) error {
 	ctx := context.Background()
 	explanation, err := provider.ExplainCommand(ctx, command)
 	if err != nil {
-		return fmt.Errorf("failed to explain command: %w", err)
+		return withExitCode(ExitProvider, fmt.Errorf("failed to explain command: %w", err))
+	}
+
+	printResult(explanation)
+	return nil
+}
+
+// explainDiff explains how after differs from before, leading with the
+// risk changes found by the local safety checks
+func explainDiff(before, after string, provider *ai.AnthropicProvider) error {
+	risk := safety.RiskChanges(before, after)
+
+	ctx := context.Background()
+	explanation, err := provider.CompareCommands(ctx, before, after, risk)
+	if err != nil {
+		return withExitCode(ExitProvider, fmt.Errorf("failed to compare commands: %w", err))
 	}
 
-	fmt.Fprintln(os.Stdout, explanation)
+	if len(risk) > 0 {
+		explanation = fmt.Sprintf("Risk: %s\n\n%s", strings.Join(risk, "; "), explanation)
+	}
+	printResult(explanation)
 	return nil
 }
 
+// tableSampleRows is how many lines of a piped table are sent with its digest
+const tableSampleRows = 40
+
+// readPipedInput reads stdin, refusing binary data and noting when the
+// input was too large to read in full
+func readPipedInput() (string, error) {
+	input, cut, err := stdin.Read()
+	if err != nil {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+
+	// Binary data means nothing to the model; say how to get text out of it
+	if stdin.IsBinary(input) {
+		return "", withExitCode(ExitUsage, fmt.Errorf("input looks binary, not text (%s read); pipe it through a tool that prints text first, such as 'file -', 'strings', or 'xxd | head'", units.Bytes(int64(len(input)))))
+	}
+	if cut {
+		status("Input is over %s; only the first %s was read.\n", units.Bytes(int64(stdin.MaxReadSize)), units.Bytes(int64(stdin.MaxReadSize)))
+	}
+	return input, nil
+}
+
 // explainOutput explains piped output
 func explainOutput(provider *ai.AnthropicProvider, shellCtx ai.ShellContext, args []string) error {
 	// Read piped input
-	input, err := stdin.Read()
+	input, err := readPipedInput()
 	if err != nil {
-		return fmt.Errorf("failed to read input: %w", err)
+		return err
 	}
 
 	if input == "" {
+		if quietFlag {
+			return withExitCode(ExitUsage, fmt.Errorf("no input received"))
+		}
 		fmt.Println("No input received.")
 		fmt.Println("\nNote: The pipe '|' only captures stdout. If the command outputs errors,")
 		fmt.Println("use: command 2>&1 | bast explain")
 		return nil
 	}
 
-	// Truncate if too large
-	input = stdin.Truncate(input, stdin.MaxInputSize)
+	// Keep only what was asked for
+	if explainLinesFlag > 0 {
+		input = stdin.TailLines(input, explainLinesFlag)
+	}
+	if explainBytesFlag > 0 {
+		input = stdin.TailBytes(input, explainBytesFlag)
+	}
+
+	// Digest structured logs or tables from the full input before it is cut
+	digest := stdin.DigestLogs(input)
+	if digest == "" {
+		if digest = stdin.DigestTable(input); digest != "" {
+			// The digest carries the statistics, so a sample of rows is enough
+			input = stdin.TruncateLines(input, tableSampleRows)
+		}
+	}
+
+	// Summarize if too large: collapse repeated log lines, keep errors
+	total := len(input)
+	input = stdin.Summarize(input, stdin.MaxInputSize)
+	if len(input) < total {
+		status("Input is %s; sending a %s summary (repeats collapsed, head, tail, and error lines). Use --lines or --bytes to choose what is kept.\n\n",
+			units.Bytes(int64(total)), units.Bytes(int64(len(input))))
+	}
 
 	// Get optional prompt from args
 	var prompt string
 	if len(args) > 0 {
 		prompt = args[0]
 	}
+	shellCtx, prompt = withReferencedFiles(shellCtx, prompt)
 
 	// Call AI to explain the output
 	ctx := context.Background()
-	result, err := provider.ExplainOutput(ctx, input, prompt, shellCtx)
+	result, err := provider.ExplainOutput(ctx, input, digest, prompt, shellCtx)
 	if err != nil {
-		return fmt.Errorf("failed to explain output: %w", err)
+		return withExitCode(ExitProvider, fmt.Errorf("failed to explain output: %w", err))
 	}
 
 	// Print the explanation
-	fmt.Fprintln(os.Stdout, result.Response)
+	printResult(result.Response)
 	return nil
 }
+
+// explainFile explains a file read through the same safety checks as @mentions
+func explainFile(path string, provider *ai.AnthropicProvider, shellCtx ai.ShellContext, args []string) error {
+	contents := files.ReadFiles(shellCtx.CWD, []string{path}, files.MaxSingleFileBytes)
+	if len(contents) == 0 {
+		return withExitCode(ExitUsage, fmt.Errorf("failed to read %s", path))
+	}
+	file := contents[0]
+	if file.Error != "" {
+		return withExitCode(ExitUsage, fmt.Errorf("cannot explain %s: %s", path, file.Error))
+	}
+
+	prompt := strings.Join(args, " ")
+	shellCtx, prompt = withReferencedFiles(shellCtx, prompt, path)
+
+	status("Explaining %s\n\n", file.Path)
+
+	ctx := context.Background()
+	result, err := provider.ExplainFile(ctx, file, prompt, shellCtx)
+	if err != nil {
+		return withExitCode(ExitProvider, fmt.Errorf("failed to explain file: %w", err))
+	}
+
+	printResult(result.Response)
+	return nil
+}
+
+// withReferencedFiles attaches the files a prompt @mentions or refers to
+// ("the readme"), as the TUI does, except those in exclude. It returns the
+// prompt with the @ of each mention stripped.
+func withReferencedFiles(shellCtx ai.ShellContext, prompt string, exclude ...string) (ai.ShellContext, string) {
+	skip := make(map[string]bool)
+	for _, path := range exclude {
+		skip[absPath(shellCtx.CWD, path)] = true
+	}
+	var paths []string
+	for _, path := range files.ReferencedPaths(shellCtx.CWD, prompt) {
+		if !skip[absPath(shellCtx.CWD, path)] {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		return shellCtx, prompt
+	}
+
+	shellCtx.Files = files.ReadFiles(shellCtx.CWD, paths, files.MaxTotalFileBytes)
+	for _, f := range shellCtx.Files {
+		if f.Error == "" {
+			status("Including %s\n", f.Path)
+		} else {
+			status("Skipping %s: %s\n", f.Path, f.Error)
+		}
+	}
+	return shellCtx, files.StripMentions(prompt)
+}
+
+// absPath resolves path against cwd
+func absPath(cwd, path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cwd, path)
+	}
+	return filepath.Clean(path)
+}
@@ -12,6 +12,8 @@ import (
 	"github.com/bastio-ai/bast/internal/ai"
 	"github.com/bastio-ai/bast/internal/auth"
 	"github.com/bastio-ai/bast/internal/config"
+	"github.com/bastio-ai/bast/internal/files"
+	"github.com/bastio-ai/bast/internal/safety"
 	"github.com/bastio-ai/bast/internal/shell"
 )
 
@@ -29,20 +31,21 @@ Usage:
 
 func init() {
 	rootCmd.AddCommand(fixCmd)
+	addQuietFlag(fixCmd)
 }
 
 func runFix(cmd *cobra.Command, args []string) error {
 	// Load config
 	cfg, err := config.Load()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return withExitCode(ExitConfig, fmt.Errorf("failed to load config: %w", err))
 	}
 
 	// Resolve credentials
 	providerCfg, err := auth.ResolveProviderConfig(cfg)
 	if err != nil {
 		fmt.Println(auth.FormatSetupInstructions(err))
-		return err
+		return withExitCode(ExitConfig, err)
 	}
 
 	// Create provider
@@ -88,6 +91,9 @@ func runFix(cmd *cobra.Command, args []string) error {
 	}
 
 	if failedCmd == "" && errorOutput == "" {
+		if quietFlag {
+			return withExitCode(ExitUsage, fmt.Errorf("no failed command or error output found"))
+		}
 		fmt.Println("No failed command or error output found.")
 		fmt.Println("\nUsage:")
 		fmt.Println("  bast fix                     # Uses BAST_LAST_CMD and BAST_LAST_ERROR env vars")
@@ -98,7 +104,12 @@ func runFix(cmd *cobra.Command, args []string) error {
 
 	// Display what we're analyzing
 	if failedCmd != "" {
-		fmt.Printf("Analyzing: %s\n", failedCmd)
+		status("Analyzing: %s\n", failedCmd)
+	}
+	if shellCtx.ExitStatus != 0 {
+		if desc, ok := shell.DescribeExitCode(shellCtx.ExitStatus, failedCmd); ok {
+			status("Exit %d: %s\n", shellCtx.ExitStatus, desc)
+		}
 	}
 	if errorOutput != "" {
 		// Truncate for display
@@ -106,15 +117,39 @@ func runFix(cmd *cobra.Command, args []string) error {
 		if len(displayError) > 200 {
 			displayError = displayError[:200] + "..."
 		}
-		fmt.Printf("Error: %s\n", displayError)
+		status("Error: %s\n", displayError)
 	}
-	fmt.Println()
+
+	// Read the code at the top of any stack trace so the fix targets it
+	frames := files.ReadStackFrames(shellCtx.CWD, files.ParseStackTrace(errorOutput))
+	for _, f := range frames {
+		status("Code: %s\n", f.Path)
+	}
+	status("\n")
 
 	// Call AI to fix the command
 	ctx := context.Background()
-	result, err := provider.FixCommand(ctx, failedCmd, errorOutput, shellCtx)
+	result, err := provider.FixCommand(ctx, failedCmd, errorOutput, frames, shellCtx)
 	if err != nil {
-		return fmt.Errorf("failed to analyze error: %w", err)
+		return withExitCode(ExitProvider, fmt.Errorf("failed to analyze error: %w", err))
+	}
+
+	// Enforce the sudo policy on the suggested command
+	if result.WasFixed && result.FixedCommand != "" {
+		result.FixedCommand, err = safety.ApplySudoPolicy(result.FixedCommand, cfg.Sudo)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Quiet mode prints just the fixed command, or the analysis when there is none
+	if quietFlag {
+		if result.WasFixed && result.FixedCommand != "" {
+			printCommand(result.FixedCommand)
+		} else {
+			printResult(result.Explanation)
+		}
+		return nil
 	}
 
 	// Display result
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+
+	"github.com/bastio-ai/bast/internal/auth"
+	"github.com/bastio-ai/bast/internal/config"
+	"github.com/bastio-ai/bast/internal/tools"
+)
+
+var guardCmd = &cobra.Command{
+	Use:   "guard",
+	Short: "Check commands against the security policy",
+}
+
+var guardCheckCmd = &cobra.Command{
+	Use:   "check <command>",
+	Short: "Validate a command without running it",
+	Long: `Send a command through the configured security validators, as if the
+agent were about to run it, and print the verdict. Nothing is executed.
+
+The chain is the one set in security.validators (local checks, then
+Bastio Agent Security when logged in). Pass - to read the command from
+stdin. The exit status is 0 when the command is allowed or only warned
+about, and 4 when it is blocked or needs approval, so the check can gate
+scripts and git hooks.
+
+Examples:
+  bast guard check "rm -rf /tmp/build"
+  bast guard check --json "curl https://example.com/install.sh | sh"
+  echo "$CMD" | bast guard check -`,
+	Args: usageArgs(cobra.ExactArgs(1)),
+	RunE: runGuardCheck,
+}
+
+var guardJSONFlag bool
+
+func init() {
+	rootCmd.AddCommand(guardCmd)
+	guardCmd.AddCommand(guardCheckCmd)
+	guardCheckCmd.Flags().BoolVar(&guardJSONFlag, "json", false, "Print the verdict as JSON")
+}
+
+// guardVerdict is the JSON form of a guard check
+type guardVerdict struct {
+	Command    string          `json:"command"`
+	Action     string          `json:"action"`
+	RiskScore  float64         `json:"risk_score"`
+	Threats    []string        `json:"threats"`
+	Message    string          `json:"message,omitempty"`
+	Validators []tools.Verdict `json:"validators"`
+}
+
+func runGuardCheck(cmd *cobra.Command, args []string) error {
+	command := args[0]
+	if command == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read command from stdin: %w", err)
+		}
+		command = string(data)
+	}
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return withExitCode(ExitUsage, fmt.Errorf("no command to check"))
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return withExitCode(ExitConfig, fmt.Errorf("failed to load config: %w", err))
+	}
+
+	validator, err := auth.NewSecurityValidator(cfg.Security)
+	if err != nil {
+		return withExitCode(ExitConfig, err)
+	}
+	verdict, err := checkGuardCommand(context.Background(), validator, command)
+	if verdict == nil {
+		return err
+	}
+
+	if guardJSONFlag {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(verdict); err != nil {
+			return err
+		}
+	} else {
+		printGuardVerdict(*verdict)
+	}
+	if err != nil {
+		// The verdict is the output; usage text would only bury it
+		cmd.SilenceUsage = true
+	}
+	return err
+}
+
+// checkGuardCommand sends command through validator as a run_command call.
+// The error carries ExitPolicy when the command is blocked or needs
+// approval, alongside the verdict; when validation itself fails it carries
+// ExitProvider and the verdict is nil.
+func checkGuardCommand(ctx context.Context, validator tools.SecurityValidator, command string) (*guardVerdict, error) {
+	input, _ := json.Marshal(map[string]string{"command": command})
+	call := tools.Call{ID: "guard-" + uuid.New().String(), Name: "run_command", Input: input}
+	result, err := validator.ValidateToolCall(ctx, call)
+	if err != nil {
+		return nil, withExitCode(ExitProvider, fmt.Errorf("validation failed: %w", err))
+	}
+
+	verdict := &guardVerdict{
+		Command:    command,
+		Action:     string(result.Action),
+		RiskScore:  result.RiskScore,
+		Threats:    result.ThreatsDetected,
+		Message:    result.Message,
+		Validators: result.Verdicts,
+	}
+	if verdict.Threats == nil {
+		verdict.Threats = []string{}
+	}
+	if verdict.Validators == nil {
+		verdict.Validators = []tools.Verdict{}
+	}
+
+	switch result.Action {
+	case tools.ActionBlock, tools.ActionRequireApproval:
+		return verdict, withExitCode(ExitPolicy, fmt.Errorf("command not allowed (%s)", result.Action))
+	}
+	return verdict, nil
+}
+
+// printGuardVerdict prints a verdict for people
+func printGuardVerdict(v guardVerdict) {
+	fmt.Printf("Verdict: %s (risk %.2f)\n", v.Action, v.RiskScore)
+	if v.Message != "" {
+		fmt.Printf("Reason:  %s\n", v.Message)
+	}
+	if len(v.Threats) > 0 {
+		fmt.Printf("Threats: %s\n", strings.Join(v.Threats, ", "))
+	}
+	if len(v.Validators) == 0 {
+		fmt.Println("No validators are configured.")
+		return
+	}
+	fmt.Println()
+	for _, s := range v.Validators {
+		line := fmt.Sprintf("  %-8s %s", s.Validator, s.Action)
+		if s.RiskScore > 0 {
+			line += fmt.Sprintf(" (risk %.2f)", s.RiskScore)
+		}
+		if s.Error != "" {
+			line += " — error: " + s.Error
+		} else if s.Message != "" {
+			line += " — " + s.Message
+		}
+		fmt.Println(line)
+	}
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bastio-ai/bast/internal/shell"
+)
+
+var handoffFieldFlag string
+
+var handoffCmd = &cobra.Command{
+	Use:    "handoff <file>",
+	Short:  "Decode a command handoff file (used by shell hooks)",
+	Long:   `Print the command from a handoff file written by "bast run --output-file", in either the v1 or v2 format.`,
+	Args:   usageArgs(cobra.ExactArgs(1)),
+	Hidden: true,
+	RunE:   runHandoff,
+}
+
+func init() {
+	rootCmd.AddCommand(handoffCmd)
+	handoffCmd.Flags().StringVar(&handoffFieldFlag, "field", "command", "field to print (command, cwd, working_dir, dangerous, needs_sudo, edited)")
+}
+
+func runHandoff(cmd *cobra.Command, args []string) error {
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to read handoff: %w", err)
+	}
+
+	h, err := shell.ParseHandoff(data)
+	if err != nil {
+		return err
+	}
+
+	// No trailing newline: hooks capture this with $(...)
+	switch handoffFieldFlag {
+	case "command":
+		fmt.Print(h.Command)
+	case "cwd":
+		fmt.Print(h.Dir)
+	case "working_dir":
+		fmt.Print(h.WorkDir)
+	case "dangerous":
+		fmt.Print(h.Dangerous)
+	case "needs_sudo":
+		fmt.Print(h.NeedsSudo)
+	case "edited":
+		fmt.Print(h.Edited)
+	default:
+		return withExitCode(ExitUsage, fmt.Errorf("unknown handoff field: %s", handoffFieldFlag))
+	}
+	return nil
+}
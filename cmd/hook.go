@@ -11,7 +11,7 @@ var hookCmd = &cobra.Command{
 	Use:   "hook [shell]",
 	Short: "Output shell hook script",
 	Long:  `Output the shell integration script for the specified shell (zsh or bash).`,
-	Args:  cobra.ExactArgs(1),
+	Args:  usageArgs(cobra.ExactArgs(1)),
 	RunE:  runHook,
 }
 
@@ -30,11 +30,11 @@ func runHook(cmd *cobra.Command, args []string) error {
 
 	switch shell {
 	case "zsh":
-		fmt.Printf(zshHookTemplate, exePath, exePath)
+		fmt.Printf(zshHookTemplate, exePath, exePath, exePath)
 	case "bash":
-		fmt.Printf(bashHookTemplate, exePath, exePath)
+		fmt.Printf(bashHookTemplate, exePath, exePath, exePath)
 	default:
-		return fmt.Errorf("unsupported shell: %s (supported: zsh, bash)", shell)
+		return withExitCode(ExitUsage, fmt.Errorf("unsupported shell: %s (supported: zsh, bash)", shell))
 	}
 
 	return nil
@@ -76,8 +76,9 @@ bast_capture() {
     "$@" > >(tee "$_bast_stdout_file") 2> >(tee "$_bast_stderr_file" >&2)
 }
 
-# Launch bast with Ctrl+A
-_bast_widget() {
+# Launch bast in the given mode (auto, command, chat, or agent)
+_bast_launch() {
+    local mode="$1"
     local saved_buffer="$BUFFER"
     local saved_cursor="$CURSOR"
 
@@ -92,16 +93,36 @@ _bast_widget() {
     # Sync history to file before launching bast
     fc -AI 2>/dev/null
 
+    # Dump aliases and function names so generated commands account for them
+    local aliasfile=$(mktemp "${TMPDIR:-/tmp}/bast_aliases.XXXXXX")
+    chmod 600 "$aliasfile"
+    { alias; print -rl -- ${(k)functions} | grep -v '^_' | sed 's/^/function /'; } > "$aliasfile" 2>/dev/null
+
     # Run bast directly (not in subshell) - TUI gets proper terminal I/O
-    "%s" run --output-file "$tmpfile"
+    BAST_ALIASES_FILE="$aliasfile" "%s" run --mode "$mode" --output-file "$tmpfile" --handoff-version 2
+    rm -f "$aliasfile"
 
     # Read result from temp file
     if [[ -f "$tmpfile" ]]; then
         local output=$(cat "$tmpfile")
+        local cmd="" workdir=""
+        if [[ "$output" == "{"* ]]; then
+            # v2 JSON handoff - decoded by bast so multi-line commands survive
+            cmd="$("%s" handoff "$tmpfile")"
+            workdir="$("%[2]s" handoff --field working_dir "$tmpfile")"
+        elif [[ "$output" == BAST_COMMAND:* ]]; then
+            cmd="${output#BAST_COMMAND:}"
+        fi
         rm -f "$tmpfile"
 
-        if [[ "$output" == BAST_COMMAND:* ]]; then
-            BUFFER="${output#BAST_COMMAND:}"
+        # Change to the directory the command must run in; if that fails,
+        # don't offer a command meant for somewhere else
+        if [[ -n "$cmd" && -n "$workdir" ]] && ! cd -- "$workdir"; then
+            cmd=""
+        fi
+
+        if [[ -n "$cmd" ]]; then
+            BUFFER="$cmd"
             CURSOR=${#BUFFER}
         else
             BUFFER="$saved_buffer"
@@ -112,11 +133,22 @@ _bast_widget() {
         CURSOR="$saved_cursor"
     fi
 
-    zle redisplay
+    # Redraw the prompt too, which may show a new directory
+    zle reset-prompt
 }
 
+# Ctrl+A classifies each query, Ctrl+G always generates a command,
+# Ctrl+T always runs the agent
+_bast_widget() { _bast_launch auto }
+_bast_command_widget() { _bast_launch command }
+_bast_agent_widget() { _bast_launch agent }
+
 zle -N _bast_widget
+zle -N _bast_command_widget
+zle -N _bast_agent_widget
 bindkey '^A' _bast_widget
+bindkey '^G' _bast_command_widget
+bindkey '^T' _bast_agent_widget
 
 # Explain command with Ctrl+E (without executing)
 _bast_explain_widget() {
@@ -170,8 +202,9 @@ bast_capture() {
     "$@" > >(tee "$_bast_stdout_file") 2> >(tee "$_bast_stderr_file" >&2)
 }
 
-# Launch bast with Ctrl+A
-_bast_readline() {
+# Launch bast in the given mode (auto, command, chat, or agent)
+_bast_launch() {
+    local mode="$1"
     local saved_line="$READLINE_LINE"
     local saved_point="$READLINE_POINT"
 
@@ -182,16 +215,36 @@ _bast_readline() {
     # Sync history to file before launching bast
     history -a 2>/dev/null
 
+    # Dump aliases and function names so generated commands account for them
+    local aliasfile=$(mktemp "${TMPDIR:-/tmp}/bast_aliases.XXXXXX")
+    chmod 600 "$aliasfile"
+    { alias; declare -F | sed 's/^declare -f[a-z]* /function /' | grep -v '^function _'; } > "$aliasfile" 2>/dev/null
+
     # Run bast directly (not in subshell) - TUI gets proper terminal I/O
-    "%s" run --output-file "$tmpfile"
+    BAST_ALIASES_FILE="$aliasfile" "%s" run --mode "$mode" --output-file "$tmpfile" --handoff-version 2
+    rm -f "$aliasfile"
 
     # Read result from temp file
     if [[ -f "$tmpfile" ]]; then
         local output=$(cat "$tmpfile")
+        local cmd="" workdir=""
+        if [[ "$output" == "{"* ]]; then
+            # v2 JSON handoff - decoded by bast so multi-line commands survive
+            cmd="$("%s" handoff "$tmpfile")"
+            workdir="$("%[2]s" handoff --field working_dir "$tmpfile")"
+        elif [[ "$output" == BAST_COMMAND:* ]]; then
+            cmd="${output#BAST_COMMAND:}"
+        fi
         rm -f "$tmpfile"
 
-        if [[ "$output" == BAST_COMMAND:* ]]; then
-            READLINE_LINE="${output#BAST_COMMAND:}"
+        # Change to the directory the command must run in; if that fails,
+        # don't offer a command meant for somewhere else
+        if [[ -n "$cmd" && -n "$workdir" ]] && ! cd -- "$workdir"; then
+            cmd=""
+        fi
+
+        if [[ -n "$cmd" ]]; then
+            READLINE_LINE="$cmd"
             READLINE_POINT=${#READLINE_LINE}
         else
             READLINE_LINE="$saved_line"
@@ -203,7 +256,15 @@ _bast_readline() {
     fi
 }
 
+# Ctrl+A classifies each query, Ctrl+G always generates a command,
+# Ctrl+T always runs the agent
+_bast_readline() { _bast_launch auto; }
+_bast_command_readline() { _bast_launch command; }
+_bast_agent_readline() { _bast_launch agent; }
+
 bind -x '"\C-a": _bast_readline'
+bind -x '"\C-g": _bast_command_readline'
+bind -x '"\C-t": _bast_agent_readline'
 
 # Explain command with Ctrl+E (without executing)
 _bast_explain_readline() {
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bastio-ai/bast/internal/shell"
+)
+
+// The hook tests source the real hook scripts into bash and zsh, with this
+// test binary standing in for bast: "run" writes a canned handoff instead of
+// opening the TUI, and every other subcommand (handoff) runs for real.
+const (
+	stubEnv       = "BAST_HOOK_TEST_STUB"   // Set to run as the stub
+	stubOutputEnv = "BAST_HOOK_TEST_OUTPUT" // What "run" writes to --output-file
+	stubLogEnv    = "BAST_HOOK_TEST_LOG"    // Where "run" records how it was called
+)
+
+func TestMain(m *testing.M) {
+	if os.Getenv(stubEnv) != "" {
+		os.Exit(runStub(os.Args[1:]))
+	}
+	os.Exit(m.Run())
+}
+
+// runStub acts as bast for the hook scripts
+func runStub(args []string) int {
+	if len(args) == 0 || args[0] != "run" {
+		rootCmd.SetArgs(args)
+		if err := rootCmd.Execute(); err != nil {
+			return exitCode(err)
+		}
+		return 0
+	}
+
+	var mode, outputFile string
+	for i := 1; i+1 < len(args); i++ {
+		switch args[i] {
+		case "--mode":
+			mode = args[i+1]
+		case "--output-file":
+			outputFile = args[i+1]
+		}
+	}
+	aliases := "no aliases file"
+	if data, err := os.ReadFile(os.Getenv("BAST_ALIASES_FILE")); err == nil && len(data) > 0 {
+		aliases = "aliases file"
+	}
+	log := fmt.Sprintf("mode=%s %s\n", mode, aliases)
+	if err := os.WriteFile(os.Getenv(stubLogEnv), []byte(log), 0600); err != nil {
+		return 1
+	}
+	if err := os.WriteFile(outputFile, []byte(os.Getenv(stubOutputEnv)), 0600); err != nil {
+		return 1
+	}
+	return 0
+}
+
+// hookShell describes how to drive one shell's hook without a terminal
+type hookShell struct {
+	name     string
+	template string
+	args     []string // Run the script without user rc files
+	line     string   // Variable holding the command line
+	point    string   // Variable holding the cursor position
+}
+
+var hookShells = []hookShell{
+	{name: "bash", template: bashHookTemplate, args: []string{"--norc", "--noprofile"}, line: "READLINE_LINE", point: "READLINE_POINT"},
+	{name: "zsh", template: zshHookTemplate, args: []string{"-f"}, line: "BUFFER", point: "CURSOR"},
+}
+
+// hookResult is the command line after the widget ran
+type hookResult struct {
+	line  string
+	point string
+	log   string
+	dir   string // Working directory the shell was left in
+}
+
+// runWidget sources the hook for sh with a draft command line, runs the
+// launch widget in mode as a key binding would, and returns the line left
+// behind. output is what the stubbed TUI hands back.
+func runWidget(t *testing.T, sh hookShell, mode, output string) hookResult {
+	t.Helper()
+	path, err := exec.LookPath(sh.name)
+	if err != nil {
+		t.Skipf("%s not installed", sh.name)
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	hook := fmt.Sprintf(sh.template, exe, exe, exe)
+	script := fmt.Sprintf(`%s
+alias ll='ls -l'
+%s='echo draft'
+%s=4
+_bast_launch %s
+printf '%%s' "$%s" > %q
+printf '%%s' "$%s" > %q
+printf '%%s' "$PWD" > %q
+`, hook, sh.line, sh.point, mode, sh.line, filepath.Join(dir, "line"), sh.point, filepath.Join(dir, "point"), filepath.Join(dir, "pwd"))
+	scriptPath := filepath.Join(dir, "script")
+	if err := os.WriteFile(scriptPath, []byte(script), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	logPath := filepath.Join(dir, "log")
+	cmd := exec.Command(path, append(sh.args, scriptPath)...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"HOME="+dir,
+		"TMPDIR="+dir,
+		stubEnv+"=1",
+		stubOutputEnv+"="+output,
+		stubLogEnv+"="+logPath,
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%s exited with %v:\n%s", sh.name, err, out)
+	}
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("reading %s: %v", name, err)
+		}
+		return string(data)
+	}
+	return hookResult{line: read("line"), point: read("point"), log: read("log"), dir: read("pwd")}
+}
+
+func TestHookHandoff(t *testing.T) {
+	multiline := "for f in *.go; do\n  gofmt -l \"$f\"\ndone"
+	v2, err := shell.NewHandoff(multiline, "/src", false, false).Encode(shell.HandoffV2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	workDir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	inDir := shell.NewHandoff("make test", "/src", false, false)
+	inDir.WorkDir = workDir
+	v2InDir, err := inDir.Encode(shell.HandoffV2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inDir.WorkDir = filepath.Join(workDir, "missing")
+	v2Missing, err := inDir.Encode(shell.HandoffV2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		output string
+		line   string
+		point  int
+		dir    string // Directory the shell must end up in, if not where it started
+	}{
+		{"v2 JSON keeps a multi-line command", string(v2), multiline, len(multiline), ""},
+		{"v1 command line", shell.LegacyHandoffPrefix + "ls -la", "ls -la", len("ls -la"), ""},
+		{"cancelled restores the draft", "", "echo draft", 4, ""},
+		{"working_dir changes directory", string(v2InDir), "make test", len("make test"), workDir},
+		{"missing working_dir restores the draft", string(v2Missing), "echo draft", 4, ""},
+	}
+
+	for _, sh := range hookShells {
+		for _, tt := range tests {
+			t.Run(sh.name+"/"+tt.name, func(t *testing.T) {
+				got := runWidget(t, sh, "command", tt.output)
+				if got.line != tt.line {
+					t.Errorf("%s = %q, want %q", sh.line, got.line, tt.line)
+				}
+				if got.point != fmt.Sprint(tt.point) {
+					t.Errorf("%s = %s, want %d", sh.point, got.point, tt.point)
+				}
+				if tt.dir != "" && got.dir != tt.dir {
+					t.Errorf("shell left in %q, want %q", got.dir, tt.dir)
+				}
+				if tt.dir == "" && got.dir == workDir {
+					t.Errorf("shell changed to %q, want it left where it started", got.dir)
+				}
+			})
+		}
+	}
+}
+
+func TestHookLaunch(t *testing.T) {
+	for _, sh := range hookShells {
+		t.Run(sh.name, func(t *testing.T) {
+			got := runWidget(t, sh, "agent", "")
+			if !strings.Contains(got.log, "mode=agent") {
+				t.Errorf("bast run was called as %q, want --mode agent", got.log)
+			}
+			if !strings.Contains(got.log, "aliases file") || strings.Contains(got.log, "no aliases file") {
+				t.Errorf("bast run was called as %q, want the alias dump in BAST_ALIASES_FILE", got.log)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bastio-ai/bast/internal/git"
+	"github.com/bastio-ai/bast/internal/safety"
+	"github.com/bastio-ai/bast/internal/stdin"
+	"github.com/bastio-ai/bast/internal/target"
+)
+
+// Per-repository settings, stored in git config
+const (
+	hookSecretsKey = "bast.precommit.secrets" // block (default), warn, or off
+	hookReviewKey  = "bast.precommit.review"  // true adds an AI review summary
+)
+
+// hookMarker identifies a pre-commit hook written by bast
+const hookMarker = "# bast-managed pre-commit hook"
+
+// maxReviewDiff caps the staged diff sent for an AI review
+const maxReviewDiff = 60000
+
+// reviewTimeout bounds the AI review so a slow provider can't hold up a commit
+const reviewTimeout = 30 * time.Second
+
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Install bast into other tools' hooks",
+}
+
+var hooksGitCmd = &cobra.Command{
+	Use:   "git",
+	Short: "Manage the git pre-commit hook",
+}
+
+var hooksGitInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install a pre-commit hook that scans staged changes",
+	Long: `Install a pre-commit hook in the current repository. Before each commit
+it scans the lines being added for credentials (API keys, tokens, private
+keys), using the same detectors that guard agent output, and blocks the
+commit when it finds one. Optionally it also prints a short AI review of
+the staged changes.
+
+Settings are per repository, in git config:
+  bast.precommit.secrets  block (default), warn, or off
+  bast.precommit.review   true to add the AI review
+
+An existing pre-commit hook is kept as pre-commit.local and still runs
+first.
+
+Examples:
+  bast hooks git install
+  bast hooks git install --review --secrets warn`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: runHooksGitInstall,
+}
+
+var hooksGitUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the pre-commit hook",
+	Args:  usageArgs(cobra.NoArgs),
+	RunE:  runHooksGitUninstall,
+}
+
+var hooksGitRunCmd = &cobra.Command{
+	Use:    "run <hook>",
+	Short:  "Run a hook (called by the installed hook script)",
+	Hidden: true,
+	Args:   usageArgs(cobra.ExactArgs(1)),
+	RunE:   runHooksGitRun,
+}
+
+var (
+	hooksReviewFlag  bool
+	hooksSecretsFlag string
+)
+
+func init() {
+	rootCmd.AddCommand(hooksCmd)
+	hooksCmd.AddCommand(hooksGitCmd)
+	hooksGitCmd.AddCommand(hooksGitInstallCmd, hooksGitUninstallCmd, hooksGitRunCmd)
+	hooksGitInstallCmd.Flags().BoolVar(&hooksReviewFlag, "review", false, "Also print an AI review of the staged changes")
+	hooksGitInstallCmd.Flags().StringVar(&hooksSecretsFlag, "secrets", "", "What to do when a credential is staged: block, warn, or off")
+}
+
+// preCommitHookPath returns the path of the repository's pre-commit hook
+func preCommitHookPath(cwd string) (string, error) {
+	dir, err := git.HooksDir(cwd)
+	if err != nil {
+		return "", withExitCode(ExitUsage, err)
+	}
+	return filepath.Join(dir, "pre-commit"), nil
+}
+
+func runHooksGitInstall(cmd *cobra.Command, args []string) error {
+	cwd, _ := os.Getwd()
+	switch hooksSecretsFlag {
+	case "", "block", "warn", "off":
+	default:
+		return withExitCode(ExitUsage, fmt.Errorf("invalid --secrets %q (expected block, warn, or off)", hooksSecretsFlag))
+	}
+	path, err := preCommitHookPath(cwd)
+	if err != nil {
+		return err
+	}
+
+	existing, err := os.ReadFile(path)
+	if err == nil && !strings.Contains(string(existing), hookMarker) {
+		local := path + ".local"
+		if _, err := os.Stat(local); err == nil {
+			return fmt.Errorf("%s exists and so does %s; merge them by hand first", path, local)
+		}
+		if err := os.Rename(path, local); err != nil {
+			return fmt.Errorf("failed to keep existing hook: %w", err)
+		}
+		fmt.Printf("Kept your existing hook as %s; it runs first.\n", local)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(preCommitHook(getBastPath())), 0755); err != nil {
+		return fmt.Errorf("failed to write hook: %w", err)
+	}
+
+	if hooksSecretsFlag != "" {
+		if err := git.SetConfigValue(cwd, hookSecretsKey, hooksSecretsFlag); err != nil {
+			return err
+		}
+	}
+	if cmd.Flags().Changed("review") {
+		if err := git.SetConfigValue(cwd, hookReviewKey, fmt.Sprint(hooksReviewFlag)); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("✓ Installed %s\n", path)
+	return nil
+}
+
+func runHooksGitUninstall(cmd *cobra.Command, args []string) error {
+	cwd, _ := os.Getwd()
+	path, err := preCommitHookPath(cwd)
+	if err != nil {
+		return err
+	}
+	existing, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(existing), hookMarker) {
+		fmt.Println("No bast pre-commit hook is installed.")
+		return nil
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove hook: %w", err)
+	}
+	if _, err := os.Stat(path + ".local"); err == nil {
+		if err := os.Rename(path+".local", path); err != nil {
+			return fmt.Errorf("failed to restore previous hook: %w", err)
+		}
+		fmt.Println("Restored your previous pre-commit hook.")
+	}
+	fmt.Printf("✓ Removed the bast hook from %s\n", path)
+	return nil
+}
+
+func runHooksGitRun(cmd *cobra.Command, args []string) error {
+	if args[0] != "pre-commit" {
+		return withExitCode(ExitUsage, fmt.Errorf("unsupported hook: %s (supported: pre-commit)", args[0]))
+	}
+	cwd, _ := os.Getwd()
+	diff, err := git.StagedDiff(cwd)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(diff) == "" {
+		return nil
+	}
+
+	mode := git.ConfigValue(cwd, hookSecretsKey)
+	if mode != "off" {
+		if findings := safety.ScanDiff(diff); len(findings) > 0 {
+			fmt.Fprintln(os.Stderr, "bast: staged changes add credentials:")
+			for _, f := range findings {
+				fmt.Fprintf(os.Stderr, "  %s:%d  %s\n", f.File, f.Line, strings.Join(f.Kinds, ", "))
+			}
+			if mode != "warn" {
+				fmt.Fprintln(os.Stderr, "Commit blocked. Remove them (or commit with --no-verify if they are fake test values).")
+				cmd.SilenceUsage = true
+				return withExitCode(ExitPolicy, fmt.Errorf("credentials in staged changes"))
+			}
+		}
+	}
+
+	if git.ConfigValue(cwd, hookReviewKey) == "true" {
+		reviewStaged(diff)
+	}
+	return nil
+}
+
+// reviewStaged prints an AI review of the staged diff. Credentials are
+// redacted before it is sent, and failures never block the commit.
+func reviewStaged(diff string) {
+	provider, err := newExplainProvider()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "bast: review skipped: %v\n", err)
+		return
+	}
+	redacted, _ := safety.RedactSecrets(stdin.Summarize(diff, maxReviewDiff))
+
+	ctx, cancel := context.WithTimeout(context.Background(), reviewTimeout)
+	defer cancel()
+	review, err := provider.ReviewDiff(ctx, redacted)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "bast: review skipped: %v\n", err)
+		return
+	}
+	fmt.Printf("bast review:\n%s\n", strings.TrimSpace(review))
+}
+
+// preCommitHook renders the pre-commit script that runs the bast at exe
+func preCommitHook(exe string) string {
+	return fmt.Sprintf(preCommitHookTemplate, hookMarker, target.Quote(exe))
+}
+
+const preCommitHookTemplate = `#!/bin/sh
+%s
+# Remove with: bast hooks git uninstall
+
+# Run the hook this one replaced, if any
+local_hook="$(dirname "$0")/pre-commit.local"
+if [ -x "$local_hook" ]; then
+    "$local_hook" "$@" || exit $?
+fi
+
+exec %s hooks git run pre-commit
+`
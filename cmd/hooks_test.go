@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPreCommitHookQuotesPath(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "my $tools")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	exe := filepath.Join(dir, "bast")
+	if err := os.WriteFile(exe, []byte("#!/bin/sh\necho \"ran $*\"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	hook := filepath.Join(t.TempDir(), "pre-commit")
+	if err := os.WriteFile(hook, []byte(preCommitHook(exe)), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := exec.Command("sh", hook).CombinedOutput()
+	if err != nil {
+		t.Fatalf("hook failed: %v\n%s", err, out)
+	}
+	if got := strings.TrimSpace(string(out)); got != "ran hooks git run pre-commit" {
+		t.Errorf("hook output = %q, want the bast at %q to run", got, exe)
+	}
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bastio-ai/bast/internal/config"
+	"github.com/bastio-ai/bast/internal/importer"
+	"github.com/bastio-ai/bast/internal/tools"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import settings from another terminal AI tool",
+	Long: `Carry settings over from Warp, Fig, aichat, or shell-gpt.
+
+bast reads the tool's config and alias files where present and shows what
+it found. The preferred model is kept only if it is an Anthropic model.
+Warp workflows become plugins in ~/.config/bast/tools, so the agent can
+run them. Shell aliases are left out unless you pass --aliases: as agent
+tools they would let the model run anything the alias expands to without
+the checks run_command gets. An Anthropic API key is imported only after
+you agree to copy it into ~/.config/bast/config.yaml.
+
+Examples:
+  bast import --from aichat
+  bast import --from warp --yes
+  bast import --from fig --aliases`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: runImport,
+}
+
+var (
+	importFromFlag    string
+	importYesFlag     bool
+	importAliasesFlag bool
+)
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().StringVar(&importFromFlag, "from", "", "Tool to import from: "+strings.Join(importer.Sources, ", "))
+	importCmd.Flags().BoolVarP(&importYesFlag, "yes", "y", false, "Import the model and commands without asking (API keys are always confirmed)")
+	importCmd.Flags().BoolVar(&importAliasesFlag, "aliases", false, "Also add shell aliases as agent plugins")
+	importCmd.MarkFlagRequired("from")
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return withExitCode(ExitConfig, fmt.Errorf("failed to get home directory: %w", err))
+	}
+	settings, err := importer.Load(importFromFlag, home)
+	if err != nil {
+		return withExitCode(ExitUsage, err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return withExitCode(ExitConfig, fmt.Errorf("failed to load config: %w", err))
+	}
+
+	fmt.Printf("Read %s settings from:\n", importFromFlag)
+	for _, f := range settings.Files {
+		fmt.Printf("  %s\n", f)
+	}
+	for _, note := range settings.Skipped {
+		fmt.Printf("  skipped: %s\n", note)
+	}
+	fmt.Println()
+
+	changed := false
+	if settings.Model != "" && settings.Model != cfg.Model {
+		if importYesFlag || confirm(fmt.Sprintf("Use model %s (currently %s)? [y/N]: ", settings.Model, cfg.Model)) {
+			cfg.Model = settings.Model
+			changed = true
+		}
+	}
+	if settings.APIKey != "" {
+		// Copying the key switches to a direct connection, which the system
+		// config may not allow
+		if err := cfg.CheckFeature(config.FeatureDirect); err != nil {
+			fmt.Printf("Not copying the Anthropic API key from %s: %v\n", settings.KeyFile, err)
+		} else if confirm(fmt.Sprintf("Copy the Anthropic API key from %s into bast's config? [y/N]: ", settings.KeyFile)) {
+			cfg.APIKey = settings.APIKey
+			cfg.Gateway = config.GatewayDirect
+			changed = true
+		}
+	}
+	if changed {
+		if err := config.Save(cfg); err != nil {
+			return withExitCode(ExitConfig, err)
+		}
+		path, _ := config.DefaultConfigPath()
+		fmt.Printf("✓ Updated %s\n", path)
+	}
+
+	if !importAliasesFlag {
+		commands := settings.Commands[:0]
+		aliases := 0
+		for _, c := range settings.Commands {
+			if c.Alias {
+				aliases++
+				continue
+			}
+			commands = append(commands, c)
+		}
+		settings.Commands = commands
+		if aliases > 0 {
+			fmt.Printf("Skipped %d shell aliases (pass --aliases to add them as agent plugins)\n", aliases)
+		}
+	}
+
+	if len(settings.Commands) == 0 {
+		if !changed {
+			fmt.Println("Nothing to import.")
+		}
+		return nil
+	}
+	return importCommands(settings)
+}
+
+// importCommands writes aliases and workflows as plugin manifests, leaving
+// existing plugins alone
+func importCommands(settings *importer.Settings) error {
+	dir, err := tools.DefaultPluginsDir()
+	if err != nil {
+		return withExitCode(ExitConfig, err)
+	}
+
+	fmt.Printf("Found %d commands to add as plugins:\n", len(settings.Commands))
+	for _, c := range settings.Commands {
+		fmt.Printf("  %-24s %s\n", importer.ToolName(c.Name), c.Command)
+	}
+	if !importYesFlag && !confirm(fmt.Sprintf("Add them to %s? [y/N]: ", dir)) {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create plugins directory: %w", err)
+	}
+
+	written, existing := 0, 0
+	for _, c := range settings.Commands {
+		name := importer.ToolName(c.Name)
+		if name == "" {
+			continue
+		}
+		path := filepath.Join(dir, fmt.Sprintf("%s-%s.yaml", settings.Source, name))
+		if _, err := os.Stat(path); err == nil {
+			existing++
+			continue
+		}
+		data, err := c.Manifest()
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return fmt.Errorf("failed to write plugin: %w", err)
+		}
+		written++
+	}
+	fmt.Printf("✓ Added %d plugins", written)
+	if existing > 0 {
+		fmt.Printf(" (%d already imported)", existing)
+	}
+	fmt.Println()
+	return nil
+}
@@ -4,7 +4,9 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -27,15 +29,40 @@ func getBastPath() string {
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize bast configuration",
-	Long:  `Interactive setup wizard to configure bast with your API key and preferences.`,
-	RunE:  runInit,
+	Long: `Interactive setup wizard to configure bast with your API key and preferences.
+
+With --enroll, bast is instead set up without prompts from an organization
+enrollment token, which provisions the Bastio proxy, model, and settings the
+organization chose. This suits MDM and provisioning scripts; pass - to read
+the token from stdin.`,
+	RunE: runInit,
+}
+
+var initEnrollFlag string
+
+// initModel is a model offered by bast init
+type initModel struct {
+	ID   string
+	Note string
+}
+
+// initModels are offered when the system config does not restrict models
+var initModels = []initModel{
+	{ID: "claude-sonnet-4-5-20250929", Note: "recommended"},
+	{ID: "claude-haiku-4-5-20251001", Note: "faster, cheaper"},
+	{ID: "claude-opus-4-6", Note: "most capable"},
 }
 
 func init() {
 	rootCmd.AddCommand(initCmd)
+	initCmd.Flags().StringVar(&initEnrollFlag, "enroll", "", "Enroll this machine with an organization token (- reads it from stdin)")
 }
 
 func runInit(cmd *cobra.Command, args []string) error {
+	if initEnrollFlag != "" {
+		return runEnroll(initEnrollFlag)
+	}
+
 	reader := bufio.NewReader(os.Stdin)
 
 	fmt.Println("Welcome to bast setup!")
@@ -60,17 +87,27 @@ func runInit(cmd *cobra.Command, args []string) error {
 		Gateway:  config.DefaultGateway,
 	}
 
-	// Ask about Bastio
-	fmt.Println("Do you want to use Bastio AI Security? (recommended)")
-	fmt.Println("Bastio adds enterprise-grade security including PII detection,")
-	fmt.Println("jailbreak prevention, and threat detection.")
-	fmt.Println()
-	fmt.Println("[Y] Yes, secure with Bastio  [n] No, connect directly")
-	fmt.Print("> ")
-	bastioChoice, _ := reader.ReadString('\n')
-	bastioChoice = strings.TrimSpace(strings.ToLower(bastioChoice))
+	// Policy from the system config limits the choices offered
+	policy := &config.Config{}
+	if loaded, err := config.Load(); err == nil {
+		policy = loaded
+	}
 
-	useBastio := bastioChoice != "n" && bastioChoice != "no"
+	// Ask about Bastio, unless the system config requires it
+	useBastio := true
+	if policy.FeatureEnabled(config.FeatureDirect) {
+		fmt.Println("Do you want to use Bastio AI Security? (recommended)")
+		fmt.Println("Bastio adds enterprise-grade security including PII detection,")
+		fmt.Println("jailbreak prevention, and threat detection.")
+		fmt.Println()
+		fmt.Println("[Y] Yes, secure with Bastio  [n] No, connect directly")
+		fmt.Print("> ")
+		bastioChoice, _ := reader.ReadString('\n')
+		bastioChoice = strings.TrimSpace(strings.ToLower(bastioChoice))
+		useBastio = bastioChoice != "n" && bastioChoice != "no"
+	} else {
+		fmt.Printf("Bastio AI Security is required on this machine (%s).\n", config.SystemConfigPath)
+	}
 
 	if useBastio {
 		cfg.Gateway = config.GatewayBastio
@@ -85,37 +122,41 @@ func runInit(cmd *cobra.Command, args []string) error {
 	}
 
 	// Select model
+	models := initModels
+	if len(policy.Managed.AllowedModels) > 0 {
+		models = nil
+		for _, model := range policy.Managed.AllowedModels {
+			models = append(models, initModel{ID: model})
+		}
+	}
 	fmt.Println()
 	fmt.Println("Select model:")
-	fmt.Println("1. claude-sonnet-4-5-20250929 (recommended)")
-	fmt.Println("2. claude-haiku-4-5-20251001 (faster, cheaper)")
-	fmt.Println("3. claude-opus-4-6 (most capable)")
+	for i, model := range models {
+		if model.Note != "" {
+			fmt.Printf("%d. %s (%s)\n", i+1, model.ID, model.Note)
+		} else {
+			fmt.Printf("%d. %s\n", i+1, model.ID)
+		}
+	}
 	fmt.Print("> ")
 	modelChoice, _ := reader.ReadString('\n')
-	modelChoice = strings.TrimSpace(modelChoice)
-
-	switch modelChoice {
-	case "2":
-		cfg.Model = "claude-haiku-4-5-20251001"
-	case "3":
-		cfg.Model = "claude-opus-4-6"
-	default:
-		cfg.Model = "claude-sonnet-4-5-20250929"
+	cfg.Model = models[0].ID
+	if n, err := strconv.Atoi(strings.TrimSpace(modelChoice)); err == nil && n >= 1 && n <= len(models) {
+		cfg.Model = models[n-1].ID
 	}
 
-	// Select mode
-	fmt.Println()
-	fmt.Println("Select execution mode:")
-	fmt.Println("1. safe - Always confirm before executing (recommended)")
-	fmt.Println("2. yolo - Execute commands without confirmation")
-	fmt.Print("> ")
-	modeChoice, _ := reader.ReadString('\n')
-	modeChoice = strings.TrimSpace(modeChoice)
-
-	if modeChoice == "2" {
-		cfg.Mode = "yolo"
-	} else {
-		cfg.Mode = "safe"
+	// Select mode, unless the system config turns yolo off
+	cfg.Mode = "safe"
+	if policy.FeatureEnabled(config.FeatureYolo) {
+		fmt.Println()
+		fmt.Println("Select execution mode:")
+		fmt.Println("1. safe - Always confirm before executing (recommended)")
+		fmt.Println("2. yolo - Execute commands without confirmation")
+		fmt.Print("> ")
+		modeChoice, _ := reader.ReadString('\n')
+		if strings.TrimSpace(modeChoice) == "2" {
+			cfg.Mode = "yolo"
+		}
 	}
 
 	// Save config
@@ -141,6 +182,67 @@ func runInit(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// runEnroll sets bast up from an organization enrollment token without
+// prompting. Running it again re-enrolls and keeps the user's other settings.
+func runEnroll(token string) error {
+	if token == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return withExitCode(ExitUsage, fmt.Errorf("failed to read enrollment token: %w", err))
+		}
+		token = string(data)
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return withExitCode(ExitUsage, fmt.Errorf("enrollment token is empty"))
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return withExitCode(ExitConfig, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	status("Enrolling with Bastio...\n")
+	enrollment, err := auth.NewAuthenticator().Enroll(ctx, token)
+	if err != nil {
+		return withExitCode(ExitProvider, err)
+	}
+
+	cfg.Gateway = config.GatewayBastio
+	cfg.Bastio.ProxyID = enrollment.ProxyID
+	if enrollment.Model != "" {
+		if err := cfg.CheckModel(enrollment.Model); err != nil {
+			return withExitCode(ExitPolicy, err)
+		}
+		cfg.Model = enrollment.Model
+	}
+
+	if err := config.Save(cfg); err != nil {
+		return withExitCode(ExitConfig, fmt.Errorf("failed to save config: %w", err))
+	}
+	if len(enrollment.Settings) > 0 {
+		if _, ok := enrollment.Settings["managed"]; ok {
+			fmt.Fprintf(os.Stderr, "Note: managed settings were ignored; they only apply from %s\n", config.SystemConfigPath)
+		}
+		if err := config.Provision(enrollment.Settings); err != nil {
+			return withExitCode(ExitConfig, fmt.Errorf("failed to apply organization settings: %w", err))
+		}
+	}
+
+	configPath, _ := config.DefaultConfigPath()
+	fmt.Println("✓ Enrolled with Bastio")
+	fmt.Printf("  Proxy:  %s\n", enrollment.ProxyID)
+	fmt.Printf("  Model:  %s\n", cfg.Model)
+	fmt.Printf("  Config: %s\n", configPath)
+	fmt.Println()
+	fmt.Printf("Add to the shell profile: eval \"$(%s hook zsh)\"\n", getBastPath())
+
+	return nil
+}
+
 func runBastioSetup(reader *bufio.Reader, cfg *config.Config) error {
 	fmt.Println()
 
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bastio-ai/bast/internal/ai"
+	"github.com/bastio-ai/bast/internal/auth"
+	"github.com/bastio-ai/bast/internal/config"
+	"github.com/bastio-ai/bast/internal/shell"
+	"github.com/bastio-ai/bast/internal/tools"
+)
+
+var onboardCmd = &cobra.Command{
+	Use:   "onboard",
+	Short: "Write an onboarding guide for the current repository",
+	Long: `Explore the current repository with a read-only agent and write an
+onboarding guide: how to build, test, and run it, the key entry points,
+and the conventions the code follows.
+
+The agent can only list directories and read files; it cannot run
+commands or write anything. The guide is printed, then saved to
+ONBOARDING.md (or --output) once you confirm.
+
+Examples:
+  bast onboard
+  bast onboard --output docs/onboarding.md
+  bast onboard --yes                 # Save without asking`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: runOnboard,
+}
+
+var (
+	onboardOutputFlag string
+	onboardYesFlag    bool
+)
+
+func init() {
+	rootCmd.AddCommand(onboardCmd)
+	addQuietFlag(onboardCmd)
+	onboardCmd.Flags().StringVarP(&onboardOutputFlag, "output", "o", "ONBOARDING.md", "File to save the guide to")
+	onboardCmd.Flags().BoolVarP(&onboardYesFlag, "yes", "y", false, "Save the guide without asking")
+}
+
+// onboardMaxIterations gives the agent room to explore a whole repository
+const onboardMaxIterations = 25
+
+// onboardTask is the preset the onboarding agent runs
+const onboardTask = `Write an onboarding guide for a developer joining this repository.
+
+Explore first: list the top-level directory, read the README and build manifests (go.mod, package.json, Makefile, pyproject.toml, and the like), CI configuration, and the main entry points. Read a few representative source and test files to learn the conventions. You can only list and read; do not ask to run anything.
+
+Then reply with only the guide, as Markdown, with these sections:
+# Onboarding
+## What this is
+## Build
+## Test
+## Run
+## Layout (one line per important directory)
+## Key entry points (files and what starts there)
+## Conventions (naming, error handling, tests, formatting, commit style)
+## Where to start
+
+Give exact commands in code blocks, taken from the files you read. If something could not be determined, say so rather than guessing.`
+
+func runOnboard(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return withExitCode(ExitConfig, fmt.Errorf("failed to load config: %w", err))
+	}
+	if err := cfg.CheckFeature(config.FeatureAgent); err != nil {
+		return withExitCode(ExitPolicy, err)
+	}
+	provider, err := newExplainProvider()
+	if err != nil {
+		return err
+	}
+
+	shellCtx := shell.GetContext()
+
+	// Read-only tools, guarded by the same validators as the TUI agent
+	registry := tools.NewRegistry()
+	tools.RegisterReadOnlyBuiltins(registry, shellCtx.CWD)
+	validator, err := auth.NewSecurityValidator(cfg.Security)
+	if err != nil {
+		return withExitCode(ExitConfig, err)
+	}
+	registry.SetSecurityClient(validator)
+
+	status("Exploring %s (read-only)...\n", shellCtx.CWD)
+	agentCfg := ai.AgentConfig{
+		MaxIterations: onboardMaxIterations,
+		Registry:      registry,
+		OnToolCall: func(call ai.ToolCall) {
+			status("  %s %s\n", call.Name, string(call.Input))
+		},
+	}
+	result, err := provider.RunAgent(context.Background(), onboardTask, shellCtx, ai.ChatContext{}, agentCfg)
+	if err != nil {
+		return withExitCode(ExitProvider, fmt.Errorf("failed to explore the repository: %w", err))
+	}
+	guide := strings.TrimSpace(result.Response)
+	if guide == "" {
+		return fmt.Errorf("the agent finished without writing a guide")
+	}
+
+	status("\n")
+	fmt.Println(guide)
+	status("\n")
+	if result.Status == ai.AgentStatusPartial || result.Status == ai.AgentStatusFailed {
+		status("The agent reports the guide is incomplete (%s): %s\n\n", result.Status, result.Summary)
+	}
+
+	if !onboardYesFlag && !confirmSave(onboardOutputFlag) {
+		status("Not saved.\n")
+		return nil
+	}
+	if err := os.WriteFile(onboardOutputFlag, []byte(guide+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to save guide: %w", err)
+	}
+	status("Saved to %s\n", onboardOutputFlag)
+	return nil
+}
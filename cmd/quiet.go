@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+)
+
+// quietFlag is set by --quiet/-q on commands meant for scripting
+var quietFlag bool
+
+// addQuietFlag registers --quiet/-q on a scripting command. It is not a
+// persistent root flag because "run" already uses -q for --query.
+func addQuietFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&quietFlag, "quiet", "q", false, "print only the result, without headers, decorations, or emoji")
+}
+
+// status prints progress and decoration text unless --quiet is set
+func status(format string, args ...any) {
+	if quietFlag {
+		return
+	}
+	fmt.Printf(format, args...)
+}
+
+// printResult prints an essential result line, stripped of emoji under --quiet
+func printResult(text string) {
+	if quietFlag {
+		text = stripDecorations(text)
+	}
+	fmt.Println(text)
+}
+
+// printCommand prints a generated command exactly as it was generated.
+// Commands are never stripped, even under --quiet: an emoji inside a quoted
+// argument is part of the command.
+func printCommand(command string) {
+	fmt.Println(command)
+}
+
+// stripDecorations removes emoji and pictographs along with the space they leave
+func stripDecorations(s string) string {
+	var b strings.Builder
+	dropped := false
+	for _, r := range s {
+		if isDecoration(r) {
+			dropped = true
+			continue
+		}
+		// Skip the space that separated a dropped emoji from the text
+		if dropped && r == ' ' {
+			dropped = false
+			continue
+		}
+		dropped = false
+		b.WriteRune(r)
+	}
+
+	lines := strings.Split(b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// isDecoration reports whether r is an emoji or part of an emoji sequence
+func isDecoration(r rune) bool {
+	// Symbols (So) cover emoji; variation selectors and zero-width joiners glue them together
+	return unicode.Is(unicode.So, r) || r == '\uFE0F' || r == '\u200D'
+}
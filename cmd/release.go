@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bastio-ai/bast/internal/config"
+	"github.com/bastio-ai/bast/internal/git"
+	"github.com/bastio-ai/bast/internal/safety"
+	"github.com/bastio-ai/bast/internal/shell"
+)
+
+var releaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Tag and push a release, one confirmed step at a time",
+	Long: `Prepare and publish a release of the current repository.
+
+bast release checks that the working tree is clean and, when
+release.ci_check is configured, that CI passed. It then drafts release
+notes from the commits since the latest tag and proposes the next
+semantic version: major for breaking changes, minor for features, patch
+otherwise. Tagging and pushing are shown as commands and each runs only
+after you confirm it, with the same danger and credential checks as
+generated commands.
+
+Examples:
+  bast release
+  bast release --version v2.0.0
+  bast release --remote upstream`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: runRelease,
+}
+
+var (
+	releaseVersionFlag string
+	releaseRemoteFlag  string
+)
+
+func init() {
+	rootCmd.AddCommand(releaseCmd)
+	releaseCmd.Flags().StringVar(&releaseVersionFlag, "version", "", "Tag to create instead of the proposed one")
+	releaseCmd.Flags().StringVar(&releaseRemoteFlag, "remote", "origin", "Remote to push the tag to")
+}
+
+func runRelease(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return withExitCode(ExitConfig, fmt.Errorf("failed to load config: %w", err))
+	}
+	shellCtx := shell.GetContext()
+	cwd := shellCtx.CWD
+
+	// 1. Clean tree
+	untracked, err := checkReleaseTree(cwd)
+	if err != nil {
+		return err
+	}
+	fmt.Println("✓ Working tree is clean")
+	if untracked {
+		fmt.Println("  (untracked files are present and will not be part of the release)")
+	}
+
+	// 2. CI status
+	if check := cfg.Release.CICheck; check != "" {
+		fmt.Printf("Checking CI: %s\n", check)
+		ci := exec.Command("sh", "-c", check)
+		ci.Dir = cwd
+		ci.Stdout, ci.Stderr = os.Stdout, os.Stderr
+		if err := ci.Run(); err != nil {
+			return fmt.Errorf("CI check failed (%v); fix CI or the release.ci_check command first", err)
+		}
+		fmt.Println("✓ CI passed")
+	} else {
+		fmt.Println("- CI not checked (set release.ci_check to verify it)")
+	}
+
+	// 3. Release notes from the commits since the latest tag
+	latest := git.LatestTag(cwd)
+	commits, err := git.CommitsSince(cwd, latest)
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		fmt.Printf("Nothing to release: no commits since %s.\n", latest)
+		return nil
+	}
+
+	provider, err := newExplainProvider()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Drafting notes from %d commits...\n\n", len(commits))
+	notes, err := provider.GenerateChangelog(context.Background(), changelogCommits(commits))
+	if err != nil {
+		return withExitCode(ExitProvider, err)
+	}
+
+	// 4. Next version
+	version := releaseVersionFlag
+	if version == "" {
+		version, err = git.NextVersion(latest, releaseBump(notes))
+		if err != nil {
+			return withExitCode(ExitUsage, fmt.Errorf("%w; pass --version", err))
+		}
+	}
+	if strings.HasPrefix(version, "-") || strings.HasPrefix(releaseRemoteFlag, "-") {
+		return withExitCode(ExitUsage, fmt.Errorf("version and remote can't start with '-'"))
+	}
+	from := latest
+	if from == "" {
+		from = "no previous tag"
+	}
+	fmt.Printf("Release %s (from %s)\n\n%s\n\n", version, from, strings.TrimSpace(notes))
+
+	// 5. Tag and push, each confirmed
+	notesFile, err := os.CreateTemp("", "bast-release-*.md")
+	if err != nil {
+		return fmt.Errorf("failed to write release notes: %w", err)
+	}
+	defer os.Remove(notesFile.Name())
+	if _, err := notesFile.WriteString(version + "\n\n" + notes + "\n"); err != nil {
+		return fmt.Errorf("failed to write release notes: %w", err)
+	}
+	notesFile.Close()
+
+	// Each step runs without a shell, so a version or remote can't inject commands
+	steps := [][]string{
+		{"git", "tag", "-a", version, "-F", notesFile.Name()},
+		{"git", "push", releaseRemoteFlag, version},
+	}
+	for i, step := range steps {
+		ran, err := runReleaseStep(cwd, step)
+		if err != nil {
+			return err
+		}
+		if !ran {
+			fmt.Println("Stopped. Remaining steps:")
+			for _, rest := range steps[i:] {
+				fmt.Printf("  %s\n", shell.JoinArgs(rest))
+			}
+			return nil
+		}
+	}
+	fmt.Printf("✓ Released %s\n", version)
+	return nil
+}
+
+// checkReleaseTree fails unless cwd is a git work tree with no changes to
+// tracked files. Any git failure aborts the release rather than passing as
+// clean. Reports whether untracked files are present.
+func checkReleaseTree(cwd string) (untracked bool, err error) {
+	dirty, untracked, err := git.WorkTreeStatus(cwd)
+	if err != nil {
+		return false, withExitCode(ExitUsage, fmt.Errorf("can't check the working tree: %w", err))
+	}
+	if dirty {
+		return false, withExitCode(ExitUsage, fmt.Errorf("working tree has uncommitted changes; commit or stash them first"))
+	}
+	return untracked, nil
+}
+
+// releaseBump picks the version bump from the drafted notes' headings
+func releaseBump(notes string) git.Bump {
+	switch {
+	case strings.Contains(notes, "### Breaking Changes"):
+		return git.BumpMajor
+	case strings.Contains(notes, "### Features"):
+		return git.BumpMinor
+	default:
+		return git.BumpPatch
+	}
+}
+
+// runReleaseStep shows a command with the same warnings as generated
+// commands and runs it once confirmed. Commands carrying credentials are
+// refused. Reports whether the step ran.
+func runReleaseStep(cwd string, args []string) (bool, error) {
+	command := shell.JoinArgs(args)
+	fmt.Printf("\n$ %s\n", command)
+	if kinds := safety.DetectSecrets(command); len(kinds) > 0 {
+		return false, withExitCode(ExitPolicy, fmt.Errorf("refusing to run a command containing a credential (%s)", strings.Join(kinds, ", ")))
+	}
+	if safety.IsDangerousCommand(command) {
+		fmt.Println("⚠️  WARNING: This command may be destructive!")
+	}
+	if safety.NeedsSudo(command) {
+		fmt.Println("This command needs elevated privileges (sudo).")
+	}
+	if !confirm("Run it? [y/N]: ") {
+		return false, nil
+	}
+
+	step := exec.Command(args[0], args[1:]...)
+	step.Dir = cwd
+	step.Stdin, step.Stdout, step.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := step.Run(); err != nil {
+		return false, fmt.Errorf("%s failed: %w", command, err)
+	}
+	return true, nil
+}
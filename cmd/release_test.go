@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/bastio-ai/bast/internal/git"
+)
+
+// initRepo creates a git repository with one commit in a temporary directory
+func initRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return dir
+}
+
+func TestCheckReleaseTree(t *testing.T) {
+	t.Run("clean", func(t *testing.T) {
+		dir := initRepo(t)
+		if untracked, err := checkReleaseTree(dir); err != nil || untracked {
+			t.Errorf("checkReleaseTree() = %v, %v; want clean", untracked, err)
+		}
+	})
+
+	t.Run("untracked files only", func(t *testing.T) {
+		dir := initRepo(t)
+		if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if untracked, err := checkReleaseTree(dir); err != nil || !untracked {
+			t.Errorf("checkReleaseTree() = %v, %v; want untracked and no error", untracked, err)
+		}
+	})
+
+	t.Run("dirty", func(t *testing.T) {
+		dir := initRepo(t)
+		path := filepath.Join(dir, "main.go")
+		if err := os.WriteFile(path, []byte("package main\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if out, err := exec.Command("git", "-C", dir, "add", "main.go").CombinedOutput(); err != nil {
+			t.Fatalf("git add: %v\n%s", err, out)
+		}
+		_, err := checkReleaseTree(dir)
+		if err == nil {
+			t.Fatal("checkReleaseTree() should reject staged changes")
+		}
+		if code := exitCode(err); code != ExitUsage {
+			t.Errorf("exit code = %d, want %d", code, ExitUsage)
+		}
+	})
+
+	t.Run("not a repository", func(t *testing.T) {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not installed")
+		}
+		t.Setenv("GIT_CEILING_DIRECTORIES", os.TempDir())
+		if _, err := checkReleaseTree(t.TempDir()); err == nil {
+			t.Error("checkReleaseTree() should fail outside a repository")
+		}
+	})
+}
+
+func TestReleaseBump(t *testing.T) {
+	tests := []struct {
+		name  string
+		notes string
+		want  git.Bump
+	}{
+		{"breaking changes", "### Breaking Changes\n- drop v1 API\n\n### Features\n- add x\n", git.BumpMajor},
+		{"features", "### Features\n- add x\n\n### Fixes\n- fix y\n", git.BumpMinor},
+		{"fixes only", "### Fixes\n- fix y\n", git.BumpPatch},
+		{"no headings", "- tidy up\n", git.BumpPatch},
+	}
+	for _, tt := range tests {
+		if got := releaseBump(tt.notes); got != tt.want {
+			t.Errorf("%s: releaseBump() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -1,26 +1,108 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
+
+	"github.com/bastio-ai/bast/internal/auth"
+	"github.com/bastio-ai/bast/internal/config"
+	"github.com/bastio-ai/bast/internal/debug"
+	"github.com/bastio-ai/bast/internal/files"
+	"github.com/bastio-ai/bast/internal/git"
+	"github.com/bastio-ai/bast/internal/shell"
+	"github.com/bastio-ai/bast/internal/useragent"
+)
+
+var (
+	debugFlag     bool
+	dirFlag       string
+	noContextFlag []string
+
+	// launchDir is the directory bast was started in, before --dir applies.
+	// Commands handed back to the shell run from here.
+	launchDir string
 )
 
 var rootCmd = &cobra.Command{
-	Use:   "bast",
+	Use:   "bast [-- query...]",
 	Short: "AI Shell Assistant",
 	Long: `bast is an AI-powered shell assistant that generates shell commands
 using natural language. It integrates with your shell to provide
-contextual command suggestions.`,
+contextual command suggestions.
+
+Pass a query after -- to skip the input screen:
+
+  bast -- find files larger than 100MB`,
+	Args: cobra.ArbitraryArgs,
+	// Queries are free text, so don't complete file names after --
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: runInline,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		launchDir, _ = os.Getwd()
+		if dirFlag != "" {
+			if _, err := shell.ChangeDir(dirFlag); err != nil {
+				return withExitCode(ExitUsage, err)
+			}
+		}
+
+		if err := shell.Configure(noContextFlag); err != nil {
+			return withExitCode(ExitUsage, err)
+		}
+
+		cfg, cfgErr := config.Load()
+		if cfgErr == nil {
+			git.Configure(git.Depth(cfg.Git.Context), cfg.Git.Budget)
+			files.Configure(cfg.Files.SlowMountThreshold)
+			auth.Configure(cfg.Bastio)
+			if err := shell.Configure(append(noContextFlag, cfg.Context.Disable...)); err != nil {
+				return withExitCode(ExitConfig, fmt.Errorf("context.disable: %w", err))
+			}
+		}
+
+		enabled := debugFlag || (cfgErr == nil && cfg.Debug)
+		if enabled {
+			if err := debug.Enable(""); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to enable debug log: %v\n", err)
+			}
+		}
+		return nil
+	},
+}
+
+// runInline launches the TUI with the query given after --
+func runInline(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return cmd.Help()
+	}
+	// Without --, a stray word is more likely a mistyped subcommand than a query
+	if cmd.ArgsLenAtDash() != 0 {
+		return withExitCode(ExitUsage, fmt.Errorf("unknown command %q for %q\nRun 'bast -- %s' to use it as a query",
+			args[0], cmd.CommandPath(), strings.Join(args, " ")))
+	}
+	queryFlag = strings.Join(args, " ")
+	return runTUI(cmd, args)
 }
 
 func Execute() {
+	useragent.SetVersion(Version)
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
 func init() {
 	// Global flags can be added here
 	rootCmd.PersistentFlags().StringP("config", "c", "", "config file path")
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return withExitCode(ExitUsage, err)
+	})
+	rootCmd.PersistentFlags().BoolVar(&debugFlag, "debug", false, "write redacted HTTP traces to the debug log")
+	rootCmd.PersistentFlags().StringVarP(&dirFlag, "dir", "C", "", "work in this directory instead of the current one")
+	rootCmd.PersistentFlags().StringSliceVar(&noContextFlag, "no-context", nil,
+		"context sources not to send: "+strings.Join(shell.Sources, ", "))
 }
@@ -9,32 +9,46 @@ import (
 	"github.com/bastio-ai/bast/internal/ai"
 	"github.com/bastio-ai/bast/internal/auth"
 	"github.com/bastio-ai/bast/internal/config"
+	"github.com/bastio-ai/bast/internal/notify"
+	"github.com/bastio-ai/bast/internal/shell"
 	"github.com/bastio-ai/bast/internal/tui"
 )
 
 var (
 	queryFlag      string
 	outputFileFlag string
+	handoffFlag    int
+	modeFlag       string
 )
 
 var runCmd = &cobra.Command{
 	Use:   "run",
 	Short: "Launch the bast TUI",
-	Long:  `Launch the interactive TUI to generate shell commands using AI.`,
-	RunE:  runTUI,
+	Long: `Launch the interactive TUI to generate shell commands using AI.
+
+By default each query is classified as a command request, a question, or an
+agent task. --mode skips classification and sends every query to one of them.`,
+	RunE: runTUI,
 }
 
 func init() {
 	rootCmd.AddCommand(runCmd)
 	runCmd.Flags().StringVarP(&queryFlag, "query", "q", "", "Initial query to process")
 	runCmd.Flags().StringVar(&outputFileFlag, "output-file", "", "Write output to file (for shell integration)")
+	runCmd.Flags().StringVar(&modeFlag, "mode", "auto", "Route queries to: auto (classify), command, chat, or agent")
+	runCmd.Flags().IntVar(&handoffFlag, "handoff-version", shell.HandoffV1, "Output format version (1: BAST_COMMAND line, 2: JSON with metadata)")
 }
 
 func runTUI(cmd *cobra.Command, args []string) error {
+	intent, err := parseRunMode(modeFlag)
+	if err != nil {
+		return withExitCode(ExitUsage, err)
+	}
+
 	// Load config
 	cfg, err := config.Load()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return withExitCode(ExitConfig, fmt.Errorf("failed to load config: %w", err))
 	}
 
 	// Resolve credentials based on gateway mode
@@ -42,7 +56,7 @@ func runTUI(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		// Print user-friendly instructions and return the error
 		fmt.Println(auth.FormatSetupInstructions(err))
-		return err
+		return withExitCode(ExitConfig, err)
 	}
 
 	// Create provider
@@ -50,16 +64,57 @@ func runTUI(cmd *cobra.Command, args []string) error {
 
 	// Create and run TUI
 	model := tui.NewModel(provider, queryFlag, outputFileFlag)
-	p := tea.NewProgram(model, tea.WithAltScreen())
+	model.SetIntentThreshold(cfg.IntentThreshold)
+	model.SetForcedIntent(intent)
+	model.SetPrewarm(cfg.Prewarm)
+	model.SetFallbackModel(cfg.FallbackModel, cfg.Model)
+	model.SetHandoffVersion(handoffFlag)
+	model.SetSudoPolicy(cfg.Sudo)
+	model.SetSafeRewrite(cfg.SafeRewrite)
+	model.SetLaunchDir(launchDir)
+	model.SetFeedbackSharing(cfg.Feedback.Share)
+	model.SetSessionSaving(cfg.Sessions.Save)
+	model.SetProbes(cfg.Context.Probes)
+	model.SetHyperlinks(cfg.Hyperlinks, cfg.EditorURL)
+	model.SetEditor(cfg.Editor)
+	model.SetToolOutputLines(cfg.ToolOutput.PromptLines, cfg.ToolOutput.DisplayLines)
+	notifier, err := notify.New(cfg.Notify, notify.TerminalCanRing())
+	if err != nil {
+		return withExitCode(ExitConfig, err)
+	}
+	model.SetNotifier(notifier)
+	model.SetInline(cfg.TUI.Inline)
+	gateway := tui.GatewayDirect
+	if providerCfg.BaseURL != "" {
+		gateway = tui.GatewayBastio
+	}
+	model.SetSessionInfo(cfg.Model, gateway, cfg.Mode)
+	var opts []tea.ProgramOption
+	if !cfg.TUI.Inline {
+		opts = append(opts, tea.WithAltScreen())
+	}
+	p := tea.NewProgram(model, opts...)
 
 	finalModel, err := p.Run()
 	if err != nil {
 		return fmt.Errorf("TUI error: %w", err)
 	}
 
-	// The TUI prints BAST_COMMAND:xxx when a command is selected
+	// The TUI prints BAST_COMMAND:xxx (or v2 JSON) when a command is selected
 	// The shell hook parses this to insert the command
 	_ = finalModel
 
 	return nil
 }
+
+// parseRunMode maps a --mode value to the intent queries are forced to,
+// or no intent for automatic classification
+func parseRunMode(mode string) (ai.Intent, error) {
+	switch mode {
+	case "", "auto":
+		return "", nil
+	case string(ai.IntentCommand), string(ai.IntentChat), string(ai.IntentAgent):
+		return ai.Intent(mode), nil
+	}
+	return "", fmt.Errorf("invalid mode %q (expected auto, command, chat, or agent)", mode)
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bastio-ai/bast/internal/config"
+	"github.com/bastio-ai/bast/internal/safety"
+	"github.com/bastio-ai/bast/internal/session"
+)
+
+// uploadTimeout bounds uploading a shared transcript
+const uploadTimeout = 30 * time.Second
+
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "List and share saved chat and agent sessions",
+}
+
+var sessionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved sessions, most recent first",
+	Args:  usageArgs(cobra.NoArgs),
+	RunE:  runSessionsList,
+}
+
+var sessionsShareCmd = &cobra.Command{
+	Use:   "share <id>",
+	Short: "Print or upload a redacted transcript of a session",
+	Long: `Render a saved session as a Markdown transcript that is safe to show a
+teammate: credentials are replaced with [REDACTED:<kind>] markers, and your
+username, hostname, and home directory with bast-user, bast-host, and
+/home/bast-user. The ID is the one in the status bar; a unique prefix is
+enough.
+
+Without --upload the transcript is printed. With --upload it is posted to
+the paste service in sessions.paste_url, or else to a secret GitHub gist
+using GITHUB_TOKEN, and the URL is printed.
+
+Examples:
+  bast sessions share 3f2a9c1e > session.md
+  bast sessions share 3f2a9c1e --upload`,
+	Args: usageArgs(cobra.ExactArgs(1)),
+	RunE: runSessionsShare,
+}
+
+var (
+	sessionsUploadFlag bool
+	sessionsGistFlag   bool
+)
+
+func init() {
+	rootCmd.AddCommand(sessionsCmd)
+	sessionsCmd.AddCommand(sessionsListCmd)
+	sessionsCmd.AddCommand(sessionsShareCmd)
+	sessionsShareCmd.Flags().BoolVar(&sessionsUploadFlag, "upload", false, "Upload the transcript and print its URL")
+	sessionsShareCmd.Flags().BoolVar(&sessionsGistFlag, "gist", false, "Upload to a GitHub gist even when a paste service is configured")
+}
+
+func runSessionsList(cmd *cobra.Command, args []string) error {
+	dir, err := session.DefaultDir()
+	if err != nil {
+		return err
+	}
+	sessions := session.List(dir)
+	if len(sessions) == 0 {
+		fmt.Println("No saved sessions yet. Set sessions.save: true in your config to save chat and agent sessions.")
+		return nil
+	}
+	for _, s := range sessions {
+		fmt.Printf("%s  %s  %2d turns  %s\n", session.ShortID(s.ID), s.Updated.Local().Format("2006-01-02 15:04"), len(s.Turns), s.Title())
+	}
+	return nil
+}
+
+func runSessionsShare(cmd *cobra.Command, args []string) error {
+	dir, err := session.DefaultDir()
+	if err != nil {
+		return err
+	}
+	s, err := session.Load(dir, args[0])
+	if err != nil {
+		return withExitCode(ExitUsage, err)
+	}
+	markdown := session.Markdown(s, safety.CurrentAnonymizer())
+
+	if !sessionsUploadFlag && !sessionsGistFlag {
+		fmt.Print(markdown)
+		return nil
+	}
+
+	var pasteURL string
+	if cfg, err := config.Load(); err == nil {
+		pasteURL = cfg.Sessions.PasteURL
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), uploadTimeout)
+	defer cancel()
+
+	var url string
+	if pasteURL != "" && !sessionsGistFlag {
+		url, err = session.UploadPaste(ctx, pasteURL, markdown)
+	} else {
+		token := session.GitHubToken()
+		if token == "" {
+			return withExitCode(ExitConfig, fmt.Errorf("set GITHUB_TOKEN (with the gist scope) or sessions.paste_url to upload"))
+		}
+		// The description is public even on a secret gist, so it names only the ID
+		id := session.ShortID(s.ID)
+		url, err = session.UploadGist(ctx, token, "bast-session-"+id+".md", "bast session "+id, markdown)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Uploaded a redacted transcript of the session.")
+	fmt.Println(url)
+	return nil
+}
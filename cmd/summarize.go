@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bastio-ai/bast/internal/ai"
+	"github.com/bastio-ai/bast/internal/files"
+	"github.com/bastio-ai/bast/internal/shell"
+)
+
+var summarizeCmd = &cobra.Command{
+	Use:   "summarize [path]",
+	Short: "Summarize a file, or map a directory",
+	Long: `Summarize a file, or map a directory you just landed in.
+
+For a file, prints a short summary of what it is for and its main parts.
+For a directory (default: the current one), lists the purpose of each
+top-level directory and the key files to read first. Only the listing and
+key files (READMEs, manifests, entry points) are read, within the same
+limits and safety checks as @mentions.
+
+Examples:
+  bast summarize                 # Map the current directory
+  bast summarize internal/       # Map a subdirectory
+  bast summarize cmd/root.go     # Summarize a file`,
+	Args: usageArgs(cobra.MaximumNArgs(1)),
+	RunE: runSummarize,
+}
+
+func init() {
+	rootCmd.AddCommand(summarizeCmd)
+	addQuietFlag(summarizeCmd)
+}
+
+func runSummarize(cmd *cobra.Command, args []string) error {
+	path := "."
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	shellCtx := shell.GetContext()
+	info, err := os.Stat(absPath(shellCtx.CWD, path))
+	if err != nil {
+		return withExitCode(ExitUsage, fmt.Errorf("cannot summarize %s: %w", path, err))
+	}
+
+	provider, err := newExplainProvider()
+	if err != nil {
+		return err
+	}
+
+	var result *ai.ChatResult
+	ctx := context.Background()
+	if info.IsDir() {
+		result, err = summarizeDir(ctx, provider, shellCtx, path)
+	} else {
+		result, err = summarizeFile(ctx, provider, shellCtx, path)
+	}
+	if err != nil {
+		return err
+	}
+
+	printResult(result.Response)
+	return nil
+}
+
+// summarizeDir surveys a directory and asks for a map of it
+func summarizeDir(ctx context.Context, provider *ai.AnthropicProvider, shellCtx ai.ShellContext, path string) (*ai.ChatResult, error) {
+	if !insideDir(shellCtx.CWD, path) {
+		return nil, withExitCode(ExitUsage, fmt.Errorf("cannot summarize %s: path outside working directory", path))
+	}
+	survey, err := files.SurveyDir(shellCtx.CWD, path)
+	if err != nil {
+		return nil, withExitCode(ExitUsage, fmt.Errorf("cannot summarize %s: %w", path, err))
+	}
+
+	status("Mapping %s (%d entries, %d key files)\n\n", path, len(survey.Entries)+survey.Omitted, len(survey.KeyFiles))
+	result, err := provider.SummarizeDir(ctx, survey, shellCtx)
+	if err != nil {
+		return nil, withExitCode(ExitProvider, err)
+	}
+	return result, nil
+}
+
+// summarizeFile reads a file through the same safety checks as @mentions
+// and asks for a summary of it
+func summarizeFile(ctx context.Context, provider *ai.AnthropicProvider, shellCtx ai.ShellContext, path string) (*ai.ChatResult, error) {
+	contents := files.ReadFiles(shellCtx.CWD, []string{path}, files.MaxSingleFileBytes)
+	if len(contents) == 0 {
+		return nil, withExitCode(ExitUsage, fmt.Errorf("failed to read %s", path))
+	}
+	file := contents[0]
+	if file.Error != "" {
+		return nil, withExitCode(ExitUsage, fmt.Errorf("cannot summarize %s: %s", path, file.Error))
+	}
+
+	status("Summarizing %s\n\n", file.Path)
+	result, err := provider.SummarizeFile(ctx, file, shellCtx)
+	if err != nil {
+		return nil, withExitCode(ExitProvider, err)
+	}
+	return result, nil
+}
+
+// insideDir reports whether path resolves to cwd or a directory below it
+func insideDir(cwd, path string) bool {
+	rel, err := filepath.Rel(cwd, absPath(cwd, path))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bastio-ai/bast/internal/config"
+	"github.com/bastio-ai/bast/internal/tools"
+)
+
+var toolsCmd = &cobra.Command{
+	Use:   "tools",
+	Short: "Inspect the tools available in agentic mode",
+}
+
+var toolsSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Show the definitions of every agent tool",
+	Long: `List every tool the agent can call in this install: the builtins, the
+default plugins shipped with bast, and your plugins from ~/.config/bast/tools.
+
+With --json the definitions are printed as JSON, each with a standalone
+JSON Schema (draft 2020-12) for its input, so policy editors and
+documentation generators can consume the exact tool surface.
+
+Examples:
+  bast tools schema
+  bast tools schema --json > tools.json`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: runToolsSchema,
+}
+
+var toolsSchemaJSONFlag bool
+
+func init() {
+	rootCmd.AddCommand(toolsCmd)
+	toolsCmd.AddCommand(toolsSchemaCmd)
+	toolsSchemaCmd.Flags().BoolVar(&toolsSchemaJSONFlag, "json", false, "Print the definitions as JSON Schema")
+}
+
+func runToolsSchema(cmd *cobra.Command, args []string) error {
+	userPlugins := true
+	if cfg, err := config.Load(); err == nil {
+		userPlugins = cfg.FeatureEnabled(config.FeaturePlugins)
+	}
+	registry := tools.NewRegistry()
+	cwd, _ := os.Getwd()
+	tools.RegisterAgentTools(registry, cwd, userPlugins)
+	export := registry.ExportSchema(Version)
+
+	if toolsSchemaJSONFlag {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(export)
+	}
+
+	for _, t := range export.Tools {
+		fmt.Printf("%s (%s)\n", t.Name, t.Source)
+		fmt.Printf("  %s\n", t.Description)
+
+		names := make([]string, 0, len(t.InputSchema.Properties))
+		for name := range t.InputSchema.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			p := t.InputSchema.Properties[name]
+			opt := ""
+			if !slices.Contains(t.InputSchema.Required, name) {
+				opt = ", optional"
+			}
+			fmt.Printf("  - %s (%s%s): %s\n", name, p.Type, opt, p.Description)
+		}
+		fmt.Println()
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package ai
+
+import (
+	"encoding/json"
+
+	"github.com/bastio-ai/bast/internal/files"
+	"github.com/bastio-ai/bast/internal/safety"
+)
+
+// Anonymization works on the values a prompt is built from rather than on
+// the request, so what bast sends is decided here and not by where the
+// user's name happens to appear in the JSON. Every method takes a nil
+// anonymizer to mean anonymization is off.
+
+// anonymizeAll anonymizes each string in values, returning a new slice
+func anonymizeAll(a *safety.Anonymizer, values []string) []string {
+	if a == nil || values == nil {
+		return values
+	}
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = a.Anonymize(v)
+	}
+	return out
+}
+
+// restoreAll restores each string in values in place
+func restoreAll(a *safety.Anonymizer, values []string) {
+	for i, v := range values {
+		values[i] = a.Restore(v)
+	}
+}
+
+// anonymizeFiles anonymizes the paths and contents of files
+func anonymizeFiles(a *safety.Anonymizer, fs []files.FileContent) []files.FileContent {
+	if a == nil || fs == nil {
+		return fs
+	}
+	out := make([]files.FileContent, len(fs))
+	for i, f := range fs {
+		out[i] = anonymizeFile(a, f)
+	}
+	return out
+}
+
+// anonymizeFile anonymizes a file's path, content, and read error
+func anonymizeFile(a *safety.Anonymizer, f files.FileContent) files.FileContent {
+	f.Path = a.Anonymize(f.Path)
+	f.Content = a.Anonymize(f.Content)
+	f.Error = a.Anonymize(f.Error)
+	return f
+}
+
+// anonymized returns a copy of c with the username, hostname, and home
+// directory replaced in every field that can hold them
+func (c ShellContext) anonymized(a *safety.Anonymizer) ShellContext {
+	if a == nil {
+		return c
+	}
+	c.CWD = a.Anonymize(c.CWD)
+	c.LastCommand = a.Anonymize(c.LastCommand)
+	c.LastOutput = a.Anonymize(c.LastOutput)
+	c.LastError = a.Anonymize(c.LastError)
+	c.User = a.Anonymize(c.User)
+	c.History = anonymizeAll(a, c.History)
+	c.Project = a.Anonymize(c.Project)
+	c.Files = anonymizeFiles(a, c.Files)
+
+	if c.Git != nil {
+		git := *c.Git
+		git.Branch = a.Anonymize(git.Branch)
+		git.Worktree = a.Anonymize(git.Worktree)
+		git.Summary = a.Anonymize(git.Summary)
+		git.DirtySubmodules = anonymizeAll(a, git.DirtySubmodules)
+		c.Git = &git
+	}
+	if c.Direnv != nil {
+		direnv := *c.Direnv
+		direnv.Path = a.Anonymize(direnv.Path)
+		direnv.Uses = anonymizeAll(a, direnv.Uses)
+		c.Direnv = &direnv
+	}
+	if c.Workspace != nil {
+		workspace := *c.Workspace
+		workspace.Root = a.Anonymize(workspace.Root)
+		c.Workspace = &workspace
+	}
+	if c.Pinned != nil {
+		pinned := make([]PinnedItem, len(c.Pinned))
+		for i, p := range c.Pinned {
+			pinned[i] = PinnedItem{Label: a.Anonymize(p.Label), Content: a.Anonymize(p.Content)}
+		}
+		c.Pinned = pinned
+	}
+	if c.Aliases != nil {
+		aliases := make([]ShellAlias, len(c.Aliases))
+		for i, alias := range c.Aliases {
+			alias.Expansion = a.Anonymize(alias.Expansion)
+			aliases[i] = alias
+		}
+		c.Aliases = aliases
+	}
+	if c.Runtimes != nil {
+		runtimes := make([]RuntimeEnv, len(c.Runtimes))
+		for i, env := range c.Runtimes {
+			env.Name = a.Anonymize(env.Name)
+			env.Current = a.Anonymize(env.Current)
+			env.Activate = a.Anonymize(env.Activate)
+			env.Bin = a.Anonymize(env.Bin)
+			runtimes[i] = env
+		}
+		c.Runtimes = runtimes
+	}
+	if c.Examples != nil {
+		examples := make([]CommandExample, len(c.Examples))
+		for i, ex := range c.Examples {
+			examples[i] = CommandExample{Query: a.Anonymize(ex.Query), Command: a.Anonymize(ex.Command)}
+		}
+		c.Examples = examples
+	}
+	if c.Probes != nil {
+		probes := make([]ProbeReading, len(c.Probes))
+		for i, probe := range c.Probes {
+			probes[i] = ProbeReading{Name: probe.Name, Value: a.Anonymize(probe.Value)}
+		}
+		c.Probes = probes
+	}
+	return c
+}
+
+// anonymized returns a copy of c with mentioned files and earlier turns
+// anonymized
+func (c ChatContext) anonymized(a *safety.Anonymizer) ChatContext {
+	if a == nil {
+		return c
+	}
+	c.Files = anonymizeFiles(a, c.Files)
+	if c.History != nil {
+		history := make([]ConversationMessage, len(c.History))
+		for i, msg := range c.History {
+			history[i] = ConversationMessage{Role: msg.Role, Content: a.Anonymize(msg.Content)}
+		}
+		c.History = history
+	}
+	return c
+}
+
+// restoreJSON puts the real identity back into a JSON document the model
+// wrote, so tools run against real paths
+func restoreJSON(a *safety.Anonymizer, data json.RawMessage) json.RawMessage {
+	if a == nil || data == nil {
+		return data
+	}
+	return json.RawMessage(a.Restore(string(data)))
+}
+
+// restore puts the real identity back into a generated command
+func (r *CommandResult) restore(a *safety.Anonymizer) {
+	if r == nil || a == nil {
+		return
+	}
+	r.Command = a.Restore(r.Command)
+	r.Explanation = a.Restore(r.Explanation)
+	r.WorkingDir = a.Restore(r.WorkingDir)
+	for i := range r.Alternatives {
+		r.Alternatives[i].Command = a.Restore(r.Alternatives[i].Command)
+		r.Alternatives[i].WorkingDir = a.Restore(r.Alternatives[i].WorkingDir)
+	}
+}
+
+// restore puts the real identity back into a suggested fix
+func (r *FixResult) restore(a *safety.Anonymizer) {
+	if r == nil || a == nil {
+		return
+	}
+	r.FixedCommand = a.Restore(r.FixedCommand)
+	r.Explanation = a.Restore(r.Explanation)
+}
+
+// restore puts the real identity back into an answer
+func (r *ChatResult) restore(a *safety.Anonymizer) {
+	if r == nil || a == nil {
+		return
+	}
+	r.Response = a.Restore(r.Response)
+	restoreAll(a, r.FollowUps)
+}
+
+// restore puts the real identity back into an agent's answer. Tool calls
+// already hold real paths, since their input is restored before they run.
+func (r *AgentResult) restore(a *safety.Anonymizer) {
+	if r == nil || a == nil {
+		return
+	}
+	r.Response = a.Restore(r.Response)
+	r.Summary = a.Restore(r.Summary)
+	restoreAll(a, r.FollowUps)
+}
@@ -0,0 +1,113 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bastio-ai/bast/internal/files"
+	"github.com/bastio-ai/bast/internal/safety"
+	"github.com/bastio-ai/bast/internal/tools"
+)
+
+func TestShellContextAnonymized(t *testing.T) {
+	anon := safety.NewAnonymizer("alice", "devbox", "/srv/alice")
+	ctx := ShellContext{
+		CWD:         "/srv/alice/app",
+		LastCommand: "ssh devbox",
+		User:        "alice",
+		History:     []string{"cd /srv/alice/app"},
+		Git:         &GitContext{IsRepo: true, Branch: "alice/fix", Summary: "branch alice/fix"},
+		Direnv:      &DirenvContext{Path: "/srv/alice/app/.envrc"},
+		Aliases:     []ShellAlias{{Name: "app", Expansion: "cd /srv/alice/app"}},
+		Runtimes:    []RuntimeEnv{{Kind: RuntimeVirtualenv, Name: "/srv/alice/app/.venv"}},
+		Workspace:   &WorkspaceContext{Root: "/srv/alice/app"},
+		Pinned:      []PinnedItem{{Label: "/srv/alice/notes.md", Content: "ask alice"}},
+		Files:       []files.FileContent{{Path: "/srv/alice/app/main.go", Content: "// by alice"}},
+	}
+
+	got := ctx.anonymized(anon)
+	data, _ := json.Marshal(got)
+	if strings.Contains(string(data), "alice") || strings.Contains(string(data), "devbox") {
+		t.Errorf("anonymized context still identifies the user: %s", data)
+	}
+	if ctx.Git.Branch != "alice/fix" || ctx.Aliases[0].Expansion != "cd /srv/alice/app" || ctx.Files[0].Path != "/srv/alice/app/main.go" {
+		t.Error("anonymizing changed the original context")
+	}
+	if same := ctx.anonymized(nil); same.CWD != ctx.CWD {
+		t.Errorf("nil anonymizer changed CWD to %q", same.CWD)
+	}
+}
+
+// pathTool records the path it was asked for and echoes it
+type pathTool struct {
+	path string
+}
+
+func (t *pathTool) Name() string                   { return "list_directory" }
+func (t *pathTool) Description() string            { return "lists a directory" }
+func (t *pathTool) InputSchema() tools.InputSchema { return tools.InputSchema{Type: "object"} }
+func (t *pathTool) Execute(ctx context.Context, input json.RawMessage) (*tools.Result, error) {
+	var args struct {
+		Path string `json:"path"`
+	}
+	json.Unmarshal(input, &args)
+	t.path = args.Path
+	return &tools.Result{Output: "listing of " + args.Path}, nil
+}
+
+// TestRunAgentAnonymizes checks that requests never carry the user's
+// identity, that tools run on real paths, and that the answer is restored
+func TestRunAgentAnonymizes(t *testing.T) {
+	anon := safety.NewAnonymizer("alice", "", "/srv/alice")
+	home := anon.Anonymize("/srv/alice")
+
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+
+		w.Header().Set("Content-Type", "application/json")
+		usage := `"usage":{"input_tokens":10,"output_tokens":10}`
+		if len(bodies) == 1 {
+			fmt.Fprintf(w, `{"id":"msg_1","type":"message","role":"assistant","model":"test","stop_reason":"tool_use",
+				"content":[{"type":"tool_use","id":"toolu_1","name":"list_directory","input":{"path":"%s/app"}}],%s}`, home, usage)
+			return
+		}
+		fmt.Fprintf(w, `{"id":"msg_2","type":"message","role":"assistant","model":"test","stop_reason":"end_turn",
+			"content":[{"type":"text","text":"%s/app has one file."}],%s}`, home, usage)
+	}))
+	defer server.Close()
+
+	tool := &pathTool{}
+	registry := tools.NewRegistry()
+	registry.Register(tool)
+	p := NewAnthropicProviderWithConfig(ProviderConfig{APIKey: "key", Model: "test", BaseURL: server.URL})
+	p.anon = anon
+
+	shellCtx := ShellContext{CWD: "/srv/alice/app", User: "alice", History: []string{"ls /srv/alice"}}
+	result, err := p.RunAgent(context.Background(), "what is in /srv/alice/app?", shellCtx, ChatContext{}, AgentConfig{Registry: registry})
+	if err != nil {
+		t.Fatalf("RunAgent() error = %v", err)
+	}
+
+	for i, body := range bodies {
+		if strings.Contains(body, "alice") {
+			t.Errorf("request %d identifies the user: %s", i+1, body)
+		}
+	}
+	if tool.path != "/srv/alice/app" {
+		t.Errorf("tool ran on %q, want the real path", tool.path)
+	}
+	if result.Response != "/srv/alice/app has one file." {
+		t.Errorf("Response = %q, want the real path restored", result.Response)
+	}
+	if !strings.Contains(string(result.ToolCalls[0].Input), "/srv/alice/app") {
+		t.Errorf("tool call input = %s, want the real path", result.ToolCalls[0].Input)
+	}
+}
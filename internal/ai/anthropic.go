@@ -1,7 +1,6 @@
 package ai
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -14,7 +13,11 @@ import (
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/bastio-ai/bast/internal/debug"
+	"github.com/bastio-ai/bast/internal/files"
+	"github.com/bastio-ai/bast/internal/safety"
 	"github.com/bastio-ai/bast/internal/tools"
+	"github.com/bastio-ai/bast/internal/useragent"
 )
 
 // DefaultAPITimeout is the default timeout for API calls
@@ -22,8 +25,12 @@ const DefaultAPITimeout = 30 * time.Second
 
 // AnthropicProvider implements the Provider interface using Anthropic's Claude API
 type AnthropicProvider struct {
-	client anthropic.Client
-	model  anthropic.Model
+	client     anthropic.Client
+	model      anthropic.Model
+	baseURL    string
+	candidates int
+	usage      *usageMeter
+	anon       *safety.Anonymizer // Nil unless privacy.anonymize is on
 }
 
 // ProviderConfig holds configuration for creating an Anthropic provider
@@ -32,6 +39,15 @@ type ProviderConfig struct {
 	Model    string
 	BaseURL  string // Optional custom base URL (e.g., for Bastio gateway)
 	DeviceID string // Device ID for Bastio User-Agent header
+
+	// Candidates is how many alternative commands to request per generation;
+	// 0 or 1 requests a single command
+	Candidates int
+
+	// Anonymize replaces the username, hostname, and home directory with
+	// placeholders in the context, files, and tool results a prompt is built
+	// from, and restores them in replies and tool call input
+	Anonymize bool
 }
 
 // NewAnthropicProvider creates a new Anthropic provider
@@ -42,9 +58,6 @@ func NewAnthropicProvider(apiKey, model string) *AnthropicProvider {
 	})
 }
 
-// CLIVersion is the version string for the CLI
-const CLIVersion = "1.0.0"
-
 // NewAnthropicProviderWithConfig creates a new Anthropic provider with full configuration
 func NewAnthropicProviderWithConfig(cfg ProviderConfig) *AnthropicProvider {
 	opts := []option.RequestOption{
@@ -55,37 +68,66 @@ func NewAnthropicProviderWithConfig(cfg ProviderConfig) *AnthropicProvider {
 	}
 	// Add Bastio CLI User-Agent header when using Bastio gateway
 	if cfg.DeviceID != "" {
-		userAgent := fmt.Sprintf("bastio-cli/%s device/%s", CLIVersion, cfg.DeviceID)
-		opts = append(opts, option.WithHeader("User-Agent", userAgent))
+		opts = append(opts, option.WithHeader("User-Agent", useragent.String(cfg.DeviceID)))
 	}
 
-	// Add debug middleware to intercept and log raw HTTP responses
-	// This helps diagnose issues with SDK JSON unmarshaling
-	if os.Getenv("BAST_DEBUG_HTTP") == "1" {
-		opts = append(opts, option.WithMiddleware(func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
-			resp, err := next(req)
-			if err != nil {
-				return resp, err
-			}
-			// Read and log response body
-			body, readErr := io.ReadAll(resp.Body)
-			resp.Body.Close()
-			if readErr != nil {
-				fmt.Fprintf(os.Stderr, "DEBUG: Failed to read response body: %v\n", readErr)
-				return resp, err
-			}
-			fmt.Fprintf(os.Stderr, "DEBUG RAW HTTP RESPONSE:\n%s\n", string(body))
-			// Restore body for SDK
-			resp.Body = io.NopCloser(bytes.NewReader(body))
-			return resp, err
-		}))
+	// Trace requests to the debug log (a no-op unless --debug is on)
+	opts = append(opts, option.WithMiddleware(func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		return debug.Trace(req, next)
+	}))
+
+	// Count tokens for the status bar
+	usage := &usageMeter{}
+	opts = append(opts, option.WithMiddleware(usage.middleware()))
+
+	var anon *safety.Anonymizer
+	if cfg.Anonymize {
+		anon = safety.CurrentAnonymizer()
 	}
 
 	client := anthropic.NewClient(opts...)
 	return &AnthropicProvider{
-		client: client,
-		model:  anthropic.Model(cfg.Model),
+		anon:       anon,
+		client:     client,
+		model:      anthropic.Model(cfg.Model),
+		baseURL:    cfg.BaseURL,
+		candidates: cfg.Candidates,
+		usage:      usage,
+	}
+}
+
+// defaultAnthropicBaseURL is used for prewarming when no base URL is configured
+const defaultAnthropicBaseURL = "https://api.anthropic.com/"
+
+// Prewarm opens the connection to the API so the first real request skips
+// DNS and TLS setup. The SDK and this request share http.DefaultClient, so
+// the connection is reused from its pool. With ping, a one-item model list
+// request is sent instead of a bare HEAD, which also warms the gateway.
+func (p *AnthropicProvider) Prewarm(ctx context.Context, ping bool) error {
+	if ping {
+		_, err := p.client.Models.List(ctx, anthropic.ModelListParams{Limit: anthropic.Int(1)})
+		return err
 	}
+
+	baseURL := p.baseURL
+	if baseURL == "" {
+		baseURL = os.Getenv("ANTHROPIC_BASE_URL")
+	}
+	if baseURL == "" {
+		baseURL = defaultAnthropicBaseURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, baseURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	// Drain the body so the connection goes back to the pool
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
 }
 
 // SetModel updates the model used for API calls
@@ -93,57 +135,25 @@ func (p *AnthropicProvider) SetModel(model string) {
 	p.model = anthropic.Model(model)
 }
 
-func (p *AnthropicProvider) GenerateCommand(ctx context.Context, query string, shellCtx ShellContext) (*CommandResult, error) {
+func (p *AnthropicProvider) GenerateCommand(ctx context.Context, query string, shellCtx ShellContext) (result *CommandResult, err error) {
+	query, shellCtx = p.anon.Anonymize(query), shellCtx.anonymized(p.anon)
+	defer func() { result.restore(p.anon) }()
+
 	ctx, cancel := context.WithTimeout(ctx, DefaultAPITimeout)
 	defer cancel()
 
-	systemPrompt := `You are bast, an AI shell assistant. Your job is to generate shell commands based on the user's request.
+	formattedSystem := joinSections(commandSections(query, shellCtx))
 
-IMPORTANT RULES:
-1. Respond with ONLY the shell command - no explanations, no markdown, no code blocks
-2. The command should be safe and appropriate for the user's environment
-3. Use the provided context (current directory, OS, shell, git status) to generate appropriate commands
-4. If the request is ambiguous, generate the most likely intended command
-5. Never include commands that could be destructive without explicit confirmation markers
-6. For git operations, consider the current branch and repository state
-
-Current environment:
-- Working directory: %s
-- Operating system: %s
-- Shell: %s
-- User: %s`
-
-	if shellCtx.LastCommand != "" {
-		systemPrompt += fmt.Sprintf("\n- Last command: %s (exit status: %d)", shellCtx.LastCommand, shellCtx.ExitStatus)
-	}
-
-	formattedSystem := fmt.Sprintf(systemPrompt, shellCtx.CWD, shellCtx.OS, shellCtx.Shell, shellCtx.User)
-
-	// Add git context if available
-	gitContext := formatGitContext(shellCtx.Git)
-	if gitContext != "" {
-		formattedSystem += gitContext
-	}
-
-	// Add history context when available
-	if len(shellCtx.History) > 0 {
-		formattedSystem += "\n\nRecent command history:\n"
-		for _, cmd := range shellCtx.History {
-			formattedSystem += fmt.Sprintf("$ %s\n", cmd)
-		}
-	}
-
-	if shellCtx.LastOutput != "" {
-		formattedSystem += fmt.Sprintf("\nLast command output:\n%s\n", shellCtx.LastOutput)
-	}
-
-	if shellCtx.LastError != "" {
-		formattedSystem += fmt.Sprintf("\nLast command stderr:\n%s\n", shellCtx.LastError)
+	// Request several candidates in one call when configured
+	maxTokens := int64(256)
+	if p.candidates > 1 {
+		formattedSystem += fmt.Sprintf(candidatesPrompt, p.candidates)
+		maxTokens *= int64(p.candidates)
 	}
 
 	message, err := p.client.Messages.New(ctx, anthropic.MessageNewParams{
 		Model:     p.model,
-		MaxTokens: int64(256),
+		MaxTokens: maxTokens,
 		System: []anthropic.TextBlockParam{
 			{Text: formattedSystem},
 		},
@@ -152,7 +162,7 @@ Current environment:
 		},
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate command: %w", err)
+		return nil, fmt.Errorf("failed to generate command: %w", classifyError(err))
 	}
 
 	// Extract text from response
@@ -168,15 +178,64 @@ Current environment:
 		return nil, fmt.Errorf("no command generated")
 	}
 
-	// Clean up command if it's wrapped in code blocks
-	command = cleanCommand(command)
+	if p.candidates > 1 {
+		if candidates := parseCandidates(command); len(candidates) > 0 {
+			return &CommandResult{
+				Command:      candidates[0].Command,
+				WorkingDir:   candidates[0].WorkingDir,
+				Alternatives: candidates[1:],
+			}, nil
+		}
+		debug.Logf("reply was not a candidate list, using it as a single command")
+	}
+
+	// Clean up command if it's wrapped in code blocks or prose
+	reply := command
+	workingDir, command := parseCommandReply(command)
+
+	// Ask once more when the reply still isn't a command
+	if err := validateCommand(command); err != nil {
+		debug.Logf("generated command failed validation (%v), asking again", err)
+		retry, retryErr := p.client.Messages.New(ctx, anthropic.MessageNewParams{
+			Model:     p.model,
+			MaxTokens: maxTokens,
+			System: []anthropic.TextBlockParam{
+				{Text: formattedSystem},
+			},
+			Messages: []anthropic.MessageParam{
+				anthropic.NewUserMessage(anthropic.NewTextBlock(query)),
+				anthropic.NewAssistantMessage(anthropic.NewTextBlock(reply)),
+				anthropic.NewUserMessage(anthropic.NewTextBlock(fmt.Sprintf(
+					"That is not a runnable shell command (%v). Reply with only the command, no other text.", err))),
+			},
+		})
+		if retryErr == nil {
+			for _, block := range retry.Content {
+				if block.Type == "text" {
+					if dir, fixed := parseCommandReply(block.Text); validateCommand(fixed) == nil {
+						workingDir, command = dir, fixed
+					}
+					break
+				}
+			}
+		}
+	}
+
+	// A leading cd becomes a working directory, shown apart from the command
+	if workingDir == "" {
+		workingDir, command = liftCdPrefix(command)
+	}
 
 	return &CommandResult{
-		Command: command,
+		Command:    command,
+		WorkingDir: workingDir,
 	}, nil
 }
 
-func (p *AnthropicProvider) ExplainCommand(ctx context.Context, command string) (string, error) {
+func (p *AnthropicProvider) ExplainCommand(ctx context.Context, command string) (text string, err error) {
+	command = p.anon.Anonymize(command)
+	defer func() { text = p.anon.Restore(text) }()
+
 	ctx, cancel := context.WithTimeout(ctx, DefaultAPITimeout)
 	defer cancel()
 
@@ -199,7 +258,53 @@ RULES:
 		},
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to explain command: %w", err)
+		return "", fmt.Errorf("failed to explain command: %w", classifyError(err))
+	}
+
+	var explanation string
+	for _, block := range message.Content {
+		if block.Type == "text" {
+			explanation = strings.TrimSpace(block.Text)
+			break
+		}
+	}
+
+	return explanation, nil
+}
+
+func (p *AnthropicProvider) CompareCommands(ctx context.Context, before, after string, riskChanges []string) (comparison string, err error) {
+	before, after, riskChanges = p.anon.Anonymize(before), p.anon.Anonymize(after), anonymizeAll(p.anon, riskChanges)
+	defer func() { comparison = p.anon.Restore(comparison) }()
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultAPITimeout)
+	defer cancel()
+
+	systemPrompt := `You are bast, an AI shell assistant. Compare two versions of a shell command, such as a generated command and the user's edit of it.
+
+RULES:
+1. Start with a one-sentence summary of what changes when running the second command instead of the first
+2. List flags and arguments that were added, removed, or changed, and what each change does
+3. Describe the risk delta: is the second command more or less destructive, broader in scope, or privileged
+4. If the commands behave the same, say so
+5. Keep the explanation brief but informative`
+
+	prompt := fmt.Sprintf("First command:\n%s\n\nSecond command:\n%s", before, after)
+	if len(riskChanges) > 0 {
+		prompt += "\n\nLocal safety checks on the second command: " + strings.Join(riskChanges, "; ")
+	}
+
+	message, err := p.client.Messages.New(ctx, anthropic.MessageNewParams{
+		Model:     p.model,
+		MaxTokens: int64(768),
+		System: []anthropic.TextBlockParam{
+			{Text: systemPrompt},
+		},
+		Messages: []anthropic.MessageParam{
+			anthropic.NewUserMessage(anthropic.NewTextBlock(prompt)),
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to compare commands: %w", classifyError(err))
 	}
 
 	var explanation string
@@ -214,6 +319,8 @@ RULES:
 }
 
 func (p *AnthropicProvider) ClassifyIntent(ctx context.Context, query string) (*IntentResult, error) {
+	query = p.anon.Anonymize(query)
+
 	ctx, cancel := context.WithTimeout(ctx, DefaultAPITimeout)
 	defer cancel()
 
@@ -251,7 +358,7 @@ Examples:
 		},
 	}, option.WithHeader("X-Bastio-Internal", "intent-classifier"))
 	if err != nil {
-		return nil, fmt.Errorf("failed to classify intent: %w", err)
+		return nil, fmt.Errorf("failed to classify intent: %w", classifyError(err))
 	}
 
 	var responseText string
@@ -295,58 +402,14 @@ Examples:
 	}, nil
 }
 
-func (p *AnthropicProvider) Chat(ctx context.Context, query string, shellCtx ShellContext, chatCtx ChatContext) (*ChatResult, error) {
+func (p *AnthropicProvider) Chat(ctx context.Context, query string, shellCtx ShellContext, chatCtx ChatContext) (result *ChatResult, err error) {
+	query, shellCtx, chatCtx = p.anon.Anonymize(query), shellCtx.anonymized(p.anon), chatCtx.anonymized(p.anon)
+	defer func() { result.restore(p.anon) }()
+
 	ctx, cancel := context.WithTimeout(ctx, DefaultAPITimeout)
 	defer cancel()
 
-	systemPrompt := fmt.Sprintf(`You are bast, an AI shell assistant. The user is asking a question or wants information.
-Provide a helpful, concise response.
-
-Current environment:
-- Working directory: %s
-- Operating system: %s
-- Shell: %s
-
-Keep responses brief and terminal-friendly (no long paragraphs).
-If the user asks for something that would be better accomplished with a command, suggest they rephrase their request.`, shellCtx.CWD, shellCtx.OS, shellCtx.Shell)
-
-	if shellCtx.LastCommand != "" {
-		systemPrompt += fmt.Sprintf("\n- Last command: %s (exit status: %d)", shellCtx.LastCommand, shellCtx.ExitStatus)
-	}
-
-	// Add git context if available
-	gitContext := formatGitContext(shellCtx.Git)
-	if gitContext != "" {
-		systemPrompt += gitContext
-	}
-
-	// Add history context when available
-	if len(shellCtx.History) > 0 {
-		systemPrompt += "\n\nRecent command history:\n"
-		for _, cmd := range shellCtx.History {
-			systemPrompt += fmt.Sprintf("$ %s\n", cmd)
-		}
-	}
-
-	if shellCtx.LastOutput != "" {
-		systemPrompt += fmt.Sprintf("\nLast command output:\n%s\n", shellCtx.LastOutput)
-	}
-
-	if shellCtx.LastError != "" {
-		systemPrompt += fmt.Sprintf("\nLast command stderr:\n%s\n", shellCtx.LastError)
-	}
-
-	// Append file contents if available
-	if len(chatCtx.Files) > 0 {
-		systemPrompt += "\n\nFile contents available for reference:"
-		for _, f := range chatCtx.Files {
-			if f.Error == "" {
-				systemPrompt += fmt.Sprintf("\n\n--- %s ---\n%s", f.Path, f.Content)
-			} else {
-				systemPrompt += fmt.Sprintf("\n\n--- %s ---\n[Error: %s]", f.Path, f.Error)
-			}
-		}
-	}
+	systemPrompt := joinSections(chatSections(shellCtx, chatCtx))
 
 	// Build message array from conversation history + current query
 	var messages []anthropic.MessageParam
@@ -365,10 +428,11 @@ If the user asks for something that would be better accomplished with a command,
 		System: []anthropic.TextBlockParam{
 			{Text: systemPrompt},
 		},
-		Messages: messages,
+		Messages:      messages,
+		StopSequences: []string{followUpsClose},
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate chat response: %w", err)
+		return nil, fmt.Errorf("failed to generate chat response: %w", classifyError(err))
 	}
 
 	var response string
@@ -378,9 +442,11 @@ If the user asks for something that would be better accomplished with a command,
 			break
 		}
 	}
+	response, followUps := parseFollowUps(response)
 
 	return &ChatResult{
-		Response: response,
+		Response:  response,
+		FollowUps: followUps,
 	}, nil
 }
 
@@ -402,9 +468,10 @@ func extractJSON(text string) string {
 	return strings.TrimSpace(text)
 }
 
-// cleanCommand removes markdown code block formatting if present
+// cleanCommand removes prose around the command and markdown code block
+// formatting if present
 func cleanCommand(cmd string) string {
-	cmd = strings.TrimSpace(cmd)
+	cmd = stripProse(cmd)
 
 	// Remove ```bash or ```sh or ``` prefix
 	if strings.HasPrefix(cmd, "```") {
@@ -425,6 +492,198 @@ func cleanCommand(cmd string) string {
 	return strings.TrimSpace(cmd)
 }
 
+// formatDirenvContext formats direnv context for inclusion in prompts
+func formatDirenvContext(env *DirenvContext) string {
+	if env == nil {
+		return ""
+	}
+
+	var ctx strings.Builder
+	ctx.WriteString("\ndirenv:\n")
+	ctx.WriteString(fmt.Sprintf("- .envrc: %s (%s)\n", env.Path, env.Status))
+	switch {
+	case env.Loaded:
+		ctx.WriteString("- Loaded in the user's shell; assume its variables and toolchain are active\n")
+	case env.Status == "allowed":
+		ctx.WriteString("- Allowed but not loaded in this shell (direnv hook may be missing)\n")
+	case env.Status == "not allowed" || env.Status == "denied":
+		ctx.WriteString("- Not loaded: commands that depend on it need `direnv allow` first\n")
+	}
+	if len(env.Uses) > 0 {
+		ctx.WriteString(fmt.Sprintf("- Toolchain: %s\n", strings.Join(env.Uses, "; ")))
+	}
+	if len(env.Variables) > 0 {
+		ctx.WriteString(fmt.Sprintf("- Exports: %s\n", strings.Join(env.Variables, ", ")))
+	}
+	return ctx.String()
+}
+
+// formatRuntimeEnvs formats the project's language environments for
+// inclusion in prompts
+func formatRuntimeEnvs(runtimes []RuntimeEnv) string {
+	if len(runtimes) == 0 {
+		return ""
+	}
+
+	var ctx strings.Builder
+	ctx.WriteString("\nLanguage environments:\n")
+	for _, env := range runtimes {
+		var what string
+		switch env.Kind {
+		case RuntimeVirtualenv:
+			what = "Python virtualenv " + env.Name
+		case RuntimeConda:
+			what = "conda env " + env.Name
+		case RuntimeNode:
+			what = "Node " + env.Name + " (pinned by the project)"
+		}
+		switch {
+		case env.Active:
+			ctx.WriteString(fmt.Sprintf("- %s: active in the user's shell", what))
+		case env.Activate != "":
+			ctx.WriteString(fmt.Sprintf("- %s: NOT active", what))
+			if env.Current != "" {
+				ctx.WriteString(fmt.Sprintf(" (%s is in use)", env.Current))
+			}
+			ctx.WriteString(fmt.Sprintf("; commands that need it must run `%s &&` first", env.Activate))
+		default:
+			ctx.WriteString(fmt.Sprintf("- %s: unknown whether it is active", what))
+		}
+		if env.Bin != "" {
+			ctx.WriteString(fmt.Sprintf("; its executables are in %s", env.Bin))
+		}
+		ctx.WriteString("\n")
+	}
+	return ctx.String()
+}
+
+// formatToolVersions formats installed tool versions for inclusion in prompts
+func formatToolVersions(tools []ToolVersion) string {
+	if len(tools) == 0 {
+		return ""
+	}
+
+	var versions []string
+	for _, t := range tools {
+		versions = append(versions, fmt.Sprintf("%s %s", t.Name, t.Version))
+	}
+	return fmt.Sprintf("\nInstalled tool versions (use flags and syntax these versions support): %s\n",
+		strings.Join(versions, ", "))
+}
+
+// formatExamples formats the user's past accepted or well-rated generations
+// as few-shot examples
+func formatExamples(examples []CommandExample) string {
+	if len(examples) == 0 {
+		return ""
+	}
+
+	var ctx strings.Builder
+	ctx.WriteString("\nCommands this user ran or rated good for similar requests (match their preferred flags, output formats, and style):\n")
+	for _, ex := range examples {
+		ctx.WriteString(fmt.Sprintf("- %q → %s\n", ex.Query, ex.Command))
+	}
+	return ctx.String()
+}
+
+// maxPromptMembers caps how many workspace members are listed in a prompt
+const maxPromptMembers = 30
+
+// formatWorkspaceContext formats monorepo workspace context for inclusion in prompts
+func formatWorkspaceContext(ws *WorkspaceContext) string {
+	if ws == nil {
+		return ""
+	}
+
+	var ctx strings.Builder
+	ctx.WriteString(fmt.Sprintf("\nWorkspace (%s) at %s with %d members:\n", ws.Kind, ws.Root, len(ws.Members)))
+	for i, member := range ws.Members {
+		if i == maxPromptMembers {
+			ctx.WriteString(fmt.Sprintf("- ... and %d more\n", len(ws.Members)-i))
+			break
+		}
+		ctx.WriteString("- " + member + "\n")
+	}
+	if ws.Current != "" {
+		ctx.WriteString(fmt.Sprintf("Working in member %s: scope builds, tests, and other commands to it, not the whole workspace\n", ws.Current))
+	} else {
+		ctx.WriteString("Working at the workspace root: commands here apply to every member\n")
+	}
+	return ctx.String()
+}
+
+// maxPromptAliases caps how many aliases are included in a prompt
+const maxPromptAliases = 20
+
+// formatAliasContext lists the user's aliases and functions that matter for
+// query: those that shadow a real binary, and those named in the query
+func formatAliasContext(aliases []ShellAlias, query string) string {
+	words := make(map[string]bool)
+	for _, w := range strings.Fields(query) {
+		words[strings.Trim(w, "`'\".,:;?!")] = true
+	}
+
+	var lines []string
+	for _, a := range aliases {
+		if !a.Shadows && !words[a.Name] {
+			continue
+		}
+		if a.Function {
+			lines = append(lines, fmt.Sprintf("- %s is a shell function", a.Name))
+		} else {
+			// Aliases often embed tokens, e.g. curl -H "Authorization: ..."
+			expansion, _ := safety.RedactSecrets(a.Expansion)
+			lines = append(lines, fmt.Sprintf("- alias %s='%s'", a.Name, expansion))
+		}
+		if len(lines) == maxPromptAliases {
+			break
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "\nUser aliases and functions (commands run through these; use flags the expansion accepts, or `command name` to bypass it):\n" +
+		strings.Join(lines, "\n") + "\n"
+}
+
+// formatPinnedContext formats pinned context for inclusion in prompts
+func formatPinnedContext(pinned []PinnedItem) string {
+	if len(pinned) == 0 {
+		return ""
+	}
+
+	var ctx strings.Builder
+	ctx.WriteString("\n\nPinned context (the user asked you to always keep this in mind):")
+	for _, item := range pinned {
+		if item.Untrusted {
+			ctx.WriteString(fmt.Sprintf("\n\n%s", wrapUntrusted(item.Label, item.Content)))
+		} else {
+			ctx.WriteString(fmt.Sprintf("\n\n--- %s ---\n%s", item.Label, item.Content))
+		}
+	}
+	ctx.WriteString("\n")
+	return ctx.String()
+}
+
+// formatReferencedFiles formats the files a one-shot query references
+func formatReferencedFiles(referenced []files.FileContent) string {
+	if len(referenced) == 0 {
+		return ""
+	}
+
+	var ctx strings.Builder
+	ctx.WriteString("\n\nFiles the user referenced:")
+	for _, f := range referenced {
+		if f.Error == "" {
+			ctx.WriteString(fmt.Sprintf("\n\n%s", wrapUntrusted(f.Path, f.Content)))
+		} else {
+			ctx.WriteString(fmt.Sprintf("\n\n--- %s ---\n[Error: %s]", f.Path, f.Error))
+		}
+	}
+	ctx.WriteString("\n")
+	return ctx.String()
+}
+
 // formatGitContext formats git context for inclusion in prompts
 func formatGitContext(git *GitContext) string {
 	if git == nil || !git.IsRepo {
@@ -433,7 +692,17 @@ func formatGitContext(git *GitContext) string {
 
 	var ctx strings.Builder
 	ctx.WriteString("\nGit Repository Context:\n")
-	ctx.WriteString(fmt.Sprintf("- Branch: %s\n", git.Branch))
+	if git.Detached {
+		ctx.WriteString(fmt.Sprintf("- Detached HEAD at %s (not on a branch)\n", git.HeadSHA))
+	} else {
+		ctx.WriteString(fmt.Sprintf("- Branch: %s\n", git.Branch))
+	}
+	if git.Worktree != "" {
+		ctx.WriteString(fmt.Sprintf("- Linked worktree: %s\n", git.Worktree))
+	}
+	if git.IsSubmodule {
+		ctx.WriteString("- Inside a submodule of another repository\n")
+	}
 
 	if git.HasStaged {
 		ctx.WriteString("- Has staged changes\n")
@@ -444,6 +713,9 @@ func formatGitContext(git *GitContext) string {
 	if git.HasUntracked {
 		ctx.WriteString("- Has untracked files\n")
 	}
+	if len(git.DirtySubmodules) > 0 {
+		ctx.WriteString(fmt.Sprintf("- Dirty submodules: %s\n", strings.Join(git.DirtySubmodules, ", ")))
+	}
 	if git.MergeInProgress {
 		ctx.WriteString("- MERGE IN PROGRESS\n")
 	}
@@ -454,8 +726,9 @@ func formatGitContext(git *GitContext) string {
 	return ctx.String()
 }
 
-// detectProjectContext analyzes the working directory to determine project type and structure
-func detectProjectContext(cwd string) string {
+// DetectProject analyzes the working directory to determine project type and
+// structure, formatted for the agent prompt
+func DetectProject(cwd string) string {
 	var ctx strings.Builder
 
 	// Check for Go project
@@ -512,7 +785,11 @@ func detectProjectContext(cwd string) string {
 }
 
 // FixCommand analyzes a failed command and suggests a fix
-func (p *AnthropicProvider) FixCommand(ctx context.Context, failedCmd string, errorOutput string, shellCtx ShellContext) (*FixResult, error) {
+func (p *AnthropicProvider) FixCommand(ctx context.Context, failedCmd string, errorOutput string, frames []files.FileContent, shellCtx ShellContext) (fix *FixResult, err error) {
+	failedCmd, errorOutput = p.anon.Anonymize(failedCmd), p.anon.Anonymize(errorOutput)
+	frames, shellCtx = anonymizeFiles(p.anon, frames), shellCtx.anonymized(p.anon)
+	defer func() { fix.restore(p.anon) }()
+
 	ctx, cancel := context.WithTimeout(ctx, DefaultAPITimeout)
 	defer cancel()
 
@@ -529,14 +806,27 @@ IMPORTANT RULES:
 3. If the error requires manual intervention (missing file, permissions issue that needs sudo), explain what to do
 4. Set was_fixed to true if you provided a working fixed command, false if only explanation
 5. Keep explanations concise (1-2 sentences)
+6. If source code from a stack trace is included, the failure is probably in that code rather than the command line: name the file and line at fault and what to change there
 
 Current environment:
 - Working directory: %s
 - Operating system: %s
 - Shell: %s
 - User: %s`, shellCtx.CWD, shellCtx.OS, shellCtx.Shell, shellCtx.User)
+	systemPrompt += formatLocale(shellCtx)
+
+	systemPrompt += formatPinnedContext(shellCtx.Pinned)
+	systemPrompt += untrustedNotice
 
-	userPrompt := fmt.Sprintf("Failed command: %s\n\nError output:\n%s", failedCmd, errorOutput)
+	userPrompt := fmt.Sprintf("Failed command: %s\n\nError output:\n%s", failedCmd, wrapUntrusted("error output", errorOutput))
+
+	// Source around the stack trace, innermost frame first
+	if len(frames) > 0 {
+		userPrompt += "\n\nSource at the top stack frames (> marks the frame's line):"
+		for _, f := range frames {
+			userPrompt += fmt.Sprintf("\n\n%s", wrapUntrusted(f.Path, f.Content))
+		}
+	}
 
 	message, err := p.client.Messages.New(ctx, anthropic.MessageNewParams{
 		Model:     p.model,
@@ -549,7 +839,7 @@ Current environment:
 		},
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to analyze error: %w", err)
+		return nil, fmt.Errorf("failed to analyze error: %w", classifyError(err))
 	}
 
 	var responseText string
@@ -584,8 +874,14 @@ Current environment:
 	}, nil
 }
 
-// ExplainOutput analyzes command output and provides an explanation
-func (p *AnthropicProvider) ExplainOutput(ctx context.Context, output string, prompt string, shellCtx ShellContext) (*ChatResult, error) {
+// ExplainOutput analyzes command output and provides an explanation. digest,
+// when set, is a summary of structured logs or tabular data in the output,
+// parsed locally from all of it
+func (p *AnthropicProvider) ExplainOutput(ctx context.Context, output, digest, prompt string, shellCtx ShellContext) (result *ChatResult, err error) {
+	output, digest, prompt = p.anon.Anonymize(output), p.anon.Anonymize(digest), p.anon.Anonymize(prompt)
+	shellCtx = shellCtx.anonymized(p.anon)
+	defer func() { result.restore(p.anon) }()
+
 	ctx, cancel := context.WithTimeout(ctx, DefaultAPITimeout)
 	defer cancel()
 
@@ -603,11 +899,24 @@ Current environment:
 - Operating system: %s
 - Shell: %s`, shellCtx.CWD, shellCtx.OS, shellCtx.Shell)
 
+	if digest != "" {
+		systemPrompt += `
+
+The output is structured data, and a digest parsed locally from all of it comes first; its first line says what was parsed. For logs it has counts, the time range, and notable entries (errors, warnings, restarts, OOM kills) grouped by message. For tables it has each column's type and statistics. Rely on it for counts, statistics, and anomalies, since the raw output may be summarized or only sampled, and lead with what matters most.`
+	}
+	systemPrompt += formatReferencedFiles(shellCtx.Files)
+	systemPrompt += untrustedNotice
+
+	analyzed := wrapUntrusted("piped output", output)
+	if digest != "" {
+		analyzed = fmt.Sprintf("Digest:\n%s\n\nRaw output:\n%s", wrapUntrusted("digest", digest), analyzed)
+	}
+
 	userPrompt := output
 	if prompt != "" {
-		userPrompt = fmt.Sprintf("Output to analyze:\n%s\n\nUser's question: %s", output, prompt)
+		userPrompt = fmt.Sprintf("Output to analyze:\n%s\n\nUser's question: %s", analyzed, prompt)
 	} else {
-		userPrompt = fmt.Sprintf("Explain this output:\n%s", output)
+		userPrompt = fmt.Sprintf("Explain this output:\n%s", analyzed)
 	}
 
 	message, err := p.client.Messages.New(ctx, anthropic.MessageNewParams{
@@ -621,7 +930,7 @@ Current environment:
 		},
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to explain output: %w", err)
+		return nil, fmt.Errorf("failed to explain output: %w", classifyError(err))
 	}
 
 	var response string
@@ -637,6 +946,132 @@ Current environment:
 	}, nil
 }
 
+// ExplainFile explains a script or source file section by section
+func (p *AnthropicProvider) ExplainFile(ctx context.Context, file files.FileContent, prompt string, shellCtx ShellContext) (result *ChatResult, err error) {
+	file, prompt, shellCtx = anonymizeFile(p.anon, file), p.anon.Anonymize(prompt), shellCtx.anonymized(p.anon)
+	defer func() { result.restore(p.anon) }()
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultAPITimeout)
+	defer cancel()
+
+	systemPrompt := fmt.Sprintf(`You are bast, an AI shell assistant helping to explain a file.
+
+The user wants to understand a script or source file. Respond with these sections:
+1. Purpose - what the file is for, in one or two sentences
+2. How it works - the main steps or components, in order
+3. Inputs and outputs - arguments, environment variables, files read or written
+4. Side effects and risks - anything destructive, networked, or privileged
+
+Skip a section if it does not apply. If the user asks a specific question, answer it first.
+Keep your response concise and terminal-friendly.
+
+Current environment:
+- Working directory: %s
+- Operating system: %s
+- Shell: %s`, shellCtx.CWD, shellCtx.OS, shellCtx.Shell)
+
+	systemPrompt += formatReferencedFiles(shellCtx.Files)
+	systemPrompt += untrustedNotice
+
+	userPrompt := fmt.Sprintf("File: %s\n%s", file.Path, wrapUntrusted(file.Path, file.Content))
+	if prompt != "" {
+		userPrompt += fmt.Sprintf("\n\nUser's question: %s", prompt)
+	}
+
+	message, err := p.client.Messages.New(ctx, anthropic.MessageNewParams{
+		Model:     p.model,
+		MaxTokens: int64(1536),
+		System: []anthropic.TextBlockParam{
+			{Text: systemPrompt},
+		},
+		Messages: []anthropic.MessageParam{
+			anthropic.NewUserMessage(anthropic.NewTextBlock(userPrompt)),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain file: %w", classifyError(err))
+	}
+
+	var response string
+	for _, block := range message.Content {
+		if block.Type == "text" {
+			response = strings.TrimSpace(block.Text)
+			break
+		}
+	}
+
+	return &ChatResult{
+		Response: response,
+	}, nil
+}
+
+// formatToolResultContent builds the tool result text sent back to the model.
+// Failed process runs get a trailing status line so the model can reason about
+// the exit code and runtime instead of guessing from the output alone.
+func formatToolResultContent(result tools.CallResult) string {
+	if !result.IsError || result.ExitCode == nil {
+		return result.Content
+	}
+	return fmt.Sprintf("%s\n[exit code %d after %s, %d bytes of output]",
+		result.Content, *result.ExitCode, result.Duration.Round(time.Millisecond), result.Bytes)
+}
+
+// readFileTool is the built-in tool mentioned files are replayed through
+const readFileTool = "read_file"
+
+// executeToolCall runs call through the registry and returns it with its
+// output filled in, along with the tool result content for the model.
+// The call keeps the full output for display; the model gets a summary of
+// command output cut to cfg.PromptOutputLines.
+func executeToolCall(ctx context.Context, cfg AgentConfig, call ToolCall) (ToolCall, string) {
+	if cfg.OnToolProgress != nil {
+		running := call
+		ctx = tools.WithProgress(ctx, func(tail []string) { cfg.OnToolProgress(running, tail) })
+	}
+	toolResult := cfg.Registry.ExecuteCall(ctx, tools.Call{
+		ID:    call.ID,
+		Name:  call.Name,
+		Input: call.Input,
+	})
+	call.Output = toolResult.Content
+	call.IsError = toolResult.IsError
+	call.ExitCode = toolResult.ExitCode
+	call.Duration = toolResult.Duration
+	call.Bytes = toolResult.Bytes
+	call.Warnings = toolResult.Warnings
+	call.Threats = toolResult.Threats
+	call.Verdicts = toolResult.Verdicts
+	if toolResult.ExitCode != nil {
+		toolResult.Content = tools.SummarizeOutput(toolResult.Content, cfg.PromptOutputLines)
+	}
+	return call, wrapUntrusted(call.Name+" output", formatToolResultContent(toolResult))
+}
+
+// canReadFiles reports whether the agent has the read_file tool
+func canReadFiles(registry *tools.Registry) bool {
+	_, ok := registry.Get(readFileTool)
+	return ok
+}
+
+// mentionedFileReads returns read_file calls for the files the user
+// mentioned, so their contents reach the agent as tool results the same way
+// as files it reads itself
+func mentionedFileReads(mentioned []files.FileContent) []ToolCall {
+	var calls []ToolCall
+	for i, f := range mentioned {
+		input, err := json.Marshal(map[string]string{"path": f.Path})
+		if err != nil {
+			continue
+		}
+		calls = append(calls, ToolCall{
+			ID:    fmt.Sprintf("toolu_mention_%d", i+1),
+			Name:  readFileTool,
+			Input: input,
+		})
+	}
+	return calls
+}
+
 // AgentAPITimeout is the timeout for agentic API calls (longer due to multi-turn)
 const AgentAPITimeout = 5 * time.Minute
 
@@ -644,7 +1079,10 @@ const AgentAPITimeout = 5 * time.Minute
 const DefaultMaxIterations = 10
 
 // RunAgent executes an agentic task with tool use
-func (p *AnthropicProvider) RunAgent(ctx context.Context, query string, shellCtx ShellContext, chatCtx ChatContext, cfg AgentConfig) (*AgentResult, error) {
+func (p *AnthropicProvider) RunAgent(ctx context.Context, query string, shellCtx ShellContext, chatCtx ChatContext, cfg AgentConfig) (agentResult *AgentResult, err error) {
+	query, shellCtx, chatCtx = p.anon.Anonymize(query), shellCtx.anonymized(p.anon), chatCtx.anonymized(p.anon)
+	defer func() { agentResult.restore(p.anon) }()
+
 	// Set defaults
 	if cfg.MaxIterations == 0 {
 		cfg.MaxIterations = DefaultMaxIterations
@@ -670,18 +1108,23 @@ Current environment:
 - Operating system: %s
 - Shell: %s
 - User: %s`, toolList.String(), shellCtx.CWD, shellCtx.OS, shellCtx.Shell, shellCtx.User)
+	systemPrompt += formatLocale(shellCtx)
 
 	// Add project context
-	projectCtx := detectProjectContext(shellCtx.CWD)
-	if projectCtx != "" {
-		systemPrompt += projectCtx
-	}
+	systemPrompt += shellCtx.Project
 
 	// Add git context if available
 	gitContext := formatGitContext(shellCtx.Git)
 	if gitContext != "" {
 		systemPrompt += gitContext
 	}
+	systemPrompt += formatDirenvContext(shellCtx.Direnv)
+	systemPrompt += formatToolVersions(shellCtx.Tools)
+	systemPrompt += formatRuntimeEnvs(shellCtx.Runtimes)
+	systemPrompt += formatWorkspaceContext(shellCtx.Workspace)
+
+	systemPrompt += formatPinnedContext(shellCtx.Pinned)
+	systemPrompt += formatProbes(shellCtx.Probes)
 
 	if shellCtx.LastCommand != "" {
 		systemPrompt += fmt.Sprintf("\n- Last command: %s (exit status: %d)", shellCtx.LastCommand, shellCtx.ExitStatus)
@@ -695,24 +1138,38 @@ Current environment:
 	}
 
 	if shellCtx.LastOutput != "" {
-		systemPrompt += fmt.Sprintf("\nLast command output:\n%s\n", shellCtx.LastOutput)
+		systemPrompt += fmt.Sprintf("\nLast command output:\n%s\n", wrapUntrusted("last command output", shellCtx.LastOutput))
 	}
 
 	if shellCtx.LastError != "" {
-		systemPrompt += fmt.Sprintf("\nLast command stderr:\n%s\n", shellCtx.LastError)
+		systemPrompt += fmt.Sprintf("\nLast command stderr:\n%s\n", wrapUntrusted("last command stderr", shellCtx.LastError))
 	}
 
-	if len(chatCtx.Files) > 0 {
+	// Mentioned files arrive as read_file results when the agent can read
+	// files itself, and in the system prompt otherwise
+	var seededReads []ToolCall
+	if cfg.Registry != nil && canReadFiles(cfg.Registry) {
+		seededReads = mentionedFileReads(chatCtx.Files)
+	} else if len(chatCtx.Files) > 0 {
 		systemPrompt += "\n\nFile contents available for reference:"
 		for _, f := range chatCtx.Files {
 			if f.Error == "" {
-				systemPrompt += fmt.Sprintf("\n\n--- %s ---\n%s", f.Path, f.Content)
+				systemPrompt += fmt.Sprintf("\n\n%s", wrapUntrusted(f.Path, f.Content))
 			} else {
 				systemPrompt += fmt.Sprintf("\n\n--- %s ---\n[Error: %s]", f.Path, f.Error)
 			}
 		}
 	}
 
+	systemPrompt += finalAnswerPrompt
+
+	// Tool results arrive wrapped as untrusted, so the notice always applies
+	if cfg.Registry != nil {
+		systemPrompt += untrustedNotice
+	} else {
+		systemPrompt = withUntrustedNotice(systemPrompt)
+	}
+
 	// Build initial messages from conversation history
 	var messages []anthropic.MessageParam
 	for _, msg := range chatCtx.History {
@@ -760,9 +1217,30 @@ Current environment:
 		ToolCalls: []ToolCall{},
 	}
 
-	// Agentic loop
+	// Replay the seeded reads as if the agent had made them first
+	guard := newToolCallGuard(p.anon)
+	if len(seededReads) > 0 {
+		var uses, results []anthropic.ContentBlockParamUnion
+		for _, call := range seededReads {
+			uses = append(uses, anthropic.NewToolUseBlock(call.ID, call.Input, call.Name))
+			call.Input = restoreJSON(p.anon, call.Input)
+			call, block := guard.run(ctx, cfg, call)
+			results = append(results, block)
+			result.ToolCalls = append(result.ToolCalls, call)
+			if cfg.OnToolCall != nil {
+				cfg.OnToolCall(call)
+			}
+		}
+		messages = append(messages, anthropic.NewAssistantMessage(uses...), anthropic.NewUserMessage(results...))
+	}
+
+	// Agentic loop. The tools and system prompt never change during a run,
+	// and messages only grow, so each call reads everything up to the
+	// previous call's last message from the prompt cache.
+	var breakpoint *anthropic.CacheControlEphemeralParam
 	for iteration := 0; iteration < cfg.MaxIterations; iteration++ {
 		result.Iterations = iteration + 1
+		breakpoint = cacheBreakpoint(messages, breakpoint)
 
 		// Use OfAny on first iteration to force tool use
 		// Use OfAuto on subsequent iterations to allow completion
@@ -782,26 +1260,32 @@ Current environment:
 			Model:     p.model,
 			MaxTokens: int64(4096),
 			System: []anthropic.TextBlockParam{
-				{Text: systemPrompt},
+				{Text: systemPrompt, CacheControl: anthropic.NewCacheControlEphemeralParam()},
 			},
-			Messages:   messages,
-			Tools:      apiTools,
-			ToolChoice: toolChoice,
+			Messages:      messages,
+			Tools:         apiTools,
+			ToolChoice:    toolChoice,
+			StopSequences: []string{finalAnswerClose},
 		}, option.WithHeader("X-Bastio-Internal", "agent"))
 		if err != nil {
-			return nil, fmt.Errorf("failed to run agent: %w", err)
+			return nil, fmt.Errorf("failed to run agent: %w", classifyError(err))
 		}
+		var stepCache PromptCache
+		stepCache.add(message.Usage)
+		result.Cache.add(message.Usage)
+		debug.Logf("agent: iteration %d input tokens: %d uncached, %d cache write, %d cache read",
+			iteration+1, message.Usage.InputTokens, message.Usage.CacheCreationInputTokens, message.Usage.CacheReadInputTokens)
 
 		// Process response blocks
 		var toolResults []anthropic.ContentBlockParamUnion
 		var responseText strings.Builder
 
 		// Debug logging for ContentBlockUnion fields
-		if os.Getenv("BAST_DEBUG_HTTP") == "1" {
-			fmt.Fprintf(os.Stderr, "DEBUG: Content block count=%d\n", len(message.Content))
+		if debug.Enabled() {
+			debug.Logf("agent: content block count=%d", len(message.Content))
 			for i, block := range message.Content {
-				fmt.Fprintf(os.Stderr, "DEBUG: Block[%d] Type=%q ID=%q Name=%q Input=%v\n",
-					i, block.Type, block.ID, block.Name, block.Input)
+				debug.Logf("agent: block[%d] type=%q id=%q name=%q input=%s",
+					i, block.Type, block.ID, block.Name, string(block.Input))
 			}
 		}
 
@@ -825,27 +1309,19 @@ Current environment:
 					Name: block.Name,
 				}
 
-				// Get raw input JSON
+				// Get raw input JSON, with real paths for the tool to use
 				if block.Input != nil {
-					toolCall.Input = block.Input
+					toolCall.Input = restoreJSON(p.anon, block.Input)
 				}
 
-				// Execute tool if registry available
+				// Execute tool if registry available, answering repeats
+				// of an earlier call from its result
 				if cfg.Registry != nil {
-					toolResult := cfg.Registry.ExecuteCall(ctx, tools.Call{
-						ID:    block.ID,
-						Name:  block.Name,
-						Input: toolCall.Input,
-					})
-					toolCall.Output = toolResult.Content
-					toolCall.IsError = toolResult.IsError
+					var resultBlock anthropic.ContentBlockParamUnion
+					toolCall, resultBlock = guard.run(ctx, cfg, toolCall)
 
 					// Build tool result for next API call
-					toolResults = append(toolResults, anthropic.NewToolResultBlock(
-						block.ID,
-						toolResult.Content,
-						toolResult.IsError,
-					))
+					toolResults = append(toolResults, resultBlock)
 				}
 
 				result.ToolCalls = append(result.ToolCalls, toolCall)
@@ -857,16 +1333,40 @@ Current environment:
 			}
 		}
 
+		if cfg.OnStep != nil {
+			cfg.OnStep(AgentStep{
+				Iteration: iteration + 1,
+				Text:      p.anon.Restore(responseText.String()),
+				ToolUses:  len(toolResults),
+				Cache:     stepCache,
+				Output:    message.Usage.OutputTokens,
+			})
+		}
+
 		// If no tool calls, we're done
 		if len(toolResults) == 0 {
-			result.Response = strings.TrimSpace(responseText.String())
+			response, answer := parseFinalAnswer(responseText.String())
+			result.Response = strings.TrimSpace(response)
+			if answer != nil {
+				result.Status = answer.Status
+				result.Summary = answer.Summary
+				result.FollowUps = answer.FollowUps
+			}
+			result.Threats = SummarizeThreats(result.ToolCalls)
 			return result, nil
 		}
 
+		if guard.looping() {
+			result.Response = strings.TrimSpace(responseText.String())
+			result.Threats = SummarizeThreats(result.ToolCalls)
+			return result, fmt.Errorf("stopped after %d repeated tool calls: the agent is looping", guard.repeats)
+		}
+
 		// Add assistant message and tool results to continue conversation
 		messages = append(messages, message.ToParam())
 		messages = append(messages, anthropic.NewUserMessage(toolResults...))
 	}
 
+	result.Threats = SummarizeThreats(result.ToolCalls)
 	return result, fmt.Errorf("max iterations (%d) reached", cfg.MaxIterations)
 }
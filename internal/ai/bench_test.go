@@ -0,0 +1,54 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bastio-ai/bast/internal/files"
+	"github.com/bastio-ai/bast/internal/perf"
+)
+
+// benchShellContext is a full context, as gathered in a busy repository
+func benchShellContext() ShellContext {
+	history := make([]string, 50)
+	for i := range history {
+		history[i] = "go test ./internal/... -run TestSomething -count=1"
+	}
+	return ShellContext{
+		CWD:         "/home/user/src/project",
+		LastCommand: "go test ./...",
+		LastOutput:  strings.Repeat("ok  \tgithub.com/example/project/internal/pkg\t0.012s\n", 200),
+		LastError:   strings.Repeat("--- FAIL: TestSomething (0.00s)\n", 50),
+		ExitStatus:  1,
+		OS:          "linux",
+		Shell:       "zsh",
+		User:        "user",
+		History:     history,
+		Git:         &GitContext{IsRepo: true, Branch: "main", HasUncommitted: true},
+		Pinned:      []PinnedItem{{Label: "note", Content: "deploy with make release"}},
+		Files:       []files.FileContent{{Path: "main.go", Content: strings.Repeat("package main\n\nfunc main() {}\n", 300)}},
+	}
+}
+
+func BenchmarkCommandPrompt(b *testing.B) {
+	shellCtx := benchShellContext()
+	for i := 0; i < b.N; i++ {
+		joinSections(commandSections("find large log files and compress them", shellCtx))
+	}
+}
+
+func BenchmarkChatPrompt(b *testing.B) {
+	shellCtx := benchShellContext()
+	chatCtx := ChatContext{Files: shellCtx.Files}
+	for i := 0; i < b.N; i++ {
+		joinSections(chatSections(shellCtx, chatCtx))
+	}
+}
+
+func TestPerfBudgets(t *testing.T) {
+	perf.Check(t, []perf.Budget{
+		{Name: "CommandPrompt", Bench: BenchmarkCommandPrompt, Max: 50 * time.Millisecond},
+		{Name: "ChatPrompt", Bench: BenchmarkChatPrompt, Max: 50 * time.Millisecond},
+	})
+}
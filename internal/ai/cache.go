@@ -0,0 +1,66 @@
+package ai
+
+import (
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// Prompt cache pricing relative to uncached input tokens
+const (
+	cacheWriteCost = 1.25
+	cacheReadCost  = 0.1
+)
+
+// PromptCache counts how an agent run's input tokens were served. The
+// system prompt and the conversation so far are cached, so each iteration
+// only sends its new tool results uncached.
+type PromptCache struct {
+	Input   int64 // Uncached input tokens
+	Written int64 // Tokens written to the cache
+	Read    int64 // Tokens read from the cache
+}
+
+// Total returns all input tokens, however they were served
+func (c PromptCache) Total() int64 {
+	return c.Input + c.Written + c.Read
+}
+
+// add counts the input usage of one API call
+func (c *PromptCache) add(usage anthropic.Usage) {
+	c.Input += usage.InputTokens
+	c.Written += usage.CacheCreationInputTokens
+	c.Read += usage.CacheReadInputTokens
+}
+
+// Savings returns the fraction of input cost saved compared to sending
+// every token uncached. It is negative when the cache was written but not
+// yet read back enough to pay for itself.
+func (c PromptCache) Savings() float64 {
+	total := c.Total()
+	if total == 0 {
+		return 0
+	}
+	cost := float64(c.Input) + cacheWriteCost*float64(c.Written) + cacheReadCost*float64(c.Read)
+	return 1 - cost/float64(total)
+}
+
+// cacheBreakpoint marks the last block of the newest message as the end of
+// the cached prefix, and clears the mark from the block previously passed
+// in, so a run never uses more than the API's four breakpoints. It returns
+// the marked block's cache control for the next call.
+func cacheBreakpoint(messages []anthropic.MessageParam, previous *anthropic.CacheControlEphemeralParam) *anthropic.CacheControlEphemeralParam {
+	if previous != nil {
+		*previous = anthropic.CacheControlEphemeralParam{}
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	content := messages[len(messages)-1].Content
+	if len(content) == 0 {
+		return nil
+	}
+	control := content[len(content)-1].GetCacheControl()
+	if control != nil {
+		*control = anthropic.NewCacheControlEphemeralParam()
+	}
+	return control
+}
@@ -0,0 +1,97 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bastio-ai/bast/internal/tools"
+)
+
+func TestPromptCacheSavings(t *testing.T) {
+	tests := []struct {
+		cache PromptCache
+		want  float64
+	}{
+		{PromptCache{}, 0},
+		{PromptCache{Input: 100}, 0},
+		{PromptCache{Written: 1000}, -0.25},
+		{PromptCache{Input: 100, Written: 1000, Read: 3000}, 1 - (100+1250+300)/4100.0},
+	}
+	for _, tt := range tests {
+		if got := tt.cache.Savings(); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%+v.Savings() = %v, want %v", tt.cache, got, tt.want)
+		}
+	}
+}
+
+// TestRunAgentPromptCache checks that each call caches the system prompt
+// and marks only the newest message, and that usage is reported per step
+// and added up per run
+func TestRunAgentPromptCache(t *testing.T) {
+	var requests []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req map[string]any
+		json.Unmarshal(body, &req)
+		requests = append(requests, req)
+
+		w.Header().Set("Content-Type", "application/json")
+		n := len(requests)
+		usage := fmt.Sprintf(`"usage":{"input_tokens":50,"cache_creation_input_tokens":%d,"cache_read_input_tokens":%d,"output_tokens":10}`,
+			1000, 1000*(n-1))
+		if n < 3 {
+			fmt.Fprintf(w, `{"id":"msg_%d","type":"message","role":"assistant","model":"test","stop_reason":"tool_use",
+				"content":[{"type":"tool_use","id":"toolu_%d","name":"list_directory","input":{"path":"dir%d"}}],%s}`, n, n, n, usage)
+			return
+		}
+		fmt.Fprintf(w, `{"id":"msg_3","type":"message","role":"assistant","model":"test","stop_reason":"end_turn",
+			"content":[{"type":"text","text":"Done."}],%s}`, usage)
+	}))
+	defer server.Close()
+
+	registry := tools.NewRegistry()
+	registry.Register(&countingTool{name: "list_directory"})
+	p := NewAnthropicProviderWithConfig(ProviderConfig{APIKey: "key", Model: "test", BaseURL: server.URL})
+	var steps []AgentStep
+	cfg := AgentConfig{Registry: registry, OnStep: func(step AgentStep) { steps = append(steps, step) }}
+	result, err := p.RunAgent(context.Background(), "look around", ShellContext{CWD: "/src"}, ChatContext{}, cfg)
+	if err != nil {
+		t.Fatalf("RunAgent() error = %v", err)
+	}
+
+	if len(requests) != 3 {
+		t.Fatalf("made %d requests, want 3", len(requests))
+	}
+	for i, req := range requests {
+		system := req["system"].([]any)
+		if system[0].(map[string]any)["cache_control"] == nil {
+			t.Errorf("request %d: system prompt is not cached", i+1)
+		}
+		var marked []int
+		messages := req["messages"].([]any)
+		for j, msg := range messages {
+			for _, block := range msg.(map[string]any)["content"].([]any) {
+				if block.(map[string]any)["cache_control"] != nil {
+					marked = append(marked, j)
+				}
+			}
+		}
+		if len(marked) != 1 || marked[0] != len(messages)-1 {
+			t.Errorf("request %d: cache breakpoints on messages %v, want only the last (%d)", i+1, marked, len(messages)-1)
+		}
+	}
+
+	want := PromptCache{Input: 150, Written: 3000, Read: 3000}
+	if result.Cache != want {
+		t.Errorf("Cache = %+v, want %+v", result.Cache, want)
+	}
+	if len(steps) != 3 || steps[1].ToolUses != 1 || steps[1].Cache.Read != 1000 || steps[2].Text != "Done." || steps[2].Output != 10 {
+		t.Errorf("steps = %+v", steps)
+	}
+}
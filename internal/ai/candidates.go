@@ -0,0 +1,47 @@
+package ai
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// candidatesPrompt asks for several alternative commands instead of one
+const candidatesPrompt = `
+
+Instead of a single command, reply with %d different commands that each fulfil the request, best first, as a JSON array of strings and nothing else, e.g. ["ls -la", "find . -maxdepth 1"]. This overrides rule 1. Prefer genuinely different approaches over small variations. A command that must run in another directory starts with its own working_dir line, e.g. "# working_dir: web\nnpm test".`
+
+// parseCandidates extracts the commands from a JSON array reply, each with
+// the working directory its hint line or leading cd names. Entries that
+// don't clean up into a valid command and duplicates are dropped. It returns
+// nil when the reply isn't an array.
+func parseCandidates(reply string) []Candidate {
+	start := strings.Index(reply, "[")
+	end := strings.LastIndex(reply, "]")
+	if start < 0 || end < start {
+		return nil
+	}
+
+	var raw []string
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &raw); err != nil {
+		return nil
+	}
+
+	var candidates []Candidate
+	seen := make(map[Candidate]bool)
+	for _, r := range raw {
+		dir, command := parseCommandReply(r)
+		if command == "" || validateCommand(command) != nil {
+			continue
+		}
+		if dir == "" {
+			dir, command = liftCdPrefix(command)
+		}
+		c := Candidate{Command: command, WorkingDir: dir}
+		if seen[c] {
+			continue
+		}
+		seen[c] = true
+		candidates = append(candidates, c)
+	}
+	return candidates
+}
@@ -0,0 +1,62 @@
+package ai
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCandidates(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply string
+		want  []Candidate
+	}{
+		{
+			name:  "plain array",
+			reply: `["ls -la", "find . -maxdepth 1"]`,
+			want:  []Candidate{{Command: "ls -la"}, {Command: "find . -maxdepth 1"}},
+		},
+		{
+			name:  "fenced array",
+			reply: "```json\n[\"git status\", \"git status -s\"]\n```",
+			want:  []Candidate{{Command: "git status"}, {Command: "git status -s"}},
+		},
+		{
+			name:  "drops duplicates and invalid entries",
+			reply: `["du -sh *", "du -sh *", "", "echo 'unterminated"]`,
+			want:  []Candidate{{Command: "du -sh *"}},
+		},
+		{
+			name:  "cleans each entry",
+			reply: "[\"`pwd`\", \"```\\nwhoami\\n```\"]",
+			want:  []Candidate{{Command: "pwd"}, {Command: "whoami"}},
+		},
+		{
+			name:  "working directory per command",
+			reply: `["# working_dir: web\nnpm test", "cd api && go test ./...", "make test"]`,
+			want: []Candidate{
+				{Command: "npm test", WorkingDir: "web"},
+				{Command: "go test ./...", WorkingDir: "api"},
+				{Command: "make test"},
+			},
+		},
+		{
+			name:  "not an array",
+			reply: "ls -la",
+			want:  nil,
+		},
+		{
+			name:  "command with brackets",
+			reply: "[ -f go.mod ] && echo yes",
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseCandidates(tt.reply); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseCandidates(%q) = %+v, want %+v", tt.reply, got, tt.want)
+			}
+		})
+	}
+}
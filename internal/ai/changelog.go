@@ -0,0 +1,52 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ChangelogCommit is a commit to describe in a changelog
+type ChangelogCommit struct {
+	Hash    string
+	Subject string
+	Body    string
+}
+
+// maxChangelogBody caps each commit body sent for a changelog
+const maxChangelogBody = 600
+
+// GenerateChangelog groups commits into a Markdown changelog section body
+// with Breaking Changes, Features, Fixes, and Other headings. The caller
+// adds the version heading.
+func (p *AnthropicProvider) GenerateChangelog(ctx context.Context, commits []ChangelogCommit) (string, error) {
+	systemPrompt := `You are bast, writing release notes from git commits.
+
+Group the commits under these Markdown headings, in this order, leaving out empty ones:
+### Breaking Changes
+### Features
+### Fixes
+### Other
+
+A commit is breaking when it says so ("BREAKING CHANGE", "!:" after the type) or clearly removes or changes behavior users rely on. Write one bullet per user-visible change, in plain language for users rather than developers, ending with the short hashes in parentheses, e.g. "- Add --json output to status (a1b2c3d)". Merge commits that describe the same change into one bullet. Put internal changes (refactors, tests, CI, dependency bumps) under Other, briefly.
+
+Reply with only the headings and bullets: no title, version, or commentary.` + untrustedNotice
+
+	var b strings.Builder
+	for _, c := range commits {
+		fmt.Fprintf(&b, "%s %s\n", c.Hash, c.Subject)
+		if body := strings.TrimSpace(c.Body); body != "" {
+			if len(body) > maxChangelogBody {
+				body = body[:maxChangelogBody] + "..."
+			}
+			fmt.Fprintf(&b, "  %s\n", strings.ReplaceAll(body, "\n", "\n  "))
+		}
+	}
+	userPrompt := fmt.Sprintf("Commits, newest first:\n%s", wrapUntrusted("git log", b.String()))
+
+	result, err := p.singleTurn(ctx, systemPrompt, userPrompt, 2048)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate changelog: %w", err)
+	}
+	return result.Response, nil
+}
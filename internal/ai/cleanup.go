@@ -0,0 +1,111 @@
+package ai
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// preambleRe matches lines models put before a command despite instructions
+	preambleRe = regexp.MustCompile(`(?i)^(here('s| is| are)\b|sure\b|certainly\b|of course\b|okay\b|ok,|you can\b|the following\b|this (command|will)\b|i('ll| will| would)\b|to [a-z]+\b.*:$|note:)`)
+
+	// explanationRe matches lines models put after a command to explain it
+	explanationRe = regexp.MustCompile(`(?i)^(this (command )?(will|lists|finds|shows|prints|deletes|removes|creates|searches|counts)\b|the (above|command)\b|note:|explanation:)`)
+
+	// shellOperatorRe matches characters that make a line look like shell code
+	shellOperatorRe = regexp.MustCompile("[|&;<>$`=]|--?[a-zA-Z]")
+)
+
+// stripProse drops natural-language lines before and after the command in a
+// model reply, e.g. "Here's the command:" or "This will list all files."
+func stripProse(reply string) string {
+	lines := strings.Split(strings.TrimSpace(reply), "\n")
+	for len(lines) > 1 && isProseLine(lines[0], preambleRe) {
+		lines = lines[1:]
+	}
+	for len(lines) > 1 && isProseLine(lines[len(lines)-1], explanationRe) {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// isProseLine reports whether line is prose rather than part of a command:
+// it matches re, or it is a sentence ending in a colon with no shell syntax
+func isProseLine(line string, re *regexp.Regexp) bool {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return true
+	}
+	if re.MatchString(line) {
+		return true
+	}
+	return strings.HasSuffix(line, ":") && strings.Contains(line, " ") && !shellOperatorRe.MatchString(line)
+}
+
+// validateCommand checks that a cleaned reply looks like a complete shell
+// command: no leftover prose, balanced quotes and parentheses, and no
+// trailing operator. It is a lexical check, not a full shell parser.
+func validateCommand(command string) error {
+	if command == "" {
+		return fmt.Errorf("empty command")
+	}
+	firstLine := strings.SplitN(command, "\n", 2)[0]
+	if isProseLine(firstLine, preambleRe) {
+		return fmt.Errorf("starts with prose: %q", firstLine)
+	}
+	// Heredoc bodies are free text, so their quotes prove nothing
+	if strings.Contains(command, "<<") {
+		return nil
+	}
+
+	var quote rune // ', ", or ` while inside quotes
+	depth := 0     // Open ( outside quotes
+	escaped := false
+	atWordStart := true
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '#' && atWordStart:
+			// Comment: skip to the end of the line
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+			if depth < 0 {
+				return fmt.Errorf("unbalanced parentheses")
+			}
+		}
+		atWordStart = r == ' ' || r == '\t' || r == '\n' || r == ';' || r == '|' || r == '&'
+	}
+	switch {
+	case quote != 0:
+		return fmt.Errorf("unterminated %c quote", quote)
+	case depth != 0:
+		return fmt.Errorf("unbalanced parentheses")
+	case escaped:
+		return fmt.Errorf("ends with a line continuation")
+	}
+
+	trimmed := strings.TrimSpace(command)
+	for _, op := range []string{"&&", "||", "|"} {
+		if strings.HasSuffix(trimmed, op) {
+			return fmt.Errorf("ends with %q", op)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package ai
+
+import "testing"
+
+func TestStripProse(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply string
+		want  string
+	}{
+		{"plain command", "ls -la", "ls -la"},
+		{"here is", "Here is the command you need:\nfind . -name '*.go'", "find . -name '*.go'"},
+		{"to do x colon", "To find large files:\nfind . -size +100M", "find . -size +100M"},
+		{"sentence ending in colon", "Run this in the repo root:\ngo test ./...", "go test ./..."},
+		{"several preamble lines", "Sure.\n\nYou can use:\ngit log --oneline", "git log --oneline"},
+		{"trailing note", "rm -i *.tmp\nNote: this asks before each file.", "rm -i *.tmp"},
+		{"single prose line kept", "Here's nothing useful", "Here's nothing useful"},
+		{"multiline command kept", "for f in *.go; do\n  gofmt -l \"$f\"\ndone", "for f in *.go; do\n  gofmt -l \"$f\"\ndone"},
+		{"command ending in colon-free line kept", "echo start\necho done", "echo start\necho done"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stripProse(tt.reply); got != tt.want {
+				t.Errorf("stripProse(%q) = %q, want %q", tt.reply, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateCommand(t *testing.T) {
+	valid := []string{
+		"ls -la",
+		`git commit -m "it's done"`,
+		"echo 'a \"quoted\" word'",
+		"echo $(date) `whoami`",
+		"ls | wc -l # count the files, it's quick",
+		"echo ${#PATH}",
+		"cat <<EOF\nit's a heredoc\nEOF",
+		"ls -la && \\\npwd",
+	}
+	for _, cmd := range valid {
+		if err := validateCommand(cmd); err != nil {
+			t.Errorf("validateCommand(%q) = %v, want nil", cmd, err)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"Here's the command",
+		`echo "unterminated`,
+		"echo 'unterminated",
+		"echo $(date",
+		"echo )",
+		"ls |",
+		"make &&",
+		"ls \\",
+	}
+	for _, cmd := range invalid {
+		if err := validateCommand(cmd); err == nil {
+			t.Errorf("validateCommand(%q) = nil, want an error", cmd)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/anthropics/anthropic-sdk-go"
+
+	"github.com/bastio-ai/bast/internal/safety"
+	"github.com/bastio-ai/bast/internal/tools"
+)
+
+// MaxRepeatedToolCalls is how many repeated identical tool calls an agent
+// run tolerates before it is stopped as looping
+const MaxRepeatedToolCalls = 3
+
+// repeatedCallNotice follows a repeated call's earlier result, so the model
+// knows nothing new ran
+const repeatedCallNotice = "\n\n[bast: this call is identical to an earlier one in this task, so it was not run again and its earlier result is repeated above. Try a different approach or give your final answer.]"
+
+// toolCallGuard answers an agent's repeated identical tool calls from the
+// earlier result instead of running them again. A call that may have side
+// effects clears what it has seen, since later identical calls can then
+// return something different; so only repeats with nothing but read-only
+// calls (or nothing at all) in between are caught.
+type toolCallGuard struct {
+	seen    map[string]guardedCall
+	repeats int
+	anon    *safety.Anonymizer // Applied to results before the model sees them
+}
+
+// guardedCall is an earlier call and the result content the model got for it
+type guardedCall struct {
+	call    ToolCall
+	content string
+}
+
+func newToolCallGuard(anon *safety.Anonymizer) *toolCallGuard {
+	return &toolCallGuard{seen: make(map[string]guardedCall), anon: anon}
+}
+
+// run executes call, or repeats the earlier result for an identical call
+func (g *toolCallGuard) run(ctx context.Context, cfg AgentConfig, call ToolCall) (ToolCall, anthropic.ContentBlockParamUnion) {
+	key := toolCallKey(call)
+	if earlier, ok := g.seen[key]; ok {
+		g.repeats++
+		repeat := earlier.call
+		repeat.ID = call.ID
+		repeat.Repeated = true
+		repeat.Duration = 0
+		// The guardrails did nothing new for the repeat
+		repeat.Warnings, repeat.Threats, repeat.Verdicts = nil, nil, nil
+		return repeat, anthropic.NewToolResultBlock(call.ID, earlier.content+repeatedCallNotice, repeat.IsError)
+	}
+
+	call, content := executeToolCall(ctx, cfg, call)
+	content = g.anon.Anonymize(content)
+	if !tools.IsReadOnly(call.Name) {
+		clear(g.seen)
+	}
+	g.seen[key] = guardedCall{call: call, content: content}
+	return call, anthropic.NewToolResultBlock(call.ID, content, call.IsError)
+}
+
+// looping reports whether the run has repeated itself too often to go on
+func (g *toolCallGuard) looping() bool {
+	return g.repeats > MaxRepeatedToolCalls
+}
+
+// toolCallKey identifies a call by tool name and input, ignoring JSON
+// whitespace and key order
+func toolCallKey(call ToolCall) string {
+	input := string(call.Input)
+	var decoded any
+	if err := json.Unmarshal(call.Input, &decoded); err == nil {
+		if canonical, err := json.Marshal(decoded); err == nil {
+			input = string(canonical)
+		}
+	}
+	return call.Name + "\x00" + input
+}
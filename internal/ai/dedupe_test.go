@@ -0,0 +1,83 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bastio-ai/bast/internal/tools"
+)
+
+// countingTool records how often it runs
+type countingTool struct {
+	name string
+	runs int
+}
+
+func (t *countingTool) Name() string                   { return t.name }
+func (t *countingTool) Description() string            { return "counts runs" }
+func (t *countingTool) InputSchema() tools.InputSchema { return tools.InputSchema{Type: "object"} }
+func (t *countingTool) Execute(ctx context.Context, input json.RawMessage) (*tools.Result, error) {
+	t.runs++
+	return &tools.Result{Output: "ok"}, nil
+}
+
+func TestToolCallGuard(t *testing.T) {
+	read := &countingTool{name: "read_file"}
+	write := &countingTool{name: "write_file"}
+	registry := tools.NewRegistry()
+	registry.Register(read)
+	registry.Register(write)
+	cfg := AgentConfig{Registry: registry}
+	guard := newToolCallGuard(nil)
+
+	call := func(name, input string) ToolCall {
+		got, _ := guard.run(context.Background(), cfg, ToolCall{ID: "toolu_1", Name: name, Input: json.RawMessage(input)})
+		return got
+	}
+
+	call("read_file", `{"path": "main.go"}`)
+	repeat := call("read_file", `{ "path":"main.go" }`)
+	if read.runs != 1 || !repeat.Repeated || repeat.Output != "ok" {
+		t.Errorf("identical read ran %d times, repeat = %+v", read.runs, repeat)
+	}
+	if call("read_file", `{"path": "go.mod"}`).Repeated {
+		t.Error("call with different input was treated as a repeat")
+	}
+
+	// A call with side effects means reads may now return something else
+	call("write_file", `{"path": "main.go"}`)
+	if call("read_file", `{"path": "main.go"}`).Repeated || read.runs != 3 {
+		t.Errorf("read after a write was not run again (%d runs)", read.runs)
+	}
+
+	if guard.looping() {
+		t.Fatal("looping after one repeat")
+	}
+	for i := 0; i < MaxRepeatedToolCalls; i++ {
+		call("read_file", `{"path": "main.go"}`)
+	}
+	if !guard.looping() {
+		t.Errorf("not looping after %d repeats", guard.repeats)
+	}
+}
+
+func TestToolCallGuard_Notice(t *testing.T) {
+	registry := tools.NewRegistry()
+	registry.Register(&countingTool{name: "list_directory"})
+	cfg := AgentConfig{Registry: registry}
+	guard := newToolCallGuard(nil)
+
+	input := json.RawMessage(`{"path": "."}`)
+	guard.run(context.Background(), cfg, ToolCall{ID: "toolu_1", Name: "list_directory", Input: input})
+	_, block := guard.run(context.Background(), cfg, ToolCall{ID: "toolu_2", Name: "list_directory", Input: input})
+
+	data, err := json.Marshal(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "toolu_2") || !strings.Contains(string(data), "not run again") {
+		t.Errorf("repeated result block = %s", data)
+	}
+}
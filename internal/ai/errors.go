@@ -0,0 +1,113 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// ErrAuth is returned when the API rejects the configured credentials
+type ErrAuth struct {
+	Err error
+}
+
+func (e *ErrAuth) Error() string { return fmt.Sprintf("authentication failed: %v", e.Err) }
+func (e *ErrAuth) Unwrap() error { return e.Err }
+
+// ErrRateLimited is returned when the API rate limit has been hit.
+// RetryAfter is zero when the API didn't say how long to wait.
+type ErrRateLimited struct {
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *ErrRateLimited) Error() string { return fmt.Sprintf("rate limited: %v", e.Err) }
+func (e *ErrRateLimited) Unwrap() error { return e.Err }
+
+// ErrContextTooLong is returned when the prompt exceeds the model's context window
+type ErrContextTooLong struct {
+	Err error
+}
+
+func (e *ErrContextTooLong) Error() string { return fmt.Sprintf("context too long: %v", e.Err) }
+func (e *ErrContextTooLong) Unwrap() error { return e.Err }
+
+// ErrOverloaded is returned when the API is temporarily overloaded or unavailable
+type ErrOverloaded struct {
+	Err error
+}
+
+func (e *ErrOverloaded) Error() string { return fmt.Sprintf("API overloaded: %v", e.Err) }
+func (e *ErrOverloaded) Unwrap() error { return e.Err }
+
+// ErrNetwork is returned when the API could not be reached or timed out
+type ErrNetwork struct {
+	Err error
+}
+
+func (e *ErrNetwork) Error() string { return fmt.Sprintf("network error: %v", e.Err) }
+func (e *ErrNetwork) Unwrap() error { return e.Err }
+
+// IsRetryable reports whether retrying the same request may succeed
+func IsRetryable(err error) bool {
+	var rateLimited *ErrRateLimited
+	var overloaded *ErrOverloaded
+	var network *ErrNetwork
+	return errors.As(err, &rateLimited) || errors.As(err, &overloaded) || errors.As(err, &network)
+}
+
+// classifyError converts an API client error into one of the typed errors above.
+// Errors that don't match a known category are returned unchanged.
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var apiErr *anthropic.Error
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden:
+			return &ErrAuth{Err: err}
+		case apiErr.StatusCode == http.StatusTooManyRequests:
+			return &ErrRateLimited{RetryAfter: retryAfter(apiErr.Response), Err: err}
+		case apiErr.StatusCode == 529 || apiErr.StatusCode >= 500:
+			return &ErrOverloaded{Err: err}
+		case apiErr.StatusCode == http.StatusBadRequest && isContextLengthError(apiErr.Error()):
+			return &ErrContextTooLong{Err: err}
+		}
+		return err
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) {
+		return &ErrNetwork{Err: err}
+	}
+	return err
+}
+
+// isContextLengthError checks an API error message for prompt-size failures
+func isContextLengthError(message string) bool {
+	message = strings.ToLower(message)
+	return strings.Contains(message, "prompt is too long") ||
+		strings.Contains(message, "context window") ||
+		strings.Contains(message, "context length")
+}
+
+// retryAfter parses the Retry-After header (seconds) from a response
+func retryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,89 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+func newAPIError(t *testing.T, status int, header http.Header, body string) error {
+	t.Helper()
+	apiErr := &anthropic.Error{
+		StatusCode: status,
+		Request:    httptest.NewRequest("POST", "https://api.anthropic.com/v1/messages", nil),
+		Response:   &http.Response{StatusCode: status, Header: header},
+	}
+	if body != "" {
+		if err := apiErr.UnmarshalJSON([]byte(body)); err != nil {
+			t.Fatalf("failed to build API error: %v", err)
+		}
+	}
+	return apiErr
+}
+
+func TestClassifyError(t *testing.T) {
+	t.Run("auth", func(t *testing.T) {
+		err := classifyError(newAPIError(t, http.StatusUnauthorized, http.Header{}, ""))
+		var target *ErrAuth
+		if !errors.As(err, &target) {
+			t.Errorf("expected ErrAuth, got %T", err)
+		}
+	})
+
+	t.Run("rate limited with retry-after", func(t *testing.T) {
+		header := http.Header{}
+		header.Set("Retry-After", "7")
+		err := fmt.Errorf("failed to run agent: %w", classifyError(newAPIError(t, http.StatusTooManyRequests, header, "")))
+		var target *ErrRateLimited
+		if !errors.As(err, &target) {
+			t.Fatalf("expected ErrRateLimited, got %T", err)
+		}
+		if target.RetryAfter != 7*time.Second {
+			t.Errorf("expected 7s retry, got %s", target.RetryAfter)
+		}
+		if !IsRetryable(err) {
+			t.Error("expected rate limit to be retryable")
+		}
+	})
+
+	t.Run("overloaded", func(t *testing.T) {
+		err := classifyError(newAPIError(t, 529, http.Header{}, ""))
+		var target *ErrOverloaded
+		if !errors.As(err, &target) {
+			t.Errorf("expected ErrOverloaded, got %T", err)
+		}
+	})
+
+	t.Run("context too long", func(t *testing.T) {
+		body := `{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long: 210000 tokens > 200000 maximum"}}`
+		err := classifyError(newAPIError(t, http.StatusBadRequest, http.Header{}, body))
+		var target *ErrContextTooLong
+		if !errors.As(err, &target) {
+			t.Errorf("expected ErrContextTooLong, got %T", err)
+		}
+		if IsRetryable(err) {
+			t.Error("expected context length error not to be retryable")
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		err := classifyError(fmt.Errorf("post: %w", context.DeadlineExceeded))
+		var target *ErrNetwork
+		if !errors.As(err, &target) {
+			t.Errorf("expected ErrNetwork, got %T", err)
+		}
+	})
+
+	t.Run("unknown errors pass through", func(t *testing.T) {
+		orig := errors.New("boom")
+		if err := classifyError(orig); err != orig {
+			t.Errorf("expected original error, got %v", err)
+		}
+	})
+}
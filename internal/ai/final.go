@@ -0,0 +1,110 @@
+package ai
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// AgentStatus is how an agent run says its task went
+type AgentStatus string
+
+const (
+	AgentStatusSuccess AgentStatus = "success" // The task is done
+	AgentStatusPartial AgentStatus = "partial" // Some of it is done, or done with caveats
+	AgentStatusFailed  AgentStatus = "failed"  // The task could not be done
+)
+
+// MaxFollowUps is the most follow-up suggestions kept from a final answer
+const MaxFollowUps = 3
+
+// Tags around the final answer block. The closing tag is also a stop
+// sequence, so the model ends its reply there.
+const (
+	finalAnswerOpen  = "<final_answer>"
+	finalAnswerClose = "</final_answer>"
+)
+
+// finalAnswerPrompt asks the agent to close its last reply with a final
+// answer block
+const finalAnswerPrompt = `
+
+When the task is finished, or you cannot go further, reply without calling tools: your answer for the user in markdown, then a final answer block as the very last thing, with JSON inside:
+<final_answer>{"status": "success", "summary": "One sentence on what was done", "follow_ups": ["A next step the user may want"]}</final_answer>
+status is "success" when the task is done, "partial" when only some of it is done or it needs the user to finish, and "failed" when it could not be done. follow_ups has at most 3 short requests the user could send next, phrased as they would type them, or is empty.`
+
+// finalAnswer is the block the agent ends its last reply with
+type finalAnswer struct {
+	Status    AgentStatus `json:"status"`
+	Summary   string      `json:"summary"`
+	FollowUps []string    `json:"follow_ups"`
+}
+
+// parseFinalAnswer splits the final answer block off a reply. It returns
+// the reply without the block, and a nil answer when there is no valid
+// block, leaving the reply as it was.
+func parseFinalAnswer(reply string) (string, *finalAnswer) {
+	start := strings.LastIndex(reply, finalAnswerOpen)
+	if start < 0 {
+		return reply, nil
+	}
+	body := reply[start+len(finalAnswerOpen):]
+	if end := strings.Index(body, finalAnswerClose); end >= 0 {
+		body = body[:end]
+	}
+
+	var answer finalAnswer
+	if err := json.Unmarshal([]byte(extractJSON(body)), &answer); err != nil {
+		return reply, nil
+	}
+	switch answer.Status {
+	case AgentStatusSuccess, AgentStatusPartial, AgentStatusFailed:
+	default:
+		answer.Status = ""
+	}
+	answer.Summary = strings.TrimSpace(answer.Summary)
+	answer.FollowUps = cleanFollowUps(answer.FollowUps)
+
+	return strings.TrimSpace(reply[:start]), &answer
+}
+
+// Tags around the follow-ups that close a chat reply. The closing tag is
+// also a stop sequence.
+const (
+	followUpsOpen  = "<follow_ups>"
+	followUpsClose = "</follow_ups>"
+)
+
+// followUpsPrompt asks for suggested next requests at the end of a chat reply
+const followUpsPrompt = `
+
+End every reply with up to 3 short requests the user could send next, phrased as they would type them, as a JSON array in follow_ups tags, e.g. <follow_ups>["show the largest files"]</follow_ups>. Use <follow_ups>[]</follow_ups> when nothing obvious follows.`
+
+// parseFollowUps splits the follow-ups off a chat reply. It returns the
+// reply as it was, and no follow-ups, when there is no valid list.
+func parseFollowUps(reply string) (string, []string) {
+	start := strings.LastIndex(reply, followUpsOpen)
+	if start < 0 {
+		return reply, nil
+	}
+	body := reply[start+len(followUpsOpen):]
+	if end := strings.Index(body, followUpsClose); end >= 0 {
+		body = body[:end]
+	}
+
+	var followUps []string
+	if err := json.Unmarshal([]byte(extractJSON(body)), &followUps); err != nil {
+		return reply, nil
+	}
+	return strings.TrimSpace(reply[:start]), cleanFollowUps(followUps)
+}
+
+// cleanFollowUps trims follow-ups, drops empty ones, and keeps MaxFollowUps
+func cleanFollowUps(followUps []string) []string {
+	var cleaned []string
+	for _, f := range followUps {
+		if f = strings.TrimSpace(f); f != "" && len(cleaned) < MaxFollowUps {
+			cleaned = append(cleaned, f)
+		}
+	}
+	return cleaned
+}
@@ -0,0 +1,89 @@
+package ai
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFinalAnswer(t *testing.T) {
+	tests := []struct {
+		name     string
+		reply    string
+		response string
+		want     *finalAnswer
+	}{
+		{
+			name:     "closed block",
+			reply:    "Updated the config.\n\n<final_answer>{\"status\": \"success\", \"summary\": \"Set the port\", \"follow_ups\": [\"restart the server\"]}</final_answer>",
+			response: "Updated the config.",
+			want:     &finalAnswer{Status: AgentStatusSuccess, Summary: "Set the port", FollowUps: []string{"restart the server"}},
+		},
+		{
+			name:     "cut at the stop sequence",
+			reply:    "Tests still fail.\n<final_answer>\n{\"status\": \"partial\", \"summary\": \"Fixed 2 of 3\"}\n",
+			response: "Tests still fail.",
+			want:     &finalAnswer{Status: AgentStatusPartial, Summary: "Fixed 2 of 3"},
+		},
+		{
+			name:     "follow-ups trimmed and capped",
+			reply:    `<final_answer>{"status": "failed", "follow_ups": ["a", " ", "b", "c", "d"]}`,
+			response: "",
+			want:     &finalAnswer{Status: AgentStatusFailed, FollowUps: []string{"a", "b", "c"}},
+		},
+		{
+			name:     "unknown status",
+			reply:    `Done <final_answer>{"status": "great"}</final_answer>`,
+			response: "Done",
+			want:     &finalAnswer{},
+		},
+		{
+			name:     "no block",
+			reply:    "Just an answer",
+			response: "Just an answer",
+		},
+		{
+			name:     "invalid block is left in place",
+			reply:    "Answer <final_answer>not json</final_answer>",
+			response: "Answer <final_answer>not json</final_answer>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, got := parseFinalAnswer(tt.reply)
+			if response != tt.response {
+				t.Errorf("response = %q, want %q", response, tt.response)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("answer = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFollowUps(t *testing.T) {
+	tests := []struct {
+		name      string
+		reply     string
+		response  string
+		followUps []string
+	}{
+		{"closed list", "Use du.\n\n<follow_ups>[\"sort by size\", \"only show dirs\"]</follow_ups>", "Use du.", []string{"sort by size", "only show dirs"}},
+		{"cut at the stop sequence", "Use du.\n<follow_ups>[\"sort by size\"]", "Use du.", []string{"sort by size"}},
+		{"empty list", "Done.<follow_ups>[]</follow_ups>", "Done.", nil},
+		{"no list", "Done.", "Done.", nil},
+		{"invalid list is left in place", "Done.<follow_ups>maybe</follow_ups>", "Done.<follow_ups>maybe</follow_ups>", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, followUps := parseFollowUps(tt.reply)
+			if response != tt.response {
+				t.Errorf("response = %q, want %q", response, tt.response)
+			}
+			if !reflect.DeepEqual(followUps, tt.followUps) {
+				t.Errorf("follow-ups = %q, want %q", followUps, tt.followUps)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+// replySeeds are model replies in the shapes cleanup has to handle
+var replySeeds = []string{
+	"ls -la",
+	"```bash\nfind . -name '*.go'\n```",
+	"```json\n{\"command\": \"ls\"}\n```",
+	"Here is the command:\n`du -sh *`\nThis shows sizes.",
+	"```\n```",
+	"```",
+	"1. git status\n2. git add -A",
+	"echo \"unbalanced",
+}
+
+func FuzzCleanCommand(f *testing.F) {
+	for _, seed := range replySeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, reply string) {
+		cleaned := cleanCommand(reply)
+		if cleaned != strings.TrimSpace(cleaned) {
+			t.Errorf("cleanCommand(%q) = %q, not trimmed", reply, cleaned)
+		}
+		validateCommand(cleaned)
+		parseCandidates(reply)
+	})
+}
+
+func FuzzExtractJSON(f *testing.F) {
+	for _, seed := range replySeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, reply string) {
+		if got := extractJSON(reply); !strings.Contains(reply, got) {
+			t.Errorf("extractJSON(%q) = %q, not part of the reply", reply, got)
+		}
+	})
+}
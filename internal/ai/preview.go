@@ -0,0 +1,59 @@
+package ai
+
+// ContextPreview describes what a command or chat request sends to the model
+type ContextPreview struct {
+	Sections        []PromptSection // System prompt sections, in order
+	SystemBytes     int             // Size of the assembled system prompt
+	Files           []FilePreview   // Attached files (chat only)
+	Probes          []string        // Machine load probes taken (chat only, opt-in)
+	HistoryMessages int             // Earlier conversation messages resent with the query
+	HistoryBytes    int
+	QueryBytes      int
+}
+
+// FilePreview describes one attached file
+type FilePreview struct {
+	Path  string
+	Bytes int
+	Error string // Why the file's contents were not included
+}
+
+// PreviewCommand returns what GenerateCommand would send for query
+func PreviewCommand(query string, shellCtx ShellContext) *ContextPreview {
+	sections := commandSections(query, shellCtx)
+	return &ContextPreview{
+		Sections:    sections,
+		SystemBytes: len(joinSections(sections)),
+		QueryBytes:  len(query),
+	}
+}
+
+// PreviewChat returns what Chat would send for query
+func PreviewChat(query string, shellCtx ShellContext, chatCtx ChatContext) *ContextPreview {
+	sections := chatSections(shellCtx, chatCtx)
+	preview := &ContextPreview{
+		Sections:        sections,
+		SystemBytes:     len(joinSections(sections)),
+		HistoryMessages: len(chatCtx.History),
+		QueryBytes:      len(query),
+	}
+	for _, r := range shellCtx.Probes {
+		preview.Probes = append(preview.Probes, r.Name)
+	}
+	for _, msg := range chatCtx.History {
+		preview.HistoryBytes += len(msg.Content)
+	}
+	for _, f := range chatCtx.Files {
+		preview.Files = append(preview.Files, FilePreview{Path: f.Path, Bytes: len(f.Content), Error: f.Error})
+	}
+	return preview
+}
+
+// Redactions returns the credential kinds redacted across all sections
+func (p *ContextPreview) Redactions() []string {
+	var kinds []string
+	for _, s := range p.Sections {
+		kinds = appendUnique(kinds, s.Redacted...)
+	}
+	return kinds
+}
@@ -0,0 +1,213 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bastio-ai/bast/internal/files"
+	"github.com/bastio-ai/bast/internal/safety"
+)
+
+// PromptSection is one named part of a system prompt
+type PromptSection struct {
+	Name     string
+	Text     string
+	Redacted []string // Credential kinds replaced with [REDACTED:<kind>] markers
+}
+
+// commandInstructions is the base prompt for command generation
+const commandInstructions = `You are bast, an AI shell assistant. Your job is to generate shell commands based on the user's request.
+
+IMPORTANT RULES:
+1. Respond with ONLY the shell command - no explanations, no markdown, no code blocks
+2. The command should be safe and appropriate for the user's environment
+3. Use the provided context (current directory, OS, shell, git status) to generate appropriate commands
+4. If the request is ambiguous, generate the most likely intended command
+5. Never include commands that could be destructive without explicit confirmation markers
+6. For git operations, consider the current branch and repository state
+7. If the command must run in another directory (such as one package of a monorepo), don't start it with "cd dir &&". Instead put "# working_dir: dir" on the first line, with dir relative to the working directory, and the command on the next line
+8. Dates and times are in the user's timezone below, not UTC: "yesterday" or "this morning" mean the user's local calendar, and cron schedules fire in the system timezone. Use the date syntax of the operating system (GNU "date -d" on linux, BSD "date -v" on darwin)
+
+Current environment:
+- Working directory: %s
+- Operating system: %s
+- Shell: %s
+- User: %s`
+
+// chatInstructions is the base prompt for chat answers
+const chatInstructions = `You are bast, an AI shell assistant. The user is asking a question or wants information.
+Provide a helpful, concise response.
+
+Current environment:
+- Working directory: %s
+- Operating system: %s
+- Shell: %s
+
+Keep responses brief and terminal-friendly (no long paragraphs).
+If the user asks for something that would be better accomplished with a command, suggest they rephrase their request.`
+
+// commandSections returns the system prompt sections for GenerateCommand
+func commandSections(query string, shellCtx ShellContext) []PromptSection {
+	instructions := fmt.Sprintf(commandInstructions, shellCtx.CWD, shellCtx.OS, shellCtx.Shell, shellCtx.User)
+	instructions += formatLocale(shellCtx)
+	instructions += formatLastCommand(shellCtx)
+
+	sections := []PromptSection{
+		{Name: "Instructions and environment", Text: instructions},
+		{Name: "Git", Text: formatGitContext(shellCtx.Git)},
+		{Name: "direnv", Text: formatDirenvContext(shellCtx.Direnv)},
+		{Name: "Tool versions", Text: formatToolVersions(shellCtx.Tools)},
+		{Name: "Language environments", Text: formatRuntimeEnvs(shellCtx.Runtimes)},
+		{Name: "Workspace", Text: formatWorkspaceContext(shellCtx.Workspace)},
+		{Name: "Aliases", Text: formatAliasContext(shellCtx.Aliases, query)},
+		{Name: "Examples", Text: formatExamples(shellCtx.Examples)},
+		{Name: "Pinned", Text: formatPinnedContext(shellCtx.Pinned)},
+	}
+	sections = append(sections, shellHistorySections(shellCtx)...)
+	return nonEmpty(sections)
+}
+
+// chatSections returns the system prompt sections for Chat
+func chatSections(shellCtx ShellContext, chatCtx ChatContext) []PromptSection {
+	instructions := fmt.Sprintf(chatInstructions, shellCtx.CWD, shellCtx.OS, shellCtx.Shell)
+	instructions += formatLocale(shellCtx)
+	instructions += followUpsPrompt
+	instructions += formatLastCommand(shellCtx)
+
+	sections := []PromptSection{
+		{Name: "Instructions and environment", Text: instructions},
+		{Name: "Git", Text: formatGitContext(shellCtx.Git)},
+		{Name: "direnv", Text: formatDirenvContext(shellCtx.Direnv)},
+		{Name: "Tool versions", Text: formatToolVersions(shellCtx.Tools)},
+		{Name: "Language environments", Text: formatRuntimeEnvs(shellCtx.Runtimes)},
+		{Name: "Workspace", Text: formatWorkspaceContext(shellCtx.Workspace)},
+		{Name: "Pinned", Text: formatPinnedContext(shellCtx.Pinned)},
+		{Name: "System probes", Text: formatProbes(shellCtx.Probes)},
+	}
+	sections = append(sections, shellHistorySections(shellCtx)...)
+	sections = append(sections, fileSection(chatCtx.Files))
+	return nonEmpty(sections)
+}
+
+// formatLocale formats the timezone and locale as environment lines, so
+// relative dates and cron schedules are worked out in local time
+func formatLocale(shellCtx ShellContext) string {
+	var b strings.Builder
+	if shellCtx.Timezone != "" {
+		fmt.Fprintf(&b, "\n- Timezone: %s", shellCtx.Timezone)
+	}
+	if shellCtx.Locale != "" {
+		fmt.Fprintf(&b, "\n- Locale: %s", shellCtx.Locale)
+	}
+	return b.String()
+}
+
+// formatProbes formats a snapshot of machine load, taken for questions
+// about why something is slow
+func formatProbes(readings []ProbeReading) string {
+	if len(readings) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n\nSystem load right now (consider it when explaining slowness; it may be unrelated):\n")
+	for _, r := range readings {
+		fmt.Fprintf(&b, "- %s: %s\n", r.Name, r.Value)
+	}
+	return b.String()
+}
+
+// formatLastCommand formats the previous command as an environment line
+func formatLastCommand(shellCtx ShellContext) string {
+	if shellCtx.LastCommand == "" {
+		return ""
+	}
+	return fmt.Sprintf("\n- Last command: %s (exit status: %d)", shellCtx.LastCommand, shellCtx.ExitStatus)
+}
+
+// shellHistorySections returns the recent history and last output sections.
+// Credentials in captured output are redacted.
+func shellHistorySections(shellCtx ShellContext) []PromptSection {
+	var history strings.Builder
+	if len(shellCtx.History) > 0 {
+		history.WriteString("\n\nRecent command history:\n")
+		for _, cmd := range shellCtx.History {
+			fmt.Fprintf(&history, "$ %s\n", cmd)
+		}
+	}
+
+	output := PromptSection{Name: "Last command output"}
+	if shellCtx.LastOutput != "" {
+		content, found := safety.RedactSecrets(shellCtx.LastOutput)
+		output.Text = fmt.Sprintf("\nLast command output:\n%s\n", wrapUntrusted("last command output", content))
+		output.Redacted = found
+	}
+
+	stderr := PromptSection{Name: "Last command stderr"}
+	if shellCtx.LastError != "" {
+		content, found := safety.RedactSecrets(shellCtx.LastError)
+		stderr.Text = fmt.Sprintf("\nLast command stderr:\n%s\n", wrapUntrusted("last command stderr", content))
+		stderr.Redacted = found
+	}
+
+	return []PromptSection{{Name: "Command history", Text: history.String()}, output, stderr}
+}
+
+// fileSection formats attached files, numbered so answers can cite lines.
+// Credentials in file contents are redacted.
+func fileSection(referenced []files.FileContent) PromptSection {
+	section := PromptSection{Name: "Files"}
+	if len(referenced) == 0 {
+		return section
+	}
+	var b strings.Builder
+	b.WriteString("\n\nFile contents available for reference (each line is prefixed with its number):")
+	for _, f := range referenced {
+		if f.Error != "" {
+			fmt.Fprintf(&b, "\n\n--- %s ---\n[Error: %s]", f.Path, f.Error)
+			continue
+		}
+		content, found := safety.RedactSecrets(f.Content)
+		section.Redacted = appendUnique(section.Redacted, found...)
+		fmt.Fprintf(&b, "\n\n%s", wrapUntrusted(f.Path, files.NumberLines(content)))
+	}
+	b.WriteString("\n\nWhen a statement relies on these files, cite the lines right after it as [path:start-end] (or [path:line]), using the path in the source attribute. Only cite lines you were shown.")
+	section.Text = b.String()
+	return section
+}
+
+// joinSections builds the system prompt from its sections
+func joinSections(sections []PromptSection) string {
+	var b strings.Builder
+	for _, s := range sections {
+		b.WriteString(s.Text)
+	}
+	return withUntrustedNotice(b.String())
+}
+
+// nonEmpty drops sections with no text
+func nonEmpty(sections []PromptSection) []PromptSection {
+	kept := sections[:0]
+	for _, s := range sections {
+		if s.Text != "" {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
+
+// appendUnique appends the values not already in list
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, existing := range list {
+			if existing == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, v)
+		}
+	}
+	return list
+}
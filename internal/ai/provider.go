@@ -3,6 +3,7 @@ package ai
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/bastio-ai/bast/internal/files"
 	"github.com/bastio-ai/bast/internal/tools"
@@ -29,6 +30,20 @@ type IntentResult struct {
 type CommandResult struct {
 	Command     string
 	Explanation string
+
+	// Alternatives are further candidate commands, in the model's order of
+	// preference, when several were requested
+	Alternatives []Candidate
+
+	// WorkingDir is where Command must run, relative to the working
+	// directory unless absolute, or empty to run it where it is
+	WorkingDir string
+}
+
+// Candidate is one of several commands generated for a request
+type Candidate struct {
+	Command    string
+	WorkingDir string // As CommandResult.WorkingDir, for this command
 }
 
 // FixResult represents the result of an error fix request
@@ -40,14 +55,22 @@ type FixResult struct {
 
 // ChatResult holds the response for chat intents
 type ChatResult struct {
-	Response string
+	Response  string
+	FollowUps []string // Requests the user might send next (Chat only)
 }
 
 // AgentResult holds the result of an agentic task
 type AgentResult struct {
-	Response   string       // Final response text
-	ToolCalls  []ToolCall   // All tool calls made during execution
-	Iterations int          // Number of API round-trips
+	Response   string        // Final response text
+	ToolCalls  []ToolCall    // All tool calls made during execution
+	Iterations int           // Number of API round-trips
+	Threats    ThreatSummary // What the security guardrails did during the run
+	Cache      PromptCache   // How the run's input tokens were served
+
+	// From the final answer block; Status is empty when the agent gave none
+	Status    AgentStatus // How the agent says the task went
+	Summary   string      // One sentence on what was done
+	FollowUps []string    // Requests the user might send next
 }
 
 // ToolCall represents a single tool invocation during agentic execution
@@ -57,13 +80,36 @@ type ToolCall struct {
 	Input    json.RawMessage // Tool input parameters
 	Output   string          // Tool execution output
 	IsError  bool            // Whether the tool execution failed
+	ExitCode *int            // Process exit code (nil for non-process tools)
+	Duration time.Duration   // Wall-clock execution time
+	Bytes    int             // Raw output size in bytes
+	Warnings []string        // Security warnings raised for this call
+	Threats  []string        // Threat tags behind the warnings
+	Verdicts []tools.Verdict // Security decisions, in validator order
+	Repeated bool            // Identical to an earlier call in the run, which answered it without running again
 }
 
 // AgentConfig holds configuration for agentic execution
 type AgentConfig struct {
-	MaxIterations int              // Maximum number of tool-use iterations (default 10)
-	Registry      *tools.Registry  // Tool registry to use
-	OnToolCall    func(ToolCall)   // Optional callback for each tool call
+	MaxIterations  int                      // Maximum number of tool-use iterations (default 10)
+	Registry       *tools.Registry          // Tool registry to use
+	OnToolCall     func(ToolCall)           // Optional callback for each tool call
+	OnToolProgress func(ToolCall, []string) // Optional callback with the latest output lines of a running tool
+	OnStep         func(AgentStep)          // Optional callback after each API round-trip
+
+	// PromptOutputLines caps the lines of command output sent back to the
+	// model (0 uses tools.DefaultPromptOutputLines). ToolCall.Output keeps it all.
+	PromptOutputLines int
+}
+
+// AgentStep is one API round-trip of an agent run. Its tool calls are
+// delivered separately through OnToolCall as they finish.
+type AgentStep struct {
+	Iteration int         // 1 for the first call
+	Text      string      // Text the model wrote, including any final answer block
+	ToolUses  int         // Tool calls the model asked for
+	Cache     PromptCache // How this call's input tokens were served
+	Output    int64       // Output tokens
 }
 
 // ConversationMessage represents a single message in a conversation
@@ -78,6 +124,12 @@ type ChatContext struct {
 	History []ConversationMessage // Conversation history for multi-turn chats
 }
 
+// Prewarmer is implemented by providers that can open their connection
+// before the first request is made
+type Prewarmer interface {
+	Prewarm(ctx context.Context, ping bool) error
+}
+
 // Provider defines the interface for AI providers
 type Provider interface {
 	// GenerateCommand generates a shell command based on the user's query and context
@@ -86,6 +138,9 @@ type Provider interface {
 	// ExplainCommand provides an explanation for a given command
 	ExplainCommand(ctx context.Context, command string) (string, error)
 
+	// CompareCommands explains how two commands differ in behaviour, flags, and risk
+	CompareCommands(ctx context.Context, before, after string, riskChanges []string) (string, error)
+
 	// ClassifyIntent determines whether the user wants a command or a chat response
 	ClassifyIntent(ctx context.Context, query string) (*IntentResult, error)
 
@@ -95,11 +150,28 @@ type Provider interface {
 	// RunAgent executes an agentic task with tool use
 	RunAgent(ctx context.Context, query string, shellCtx ShellContext, chatCtx ChatContext, cfg AgentConfig) (*AgentResult, error)
 
-	// FixCommand analyzes a failed command and suggests a fix
-	FixCommand(ctx context.Context, failedCmd string, errorOutput string, shellCtx ShellContext) (*FixResult, error)
+	// FixCommand analyzes a failed command and suggests a fix. frames holds
+	// the source around the top user-code frames of any stack trace in the
+	// error output.
+	FixCommand(ctx context.Context, failedCmd string, errorOutput string, frames []files.FileContent, shellCtx ShellContext) (*FixResult, error)
 
 	// ExplainOutput analyzes command output and provides an explanation
-	ExplainOutput(ctx context.Context, output string, prompt string, shellCtx ShellContext) (*ChatResult, error)
+	ExplainOutput(ctx context.Context, output, digest, prompt string, shellCtx ShellContext) (*ChatResult, error)
+
+	// ExplainFile produces a structured explanation of a script or source file
+	ExplainFile(ctx context.Context, file files.FileContent, prompt string, shellCtx ShellContext) (*ChatResult, error)
+
+	// SummarizeFile produces a short summary of a file
+	SummarizeFile(ctx context.Context, file files.FileContent, shellCtx ShellContext) (*ChatResult, error)
+
+	// SummarizeDir maps a surveyed directory: its purpose, layout, and key files
+	SummarizeDir(ctx context.Context, survey *files.DirSurvey, shellCtx ShellContext) (*ChatResult, error)
+
+	// GenerateChangelog groups commits into Markdown release notes
+	GenerateChangelog(ctx context.Context, commits []ChangelogCommit) (string, error)
+
+	// ReviewDiff writes a short pre-commit review of a staged diff
+	ReviewDiff(ctx context.Context, diff string) (string, error)
 
 	// SetModel updates the model used for API calls
 	SetModel(model string)
@@ -114,6 +186,11 @@ type GitContext struct {
 	HasStaged        bool     // True if there are staged changes
 	MergeInProgress  bool     // True if a merge is in progress
 	RebaseInProgress bool     // True if a rebase is in progress
+	Detached         bool     // True if HEAD is not on a branch
+	HeadSHA          string   // Short SHA of HEAD (set when detached)
+	Worktree         string   // Linked worktree name (empty for the main worktree)
+	IsSubmodule      bool     // True if the repository is a submodule of another
+	DirtySubmodules  []string // Submodules with new commits or local changes
 	Summary          string   // Brief summary for prompts
 }
 
@@ -127,6 +204,88 @@ type ShellContext struct {
 	OS          string
 	Shell       string
 	User        string
+	Timezone    string   // IANA zone and UTC offset, e.g. "Europe/Berlin (UTC+02:00)"
+	Locale      string   // Locale for dates and numbers, e.g. "de_DE.UTF-8"
 	History     []string // recent commands from history file
 	Git         *GitContext // Git repository context (nil if not in repo)
+	Pinned      []PinnedItem // Context the user pinned for the session
+	Direnv      *DirenvContext // direnv .envrc for the directory (nil if none)
+	Aliases     []ShellAlias   // User aliases and functions (from the shell hook)
+	Tools       []ToolVersion  // Installed versions of tools the project uses
+	Runtimes    []RuntimeEnv   // Python virtualenvs, conda envs, and pinned Node versions
+	Workspace   *WorkspaceContext // Monorepo workspace (nil if none)
+	Project     string            // Project type and layout (see DetectProject), used by the agent
+	Examples    []CommandExample  // Past generations the user accepted or rated good for similar queries
+	Files       []files.FileContent // Files a one-shot query references (chat and agent use ChatContext.Files)
+	Probes      []ProbeReading      // CPU, memory, disk, and thermal readings for performance questions (opt-in)
+}
+
+// ProbeReading is one measurement of how loaded the machine is
+type ProbeReading struct {
+	Name  string // e.g. "CPU load"
+	Value string // e.g. "3.10 2.40 1.90 (1, 5, 15 min) on 8 CPUs"
+}
+
+// CommandExample is a past query and the command the user confirmed for it
+type CommandExample struct {
+	Query   string
+	Command string
+}
+
+// WorkspaceContext describes the monorepo workspace the working directory is in
+type WorkspaceContext struct {
+	Root    string   // Directory holding the workspace definition
+	Kind    string   // "go.work", "pnpm", or "cargo"
+	Members []string // Member paths relative to Root
+	Current string   // Member containing the working directory ("" at the root)
+}
+
+// Runtime environment kinds
+const (
+	RuntimeVirtualenv = "virtualenv"
+	RuntimeConda      = "conda"
+	RuntimeNode       = "node"
+)
+
+// RuntimeEnv is a language environment the project uses or the shell has
+// active: a Python virtualenv, a conda env, or a pinned Node version
+type RuntimeEnv struct {
+	Kind     string // RuntimeVirtualenv, RuntimeConda, or RuntimeNode
+	Name     string // Virtualenv path, conda env name, or wanted Node version
+	Active   bool   // True if the user's shell has it active
+	Current  string // What is active instead, e.g. the Node version in use
+	Activate string // Command that activates it, when it isn't active
+	Bin      string // Directory of its executables, to call them without activating
+}
+
+// ToolVersion is the installed version of a tool
+type ToolVersion struct {
+	Name    string // e.g. "go" or "docker compose"
+	Version string // e.g. "1.24.1"
+}
+
+// ShellAlias is an alias or function defined in the user's interactive shell
+type ShellAlias struct {
+	Name      string
+	Expansion string // Alias expansion (empty for functions)
+	Function  bool   // True for shell functions
+	Shadows   bool   // True if it hides a binary of the same name on PATH
+}
+
+// DirenvContext describes the .envrc that applies to the working directory.
+// Only variable names are recorded, never their values.
+type DirenvContext struct {
+	Path      string   // Path to the .envrc
+	Status    string   // "allowed", "not allowed", "denied", or "unknown"
+	Loaded    bool     // True if the user's shell has it loaded
+	Variables []string // Names of exported variables
+	Uses      []string // Toolchain directives, e.g. "use nix" or "layout python3"
+}
+
+// PinnedItem is a piece of context the user pinned so it is included in
+// every prompt, regardless of how much conversation history is sent
+type PinnedItem struct {
+	Label     string // Where the content came from, e.g. "note" or a file path
+	Content   string
+	Untrusted bool // File contents or command output, sent as untrusted data
 }
@@ -0,0 +1,28 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+)
+
+// ReviewDiff writes a short pre-commit review of a staged diff: what the
+// change does and anything that looks like a mistake. The caller redacts
+// credentials and caps the diff size.
+func (p *AnthropicProvider) ReviewDiff(ctx context.Context, diff string) (string, error) {
+	systemPrompt := `You are bast, reviewing staged changes just before they are committed.
+
+Reply in at most 8 short lines of plain text, no Markdown headings:
+- First line: what the change does, in one sentence.
+- Then one line per likely problem, most serious first: bugs, leftover debug code, commented-out code, TODOs, files that look committed by accident, or tests that no longer match. Name the file.
+- If nothing looks wrong, say so on the second line.
+
+Do not restate the diff or suggest style changes.` + untrustedNotice
+
+	userPrompt := fmt.Sprintf("Staged diff:\n%s", wrapUntrusted("git diff --cached", diff))
+
+	result, err := p.singleTurn(ctx, systemPrompt, userPrompt, 600)
+	if err != nil {
+		return "", fmt.Errorf("failed to review diff: %w", err)
+	}
+	return result.Response, nil
+}
@@ -0,0 +1,129 @@
+package ai
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// IntentOverridesFileName is the file that stores remembered intent choices
+const IntentOverridesFileName = "intents.yaml"
+
+// intentKeyWords is how many leading words of a query form its routing key.
+// Two words ("how do") lumped together questions and commands alike, so the
+// key reaches past the opening into the subject ("how do list files").
+const intentKeyWords = 4
+
+// intentFillerWords are dropped from routing keys, since "how do I" and
+// "how do you" ask the same thing
+var intentFillerWords = map[string]bool{
+	"i": true, "you": true, "we": true, "me": true, "my": true,
+	"a": true, "an": true, "the": true, "to": true, "please": true,
+}
+
+// IntentOverrides remembers the user's explicit command/chat choices for
+// low-confidence queries, so a similar query the classifier is again unsure
+// about is routed without asking.
+type IntentOverrides struct {
+	mu      sync.Mutex
+	path    string
+	Choices map[string]Intent `yaml:"choices"`
+}
+
+// DefaultIntentOverridesPath returns the default path for remembered intent choices
+func DefaultIntentOverridesPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", "bast", IntentOverridesFileName), nil
+}
+
+// LoadIntentOverrides loads remembered intent choices from path.
+// A missing or unreadable file yields an empty set.
+func LoadIntentOverrides(path string) *IntentOverrides {
+	o := &IntentOverrides{path: path, Choices: make(map[string]Intent)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return o
+	}
+	if err := yaml.Unmarshal(data, o); err != nil || o.Choices == nil {
+		o.Choices = make(map[string]Intent)
+	}
+	return o
+}
+
+// Lookup returns the remembered intent for a query, if any
+func (o *IntentOverrides) Lookup(query string) (Intent, bool) {
+	if o == nil {
+		return "", false
+	}
+	key := IntentKey(query)
+	if key == "" {
+		return "", false
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	intent, ok := o.Choices[key]
+	return intent, ok
+}
+
+// Remember records the user's choice for a query and persists it to disk
+func (o *IntentOverrides) Remember(query string, intent Intent) error {
+	if o == nil {
+		return nil
+	}
+	key := IntentKey(query)
+	if key == "" {
+		return nil
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.Choices[key] = intent
+
+	if o.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(o.path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	data, err := yaml.Marshal(o)
+	if err != nil {
+		return fmt.Errorf("failed to marshal intent overrides: %w", err)
+	}
+	if err := os.WriteFile(o.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write intent overrides: %w", err)
+	}
+	return nil
+}
+
+// IntentKey normalizes a query into the key used for remembered choices.
+// e.g. "How do I find large files?" → "how do find large"
+func IntentKey(query string) string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '@' && r != '.' && r != '/'
+	})
+
+	var kept []string
+	for _, w := range words {
+		// Skip @mentions and paths, they vary between otherwise similar queries
+		if strings.HasPrefix(w, "@") || strings.Contains(w, "/") || strings.Contains(w, ".") {
+			continue
+		}
+		if intentFillerWords[w] {
+			continue
+		}
+		kept = append(kept, w)
+		if len(kept) == intentKeyWords {
+			break
+		}
+	}
+	return strings.Join(kept, " ")
+}
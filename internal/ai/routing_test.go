@@ -0,0 +1,56 @@
+package ai
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestIntentKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"plain query", "How do I find large files?", "how do find large"},
+		{"skips filler", "how do you find large files", "how do find large"},
+		{"distinct subjects", "how do I undo a commit", "how do undo commit"},
+		{"skips mentions", "@main.go explain this", "explain this"},
+		{"skips paths", "summarize ./docs/readme please", "summarize"},
+		{"single word", "ls", "ls"},
+		{"empty", "   ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IntentKey(tt.input); got != tt.expected {
+				t.Errorf("IntentKey(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIntentOverridesRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), IntentOverridesFileName)
+
+	overrides := LoadIntentOverrides(path)
+	if _, ok := overrides.Lookup("how do I list files"); ok {
+		t.Fatal("expected no remembered choice in empty file")
+	}
+	if err := overrides.Remember("How do I list files", IntentChat); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reloaded := LoadIntentOverrides(path)
+	intent, ok := reloaded.Lookup("how do you list files here")
+	if !ok || intent != IntentChat {
+		t.Errorf("expected remembered chat intent, got %q (found=%v)", intent, ok)
+	}
+	if _, ok := reloaded.Lookup("how do I undo a commit"); ok {
+		t.Error("a choice for listing files should not route a question about commits")
+	}
+
+	var nilOverrides *IntentOverrides
+	if _, ok := nilOverrides.Lookup("anything"); ok {
+		t.Error("expected nil overrides to find nothing")
+	}
+}
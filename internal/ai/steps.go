@@ -0,0 +1,140 @@
+package ai
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MaxAnswerCommands is the most commands taken from one answer
+const MaxAnswerCommands = 9
+
+// shellFenceLangs are code block languages holding shell commands; an
+// untagged block counts too
+var shellFenceLangs = map[string]bool{
+	"": true, "bash": true, "sh": true, "shell": true, "zsh": true,
+	"fish": true, "console": true, "shell-session": true, "terminal": true,
+}
+
+// inlineCodeRe matches an inline code span
+var inlineCodeRe = regexp.MustCompile("`([^`\n]+)`")
+
+// imperativeRe matches the words that introduce a command in prose, as in
+// "run `npm ci`, then `npm test`"
+var imperativeRe = regexp.MustCompile(`(?i)\b(run|execute|type|enter|use|try|then|with)\b`)
+
+// ExtractCommands finds the shell commands an answer tells the user to run,
+// in order: the lines of shell code blocks (only the prompted lines of a
+// console transcript) and inline code that follows a word like "run". It
+// works locally on the answer, so commands still go through the usual
+// checks before anything runs.
+func ExtractCommands(answer string) []string {
+	var commands []string
+	seen := make(map[string]bool)
+	add := func(command string) {
+		command = strings.TrimSpace(command)
+		if command == "" || seen[command] || len(commands) >= MaxAnswerCommands || validateCommand(command) != nil {
+			return
+		}
+		seen[command] = true
+		commands = append(commands, command)
+	}
+
+	var block []string
+	inFence, shellFence := false, false
+	for _, line := range strings.Split(answer, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			if inFence {
+				if shellFence {
+					for _, command := range blockCommands(block) {
+						add(command)
+					}
+				}
+				inFence, block = false, nil
+				continue
+			}
+			lang := strings.ToLower(strings.TrimSpace(trimmed[3:]))
+			inFence, shellFence = true, shellFenceLangs[lang]
+			continue
+		}
+		if inFence {
+			block = append(block, line)
+			continue
+		}
+		for _, command := range inlineCommands(line) {
+			add(command)
+		}
+	}
+	return commands
+}
+
+// blockCommands returns the commands in a shell code block. Lines ending in
+// a backslash continue onto the next. When some lines start with a "$ "
+// prompt, the block is a transcript and the other lines are output.
+func blockCommands(block []string) []string {
+	var lines []string
+	var pending string
+	for _, line := range block {
+		line = strings.TrimSpace(line)
+		if strings.HasSuffix(line, "\\") {
+			pending += strings.TrimSpace(strings.TrimSuffix(line, "\\")) + " "
+			continue
+		}
+		lines = append(lines, strings.TrimSpace(pending+line))
+		pending = ""
+	}
+	if pending != "" {
+		lines = append(lines, strings.TrimSpace(pending))
+	}
+
+	transcript := false
+	for _, line := range lines {
+		if strings.HasPrefix(line, "$ ") {
+			transcript = true
+			break
+		}
+	}
+
+	var commands []string
+	for _, line := range lines {
+		if transcript {
+			if !strings.HasPrefix(line, "$ ") {
+				continue
+			}
+			line = strings.TrimPrefix(line, "$ ")
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		commands = append(commands, line)
+	}
+	return commands
+}
+
+// inlineCommands returns the inline code spans in a line of prose that an
+// imperative word before them marks as commands. Spans that look like file
+// names, flags, or variables are skipped.
+func inlineCommands(line string) []string {
+	var commands []string
+	for _, loc := range inlineCodeRe.FindAllStringSubmatchIndex(line, -1) {
+		if !imperativeRe.MatchString(line[:loc[0]]) {
+			continue
+		}
+		span := strings.TrimSpace(line[loc[2]:loc[3]])
+		span = strings.TrimPrefix(span, "$ ")
+		if !strings.Contains(span, " ") && !isBareCommandName(span) {
+			continue
+		}
+		commands = append(commands, span)
+	}
+	return commands
+}
+
+// isBareCommandName reports whether a one-word span reads as a command,
+// like `make`, rather than a file, flag, or environment variable
+func isBareCommandName(word string) bool {
+	if word == "" || strings.ContainsAny(word, "./=$") || strings.HasPrefix(word, "-") {
+		return false
+	}
+	return strings.ToUpper(word) != word
+}
@@ -0,0 +1,56 @@
+package ai
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractCommands(t *testing.T) {
+	tests := []struct {
+		name   string
+		answer string
+		want   []string
+	}{
+		{
+			name:   "inline commands after imperative words",
+			answer: "Run `npm ci`, then `npm test`. The config lives in `package.json`.",
+			want:   []string{"npm ci", "npm test"},
+		},
+		{
+			name:   "inline code without an imperative is not a command",
+			answer: "The `--force` flag and `git status` output differ.",
+		},
+		{
+			name:   "bare command names, not files or variables",
+			answer: "Use `make`, or set `PATH` and try `./build.sh`.",
+			want:   []string{"make"},
+		},
+		{
+			name:   "shell code block with comments and continuations",
+			answer: "Steps:\n```bash\n# install\nnpm ci\ndocker run \\\n  -p 8080:80 nginx\n```",
+			want:   []string{"npm ci", "docker run -p 8080:80 nginx"},
+		},
+		{
+			name:   "console transcript keeps prompted lines",
+			answer: "```console\n$ go version\ngo version go1.24 linux/amd64\n$ go env GOPATH\n```",
+			want:   []string{"go version", "go env GOPATH"},
+		},
+		{
+			name:   "other languages are skipped",
+			answer: "```yaml\nport: 8080\n```\n```go\nfmt.Println(1)\n```",
+		},
+		{
+			name:   "duplicates and invalid commands are dropped",
+			answer: "```sh\nls -la\necho \"unterminated\nls -la\n```",
+			want:   []string{"ls -la"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractCommands(tt.answer); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractCommands() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
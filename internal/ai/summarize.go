@@ -0,0 +1,122 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+
+	"github.com/bastio-ai/bast/internal/files"
+)
+
+// SummarizeFile produces a short summary of a file
+func (p *AnthropicProvider) SummarizeFile(ctx context.Context, file files.FileContent, shellCtx ShellContext) (*ChatResult, error) {
+	systemPrompt := `You are bast, an AI shell assistant summarizing a file for someone who has not seen it.
+
+Write a concise summary: what the file is for in one sentence, then a few bullets on its main parts (functions, sections, or settings) and anything notable (side effects, dependencies, TODOs). Keep it under 15 lines and terminal-friendly.`
+	systemPrompt += formatSummaryEnvironment(shellCtx) + untrustedNotice
+
+	userPrompt := fmt.Sprintf("File: %s\n%s", file.Path, wrapUntrusted(file.Path, file.Content))
+	result, err := p.singleTurn(ctx, systemPrompt, userPrompt, 1024)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize file: %w", err)
+	}
+	return result, nil
+}
+
+// SummarizeDir produces a map of a directory from a survey: what it is,
+// the purpose of each top-level directory, and the key files to read first
+func (p *AnthropicProvider) SummarizeDir(ctx context.Context, survey *files.DirSurvey, shellCtx ShellContext) (*ChatResult, error) {
+	systemPrompt := `You are bast, an AI shell assistant helping someone who just landed in an unfamiliar directory or repository.
+
+From the listing and key files, write a map with these sections:
+1. Overview - what this is, its language and tooling, in one or two sentences
+2. Layout - one line per top-level directory on its purpose, inferred from its file names
+3. Key files - the files to read first and why
+4. Getting started - how to build, test, or run it, only if the key files say so
+
+Base everything on what you were shown; say so when a purpose is a guess. Keep it terminal-friendly.`
+	systemPrompt += formatSummaryEnvironment(shellCtx) + untrustedNotice
+
+	userPrompt := formatDirSurvey(survey)
+	result, err := p.singleTurn(ctx, systemPrompt, userPrompt, 1536)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize directory: %w", err)
+	}
+	return result, nil
+}
+
+// formatSummaryEnvironment describes where the summary is being read
+func formatSummaryEnvironment(shellCtx ShellContext) string {
+	return fmt.Sprintf("\n\nCurrent environment:\n- Working directory: %s\n- Operating system: %s", shellCtx.CWD, shellCtx.OS)
+}
+
+// singleTurn sends one system and user prompt and returns the text reply
+func (p *AnthropicProvider) singleTurn(ctx context.Context, systemPrompt, userPrompt string, maxTokens int64) (result *ChatResult, err error) {
+	systemPrompt, userPrompt = p.anon.Anonymize(systemPrompt), p.anon.Anonymize(userPrompt)
+	defer func() { result.restore(p.anon) }()
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultAPITimeout)
+	defer cancel()
+
+	message, err := p.client.Messages.New(ctx, anthropic.MessageNewParams{
+		Model:     p.model,
+		MaxTokens: maxTokens,
+		System: []anthropic.TextBlockParam{
+			{Text: systemPrompt},
+		},
+		Messages: []anthropic.MessageParam{
+			anthropic.NewUserMessage(anthropic.NewTextBlock(userPrompt)),
+		},
+	})
+	if err != nil {
+		return nil, classifyError(err)
+	}
+
+	var response string
+	for _, block := range message.Content {
+		if block.Type == "text" {
+			response = strings.TrimSpace(block.Text)
+			break
+		}
+	}
+
+	return &ChatResult{
+		Response: response,
+	}, nil
+}
+
+// formatDirSurvey renders a directory survey for the prompt
+func formatDirSurvey(survey *files.DirSurvey) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Directory: %s\n\nTop-level entries:\n", survey.Path)
+	for _, e := range survey.Entries {
+		if !e.IsDir {
+			fmt.Fprintf(&b, "  %s\n", e.Name)
+			continue
+		}
+		fmt.Fprintf(&b, "  %s/ (%d files)", e.Name, e.Files)
+		if len(e.Sample) > 0 {
+			fmt.Fprintf(&b, ": %s", strings.Join(e.Sample, ", "))
+			if e.Files > len(e.Sample) {
+				b.WriteString(", ...")
+			}
+		}
+		b.WriteString("\n")
+	}
+	if survey.Omitted > 0 {
+		fmt.Fprintf(&b, "  ... and %d more entries\n", survey.Omitted)
+	}
+	if survey.Truncated {
+		fmt.Fprintf(&b, "(file counts stop at %d files)\n", files.MaxSurveyFiles)
+	}
+
+	if len(survey.KeyFiles) > 0 {
+		b.WriteString("\nKey files:")
+		for _, f := range survey.KeyFiles {
+			fmt.Fprintf(&b, "\n\n%s", wrapUntrusted(f.Path, f.Content))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,33 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bastio-ai/bast/internal/files"
+)
+
+func TestFormatDirSurvey(t *testing.T) {
+	survey := &files.DirSurvey{
+		Path: ".",
+		Entries: []files.DirEntry{
+			{Name: "cmd", IsDir: true, Files: 12, Sample: []string{"cmd/root.go", "cmd/run.go"}},
+			{Name: "go.mod"},
+		},
+		Omitted:  3,
+		KeyFiles: []files.FileContent{{Path: "go.mod", Content: "module demo"}},
+	}
+
+	got := formatDirSurvey(survey)
+	for _, want := range []string{
+		"Directory: .",
+		"  cmd/ (12 files): cmd/root.go, cmd/run.go, ...\n",
+		"  go.mod\n",
+		"... and 3 more entries",
+		`<untrusted source="go.mod">`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatDirSurvey() missing %q:\n%s", want, got)
+		}
+	}
+}
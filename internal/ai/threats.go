@@ -0,0 +1,70 @@
+package ai
+
+import (
+	"github.com/bastio-ai/bast/internal/tools"
+)
+
+// ThreatSummary aggregates the security verdicts of an agent run
+type ThreatSummary struct {
+	Checked      int      `json:"checked"`           // Tool calls seen by at least one validator
+	Blocked      int      `json:"blocked"`           // Calls or outputs blocked, or held for approval
+	Sanitized    int      `json:"sanitized"`         // Outputs rewritten before reaching the model
+	Warnings     int      `json:"warnings"`          // Calls that raised warnings
+	MaxRiskScore float64  `json:"max_risk_score"`    // Highest risk score reported
+	Threats      []string `json:"threats,omitempty"` // Distinct threat tags, in first-seen order
+}
+
+// IsEmpty reports whether no validator looked at any tool call
+func (s ThreatSummary) IsEmpty() bool {
+	return s.Checked == 0
+}
+
+// SummarizeThreats aggregates security verdicts across tool calls
+func SummarizeThreats(calls []ToolCall) ThreatSummary {
+	var summary ThreatSummary
+	seen := make(map[string]bool)
+
+	for _, call := range calls {
+		if len(call.Verdicts) == 0 && len(call.Warnings) == 0 {
+			continue
+		}
+		summary.Checked++
+
+		var blocked, sanitized bool
+		for _, v := range call.Verdicts {
+			switch v.Action {
+			case string(tools.ActionBlock), string(tools.ActionRequireApproval):
+				blocked = true
+			case string(tools.ScanActionSanitize):
+				sanitized = true
+			}
+			if v.RiskScore > summary.MaxRiskScore {
+				summary.MaxRiskScore = v.RiskScore
+			}
+			for _, threat := range v.Threats {
+				if !seen[threat] {
+					seen[threat] = true
+					summary.Threats = append(summary.Threats, threat)
+				}
+			}
+		}
+		if blocked {
+			summary.Blocked++
+		}
+		if sanitized {
+			summary.Sanitized++
+		}
+		if len(call.Warnings) > 0 {
+			summary.Warnings++
+		}
+
+		for _, threat := range call.Threats {
+			if !seen[threat] {
+				seen[threat] = true
+				summary.Threats = append(summary.Threats, threat)
+			}
+		}
+	}
+
+	return summary
+}
@@ -0,0 +1,47 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/bastio-ai/bast/internal/tools"
+)
+
+func TestSummarizeThreats(t *testing.T) {
+	calls := []ToolCall{
+		{Name: "list_directory", Verdicts: []tools.Verdict{{Validator: "local", Stage: "validate", Action: "allow"}}},
+		{Name: "run_command", Verdicts: []tools.Verdict{
+			{Validator: "local", Stage: "validate", Action: "require_approval", RiskScore: 1.0, Threats: []string{"dangerous_command"}},
+		}},
+		{Name: "read_file", Verdicts: []tools.Verdict{
+			{Validator: "local", Stage: "validate", Action: "allow"},
+			{Validator: "local", Stage: "scan", Action: "sanitize", RiskScore: 0.8, Threats: []string{"aws_access_key"}},
+		}},
+		{Name: "run_command", Warnings: []string{"network access"}, Threats: []string{"exfiltration", "dangerous_command"}},
+		{Name: "unchecked"},
+	}
+
+	summary := SummarizeThreats(calls)
+	if summary.Checked != 4 {
+		t.Errorf("expected 4 checked, got %d", summary.Checked)
+	}
+	if summary.Blocked != 1 || summary.Sanitized != 1 || summary.Warnings != 1 {
+		t.Errorf("unexpected counts: %+v", summary)
+	}
+	if summary.MaxRiskScore != 1.0 {
+		t.Errorf("expected max risk 1.0, got %.2f", summary.MaxRiskScore)
+	}
+	want := []string{"dangerous_command", "aws_access_key", "exfiltration"}
+	if len(summary.Threats) != len(want) {
+		t.Fatalf("expected threats %v, got %v", want, summary.Threats)
+	}
+	for i := range want {
+		if summary.Threats[i] != want[i] {
+			t.Errorf("expected threats %v, got %v", want, summary.Threats)
+			break
+		}
+	}
+
+	if !SummarizeThreats(nil).IsEmpty() {
+		t.Error("expected empty summary for no calls")
+	}
+}
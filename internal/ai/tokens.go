@@ -0,0 +1,30 @@
+package ai
+
+// ContextWindowTokens is the context window of the supported Claude models
+const ContextWindowTokens = 200_000
+
+// systemPromptTokens approximates the system prompt and shell context
+// sent with every chat and agent request
+const systemPromptTokens = 1500
+
+// EstimateTokens approximates how many tokens text uses: about four bytes
+// per token for English prose and code. It errs high rather than low.
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// EstimateRequestTokens approximates the prompt size of a chat or agent
+// request carrying history, pinned context, and extra text such as tool output
+func EstimateRequestTokens(history []ConversationMessage, pinned []PinnedItem, extra ...string) int {
+	total := systemPromptTokens
+	for _, msg := range history {
+		total += EstimateTokens(msg.Content) + 4 // Role and message framing
+	}
+	for _, item := range pinned {
+		total += EstimateTokens(item.Label) + EstimateTokens(item.Content)
+	}
+	for _, text := range extra {
+		total += EstimateTokens(text)
+	}
+	return total
+}
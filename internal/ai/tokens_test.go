@@ -0,0 +1,42 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"a", 1},
+		{"abcd", 1},
+		{"abcde", 2},
+		{strings.Repeat("x", 4000), 1000},
+	}
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.text); got != tt.want {
+			t.Errorf("EstimateTokens(%d bytes) = %d, want %d", len(tt.text), got, tt.want)
+		}
+	}
+}
+
+func TestEstimateRequestTokens(t *testing.T) {
+	empty := EstimateRequestTokens(nil, nil)
+	if empty != systemPromptTokens {
+		t.Errorf("empty request = %d, want %d", empty, systemPromptTokens)
+	}
+
+	history := []ConversationMessage{
+		{Role: "user", Content: strings.Repeat("q", 400)},
+		{Role: "assistant", Content: strings.Repeat("a", 800)},
+	}
+	pinned := []PinnedItem{{Label: "note", Content: strings.Repeat("p", 396)}}
+	got := EstimateRequestTokens(history, pinned, strings.Repeat("o", 40))
+	want := systemPromptTokens + (100 + 4) + (200 + 4) + (1 + 99) + 10
+	if got != want {
+		t.Errorf("EstimateRequestTokens = %d, want %d", got, want)
+	}
+}
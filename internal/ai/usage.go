@@ -0,0 +1,88 @@
+package ai
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/anthropics/anthropic-sdk-go/option"
+)
+
+// TokenUsage counts the tokens used by API calls
+type TokenUsage struct {
+	Input  int64
+	Output int64
+}
+
+// Total returns input and output tokens together
+func (u TokenUsage) Total() int64 {
+	return u.Input + u.Output
+}
+
+// UsageReporter is implemented by providers that count the tokens they use
+type UsageReporter interface {
+	Usage() TokenUsage
+}
+
+// usageMeter adds up the usage reported in message responses. Agent runs
+// call the API from their own goroutine, so it is safe for concurrent use.
+type usageMeter struct {
+	mu    sync.Mutex
+	usage TokenUsage
+}
+
+// middleware reads the usage block from successful message responses and
+// passes the body on untouched
+func (u *usageMeter) middleware() option.Middleware {
+	return func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		resp, err := next(req)
+		if err != nil || resp.Body == nil || resp.StatusCode != http.StatusOK || !strings.HasSuffix(req.URL.Path, "/messages") {
+			return resp, err
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+
+		// Input counts cached tokens too, which the API reports separately
+		var parsed struct {
+			Usage struct {
+				InputTokens              int64 `json:"input_tokens"`
+				CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+				CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+				OutputTokens             int64 `json:"output_tokens"`
+			} `json:"usage"`
+		}
+		if json.Unmarshal(body, &parsed) == nil {
+			usage := parsed.Usage
+			u.add(TokenUsage{
+				Input:  usage.InputTokens + usage.CacheCreationInputTokens + usage.CacheReadInputTokens,
+				Output: usage.OutputTokens,
+			})
+		}
+		return resp, nil
+	}
+}
+
+func (u *usageMeter) add(t TokenUsage) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.usage.Input += t.Input
+	u.usage.Output += t.Output
+}
+
+func (u *usageMeter) total() TokenUsage {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.usage
+}
+
+// Usage returns the tokens used by this provider so far
+func (p *AnthropicProvider) Usage() TokenUsage {
+	return p.usage.total()
+}
@@ -0,0 +1,34 @@
+package ai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProviderUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","model":"test",
+			"content":[{"type":"text","text":"Lists files."}],"stop_reason":"end_turn",
+			"usage":{"input_tokens":120,"cache_read_input_tokens":100,"output_tokens":30}}`))
+	}))
+	defer server.Close()
+
+	p := NewAnthropicProviderWithConfig(ProviderConfig{APIKey: "key", Model: "test", BaseURL: server.URL})
+	for range 2 {
+		explanation, err := p.ExplainCommand(context.Background(), "ls")
+		if err != nil {
+			t.Fatalf("ExplainCommand() error = %v", err)
+		}
+		if explanation == "" {
+			t.Error("usage counting should leave the response intact")
+		}
+	}
+
+	got := p.Usage()
+	if got != (TokenUsage{Input: 440, Output: 60}) || got.Total() != 500 {
+		t.Errorf("Usage() = %+v, want 440 in (cached tokens included) and 60 out", got)
+	}
+}
@@ -0,0 +1,64 @@
+package ai
+
+import (
+	"regexp"
+	"strings"
+)
+
+// workingDirRe matches the hint line a command reply starts with when the
+// command must run in another directory
+var workingDirRe = regexp.MustCompile(`^#\s*working_dir:\s*(.+)$`)
+
+// cdPrefixRe matches a leading "cd dir &&", which is turned into a working
+// directory hint
+var cdPrefixRe = regexp.MustCompile(`^cd\s+('[^']+'|"[^"]+"|[^\s;&|'"$` + "`" + `]+)\s*&&\s*`)
+
+// splitWorkingDir takes the working_dir hint line off the front of a reply.
+// It returns an empty directory when there is none.
+func splitWorkingDir(reply string) (dir, rest string) {
+	reply = strings.TrimSpace(reply)
+	first, rest, _ := strings.Cut(reply, "\n")
+	m := workingDirRe.FindStringSubmatch(strings.TrimSpace(first))
+	if m == nil {
+		return "", reply
+	}
+	return unquoteDir(m[1]), strings.TrimSpace(rest)
+}
+
+// parseCommandReply cleans a command reply and takes off its working_dir
+// hint, which may come before or inside a code block
+func parseCommandReply(reply string) (dir, command string) {
+	dir, rest := splitWorkingDir(reply)
+	command = cleanCommand(rest)
+	if dir == "" {
+		dir, command = splitWorkingDir(command)
+	}
+	return dir, command
+}
+
+// liftCdPrefix turns a command starting with "cd dir && " into dir and the
+// rest of the command. Commands that cd somewhere else later, or whose
+// directory needs expanding, are left alone.
+func liftCdPrefix(command string) (dir, rest string) {
+	m := cdPrefixRe.FindStringSubmatch(command)
+	if m == nil || m[1] == "-" {
+		return "", command
+	}
+	rest = command[len(m[0]):]
+	if rest == "" || strings.Contains(rest, "cd ") {
+		return "", command
+	}
+	return unquoteDir(m[1]), rest
+}
+
+// unquoteDir removes the quotes around a directory
+func unquoteDir(dir string) string {
+	dir = strings.TrimSpace(dir)
+	if len(dir) >= 2 && (dir[0] == '\'' || dir[0] == '"') && dir[len(dir)-1] == dir[0] {
+		dir = dir[1 : len(dir)-1]
+	}
+	if dir == "." || dir == "./" {
+		return ""
+	}
+	return dir
+}
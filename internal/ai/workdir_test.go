@@ -0,0 +1,47 @@
+package ai
+
+import "testing"
+
+func TestParseCommandReply(t *testing.T) {
+	tests := []struct {
+		reply   string
+		wantDir string
+		wantCmd string
+	}{
+		{"# working_dir: packages/api\nnpm test", "packages/api", "npm test"},
+		{"#working_dir: 'services/my app'\nmake build", "services/my app", "make build"},
+		{"```bash\n# working_dir: web\npnpm lint\n```", "web", "pnpm lint"},
+		{"# working_dir: web\n```bash\npnpm lint\n```", "web", "pnpm lint"},
+		{"Run this:\n# working_dir: web\npnpm lint", "web", "pnpm lint"},
+		{"# working_dir: .\nls", "", "ls"},
+		{"go test ./...", "", "go test ./..."},
+		{"# list files\nls -la", "", "# list files\nls -la"},
+	}
+	for _, tt := range tests {
+		if dir, command := parseCommandReply(tt.reply); dir != tt.wantDir || command != tt.wantCmd {
+			t.Errorf("parseCommandReply(%q) = %q, %q, want %q, %q", tt.reply, dir, command, tt.wantDir, tt.wantCmd)
+		}
+	}
+}
+
+func TestLiftCdPrefix(t *testing.T) {
+	tests := []struct {
+		command string
+		wantDir string
+		wantCmd string
+	}{
+		{"cd packages/api && npm test", "packages/api", "npm test"},
+		{`cd "my dir" && make`, "my dir", "make"},
+		{"cd web&&pnpm build && pnpm test", "web", "pnpm build && pnpm test"},
+		{"cd - && ls", "", "cd - && ls"},
+		{"cd $PROJECT && make", "", "cd $PROJECT && make"},
+		{"cd a && make && cd b && make", "", "cd a && make && cd b && make"},
+		{"cd web; npm test", "", "cd web; npm test"},
+		{"npm test", "", "npm test"},
+	}
+	for _, tt := range tests {
+		if dir, command := liftCdPrefix(tt.command); dir != tt.wantDir || command != tt.wantCmd {
+			t.Errorf("liftCdPrefix(%q) = %q, %q, want %q, %q", tt.command, dir, command, tt.wantDir, tt.wantCmd)
+		}
+	}
+}
@@ -8,12 +8,15 @@ import (
 	"io"
 	"net/http"
 	"os"
+
+	"github.com/bastio-ai/bast/internal/useragent"
 )
 
 // Authenticator handles Bastio authentication and proxy management
 type Authenticator struct {
 	deviceFlow *DeviceFlowClient
 	baseURL    string
+	gatewayURL string
 }
 
 // NewAuthenticator creates a new authenticator instance
@@ -21,14 +24,17 @@ func NewAuthenticator() *Authenticator {
 	return &Authenticator{
 		deviceFlow: NewDeviceFlowClient(),
 		baseURL:    GetBastioBaseURL(),
+		gatewayURL: GetBastioGatewayURL(),
 	}
 }
 
-// NewAuthenticatorWithURL creates an authenticator with a custom base URL (for testing)
+// NewAuthenticatorWithURL creates an authenticator with a custom base URL,
+// used for both the API and the gateway (for testing)
 func NewAuthenticatorWithURL(baseURL string) *Authenticator {
 	return &Authenticator{
 		deviceFlow: NewDeviceFlowClientWithURL(baseURL),
 		baseURL:    baseURL,
+		gatewayURL: baseURL,
 	}
 }
 
@@ -39,6 +45,12 @@ type LoginResult struct {
 	VerifyURL   string
 }
 
+// KeepDeviceID makes the next login register as deviceID rather than a
+// newly generated ID, so Bastio sees the same device after a repair
+func (a *Authenticator) KeepDeviceID(deviceID string) {
+	a.deviceFlow.DeviceID = deviceID
+}
+
 // StartLogin initiates the device flow login process
 func (a *Authenticator) StartLogin(ctx context.Context) (*DeviceAuthorizationResponse, error) {
 	return a.deviceFlow.StartDeviceFlow(ctx)
@@ -66,9 +78,21 @@ func (a *Authenticator) CompleteLogin(ctx context.Context, deviceCode string, in
 	return creds, nil
 }
 
-// Logout clears stored credentials
+// Logout clears stored credentials. A saved device ID is written back, so
+// logging in again registers the same device; with no credentials file there
+// is nothing to keep and nothing is written.
 func (a *Authenticator) Logout() error {
-	return DeleteCredentials()
+	if !CredentialsExist() {
+		return DeleteCredentials()
+	}
+	id := DeviceID()
+	if err := DeleteCredentials(); err != nil {
+		return err
+	}
+	if id == "" {
+		return nil
+	}
+	return SaveCredentials(&Credentials{DeviceID: id})
 }
 
 // GetCredentials loads credentials (API keys don't expire, no refresh needed)
@@ -84,6 +108,7 @@ type AuthStatus struct {
 	ProxyID          string
 	CredentialsPath  string
 	BastioGatewayURL string
+	CredentialsErr   error // Set when the credentials file exists but can't be used
 }
 
 // GetStatus returns the current authentication status
@@ -100,6 +125,7 @@ func (a *Authenticator) GetStatus(ctx context.Context) (*AuthStatus, error) {
 
 	creds, err := LoadCredentials()
 	if err != nil {
+		status.CredentialsErr = err
 		return status, nil
 	}
 	if creds == nil {
@@ -142,6 +168,7 @@ func (a *Authenticator) StoreProviderKey(ctx context.Context, bastioAPIKey, prov
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+bastioAPIKey)
+	req.Header.Set("User-Agent", useragent.String(DeviceID()))
 
 	client := &http.Client{Timeout: DefaultHTTPTimeout}
 	resp, err := client.Do(req)
@@ -3,6 +3,8 @@ package auth
 import (
 	"os"
 	"time"
+
+	"github.com/bastio-ai/bast/internal/config"
 )
 
 const (
@@ -16,9 +18,6 @@ const (
 	// DefaultBastioWebURL is the default base URL for the Bastio web frontend
 	DefaultBastioWebURL = "https://www.bastio.com"
 
-	// CLIVersion is the version of the CLI for device registration
-	CLIVersion = "1.0.0"
-
 	// DefaultDeviceFlowTimeout is the maximum time to wait for device authorization
 	DefaultDeviceFlowTimeout = 15 * time.Minute
 
@@ -26,11 +25,23 @@ const (
 	DefaultHTTPTimeout = 30 * time.Second
 )
 
+// configuredURLs are the Bastio URLs from config, set by Configure
+var configuredURLs config.BastioConfig
+
+// Configure sets the Bastio URLs from config, typically provisioned in the
+// system config. Environment variables still take precedence.
+func Configure(bastio config.BastioConfig) {
+	configuredURLs = bastio
+}
+
 // GetBastioBaseURL returns the Bastio API base URL, checking env var first
 func GetBastioBaseURL() string {
 	if url := os.Getenv("BASTIO_API_URL"); url != "" {
 		return url
 	}
+	if configuredURLs.APIURL != "" {
+		return configuredURLs.APIURL
+	}
 	return DefaultBastioBaseURL
 }
 
@@ -39,6 +50,9 @@ func GetBastioGatewayURL() string {
 	if url := os.Getenv("BASTIO_GATEWAY_URL"); url != "" {
 		return url
 	}
+	if configuredURLs.GatewayURL != "" {
+		return configuredURLs.GatewayURL
+	}
 	return DefaultBastioGatewayURL
 }
 
@@ -47,5 +61,8 @@ func GetBastioWebURL() string {
 	if url := os.Getenv("BASTIO_WEB_URL"); url != "" {
 		return url
 	}
+	if configuredURLs.WebURL != "" {
+		return configuredURLs.WebURL
+	}
 	return DefaultBastioWebURL
 }
@@ -1,12 +1,20 @@
 package auth
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+
+	"github.com/bastio-ai/bast/internal/config"
 )
 
 const (
@@ -15,8 +23,43 @@ const (
 
 	// CredentialsFileMode is the file permission for the credentials file (owner read/write only)
 	CredentialsFileMode = 0600
+
+	// CredentialsBackupSuffix is appended to the credentials path for the
+	// copy of the last good file, taken before every write
+	CredentialsBackupSuffix = ".bak"
 )
 
+// CredentialsSchemaVersion is the credentials.yaml schema this build reads and writes
+const CredentialsSchemaVersion = 2
+
+// credentialsMigrations upgrade credentials.yaml, in order
+var credentialsMigrations = []config.Migration{
+	{
+		From:        0,
+		Description: "record the schema version",
+		Apply:       func(doc *yaml.Node) error { return nil },
+	},
+	{
+		From:        1,
+		Description: "save the device ID",
+		Apply:       saveLegacyDeviceID,
+	},
+}
+
+// saveLegacyDeviceID writes the hostname-derived ID that credentials from
+// before the device ID was saved registered with, so a later hostname
+// change doesn't make the install a new device
+func saveLegacyDeviceID(doc *yaml.Node) error {
+	bastio := config.Key(doc, "bastio")
+	if bastio == nil || bastio.Kind != yaml.MappingNode {
+		return nil
+	}
+	if id := config.Key(bastio, "device_id"); id == nil || id.Value == "" {
+		config.SetKey(bastio, "device_id", legacyDeviceID())
+	}
+	return nil
+}
+
 // Credentials holds the Bastio authentication credentials
 type Credentials struct {
 	AccessToken  string    `mapstructure:"access_token"`
@@ -32,6 +75,77 @@ type CredentialsFile struct {
 	Bastio Credentials `mapstructure:"bastio"`
 }
 
+// credentialsSchema mirrors credentials.yaml for strict validation, so a
+// truncated or hand-edited file is reported precisely instead of half-loaded
+type credentialsSchema struct {
+	Version int `yaml:"version"`
+	Bastio  struct {
+		AccessToken  string    `yaml:"access_token"`
+		RefreshToken string    `yaml:"refresh_token"`
+		ExpiresAt    time.Time `yaml:"expires_at"`
+		ProxyAPIKey  string    `yaml:"proxy_api_key"`
+		ProxyID      string    `yaml:"proxy_id"`
+		DeviceID     string    `yaml:"device_id"`
+	} `yaml:"bastio"`
+}
+
+// CredentialsError reports a credentials file that can't be used as is
+type CredentialsError struct {
+	Path    string
+	Problem string
+}
+
+func (e *CredentialsError) Error() string {
+	return fmt.Sprintf("%s is damaged: %s (run 'bast auth repair' to fix it)", e.Path, e.Problem)
+}
+
+// unknownFieldPattern matches yaml.v3's message for a key the schema lacks
+var unknownFieldPattern = regexp.MustCompile(`field (\S+) not found in type \S+`)
+
+// ValidateCredentials checks the contents of a credentials file against the
+// schema and returns a *CredentialsError describing the first problems found.
+// A file from a newer bast is a *config.NewerSchemaError instead, since
+// repairing it would throw away keys this build doesn't know.
+func ValidateCredentials(path string, data []byte) error {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return &CredentialsError{Path: path, Problem: "the file is empty"}
+	}
+
+	var header struct {
+		Version int `yaml:"version"`
+	}
+	if yaml.Unmarshal(data, &header) == nil && header.Version > CredentialsSchemaVersion {
+		return &config.NewerSchemaError{Path: path, Version: header.Version, Supported: CredentialsSchemaVersion}
+	}
+
+	var schema credentialsSchema
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&schema); err != nil {
+		if errors.Is(err, io.EOF) {
+			return &CredentialsError{Path: path, Problem: "the file has no content"}
+		}
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) {
+			problems := make([]string, len(typeErr.Errors))
+			for i, msg := range typeErr.Errors {
+				problems[i] = unknownFieldPattern.ReplaceAllString(msg, "unknown key $1")
+			}
+			return &CredentialsError{Path: path, Problem: strings.Join(problems, "; ")}
+		}
+		return &CredentialsError{Path: path, Problem: strings.TrimPrefix(err.Error(), "yaml: ")}
+	}
+
+	b := schema.Bastio
+	switch {
+	case b.ProxyAPIKey != "" && b.ProxyID == "":
+		return &CredentialsError{Path: path, Problem: "bastio.proxy_api_key is set but bastio.proxy_id is missing"}
+	case b.ProxyID != "" && b.ProxyAPIKey == "":
+		return &CredentialsError{Path: path, Problem: "bastio.proxy_id is set but bastio.proxy_api_key is missing"}
+	}
+	return nil
+}
+
 // CredentialsPath returns the path to the credentials file
 func CredentialsPath() (string, error) {
 	homeDir, err := os.UserHomeDir()
@@ -48,10 +162,33 @@ func LoadCredentials() (*Credentials, error) {
 		return nil, err
 	}
 
-	// Check if file exists
-	if _, err := os.Stat(credPath); os.IsNotExist(err) {
+	data, err := os.ReadFile(credPath)
+	if os.IsNotExist(err) {
 		return nil, nil // No credentials file yet
 	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials: %w", err)
+	}
+
+	// Migrate first, so an older file is checked against the schema it is
+	// upgraded to rather than reported as damaged. A file that can't be
+	// migrated because it doesn't parse is reported by the validator.
+	if err := config.MigrateAndReport(CredentialsFileName, credPath, CredentialsSchemaVersion, credentialsMigrations); err != nil {
+		var newer *config.NewerSchemaError
+		if errors.As(err, &newer) {
+			return nil, err
+		}
+		if verr := ValidateCredentials(credPath, data); verr != nil {
+			return nil, verr
+		}
+		return nil, err
+	}
+	if data, err = os.ReadFile(credPath); err != nil {
+		return nil, fmt.Errorf("failed to read credentials: %w", err)
+	}
+	if err := ValidateCredentials(credPath, data); err != nil {
+		return nil, err
+	}
 
 	v := viper.New()
 	v.SetConfigType("yaml")
@@ -82,9 +219,25 @@ func SaveCredentials(creds *Credentials) error {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
+	if err := backupCredentials(credPath); err != nil {
+		return fmt.Errorf("failed to back up credentials: %w", err)
+	}
+
+	// Write to a temporary file and rename it into place, so an interrupted
+	// write can't leave a truncated credentials file behind
+	tmp, err := os.CreateTemp(configDir, ".credentials-*.yaml")
+	if err != nil {
+		return fmt.Errorf("failed to write credentials: %w", err)
+	}
+	tmpPath := tmp.Name()
+	tmp.Close()
+	defer os.Remove(tmpPath)
+
 	v := viper.New()
 	v.SetConfigType("yaml")
-	v.SetConfigFile(credPath)
+	v.SetConfigFile(tmpPath)
+
+	v.Set("version", CredentialsSchemaVersion)
 
 	// Set the credentials under the bastio section
 	v.Set("bastio.access_token", creds.AccessToken)
@@ -95,27 +248,73 @@ func SaveCredentials(creds *Credentials) error {
 	v.Set("bastio.device_id", creds.DeviceID)
 
 	// Write the config file
-	if err := v.WriteConfigAs(credPath); err != nil {
+	if err := v.WriteConfigAs(tmpPath); err != nil {
 		return fmt.Errorf("failed to write credentials: %w", err)
 	}
 
 	// Set secure permissions (owner read/write only)
-	if err := os.Chmod(credPath, CredentialsFileMode); err != nil {
+	if err := os.Chmod(tmpPath, CredentialsFileMode); err != nil {
 		return fmt.Errorf("failed to set credentials file permissions: %w", err)
 	}
 
+	if err := os.Rename(tmpPath, credPath); err != nil {
+		return fmt.Errorf("failed to write credentials: %w", err)
+	}
+
 	return nil
 }
 
-// DeleteCredentials removes the credentials file
+// backupCredentials copies the current credentials file aside before it is
+// overwritten. A damaged file is not copied, so it can't replace the last
+// good backup.
+func backupCredentials(credPath string) error {
+	data, err := os.ReadFile(credPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if ValidateCredentials(credPath, data) != nil {
+		return nil
+	}
+	return os.WriteFile(credPath+CredentialsBackupSuffix, data, CredentialsFileMode)
+}
+
+// deviceIDPattern finds a device ID line even in a file that no longer parses
+var deviceIDPattern = regexp.MustCompile(`(?m)^\s*device_id:\s*["']?([A-Za-z0-9_-]+)["']?\s*$`)
+
+// RecoverDeviceID returns the device ID recorded in the credentials file, or
+// failing that in its backup, reading leniently so a damaged file still
+// yields it. It returns "" when neither has one.
+func RecoverDeviceID() string {
+	credPath, err := CredentialsPath()
+	if err != nil {
+		return ""
+	}
+	for _, path := range []string{credPath, credPath + CredentialsBackupSuffix} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if m := deviceIDPattern.FindSubmatch(data); m != nil {
+			return string(m[1])
+		}
+	}
+	return ""
+}
+
+// DeleteCredentials removes the credentials file and its backup
 func DeleteCredentials() error {
 	credPath, err := CredentialsPath()
 	if err != nil {
 		return err
 	}
 
-	if err := os.Remove(credPath); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to delete credentials: %w", err)
+	for _, path := range []string{credPath, credPath + CredentialsBackupSuffix} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete credentials: %w", err)
+		}
 	}
 
 	return nil
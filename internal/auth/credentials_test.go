@@ -1,8 +1,17 @@
 package auth
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/bastio-ai/bast/internal/config"
 )
 
 func TestCredentials_IsExpired(t *testing.T) {
@@ -82,3 +91,135 @@ func TestCredentials_HasProxyCredentials(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateCredentials(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		problem string // Substring of the reported problem; empty means valid
+	}{
+		{"valid", "version: 1\nbastio:\n  proxy_api_key: key\n  proxy_id: proxy\n  device_id: abc123\n", ""},
+		{"no proxy yet", "version: 1\nbastio:\n  device_id: abc123\n", ""},
+		{"empty", "  \n", "empty"},
+		{"truncated", "version: 1\nbastio:\n  proxy_api_key: \"key\n", "line"},
+		{"unknown key", "version: 1\nbastio:\n  proxy_key: key\n", "unknown key proxy_key"},
+		{"wrong type", "version: 1\nbastio:\n  proxy_id: [a, b]\n", "line 3"},
+		{"missing proxy id", "version: 1\nbastio:\n  proxy_api_key: key\n", "bastio.proxy_id is missing"},
+		{"missing api key", "version: 1\nbastio:\n  proxy_id: proxy\n", "bastio.proxy_api_key is missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCredentials("credentials.yaml", []byte(tt.data))
+			if tt.problem == "" {
+				if err != nil {
+					t.Errorf("ValidateCredentials() error = %v, want nil", err)
+				}
+				return
+			}
+			var credErr *CredentialsError
+			if !errors.As(err, &credErr) {
+				t.Fatalf("ValidateCredentials() error = %v, want *CredentialsError", err)
+			}
+			if !strings.Contains(credErr.Problem, tt.problem) {
+				t.Errorf("Problem = %q, want it to mention %q", credErr.Problem, tt.problem)
+			}
+		})
+	}
+}
+
+func TestSaveCredentials_Backup(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	credPath, _ := CredentialsPath()
+
+	if err := SaveCredentials(&Credentials{ProxyAPIKey: "old", ProxyID: "proxy", DeviceID: "dev1"}); err != nil {
+		t.Fatalf("SaveCredentials() error = %v", err)
+	}
+	if _, err := os.Stat(credPath + CredentialsBackupSuffix); !os.IsNotExist(err) {
+		t.Errorf("first save should not leave a backup, stat err = %v", err)
+	}
+
+	if err := SaveCredentials(&Credentials{ProxyAPIKey: "new", ProxyID: "proxy", DeviceID: "dev1"}); err != nil {
+		t.Fatalf("SaveCredentials() error = %v", err)
+	}
+	backup, err := os.ReadFile(credPath + CredentialsBackupSuffix)
+	if err != nil {
+		t.Fatalf("reading backup: %v", err)
+	}
+	if !strings.Contains(string(backup), "old") {
+		t.Errorf("backup should hold the previous file, got:\n%s", backup)
+	}
+
+	// A damaged file must not replace the last good backup
+	os.WriteFile(credPath, []byte("bastio:\n  proxy_api_key: \"trunc"), CredentialsFileMode)
+	if _, err := LoadCredentials(); err == nil {
+		t.Error("LoadCredentials() should reject a damaged file")
+	}
+	if got := RecoverDeviceID(); got != "dev1" {
+		t.Errorf("RecoverDeviceID() = %q, want dev1 from the backup", got)
+	}
+	if err := SaveCredentials(&Credentials{ProxyAPIKey: "fixed", ProxyID: "proxy", DeviceID: "dev1"}); err != nil {
+		t.Fatalf("SaveCredentials() error = %v", err)
+	}
+	backup, _ = os.ReadFile(credPath + CredentialsBackupSuffix)
+	if !strings.Contains(string(backup), "old") {
+		t.Errorf("damaged file replaced the backup:\n%s", backup)
+	}
+	creds, err := LoadCredentials()
+	if err != nil || creds.ProxyAPIKey != "fixed" {
+		t.Errorf("LoadCredentials() = %+v, %v", creds, err)
+	}
+}
+
+func TestLoadCredentials_MigratesBeforeValidating(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	credPath, _ := CredentialsPath()
+
+	// A version 0 file with a key the current schema renamed is valid once
+	// migrated, so it must not be reported as damaged
+	saved := credentialsMigrations
+	defer func() { credentialsMigrations = saved }()
+	credentialsMigrations = append([]config.Migration{{
+		From:        0,
+		Description: "rename proxy_key",
+		Apply: func(doc *yaml.Node) error {
+			config.RenameKey(config.Key(doc, "bastio"), "proxy_key", "proxy_api_key")
+			return nil
+		},
+	}}, saved[1:]...)
+
+	os.MkdirAll(filepath.Dir(credPath), 0755)
+	os.WriteFile(credPath, []byte("bastio:\n  proxy_key: key\n  proxy_id: proxy\n"), CredentialsFileMode)
+	creds, err := LoadCredentials()
+	if err != nil {
+		t.Fatalf("LoadCredentials() error = %v", err)
+	}
+	if creds.ProxyAPIKey != "key" {
+		t.Errorf("ProxyAPIKey = %q, want the migrated key", creds.ProxyAPIKey)
+	}
+}
+
+func TestLoadCredentials_NewerSchema(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	credPath, _ := CredentialsPath()
+
+	data := []byte(fmt.Sprintf("version: %d\nbastio:\n  proxy_api_key: key\n  proxy_id: proxy\n  region: eu\n", CredentialsSchemaVersion+1))
+	os.MkdirAll(filepath.Dir(credPath), 0755)
+	os.WriteFile(credPath, data, CredentialsFileMode)
+
+	_, err := LoadCredentials()
+	var newer *config.NewerSchemaError
+	if !errors.As(err, &newer) {
+		t.Fatalf("LoadCredentials() error = %v, want *config.NewerSchemaError", err)
+	}
+	var credErr *CredentialsError
+	if errors.As(err, &credErr) {
+		t.Error("a newer file must not be reported as damaged")
+	}
+	if got, _ := os.ReadFile(credPath); string(got) != string(data) {
+		t.Errorf("newer file was rewritten:\n%s", got)
+	}
+	if err := ValidateCredentials(credPath, data); !errors.As(err, &newer) {
+		t.Errorf("ValidateCredentials() error = %v, want *config.NewerSchemaError", err)
+	}
+}
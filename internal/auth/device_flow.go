@@ -3,15 +3,14 @@ package auth
 import (
 	"bytes"
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"runtime"
 	"time"
+
+	"github.com/bastio-ai/bast/internal/useragent"
 )
 
 // DeviceAuthorizationResponse is the response from the device authorization endpoint
@@ -38,6 +37,7 @@ type TokenResponse struct {
 type DeviceFlowClient struct {
 	BaseURL    string
 	HTTPClient *http.Client
+	DeviceID   string // Overrides the generated device ID, e.g. to keep it across a repair
 }
 
 // NewDeviceFlowClient creates a new device flow client
@@ -64,14 +64,13 @@ func NewDeviceFlowClientWithURL(baseURL string) *DeviceFlowClient {
 func (c *DeviceFlowClient) StartDeviceFlow(ctx context.Context) (*DeviceAuthorizationResponse, error) {
 	url := c.BaseURL + "/cli/auth/device"
 
-	// Generate device ID from hostname + username
-	deviceID := generateDeviceID()
+	deviceID := c.deviceID()
 
 	reqBody := map[string]string{
 		"device_name": "bast-cli",
 		"device_id":   deviceID,
 		"os_info":     runtime.GOOS,
-		"cli_version": CLIVersion,
+		"cli_version": useragent.Version(),
 	}
 
 	jsonBody, err := json.Marshal(reqBody)
@@ -84,6 +83,7 @@ func (c *DeviceFlowClient) StartDeviceFlow(ctx context.Context) (*DeviceAuthoriz
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", useragent.String(deviceID))
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
@@ -113,18 +113,13 @@ func (c *DeviceFlowClient) StartDeviceFlow(ctx context.Context) (*DeviceAuthoriz
 	return &authResp, nil
 }
 
-// generateDeviceID generates a unique identifier for this device
-func generateDeviceID() string {
-	hostname, _ := os.Hostname()
-	username := os.Getenv("USER")
-	if username == "" {
-		username = os.Getenv("USERNAME") // Windows
+// deviceID returns the device ID to register: the kept one if set,
+// otherwise this device's saved or new ID
+func (c *DeviceFlowClient) deviceID() string {
+	if c.DeviceID != "" {
+		return c.DeviceID
 	}
-
-	// Create a hash of hostname + username
-	data := hostname + ":" + username
-	hash := sha256.Sum256([]byte(data))
-	return hex.EncodeToString(hash[:8]) // Use first 8 bytes (16 hex chars)
+	return registrationDeviceID()
 }
 
 // spinnerFrames contains the braille characters used for the animated spinner
@@ -218,6 +213,7 @@ func (c *DeviceFlowClient) requestToken(ctx context.Context, deviceCode string)
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", useragent.String(c.deviceID()))
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
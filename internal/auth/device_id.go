@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"sync"
+)
+
+var (
+	deviceIDMu sync.Mutex
+	deviceID   string
+)
+
+// DeviceID returns this device's saved ID for Bastio, or "" until a login
+// or enrollment saves one, so no device is reported before then. The random
+// ID survives DHCP hostname changes and machine renames, unlike one derived
+// from the hostname. Once found, the ID is cached for the process.
+func DeviceID() string {
+	deviceIDMu.Lock()
+	defer deviceIDMu.Unlock()
+	if deviceID == "" {
+		deviceID = loadDeviceID()
+	}
+	return deviceID
+}
+
+// loadDeviceID reads the saved device ID. Loading the credentials first
+// runs the migration that saves the hostname-derived ID of older installs.
+func loadDeviceID() string {
+	if id := RecoverDeviceID(); id != "" || !CredentialsExist() {
+		return id
+	}
+	if creds, err := LoadCredentials(); err == nil && creds != nil {
+		return creds.DeviceID
+	}
+	return ""
+}
+
+// registrationDeviceID returns the device ID for a login or enrollment to
+// register and save: the saved one, the hostname-derived one for a damaged
+// file from before the ID was saved, or else a new random ID
+func registrationDeviceID() string {
+	if id := DeviceID(); id != "" {
+		return id
+	}
+	if CredentialsExist() {
+		return legacyDeviceID()
+	}
+	return newDeviceID()
+}
+
+// legacyDeviceID is the ID earlier versions derived from the hostname and
+// username
+func legacyDeviceID() string {
+	hostname, _ := os.Hostname()
+	username := os.Getenv("USER")
+	if username == "" {
+		username = os.Getenv("USERNAME") // Windows
+	}
+	hash := sha256.Sum256([]byte(hostname + ":" + username))
+	return hex.EncodeToString(hash[:8])
+}
+
+// newDeviceID generates a random device ID in the same 16-hex-character
+// form as legacyDeviceID
+func newDeviceID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return hex.EncodeToString([]byte("bast-cli"))
+	}
+	return hex.EncodeToString(b)
+}
+
+// resetDeviceID forgets the cached device ID (for testing)
+func resetDeviceID() {
+	deviceIDMu.Lock()
+	defer deviceIDMu.Unlock()
+	deviceID = ""
+}
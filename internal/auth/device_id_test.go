@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeLegacyCredentials writes a credentials file from before the device
+// ID was saved
+func writeLegacyCredentials(t *testing.T) {
+	t.Helper()
+	credPath, err := CredentialsPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(filepath.Dir(credPath), 0755)
+	data := "version: 1\nbastio:\n  proxy_api_key: key\n  proxy_id: proxy\n"
+	if err := os.WriteFile(credPath, []byte(data), CredentialsFileMode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDeviceID_SavedOnLogin(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	resetDeviceID()
+	t.Cleanup(resetDeviceID)
+
+	// No device is reported, and nothing written, before the first login
+	if id := DeviceID(); id != "" {
+		t.Fatalf("DeviceID() before login = %q, want none", id)
+	}
+	id := registrationDeviceID()
+	if len(id) != 16 {
+		t.Fatalf("registrationDeviceID() = %q, want 16 hex characters", id)
+	}
+	if CredentialsExist() {
+		t.Fatal("reading the device ID should not create a credentials file")
+	}
+
+	// Logging in saves it, and a later run reads the same ID
+	if err := SaveCredentials(&Credentials{ProxyAPIKey: "key", ProxyID: "proxy", DeviceID: id}); err != nil {
+		t.Fatalf("SaveCredentials() error = %v", err)
+	}
+	resetDeviceID()
+	if got := DeviceID(); got != id {
+		t.Errorf("DeviceID() after restart = %q, want %q", got, id)
+	}
+}
+
+func TestDeviceID_LegacyCredentialsMigrated(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USER", "alice")
+	resetDeviceID()
+	t.Cleanup(resetDeviceID)
+
+	// Credentials from before the device ID was saved keep the
+	// hostname-derived ID they registered with, and the migration saves it
+	writeLegacyCredentials(t)
+	want := legacyDeviceID()
+	if got := DeviceID(); got != want {
+		t.Errorf("DeviceID() = %q, want the legacy ID %q", got, want)
+	}
+	if got := RecoverDeviceID(); got != want {
+		t.Errorf("saved device ID = %q, want the legacy ID %q", got, want)
+	}
+	creds, err := LoadCredentials()
+	if err != nil || creds.ProxyAPIKey != "key" || creds.DeviceID != want {
+		t.Errorf("LoadCredentials() = %+v, %v; want the proxy key kept and the ID saved", creds, err)
+	}
+
+	// A later rename doesn't change it
+	t.Setenv("USER", "bob")
+	resetDeviceID()
+	if got := DeviceID(); got != want {
+		t.Errorf("DeviceID() after rename = %q, want %q", got, want)
+	}
+}
+
+func TestDeviceID_LegacyKeptAcrossLogout(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	resetDeviceID()
+	t.Cleanup(resetDeviceID)
+
+	writeLegacyCredentials(t)
+	if err := NewAuthenticatorWithURL("http://unused").Logout(); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	if got, want := RecoverDeviceID(), legacyDeviceID(); got != want {
+		t.Errorf("device ID after logout = %q, want the legacy ID %q", got, want)
+	}
+}
+
+func TestDeviceID_KeptAcrossLogout(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	resetDeviceID()
+	t.Cleanup(resetDeviceID)
+
+	if err := SaveCredentials(&Credentials{ProxyAPIKey: "key", ProxyID: "proxy", DeviceID: "0123456789abcdef"}); err != nil {
+		t.Fatalf("SaveCredentials() error = %v", err)
+	}
+	if err := NewAuthenticatorWithURL("http://unused").Logout(); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+
+	creds, err := LoadCredentials()
+	if err != nil || creds == nil {
+		t.Fatalf("LoadCredentials() = %+v, %v", creds, err)
+	}
+	if creds.HasProxyCredentials() {
+		t.Error("Logout() should clear the proxy key")
+	}
+	if got := DeviceID(); got != "0123456789abcdef" {
+		t.Errorf("DeviceID() after logout = %q, want the original", got)
+	}
+	status, _ := NewAuthenticatorWithURL("http://unused").GetStatus(context.Background())
+	if status.LoggedIn {
+		t.Error("status should not be logged in after logout")
+	}
+}
+
+func TestLogout_NeverLoggedIn(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	resetDeviceID()
+	t.Cleanup(resetDeviceID)
+
+	if err := NewAuthenticatorWithURL("http://unused").Logout(); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	if CredentialsExist() {
+		t.Error("Logout() without credentials should not create a credentials file")
+	}
+}
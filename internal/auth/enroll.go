@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+
+	"github.com/bastio-ai/bast/internal/useragent"
+)
+
+// EnrollResponse is the response from the enrollment endpoint
+type EnrollResponse struct {
+	APIKey   string `json:"api_key"`   // The Bastio API key for gateway requests
+	ProxyID  string `json:"proxy_id"`  // The proxy provisioned for this device
+	DeviceID string `json:"device_id"` // Device ID for User-Agent header
+	Model    string `json:"model"`     // Organization default model; empty keeps bast's default
+
+	// Settings are config.yaml settings chosen by the organization, such
+	// as security validators, keyed like the config file
+	Settings map[string]any `json:"settings"`
+}
+
+// Enroll provisions this device with an organization enrollment token, in
+// place of the browser device flow, and saves the resulting credentials.
+// The organization's Anthropic key is held by Bastio, so none is needed here.
+func (a *Authenticator) Enroll(ctx context.Context, orgToken string) (*EnrollResponse, error) {
+	url := a.baseURL + "/cli/auth/enroll"
+	deviceID := registrationDeviceID()
+
+	reqBody := map[string]string{
+		"device_name": "bast-cli",
+		"device_id":   deviceID,
+		"os_info":     runtime.GOOS,
+		"cli_version": useragent.Version(),
+	}
+
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+orgToken)
+	req.Header.Set("User-Agent", useragent.String(deviceID))
+
+	client := &http.Client{Timeout: DefaultHTTPTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("enrollment request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, fmt.Errorf("enrollment token was rejected (status %d); ask your administrator for a current one", resp.StatusCode)
+	case resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated:
+		return nil, fmt.Errorf("enrollment failed (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var enrollResp EnrollResponse
+	if err := json.Unmarshal(body, &enrollResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if enrollResp.APIKey == "" || enrollResp.ProxyID == "" {
+		return nil, fmt.Errorf("enrollment response is missing the proxy credentials")
+	}
+
+	// Use the device_id we sent if backend didn't return one
+	if enrollResp.DeviceID == "" {
+		enrollResp.DeviceID = deviceID
+	}
+
+	creds := &Credentials{
+		ProxyAPIKey: enrollResp.APIKey,
+		ProxyID:     enrollResp.ProxyID,
+		DeviceID:    enrollResp.DeviceID,
+	}
+	if err := SaveCredentials(creds); err != nil {
+		return nil, fmt.Errorf("failed to save credentials: %w", err)
+	}
+
+	return &enrollResp, nil
+}
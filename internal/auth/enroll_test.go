@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEnroll(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/cli/auth/enroll" {
+			t.Errorf("path = %q, want /cli/auth/enroll", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer org-token" {
+			t.Errorf("Authorization = %q", got)
+		}
+		w.Write([]byte(`{"api_key":"bastio-key","proxy_id":"proxy-1","model":"claude-haiku-4-5-20251001","settings":{"mode":"safe"}}`))
+	}))
+	defer server.Close()
+
+	resp, err := NewAuthenticatorWithURL(server.URL).Enroll(context.Background(), "org-token")
+	if err != nil {
+		t.Fatalf("Enroll() error = %v", err)
+	}
+	if resp.Model != "claude-haiku-4-5-20251001" || resp.Settings["mode"] != "safe" {
+		t.Errorf("Enroll() = %+v", resp)
+	}
+
+	creds, err := LoadCredentials()
+	if err != nil {
+		t.Fatalf("LoadCredentials() error = %v", err)
+	}
+	if creds.ProxyAPIKey != "bastio-key" || creds.ProxyID != "proxy-1" || creds.DeviceID == "" {
+		t.Errorf("saved credentials = %+v", creds)
+	}
+}
+
+func TestEnroll_Rejected(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	_, err := NewAuthenticatorWithURL(server.URL).Enroll(context.Background(), "expired")
+	if err == nil || !strings.Contains(err.Error(), "rejected") {
+		t.Fatalf("Enroll() error = %v, want rejected token", err)
+	}
+	if CredentialsExist() {
+		t.Error("credentials saved for a rejected token")
+	}
+}
@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bastio-ai/bast/internal/useragent"
+)
+
+// HealthProbeTimeout bounds each endpoint probe so a dead host can't stall
+// `bast auth status`
+const HealthProbeTimeout = 5 * time.Second
+
+// Health states for an endpoint
+const (
+	HealthOK          = "ok"
+	HealthUnreachable = "unreachable"  // No HTTP response at all
+	HealthKeyRejected = "key rejected" // The endpoint answered 401 or 403
+	HealthError       = "error"        // The endpoint answered with another failure
+)
+
+// EndpointHealth is the result of probing one Bastio endpoint
+type EndpointHealth struct {
+	Name       string
+	URL        string
+	State      string
+	StatusCode int
+	Latency    time.Duration
+	Version    string
+	PolicyAge  time.Duration // Age of the security policy bundle; zero when not reported
+	Err        error
+}
+
+// Summary describes the result in one line for display
+func (h EndpointHealth) Summary() string {
+	switch h.State {
+	case HealthOK:
+		parts := []string{fmt.Sprintf("ok (%s)", h.Latency.Round(time.Millisecond))}
+		if h.Version != "" {
+			parts = append(parts, "version "+h.Version)
+		}
+		if h.PolicyAge > 0 {
+			parts = append(parts, "policy updated "+formatAge(h.PolicyAge)+" ago")
+		}
+		return strings.Join(parts, ", ")
+	case HealthUnreachable:
+		return fmt.Sprintf("unreachable: %v", h.Err)
+	case HealthKeyRejected:
+		return fmt.Sprintf("key rejected (status %d) - run 'bast auth login' to get a new key", h.StatusCode)
+	default:
+		return fmt.Sprintf("error (status %d)", h.StatusCode)
+	}
+}
+
+// healthResponse is the body the health and policy endpoints may return
+type healthResponse struct {
+	Version         string    `json:"version"`
+	PolicyVersion   string    `json:"policy_version"`
+	PolicyUpdatedAt time.Time `json:"policy_updated_at"`
+}
+
+// CheckHealth probes the API, the gateway, and, when creds hold a proxy key,
+// the security endpoint for that proxy. Each probe is independent, so a
+// rejected key shows up separately from an unreachable host.
+func (a *Authenticator) CheckHealth(ctx context.Context, creds *Credentials) []EndpointHealth {
+	client := &http.Client{Timeout: HealthProbeTimeout}
+	deviceID := DeviceID()
+
+	results := []EndpointHealth{
+		probe(ctx, client, "API", a.baseURL+"/health", "", deviceID),
+		probe(ctx, client, "Gateway", a.gatewayURL+"/health", "", deviceID),
+	}
+	if creds != nil && creds.HasProxyCredentials() {
+		url := fmt.Sprintf("%s/v1/guard/%s/status", a.baseURL, creds.ProxyID)
+		results = append(results, probe(ctx, client, "Security", url, creds.ProxyAPIKey, deviceID))
+	}
+	return results
+}
+
+// probe sends a GET to url and classifies the outcome
+func probe(ctx context.Context, client *http.Client, name, url, apiKey, deviceID string) EndpointHealth {
+	h := EndpointHealth{Name: name, URL: url}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		h.State, h.Err = HealthUnreachable, err
+		return h
+	}
+	req.Header.Set("User-Agent", useragent.String(deviceID))
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	h.Latency = time.Since(start)
+	if err != nil {
+		h.State, h.Err = HealthUnreachable, err
+		return h
+	}
+	defer resp.Body.Close()
+
+	h.StatusCode = resp.StatusCode
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		h.State = HealthKeyRejected
+		return h
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		h.State = HealthError
+		return h
+	}
+	h.State = HealthOK
+
+	// Version and policy details are best-effort: a bare 200 is still healthy
+	h.Version = resp.Header.Get("X-Bastio-Version")
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return h
+	}
+	var info healthResponse
+	if json.Unmarshal(body, &info) != nil {
+		return h
+	}
+	if info.Version != "" {
+		h.Version = info.Version
+	}
+	if info.PolicyVersion != "" && h.Version == "" {
+		h.Version = info.PolicyVersion
+	}
+	if !info.PolicyUpdatedAt.IsZero() {
+		h.PolicyAge = max(time.Since(info.PolicyUpdatedAt), time.Second)
+	}
+	return h
+}
+
+// formatAge renders d in the largest whole unit that fits
+func formatAge(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	default:
+		return fmt.Sprintf("%ds", int(d/time.Second))
+	}
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckHealth(t *testing.T) {
+	updated := time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			w.Header().Set("X-Bastio-Version", "1.4.0")
+			w.Write([]byte(`{"status":"ok"}`))
+		case "/v1/guard/proxy-1/status":
+			if got := r.Header.Get("Authorization"); got != "Bearer good-key" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"policy_version":"2024.06","policy_updated_at":"` + updated + `"}`))
+		default:
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	a := NewAuthenticatorWithURL(server.URL)
+	results := a.CheckHealth(context.Background(), &Credentials{ProxyAPIKey: "good-key", ProxyID: "proxy-1"})
+	if len(results) != 3 {
+		t.Fatalf("CheckHealth() returned %d results, want 3", len(results))
+	}
+	for _, h := range results {
+		if h.State != HealthOK {
+			t.Errorf("%s state = %q, want ok (err %v)", h.Name, h.State, h.Err)
+		}
+	}
+	if results[0].Version != "1.4.0" {
+		t.Errorf("API version = %q, want 1.4.0", results[0].Version)
+	}
+	security := results[2]
+	if security.Version != "2024.06" {
+		t.Errorf("Security version = %q, want 2024.06", security.Version)
+	}
+	if security.PolicyAge < time.Hour || security.PolicyAge > 3*time.Hour {
+		t.Errorf("PolicyAge = %v, want about 2h", security.PolicyAge)
+	}
+	if !strings.Contains(security.Summary(), "policy updated 2h ago") {
+		t.Errorf("Summary() = %q", security.Summary())
+	}
+
+	results = a.CheckHealth(context.Background(), &Credentials{ProxyAPIKey: "bad-key", ProxyID: "proxy-1"})
+	if got := results[2]; got.State != HealthKeyRejected || got.StatusCode != http.StatusUnauthorized {
+		t.Errorf("rejected key: state = %q, status = %d", got.State, got.StatusCode)
+	}
+	if results[0].State != HealthOK {
+		t.Errorf("API should stay ok when only the key is rejected, got %q", results[0].State)
+	}
+}
+
+func TestCheckHealth_NoCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	results := NewAuthenticatorWithURL(server.URL).CheckHealth(context.Background(), nil)
+	if len(results) != 2 {
+		t.Fatalf("CheckHealth() returned %d results, want 2 without credentials", len(results))
+	}
+	if results[1].State != HealthError || results[1].Summary() != "error (status 503)" {
+		t.Errorf("gateway = %q, %q", results[1].State, results[1].Summary())
+	}
+}
+
+func TestCheckHealth_Unreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	results := NewAuthenticatorWithURL(url).CheckHealth(context.Background(), nil)
+	for _, h := range results {
+		if h.State != HealthUnreachable || h.Err == nil {
+			t.Errorf("%s state = %q, err = %v, want unreachable", h.Name, h.State, h.Err)
+		}
+	}
+}
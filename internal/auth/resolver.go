@@ -32,7 +32,12 @@ func (e *ErrNoAPIKey) Error() string {
 //  4. Fall back to direct mode with ANTHROPIC_API_KEY or config
 func ResolveProviderConfig(cfg *config.Config) (ai.ProviderConfig, error) {
 	providerCfg := ai.ProviderConfig{
-		Model: cfg.Model,
+		Model:      cfg.Model,
+		Candidates: cfg.Candidates,
+		Anonymize:  cfg.Privacy.Anonymize,
+	}
+	if err := cfg.CheckModel(cfg.Model); err != nil {
+		return providerCfg, err
 	}
 
 	// 1. Check for explicit direct mode override
@@ -87,6 +92,10 @@ func resolveBastioCredentials(providerCfg ai.ProviderConfig) (ai.ProviderConfig,
 }
 
 func resolveDirectCredentials(cfg *config.Config, providerCfg ai.ProviderConfig) (ai.ProviderConfig, error) {
+	if err := cfg.CheckFeature(config.FeatureDirect); err != nil {
+		return providerCfg, err
+	}
+
 	// Try environment variables first
 	apiKey := os.Getenv("ANTHROPIC_API_KEY")
 	if apiKey == "" {
@@ -117,9 +126,10 @@ func resolveDirectCredentials(cfg *config.Config, providerCfg ai.ProviderConfig)
 
 // BastioSecurityConfig holds configuration for Bastio Agent Security
 type BastioSecurityConfig struct {
-	BaseURL string
-	ProxyID string
-	APIKey  string
+	BaseURL  string
+	ProxyID  string
+	APIKey   string
+	DeviceID string // Device ID for the User-Agent header
 }
 
 // GetBastioSecurityConfig extracts Bastio security configuration from credentials.
@@ -137,9 +147,10 @@ func GetBastioSecurityConfig() *BastioSecurityConfig {
 	}
 
 	return &BastioSecurityConfig{
-		BaseURL: GetBastioBaseURL(),
-		ProxyID: creds.ProxyID,
-		APIKey:  creds.ProxyAPIKey,
+		BaseURL:  GetBastioBaseURL(),
+		ProxyID:  creds.ProxyID,
+		APIKey:   creds.ProxyAPIKey,
+		DeviceID: creds.DeviceID,
 	}
 }
 
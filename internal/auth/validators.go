@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/bastio-ai/bast/internal/config"
+	"github.com/bastio-ai/bast/internal/debug"
+	"github.com/bastio-ai/bast/internal/tools"
+)
+
+// NewSecurityValidator builds the agent's validator chain from config.
+// Without explicit config, local checks run first and Bastio Agent Security
+// is chained after them when credentials are available. An unknown validator
+// name or fail mode is an error, so a typo never silently drops a check.
+func NewSecurityValidator(cfg config.SecurityConfig) (tools.SecurityValidator, error) {
+	// Generate a new session ID for this agent invocation
+	return NewSessionSecurityValidator(cfg, uuid.New().String())
+}
+
+// NewSessionSecurityValidator is NewSecurityValidator for agent runs that
+// belong to a longer session, such as a TUI session, so Bastio groups their
+// tool calls under sessionID
+func NewSessionSecurityValidator(cfg config.SecurityConfig, sessionID string) (tools.SecurityValidator, error) {
+	validators := []config.ValidatorConfig{{Name: "local"}, {Name: "bastio"}}
+	if len(cfg.Validators) > 0 {
+		validators = cfg.Validators
+	}
+
+	var stages []tools.ChainStage
+	for _, v := range validators {
+		stage := tools.ChainStage{Name: v.Name, FailMode: tools.FailMode(v.FailMode)}
+		switch stage.FailMode {
+		case "", tools.FailOpen, tools.FailClosed:
+		default:
+			return nil, fmt.Errorf("security validator %q has unknown fail_mode %q (use open or closed)", v.Name, v.FailMode)
+		}
+
+		switch v.Name {
+		case "local":
+			stage.Validator = tools.NewLocalSecurityClient()
+			if v.ChunkSize > 0 || v.ScanThreshold > 0 {
+				stage.Validator = tools.NewChunkedScanner(stage.Validator, v.ChunkSize, v.ScanThreshold)
+			}
+		case "bastio":
+			securityCfg := GetBastioSecurityConfig()
+			if securityCfg == nil {
+				if stage.FailMode != tools.FailClosed {
+					debug.Logf("bastio validator skipped: no credentials")
+					continue
+				}
+				// A validator that must not fail open can't be dropped
+				// just because it can't be reached
+				stage.Validator = unavailableValidator{reason: "Bastio Agent Security is required (fail_mode: closed) but no Bastio credentials are available; run 'bast auth login'"}
+				break
+			}
+			client := tools.NewBastioSecurityClient(
+				securityCfg.BaseURL,
+				securityCfg.ProxyID,
+				securityCfg.APIKey,
+				sessionID,
+			)
+			client.SetDeviceID(securityCfg.DeviceID)
+			// Remote scanning is priced by size, so large outputs are chunked by default;
+			// repeated read-only calls reuse allow verdicts to save round-trips
+			stage.Validator = tools.NewVerdictCache(
+				tools.NewChunkedScanner(client, v.ChunkSize, v.ScanThreshold), tools.DefaultVerdictTTL)
+		case "audit":
+			path, err := tools.DefaultAuditLogPath()
+			if err != nil {
+				return nil, fmt.Errorf("failed to set up audit validator: %w", err)
+			}
+			stage.Validator = tools.NewAuditLogger(path)
+		default:
+			return nil, fmt.Errorf("unknown security validator %q (use local, bastio, or audit)", v.Name)
+		}
+		stages = append(stages, stage)
+	}
+	return tools.NewChainValidator(stages...), nil
+}
+
+// unavailableValidator stands in for a fail-closed validator that couldn't
+// be set up, blocking every call and all output
+type unavailableValidator struct {
+	reason string
+}
+
+func (u unavailableValidator) ValidateToolCall(ctx context.Context, call tools.Call) (*tools.ValidationResult, error) {
+	return &tools.ValidationResult{Action: tools.ActionBlock, Message: u.reason}, nil
+}
+
+func (u unavailableValidator) ScanContent(ctx context.Context, toolName string, content string) (*tools.ScanResult, error) {
+	return &tools.ScanResult{Action: tools.ScanActionBlock, Message: u.reason}, nil
+}
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bastio-ai/bast/internal/config"
+	"github.com/bastio-ai/bast/internal/tools"
+)
+
+func TestNewSecurityValidator_RejectsUnknownConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		validators []config.ValidatorConfig
+		want       string
+	}{
+		{"fail mode typo", []config.ValidatorConfig{{Name: "local", FailMode: "close"}}, `unknown fail_mode "close"`},
+		{"validator typo", []config.ValidatorConfig{{Name: "locl"}}, `unknown security validator "locl"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSecurityValidator(config.SecurityConfig{Validators: tt.validators})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("NewSecurityValidator() error = %v, want it to mention %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSecurityValidator_BastioWithoutCredentials(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	call := tools.Call{ID: "1", Name: "run_command", Input: []byte(`{"command":"ls"}`)}
+
+	tests := []struct {
+		failMode string
+		want     tools.ValidationAction
+	}{
+		{"open", tools.ActionAllow},
+		{"closed", tools.ActionBlock},
+	}
+	for _, tt := range tests {
+		t.Run(tt.failMode, func(t *testing.T) {
+			validator, err := NewSecurityValidator(config.SecurityConfig{
+				Validators: []config.ValidatorConfig{{Name: "local"}, {Name: "bastio", FailMode: tt.failMode}},
+			})
+			if err != nil {
+				t.Fatalf("NewSecurityValidator() error = %v", err)
+			}
+			result, err := validator.ValidateToolCall(context.Background(), call)
+			if err != nil {
+				t.Fatalf("ValidateToolCall() error = %v", err)
+			}
+			if result.Action != tt.want {
+				t.Errorf("Action = %q, want %q (%s)", result.Action, tt.want, result.Message)
+			}
+			scan, err := validator.ScanContent(context.Background(), "run_command", "file.txt")
+			if err != nil {
+				t.Fatalf("ScanContent() error = %v", err)
+			}
+			if blocked := scan.Action == tools.ScanActionBlock; blocked != (tt.failMode == "closed") {
+				t.Errorf("scan Action = %q with fail_mode %s", scan.Action, tt.failMode)
+			}
+		})
+	}
+}
@@ -1,9 +1,13 @@
 package config
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -12,26 +16,295 @@ import (
 // For direct mode, the API key is stored at the root level (api_key).
 // For Bastio mode, credentials are stored separately in credentials.yaml.
 type Config struct {
+	// Version is the schema version of the file (see SchemaVersion)
+	Version int `mapstructure:"version"`
+
 	Mode     string `mapstructure:"mode"`     // "safe" or "yolo"
 	Provider string `mapstructure:"provider"` // AI provider (e.g., "anthropic")
 	APIKey   string `mapstructure:"api_key"`  // API key for direct mode
 	Model    string `mapstructure:"model"`    // Model to use (e.g., "claude-sonnet-4-20250514")
 	Gateway  string `mapstructure:"gateway"`  // "bastio" or "direct"
 
+	// FallbackModel is offered while rate limited, to retry on a smaller model
+	FallbackModel string `mapstructure:"fallback_model"`
+
+	// Candidates requests this many alternative commands per generation,
+	// ranked locally and paged through with Tab. 0 or 1 requests one command.
+	Candidates int `mapstructure:"candidates"`
+
+	// Debug writes redacted HTTP traces to ~/.config/bast/debug.log
+	Debug bool `mapstructure:"debug"`
+
+	// Prewarm opens the provider connection while the user is typing:
+	// "off" (default), "connect" (TLS handshake only), or "ping" (tiny request)
+	Prewarm string `mapstructure:"prewarm"`
+
+	// Sudo is the policy for generated commands that need sudo: "confirm"
+	// (default, acknowledge before use), "strip" (remove sudo), or "forbid"
+	Sudo string `mapstructure:"sudo"`
+
+	// SafeRewrite rewrites destructive file operations in generated commands:
+	// rm moves files to the trash (when a trash tool is installed) and mv
+	// asks before overwriting. The original is shown and can be kept.
+	SafeRewrite bool `mapstructure:"safe_rewrite"`
+
+	// Hyperlinks controls OSC 8 links for file paths and URLs in chat and
+	// agent output: "auto" (default, when the terminal supports them), "on", or "off"
+	Hyperlinks string `mapstructure:"hyperlinks"`
+
+	// EditorURL opens linked files in an editor, e.g. "vscode://file/{path}:{line}".
+	// Empty links to file:// URLs.
+	EditorURL string `mapstructure:"editor_url"`
+
+	// Editor opens referenced files with Ctrl+O: a command such as "code" or
+	// "myedit {path}:{line}". Empty uses $VISUAL, then $EDITOR.
+	Editor string `mapstructure:"editor"`
+
+	// IntentThreshold is the minimum classifier confidence for automatic routing.
+	// Below it, the TUI asks whether to run a command or just answer.
+	IntentThreshold float64 `mapstructure:"intent_threshold"`
+
 	// Bastio contains settings for Bastio gateway connection
 	Bastio BastioConfig `mapstructure:"bastio"`
+
+	// Security configures the validators that guard agent tool calls
+	Security SecurityConfig `mapstructure:"security"`
+
+	// Git controls how much repository context is gathered for prompts
+	Git GitConfig `mapstructure:"git"`
+
+	// Feedback controls what happens to ratings of generated commands
+	Feedback FeedbackConfig `mapstructure:"feedback"`
+
+	// Sessions controls the local record of chat and agent sessions
+	Sessions SessionsConfig `mapstructure:"sessions"`
+
+	// ToolOutput sets separate line budgets for agent command output
+	ToolOutput ToolOutputConfig `mapstructure:"tool_output"`
+
+	// Targets names execution targets for /target, e.g. "dev: ssh:dev-box"
+	// or "web: docker:web-1"
+	Targets map[string]string `mapstructure:"targets"`
+
+	// TUI controls how the interface is drawn
+	TUI TUIConfig `mapstructure:"tui"`
+
+	// Release configures the checks bast release runs before tagging
+	Release ReleaseConfig `mapstructure:"release"`
+
+	// Files controls how referenced files are read
+	Files FilesConfig `mapstructure:"files"`
+
+	// Context turns off context sources that are gathered for prompts
+	Context ContextConfig `mapstructure:"context"`
+
+	// Privacy controls what identifies the user in requests
+	Privacy PrivacyConfig `mapstructure:"privacy"`
+
+	// Notify controls the bell when bast needs attention or finishes a task
+	Notify NotifyConfig `mapstructure:"notify"`
+
+	// Hooks runs scripts at points in the agent lifecycle
+	Hooks HooksConfig `mapstructure:"hooks"`
+
+	// Managed is policy from the system config (see SystemConfigPath)
+	Managed ManagedConfig `mapstructure:"managed"`
+}
+
+// NotifyConfig holds settings for getting the user's attention
+type NotifyConfig struct {
+	// Bell is "off" (default), "audible" (terminal bell), "visual" (flash
+	// the frame), or "both". Terminals without a bell flash instead.
+	Bell string `mapstructure:"bell"`
+
+	// Events limits the bell to some of "danger" (a dangerous command to
+	// confirm), "approval" (the agent waits for a y/n), and "done" (an
+	// agent task finished). Empty means all of them.
+	Events []string `mapstructure:"events"`
+
+	// QuietHours mutes the audible bell during a daily span of local time,
+	// e.g. "22:00-07:00"; the frame still flashes
+	QuietHours string `mapstructure:"quiet_hours"`
+}
+
+// HooksConfig lists shell commands run at points in the agent lifecycle.
+// Each gets the event as JSON on stdin.
+type HooksConfig struct {
+	OnSessionStart []string `mapstructure:"on_session_start"` // An agent task starts
+	BeforeTool     []string `mapstructure:"before_tool"`      // A nonzero exit blocks the tool call
+	AfterTool      []string `mapstructure:"after_tool"`       // With the tool's result
+	OnSessionEnd   []string `mapstructure:"on_session_end"`   // An agent task finished or failed
+
+	// Timeout bounds each hook command (e.g. "5s", the default)
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// PrivacyConfig holds settings that keep the user's identity out of requests
+type PrivacyConfig struct {
+	// Anonymize replaces the username, hostname, and home directory with
+	// placeholders (bast-user, bast-host, /home/bast-user) in everything
+	// sent to the model, and maps them back in replies
+	Anonymize bool `mapstructure:"anonymize"`
+}
+
+// ContextConfig holds prompt context settings
+type ContextConfig struct {
+	// Disable lists context sources never sent to the model: "history",
+	// "git", "last_output", "project", and "identity" (the username).
+	// --no-context adds to this list for a single run.
+	Disable []string `mapstructure:"disable"`
+
+	// Probes adds a snapshot of CPU load, memory pressure, disk IO, and
+	// (on macOS) thermal state to chat and agent requests that ask about
+	// performance, like "why is my build slow". Off by default.
+	Probes bool `mapstructure:"probes"`
+}
+
+// FilesConfig holds file reading settings
+type FilesConfig struct {
+	// SlowMountThreshold skips files whose filesystem takes longer than
+	// this to resolve and stat, such as a stalled network mount (e.g.
+	// "300ms", the default)
+	SlowMountThreshold time.Duration `mapstructure:"slow_mount_threshold"`
+}
+
+// ReleaseConfig holds settings for bast release
+type ReleaseConfig struct {
+	// CICheck is a shell command that exits 0 when CI passed for HEAD.
+	// Empty skips the CI check.
+	CICheck string `mapstructure:"ci_check"`
+}
+
+// TUIConfig controls how the interface is drawn
+type TUIConfig struct {
+	// Inline draws the TUI compactly beneath the prompt, without the
+	// alternate screen or frame. Chat and agent sessions still switch to
+	// the full screen.
+	Inline bool `mapstructure:"inline"`
+}
+
+// ToolOutputConfig holds line budgets for the output of agent tool calls
+type ToolOutputConfig struct {
+	// PromptLines caps the lines sent back to the model, keeping the head,
+	// tail, and error lines. 0 uses the default (60).
+	PromptLines int `mapstructure:"prompt_lines"`
+
+	// DisplayLines caps the lines shown per tool call in the TUI, which
+	// scrolls. 0 uses the default (40).
+	DisplayLines int `mapstructure:"display_lines"`
+}
+
+// FeedbackConfig holds settings for command ratings
+type FeedbackConfig struct {
+	// Share also sends ratings (query, command, verdict, comment) to Bastio.
+	// Ratings are always kept locally in ~/.config/bast/feedback.jsonl.
+	Share bool `mapstructure:"share"`
+}
+
+// SessionsConfig holds settings for saved sessions
+type SessionsConfig struct {
+	// Save keeps a transcript of each chat and agent session in
+	// ~/.config/bast/sessions for bast sessions list and share, and a
+	// step-by-step record of agent runs in ~/.config/bast/transcripts.
+	// Off by default, since responses and tool output can hold anything
+	// the agent read; credentials are redacted before they are written.
+	Save bool `mapstructure:"save"`
+
+	// PasteURL is a paste service that bast sessions share --upload posts
+	// redacted transcripts to. Empty uploads a secret GitHub gist instead.
+	PasteURL string `mapstructure:"paste_url"`
 }
 
 // BastioConfig holds settings for Bastio gateway connection
 type BastioConfig struct {
 	ProxyID string `mapstructure:"proxy_id"`
+
+	// Self-hosted or regional Bastio endpoints, usually set in the system
+	// config. Empty uses the public service; BASTIO_*_URL variables win.
+	APIURL     string `mapstructure:"api_url"`
+	GatewayURL string `mapstructure:"gateway_url"`
+	WebURL     string `mapstructure:"web_url"`
+}
+
+// SecurityConfig holds agent tool call validation settings
+type SecurityConfig struct {
+	// Validators run in order and stop at the first block.
+	// Empty means "local" then "bastio" (when credentials exist).
+	Validators []ValidatorConfig `mapstructure:"validators"`
+
+	// ConfirmWarnings pauses the agent for a y/n before running a tool call
+	// that a validator flagged with a warning.
+	ConfirmWarnings bool `mapstructure:"confirm_warnings"`
+
+	// Env filters the environment of commands the agent and plugins run
+	Env EnvConfig `mapstructure:"env"`
+}
+
+// EnvConfig selects which environment variables agent commands inherit
+type EnvConfig struct {
+	// Mode is "denylist" (default, drop credential-like variables such as
+	// AWS_* and GITHUB_TOKEN) or "allowlist" (keep only basics like PATH and HOME)
+	Mode string `mapstructure:"mode"`
+
+	// Deny adds name patterns to drop in denylist mode, e.g. "STRIPE_*"
+	Deny []string `mapstructure:"deny"`
+
+	// Allow adds name patterns to keep in allowlist mode, e.g. "GO*"
+	Allow []string `mapstructure:"allow"`
+}
+
+// GitConfig holds git context settings
+type GitConfig struct {
+	// Context is "off", "fast" (branch and status only), or "full" (default)
+	Context string `mapstructure:"context"`
+
+	// Budget caps the total time spent on git commands (e.g. "500ms")
+	Budget time.Duration `mapstructure:"budget"`
+}
+
+// ValidatorConfig selects one validator in the security chain
+type ValidatorConfig struct {
+	Name     string `mapstructure:"name"`      // "local", "bastio", or "audit"
+	FailMode string `mapstructure:"fail_mode"` // "open" (default) or "closed"
+
+	// Large outputs are scanned in chunks of ChunkSize bytes; above
+	// ScanThreshold only the head, tail and sampled middle chunks are sent.
+	ChunkSize     int `mapstructure:"chunk_size"`
+	ScanThreshold int `mapstructure:"scan_threshold"`
 }
 
 const (
 	DefaultMode     = "safe"
 	DefaultProvider = "anthropic"
 	DefaultModel    = "claude-sonnet-4-5-20250929"
-	DefaultGateway  = "direct" // "bastio" or "direct"
+
+	// DefaultFallbackModel is the smaller model offered while rate limited
+	DefaultFallbackModel = "claude-haiku-4-5-20251001"
+	DefaultGateway       = "direct" // "bastio" or "direct"
+
+	// Prewarm modes
+	PrewarmOff     = "off"
+	PrewarmConnect = "connect"
+	PrewarmPing    = "ping"
+
+	// Hyperlink modes
+	HyperlinksAuto = "auto"
+	HyperlinksOn   = "on"
+	HyperlinksOff  = "off"
+
+	// Bell modes (see NotifyConfig)
+	BellOff     = "off"
+	BellAudible = "audible"
+	BellVisual  = "visual"
+	BellBoth    = "both"
+
+	// Sudo policies (see safety.ApplySudoPolicy)
+	SudoConfirm = "confirm"
+	SudoStrip   = "strip"
+	SudoForbid  = "forbid"
+
+	// DefaultIntentThreshold is the default minimum intent classification confidence
+	DefaultIntentThreshold = 0.6
 
 	// Gateway modes
 	GatewayBastio = "bastio"
@@ -60,6 +333,11 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 
+	// Upgrade files written by older versions before they are parsed
+	if err := MigrateAndReport("config.yaml", filepath.Join(configDir, "config.yaml"), SchemaVersion, configMigrations); err != nil {
+		return nil, err
+	}
+
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
 	viper.AddConfigPath(configDir)
@@ -68,14 +346,32 @@ func Load() (*Config, error) {
 	viper.SetDefault("mode", DefaultMode)
 	viper.SetDefault("provider", DefaultProvider)
 	viper.SetDefault("model", DefaultModel)
+	viper.SetDefault("fallback_model", DefaultFallbackModel)
 	viper.SetDefault("gateway", DefaultGateway)
+	viper.SetDefault("intent_threshold", DefaultIntentThreshold)
+	viper.SetDefault("hyperlinks", HyperlinksAuto)
+	viper.SetDefault("notify.bell", BellOff)
+	viper.SetDefault("sessions.save", false)
 
 	// Allow environment variable overrides
 	viper.SetEnvPrefix("BAST")
 	viper.AutomaticEnv()
 
-	// Read config file (if exists)
-	if err := viper.ReadInConfig(); err != nil {
+	sys, sysData, err := readSystemConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// Read config file (if exists), over the system config when there is one
+	if sys != nil {
+		if err := viper.ReadConfig(bytes.NewReader(sysData)); err != nil {
+			return nil, fmt.Errorf("error reading system config: %w", err)
+		}
+		err = viper.MergeInConfig()
+	} else {
+		err = viper.ReadInConfig()
+	}
+	if err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			return nil, fmt.Errorf("error reading config: %w", err)
 		}
@@ -86,6 +382,9 @@ func Load() (*Config, error) {
 	if err := viper.Unmarshal(&cfg); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	if err := cfg.applyManaged(sys); err != nil {
+		return nil, err
+	}
 
 	return &cfg, nil
 }
@@ -102,29 +401,78 @@ func Save(cfg *Config) error {
 	}
 
 	configPath := filepath.Join(configDir, "config.yaml")
+	v, err := readUserConfig(configPath)
+	if err != nil {
+		return err
+	}
 
-	viper.Set("mode", cfg.Mode)
-	viper.Set("provider", cfg.Provider)
-	viper.Set("model", cfg.Model)
-	viper.Set("gateway", cfg.Gateway)
+	v.Set("version", SchemaVersion)
+	v.Set("mode", cfg.Mode)
+	v.Set("provider", cfg.Provider)
+	v.Set("model", cfg.Model)
+	v.Set("gateway", cfg.Gateway)
 
 	// Only save API key for direct mode
 	if cfg.Gateway == GatewayDirect && cfg.APIKey != "" {
-		viper.Set("api_key", cfg.APIKey)
+		v.Set("api_key", cfg.APIKey)
 	}
 
 	// Save bastio config if set
 	if cfg.Bastio.ProxyID != "" {
-		viper.Set("bastio.proxy_id", cfg.Bastio.ProxyID)
+		v.Set("bastio.proxy_id", cfg.Bastio.ProxyID)
 	}
 
-	if err := viper.WriteConfigAs(configPath); err != nil {
+	if err := v.WriteConfigAs(configPath); err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
 	}
 
 	return nil
 }
 
+// Provision merges settings, keyed as in config.yaml, into the user's
+// config, as chosen by an organization at enrollment. A managed section is
+// dropped, since policy only applies from the system config.
+func Provision(settings map[string]any) error {
+	configPath, err := DefaultConfigPath()
+	if err != nil {
+		return err
+	}
+	v, err := readUserConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	provisioned := make(map[string]any, len(settings))
+	for key, value := range settings {
+		if key != "managed" {
+			provisioned[key] = value
+		}
+	}
+	if err := v.MergeConfigMap(provisioned); err != nil {
+		return fmt.Errorf("invalid settings: %w", err)
+	}
+	v.Set("version", SchemaVersion)
+
+	if err := v.WriteConfigAs(configPath); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// readUserConfig reads the user's config.yaml alone, so system settings and
+// defaults are not copied into it when it is written back
+func readUserConfig(configPath string) (*viper.Viper, error) {
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create config directory: %w", err)
+	}
+	v := viper.New()
+	v.SetConfigFile(configPath)
+	if err := v.ReadInConfig(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("error reading config: %w", err)
+	}
+	return v, nil
+}
+
 func ConfigExists() bool {
 	configPath, err := DefaultConfigPath()
 	if err != nil {
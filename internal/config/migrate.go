@@ -0,0 +1,222 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaVersion is the config.yaml schema this build reads and writes
+const SchemaVersion = 1
+
+// VersionKey is the top-level key that records a file's schema version.
+// Files without it are version 0.
+const VersionKey = "version"
+
+// Migration upgrades a YAML file from one schema version to the next
+type Migration struct {
+	// From is the version this migration upgrades; the result is From+1
+	From int
+
+	// Description says what changed, for the migration notice
+	Description string
+
+	// Apply edits the top-level mapping in place. Working on the node
+	// rather than a decoded map keeps the user's comments and key order.
+	Apply func(doc *yaml.Node) error
+}
+
+// MigrationResult describes a migrated file
+type MigrationResult struct {
+	From, To int
+	Backup   string   // copy of the file before migration
+	Applied  []string // descriptions of the migrations that ran
+}
+
+// NewerSchemaError reports a file written by a newer bast, which this build
+// must not read or rewrite
+type NewerSchemaError struct {
+	Path      string
+	Version   int
+	Supported int
+}
+
+func (e *NewerSchemaError) Error() string {
+	return fmt.Sprintf("%s uses schema version %d but this bast only supports up to %d; upgrade bast", e.Path, e.Version, e.Supported)
+}
+
+// configMigrations upgrade config.yaml, in order
+var configMigrations = []Migration{
+	{
+		From:        0,
+		Description: "record the schema version",
+		Apply:       func(doc *yaml.Node) error { return nil },
+	},
+}
+
+// MigrateFile upgrades the YAML file at path to version current. A backup
+// of the old file is written next to it as <path>.v<N>.bak with the same
+// permissions, and the new version is recorded under VersionKey. A missing
+// file, or one already at current, is left alone and yields a nil result.
+// A file from a newer schema is a *NewerSchemaError, so it is never misread.
+func MigrateFile(path string, current int, migrations []Migration) (*MigrationResult, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	doc := documentMapping(&root)
+	if doc == nil {
+		// Empty file: nothing to misread
+		return nil, nil
+	}
+
+	version, err := fileVersion(doc)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if version > current {
+		return nil, &NewerSchemaError{Path: path, Version: version, Supported: current}
+	}
+	if version == current {
+		return nil, nil
+	}
+
+	result := &MigrationResult{From: version, To: current}
+	for v := version; v < current; v++ {
+		m := findMigration(migrations, v)
+		if m == nil {
+			return nil, fmt.Errorf("%s: no migration from schema version %d", path, v)
+		}
+		if err := m.Apply(doc); err != nil {
+			return nil, fmt.Errorf("%s: migrating from version %d (%s): %w", path, v, m.Description, err)
+		}
+		result.Applied = append(result.Applied, m.Description)
+	}
+	SetKey(doc, VersionKey, strconv.Itoa(current))
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&root); err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	enc.Close()
+
+	mode := info.Mode().Perm()
+	result.Backup = fmt.Sprintf("%s.v%d.bak", path, version)
+	if err := os.WriteFile(result.Backup, data, mode); err != nil {
+		return nil, fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+	if err := os.Chmod(result.Backup, mode); err != nil {
+		return nil, fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+
+	// Write beside the original and rename, so a failure never leaves a
+	// half-written file
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), mode); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return nil, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return result, nil
+}
+
+// documentMapping returns the top-level mapping of a parsed document
+func documentMapping(root *yaml.Node) *yaml.Node {
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 {
+		return nil
+	}
+	if doc := root.Content[0]; doc.Kind == yaml.MappingNode {
+		return doc
+	}
+	return nil
+}
+
+// fileVersion reads VersionKey from a top-level mapping
+func fileVersion(doc *yaml.Node) (int, error) {
+	value := Key(doc, VersionKey)
+	if value == nil {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(value.Value)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid schema version %q", value.Value)
+	}
+	return v, nil
+}
+
+func findMigration(migrations []Migration, from int) *Migration {
+	for i := range migrations {
+		if migrations[i].From == from {
+			return &migrations[i]
+		}
+	}
+	return nil
+}
+
+// Key returns the value node for key in a mapping, or nil
+func Key(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// SetKey sets a scalar value in a mapping, adding the key if needed
+func SetKey(mapping *yaml.Node, key, value string) {
+	if node := Key(mapping, key); node != nil {
+		node.Kind, node.Tag, node.Value, node.Content = yaml.ScalarNode, "", value, nil
+		return
+	}
+	mapping.Content = append(mapping.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Value: value},
+	)
+}
+
+// RenameKey moves a key in a mapping, keeping its value and position.
+// It does nothing if old is absent or new is already set.
+func RenameKey(mapping *yaml.Node, old, new string) {
+	if Key(mapping, new) != nil {
+		return
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == old {
+			mapping.Content[i].Value = new
+			return
+		}
+	}
+}
+
+// MigrateAndReport runs MigrateFile and prints a one-line notice to
+// stderr when the file was upgraded
+func MigrateAndReport(name, path string, current int, migrations []Migration) error {
+	r, err := MigrateFile(path, current, migrations)
+	if err != nil {
+		return err
+	}
+	if r != nil {
+		fmt.Fprintf(os.Stderr, "bast: upgraded %s from schema version %d to %d (previous file saved as %s)\n", name, r.From, r.To, r.Backup)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+var testMigrations = []Migration{
+	{From: 0, Description: "rename key", Apply: func(doc *yaml.Node) error {
+		RenameKey(doc, "ai_model", "model")
+		return nil
+	}},
+	{From: 1, Description: "default mode", Apply: func(doc *yaml.Node) error {
+		if Key(doc, "mode") == nil {
+			SetKey(doc, "mode", "safe")
+		}
+		return nil
+	}},
+}
+
+func TestMigrateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	old := "# my settings\nai_model: claude # preferred\n"
+	if err := os.WriteFile(path, []byte(old), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := MigrateFile(path, 2, testMigrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r == nil || r.From != 0 || r.To != 2 || len(r.Applied) != 2 {
+		t.Fatalf("result = %+v", r)
+	}
+
+	data, _ := os.ReadFile(path)
+	got := string(data)
+	for _, want := range []string{"# my settings", "model: claude # preferred", "mode: safe", "version: 2"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("migrated file missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "ai_model") {
+		t.Errorf("old key kept:\n%s", got)
+	}
+
+	backup, err := os.ReadFile(r.Backup)
+	if err != nil || string(backup) != old {
+		t.Errorf("backup = %q, %v; want the original file", backup, err)
+	}
+	if info, _ := os.Stat(r.Backup); info.Mode().Perm() != 0600 {
+		t.Errorf("backup mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	// Already current: untouched
+	if r, err := MigrateFile(path, 2, testMigrations); r != nil || err != nil {
+		t.Errorf("second run = %+v, %v; want nil, nil", r, err)
+	}
+}
+
+func TestMigrateFileNewerVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("version: 3\nmode: yolo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := MigrateFile(path, 2, testMigrations)
+	var newer *NewerSchemaError
+	if !errors.As(err, &newer) || newer.Version != 3 || !strings.Contains(err.Error(), "upgrade bast") {
+		t.Errorf("err = %v, want a newer-schema error", err)
+	}
+}
+
+func TestMigrateFileMissingOrEmpty(t *testing.T) {
+	dir := t.TempDir()
+	if r, err := MigrateFile(filepath.Join(dir, "none.yaml"), 2, testMigrations); r != nil || err != nil {
+		t.Errorf("missing file = %+v, %v", r, err)
+	}
+	empty := filepath.Join(dir, "empty.yaml")
+	os.WriteFile(empty, nil, 0644)
+	if r, err := MigrateFile(empty, 2, testMigrations); r != nil || err != nil {
+		t.Errorf("empty file = %+v, %v", r, err)
+	}
+}
+
+func TestMigrateFileMissingStep(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(path, []byte("mode: safe\n"), 0644)
+	if _, err := MigrateFile(path, 3, testMigrations); err == nil {
+		t.Error("expected an error for a missing migration step")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "mode: safe\n" {
+		t.Errorf("file changed after a failed migration: %q", data)
+	}
+}
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// SystemConfigPath is the machine-wide config that platform teams provision.
+// Its settings apply under the user's config.yaml, and its managed section
+// sets policy the user cannot override.
+var SystemConfigPath = "/etc/bast/config.yaml"
+
+// Features a system config can turn off with managed.disabled_features
+const (
+	FeatureAgent   = "agent"   // Agentic mode, /agent, and bast onboard
+	FeaturePlugins = "plugins" // User plugins in ~/.config/bast/tools
+	FeatureYolo    = "yolo"    // mode: yolo, which runs commands without confirmation
+	FeatureDirect  = "direct"  // The direct Anthropic API, bypassing the Bastio gateway
+)
+
+// Features lists every feature a system config can turn off
+var Features = []string{FeatureAgent, FeaturePlugins, FeatureYolo, FeatureDirect}
+
+// ManagedConfig is policy set by the system config. It is ignored in the
+// user's config.yaml.
+type ManagedConfig struct {
+	// AllowedModels restricts the models bast may use. Empty allows any.
+	AllowedModels []string `mapstructure:"allowed_models"`
+
+	// DisabledFeatures lists features (see Features) that are turned off
+	DisabledFeatures []string `mapstructure:"disabled_features"`
+
+	// Locked lists top-level settings, such as "security" or "privacy",
+	// whose system values replace the user's entirely
+	Locked []string `mapstructure:"locked"`
+}
+
+// ErrFeatureDisabled is returned when the system config turns off a feature
+type ErrFeatureDisabled struct {
+	Feature string
+}
+
+func (e *ErrFeatureDisabled) Error() string {
+	return fmt.Sprintf("%s is disabled by %s", e.Feature, SystemConfigPath)
+}
+
+// readSystemConfig returns the system config and its raw contents, or nil
+// when there is none
+func readSystemConfig() (*viper.Viper, []byte, error) {
+	data, err := os.ReadFile(SystemConfigPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading system config: %w", err)
+	}
+	sys := viper.New()
+	sys.SetConfigType("yaml")
+	if err := sys.ReadConfig(bytes.NewReader(data)); err != nil {
+		return nil, nil, fmt.Errorf("error reading system config %s: %w", SystemConfigPath, err)
+	}
+	return sys, data, nil
+}
+
+// applyManaged enforces the system config's managed section on c
+func (c *Config) applyManaged(sys *viper.Viper) error {
+	c.Managed = ManagedConfig{}
+	if sys == nil {
+		return nil
+	}
+	if err := sys.UnmarshalKey("managed", &c.Managed); err != nil {
+		return fmt.Errorf("invalid managed section in %s: %w", SystemConfigPath, err)
+	}
+	for _, feature := range c.Managed.DisabledFeatures {
+		if !isFeature(feature) {
+			return fmt.Errorf("unknown feature %q in %s (want one of %s)", feature, SystemConfigPath, strings.Join(Features, ", "))
+		}
+	}
+
+	for _, key := range c.Managed.Locked {
+		field, ok := c.settingField(key)
+		if !ok {
+			return fmt.Errorf("unknown setting %q in managed.locked of %s", key, SystemConfigPath)
+		}
+		field.Set(reflect.Zero(field.Type()))
+		if err := sys.UnmarshalKey(key, field.Addr().Interface()); err != nil {
+			return fmt.Errorf("invalid %s in %s: %w", key, SystemConfigPath, err)
+		}
+	}
+
+	if !c.FeatureEnabled(FeatureYolo) && c.Mode == "yolo" {
+		c.Mode = DefaultMode
+	}
+	if c.FallbackModel != "" && !c.ModelAllowed(c.FallbackModel) {
+		c.FallbackModel = ""
+	}
+	return nil
+}
+
+// settingField returns the field of c that holds the top-level setting key
+func (c *Config) settingField(key string) (reflect.Value, bool) {
+	v := reflect.ValueOf(c).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if tag := v.Type().Field(i).Tag.Get("mapstructure"); tag == key && key != "managed" {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// FeatureEnabled reports whether the system config leaves feature on
+func (c *Config) FeatureEnabled(feature string) bool {
+	for _, disabled := range c.Managed.DisabledFeatures {
+		if disabled == feature {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckFeature returns an ErrFeatureDisabled when feature is turned off
+func (c *Config) CheckFeature(feature string) error {
+	if !c.FeatureEnabled(feature) {
+		return &ErrFeatureDisabled{Feature: feature}
+	}
+	return nil
+}
+
+// ModelAllowed reports whether the system config permits model
+func (c *Config) ModelAllowed(model string) bool {
+	if len(c.Managed.AllowedModels) == 0 {
+		return true
+	}
+	for _, allowed := range c.Managed.AllowedModels {
+		if allowed == model {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckModel returns an error when the system config does not permit model
+func (c *Config) CheckModel(model string) error {
+	if c.ModelAllowed(model) {
+		return nil
+	}
+	return fmt.Errorf("model %s is not allowed by %s (allowed: %s)",
+		model, SystemConfigPath, strings.Join(c.Managed.AllowedModels, ", "))
+}
+
+func isFeature(name string) bool {
+	for _, feature := range Features {
+		if name == feature {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// setupConfigs writes a system and a user config into a temporary home
+func setupConfigs(t *testing.T, system, user string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	old := SystemConfigPath
+	SystemConfigPath = filepath.Join(home, "etc-bast.yaml")
+	t.Cleanup(func() { SystemConfigPath = old })
+	if system != "" {
+		if err := os.WriteFile(SystemConfigPath, []byte(system), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dir := filepath.Join(home, ".config", "bast")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if user != "" {
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(user), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadMergesSystemConfig(t *testing.T) {
+	setupConfigs(t, `
+gateway: bastio
+bastio:
+  gateway_url: https://bastio.internal.example
+security:
+  confirm_warnings: true
+managed:
+  allowed_models: [claude-sonnet-4-5-20250929]
+  disabled_features: [yolo, plugins]
+  locked: [security]
+`, `
+version: 1
+mode: yolo
+model: claude-opus-4-6
+security:
+  confirm_warnings: false
+managed:
+  disabled_features: []
+`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Gateway != GatewayBastio || cfg.Bastio.GatewayURL != "https://bastio.internal.example" {
+		t.Errorf("system settings not applied: gateway %q, url %q", cfg.Gateway, cfg.Bastio.GatewayURL)
+	}
+	if cfg.Model != "claude-opus-4-6" {
+		t.Errorf("Model = %q, want the user's choice", cfg.Model)
+	}
+	if !cfg.Security.ConfirmWarnings {
+		t.Error("locked security section was overridden by the user")
+	}
+	if cfg.Mode != DefaultMode {
+		t.Errorf("Mode = %q with yolo disabled, want %q", cfg.Mode, DefaultMode)
+	}
+	if cfg.FeatureEnabled(FeaturePlugins) || !cfg.FeatureEnabled(FeatureAgent) {
+		t.Errorf("features = %v, want only yolo and plugins off", cfg.Managed.DisabledFeatures)
+	}
+	var disabled *ErrFeatureDisabled
+	if err := cfg.CheckFeature(FeaturePlugins); !errors.As(err, &disabled) {
+		t.Errorf("CheckFeature(plugins) = %v, want ErrFeatureDisabled", err)
+	}
+	if err := cfg.CheckModel(cfg.Model); err == nil {
+		t.Error("CheckModel allowed a model outside allowed_models")
+	}
+	if cfg.FallbackModel != "" {
+		t.Errorf("FallbackModel = %q, want it dropped when not allowed", cfg.FallbackModel)
+	}
+}
+
+func TestLoadWithoutSystemConfig(t *testing.T) {
+	setupConfigs(t, "", "version: 1\nmodel: claude-haiku-4-5-20251001\nmanaged:\n  disabled_features: [agent]\n")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Model != "claude-haiku-4-5-20251001" {
+		t.Errorf("Model = %q", cfg.Model)
+	}
+	if !cfg.FeatureEnabled(FeatureAgent) {
+		t.Error("a managed section in the user's config took effect")
+	}
+}
+
+func TestLoadRejectsUnknownFeature(t *testing.T) {
+	setupConfigs(t, "managed:\n  disabled_features: [agnet]\n", "")
+	if _, err := Load(); err == nil {
+		t.Error("Load() accepted an unknown feature")
+	}
+}
+
+func TestSaveDoesNotCopySystemSettings(t *testing.T) {
+	setupConfigs(t, "bastio:\n  gateway_url: https://bastio.internal.example\n", "version: 1\nhyperlinks: off\n")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Model = "claude-haiku-4-5-20251001"
+	if err := Save(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	path, _ := DefaultConfigPath()
+	saved := viper.New()
+	saved.SetConfigFile(path)
+	if err := saved.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if saved.IsSet("bastio.gateway_url") {
+		t.Error("Save copied a system setting into the user's config")
+	}
+	if saved.GetString("hyperlinks") != "off" || saved.GetString("model") != "claude-haiku-4-5-20251001" {
+		t.Errorf("Save lost settings: %v", saved.AllSettings())
+	}
+}
+
+func TestProvision(t *testing.T) {
+	setupConfigs(t, "", "model: claude-opus-4-6\nmode: yolo\n")
+
+	err := Provision(map[string]any{
+		"mode":    "safe",
+		"managed": map[string]any{"locked": []any{"security"}},
+	})
+	if err != nil {
+		t.Fatalf("Provision() error = %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Mode != "safe" {
+		t.Errorf("Mode = %q, want provisioned safe", cfg.Mode)
+	}
+	if cfg.Model != "claude-opus-4-6" {
+		t.Errorf("Model = %q, want the user's model kept", cfg.Model)
+	}
+	if len(cfg.Managed.Locked) != 0 {
+		t.Errorf("Managed.Locked = %v, want managed settings dropped", cfg.Managed.Locked)
+	}
+}
@@ -0,0 +1,197 @@
+// Package debug writes redacted HTTP traces and diagnostics to a log file.
+package debug
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bastio-ai/bast/internal/safety"
+)
+
+const (
+	// LogFileName is the debug log file under the bast config directory
+	LogFileName = "debug.log"
+	// MaxLogSize is the size at which the debug log is rotated to debug.log.1
+	MaxLogSize = 5 * 1024 * 1024
+	// MaxBodyBytes caps how much of each request/response body is traced
+	MaxBodyBytes = 4096
+)
+
+// sensitiveHeaders are masked in traces
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"X-Api-Key":           true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+var (
+	mu      sync.Mutex
+	enabled bool
+	logPath string
+)
+
+// DefaultLogPath returns the default path for the debug log
+func DefaultLogPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", "bast", LogFileName), nil
+}
+
+// Enable turns on debug logging to path (the default path if empty)
+func Enable(path string) error {
+	if path == "" {
+		var err error
+		if path, err = DefaultLogPath(); err != nil {
+			return err
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	enabled = true
+	logPath = path
+	return nil
+}
+
+// Enabled reports whether debug logging is on.
+// BAST_DEBUG_HTTP=1 is honored for compatibility and logs to the default path.
+func Enabled() bool {
+	mu.Lock()
+	on := enabled
+	mu.Unlock()
+	if on {
+		return true
+	}
+	if os.Getenv("BAST_DEBUG_HTTP") == "1" {
+		return Enable("") == nil
+	}
+	return false
+}
+
+// Logf appends a timestamped line to the debug log when enabled
+func Logf(format string, args ...any) {
+	if !Enabled() {
+		return
+	}
+	write(fmt.Sprintf("%s %s\n", time.Now().Format(time.RFC3339Nano), fmt.Sprintf(format, args...)))
+}
+
+// write appends to the log, rotating it once it grows past MaxLogSize
+func write(entry string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return
+	}
+	if info, err := os.Stat(logPath); err == nil && info.Size()+int64(len(entry)) > MaxLogSize {
+		os.Rename(logPath, logPath+".1")
+	}
+	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	f.WriteString(entry)
+}
+
+// Trace performs an HTTP round trip via next and logs a redacted trace of it.
+// Its signature matches the Anthropic SDK middleware.
+func Trace(req *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if !Enabled() {
+		return next(req)
+	}
+
+	var reqBody []byte
+	if req.Body != nil {
+		reqBody, _ = io.ReadAll(req.Body)
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+	Logf("HTTP request %s %s\n%s%s", req.Method, req.URL, formatHeaders(req.Header), formatBody(reqBody))
+
+	start := time.Now()
+	resp, err := next(req)
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		Logf("HTTP error %s %s after %s: %v", req.Method, req.URL, elapsed, err)
+		return resp, err
+	}
+
+	respBody, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	if readErr != nil {
+		Logf("HTTP response %s %s: failed to read body: %v", req.Method, req.URL, readErr)
+		return resp, err
+	}
+	Logf("HTTP response %d %s %s after %s\n%s%s", resp.StatusCode, req.Method, req.URL, elapsed, formatHeaders(resp.Header), formatBody(respBody))
+	return resp, err
+}
+
+// Transport is an http.RoundTripper that traces requests when debug is enabled
+type Transport struct {
+	Base http.RoundTripper // nil uses http.DefaultTransport
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return Trace(req, base.RoundTrip)
+}
+
+// formatHeaders renders headers one per line with sensitive values masked
+func formatHeaders(h http.Header) string {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		value := strings.Join(h[name], ", ")
+		if sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+			value = maskValue(value)
+		}
+		fmt.Fprintf(&b, "  %s: %s\n", name, value)
+	}
+	return b.String()
+}
+
+// maskValue keeps a short prefix so traces still show which credential was used
+func maskValue(value string) string {
+	if len(value) <= 12 {
+		return "[REDACTED]"
+	}
+	return value[:8] + "…[REDACTED]"
+}
+
+// formatBody caps and redacts a body for the trace
+func formatBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	text := string(body)
+	suffix := ""
+	if len(text) > MaxBodyBytes {
+		suffix = fmt.Sprintf("\n  ... (%d more bytes)", len(text)-MaxBodyBytes)
+		text = text[:MaxBodyBytes]
+	}
+	text, _ = safety.RedactSecrets(text)
+	return "  " + text + suffix
+}
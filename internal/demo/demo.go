@@ -0,0 +1,301 @@
+// Package demo runs bast without an API key: a canned provider answers a
+// scripted tour of the TUI inside a throwaway sandbox project
+package demo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bastio-ai/bast/internal/ai"
+	"github.com/bastio-ai/bast/internal/files"
+	"github.com/bastio-ai/bast/internal/tools"
+)
+
+// Model is the model name shown in the status bar during the demo
+const Model = "demo (canned responses)"
+
+// Step is one stop of the tour, run as its own TUI session
+type Step struct {
+	Title  string
+	Intro  string    // What the step shows and which keys to try
+	Query  string    // Sent as soon as the TUI opens
+	Intent ai.Intent // Where the query is routed
+}
+
+// Steps is the tour: generating and explaining a command, confirming a
+// dangerous one, and an agent task
+var Steps = []Step{
+	{
+		Title:  "Generate and explain a command",
+		Intro:  "bast turns a request into a command for your shell. In the confirm view, press ? to have it explained, then Enter to accept it or Esc to skip.",
+		Query:  "find the largest files here",
+		Intent: ai.IntentCommand,
+	},
+	{
+		Title:  "Confirm a dangerous command",
+		Intro:  "Commands that delete or overwrite things are flagged before they reach your prompt. Read the warning, press ? to see what it would remove, then type yes to accept or press Esc.",
+		Query:  "clear out the logs folder",
+		Intent: ai.IntentCommand,
+	},
+	{
+		Title:  "Run an agent task",
+		Intro:  "In agent mode bast uses tools to do the work itself, showing each tool call as it goes. When it's done, press Esc to leave.",
+		Query:  "add a .gitignore that keeps the logs out of git",
+		Intent: ai.IntentAgent,
+	},
+}
+
+// errNotInDemo is returned for features the demo has no script for
+var errNotInDemo = errors.New("not part of the demo; run bast init to use bast with your own API key")
+
+// Provider implements ai.Provider with scripted answers to the tour's
+// queries, so the TUI runs end to end without a model
+type Provider struct {
+	// ThinkTime is how long each answer takes, so progress is visible
+	ThinkTime time.Duration
+}
+
+// NewProvider creates a canned provider with a short think time
+func NewProvider() *Provider {
+	return &Provider{ThinkTime: 700 * time.Millisecond}
+}
+
+// think waits ThinkTime or until ctx is done
+func (p *Provider) think(ctx context.Context) error {
+	select {
+	case <-time.After(p.ThinkTime):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// cannedCommand is a scripted command for queries containing all of words
+type cannedCommand struct {
+	words       []string
+	result      ai.CommandResult
+	explanation string
+}
+
+var cannedCommands = []cannedCommand{
+	{
+		words:  []string{"largest"},
+		result: ai.CommandResult{Command: "du -ah . | sort -rh | head -n 10"},
+		explanation: "du -ah . measures every file and directory under this one, in human-readable sizes (-h), files included (-a).\n" +
+			"sort -rh orders the sizes largest first, understanding suffixes like K, M, and G.\n" +
+			"head -n 10 keeps the top ten.\n" +
+			"It only reads, so it's safe to run.",
+	},
+	{
+		words:  []string{"logs"},
+		result: ai.CommandResult{Command: "rm -rf *", WorkingDir: "logs"},
+		explanation: "rm -rf * deletes everything in the logs directory: -r descends into subdirectories and -f skips prompts.\n" +
+			"Nothing goes to the trash, so the files can't be recovered.\n" +
+			"bast runs it in logs/ in a subshell, so it can't reach the rest of the project.",
+	},
+}
+
+// match returns the canned command for query, if there is one
+func match(query string) (cannedCommand, bool) {
+	query = strings.ToLower(query)
+	for _, c := range cannedCommands {
+		matched := true
+		for _, word := range c.words {
+			if !strings.Contains(query, word) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return c, true
+		}
+	}
+	return cannedCommand{}, false
+}
+
+// GenerateCommand returns the scripted command for the tour's queries and
+// a harmless one otherwise
+func (p *Provider) GenerateCommand(ctx context.Context, query string, shellCtx ai.ShellContext) (*ai.CommandResult, error) {
+	if err := p.think(ctx); err != nil {
+		return nil, err
+	}
+	if c, ok := match(query); ok {
+		result := c.result
+		return &result, nil
+	}
+	return &ai.CommandResult{Command: fmt.Sprintf("echo %q", "The demo has no script for that; try: "+Steps[0].Query)}, nil
+}
+
+// ExplainCommand explains the tour's commands
+func (p *Provider) ExplainCommand(ctx context.Context, command string) (string, error) {
+	if err := p.think(ctx); err != nil {
+		return "", err
+	}
+	for _, c := range cannedCommands {
+		if c.result.Command == command {
+			return c.explanation, nil
+		}
+	}
+	return "The demo can only explain the commands it generates. With your own API key, bast explains any command.", nil
+}
+
+// CompareCommands describes an edit without a model
+func (p *Provider) CompareCommands(ctx context.Context, before, after string, riskChanges []string) (string, error) {
+	if err := p.think(ctx); err != nil {
+		return "", err
+	}
+	if len(riskChanges) > 0 {
+		return "The edited command is riskier: " + strings.Join(riskChanges, "; "), nil
+	}
+	return "The demo can't compare commands in detail. With your own API key, bast explains what an edit changes.", nil
+}
+
+// ClassifyIntent routes agent-like requests to the agent and everything
+// else to command generation
+func (p *Provider) ClassifyIntent(ctx context.Context, query string) (*ai.IntentResult, error) {
+	if strings.Contains(strings.ToLower(query), ".gitignore") {
+		return &ai.IntentResult{Intent: ai.IntentAgent, Confidence: 0.95}, nil
+	}
+	if strings.HasSuffix(strings.TrimSpace(query), "?") {
+		return &ai.IntentResult{Intent: ai.IntentChat, Confidence: 0.95}, nil
+	}
+	return &ai.IntentResult{Intent: ai.IntentCommand, Confidence: 0.95}, nil
+}
+
+// Chat answers with a pointer back to the tour
+func (p *Provider) Chat(ctx context.Context, query string, shellCtx ai.ShellContext, chatCtx ai.ChatContext) (*ai.ChatResult, error) {
+	if err := p.think(ctx); err != nil {
+		return nil, err
+	}
+	return &ai.ChatResult{
+		Response: "This is the bast demo, so answers are canned. With your own API key, bast answers questions about your shell, " +
+			"your project, and command output, with the files you @mention attached.",
+		FollowUps: []string{Steps[0].Query, Steps[2].Query},
+	}, nil
+}
+
+// agentCall is one scripted agent step: the text before a tool call and
+// the call itself
+type agentCall struct {
+	text  string
+	tool  string
+	input map[string]string
+}
+
+// gitignoreTask is the scripted agent task
+var gitignoreTask = []agentCall{
+	{"I'll look at what's in the project first.", "list_directory", map[string]string{"path": "."}},
+	{"There's a logs directory and no .gitignore yet. I'll add one that ignores it and any stray log files.", "write_file",
+		map[string]string{"path": ".gitignore", "content": "# Local logs\nlogs/\n*.log\n"}},
+	{"Let me check the result.", "read_file", map[string]string{"path": ".gitignore"}},
+}
+
+// RunAgent performs the scripted .gitignore task with the real tools, so
+// tool calls go through the registry's security checks and show up in the
+// TUI like a live run
+func (p *Provider) RunAgent(ctx context.Context, query string, shellCtx ai.ShellContext, chatCtx ai.ChatContext, cfg ai.AgentConfig) (*ai.AgentResult, error) {
+	result := &ai.AgentResult{}
+	if !strings.Contains(strings.ToLower(query), "gitignore") {
+		if err := p.think(ctx); err != nil {
+			return nil, err
+		}
+		result.Iterations = 1
+		result.Response = "The demo's agent only knows one task. Try: " + Steps[2].Query
+		return result, nil
+	}
+	if cfg.Registry == nil {
+		return nil, errors.New("no tool registry")
+	}
+
+	for i, step := range gitignoreTask {
+		if err := p.think(ctx); err != nil {
+			return nil, err
+		}
+		input, err := json.Marshal(step.input)
+		if err != nil {
+			return nil, err
+		}
+		call := ai.ToolCall{ID: fmt.Sprintf("toolu_demo_%d", i+1), Name: step.tool, Input: input}
+		result.Iterations++
+		if cfg.OnStep != nil {
+			cfg.OnStep(ai.AgentStep{Iteration: result.Iterations, Text: step.text, ToolUses: 1})
+		}
+		callResult := cfg.Registry.ExecuteCall(ctx, tools.Call{ID: call.ID, Name: call.Name, Input: call.Input})
+		call.Output = callResult.Content
+		call.IsError = callResult.IsError
+		call.ExitCode = callResult.ExitCode
+		call.Duration = callResult.Duration
+		call.Bytes = callResult.Bytes
+		call.Warnings = callResult.Warnings
+		call.Threats = callResult.Threats
+		call.Verdicts = callResult.Verdicts
+		result.ToolCalls = append(result.ToolCalls, call)
+		if cfg.OnToolCall != nil {
+			cfg.OnToolCall(call)
+		}
+		if call.IsError {
+			result.Status = ai.AgentStatusFailed
+			result.Summary = "Couldn't write the .gitignore"
+			result.Response = fmt.Sprintf("The %s call failed: %s", call.Name, call.Output)
+			return result, nil
+		}
+	}
+
+	if err := p.think(ctx); err != nil {
+		return nil, err
+	}
+	result.Iterations++
+	result.Status = ai.AgentStatusSuccess
+	result.Summary = "Added a .gitignore that ignores logs/ and *.log"
+	result.Response = "Added `.gitignore` with `logs/` and `*.log`, so the log files stay out of git. " +
+		"Existing files that are already tracked would still need `git rm --cached`."
+	result.FollowUps = []string{Steps[0].Query}
+	if cfg.OnStep != nil {
+		cfg.OnStep(ai.AgentStep{Iteration: result.Iterations, Text: result.Response})
+	}
+	return result, nil
+}
+
+// FixCommand is not part of the demo
+func (p *Provider) FixCommand(ctx context.Context, failedCmd string, errorOutput string, frames []files.FileContent, shellCtx ai.ShellContext) (*ai.FixResult, error) {
+	return nil, errNotInDemo
+}
+
+// ExplainOutput is not part of the demo
+func (p *Provider) ExplainOutput(ctx context.Context, output, digest, prompt string, shellCtx ai.ShellContext) (*ai.ChatResult, error) {
+	return nil, errNotInDemo
+}
+
+// ExplainFile is not part of the demo
+func (p *Provider) ExplainFile(ctx context.Context, file files.FileContent, prompt string, shellCtx ai.ShellContext) (*ai.ChatResult, error) {
+	return nil, errNotInDemo
+}
+
+// SummarizeFile is not part of the demo
+func (p *Provider) SummarizeFile(ctx context.Context, file files.FileContent, shellCtx ai.ShellContext) (*ai.ChatResult, error) {
+	return nil, errNotInDemo
+}
+
+// SummarizeDir is not part of the demo
+func (p *Provider) SummarizeDir(ctx context.Context, survey *files.DirSurvey, shellCtx ai.ShellContext) (*ai.ChatResult, error) {
+	return nil, errNotInDemo
+}
+
+// GenerateChangelog is not part of the demo
+func (p *Provider) GenerateChangelog(ctx context.Context, commits []ai.ChangelogCommit) (string, error) {
+	return "", errNotInDemo
+}
+
+// ReviewDiff is not part of the demo
+func (p *Provider) ReviewDiff(ctx context.Context, diff string) (string, error) {
+	return "", errNotInDemo
+}
+
+// SetModel does nothing; the demo has one model
+func (p *Provider) SetModel(model string) {}
+
+var _ ai.Provider = (*Provider)(nil)
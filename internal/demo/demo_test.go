@@ -0,0 +1,67 @@
+package demo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bastio-ai/bast/internal/ai"
+	"github.com/bastio-ai/bast/internal/safety"
+	"github.com/bastio-ai/bast/internal/tools"
+)
+
+func TestCommandSteps(t *testing.T) {
+	p := &Provider{}
+	ctx := context.Background()
+
+	largest, err := p.GenerateCommand(ctx, Steps[0].Query, ai.ShellContext{})
+	if err != nil || largest.Command != "du -ah . | sort -rh | head -n 10" {
+		t.Fatalf("GenerateCommand(%q) = %+v, %v", Steps[0].Query, largest, err)
+	}
+	if explanation, _ := p.ExplainCommand(ctx, largest.Command); explanation != cannedCommands[0].explanation {
+		t.Errorf("ExplainCommand() = %q, want the canned explanation", explanation)
+	}
+
+	clear, err := p.GenerateCommand(ctx, Steps[1].Query, ai.ShellContext{})
+	if err != nil || clear.WorkingDir != "logs" || !safety.IsDangerousCommand(clear.Command) {
+		t.Errorf("GenerateCommand(%q) = %+v, %v; want a dangerous command in logs", Steps[1].Query, clear, err)
+	}
+
+	if intent, _ := p.ClassifyIntent(ctx, Steps[2].Query); intent.Intent != ai.IntentAgent {
+		t.Errorf("ClassifyIntent(%q) = %s, want agent", Steps[2].Query, intent.Intent)
+	}
+}
+
+func TestRunAgentInSandbox(t *testing.T) {
+	root, project, err := NewSandbox()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	t.Chdir(project)
+
+	registry := tools.NewRegistry()
+	tools.RegisterBuiltins(registry, project)
+	var calls []ai.ToolCall
+	var steps int
+	cfg := ai.AgentConfig{
+		Registry:   registry,
+		OnToolCall: func(call ai.ToolCall) { calls = append(calls, call) },
+		OnStep:     func(ai.AgentStep) { steps++ },
+	}
+	result, err := (&Provider{}).RunAgent(context.Background(), Steps[2].Query, ai.ShellContext{CWD: project}, ai.ChatContext{}, cfg)
+	if err != nil {
+		t.Fatalf("RunAgent() error = %v", err)
+	}
+	if result.Status != ai.AgentStatusSuccess || len(calls) != 3 || steps != 4 || result.Iterations != 4 {
+		t.Fatalf("RunAgent() = %+v with %d calls, %d steps", result, len(calls), steps)
+	}
+	data, err := os.ReadFile(filepath.Join(project, ".gitignore"))
+	if err != nil || string(data) != "# Local logs\nlogs/\n*.log\n" {
+		t.Errorf(".gitignore = %q, %v", data, err)
+	}
+	if calls[2].Output != string(data) {
+		t.Errorf("read_file output = %q, want the written .gitignore", calls[2].Output)
+	}
+}
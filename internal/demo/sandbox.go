@@ -0,0 +1,86 @@
+package demo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// sandboxFiles is the demo project, by path relative to its root
+var sandboxFiles = map[string]string{
+	"README.md": "# demo-app\n\nA tiny web service used by the bast demo. Nothing here is real.\n",
+	"go.mod":    "module example.com/demo-app\n\ngo 1.24\n",
+	"main.go": `package main
+
+import (
+	"fmt"
+	"net/http"
+)
+
+func main() {
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "hello from demo-app")
+	})
+	http.ListenAndServe(":8080", nil)
+}
+`,
+	"scripts/deploy.sh": "#!/bin/sh\nset -e\necho \"deploying demo-app (not really)\"\n",
+}
+
+// sandboxLogs are generated log files and their sizes, so "largest files"
+// has something to find
+var sandboxLogs = map[string]int{
+	"logs/app-2026-10-13.log": 180 * 1024,
+	"logs/app-2026-10-14.log": 420 * 1024,
+	"logs/app-2026-10-15.log": 64 * 1024,
+	"logs/build.log":          900 * 1024,
+}
+
+// NewSandbox creates the demo project in a new temporary directory. It
+// returns the directory holding it and the project inside; remove the
+// former when done. The holding directory is free for files the demo
+// itself needs, like the handoff file, so the agent doesn't see them.
+func NewSandbox() (root, project string, err error) {
+	root, err = os.MkdirTemp("", "bast-demo-")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create demo sandbox: %w", err)
+	}
+	project = filepath.Join(root, "demo-app")
+	if err := writeSandbox(project); err != nil {
+		os.RemoveAll(root)
+		return "", "", err
+	}
+	return root, project, nil
+}
+
+// writeSandbox writes the demo project to dir
+func writeSandbox(dir string) error {
+	write := func(path string, data []byte, perm os.FileMode) error {
+		full := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			return fmt.Errorf("failed to create demo sandbox: %w", err)
+		}
+		if err := os.WriteFile(full, data, perm); err != nil {
+			return fmt.Errorf("failed to create demo sandbox: %w", err)
+		}
+		return nil
+	}
+	for path, content := range sandboxFiles {
+		perm := os.FileMode(0644)
+		if strings.HasSuffix(path, ".sh") {
+			perm = 0755
+		}
+		if err := write(path, []byte(content), perm); err != nil {
+			return err
+		}
+	}
+	for path, size := range sandboxLogs {
+		line := "2026-10-15T09:00:00Z INFO request served path=/ status=200\n"
+		content := strings.Repeat(line, size/len(line)+1)[:size]
+		if err := write(path, []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+// Package direnv detects .envrc files and their direnv allow status
+package direnv
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Status is whether direnv will load an .envrc
+type Status string
+
+const (
+	StatusAllowed    Status = "allowed"     // direnv loads the .envrc
+	StatusNotAllowed Status = "not allowed" // The .envrc is new or changed and needs `direnv allow`
+	StatusDenied     Status = "denied"      // The user ran `direnv deny`
+	StatusUnknown    Status = "unknown"     // direnv is not installed or its status could not be read
+)
+
+// statusTimeout bounds the `direnv status` call
+const statusTimeout = 300 * time.Millisecond
+
+// Context describes the .envrc that applies to a directory
+type Context struct {
+	Path      string   // Path to the .envrc
+	Status    Status   // Whether direnv loads it
+	Loaded    bool     // True if the current shell has it loaded
+	Variables []string // Names of exported variables (never values)
+	Uses      []string // Toolchain directives, e.g. "use nix" or "layout python3"
+}
+
+var (
+	exportRe    = regexp.MustCompile(`^(?:export\s+)?([A-Za-z_][A-Za-z0-9_]*)=`)
+	directiveRe = regexp.MustCompile(`^(use|layout)\s+(.+)$`)
+)
+
+// GetContext finds the .envrc that applies to cwd, searching parent
+// directories the way direnv does. Returns nil if there is none.
+func GetContext(cwd string) *Context {
+	path := findEnvrc(cwd)
+	if path == "" {
+		return nil
+	}
+
+	ctx := &Context{Path: path, Status: status(cwd)}
+	ctx.Loaded = os.Getenv("DIRENV_DIR") == "-"+filepath.Dir(path)
+	ctx.Variables, ctx.Uses = parseEnvrc(path)
+	return ctx
+}
+
+// findEnvrc returns the nearest .envrc at or above dir
+func findEnvrc(dir string) string {
+	for {
+		path := filepath.Join(dir, ".envrc")
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// status asks direnv whether the .envrc for cwd is allowed
+func status(cwd string) Status {
+	if _, err := exec.LookPath("direnv"); err != nil {
+		return StatusUnknown
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), statusTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "direnv", "status")
+	cmd.Dir = cwd
+	out, err := cmd.Output()
+	if err != nil {
+		return StatusUnknown
+	}
+	return parseStatus(string(out))
+}
+
+// parseStatus reads the "Found RC allowed" line of `direnv status`. Older
+// versions print true/false, newer ones 0 (allowed), 1 (not allowed), or 2 (denied).
+func parseStatus(out string) Status {
+	for _, line := range strings.Split(out, "\n") {
+		value, ok := strings.CutPrefix(strings.TrimSpace(line), "Found RC allowed ")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(value) {
+		case "true", "0":
+			return StatusAllowed
+		case "false", "1":
+			return StatusNotAllowed
+		case "2":
+			return StatusDenied
+		}
+	}
+	return StatusUnknown
+}
+
+// parseEnvrc returns the variable names exported by an .envrc and its
+// use/layout directives. Values are never read into the result.
+func parseEnvrc(path string) (variables, uses []string) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil
+	}
+	defer f.Close()
+
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			variables = append(variables, name)
+		}
+	}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		switch {
+		case exportRe.MatchString(line):
+			add(exportRe.FindStringSubmatch(line)[1])
+		case strings.HasPrefix(line, "PATH_add ") || strings.HasPrefix(line, "path_add "):
+			add("PATH")
+		case strings.HasPrefix(line, "dotenv"):
+			uses = append(uses, line)
+		case directiveRe.MatchString(line):
+			m := directiveRe.FindStringSubmatch(line)
+			uses = append(uses, m[1]+" "+strings.TrimSpace(m[2]))
+		}
+	}
+	return variables, uses
+}
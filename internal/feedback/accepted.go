@@ -0,0 +1,96 @@
+package feedback
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bastio-ai/bast/internal/safety"
+)
+
+// AcceptedFileName is the file that stores accepted generations, one JSON entry per line
+const AcceptedFileName = "accepted.jsonl"
+
+// Accepted is a generated command the user ran, with the query that produced it
+type Accepted struct {
+	Time    time.Time `json:"time"`
+	Project string    `json:"project"` // Repository root, or the directory outside a repository
+	Query   string    `json:"query"`
+	Command string    `json:"command"` // As run, including the user's edits
+}
+
+// DefaultAcceptedPath returns the default path of the accepted generations file
+func DefaultAcceptedPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", "bast", AcceptedFileName), nil
+}
+
+// RecordAccepted adds an accepted generation to the file at path.
+// Credentials in the query and command are redacted first: the file is
+// read back into prompts as examples.
+func RecordAccepted(path string, a Accepted) error {
+	a.Query, _ = safety.RedactSecrets(a.Query)
+	a.Command, _ = safety.RedactSecrets(a.Command)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	data, err := json.Marshal(a)
+	if err != nil {
+		return fmt.Errorf("failed to marshal accepted command: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open accepted commands file: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write accepted command: %w", err)
+	}
+	return nil
+}
+
+// LoadAccepted reads the most recent accepted generations for project from
+// path, oldest first. A missing file yields none; malformed lines are skipped.
+func LoadAccepted(path, project string) []Accepted {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var accepted []Accepted
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var a Accepted
+		if json.Unmarshal(scanner.Bytes(), &a) != nil || a.Command == "" || a.Project != project {
+			continue
+		}
+		accepted = append(accepted, a)
+	}
+	if len(accepted) > maxEntries {
+		accepted = accepted[len(accepted)-maxEntries:]
+	}
+	return accepted
+}
+
+// ProjectRoot returns the repository root containing dir, or dir itself
+// when it is not inside a repository
+func ProjectRoot(dir string) string {
+	for d := dir; ; {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			return d
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return dir
+		}
+		d = parent
+	}
+}
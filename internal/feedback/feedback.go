@@ -0,0 +1,203 @@
+// Package feedback records the user's ratings of generated commands and the
+// commands they accept, and picks past generations as examples for similar queries
+package feedback
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// FileName is the file that stores feedback, one JSON entry per line
+const FileName = "feedback.jsonl"
+
+// maxEntries bounds how many of the most recent entries are loaded
+const maxEntries = 1000
+
+// minSimilarity is the word overlap a past query needs to count as similar
+const minSimilarity = 0.3
+
+// Rating is the user's verdict on a generated command
+type Rating string
+
+const (
+	RatingGood Rating = "good" // The command did what was asked
+	RatingBad  Rating = "bad"  // The command was wrong
+	RatingNote Rating = "note" // Free-text feedback without a verdict
+)
+
+// Entry is one piece of feedback on a generated command
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Query   string    `json:"query"`
+	Command string    `json:"command"`
+	Dir     string    `json:"dir,omitempty"` // Never sent off the machine
+	Rating  Rating    `json:"rating"`
+	Comment string    `json:"comment,omitempty"`
+}
+
+// DefaultPath returns the default path of the feedback file
+func DefaultPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", "bast", FileName), nil
+}
+
+// Append adds an entry to the feedback file at path
+func Append(path string, e Entry) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal feedback: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open feedback file: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write feedback: %w", err)
+	}
+	return nil
+}
+
+// Load reads the most recent entries from path, oldest first.
+// A missing file yields no entries; malformed lines are skipped.
+func Load(path string) []Entry {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if json.Unmarshal(scanner.Bytes(), &e) != nil || e.Command == "" {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) > maxEntries {
+		entries = entries[len(entries)-maxEntries:]
+	}
+	return entries
+}
+
+// Example is a past query and the command the user settled on for it
+type Example struct {
+	Time    time.Time
+	Query   string
+	Command string
+}
+
+// Examples returns up to k past generations similar to query, most similar
+// first: commands accepted in the same project and commands rated good
+// anywhere. A command the user rated bad for the same query is never
+// returned, even if it was accepted before.
+func Examples(entries []Entry, accepted []Accepted, project, query string, k int) []Example {
+	// The latest verdict on a query/command pair wins
+	verdicts := make(map[string]Rating)
+	for _, e := range entries {
+		if e.Rating == RatingGood || e.Rating == RatingBad {
+			verdicts[pairKey(e.Query, e.Command)] = e.Rating
+		}
+	}
+
+	var pool []Example
+	for _, e := range entries {
+		if e.Rating == RatingGood {
+			pool = append(pool, Example{Time: e.Time, Query: e.Query, Command: e.Command})
+		}
+	}
+	for _, a := range accepted {
+		if a.Project == project {
+			pool = append(pool, Example{Time: a.Time, Query: a.Query, Command: a.Command})
+		}
+	}
+
+	type scored struct {
+		example Example
+		score   float64
+	}
+	words := queryWords(query)
+	best := make(map[string]int) // pair key → index in candidates
+	var candidates []scored
+	for _, ex := range pool {
+		key := pairKey(ex.Query, ex.Command)
+		if verdicts[key] == RatingBad {
+			continue
+		}
+		score := similarity(words, queryWords(ex.Query))
+		if score < minSimilarity {
+			continue
+		}
+		// Keep one copy of each pair, the most recent
+		if i, seen := best[key]; seen {
+			if ex.Time.After(candidates[i].example.Time) {
+				candidates[i].example = ex
+			}
+			continue
+		}
+		best[key] = len(candidates)
+		candidates = append(candidates, scored{ex, score})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].example.Time.After(candidates[j].example.Time)
+	})
+
+	var examples []Example
+	for _, c := range candidates {
+		if len(examples) == k {
+			break
+		}
+		examples = append(examples, c.example)
+	}
+	return examples
+}
+
+// pairKey identifies a query/command pair regardless of query case
+func pairKey(query, command string) string {
+	return strings.ToLower(strings.TrimSpace(query)) + "\x00" + command
+}
+
+// queryWords returns the distinct lowercase words of a query
+func queryWords(query string) map[string]bool {
+	words := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.' && r != '-'
+	}) {
+		words[w] = true
+	}
+	return words
+}
+
+// similarity is the Jaccard overlap of two word sets
+func similarity(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for w := range a {
+		if b[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
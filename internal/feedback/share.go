@@ -0,0 +1,55 @@
+package feedback
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bastio-ai/bast/internal/debug"
+	"github.com/bastio-ai/bast/internal/useragent"
+)
+
+var shareClient = &http.Client{
+	Timeout:   10 * time.Second,
+	Transport: &debug.Transport{Base: http.DefaultTransport},
+}
+
+// shareRequest is the payload sent to Bastio. The working directory stays local.
+type shareRequest struct {
+	Query   string `json:"query"`
+	Command string `json:"command"`
+	Rating  Rating `json:"rating"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// Share sends an entry to Bastio's feedback endpoint for the user's proxy.
+// Only called for users who opted in with feedback.share.
+func Share(ctx context.Context, baseURL, proxyID, apiKey, deviceID string, e Entry) error {
+	body, err := json.Marshal(shareRequest{Query: e.Query, Command: e.Command, Rating: e.Rating, Comment: e.Comment})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/v1/guard/%s/feedback", baseURL, proxyID)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("User-Agent", useragent.String(deviceID))
+
+	resp, err := shareClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("feedback rejected: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package files
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bastio-ai/bast/internal/perf"
+)
+
+// benchQuery is a long query with mentions and file-like words, as typed
+var benchQuery = strings.Repeat("compare @src/main.go with the config in deploy/app.yaml and the readme ", 20)
+
+func BenchmarkParseMentions(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		ParseMentions(benchQuery)
+	}
+}
+
+func BenchmarkStripMentions(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		StripMentions(benchQuery)
+	}
+}
+
+func BenchmarkDetectFileReferences(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		DetectFileReferences(benchQuery)
+	}
+}
+
+func TestPerfBudgets(t *testing.T) {
+	perf.Check(t, []perf.Budget{
+		{Name: "ParseMentions", Bench: BenchmarkParseMentions, Max: 250 * time.Microsecond},
+		{Name: "StripMentions", Bench: BenchmarkStripMentions, Max: 250 * time.Microsecond},
+		{Name: "DetectFileReferences", Bench: BenchmarkDetectFileReferences, Max: 500 * time.Microsecond},
+	})
+}
@@ -0,0 +1,62 @@
+package files
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// Citation is a reference from a chat answer to lines of an included file,
+// written by the model as [path:start-end] or [path:line]
+type Citation struct {
+	Raw   string // The marker as it appears in the answer, brackets included
+	Path  string
+	Start int
+	End   int // Equal to Start for a single line
+}
+
+var citationRe = regexp.MustCompile(`\[([^\[\]\s:]+):(\d+)(?:-(\d+))?\]`)
+
+// ParseCitations returns the distinct citation markers in text, in order
+func ParseCitations(text string) []Citation {
+	var citations []Citation
+	seen := make(map[string]bool)
+	for _, m := range citationRe.FindAllStringSubmatch(text, -1) {
+		if seen[m[0]] {
+			continue
+		}
+		start, err := strconv.Atoi(m[2])
+		if err != nil || start == 0 {
+			continue
+		}
+		end := start
+		if m[3] != "" {
+			if end, err = strconv.Atoi(m[3]); err != nil || end < start {
+				continue
+			}
+		}
+		seen[m[0]] = true
+		citations = append(citations, Citation{Raw: m[0], Path: m[1], Start: start, End: end})
+	}
+	return citations
+}
+
+// NumberLines prefixes each line of content with its 1-based line number,
+// so a model can cite line ranges
+func NumberLines(content string) string {
+	var out []byte
+	line := 1
+	atStart := true
+	for i := 0; i < len(content); i++ {
+		if atStart {
+			out = strconv.AppendInt(out, int64(line), 10)
+			out = append(out, ':', ' ')
+			atStart = false
+		}
+		out = append(out, content[i])
+		if content[i] == '\n' {
+			line++
+			atStart = true
+		}
+	}
+	return string(out)
+}
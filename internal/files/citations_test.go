@@ -0,0 +1,27 @@
+package files
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCitations(t *testing.T) {
+	text := "Retries are capped [internal/ai/errors.go:40-52], see also [main.go:7]. " +
+		"Repeated [main.go:7], invalid [x.go:9-3], [notes: 1-2], and [link](http://example.com:80)."
+	want := []Citation{
+		{Raw: "[internal/ai/errors.go:40-52]", Path: "internal/ai/errors.go", Start: 40, End: 52},
+		{Raw: "[main.go:7]", Path: "main.go", Start: 7, End: 7},
+	}
+	if got := ParseCitations(text); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseCitations() = %+v, want %+v", got, want)
+	}
+}
+
+func TestNumberLines(t *testing.T) {
+	if got, want := NumberLines("a\nb\n\nc"), "1: a\n2: b\n3: \n4: c"; got != want {
+		t.Errorf("NumberLines() = %q, want %q", got, want)
+	}
+	if got := NumberLines(""); got != "" {
+		t.Errorf("NumberLines(\"\") = %q, want empty", got)
+	}
+}
@@ -1,5 +1,7 @@
 package files
 
+import "time"
+
 const (
 	// MaxSingleFileBytes is the maximum size of a single file that can be read (50KB)
 	MaxSingleFileBytes = 50 * 1024
@@ -7,9 +9,22 @@ const (
 	// MaxTotalFileBytes is the maximum total bytes to read across all files (100KB)
 	MaxTotalFileBytes = 100 * 1024
 
+	// MaxReadWorkers is how many files ReadFiles reads at once
+	MaxReadWorkers = 8
+
 	// MaxSearchDepth is the maximum directory depth for file searches
 	MaxSearchDepth = 5
 
 	// MaxSuggestions is the default maximum number of file suggestions
 	MaxSuggestions = 10
+
+	// MaxIndexFiles is the most files a suggestion index holds; larger
+	// trees fall back to live search
+	MaxIndexFiles = 50000
+
+	// IndexBuildTimeout bounds how long building a suggestion index may take
+	IndexBuildTimeout = 3 * time.Second
+
+	// IndexDirName is the directory under ~/.config/bast for suggestion indexes
+	IndexDirName = "index"
 )
@@ -0,0 +1,157 @@
+package files
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// MaxFailureFiles is the maximum number of files attached from test failures
+const MaxFailureFiles = 5
+
+// TestFailure is a failing test parsed from test runner output
+type TestFailure struct {
+	Runner string // "go", "pytest", or "jest"
+	Test   string // Failing test name (may be empty if only the file is known)
+	File   string // File reported by the runner (may be a bare file name)
+}
+
+var (
+	// go test: "--- FAIL: TestName (0.00s)" and "    name_test.go:42: message"
+	goFailRe     = regexp.MustCompile(`(?m)^\s*--- FAIL: (\S+)`)
+	goFileLineRe = regexp.MustCompile(`(?m)^\s+(\S+_test\.go):\d+:`)
+	goPackageRe  = regexp.MustCompile(`(?m)^FAIL\s+(\S+)\s+[\d.]+s\s*$`)
+
+	// pytest: "FAILED tests/test_x.py::test_name - AssertionError"
+	pytestFailRe = regexp.MustCompile(`(?m)^FAILED (\S+\.py)::(\S+)`)
+
+	// jest: "FAIL src/x.test.js" followed by "● Suite › test name"
+	jestFileRe = regexp.MustCompile(`(?m)^\s*FAIL\s+(\S+\.[jt]sx?)\s*$`)
+	jestTestRe = regexp.MustCompile(`(?m)^\s*● (.+)$`)
+)
+
+// ParseTestFailures extracts failing tests from go test, pytest, and jest output
+func ParseTestFailures(output string) []TestFailure {
+	var failures []TestFailure
+
+	// go test reports test names and file:line separately, so pair them in order
+	goTests := goFailRe.FindAllStringSubmatch(output, -1)
+	goFiles := goFileLineRe.FindAllStringSubmatch(output, -1)
+	for i, m := range goTests {
+		f := TestFailure{Runner: "go", Test: m[1]}
+		if i < len(goFiles) {
+			f.File = goFiles[i][1]
+		}
+		failures = append(failures, f)
+	}
+	for i := len(goTests); i < len(goFiles); i++ {
+		failures = append(failures, TestFailure{Runner: "go", File: goFiles[i][1]})
+	}
+
+	for _, m := range pytestFailRe.FindAllStringSubmatch(output, -1) {
+		failures = append(failures, TestFailure{Runner: "pytest", Test: m[2], File: m[1]})
+	}
+
+	jestFiles := jestFileRe.FindAllStringSubmatch(output, -1)
+	if len(jestFiles) > 0 {
+		jestTests := jestTestRe.FindAllStringSubmatch(output, -1)
+		for i, m := range jestFiles {
+			f := TestFailure{Runner: "jest", File: m[1]}
+			if len(jestFiles) == 1 && i < len(jestTests) {
+				f.Test = strings.TrimSpace(jestTests[i][1])
+			}
+			failures = append(failures, f)
+		}
+	}
+
+	return failures
+}
+
+// FailingTestFiles resolves the files named in test failures to paths
+// relative to cwd. go test reports bare file names relative to the package,
+// so those are located by searching cwd, preferring failing packages.
+// Returns at most MaxFailureFiles unique paths.
+func FailingTestFiles(cwd string, output string) []string {
+	failures := ParseTestFailures(output)
+	if len(failures) == 0 {
+		return nil
+	}
+
+	var packages []string
+	for _, m := range goPackageRe.FindAllStringSubmatch(output, -1) {
+		packages = append(packages, m[1])
+	}
+
+	seen := make(map[string]bool)
+	var paths []string
+	for _, f := range failures {
+		if f.File == "" {
+			continue
+		}
+		path := resolveFailureFile(cwd, f.File, packages)
+		if path == "" || seen[path] {
+			continue
+		}
+		seen[path] = true
+		paths = append(paths, path)
+		if len(paths) == MaxFailureFiles {
+			break
+		}
+	}
+	return paths
+}
+
+// resolveFailureFile finds name under cwd, returning "" if it does not exist
+func resolveFailureFile(cwd, name string, packages []string) string {
+	if filepath.IsAbs(name) {
+		rel, err := filepath.Rel(cwd, name)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return ""
+		}
+		name = rel
+	}
+	if info, err := os.Stat(filepath.Join(cwd, name)); err == nil && !info.IsDir() {
+		return filepath.Clean(name)
+	}
+	if strings.ContainsRune(name, filepath.Separator) {
+		return ""
+	}
+
+	// Bare file name: search the tree for it
+	var matches []string
+	filepath.WalkDir(cwd, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(cwd, path)
+		if err != nil || rel == "." {
+			return nil
+		}
+		if d.IsDir() {
+			if skippedDirs[d.Name()] || strings.Count(rel, string(filepath.Separator)) >= MaxSearchDepth {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.Name() == name {
+			matches = append(matches, rel)
+		}
+		return nil
+	})
+
+	// Prefer a match in one of the failing packages
+	for _, match := range matches {
+		dir := filepath.ToSlash(filepath.Dir(match))
+		for _, pkg := range packages {
+			if strings.HasSuffix(pkg, "/"+dir) || pkg == dir {
+				return match
+			}
+		}
+	}
+	if len(matches) > 0 {
+		return matches[0]
+	}
+	return ""
+}
@@ -0,0 +1,60 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseTestFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected []TestFailure
+	}{
+		{"no failures", "ok  \tgithub.com/x/y\t0.01s\n", nil},
+		{
+			"go test",
+			"--- FAIL: TestParse (0.00s)\n    parse_test.go:42: got 1, want 2\nFAIL\nFAIL\tgithub.com/x/y/parse\t0.01s\n",
+			[]TestFailure{{Runner: "go", Test: "TestParse", File: "parse_test.go"}},
+		},
+		{
+			"pytest",
+			"=== short test summary info ===\nFAILED tests/test_api.py::test_login - AssertionError: 401\n",
+			[]TestFailure{{Runner: "pytest", Test: "test_login", File: "tests/test_api.py"}},
+		},
+		{
+			"jest",
+			"FAIL src/sum.test.js\n  ● sum › adds numbers\n\n    expect(received).toBe(expected)\n",
+			[]TestFailure{{Runner: "jest", Test: "sum › adds numbers", File: "src/sum.test.js"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseTestFailures(tt.output)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ParseTestFailures() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFailingTestFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, p := range []string{"a/parse_test.go", "b/parse_test.go", "tests/test_api.py"} {
+		os.MkdirAll(filepath.Join(dir, filepath.Dir(p)), 0755)
+		os.WriteFile(filepath.Join(dir, p), []byte("x"), 0644)
+	}
+
+	output := "--- FAIL: TestParse (0.00s)\n    parse_test.go:42: boom\nFAIL\tgithub.com/x/y/b\t0.01s\n" +
+		"FAILED tests/test_api.py::test_login - AssertionError\n" +
+		"FAILED tests/missing.py::test_gone - AssertionError\n"
+
+	got := FailingTestFiles(dir, output)
+	want := []string{filepath.Join("b", "parse_test.go"), filepath.Join("tests", "test_api.py")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FailingTestFiles() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,26 @@
+package files
+
+import (
+	"strings"
+	"testing"
+)
+
+func FuzzParseMentions(f *testing.F) {
+	for _, seed := range []string{
+		"summarize @readme.md and @src/main.go",
+		`explain @"file with spaces.txt"`,
+		"mail user@example.com",
+		"@", `@""`, `@"unterminated`, "@@@", "trailing @",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, query string) {
+		for _, mention := range ParseMentions(query) {
+			if mention == "" || !strings.Contains(query, mention) {
+				t.Errorf("ParseMentions(%q) returned %q", query, mention)
+			}
+		}
+		StripMentions(query)
+		DetectFileReferences(query)
+	})
+}
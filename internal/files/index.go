@@ -0,0 +1,149 @@
+package files
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Index is a snapshot of the files ListFiles would search under a
+// directory, so @mention suggestions don't walk the tree on every keystroke.
+// It is invalidated by the modification times of the directories it saw,
+// which change whenever an entry is added, removed, or renamed.
+type Index struct {
+	Root  string           `json:"root"`
+	Files []string         `json:"files"` // Relative paths, sorted
+	Dirs  map[string]int64 `json:"dirs"`  // Relative directory -> mtime in nanoseconds
+
+	// Complete is false when the build hit MaxIndexFiles or
+	// IndexBuildTimeout, in which case the index can't answer searches
+	Complete bool `json:"complete"`
+}
+
+// BuildIndex walks root like ListFiles, stopping at MaxIndexFiles files or
+// after IndexBuildTimeout
+func BuildIndex(root string) *Index {
+	ix := &Index{Root: root, Dirs: map[string]int64{}, Complete: true}
+	if info, err := os.Stat(root); err == nil {
+		ix.Dirs["."] = info.ModTime().UnixNano()
+	}
+
+	deadline := time.Now().Add(IndexBuildTimeout)
+	walkSuggestable(root, func(relPath string, d fs.DirEntry) error {
+		if len(ix.Files) >= MaxIndexFiles || time.Now().After(deadline) {
+			ix.Complete = false
+			return fs.SkipAll
+		}
+		if d.IsDir() {
+			if info, err := d.Info(); err == nil {
+				ix.Dirs[relPath] = info.ModTime().UnixNano()
+			}
+			return nil
+		}
+		ix.Files = append(ix.Files, relPath)
+		return nil
+	})
+
+	sort.Strings(ix.Files)
+	return ix
+}
+
+// Usable reports whether the index can answer searches under cwd: it was
+// built there, is complete, and no directory in it has changed since
+func (ix *Index) Usable(cwd string) bool {
+	if ix == nil || !ix.Complete || ix.Root != cwd {
+		return false
+	}
+	for dir, mtime := range ix.Dirs {
+		info, err := os.Stat(filepath.Join(ix.Root, dir))
+		if err != nil || info.ModTime().UnixNano() != mtime {
+			return false
+		}
+	}
+	return true
+}
+
+// Search returns the indexed files matching prefix, as ListFiles would
+func (ix *Index) Search(prefix string, maxResults int) []string {
+	prefix = strings.ToLower(prefix)
+
+	var matches []string
+	for _, relPath := range ix.Files {
+		if len(matches) == maxResults {
+			break
+		}
+		if matchesPrefix(relPath, prefix) {
+			matches = append(matches, relPath)
+		}
+	}
+	return matches
+}
+
+// IndexPath returns where the index for root is kept, one file per directory
+func IndexPath(root string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	sum := sha256.Sum256([]byte(root))
+	name := hex.EncodeToString(sum[:8]) + ".json"
+	return filepath.Join(homeDir, ".config", "bast", IndexDirName, name), nil
+}
+
+// LoadIndex reads the saved index for root. It returns nil if there is none.
+func LoadIndex(root string) *Index {
+	path, err := IndexPath(root)
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var ix Index
+	if err := json.Unmarshal(data, &ix); err != nil || ix.Root != root {
+		return nil
+	}
+	return &ix
+}
+
+// Save writes the index for its root. Incomplete indexes aren't saved,
+// since they're never used.
+func (ix *Index) Save() error {
+	if !ix.Complete {
+		return nil
+	}
+	path, err := IndexPath(ix.Root)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create index directory: %w", err)
+	}
+	data, err := json.Marshal(ix)
+	if err != nil {
+		return fmt.Errorf("failed to encode index: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return nil
+}
+
+// PrefetchIndex returns a usable index for root, loading the saved one
+// when it is still current and otherwise rebuilding and saving it
+func PrefetchIndex(root string) *Index {
+	if ix := LoadIndex(root); ix.Usable(root) {
+		return ix
+	}
+	ix := BuildIndex(root)
+	ix.Save() // Best effort; it is rebuilt next time otherwise
+	return ix
+}
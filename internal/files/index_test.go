@@ -0,0 +1,57 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestIndex(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	for _, d := range []string{"src/main", "node_modules/pkg", ".git"} {
+		if err := os.MkdirAll(filepath.Join(dir, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []string{"src/app.go", "src/main/main.go", "README.md", "node_modules/pkg/index.js", ".git/HEAD"} {
+		if err := os.WriteFile(filepath.Join(dir, f), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ix := PrefetchIndex(dir)
+	if !ix.Usable(dir) {
+		t.Fatal("fresh index is not usable")
+	}
+	for _, prefix := range []string{"", "main", "READ", "index"} {
+		if got, want := ix.Search(prefix, 10), ListFiles(dir, prefix, 10); !reflect.DeepEqual(got, want) {
+			t.Errorf("Search(%q) = %v, ListFiles = %v", prefix, got, want)
+		}
+	}
+	if ix.Usable(t.TempDir()) {
+		t.Error("index is usable in another directory")
+	}
+
+	saved := LoadIndex(dir)
+	if !reflect.DeepEqual(saved, ix) {
+		t.Errorf("LoadIndex() = %+v, want %+v", saved, ix)
+	}
+
+	// Adding a file changes its directory's mtime
+	if err := os.WriteFile(filepath.Join(dir, "src", "new.go"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(dir, "src"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	if ix.Usable(dir) {
+		t.Error("index is usable after a directory changed")
+	}
+	if got := PrefetchIndex(dir).Search("new", 10); !reflect.DeepEqual(got, []string{"src/new.go"}) {
+		t.Errorf("rebuilt Search(new) = %v", got)
+	}
+}
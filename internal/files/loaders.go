@@ -0,0 +1,213 @@
+package files
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MaxDocumentBytes is the largest notebook, PDF, or docx file that text is
+// extracted from (20MB). The extracted text is then held to the usual limits.
+const MaxDocumentBytes = 20 * 1024 * 1024
+
+// pdftotextTimeout bounds the external pdftotext run
+const pdftotextTimeout = 10 * time.Second
+
+// loader extracts plain text from a document format
+type loader func(path string, data []byte) (string, error)
+
+// loaders maps file extensions to their text extractors
+var loaders = map[string]loader{
+	".ipynb": loadNotebook,
+	".pdf":   loadPDF,
+	".docx":  loadDocx,
+}
+
+// IsDocument reports whether path is a format that ExtractText converts
+func IsDocument(path string) bool {
+	_, ok := loaders[strings.ToLower(filepath.Ext(path))]
+	return ok
+}
+
+// ExtractText reads a notebook, PDF, or docx file and returns its text.
+// Notebook outputs are dropped. It fails for other formats, for files over
+// MaxDocumentBytes, and for documents with no extractable text.
+func ExtractText(path string) (string, error) {
+	load, ok := loaders[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return "", fmt.Errorf("unsupported document type")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.Size() > MaxDocumentBytes {
+		return "", fmt.Errorf("document too large (>20MB, see MaxDocumentBytes)")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	text, err := load(path, data)
+	if err != nil {
+		return "", err
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", fmt.Errorf("no extractable text")
+	}
+	return text, nil
+}
+
+// notebook is the part of a Jupyter notebook that holds its source
+type notebook struct {
+	Cells []struct {
+		CellType string          `json:"cell_type"`
+		Source   json.RawMessage `json:"source"` // a string or a list of lines
+	} `json:"cells"`
+	Metadata struct {
+		Kernelspec struct {
+			Language string `json:"language"`
+		} `json:"kernelspec"`
+		LanguageInfo struct {
+			Name string `json:"name"`
+		} `json:"language_info"`
+	} `json:"metadata"`
+}
+
+// loadNotebook renders a notebook's cells in the "# %%" percent format,
+// without outputs, which are often large images or logs
+func loadNotebook(path string, data []byte) (string, error) {
+	var nb notebook
+	if err := json.Unmarshal(data, &nb); err != nil {
+		return "", fmt.Errorf("invalid notebook: %w", err)
+	}
+	lang := nb.Metadata.Kernelspec.Language
+	if lang == "" {
+		lang = nb.Metadata.LanguageInfo.Name
+	}
+
+	var b strings.Builder
+	if lang != "" {
+		fmt.Fprintf(&b, "# Jupyter notebook (%s), outputs omitted\n", lang)
+	}
+	for _, cell := range nb.Cells {
+		source := notebookSource(cell.Source)
+		if strings.TrimSpace(source) == "" {
+			continue
+		}
+		switch cell.CellType {
+		case "code":
+			b.WriteString("\n# %%\n")
+		default:
+			fmt.Fprintf(&b, "\n# %%%% [%s]\n", cell.CellType)
+		}
+		b.WriteString(strings.TrimRight(source, "\n"))
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+// notebookSource joins a cell source, which is a string or a list of lines
+func notebookSource(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var lines []string
+	json.Unmarshal(raw, &lines)
+	return strings.Join(lines, "")
+}
+
+// loadDocx extracts paragraphs from a Word document's main part
+func loadDocx(path string, data []byte) (string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("invalid docx: %w", err)
+	}
+	var doc io.ReadCloser
+	for _, f := range zr.File {
+		if f.Name == "word/document.xml" {
+			doc, err = f.Open()
+			if err != nil {
+				return "", fmt.Errorf("invalid docx: %w", err)
+			}
+			break
+		}
+	}
+	if doc == nil {
+		return "", fmt.Errorf("invalid docx: no word/document.xml")
+	}
+	defer doc.Close()
+
+	var b strings.Builder
+	dec := xml.NewDecoder(io.LimitReader(doc, MaxDocumentBytes))
+	inText := false
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("invalid docx: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				b.WriteString("\t")
+			case "br", "cr":
+				b.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				b.WriteString("\n")
+			case "tc":
+				b.WriteString("\t")
+			}
+		case xml.CharData:
+			if inText {
+				b.Write(t)
+			}
+		}
+	}
+	return b.String(), nil
+}
+
+// loadPDF extracts text with pdftotext (poppler) when it is installed,
+// which handles embedded fonts and layout. Otherwise a built-in extractor
+// reads the text operators of the page streams, which covers PDFs written
+// with standard fonts but not ones that re-encode their glyphs.
+func loadPDF(path string, data []byte) (string, error) {
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return "", fmt.Errorf("not a PDF file")
+	}
+	if _, err := exec.LookPath("pdftotext"); err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), pdftotextTimeout)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, "pdftotext", "-q", "-enc", "UTF-8", path, "-").Output()
+		if err == nil && strings.TrimSpace(string(out)) != "" {
+			return string(out), nil
+		}
+	}
+	text := extractPDFText(data)
+	if strings.TrimSpace(text) == "" || IsBinary(text) {
+		return "", fmt.Errorf("no extractable text (scanned, or uses embedded font encodings; install pdftotext)")
+	}
+	return text, nil
+}
@@ -0,0 +1,135 @@
+package files
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadNotebook(t *testing.T) {
+	nb := `{
+  "metadata": {"kernelspec": {"language": "python"}},
+  "cells": [
+    {"cell_type": "markdown", "source": ["# Churn model\n", "Trains on last month."]},
+    {"cell_type": "code", "source": "import pandas as pd\ndf = pd.read_csv('churn.csv')",
+     "outputs": [{"output_type": "display_data", "data": {"image/png": "iVBORw0KGgoAAAANSUhEUgAA"}}]},
+    {"cell_type": "code", "source": []}
+  ]
+}`
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "model.ipynb"), []byte(nb), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	results := ReadFiles(dir, []string{"model.ipynb"}, MaxTotalFileBytes)
+	if len(results) != 1 || results[0].Error != "" {
+		t.Fatalf("results = %+v", results)
+	}
+	got := results[0].Content
+	for _, want := range []string{"(python)", "# %% [markdown]\n# Churn model\nTrains on last month.", "# %%\nimport pandas as pd"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("notebook text missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "iVBORw0KGgo") {
+		t.Errorf("notebook outputs were kept:\n%s", got)
+	}
+}
+
+func TestLoadDocx(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.Create("word/document.xml")
+	fmt.Fprint(w, `<?xml version="1.0"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:r><w:t>Design</w:t></w:r><w:r><w:t xml:space="preserve"> review</w:t></w:r></w:p>
+<w:p><w:r><w:t>Owner:</w:t><w:tab/><w:t>platform</w:t></w:r></w:p>
+</w:body></w:document>`)
+	zw.Close()
+
+	got, err := loadDocx("design.docx", buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Design review\nOwner:\tplatform\n"; got != want {
+		t.Errorf("loadDocx() = %q, want %q", got, want)
+	}
+
+	if _, err := loadDocx("bad.docx", []byte("not a zip")); err == nil {
+		t.Error("expected an error for an invalid docx")
+	}
+}
+
+func TestExtractPDFText(t *testing.T) {
+	var content bytes.Buffer
+	zw := zlib.NewWriter(&content)
+	fmt.Fprint(zw, "BT /F1 12 Tf 72 720 Td (Quarterly \\(Q3\\) report) Tj 0 -14 Td [(Rev) -20 (enue) -300 (up)] TJ ET")
+	zw.Close()
+
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n1 0 obj << /Type /Catalog >> endobj\n")
+	fmt.Fprintf(&pdf, "4 0 obj << /Length %d /Filter /FlateDecode >>\nstream\n", content.Len())
+	pdf.Write(content.Bytes())
+	pdf.WriteString("\nendstream\nendobj\n")
+	pdf.WriteString("5 0 obj << /Length 30 >>\nstream\nBT <FEFF00480069> Tj ET\nendstream\nendobj\n")
+	pdf.WriteString("6 0 obj << /Subtype /Image /Length 4 >>\nstream\n(no)\nendstream\nendobj\n%%EOF\n")
+
+	got := extractPDFText(pdf.Bytes())
+	want := "Quarterly (Q3) report\nRevenue up\nHi\n"
+	if got != want {
+		t.Errorf("extractPDFText() = %q, want %q", got, want)
+	}
+}
+
+func TestPDFStreamsDecompressionBudget(t *testing.T) {
+	// Each stream inflates to 1000 bytes
+	var content bytes.Buffer
+	zw := zlib.NewWriter(&content)
+	fmt.Fprint(zw, strings.Repeat("BT (x) Tj ET ", 1000/13)+strings.Repeat(" ", 1000%13))
+	zw.Close()
+
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n")
+	for i := 0; i < 10; i++ {
+		fmt.Fprintf(&pdf, "%d 0 obj << /Length %d /Filter /FlateDecode >>\nstream\n", i+1, content.Len())
+		pdf.Write(content.Bytes())
+		pdf.WriteString("\nendstream\nendobj\n")
+	}
+
+	if got := len(pdfStreams(pdf.Bytes(), 20000)); got != 10 {
+		t.Errorf("pdfStreams() with room for all = %d streams, want 10", got)
+	}
+	streams := pdfStreams(pdf.Bytes(), 2500)
+	total := 0
+	for _, s := range streams {
+		total += len(s)
+	}
+	if len(streams) != 3 || total != 2500 {
+		t.Errorf("pdfStreams() = %d streams of %d bytes, want decoding to stop at the 2500 byte budget", len(streams), total)
+	}
+}
+
+func TestDecodePDFString(t *testing.T) {
+	if got := decodePDFString([]byte("caf\xe9")); got != "café" {
+		t.Errorf("Latin-1 string = %q", got)
+	}
+	if got := decodePDFString([]byte{0x00, 0x2a, 0x00, 0x51}); got != "" {
+		t.Errorf("glyph id string = %q, want it dropped", got)
+	}
+}
+
+func TestExtractTextUnsupported(t *testing.T) {
+	if IsDocument("notes.txt") || !IsDocument("Report.PDF") {
+		t.Error("IsDocument matched the wrong extensions")
+	}
+	path := filepath.Join(t.TempDir(), "empty.pdf")
+	os.WriteFile(path, []byte("%PDF-1.4\n%%EOF\n"), 0644)
+	if _, err := ExtractText(path); err == nil {
+		t.Error("expected an error for a PDF without text")
+	}
+}
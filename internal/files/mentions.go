@@ -1,6 +1,7 @@
 package files
 
 import (
+	"os"
 	"regexp"
 	"strings"
 )
@@ -11,22 +12,65 @@ var mentionRegex = regexp.MustCompile(`@(?:"([^"]+)"|([^\s@"]+))`)
 
 // ParseMentions extracts @file references from a query.
 // e.g., "summarize @readme.md and @src/main.go" → ["readme.md", "src/main.go"]
+// The domain of an email address (user@example.com) is only a mention when
+// a file by that name exists.
 func ParseMentions(query string) []string {
-	matches := mentionRegex.FindAllStringSubmatch(query, -1)
 	var mentions []string
-
-	for _, match := range matches {
-		// match[1] is quoted, match[2] is unquoted
-		if match[1] != "" {
-			mentions = append(mentions, match[1])
-		} else if match[2] != "" {
-			mentions = append(mentions, match[2])
+	for _, loc := range mentionRegex.FindAllStringSubmatchIndex(query, -1) {
+		if mention, ok := mentionAt(query, loc); ok {
+			mentions = append(mentions, mention)
 		}
 	}
-
 	return mentions
 }
 
+// mentionAt returns the file named by the mention regex match at loc,
+// rejecting email-like matches whose target is not a file
+func mentionAt(query string, loc []int) (string, bool) {
+	// loc[2:4] is the quoted name, loc[4:6] the unquoted one
+	if loc[2] >= 0 {
+		return query[loc[2]:loc[3]], loc[3] > loc[2]
+	}
+	if loc[4] < 0 || loc[5] == loc[4] {
+		return "", false
+	}
+	mention := query[loc[4]:loc[5]]
+	if loc[0] > 0 && isWordByte(query[loc[0]-1]) {
+		info, err := os.Stat(mention)
+		return mention, err == nil && !info.IsDir()
+	}
+	return mention, true
+}
+
+// isWordByte reports whether b can end the local part of an email address
+func isWordByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' ||
+		strings.IndexByte("._%+-", b) >= 0
+}
+
+// ReferencedPaths returns the files a query refers to: explicit @mentions
+// first, then implicit references ("the readme") that resolve to a file in cwd
+func ReferencedPaths(cwd, query string) []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, mention := range ParseMentions(query) {
+		if !seen[mention] {
+			seen[mention] = true
+			paths = append(paths, mention)
+		}
+	}
+	for _, ref := range DetectFileReferences(query) {
+		if seen[ref] {
+			continue
+		}
+		if path, err := FindFile(cwd, ref); err == nil && !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
 // filePatterns are words that commonly refer to specific files
 var filePatterns = map[string]bool{
 	"readme":       true,
@@ -155,11 +199,19 @@ func isLikelyFileReference(word string) bool {
 
 // StripMentions removes @mentions from a query for cleaner AI prompts.
 // e.g., "summarize @readme.md" → "summarize readme.md"
+// Email addresses are left intact.
 func StripMentions(query string) string {
-	// Replace @mentions with just the filename
-	result := mentionRegex.ReplaceAllStringFunc(query, func(match string) string {
-		// Remove the @ prefix
-		return strings.TrimPrefix(match, "@")
-	})
-	return result
+	var b strings.Builder
+	last := 0
+	for _, loc := range mentionRegex.FindAllStringSubmatchIndex(query, -1) {
+		if _, ok := mentionAt(query, loc); !ok {
+			continue
+		}
+		// Replace the @mention with just the filename
+		b.WriteString(query[last:loc[0]])
+		b.WriteString(query[loc[0]+1 : loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(query[last:])
+	return b.String()
 }
@@ -1,6 +1,8 @@
 package files
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 )
@@ -21,8 +23,8 @@ func TestParseMentions(t *testing.T) {
 		{"mention at start", "@readme.md summarize this", []string{"readme.md"}},
 		{"mention at end", "what's in @package.json", []string{"package.json"}},
 		{"deep path", "@internal/files/reader.go", []string{"internal/files/reader.go"}},
-		// Note: emails are currently matched as mentions - this is a known limitation
-		{"email matched as mention", "contact user@example.com", []string{"example.com"}},
+		{"email skipped", "contact user@example.com", nil},
+		{"email beside mention", "mail first.last@example.org about @notes.md", []string{"notes.md"}},
 	}
 
 	for _, tt := range tests {
@@ -120,6 +122,20 @@ func TestIsLikelyFileReference(t *testing.T) {
 	}
 }
 
+func TestParseMentionsEmailLikeFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("a: 1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	// An email-like mention counts when the file exists
+	got := ParseMentions("compare prod@config.yaml with admin@example.com")
+	if want := []string{"config.yaml"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseMentions = %v, want %v", got, want)
+	}
+}
+
 func TestStripMentions(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -130,6 +146,7 @@ func TestStripMentions(t *testing.T) {
 		{"single mention", "summarize @readme.md", "summarize readme.md"},
 		{"multiple mentions", "compare @a.go and @b.go", "compare a.go and b.go"},
 		{"quoted mention", `read @"my file.txt"`, `read "my file.txt"`},
+		{"email kept", "email user@example.com about @a.go", "email user@example.com about a.go"},
 	}
 
 	for _, tt := range tests {
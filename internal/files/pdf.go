@@ -0,0 +1,356 @@
+package files
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// extractPDFText pulls the text shown by the page content streams of a
+// PDF. Strings are decoded as Latin-1 (or UTF-16 with a byte order mark);
+// strings in glyph-id encodings come out as control characters and are
+// dropped, so the result may be empty for such files.
+func extractPDFText(data []byte) string {
+	var b strings.Builder
+	for _, stream := range pdfStreams(data, MaxDocumentBytes) {
+		extractContentText(stream, &b)
+	}
+	return b.String()
+}
+
+// pdfStreams returns the decoded streams of a PDF that may hold page
+// content, skipping images, fonts, metadata, and unsupported filters.
+// Decompression stops once budget bytes have been inflated in total, so a
+// small file of many compressed streams can't expand without bound.
+func pdfStreams(data []byte, budget int) [][]byte {
+	var streams [][]byte
+	rest := data
+	offset := 0
+	for {
+		i := bytes.Index(rest, []byte("stream"))
+		if i < 0 {
+			break
+		}
+		start := offset + i
+		// Skip "endstream" and matches inside other words
+		if start >= 3 && string(data[start-3:start]) == "end" {
+			offset = start + len("stream")
+			rest = data[offset:]
+			continue
+		}
+		body := start + len("stream")
+		if body < len(data) && data[body] == '\r' {
+			body++
+		}
+		if body < len(data) && data[body] == '\n' {
+			body++
+		}
+		end := bytes.Index(data[body:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		raw := bytes.TrimRight(data[body:body+end], "\r\n")
+
+		dictStart := bytes.LastIndex(data[:start], []byte(" obj"))
+		if dictStart < 0 {
+			dictStart = 0
+		}
+		s, inflated, ok := decodeStream(string(data[dictStart:start]), raw, budget)
+		if ok {
+			streams = append(streams, s)
+		}
+		if budget -= inflated; budget <= 0 {
+			break
+		}
+
+		offset = body + end + len("endstream")
+		rest = data[offset:]
+	}
+	return streams
+}
+
+// decodeStream applies the stream's filter, inflating at most limit bytes.
+// It returns how many bytes were inflated, and reports false for streams
+// that can't hold page text or use a filter other than FlateDecode.
+func decodeStream(dict string, raw []byte, limit int) (out []byte, inflated int, ok bool) {
+	for _, skip := range []string{"/Image", "/XRef", "/ObjStm", "/Metadata", "/FontFile", "/Length1", "/EmbeddedFile"} {
+		if strings.Contains(dict, skip) {
+			return nil, 0, false
+		}
+	}
+	if !strings.Contains(dict, "/Filter") {
+		return raw, 0, true
+	}
+	filters := strings.Count(dict, "Decode")
+	if !strings.Contains(dict, "/FlateDecode") || filters > 1 {
+		return nil, 0, false
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, 0, false
+	}
+	defer zr.Close()
+	// Keep what decompressed before any corruption
+	out, _ = io.ReadAll(io.LimitReader(zr, int64(limit)))
+	return out, len(out), len(out) > 0
+}
+
+// pdfOperand is a value on the content stream operand stack
+type pdfOperand struct {
+	str   string
+	num   float64
+	isStr bool
+	isNum bool
+	array []pdfOperand
+	isArr bool
+}
+
+// extractContentText interprets the text operators of a content stream
+func extractContentText(content []byte, b *strings.Builder) {
+	var stack []pdfOperand
+	var arrays [][]pdfOperand // open [ ... ] arrays
+	lastY := 0.0
+	// Gaps become a space only when more text follows on the same line
+	pendingSpace := false
+	write := func(text string) {
+		if text == "" {
+			return
+		}
+		if pendingSpace && b.Len() > 0 && !strings.HasSuffix(b.String(), "\n") {
+			b.WriteString(" ")
+		}
+		pendingSpace = false
+		b.WriteString(text)
+	}
+	newline := func() {
+		pendingSpace = false
+		if b.Len() > 0 && !strings.HasSuffix(b.String(), "\n") {
+			b.WriteString("\n")
+		}
+	}
+	push := func(op pdfOperand) {
+		if n := len(arrays); n > 0 {
+			arrays[n-1] = append(arrays[n-1], op)
+			return
+		}
+		stack = append(stack, op)
+	}
+	num := func(i int) float64 {
+		if i >= 0 && i < len(stack) && stack[i].isNum {
+			return stack[i].num
+		}
+		return 0
+	}
+
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '%':
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+		case c == '(':
+			s, next := readLiteralString(content, i)
+			push(pdfOperand{str: decodePDFString(s), isStr: true})
+			i = next
+		case c == '<' && i+1 < len(content) && content[i+1] == '<':
+			i += 2
+		case c == '>' && i+1 < len(content) && content[i+1] == '>':
+			i += 2
+		case c == '<':
+			end := bytes.IndexByte(content[i:], '>')
+			if end < 0 {
+				return
+			}
+			push(pdfOperand{str: decodePDFString(decodeHex(content[i+1 : i+end])), isStr: true})
+			i += end + 1
+		case c == '[':
+			arrays = append(arrays, nil)
+			i++
+		case c == ']':
+			if n := len(arrays); n > 0 {
+				arr := arrays[n-1]
+				arrays = arrays[:n-1]
+				push(pdfOperand{array: arr, isArr: true})
+			}
+			i++
+		case isPDFSpace(c):
+			i++
+		default:
+			isName := c == '/'
+			if isName {
+				i++
+			}
+			start := i
+			for i < len(content) && !isPDFSpace(content[i]) && !isPDFDelimiter(content[i]) {
+				i++
+			}
+			if isName {
+				// Names (fonts, resources) are operands the text doesn't need
+				push(pdfOperand{})
+				continue
+			}
+			if i == start {
+				i++
+				continue
+			}
+			word := string(content[start:i])
+			if f, err := strconv.ParseFloat(word, 64); err == nil {
+				push(pdfOperand{num: f, isNum: true})
+				continue
+			}
+
+			switch word {
+			case "Tj":
+				if n := len(stack); n > 0 && stack[n-1].isStr {
+					write(stack[n-1].str)
+				}
+			case "'", "\"":
+				newline()
+				if n := len(stack); n > 0 && stack[n-1].isStr {
+					write(stack[n-1].str)
+				}
+			case "TJ":
+				if n := len(stack); n > 0 && stack[n-1].isArr {
+					for _, el := range stack[n-1].array {
+						if el.isStr {
+							write(el.str)
+						} else if el.isNum && el.num < -250 {
+							// A large negative kern is a word gap
+							pendingSpace = true
+						}
+					}
+				}
+			case "Td", "TD":
+				if num(len(stack)-1) != 0 {
+					newline()
+				} else {
+					pendingSpace = true
+				}
+			case "T*":
+				newline()
+			case "Tm":
+				if y := num(len(stack) - 1); y != lastY {
+					newline()
+					lastY = y
+				}
+			case "ET":
+				pendingSpace = true
+			}
+			stack = stack[:0]
+		}
+	}
+	newline()
+}
+
+// readLiteralString reads a (...) string starting at content[i], handling
+// nesting and escapes. It returns the raw bytes and the index after it.
+func readLiteralString(content []byte, i int) ([]byte, int) {
+	var out []byte
+	depth := 0
+	for i++; i < len(content); i++ {
+		c := content[i]
+		switch c {
+		case '\\':
+			i++
+			if i >= len(content) {
+				return out, i
+			}
+			switch e := content[i]; e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r', '\n':
+				// Line continuation
+				if e == '\r' && i+1 < len(content) && content[i+1] == '\n' {
+					i++
+				}
+			default:
+				if e >= '0' && e <= '7' {
+					v := 0
+					for n := 0; n < 3 && i < len(content) && content[i] >= '0' && content[i] <= '7'; n++ {
+						v = v*8 + int(content[i]-'0')
+						i++
+					}
+					i--
+					out = append(out, byte(v))
+				} else {
+					out = append(out, e)
+				}
+			}
+		case '(':
+			depth++
+			out = append(out, c)
+		case ')':
+			if depth == 0 {
+				return out, i + 1
+			}
+			depth--
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out, i
+}
+
+// decodeHex decodes a <...> hex string; an odd final digit is padded with 0
+func decodeHex(hex []byte) []byte {
+	var digits []byte
+	for _, c := range hex {
+		if !isPDFSpace(c) {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, 0, len(digits)/2)
+	for i := 0; i+1 < len(digits); i += 2 {
+		v, err := strconv.ParseUint(string(digits[i:i+2]), 16, 8)
+		if err != nil {
+			return nil
+		}
+		out = append(out, byte(v))
+	}
+	return out
+}
+
+// decodePDFString turns string bytes into text: UTF-16BE after a byte
+// order mark, Latin-1 otherwise. Strings with control characters are
+// glyph ids rather than text, and decode to "".
+func decodePDFString(s []byte) string {
+	if len(s) >= 2 && s[0] == 0xFE && s[1] == 0xFF {
+		u := make([]uint16, 0, len(s)/2)
+		for i := 2; i+1 < len(s); i += 2 {
+			u = append(u, uint16(s[i])<<8|uint16(s[i+1]))
+		}
+		return string(utf16.Decode(u))
+	}
+	var b strings.Builder
+	for _, c := range s {
+		if c < 0x20 && c != '\t' && c != '\n' && c != '\r' {
+			return ""
+		}
+		b.WriteRune(rune(c))
+	}
+	return b.String()
+}
+
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
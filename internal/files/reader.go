@@ -1,10 +1,13 @@
 package files
 
 import (
+	"errors"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"unicode/utf8"
 )
 
@@ -38,128 +41,201 @@ type FileContent struct {
 }
 
 // ReadFiles reads multiple files, respecting size limits.
-// maxBytes is the maximum total bytes to read across all files.
-// Files are read in order until the limit is reached.
+// maxBytes is the maximum total bytes to read across all files. It is
+// shared so that every readable file contributes: files smaller than an
+// equal share are kept whole, and what they leave over is split among the
+// larger ones (see allocateBudget). Files are read concurrently and
+// returned in input order.
 func ReadFiles(cwd string, paths []string, maxBytes int) []FileContent {
-	var results []FileContent
-	totalRead := 0
-
-	for _, p := range paths {
-		if totalRead >= maxBytes {
-			break
+	reads := make([]fileRead, len(paths))
+	sem := make(chan struct{}, MaxReadWorkers)
+	var wg sync.WaitGroup
+	for i, p := range paths {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, p string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			reads[i] = readFile(cwd, p, maxBytes)
+		}(i, p)
+	}
+	wg.Wait()
+
+	// Skip binary files (check for null bytes or invalid UTF-8) before
+	// sharing out the budget, so they don't take a share
+	demands := make([]int, len(reads))
+	for i := range reads {
+		r := &reads[i]
+		if r.Error == "" && IsBinary(r.Content) {
+			r.FileContent = FileContent{Path: r.Path, Error: "binary file"}
 		}
-
-		// Resolve path relative to cwd
-		fullPath := p
-		if !filepath.IsAbs(p) {
-			fullPath = filepath.Join(cwd, p)
+		if r.Error == "" {
+			demands[i] = len(r.Content)
 		}
+	}
+	allowed := allocateBudget(demands, maxBytes)
 
-		// Security: ensure path is within cwd (no parent traversal)
-		absPath, err := filepath.Abs(fullPath)
-		if err != nil {
-			results = append(results, FileContent{
-				Path:  p,
-				Error: "invalid path",
-			})
+	results := make([]FileContent, 0, len(reads))
+	for i, r := range reads {
+		if r.Error != "" {
+			results = append(results, r.FileContent)
 			continue
 		}
-
-		absCwd, err := filepath.Abs(cwd)
-		if err != nil {
+		if allowed[i] == 0 && demands[i] > 0 {
 			results = append(results, FileContent{
-				Path:  p,
-				Error: "invalid working directory",
+				Path:  r.Path,
+				Error: "skipped (byte budget used up by other files)",
 			})
 			continue
 		}
 
-		if !strings.HasPrefix(absPath, absCwd+string(filepath.Separator)) && absPath != absCwd {
-			// Allow files directly in cwd
-			if filepath.Dir(absPath) != absCwd {
-				results = append(results, FileContent{
-					Path:  p,
-					Error: "path outside working directory",
-				})
-				continue
-			}
-		}
+		content := TruncateUTF8(r.Content, allowed[i])
+		truncated := len(content) < int(r.size)
 
-		// Security: block sensitive files from being read
-		if isSensitiveFile(absPath) {
-			results = append(results, FileContent{
-				Path:  p,
-				Error: "sensitive file (contains credentials or secrets)",
-			})
-			continue
+		fc := FileContent{
+			Path:    r.Path,
+			Content: content,
 		}
-
-		// Check if file exists and is regular
-		info, err := os.Stat(absPath)
-		if err != nil {
-			results = append(results, FileContent{
-				Path:  p,
-				Error: "file not found",
-			})
-			continue
+		if truncated {
+			fc.Content += "\n... (truncated)"
 		}
 
-		if info.IsDir() {
-			results = append(results, FileContent{
-				Path:  p,
-				Error: "is a directory",
-			})
-			continue
-		}
+		results = append(results, fc)
+	}
 
-		// Skip large files
-		if info.Size() > int64(MaxSingleFileBytes) {
-			results = append(results, FileContent{
-				Path:  p,
-				Error: "file too large (>50KB, see MaxSingleFileBytes)",
-			})
-			continue
-		}
+	return results
+}
 
-		// Read file with remaining budget
-		remaining := maxBytes - totalRead
-		if remaining <= 0 {
-			break
+// allocateBudget splits budget across demands so each gets an equal share,
+// capped at what it asked for, with leftover share redistributed to the
+// rest (max-min fairness). It returns the bytes allowed for each demand.
+func allocateBudget(demands []int, budget int) []int {
+	allowed := make([]int, len(demands))
+	var order []int
+	for i, d := range demands {
+		if d > 0 {
+			order = append(order, i)
 		}
-
-		content, err := readFileWithLimit(absPath, remaining)
-		if err != nil {
-			results = append(results, FileContent{
-				Path:  p,
-				Error: err.Error(),
-			})
+	}
+	// Smallest first: each one either fits its share or none of the rest do
+	sort.SliceStable(order, func(a, b int) bool {
+		return demands[order[a]] < demands[order[b]]
+	})
+
+	remaining := budget
+	for n, i := range order {
+		share := remaining / (len(order) - n)
+		if demands[i] <= share {
+			allowed[i] = demands[i]
+			remaining -= demands[i]
 			continue
 		}
-
-		// Skip binary files (check for null bytes or invalid UTF-8)
-		if isBinary(content) {
-			results = append(results, FileContent{
-				Path:  p,
-				Error: "binary file",
-			})
-			continue
+		// The rest all want more than an equal share: split what's left,
+		// giving the odd bytes to the files mentioned first
+		rest := append([]int(nil), order[n:]...)
+		sort.Ints(rest)
+		extra := remaining - share*len(rest)
+		for _, j := range rest {
+			allowed[j] = share
+			if extra > 0 {
+				allowed[j]++
+				extra--
+			}
 		}
+		break
+	}
+	return allowed
+}
+
+// TruncateUTF8 cuts s to at most n bytes without splitting a character
+func TruncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
 
-		totalRead += len(content)
-		truncated := len(content) < int(info.Size())
+// fileRead is one file read by ReadFiles before the budget is applied
+type fileRead struct {
+	FileContent
+	size int64 // Size on disk, to tell when content was cut short
+}
 
-		fc := FileContent{
-			Path:    p,
-			Content: content,
+// readFile checks that p may be read and reads up to maxBytes of it.
+// Failures are reported in Error.
+func readFile(cwd, p string, maxBytes int) fileRead {
+	// Resolve path relative to cwd
+	fullPath := p
+	if !filepath.IsAbs(p) {
+		fullPath = filepath.Join(cwd, p)
+	}
+
+	// Security: ensure path is within cwd (no parent traversal)
+	absPath, err := filepath.Abs(fullPath)
+	if err != nil {
+		return fileRead{FileContent: FileContent{Path: p, Error: "invalid path"}}
+	}
+
+	absCwd, err := filepath.Abs(cwd)
+	if err != nil {
+		return fileRead{FileContent: FileContent{Path: p, Error: "invalid working directory"}}
+	}
+
+	if !strings.HasPrefix(absPath, absCwd+string(filepath.Separator)) && absPath != absCwd {
+		// Allow files directly in cwd
+		if filepath.Dir(absPath) != absCwd {
+			return fileRead{FileContent: FileContent{Path: p, Error: "path outside working directory"}}
 		}
-		if truncated {
-			fc.Content += "\n... (truncated)"
+	}
+
+	// Security: block sensitive files from being read
+	if isSensitiveFile(absPath) {
+		return fileRead{FileContent: FileContent{Path: p, Error: "sensitive file (contains credentials or secrets)"}}
+	}
+
+	// Security: a symlink inside cwd can point anywhere, so check where it
+	// leads too. Check if file exists and is regular.
+	realPath, info, err := statInside(absCwd, absPath)
+	switch {
+	case errors.Is(err, errOutsideDir), errors.Is(err, errSlowMount):
+		return fileRead{FileContent: FileContent{Path: p, Error: err.Error()}}
+	case err != nil:
+		return fileRead{FileContent: FileContent{Path: p, Error: "file not found"}}
+	case realPath != absPath && isSensitiveFile(realPath):
+		return fileRead{FileContent: FileContent{Path: p, Error: "symlink to a sensitive file (contains credentials or secrets)"}}
+	}
+	absPath = realPath
+
+	if info.IsDir() {
+		return fileRead{FileContent: FileContent{Path: p, Error: "is a directory"}}
+	}
+
+	// Notebooks, PDFs, and docx files are converted to text, which is cut
+	// to the single-file limit rather than refused for its size
+	if IsDocument(absPath) {
+		text, err := ExtractText(absPath)
+		if err != nil {
+			return fileRead{FileContent: FileContent{Path: p, Error: err.Error()}}
 		}
+		content := TruncateUTF8(text, min(maxBytes, MaxSingleFileBytes))
+		return fileRead{FileContent: FileContent{Path: p, Content: content}, size: int64(len(text))}
+	}
 
-		results = append(results, fc)
+	// Skip large files
+	if info.Size() > int64(MaxSingleFileBytes) {
+		return fileRead{FileContent: FileContent{Path: p, Error: "file too large (>50KB, see MaxSingleFileBytes)"}}
 	}
 
-	return results
+	content, err := readFileWithLimit(absPath, maxBytes)
+	if err != nil {
+		return fileRead{FileContent: FileContent{Path: p, Error: err.Error()}}
+	}
+	return fileRead{FileContent: FileContent{Path: p, Content: content}, size: info.Size()}
 }
 
 // readFileWithLimit reads up to maxBytes from a file
@@ -187,8 +263,9 @@ func capitalizeFirst(s string) string {
 	return strings.ToUpper(s[:1]) + s[1:]
 }
 
-// isBinary checks if content appears to be binary
-func isBinary(content string) bool {
+// IsBinary reports whether content appears to be binary: it has NUL bytes
+// or is not valid UTF-8
+func IsBinary(content string) bool {
 	// Check for null bytes
 	if strings.Contains(content, "\x00") {
 		return true
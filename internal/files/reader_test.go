@@ -1,8 +1,10 @@
 package files
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -102,9 +104,9 @@ func TestIsBinary(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := isBinary(tt.content)
+			got := IsBinary(tt.content)
 			if got != tt.binary {
-				t.Errorf("isBinary(%q) = %v, want %v", tt.content, got, tt.binary)
+				t.Errorf("IsBinary(%q) = %v, want %v", tt.content, got, tt.binary)
 			}
 		})
 	}
@@ -239,6 +241,94 @@ func TestReadFiles(t *testing.T) {
 			t.Errorf("Content should be truncated, got %d bytes", len(results[0].Content))
 		}
 	})
+
+	t.Run("many files keep input order and share the budget", func(t *testing.T) {
+		var paths []string
+		for i := 0; i < 3*MaxReadWorkers; i++ {
+			name := fmt.Sprintf("many/%02d.txt", i)
+			path := filepath.Join(tmpDir, name)
+			os.MkdirAll(filepath.Dir(path), 0755)
+			if err := os.WriteFile(path, []byte(strings.Repeat("x", 100)), 0644); err != nil {
+				t.Fatal(err)
+			}
+			paths = append(paths, name)
+			if i == 2 {
+				paths = append(paths, ".env")
+			}
+		}
+
+		// 1200 bytes over 24 files of 100 bytes: 50 each, in input order
+		results := ReadFiles(tmpDir, paths, 1200)
+		if len(results) != len(paths) {
+			t.Fatalf("Expected %d results, got %d", len(paths), len(results))
+		}
+		for i, r := range results {
+			if r.Path != paths[i] {
+				t.Errorf("result %d is %s, want %s", i, r.Path, paths[i])
+			}
+		}
+		if results[3].Error == "" {
+			t.Error("Expected .env to be refused in place")
+		}
+		if last := results[len(results)-1].Content; last != strings.Repeat("x", 50)+"\n... (truncated)" {
+			t.Errorf("Last file = %q, want 50 bytes and a truncation note", last)
+		}
+	})
+}
+
+func TestReadFilesSharesBudget(t *testing.T) {
+	tmpDir := t.TempDir()
+	big := strings.Repeat("a", 40*1024)
+	small := "small file"
+	for name, content := range map[string]string{"big.txt": big, "small.txt": small, "later.txt": big} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The first large file must not starve the ones mentioned after it
+	results := ReadFiles(tmpDir, []string{"big.txt", "small.txt", "later.txt"}, 20*1024)
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	if results[1].Content != small {
+		t.Errorf("small file = %q, want it whole", results[1].Content)
+	}
+	half := (20*1024 - len(small)) / 2
+	for _, i := range []int{0, 2} {
+		if got := strings.Count(results[i].Content, "a"); got < half || got > half+1 {
+			t.Errorf("%s kept %d bytes, want about %d", results[i].Path, got, half)
+		}
+	}
+}
+
+func TestAllocateBudget(t *testing.T) {
+	tests := []struct {
+		demands []int
+		budget  int
+		want    []int
+	}{
+		{[]int{10, 20, 30}, 100, []int{10, 20, 30}},
+		{[]int{100, 10, 100}, 110, []int{50, 10, 50}},
+		{[]int{100, 0, 100}, 101, []int{51, 0, 50}},
+		{[]int{5, 5, 5}, 2, []int{1, 1, 0}},
+		{nil, 100, []int{}},
+	}
+	for _, tt := range tests {
+		got := allocateBudget(tt.demands, tt.budget)
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("allocateBudget(%v, %d) = %v, want %v", tt.demands, tt.budget, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateUTF8(t *testing.T) {
+	if got := TruncateUTF8("héllo", 2); got != "h" {
+		t.Errorf("TruncateUTF8 = %q, want %q", got, "h")
+	}
+	if got := TruncateUTF8("héllo", 3); got != "hé" {
+		t.Errorf("TruncateUTF8 = %q, want %q", got, "hé")
+	}
 }
 
 func TestFindFile(t *testing.T) {
@@ -266,9 +356,9 @@ func TestFindFile(t *testing.T) {
 	}
 
 	tests := []struct {
-		name     string
-		query    string
-		wantErr  bool
+		name    string
+		query   string
+		wantErr bool
 	}{
 		{"readme lowercase", "readme", false},
 		{"license lowercase", "license", false},
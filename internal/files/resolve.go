@@ -0,0 +1,83 @@
+package files
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultSlowMountThreshold is how long resolving and stat-ing a file may
+// take before it is skipped as being on a slow (usually network) mount
+const DefaultSlowMountThreshold = 300 * time.Millisecond
+
+var slowMountThreshold = DefaultSlowMountThreshold
+
+// Configure sets the slow-mount threshold used when reading files.
+// A non-positive threshold uses DefaultSlowMountThreshold.
+func Configure(threshold time.Duration) {
+	if threshold <= 0 {
+		threshold = DefaultSlowMountThreshold
+	}
+	slowMountThreshold = threshold
+}
+
+// statFile is os.Stat, replaced in tests to simulate a slow mount
+var statFile = os.Stat
+
+// errSlowMount reports a file whose filesystem did not answer in time
+var errSlowMount = errors.New("filesystem too slow (network mount?)")
+
+// errOutsideDir reports a symlink leading out of the allowed directory
+var errOutsideDir = errors.New("symlink points outside working directory")
+
+// ResolveInside follows the symlinks in path and checks that the target
+// is still inside dir (or directly in it). dir is resolved too, so a
+// workspace reached through a symlink works.
+func ResolveInside(dir, path string) (string, error) {
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", fmt.Errorf("invalid working directory")
+	}
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	if real != realDir && !strings.HasPrefix(real, realDir+string(filepath.Separator)) {
+		return "", errOutsideDir
+	}
+	return real, nil
+}
+
+// resolvedFile is a path after symlink resolution, with its stat result
+type resolvedFile struct {
+	path string
+	info os.FileInfo
+	err  error
+}
+
+// statInside resolves path within dir and stats the target, giving up
+// with errSlowMount when that takes longer than the slow-mount threshold.
+// A hung network mount would otherwise stall the whole read.
+func statInside(dir, path string) (string, os.FileInfo, error) {
+	stat := statFile
+	done := make(chan resolvedFile, 1)
+	go func() {
+		real, err := ResolveInside(dir, path)
+		if err != nil {
+			done <- resolvedFile{err: err}
+			return
+		}
+		info, err := stat(real)
+		done <- resolvedFile{path: real, info: info, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.path, r.info, r.err
+	case <-time.After(slowMountThreshold):
+		return "", nil, errSlowMount
+	}
+}
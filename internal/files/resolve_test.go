@@ -0,0 +1,79 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadFilesSymlinks(t *testing.T) {
+	outside := t.TempDir()
+	secret := filepath.Join(outside, "config")
+	if err := os.WriteFile(secret, []byte("Host prod\n  IdentityFile ~/.ssh/prod"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cwd := t.TempDir()
+	os.WriteFile(filepath.Join(cwd, "notes.md"), []byte("notes"), 0644)
+	os.WriteFile(filepath.Join(cwd, ".env"), []byte("KEY=1"), 0644)
+	links := map[string]string{
+		"ssh-config": secret,                         // leads outside cwd
+		"env-link":   filepath.Join(cwd, ".env"),     // inside, but sensitive
+		"readme":     filepath.Join(cwd, "notes.md"), // inside and fine
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(cwd, name)); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+	}
+
+	results := ReadFiles(cwd, []string{"ssh-config", "env-link", "readme"}, MaxTotalFileBytes)
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	if !strings.Contains(results[0].Error, "outside working directory") {
+		t.Errorf("ssh-config: error = %q, content = %q", results[0].Error, results[0].Content)
+	}
+	if !strings.Contains(results[1].Error, "sensitive") {
+		t.Errorf("env-link: error = %q", results[1].Error)
+	}
+	if results[2].Content != "notes" {
+		t.Errorf("readme: content = %q, error = %q", results[2].Content, results[2].Error)
+	}
+}
+
+func TestReadFilesSymlinkedWorkspace(t *testing.T) {
+	real := t.TempDir()
+	os.WriteFile(filepath.Join(real, "main.go"), []byte("package main"), 0644)
+	link := filepath.Join(t.TempDir(), "workspace")
+	if err := os.Symlink(real, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	results := ReadFiles(link, []string{"main.go"}, MaxTotalFileBytes)
+	if len(results) != 1 || results[0].Content != "package main" {
+		t.Errorf("results = %+v", results)
+	}
+}
+
+func TestReadFilesSlowMount(t *testing.T) {
+	cwd := t.TempDir()
+	os.WriteFile(filepath.Join(cwd, "remote.txt"), []byte("data"), 0644)
+
+	statFile = func(name string) (os.FileInfo, error) {
+		time.Sleep(200 * time.Millisecond)
+		return os.Stat(name)
+	}
+	Configure(10 * time.Millisecond)
+	defer func() {
+		statFile = os.Stat
+		Configure(0)
+	}()
+
+	results := ReadFiles(cwd, []string{"remote.txt"}, MaxTotalFileBytes)
+	if len(results) != 1 || !strings.Contains(results[0].Error, "too slow") {
+		t.Errorf("results = %+v, want a slow filesystem error", results)
+	}
+}
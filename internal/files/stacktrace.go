@@ -0,0 +1,137 @@
+package files
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MaxStackFrames is the maximum number of user-code frames read from a stack trace
+	MaxStackFrames = 3
+
+	// StackFrameContext is the number of lines shown either side of a frame's line
+	StackFrameContext = 8
+)
+
+// StackFrame is a single frame parsed from a stack trace
+type StackFrame struct {
+	File     string
+	Line     int
+	Function string // May be empty when the trace does not name it
+}
+
+var (
+	// Go panic: "main.run(...)" followed by "\t/path/to/main.go:42 +0x1d"
+	goFrameRe = regexp.MustCompile(`(?m)^(\S.*)\n\t(\S+\.go):(\d+)`)
+
+	// Python traceback: `  File "app/models.py", line 42, in save`
+	pyFrameRe = regexp.MustCompile(`(?m)^\s*File "([^"]+)", line (\d+)(?:, in (\S+))?`)
+
+	// Node: "    at save (/app/src/models.js:42:7)" or "    at /app/src/models.js:42:7"
+	nodeFrameRe = regexp.MustCompile(`(?m)^\s*at (?:(\S+) \()?((?:[A-Za-z]:)?[^():\s]+):(\d+):\d+\)?`)
+)
+
+// libraryMarkers identify frames in dependencies. Standard library frames
+// live outside the project and are skipped by ReadStackFrames.
+var libraryMarkers = []string{
+	"/pkg/mod/", "vendor/", // Go
+	"site-packages", "dist-packages", "<frozen", "<string>", // Python
+	"node_modules", "node:", "<anonymous>", // Node
+}
+
+// ParseStackTrace extracts user-code frames from a Go panic, Python traceback,
+// or Node stack, innermost frame first. Frames in dependencies are dropped.
+// Returns nil if output contains no stack trace.
+func ParseStackTrace(output string) []StackFrame {
+	var frames []StackFrame
+
+	switch {
+	case strings.Contains(output, "Traceback (most recent call last)"):
+		// Python lists the innermost frame last
+		matches := pyFrameRe.FindAllStringSubmatch(output, -1)
+		for i := len(matches) - 1; i >= 0; i-- {
+			line, _ := strconv.Atoi(matches[i][2])
+			frames = append(frames, StackFrame{File: matches[i][1], Line: line, Function: matches[i][3]})
+		}
+
+	case strings.Contains(output, "goroutine ") && goFrameRe.MatchString(output):
+		for _, m := range goFrameRe.FindAllStringSubmatch(output, -1) {
+			line, _ := strconv.Atoi(m[3])
+			frames = append(frames, StackFrame{File: m[2], Line: line, Function: goFunctionName(m[1])})
+		}
+
+	default:
+		for _, m := range nodeFrameRe.FindAllStringSubmatch(output, -1) {
+			line, _ := strconv.Atoi(m[3])
+			frames = append(frames, StackFrame{File: m[2], Line: line, Function: m[1]})
+		}
+	}
+
+	var user []StackFrame
+	for _, f := range frames {
+		if !isLibraryFrame(f.File) {
+			user = append(user, f)
+		}
+	}
+	return user
+}
+
+// goFunctionName trims the argument list from a Go panic function line
+func goFunctionName(line string) string {
+	if i := strings.LastIndex(line, "("); i > 0 {
+		line = line[:i]
+	}
+	return strings.TrimSpace(line)
+}
+
+// isLibraryFrame reports whether file belongs to a dependency
+func isLibraryFrame(file string) bool {
+	for _, marker := range libraryMarkers {
+		if strings.Contains(file, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadStackFrames reads the source around the top user-code frames of a
+// stack trace. Each result covers StackFrameContext lines either side of the
+// frame's line, with the line itself marked. Frames outside cwd, or whose
+// files cannot be read, are skipped.
+func ReadStackFrames(cwd string, frames []StackFrame) []FileContent {
+	var results []FileContent
+	for _, f := range frames {
+		if len(results) == MaxStackFrames {
+			break
+		}
+		read := ReadFiles(cwd, []string{f.File}, MaxSingleFileBytes)
+		if len(read) == 0 || read[0].Error != "" {
+			continue
+		}
+
+		lines := strings.Split(read[0].Content, "\n")
+		if f.Line < 1 || f.Line > len(lines) {
+			continue
+		}
+		start := max(f.Line-StackFrameContext, 1)
+		end := min(f.Line+StackFrameContext, len(lines))
+
+		var b strings.Builder
+		for n := start; n <= end; n++ {
+			marker := "  "
+			if n == f.Line {
+				marker = "> "
+			}
+			b.WriteString(fmt.Sprintf("%s%4d | %s\n", marker, n, lines[n-1]))
+		}
+
+		path := fmt.Sprintf("%s:%d-%d", f.File, start, end)
+		if f.Function != "" {
+			path += " (in " + f.Function + ")"
+		}
+		results = append(results, FileContent{Path: path, Content: strings.TrimSuffix(b.String(), "\n")})
+	}
+	return results
+}
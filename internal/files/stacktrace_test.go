@@ -0,0 +1,84 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseStackTrace(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected []StackFrame
+	}{
+		{"no trace", "command not found: foo", nil},
+		{
+			"go panic",
+			"panic: runtime error: index out of range\n\ngoroutine 1 [running]:\n" +
+				"main.parse({0x0, 0x0})\n\t/home/u/app/parse.go:12 +0x1d\n" +
+				"main.main()\n\t/home/u/app/main.go:5 +0x25\n" +
+				"github.com/x/y.Do()\n\t/home/u/go/pkg/mod/github.com/x/y@v1.0.0/y.go:9 +0x1\n",
+			[]StackFrame{
+				{File: "/home/u/app/parse.go", Line: 12, Function: "main.parse"},
+				{File: "/home/u/app/main.go", Line: 5, Function: "main.main"},
+			},
+		},
+		{
+			"python traceback",
+			"Traceback (most recent call last):\n" +
+				"  File \"app.py\", line 10, in <module>\n    main()\n" +
+				"  File \"/usr/lib/python3/site-packages/req.py\", line 3, in get\n" +
+				"  File \"models.py\", line 42, in save\n    x = 1 / 0\nZeroDivisionError: division by zero\n",
+			[]StackFrame{
+				{File: "models.py", Line: 42, Function: "save"},
+				{File: "app.py", Line: 10, Function: "<module>"},
+			},
+		},
+		{
+			"node stack",
+			"TypeError: x is undefined\n    at save (/app/src/models.js:42:7)\n" +
+				"    at /app/node_modules/express/router.js:1:1\n    at /app/src/index.js:3:1\n" +
+				"    at node:internal/main:1:1\n",
+			[]StackFrame{
+				{File: "/app/src/models.js", Line: 42, Function: "save"},
+				{File: "/app/src/index.js", Line: 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseStackTrace(tt.output)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ParseStackTrace() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestReadStackFrames(t *testing.T) {
+	dir := t.TempDir()
+	var src strings.Builder
+	for i := 1; i <= 30; i++ {
+		src.WriteString("line\n")
+	}
+	os.WriteFile(filepath.Join(dir, "models.py"), []byte(src.String()), 0644)
+
+	frames := []StackFrame{
+		{File: "/elsewhere/lib.py", Line: 1},
+		{File: "models.py", Line: 20, Function: "save"},
+	}
+	got := ReadStackFrames(dir, frames)
+	if len(got) != 1 {
+		t.Fatalf("ReadStackFrames() returned %d results, want 1", len(got))
+	}
+	if got[0].Path != "models.py:12-28 (in save)" {
+		t.Errorf("Path = %q, want %q", got[0].Path, "models.py:12-28 (in save)")
+	}
+	if !strings.Contains(got[0].Content, ">   20 | line") {
+		t.Errorf("Content does not mark line 20:\n%s", got[0].Content)
+	}
+}
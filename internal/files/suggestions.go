@@ -2,6 +2,7 @@ package files
 
 import (
 	"io/fs"
+	"os"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -29,11 +30,45 @@ var skippedDirs = map[string]bool{
 // Searches cwd and subdirectories recursively (limited depth).
 // Returns relative paths sorted alphabetically.
 func ListFiles(cwd string, prefix string, maxResults int) []string {
-	maxDepth := MaxSearchDepth
 	var matches []string
 
 	prefix = strings.ToLower(prefix)
 
+	walkSuggestable(cwd, func(relPath string, d fs.DirEntry) error {
+		if d.IsDir() {
+			return nil // Don't include directories in results
+		}
+
+		// Check if file matches prefix (case-insensitive)
+		if matchesPrefix(relPath, prefix) {
+			matches = append(matches, relPath)
+		}
+		return nil
+	})
+
+	// Sort alphabetically
+	sort.Strings(matches)
+
+	// Limit results
+	if len(matches) > maxResults {
+		matches = matches[:maxResults]
+	}
+
+	return matches
+}
+
+// matchesPrefix reports whether a relative path matches a lowercased
+// suggestion prefix
+func matchesPrefix(relPath, prefix string) bool {
+	return prefix == "" || strings.Contains(strings.ToLower(relPath), prefix)
+}
+
+// walkSuggestable walks the files and directories under cwd that are
+// offered as suggestions, to MaxSearchDepth, skipping hidden entries and
+// skippedDirs. visit gets paths relative to cwd and may return fs.SkipAll.
+func walkSuggestable(cwd string, visit func(relPath string, d fs.DirEntry) error) {
+	maxDepth := MaxSearchDepth
+
 	filepath.WalkDir(cwd, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return nil // Skip errors
@@ -69,29 +104,59 @@ func ListFiles(cwd string, prefix string, maxResults int) []string {
 		}
 
 		// Skip known directories to ignore
-		if d.IsDir() {
-			if skippedDirs[name] {
-				return fs.SkipDir
-			}
-			return nil // Don't include directories in results
+		if d.IsDir() && skippedDirs[name] {
+			return fs.SkipDir
 		}
 
-		// Check if file matches prefix (case-insensitive)
-		lowerPath := strings.ToLower(relPath)
-		if prefix == "" || strings.Contains(lowerPath, prefix) {
-			matches = append(matches, relPath)
-		}
+		return visit(relPath, d)
+	})
+}
+
+// ListDir returns the entries of the directory a path prefix points into,
+// for autocomplete that descends one directory at a time. For "src/ma" it
+// lists entries of src/ whose names start with "ma" (case-insensitive).
+// Directories come first, with a trailing slash, then files; each group is
+// sorted alphabetically ignoring case. Hidden entries are only listed when the name part
+// starts with a dot.
+func ListDir(cwd string, prefix string, maxResults int) []string {
+	slash := strings.LastIndex(prefix, "/")
+	dir, name := prefix[:slash+1], strings.ToLower(prefix[slash+1:])
 
+	base := dir
+	if !filepath.IsAbs(base) {
+		base = filepath.Join(cwd, dir)
+	}
+	entries, err := os.ReadDir(base)
+	if err != nil {
 		return nil
-	})
+	}
 
-	// Sort alphabetically
-	sort.Strings(matches)
+	var dirs, files []string
+	for _, e := range entries {
+		entryName := e.Name()
+		if strings.HasPrefix(entryName, ".") && !strings.HasPrefix(name, ".") {
+			continue
+		}
+		if !strings.HasPrefix(strings.ToLower(entryName), name) {
+			continue
+		}
+		if e.IsDir() {
+			if !skippedDirs[entryName] {
+				dirs = append(dirs, dir+entryName+"/")
+			}
+			continue
+		}
+		files = append(files, dir+entryName)
+	}
+	byName := func(list []string) func(i, j int) bool {
+		return func(i, j int) bool { return strings.ToLower(list[i]) < strings.ToLower(list[j]) }
+	}
+	sort.Slice(dirs, byName(dirs))
+	sort.Slice(files, byName(files))
 
-	// Limit results
+	matches := append(dirs, files...)
 	if len(matches) > maxResults {
 		matches = matches[:maxResults]
 	}
-
 	return matches
 }
@@ -0,0 +1,44 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestListDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, d := range []string{"src/main", "src/lib", "src/node_modules", "src/.hidden", "docs"} {
+		if err := os.MkdirAll(filepath.Join(dir, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []string{"src/Makefile", "src/app.go", "src/.env", "src/main/main.go", "README.md"} {
+		if err := os.WriteFile(filepath.Join(dir, f), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		prefix string
+		max    int
+		want   []string
+	}{
+		{"directories first", "src/", 10, []string{"src/lib/", "src/main/", "src/app.go", "src/Makefile"}},
+		{"name prefix is case-insensitive", "src/ma", 10, []string{"src/main/", "src/Makefile"}},
+		{"nested descent", "src/main/", 10, []string{"src/main/main.go"}},
+		{"hidden entries on a dot", "src/.", 10, []string{"src/.hidden/", "src/.env"}},
+		{"limit", "src/", 2, []string{"src/lib/", "src/main/"}},
+		{"missing directory", "nope/", 10, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ListDir(dir, tt.prefix, tt.max)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListDir(%q) = %v, want %v", tt.prefix, got, tt.want)
+			}
+		})
+	}
+}
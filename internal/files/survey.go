@@ -0,0 +1,146 @@
+package files
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// MaxSurveyFiles caps the files counted while surveying a directory
+	MaxSurveyFiles = 5000
+
+	// maxSurveySamples is how many file names are kept per top-level directory
+	maxSurveySamples = 8
+
+	// maxSurveyEntries caps the top-level entries listed in a survey
+	maxSurveyEntries = 60
+)
+
+// keyFileNames are files that explain a project, matched case-insensitively
+// at the top level and, for READMEs, in each top-level directory
+var keyFileNames = []string{
+	"readme", "readme.md", "readme.rst", "readme.txt",
+	"contributing.md", "architecture.md",
+	"go.mod", "package.json", "cargo.toml", "pyproject.toml", "setup.py", "requirements.txt",
+	"gemfile", "pom.xml", "build.gradle", "build.gradle.kts", "composer.json", "mix.exs",
+	"makefile", "justfile", "taskfile.yml", "dockerfile", "docker-compose.yml", "compose.yaml",
+	"main.go", "main.py", "index.js", "index.ts",
+}
+
+// DirEntry describes one top-level entry of a surveyed directory
+type DirEntry struct {
+	Name   string
+	IsDir  bool
+	Files  int      // Files below a directory, up to the survey limit
+	Sample []string // A few file names below a directory, in walk order
+}
+
+// DirSurvey is a bounded overview of a directory: its top-level entries and
+// the contents of files that explain it
+type DirSurvey struct {
+	Path      string
+	Entries   []DirEntry
+	Omitted   int           // Top-level entries left out of Entries
+	KeyFiles  []FileContent // READMEs, manifests, and entry points
+	Truncated bool          // The file count hit MaxSurveyFiles
+}
+
+// SurveyDir walks dir (relative to cwd, and inside it) to at most
+// MaxSearchDepth, counting files per top-level directory, and reads its key
+// files within MaxTotalFileBytes. Hidden entries and dependency or build
+// directories are skipped, and key files go through ReadFiles, so
+// credentials are never read.
+func SurveyDir(cwd, dir string) (*DirSurvey, error) {
+	root := dir
+	if !filepath.IsAbs(root) {
+		root = filepath.Join(cwd, dir)
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	survey := &DirSurvey{Path: dir}
+	var keyPaths, dirReadmes []string
+	counted := 0
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, ".") || (e.IsDir() && skippedDirs[name]) {
+			continue
+		}
+		if !e.IsDir() {
+			if isKeyFile(name) {
+				keyPaths = append(keyPaths, filepath.Join(dir, name))
+			}
+			survey.Entries = append(survey.Entries, DirEntry{Name: name})
+			continue
+		}
+
+		entry := DirEntry{Name: name, IsDir: true}
+		filepath.WalkDir(filepath.Join(root, name), func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if counted >= MaxSurveyFiles {
+				survey.Truncated = true
+				return fs.SkipAll
+			}
+			rel, _ := filepath.Rel(root, path)
+			if d.IsDir() {
+				if path != filepath.Join(root, name) && (strings.HasPrefix(d.Name(), ".") || skippedDirs[d.Name()] ||
+					strings.Count(rel, string(filepath.Separator)) >= MaxSearchDepth) {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if strings.HasPrefix(d.Name(), ".") {
+				return nil
+			}
+			counted++
+			entry.Files++
+			if len(entry.Sample) < maxSurveySamples {
+				entry.Sample = append(entry.Sample, filepath.ToSlash(rel))
+			}
+			if strings.EqualFold(filepath.Dir(rel), name) && strings.HasPrefix(strings.ToLower(d.Name()), "readme") {
+				dirReadmes = append(dirReadmes, filepath.Join(dir, rel))
+			}
+			return nil
+		})
+		survey.Entries = append(survey.Entries, entry)
+	}
+
+	// Directories first, then files, each alphabetical
+	sort.SliceStable(survey.Entries, func(i, j int) bool {
+		a, b := survey.Entries[i], survey.Entries[j]
+		if a.IsDir != b.IsDir {
+			return a.IsDir
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+	if len(survey.Entries) > maxSurveyEntries {
+		survey.Omitted = len(survey.Entries) - maxSurveyEntries
+		survey.Entries = survey.Entries[:maxSurveyEntries]
+	}
+
+	// Top-level key files first, then the READMEs of subdirectories
+	for _, f := range ReadFiles(cwd, append(keyPaths, dirReadmes...), MaxTotalFileBytes) {
+		if f.Error == "" {
+			survey.KeyFiles = append(survey.KeyFiles, f)
+		}
+	}
+	return survey, nil
+}
+
+// isKeyFile reports whether a top-level file name explains the project
+func isKeyFile(name string) bool {
+	lower := strings.ToLower(name)
+	for _, key := range keyFileNames {
+		if lower == key {
+			return true
+		}
+	}
+	return false
+}
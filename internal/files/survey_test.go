@@ -0,0 +1,68 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSurveyDir(t *testing.T) {
+	dir := t.TempDir()
+	for path, content := range map[string]string{
+		"README.md":            "# demo",
+		"go.mod":               "module demo",
+		".env":                 "SECRET=1",
+		"notes.txt":            "todo",
+		"cmd/main.go":          "package main",
+		"internal/db/db.go":    "package db",
+		"internal/README.md":   "internal packages",
+		"node_modules/x/i.js":  "x",
+		".git/HEAD":            "ref",
+		"internal/db/.keep":    "",
+		"internal/db/query.go": "package db",
+	} {
+		full := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	survey, err := SurveyDir(dir, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, e := range survey.Entries {
+		names = append(names, e.Name)
+	}
+	want := []string{"cmd", "internal", "go.mod", "notes.txt", "README.md"}
+	if len(names) != len(want) {
+		t.Fatalf("entries = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("entries = %v, want %v", names, want)
+		}
+	}
+	if internal := survey.Entries[1]; internal.Files != 3 {
+		t.Errorf("internal has %d files, want 3 (hidden files skipped)", internal.Files)
+	}
+
+	var keys []string
+	for _, f := range survey.KeyFiles {
+		keys = append(keys, f.Path)
+	}
+	wantKeys := []string{"README.md", "go.mod", filepath.Join("internal", "README.md")}
+	if len(keys) != len(wantKeys) {
+		t.Fatalf("key files = %v, want %v", keys, wantKeys)
+	}
+	for i := range wantKeys {
+		if keys[i] != wantKeys[i] {
+			t.Errorf("key files = %v, want %v", keys, wantKeys)
+		}
+	}
+}
@@ -2,12 +2,15 @@
 package git
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 // Context contains information about the current git repository state
@@ -23,6 +26,12 @@ type Context struct {
 	RemoteURL        string   // Origin remote URL (if available)
 	Ahead            int      // Commits ahead of remote
 	Behind           int      // Commits behind remote
+	Detached         bool     // True if HEAD is not on a branch
+	HeadSHA          string   // Short SHA of HEAD (set when detached)
+	Worktree         string   // Linked worktree name (empty for the main worktree)
+	IsSubmodule      bool     // True if the repository is a submodule of another
+	DirtySubmodules  []string // Submodules with new commits or local changes
+	Partial          bool     // True if some git commands exceeded the time budget
 }
 
 // Commit represents a git commit
@@ -30,11 +39,49 @@ type Commit struct {
 	Hash    string // Short hash (7 chars)
 	Subject string // Commit message first line
 	Author  string // Author name
+	Body    string // Message after the subject (set by CommitsSince)
 }
 
-// GetContext gathers git repository context from the current directory
+// Depth controls how much git context is gathered
+type Depth string
+
+const (
+	DepthOff  Depth = "off"  // Skip git context entirely
+	DepthFast Depth = "fast" // Branch and working tree status only
+	DepthFull Depth = "full" // Also recent commits, remote URL, and ahead/behind
+)
+
+// DefaultBudget is the total time allowed for git commands per invocation
+const DefaultBudget = 500 * time.Millisecond
+
+var (
+	depth  = DepthFull
+	budget = DefaultBudget
+)
+
+// Configure sets the depth and time budget used by GetContext.
+// Unknown depths fall back to full; a non-positive budget uses DefaultBudget.
+func Configure(d Depth, b time.Duration) {
+	switch d {
+	case DepthOff, DepthFast:
+		depth = d
+	default:
+		depth = DepthFull
+	}
+	if b <= 0 {
+		b = DefaultBudget
+	}
+	budget = b
+}
+
+// GetContext gathers git repository context from the current directory.
+// Git commands run concurrently; any still running when the budget
+// expires are killed and the context is marked Partial.
 func GetContext(cwd string) *Context {
 	ctx := &Context{}
+	if depth == DepthOff {
+		return ctx
+	}
 
 	// Check if we're in a git repository
 	gitDir := findGitDir(cwd)
@@ -42,26 +89,54 @@ func GetContext(cwd string) *Context {
 		return ctx
 	}
 	ctx.IsRepo = true
-
-	// Get current branch
-	ctx.Branch = getCurrentBranch(cwd)
-
-	// Check for uncommitted changes
-	ctx.HasUncommitted, ctx.HasStaged, ctx.HasUntracked = getWorkingTreeStatus(cwd)
+	ctx.Worktree, ctx.IsSubmodule = classifyGitDir(gitDir)
 
 	// Check for merge/rebase in progress
 	ctx.MergeInProgress = fileExists(filepath.Join(gitDir, "MERGE_HEAD"))
 	ctx.RebaseInProgress = fileExists(filepath.Join(gitDir, "rebase-merge")) ||
 		fileExists(filepath.Join(gitDir, "rebase-apply"))
 
-	// Get recent commits
-	ctx.RecentCommits = getRecentCommits(cwd, 5)
+	runCtx, cancel := context.WithTimeout(context.Background(), budget)
+	defer cancel()
+
+	// Each command fills in its own fields, so no locking is needed
+	var wg sync.WaitGroup
+	run := func(f func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f()
+		}()
+	}
 
-	// Get remote URL
-	ctx.RemoteURL = getRemoteURL(cwd)
+	// Get current branch, falling back to the commit for detached HEAD
+	run(func() {
+		ctx.Branch = getCurrentBranch(runCtx, cwd)
+		if ctx.Branch == "HEAD" {
+			ctx.Branch = ""
+			ctx.Detached = true
+			ctx.HeadSHA = getHeadSHA(runCtx, cwd)
+		}
+	})
 
-	// Get ahead/behind counts
-	ctx.Ahead, ctx.Behind = getAheadBehind(cwd)
+	// Check for uncommitted changes
+	run(func() {
+		ctx.HasUncommitted, ctx.HasStaged, ctx.HasUntracked, ctx.DirtySubmodules = getWorkingTreeStatus(runCtx, cwd)
+	})
+
+	if depth == DepthFull {
+		// Get recent commits
+		run(func() { ctx.RecentCommits = getRecentCommits(runCtx, cwd, 5) })
+
+		// Get remote URL
+		run(func() { ctx.RemoteURL = getRemoteURL(runCtx, cwd) })
+
+		// Get ahead/behind counts
+		run(func() { ctx.Ahead, ctx.Behind = getAheadBehind(runCtx, cwd) })
+	}
+
+	wg.Wait()
+	ctx.Partial = runCtx.Err() != nil
 
 	return ctx
 }
@@ -80,7 +155,12 @@ func findGitDir(cwd string) string {
 			if err == nil {
 				line := strings.TrimSpace(string(content))
 				if strings.HasPrefix(line, "gitdir: ") {
-					return strings.TrimPrefix(line, "gitdir: ")
+					gitDir := strings.TrimPrefix(line, "gitdir: ")
+					// Submodules use a path relative to the .git file
+					if !filepath.IsAbs(gitDir) {
+						gitDir = filepath.Join(dir, gitDir)
+					}
+					return filepath.Clean(gitDir)
 				}
 			}
 		}
@@ -93,64 +173,119 @@ func findGitDir(cwd string) string {
 	}
 }
 
-// getCurrentBranch returns the current branch name
-func getCurrentBranch(cwd string) string {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+// classifyGitDir detects linked worktrees (.git/worktrees/<name>) and
+// submodules (.git/modules/<path>) from the resolved git directory
+func classifyGitDir(gitDir string) (worktree string, submodule bool) {
+	slashed := filepath.ToSlash(gitDir)
+	if filepath.Base(filepath.Dir(gitDir)) == "worktrees" {
+		worktree = filepath.Base(gitDir)
+	}
+	submodule = strings.Contains(slashed, "/.git/modules/")
+	return worktree, submodule
+}
+
+// gitOutput runs a git command in cwd, killing it when ctx expires
+func gitOutput(ctx context.Context, cwd string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Dir = cwd
 	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// getCurrentBranch returns the current branch name
+func getCurrentBranch(ctx context.Context, cwd string) string {
+	out, err := gitOutput(ctx, cwd, "rev-parse", "--abbrev-ref", "HEAD")
 	if err != nil {
 		return ""
 	}
-	return strings.TrimSpace(string(out))
+	return strings.TrimSpace(out)
 }
 
-// getWorkingTreeStatus checks for uncommitted, staged, and untracked files
-func getWorkingTreeStatus(cwd string) (uncommitted, staged, untracked bool) {
-	cmd := exec.Command("git", "status", "--porcelain")
-	cmd.Dir = cwd
-	out, err := cmd.Output()
+// getHeadSHA returns the short SHA of HEAD
+func getHeadSHA(ctx context.Context, cwd string) string {
+	out, err := gitOutput(ctx, cwd, "rev-parse", "--short", "HEAD")
 	if err != nil {
-		return false, false, false
+		return ""
 	}
+	return strings.TrimSpace(out)
+}
 
-	lines := strings.Split(string(out), "\n")
-	for _, line := range lines {
-		if len(line) < 2 {
+// getWorkingTreeStatus checks for uncommitted, staged, and untracked files,
+// and lists submodules that have new commits or local changes
+func getWorkingTreeStatus(ctx context.Context, cwd string) (uncommitted, staged, untracked bool, dirtySubmodules []string) {
+	// Porcelain v2 reports submodule state alongside the usual XY codes
+	out, err := gitOutput(ctx, cwd, "status", "--porcelain=v2")
+	if err != nil {
+		return false, false, false, nil
+	}
+	return parseStatusV2(out)
+}
+
+// statusV2Fields is how many space-separated fields each kind of porcelain
+// v2 entry has, the path last. Splitting into exactly this many keeps
+// spaces in the path.
+var statusV2Fields = map[string]int{
+	"1": 9,  // 1 XY sub mH mI mW hH hI path
+	"2": 10, // 2 XY sub mH mI mW hH hI Xscore path<TAB>origPath
+	"u": 11, // u XY sub m1 m2 m3 mW h1 h2 h3 path
+}
+
+// parseStatusV2 reads git status --porcelain=v2 output
+func parseStatusV2(out string) (uncommitted, staged, untracked bool, dirtySubmodules []string) {
+	for _, line := range strings.Split(out, "\n") {
+		// Untracked files
+		if strings.HasPrefix(line, "? ") {
+			untracked = true
+			continue
+		}
+
+		kind, _, _ := strings.Cut(line, " ")
+		n, ok := statusV2Fields[kind]
+		if !ok {
+			continue
+		}
+		fields := strings.SplitN(line, " ", n)
+		if len(fields) != n || len(fields[1]) != 2 {
 			continue
 		}
-		indexStatus := line[0]
-		workTreeStatus := line[1]
+		indexStatus := fields[1][0]
+		workTreeStatus := fields[1][1]
 
 		// Staged changes (added to index)
-		if indexStatus != ' ' && indexStatus != '?' {
+		if indexStatus != '.' {
 			staged = true
 		}
 
 		// Uncommitted changes in working tree
-		if workTreeStatus != ' ' && workTreeStatus != '?' {
+		if workTreeStatus != '.' {
 			uncommitted = true
 		}
 
-		// Untracked files
-		if indexStatus == '?' && workTreeStatus == '?' {
-			untracked = true
+		// Submodule state "S<commit><modified><untracked>", e.g. "SC.." or "S.M."
+		if sub := fields[2]; strings.HasPrefix(sub, "S") && sub != "S..." {
+			path, _, _ := strings.Cut(fields[n-1], "\t") // A rename's original path follows a tab
+			if unquoted, err := strconv.Unquote(path); err == nil {
+				path = unquoted
+			}
+			dirtySubmodules = append(dirtySubmodules, path)
 		}
 	}
 
-	return uncommitted, staged, untracked
+	return uncommitted, staged, untracked, dirtySubmodules
 }
 
 // getRecentCommits returns the most recent commits
-func getRecentCommits(cwd string, count int) []Commit {
-	cmd := exec.Command("git", "log", "-n", strconv.Itoa(count), "--pretty=format:%h|%s|%an")
-	cmd.Dir = cwd
-	out, err := cmd.Output()
+func getRecentCommits(ctx context.Context, cwd string, count int) []Commit {
+	out, err := gitOutput(ctx, cwd, "log", "-n", strconv.Itoa(count), "--pretty=format:%h|%s|%an")
 	if err != nil {
 		return nil
 	}
 
 	var commits []Commit
-	lines := strings.Split(string(out), "\n")
+	lines := strings.Split(out, "\n")
 	for _, line := range lines {
 		parts := strings.SplitN(line, "|", 3)
 		if len(parts) == 3 {
@@ -166,26 +301,22 @@ func getRecentCommits(cwd string, count int) []Commit {
 }
 
 // getRemoteURL returns the origin remote URL
-func getRemoteURL(cwd string) string {
-	cmd := exec.Command("git", "remote", "get-url", "origin")
-	cmd.Dir = cwd
-	out, err := cmd.Output()
+func getRemoteURL(ctx context.Context, cwd string) string {
+	out, err := gitOutput(ctx, cwd, "remote", "get-url", "origin")
 	if err != nil {
 		return ""
 	}
-	return strings.TrimSpace(string(out))
+	return strings.TrimSpace(out)
 }
 
 // getAheadBehind returns the number of commits ahead/behind the remote
-func getAheadBehind(cwd string) (ahead, behind int) {
-	cmd := exec.Command("git", "rev-list", "--left-right", "--count", "@{upstream}...HEAD")
-	cmd.Dir = cwd
-	out, err := cmd.Output()
+func getAheadBehind(ctx context.Context, cwd string) (ahead, behind int) {
+	out, err := gitOutput(ctx, cwd, "rev-list", "--left-right", "--count", "@{upstream}...HEAD")
 	if err != nil {
 		return 0, 0
 	}
 
-	parts := strings.Fields(string(out))
+	parts := strings.Fields(out)
 	if len(parts) == 2 {
 		// Note: left-right gives us behind first, then ahead
 		behind, _ = strconv.Atoi(parts[0])
@@ -212,6 +343,16 @@ func (c *Context) Summary() string {
 	// Branch
 	if c.Branch != "" {
 		parts = append(parts, "branch: "+c.Branch)
+	} else if c.Detached {
+		parts = append(parts, "detached HEAD at "+c.HeadSHA)
+	}
+
+	// Repository layout
+	if c.Worktree != "" {
+		parts = append(parts, "worktree: "+c.Worktree)
+	}
+	if c.IsSubmodule {
+		parts = append(parts, "inside a submodule")
 	}
 
 	// Status
@@ -227,10 +368,15 @@ func (c *Context) Summary() string {
 	}
 	if len(status) > 0 {
 		parts = append(parts, strings.Join(status, ", "))
-	} else {
+	} else if !c.Partial {
+		// Status may be missing rather than clean when git timed out
 		parts = append(parts, "clean")
 	}
 
+	if len(c.DirtySubmodules) > 0 {
+		parts = append(parts, "dirty submodules: "+strings.Join(c.DirtySubmodules, ", "))
+	}
+
 	// Special states
 	if c.MergeInProgress {
 		parts = append(parts, "MERGE IN PROGRESS")
@@ -251,5 +397,9 @@ func (c *Context) Summary() string {
 		parts = append(parts, strings.Join(syncStatus, ", "))
 	}
 
+	if c.Partial {
+		parts = append(parts, "partial (git timed out)")
+	}
+
 	return strings.Join(parts, " | ")
 }
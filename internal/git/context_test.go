@@ -0,0 +1,42 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseStatusV2(t *testing.T) {
+	const hashes = "0000000000000000000000000000000000000000 1111111111111111111111111111111111111111"
+	tests := []struct {
+		name        string
+		out         string
+		uncommitted bool
+		staged      bool
+		untracked   bool
+		submodules  []string
+	}{
+		{"clean", "", false, false, false, nil},
+		{"untracked", "? notes.txt", false, false, true, nil},
+		{"ordinary staged", "1 M. N... 100644 100644 100644 " + hashes + " main.go", false, true, false, nil},
+		{"ordinary modified", "1 .M N... 100644 100644 100644 " + hashes + " docs/read me.md", true, false, false, nil},
+		{"renamed", "2 R. N... 100644 100644 100644 " + hashes + " R100 new name.go\told name.go", false, true, false, nil},
+		{"unmerged", "u UU N... 100644 100644 100644 100644 " + hashes + " 2222222222222222222222222222222222222222 conflict.go", true, true, false, nil},
+		{"submodule with spaces", "1 .M SC.. 160000 160000 160000 " + hashes + " vendor/my lib", true, false, false, []string{"vendor/my lib"}},
+		{"quoted submodule", "1 .M S.M. 160000 160000 160000 " + hashes + ` "vendor/caf\303\251"`, true, false, false, []string{"vendor/café"}},
+		{"renamed submodule", "2 R. SC.. 160000 160000 160000 " + hashes + " R100 libs/new lib\tlibs/old lib", false, true, false, []string{"libs/new lib"}},
+		{"clean submodule", "1 M. S... 160000 160000 160000 " + hashes + " vendor/lib", false, true, false, nil},
+		{"headers and truncated lines", "# branch.oid abc\n1 M.", false, false, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uncommitted, staged, untracked, submodules := parseStatusV2(tt.out)
+			if uncommitted != tt.uncommitted || staged != tt.staged || untracked != tt.untracked {
+				t.Errorf("parseStatusV2() = uncommitted %v, staged %v, untracked %v; want %v, %v, %v",
+					uncommitted, staged, untracked, tt.uncommitted, tt.staged, tt.untracked)
+			}
+			if !reflect.DeepEqual(submodules, tt.submodules) {
+				t.Errorf("dirty submodules = %q, want %q", submodules, tt.submodules)
+			}
+		})
+	}
+}
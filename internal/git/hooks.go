@@ -0,0 +1,57 @@
+package git
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+)
+
+// StagedDiff returns the staged changes as a unified diff without color
+// or context lines, as scanned by the pre-commit hook
+func StagedDiff(cwd string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), logTimeout)
+	defer cancel()
+	out, err := gitOutput(ctx, cwd, "diff", "--cached", "--no-color", "--no-ext-diff", "-U0")
+	if err != nil {
+		return "", commandError("git diff", err)
+	}
+	return out, nil
+}
+
+// HooksDir returns the directory git runs hooks from, honoring
+// core.hooksPath and worktrees
+func HooksDir(cwd string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), logTimeout)
+	defer cancel()
+	out, err := gitOutput(ctx, cwd, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", commandError("git rev-parse", err)
+	}
+	dir := strings.TrimSpace(out)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(cwd, dir)
+	}
+	return dir, nil
+}
+
+// ConfigValue returns a git config value for the repository at cwd, or ""
+// when it is unset
+func ConfigValue(cwd, key string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), logTimeout)
+	defer cancel()
+	out, err := gitOutput(ctx, cwd, "config", "--get", key)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// SetConfigValue sets a git config value in the repository's local config
+func SetConfigValue(cwd, key, value string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), logTimeout)
+	defer cancel()
+	if _, err := gitOutput(ctx, cwd, "config", "--local", key, value); err != nil {
+		return commandError("git config", err)
+	}
+	return nil
+}
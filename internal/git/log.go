@@ -0,0 +1,111 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// logTimeout bounds git commands run for history rather than prompt context,
+// which are not subject to the context budget
+const logTimeout = 10 * time.Second
+
+// Field and record separators for git log output, chosen to never appear
+// in commit messages
+const (
+	fieldSep  = "\x1f"
+	recordSep = "\x1e"
+)
+
+// LatestTag returns the most recent tag reachable from HEAD, or "" if the
+// repository has none
+func LatestTag(cwd string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), logTimeout)
+	defer cancel()
+	out, err := gitOutput(ctx, cwd, "describe", "--tags", "--abbrev=0")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// CommitsSince returns the commits after since (a tag, branch, or hash) up
+// to HEAD, newest first, with their message bodies. An empty since returns
+// the whole history. Merge commits are skipped.
+func CommitsSince(cwd, since string) ([]Commit, error) {
+	// A value starting with '-' would be read by git log as an option
+	if strings.HasPrefix(since, "-") {
+		return nil, fmt.Errorf("invalid revision %q: can't start with '-'", since)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), logTimeout)
+	defer cancel()
+
+	args := []string{"log", "--no-merges", "--pretty=format:%h" + fieldSep + "%s" + fieldSep + "%an" + fieldSep + "%b" + recordSep}
+	if since != "" {
+		args = append(args, since+"..HEAD")
+	}
+	out, err := gitOutput(ctx, cwd, args...)
+	if err != nil {
+		return nil, commandError("git log", err)
+	}
+
+	var commits []Commit
+	for _, record := range strings.Split(out, recordSep) {
+		parts := strings.SplitN(strings.TrimLeft(record, "\n"), fieldSep, 4)
+		if len(parts) != 4 {
+			continue
+		}
+		commits = append(commits, Commit{
+			Hash:    parts[0],
+			Subject: parts[1],
+			Author:  parts[2],
+			Body:    strings.TrimSpace(parts[3]),
+		})
+	}
+	return commits, nil
+}
+
+// WorkTreeStatus reports whether the work tree at cwd has changes to
+// tracked files and whether it has untracked files. Unlike GetContext it
+// runs git directly, with no time budget and regardless of git.context, and
+// returns an error when cwd isn't a work tree or git fails.
+func WorkTreeStatus(cwd string) (dirty, untracked bool, err error) {
+	ctx := context.Background()
+	out, err := gitOutput(ctx, cwd, "rev-parse", "--is-inside-work-tree")
+	if err != nil {
+		return false, false, commandError("git rev-parse", err)
+	}
+	if strings.TrimSpace(out) != "true" {
+		return false, false, fmt.Errorf("not inside a git work tree")
+	}
+
+	out, err = gitOutput(ctx, cwd, "status", "--porcelain")
+	if err != nil {
+		return false, false, commandError("git status", err)
+	}
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "??"):
+			untracked = true
+		default:
+			dirty = true
+		}
+	}
+	return dirty, untracked, nil
+}
+
+// commandError reports a failed git command by the first line of its
+// stderr, which names the actual problem
+func commandError(name string, err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		first, _, _ := strings.Cut(strings.TrimSpace(string(exitErr.Stderr)), "\n")
+		return fmt.Errorf("%s: %s", name, first)
+	}
+	return fmt.Errorf("%s: %w", name, err)
+}
@@ -0,0 +1,14 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCommitsSince_RejectsOptions(t *testing.T) {
+	for _, since := range []string{"--output=/tmp/x", "-p"} {
+		if _, err := CommitsSince(t.TempDir(), since); err == nil || !strings.Contains(err.Error(), "can't start with '-'") {
+			t.Errorf("CommitsSince(%q) error = %v, want it rejected", since, err)
+		}
+	}
+}
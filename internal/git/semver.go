@@ -0,0 +1,60 @@
+package git
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Bump is the part of a semantic version a release increments
+type Bump int
+
+const (
+	BumpPatch Bump = iota
+	BumpMinor
+	BumpMajor
+)
+
+// semverTag matches tags like v1.2.3 or 1.2.3-rc.1, capturing the
+// pre-release part
+var semverTag = regexp.MustCompile(`^(v?)(\d+)\.(\d+)\.(\d+)(-[^+]*)?(?:\+.*)?$`)
+
+// NextVersion returns the tag after latest for the given bump, keeping its
+// "v" prefix. Before 1.0.0 a major bump only increments the minor version.
+// A pre-release is promoted to its release when that is at least the bump,
+// so v1.3.0-rc.2 becomes v1.3.0 for a patch or minor bump. With no
+// previous tag it proposes v0.1.0; an unparseable tag is an error.
+func NextVersion(latest string, bump Bump) (string, error) {
+	if latest == "" {
+		return "v0.1.0", nil
+	}
+	m := semverTag.FindStringSubmatch(latest)
+	if m == nil {
+		return "", fmt.Errorf("latest tag %q is not a semantic version", latest)
+	}
+	major, _ := strconv.Atoi(m[2])
+	minor, _ := strconv.Atoi(m[3])
+	patch, _ := strconv.Atoi(m[4])
+
+	if bump == BumpMajor && major == 0 {
+		bump = BumpMinor
+	}
+	// A pre-release of the bumped version is released as is
+	if m[5] != "" {
+		switch {
+		case bump == BumpPatch,
+			bump == BumpMinor && patch == 0,
+			bump == BumpMajor && minor == 0 && patch == 0:
+			return fmt.Sprintf("%s%d.%d.%d", m[1], major, minor, patch), nil
+		}
+	}
+	switch bump {
+	case BumpMajor:
+		major, minor, patch = major+1, 0, 0
+	case BumpMinor:
+		minor, patch = minor+1, 0
+	default:
+		patch++
+	}
+	return fmt.Sprintf("%s%d.%d.%d", m[1], major, minor, patch), nil
+}
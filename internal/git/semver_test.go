@@ -0,0 +1,33 @@
+package git
+
+import "testing"
+
+func TestNextVersion(t *testing.T) {
+	tests := []struct {
+		latest string
+		bump   Bump
+		want   string
+	}{
+		{"v1.2.3", BumpPatch, "v1.2.4"},
+		{"v1.2.3", BumpMinor, "v1.3.0"},
+		{"v1.2.3", BumpMajor, "v2.0.0"},
+		{"0.4.1", BumpMajor, "0.5.0"},
+		{"v1.3.0-rc.2", BumpPatch, "v1.3.0"},
+		{"v1.3.0-rc.2", BumpMinor, "v1.3.0"},
+		{"v1.3.0-rc.2", BumpMajor, "v2.0.0"},
+		{"v2.0.0-beta.1", BumpMajor, "v2.0.0"},
+		{"v1.3.1-rc.1", BumpMinor, "v1.4.0"},
+		{"v1.3.0+build.5", BumpPatch, "v1.3.1"},
+		{"", BumpMajor, "v0.1.0"},
+	}
+	for _, tt := range tests {
+		got, err := NextVersion(tt.latest, tt.bump)
+		if err != nil || got != tt.want {
+			t.Errorf("NextVersion(%q, %d) = %q, %v; want %q", tt.latest, tt.bump, got, err, tt.want)
+		}
+	}
+
+	if _, err := NextVersion("nightly", BumpPatch); err == nil {
+		t.Error("expected an error for a non-semver tag")
+	}
+}
@@ -0,0 +1,325 @@
+// Package importer reads settings from other terminal AI tools so they can
+// be carried over into bast.
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Sources lists the tools settings can be imported from
+var Sources = []string{"warp", "fig", "aichat", "shell-gpt"}
+
+// Settings is what could be read from another tool's files
+type Settings struct {
+	Source string
+
+	// Model is the preferred Anthropic model, or empty. Models from other
+	// providers are reported in Skipped instead.
+	Model string
+
+	// APIKey is an Anthropic API key and KeyFile the file it was read from
+	APIKey  string
+	KeyFile string
+
+	// Commands are aliases and saved workflows, imported as plugins
+	Commands []Command
+
+	// Files lists the files that were read
+	Files []string
+
+	// Skipped explains settings that were found but do not map to bast
+	Skipped []string
+}
+
+// Command is a named shell command from another tool
+type Command struct {
+	Name        string
+	Description string
+	Command     string   // with ${BAST_PARAM_*} references to the parameters
+	Parameters  []string // parameter names, lowercase
+	Alias       bool     // a shell alias rather than a saved workflow
+}
+
+// paramRef returns the shell reference to a plugin parameter. Plugins get
+// each parameter as a BAST_PARAM_* environment variable; referring to that
+// instead of a $PARAM placeholder keeps the value out of the command text,
+// so the shell never parses it as code.
+func paramRef(name string) string {
+	return "${BAST_PARAM_" + strings.ToUpper(name) + "}"
+}
+
+// Load reads the settings of source from under home. It returns an error
+// naming the paths it looked in when none of them exist.
+func Load(source, home string) (*Settings, error) {
+	s := &Settings{Source: source}
+	var looked []string
+	var err error
+	switch source {
+	case "warp":
+		looked, err = s.loadWarp(home)
+	case "fig":
+		looked, err = s.loadFig(home)
+	case "aichat":
+		looked, err = s.loadAichat(home)
+	case "shell-gpt":
+		looked, err = s.loadShellGPT(home)
+	default:
+		return nil, fmt.Errorf("unknown source %q (expected one of: %s)", source, strings.Join(Sources, ", "))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(s.Files) == 0 {
+		return nil, fmt.Errorf("no %s settings found (looked in %s)", source, strings.Join(looked, ", "))
+	}
+	return s, nil
+}
+
+// warpWorkflow is a Warp workflow file
+type warpWorkflow struct {
+	Name        string `yaml:"name"`
+	Command     string `yaml:"command"`
+	Description string `yaml:"description"`
+	Arguments   []struct {
+		Name string `yaml:"name"`
+	} `yaml:"arguments"`
+}
+
+// warpArgument matches a {{argument}} placeholder in a Warp workflow
+var warpArgument = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]+)\s*\}\}`)
+
+// loadWarp imports Warp workflows. Warp keeps AI and model settings in
+// its own account, so there is nothing else to read.
+func (s *Settings) loadWarp(home string) ([]string, error) {
+	dirs := []string{
+		filepath.Join(home, ".warp", "workflows"),
+		filepath.Join(home, ".local", "share", "warp-terminal", "workflows"),
+	}
+	for _, dir := range dirs {
+		paths, _ := filepath.Glob(filepath.Join(dir, "*.y*ml"))
+		for _, path := range paths {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			var wf warpWorkflow
+			if err := yaml.Unmarshal(data, &wf); err != nil || wf.Command == "" {
+				s.Skipped = append(s.Skipped, fmt.Sprintf("%s: not a workflow file", path))
+				continue
+			}
+			s.Files = append(s.Files, path)
+
+			name := wf.Name
+			if name == "" {
+				name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+			}
+			cmd := Command{Name: name, Description: wf.Description}
+			cmd.Command = warpArgument.ReplaceAllStringFunc(wf.Command, func(m string) string {
+				param := strings.ToLower(warpArgument.FindStringSubmatch(m)[1])
+				cmd.Parameters = appendUnique(cmd.Parameters, param)
+				return `"` + paramRef(param) + `"`
+			})
+			if cmd.Description == "" {
+				cmd.Description = fmt.Sprintf("Warp workflow: %s", wf.Command)
+			}
+			s.Commands = append(s.Commands, cmd)
+		}
+	}
+	return dirs, nil
+}
+
+// shellAlias matches alias name='expansion' (or "expansion", or bare)
+var shellAlias = regexp.MustCompile(`^\s*alias\s+([A-Za-z0-9_.:-]+)=(?:'([^']*)'|"([^"]*)"|(\S+))\s*$`)
+
+// loadFig imports aliases from Fig's dotfiles. Fig's settings.json only
+// covers its autocomplete UI, which has no bast equivalent.
+func (s *Settings) loadFig(home string) ([]string, error) {
+	dir := filepath.Join(home, ".fig", "user", "dotfiles")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return []string{dir}, nil
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		found := false
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			m := shellAlias.FindStringSubmatch(scanner.Text())
+			if m == nil {
+				continue
+			}
+			expansion := m[2] + m[3] + m[4]
+			s.Commands = append(s.Commands, Command{
+				Name:        m[1],
+				Description: fmt.Sprintf("Fig alias %s='%s'. Extra arguments go in args.", m[1], expansion),
+				// Unquoted, so args splits into words, but is never run as code
+				Command:    expansion + " " + paramRef("args"),
+				Parameters: []string{"args"},
+				Alias:      true,
+			})
+			found = true
+		}
+		f.Close()
+		if found {
+			s.Files = append(s.Files, path)
+		}
+	}
+	return []string{dir}, nil
+}
+
+// aichatConfig is the part of aichat's config.yaml that maps to bast
+type aichatConfig struct {
+	Model   string `yaml:"model"`
+	Clients []struct {
+		Type   string `yaml:"type"`
+		APIKey string `yaml:"api_key"`
+	} `yaml:"clients"`
+}
+
+// loadAichat imports aichat's model and Claude client key
+func (s *Settings) loadAichat(home string) ([]string, error) {
+	var paths []string
+	if dir := os.Getenv("AICHAT_CONFIG_DIR"); dir != "" {
+		paths = append(paths, filepath.Join(dir, "config.yaml"))
+	}
+	paths = append(paths,
+		filepath.Join(home, ".config", "aichat", "config.yaml"),
+		filepath.Join(home, "Library", "Application Support", "aichat", "config.yaml"),
+	)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var cfg aichatConfig
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		s.Files = append(s.Files, path)
+
+		// Models are "client:model", e.g. "claude:claude-3-5-sonnet-20240620"
+		if cfg.Model != "" {
+			client, model, ok := strings.Cut(cfg.Model, ":")
+			if ok && client == "claude" {
+				s.Model = model
+			} else {
+				s.Skipped = append(s.Skipped, fmt.Sprintf("model %s is not an Anthropic model", cfg.Model))
+			}
+		}
+		for _, c := range cfg.Clients {
+			if c.Type == "claude" && c.APIKey != "" {
+				s.APIKey, s.KeyFile = c.APIKey, path
+			}
+		}
+		break
+	}
+	return paths, nil
+}
+
+// loadShellGPT imports shell-gpt's default model and any Anthropic key
+// from .sgptrc. OpenAI keys are reported as skipped.
+func (s *Settings) loadShellGPT(home string) ([]string, error) {
+	path := filepath.Join(home, ".config", "shell_gpt", ".sgptrc")
+	f, err := os.Open(path)
+	if err != nil {
+		return []string{path}, nil
+	}
+	defer f.Close()
+	s.Files = append(s.Files, path)
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok || strings.HasPrefix(key, "#") {
+			continue
+		}
+		value = strings.Trim(value, `"'`)
+		switch key {
+		case "DEFAULT_MODEL":
+			// LiteLLM names Anthropic models "anthropic/claude-..." or "claude-..."
+			model := strings.TrimPrefix(value, "anthropic/")
+			if strings.HasPrefix(model, "claude") {
+				s.Model = model
+			} else {
+				s.Skipped = append(s.Skipped, fmt.Sprintf("model %s is not an Anthropic model", value))
+			}
+		case "ANTHROPIC_API_KEY", "OPENAI_API_KEY":
+			if strings.HasPrefix(value, "sk-ant-") {
+				s.APIKey, s.KeyFile = value, path
+			} else if value != "" {
+				s.Skipped = append(s.Skipped, fmt.Sprintf("%s is not an Anthropic key", key))
+			}
+		}
+	}
+	return []string{path}, scanner.Err()
+}
+
+// toolName matches characters allowed in a tool name
+var toolName = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// ToolName turns a command name into a plugin tool name, e.g.
+// "Uncompress a tar file" becomes "uncompress_a_tar_file"
+func ToolName(name string) string {
+	n := strings.Trim(toolName.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if len(n) > 64 {
+		n = strings.TrimRight(n[:64], "_")
+	}
+	return n
+}
+
+// pluginManifest is the subset of a plugin manifest written for imports
+type pluginManifest struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description"`
+	Command     string            `yaml:"command"`
+	Parameters  []pluginParameter `yaml:"parameters"`
+}
+
+type pluginParameter struct {
+	Name        string `yaml:"name"`
+	Type        string `yaml:"type"`
+	Description string `yaml:"description"`
+	Required    bool   `yaml:"required"`
+}
+
+// Manifest renders c as a plugin manifest for ~/.config/bast/tools
+func (c Command) Manifest() ([]byte, error) {
+	m := pluginManifest{
+		Name:        ToolName(c.Name),
+		Description: c.Description,
+		Command:     c.Command,
+		Parameters:  []pluginParameter{},
+	}
+	for _, p := range c.Parameters {
+		m.Parameters = append(m.Parameters, pluginParameter{
+			Name:        p,
+			Type:        "string",
+			Description: p,
+			Required:    p != "args",
+		})
+	}
+	return yaml.Marshal(m)
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
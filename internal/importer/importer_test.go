@@ -0,0 +1,166 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/bastio-ai/bast/internal/tools"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadAichat(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("AICHAT_CONFIG_DIR", "")
+	writeFile(t, filepath.Join(home, ".config", "aichat", "config.yaml"), `model: claude:claude-sonnet-4-20250514
+clients:
+- type: openai
+  api_key: sk-openai
+- type: claude
+  api_key: sk-ant-test
+`)
+	s, err := Load("aichat", home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Model != "claude-sonnet-4-20250514" || s.APIKey != "sk-ant-test" {
+		t.Errorf("model = %q, key = %q", s.Model, s.APIKey)
+	}
+}
+
+func TestLoadShellGPT(t *testing.T) {
+	home := t.TempDir()
+	writeFile(t, filepath.Join(home, ".config", "shell_gpt", ".sgptrc"), `DEFAULT_MODEL=gpt-4o
+OPENAI_API_KEY=sk-proj-abc
+`)
+	s, err := Load("shell-gpt", home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Model != "" || s.APIKey != "" {
+		t.Errorf("imported non-Anthropic settings: model = %q, key = %q", s.Model, s.APIKey)
+	}
+	if len(s.Skipped) != 2 {
+		t.Errorf("skipped = %v, want the model and key", s.Skipped)
+	}
+}
+
+func TestLoadWarp(t *testing.T) {
+	home := t.TempDir()
+	writeFile(t, filepath.Join(home, ".warp", "workflows", "untar.yaml"), `name: Uncompress a tar file
+command: "tar -xvzf {{tar_file}} -C {{ dir }}"
+arguments:
+  - name: tar_file
+  - name: dir
+`)
+	s, err := Load("warp", home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Commands) != 1 {
+		t.Fatalf("commands = %+v", s.Commands)
+	}
+	c := s.Commands[0]
+	if c.Command != `tar -xvzf "${BAST_PARAM_TAR_FILE}" -C "${BAST_PARAM_DIR}"` || !reflect.DeepEqual(c.Parameters, []string{"tar_file", "dir"}) {
+		t.Errorf("command = %q, parameters = %v", c.Command, c.Parameters)
+	}
+
+	data, err := c.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m pluginManifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Name != "uncompress_a_tar_file" || len(m.Parameters) != 2 || !m.Parameters[0].Required {
+		t.Errorf("manifest = %+v", m)
+	}
+}
+
+func TestLoadFig(t *testing.T) {
+	home := t.TempDir()
+	writeFile(t, filepath.Join(home, ".fig", "user", "dotfiles", "aliases.zsh"), `# aliases
+alias gs='git status'
+alias k=kubectl
+export EDITOR=vim
+`)
+	s, err := Load("fig", home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Commands) != 2 || s.Commands[0].Command != "git status ${BAST_PARAM_ARGS}" || s.Commands[1].Name != "k" {
+		t.Errorf("commands = %+v", s.Commands)
+	}
+	if !s.Commands[0].Alias || !s.Commands[1].Alias {
+		t.Errorf("commands = %+v, want them marked as aliases", s.Commands)
+	}
+}
+
+func TestAliasArgsAreNotCode(t *testing.T) {
+	home := t.TempDir()
+	writeFile(t, filepath.Join(home, ".fig", "user", "dotfiles", "aliases.zsh"), "alias say=echo\n")
+	s, err := Load("fig", home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := s.Commands[0].Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "fig-say.yaml"), string(data))
+	plugins, err := tools.LoadPlugins(dir)
+	if err != nil || len(plugins) != 1 {
+		t.Fatalf("LoadPlugins = %v, %v", plugins, err)
+	}
+
+	result, err := plugins[0].Execute(context.Background(), json.RawMessage(`{"args": "hi; echo $(id -u) > pwned"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(result.Output); got != "hi; echo $(id -u) > pwned" {
+		t.Errorf("output = %q, want the args echoed as plain words", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pwned")); err == nil {
+		t.Error("args were run as shell code")
+	}
+}
+
+func TestLoadNotFound(t *testing.T) {
+	_, err := Load("shell-gpt", t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), ".sgptrc") {
+		t.Errorf("err = %v, want the paths looked in", err)
+	}
+	if _, err := Load("iterm", t.TempDir()); err == nil {
+		t.Error("expected an error for an unknown source")
+	}
+}
+
+func TestToolName(t *testing.T) {
+	tests := map[string]string{
+		"Uncompress a tar file": "uncompress_a_tar_file",
+		"git-log (pretty)":      "git_log_pretty",
+		"ll":                    "ll",
+	}
+	for in, want := range tests {
+		if got := ToolName(in); got != want {
+			t.Errorf("ToolName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+// Package lifecycle runs the user's hook scripts at points in an agent task,
+// with the event as JSON on stdin, for logging, notifications, or policy
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/bastio-ai/bast/internal/config"
+	"github.com/bastio-ai/bast/internal/debug"
+	"github.com/bastio-ai/bast/internal/tools"
+)
+
+// DefaultTimeout bounds each hook command when hooks.timeout is unset
+const DefaultTimeout = 5 * time.Second
+
+// maxReason caps how much of a blocking hook's output is passed on as the reason
+const maxReason = 500
+
+// Event is a point in the agent lifecycle that hooks run at
+type Event string
+
+const (
+	EventSessionStart Event = "on_session_start" // An agent task starts
+	EventBeforeTool   Event = "before_tool"      // A tool call is about to be validated and run
+	EventAfterTool    Event = "after_tool"       // A tool call has a result
+	EventSessionEnd   Event = "on_session_end"   // An agent task finished or failed
+)
+
+// Payload is the JSON a hook reads on stdin. Fields that don't apply to
+// the event are left out.
+type Payload struct {
+	Event     Event       `json:"event"`
+	SessionID string      `json:"session_id"`
+	Time      time.Time   `json:"time"`
+	CWD       string      `json:"cwd,omitempty"`
+	Query     string      `json:"query,omitempty"`
+	Tool      *ToolCall   `json:"tool,omitempty"`
+	Result    *ToolResult `json:"result,omitempty"`
+	Outcome   *Outcome    `json:"outcome,omitempty"`
+}
+
+// ToolCall is the call a before_tool or after_tool hook runs for
+type ToolCall struct {
+	ID    string          `json:"id"`
+	Name  string          `json:"name"`
+	Input json.RawMessage `json:"input"`
+}
+
+// ToolResult is what the model is given back for a call
+type ToolResult struct {
+	Output     string `json:"output"`
+	IsError    bool   `json:"is_error"`
+	ExitCode   *int   `json:"exit_code,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// Outcome is how an agent task ended
+type Outcome struct {
+	Status     string `json:"status,omitempty"` // The agent's own verdict: success, partial, or failed
+	Summary    string `json:"summary,omitempty"`
+	Error      string `json:"error,omitempty"` // Set when the run itself failed
+	ToolCalls  int    `json:"tool_calls"`
+	Iterations int    `json:"iterations"`
+}
+
+// Hooks runs the configured commands for one agent task
+type Hooks struct {
+	commands  map[Event][]string
+	timeout   time.Duration
+	sessionID string
+	dir       string
+}
+
+// New returns the hooks in cfg for a task in session sessionID, run in
+// dir. It returns nil when no hooks are configured.
+func New(cfg config.HooksConfig, sessionID, dir string) *Hooks {
+	commands := map[Event][]string{
+		EventSessionStart: cfg.OnSessionStart,
+		EventBeforeTool:   cfg.BeforeTool,
+		EventAfterTool:    cfg.AfterTool,
+		EventSessionEnd:   cfg.OnSessionEnd,
+	}
+	configured := false
+	for _, c := range commands {
+		configured = configured || len(c) > 0
+	}
+	if !configured {
+		return nil
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Hooks{commands: commands, timeout: timeout, sessionID: sessionID, dir: dir}
+}
+
+// SessionStart runs the on_session_start hooks for a task
+func (h *Hooks) SessionStart(ctx context.Context, query string) {
+	h.run(ctx, Payload{Event: EventSessionStart, Query: query})
+}
+
+// SessionEnd runs the on_session_end hooks for a task
+func (h *Hooks) SessionEnd(ctx context.Context, query string, outcome Outcome) {
+	h.run(ctx, Payload{Event: EventSessionEnd, Query: query, Outcome: &outcome})
+}
+
+// BeforeCall runs the before_tool hooks. A hook that exits nonzero or
+// times out blocks the call, with its output as the reason.
+func (h *Hooks) BeforeCall(ctx context.Context, call tools.Call) error {
+	return h.run(ctx, Payload{Event: EventBeforeTool, Tool: toolCall(call)})
+}
+
+// AfterCall runs the after_tool hooks with the call's result
+func (h *Hooks) AfterCall(ctx context.Context, call tools.Call, result tools.CallResult) {
+	h.run(ctx, Payload{
+		Event: EventAfterTool,
+		Tool:  toolCall(call),
+		Result: &ToolResult{
+			Output:     result.Content,
+			IsError:    result.IsError,
+			ExitCode:   result.ExitCode,
+			DurationMS: result.Duration.Milliseconds(),
+		},
+	})
+}
+
+func toolCall(call tools.Call) *ToolCall {
+	return &ToolCall{ID: call.ID, Name: call.Name, Input: call.Input}
+}
+
+// run sends p to each command for its event, in order. Only before_tool
+// failures are returned, and they stop the remaining commands; failures of
+// other hooks are logged, since they can't change what already happened.
+func (h *Hooks) run(ctx context.Context, p Payload) error {
+	if h == nil || len(h.commands[p.Event]) == 0 {
+		return nil
+	}
+	p.SessionID = h.sessionID
+	p.Time = time.Now()
+	p.CWD = h.dir
+	data, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", p.Event, err)
+	}
+
+	for _, command := range h.commands[p.Event] {
+		err := h.exec(ctx, p.Event, command, data)
+		if err == nil {
+			continue
+		}
+		if p.Event == EventBeforeTool {
+			return err
+		}
+		debug.Logf("%s hook %q failed: %v", p.Event, command, err)
+	}
+	return nil
+}
+
+// exec runs one hook command with data on stdin
+func (h *Hooks) exec(ctx context.Context, event Event, command string, data []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = h.dir
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Env = append(os.Environ(), "BAST_HOOK_EVENT="+string(event), "BAST_SESSION_ID="+h.sessionID)
+	cmd.WaitDelay = time.Second
+	out, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("hook timed out after %s", h.timeout)
+	}
+	if err != nil {
+		if reason := strings.TrimSpace(string(out)); reason != "" {
+			if len(reason) > maxReason {
+				reason = reason[:maxReason] + "..."
+			}
+			return fmt.Errorf("%s", reason)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bastio-ai/bast/internal/config"
+	"github.com/bastio-ai/bast/internal/tools"
+)
+
+func TestNewWithoutHooks(t *testing.T) {
+	h := New(config.HooksConfig{}, "session", t.TempDir())
+	if h != nil {
+		t.Fatalf("New(empty) = %+v, want nil", h)
+	}
+	// A nil Hooks is safe to call
+	h.SessionStart(context.Background(), "query")
+	if err := h.BeforeCall(context.Background(), tools.Call{Name: "run_command"}); err != nil {
+		t.Errorf("nil BeforeCall() = %v", err)
+	}
+}
+
+func TestHooksPayload(t *testing.T) {
+	dir := t.TempDir()
+	log := filepath.Join(dir, "events.jsonl")
+	record := `cat >> ` + log + ` && echo >> ` + log
+	h := New(config.HooksConfig{
+		OnSessionStart: []string{record},
+		AfterTool:      []string{record, "exit 1"}, // A failing after_tool hook changes nothing
+		OnSessionEnd:   []string{record},
+	}, "abc-123", dir)
+
+	ctx := context.Background()
+	call := tools.Call{ID: "call-1", Name: "run_command", Input: json.RawMessage(`{"command":"ls"}`)}
+	h.SessionStart(ctx, "list files")
+	if err := h.BeforeCall(ctx, call); err != nil {
+		t.Fatalf("BeforeCall() with no before_tool hooks = %v", err)
+	}
+	h.AfterCall(ctx, call, tools.CallResult{Content: "a.txt", Duration: 1500 * time.Millisecond})
+	h.SessionEnd(ctx, "list files", Outcome{Status: "success", ToolCalls: 1, Iterations: 2})
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []Payload
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var p Payload
+		if err := json.Unmarshal([]byte(line), &p); err != nil {
+			t.Fatalf("hook got invalid JSON %q: %v", line, err)
+		}
+		events = append(events, p)
+	}
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3: %s", len(events), data)
+	}
+	if events[0].Event != EventSessionStart || events[0].Query != "list files" || events[0].SessionID != "abc-123" || events[0].CWD != dir {
+		t.Errorf("start event = %+v", events[0])
+	}
+	if e := events[1]; e.Event != EventAfterTool || e.Tool == nil || e.Tool.Name != "run_command" ||
+		e.Result == nil || e.Result.Output != "a.txt" || e.Result.DurationMS != 1500 {
+		t.Errorf("after_tool event = %+v", e)
+	}
+	if e := events[2]; e.Event != EventSessionEnd || e.Outcome == nil || e.Outcome.Status != "success" || e.Outcome.Iterations != 2 {
+		t.Errorf("end event = %+v", e)
+	}
+}
+
+func TestBeforeToolBlocks(t *testing.T) {
+	call := tools.Call{ID: "call-1", Name: "run_command", Input: json.RawMessage(`{"command":"rm -rf build"}`)}
+	tests := []struct {
+		name    string
+		hook    string
+		wantErr string
+	}{
+		{"allowed", `grep -q '"run_command"'`, ""},
+		{"blocked with reason", `grep -q 'rm -rf' && { echo "no rm -rf, use trash"; exit 1; }; exit 0`, "no rm -rf, use trash"},
+		{"blocked without output", "exit 3", "exit status 3"},
+		{"timed out", "sleep 5", "timed out"},
+		{"env", `test "$BAST_HOOK_EVENT" = before_tool && test "$BAST_SESSION_ID" = s1`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New(config.HooksConfig{BeforeTool: []string{tt.hook}, Timeout: 200 * time.Millisecond}, "s1", t.TempDir())
+			err := h.BeforeCall(context.Background(), call)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("BeforeCall() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("BeforeCall() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+// Package notify gets the user's attention when bast needs a decision or
+// finishes a long task, with a terminal bell or a flash of the frame
+package notify
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bastio-ai/bast/internal/config"
+)
+
+// Event is something the user may want to be told about
+type Event string
+
+const (
+	EventDanger   Event = "danger"   // A dangerous command waits for confirmation
+	EventApproval Event = "approval" // The agent or attached files wait for approval
+	EventDone     Event = "done"     // An agent task finished
+)
+
+// Alert is how an event is signalled
+type Alert struct {
+	Audible bool // Ring the terminal bell
+	Visual  bool // Flash the frame
+}
+
+// Notifier decides how each event is signalled
+type Notifier struct {
+	bell    string
+	events  []Event // Empty means every event
+	quiet   *window
+	canRing bool
+}
+
+// window is a daily span of time, which may cross midnight
+type window struct {
+	start, end int // Minutes since midnight
+}
+
+// New builds a Notifier from config. canRing is whether the terminal can
+// sound a bell (see TerminalCanRing); when it can't, audible alerts flash
+// instead.
+func New(cfg config.NotifyConfig, canRing bool) (*Notifier, error) {
+	n := &Notifier{bell: cfg.Bell, canRing: canRing}
+	switch cfg.Bell {
+	case "", config.BellOff, config.BellAudible, config.BellVisual, config.BellBoth:
+	default:
+		return nil, fmt.Errorf("invalid notify.bell %q: use %q, %q, %q, or %q",
+			cfg.Bell, config.BellOff, config.BellAudible, config.BellVisual, config.BellBoth)
+	}
+	for _, e := range cfg.Events {
+		switch ev := Event(e); ev {
+		case EventDanger, EventApproval, EventDone:
+			n.events = append(n.events, ev)
+		default:
+			return nil, fmt.Errorf("invalid notify event %q: use %q, %q, or %q", e, EventDanger, EventApproval, EventDone)
+		}
+	}
+	if cfg.QuietHours != "" {
+		w, err := parseWindow(cfg.QuietHours)
+		if err != nil {
+			return nil, fmt.Errorf("invalid notify.quiet_hours: %w", err)
+		}
+		n.quiet = w
+	}
+	return n, nil
+}
+
+// For returns how ev should be signalled at now. During quiet hours the
+// bell is muted and only the frame flashes.
+func (n *Notifier) For(ev Event, now time.Time) Alert {
+	if n == nil || len(n.events) > 0 && !slices.Contains(n.events, ev) {
+		return Alert{}
+	}
+	var alert Alert
+	switch n.bell {
+	case config.BellAudible:
+		alert.Audible = true
+	case config.BellVisual:
+		alert.Visual = true
+	case config.BellBoth:
+		alert.Audible, alert.Visual = true, true
+	}
+	if alert.Audible && (!n.canRing || n.quiet.contains(now)) {
+		alert.Audible, alert.Visual = false, true
+	}
+	return alert
+}
+
+// contains reports whether t falls in the window. A nil window contains nothing.
+func (w *window) contains(t time.Time) bool {
+	if w == nil {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	if w.start <= w.end {
+		return minute >= w.start && minute < w.end
+	}
+	return minute >= w.start || minute < w.end
+}
+
+// parseWindow parses a span like "22:00-07:00" in local time
+func parseWindow(s string) (*window, error) {
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return nil, fmt.Errorf("%q is not a range like 22:00-07:00", s)
+	}
+	start, err := parseClock(from)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return nil, err
+	}
+	if start == end {
+		return nil, fmt.Errorf("%q starts and ends at the same time", s)
+	}
+	return &window{start: start, end: end}, nil
+}
+
+// parseClock parses "HH:MM" into minutes since midnight
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a time like 07:30", strings.TrimSpace(s))
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// TerminalCanRing reports whether the controlling terminal can sound a
+// bell: there is one, and TERM is set to something other than "dumb"
+func TerminalCanRing() bool {
+	if term := os.Getenv("TERM"); term == "" || term == "dumb" {
+		return false
+	}
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return false
+	}
+	tty.Close()
+	return true
+}
+
+// Ring sounds the terminal bell. It writes to the controlling terminal
+// rather than stdout, which the TUI is drawing on.
+func Ring() error {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer tty.Close()
+	_, err = tty.WriteString("\a")
+	return err
+}
@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bastio-ai/bast/internal/config"
+)
+
+func at(hour, minute int) time.Time {
+	return time.Date(2026, 1, 2, hour, minute, 0, 0, time.Local)
+}
+
+func TestFor(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.NotifyConfig
+		canRing bool
+		ev      Event
+		now     time.Time
+		want    Alert
+	}{
+		{"off", config.NotifyConfig{Bell: config.BellOff}, true, EventDone, at(12, 0), Alert{}},
+		{"unset", config.NotifyConfig{}, true, EventDone, at(12, 0), Alert{}},
+		{"audible", config.NotifyConfig{Bell: config.BellAudible}, true, EventDanger, at(12, 0), Alert{Audible: true}},
+		{"visual", config.NotifyConfig{Bell: config.BellVisual}, true, EventApproval, at(12, 0), Alert{Visual: true}},
+		{"both", config.NotifyConfig{Bell: config.BellBoth}, true, EventDone, at(12, 0), Alert{Audible: true, Visual: true}},
+		{"no bell in terminal", config.NotifyConfig{Bell: config.BellAudible}, false, EventDone, at(12, 0), Alert{Visual: true}},
+		{"event not chosen", config.NotifyConfig{Bell: config.BellAudible, Events: []string{"danger"}}, true, EventDone, at(12, 0), Alert{}},
+		{"event chosen", config.NotifyConfig{Bell: config.BellAudible, Events: []string{"danger"}}, true, EventDanger, at(12, 0), Alert{Audible: true}},
+		{"quiet hours", config.NotifyConfig{Bell: config.BellAudible, QuietHours: "22:00-07:00"}, true, EventDone, at(23, 30), Alert{Visual: true}},
+		{"after midnight", config.NotifyConfig{Bell: config.BellAudible, QuietHours: "22:00-07:00"}, true, EventDone, at(6, 59), Alert{Visual: true}},
+		{"quiet hours over", config.NotifyConfig{Bell: config.BellAudible, QuietHours: "22:00-07:00"}, true, EventDone, at(7, 0), Alert{Audible: true}},
+		{"daytime quiet hours", config.NotifyConfig{Bell: config.BellBoth, QuietHours: "12:00-13:30"}, true, EventDone, at(13, 0), Alert{Visual: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := New(tt.cfg, tt.canRing)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if got := n.For(tt.ev, tt.now); got != tt.want {
+				t.Errorf("For() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFor_NilNotifier(t *testing.T) {
+	var n *Notifier
+	if got := n.For(EventDone, at(12, 0)); got != (Alert{}) {
+		t.Errorf("For() = %+v, want no alert", got)
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	tests := []config.NotifyConfig{
+		{Bell: "loud"},
+		{Bell: config.BellAudible, Events: []string{"finished"}},
+		{Bell: config.BellAudible, QuietHours: "22:00"},
+		{Bell: config.BellAudible, QuietHours: "10pm-7am"},
+		{Bell: config.BellAudible, QuietHours: "07:00-07:00"},
+	}
+	for _, cfg := range tests {
+		if _, err := New(cfg, true); err == nil {
+			t.Errorf("New(%+v) succeeded, want error", cfg)
+		}
+	}
+}
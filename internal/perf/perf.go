@@ -0,0 +1,39 @@
+// Package perf checks benchmarks against time budgets, so a slow regression
+// in a hot path (run on every keystroke or request) fails the tests.
+package perf
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// EnvVar enables budget checks. They are skipped otherwise, since timings
+// are only meaningful on an idle machine without -race or coverage.
+const EnvVar = "BAST_PERF"
+
+// Budget is a benchmark and the most time one iteration may take
+type Budget struct {
+	Name  string
+	Bench func(b *testing.B)
+	Max   time.Duration
+}
+
+// Check runs each benchmark and fails the ones over budget. Budgets are set
+// well above measured times, so only significant regressions trip them.
+func Check(t *testing.T, budgets []Budget) {
+	t.Helper()
+	if os.Getenv(EnvVar) == "" {
+		t.Skipf("set %s=1 to check performance budgets", EnvVar)
+	}
+	for _, budget := range budgets {
+		t.Run(budget.Name, func(t *testing.T) {
+			result := testing.Benchmark(budget.Bench)
+			got := time.Duration(result.NsPerOp())
+			if got > budget.Max {
+				t.Errorf("%s took %v per op, over its %v budget", budget.Name, got, budget.Max)
+			}
+			t.Logf("%v per op (budget %v)", got, budget.Max)
+		})
+	}
+}
@@ -0,0 +1,390 @@
+// Package probe takes a snapshot of how loaded the machine is (CPU load,
+// memory pressure, disk IO, and thermal throttling on macOS) for questions
+// about why something is slow. Probes are opt-in (context.probes) since
+// they describe the machine rather than the project.
+package probe
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bastio-ai/bast/internal/ai"
+	"github.com/bastio-ai/bast/internal/debug"
+	"github.com/bastio-ai/bast/internal/units"
+)
+
+// Timeout bounds all probes together; any still running are dropped
+const Timeout = 2 * time.Second
+
+// diskSampleInterval is how long disk counters are watched on Linux
+const diskSampleInterval = 500 * time.Millisecond
+
+// Probe names, in the order readings are listed
+const (
+	NameCPU     = "CPU load"
+	NameMemory  = "Memory pressure"
+	NameDisk    = "Disk IO"
+	NameThermal = "Thermal state"
+)
+
+// probe reads one measurement on one OS
+type probe struct {
+	name string
+	read func(ctx context.Context) (string, error)
+}
+
+// probes are the measurements taken on each OS
+var probes = map[string][]probe{
+	"linux": {
+		{NameCPU, func(context.Context) (string, error) { return readFile("/proc/loadavg", parseLoadavg) }},
+		{NameMemory, readLinuxMemory},
+		{NameDisk, readLinuxDisk},
+	},
+	"darwin": {
+		{NameCPU, func(ctx context.Context) (string, error) {
+			return runParse(ctx, parseLoadavg, "sysctl", "-n", "vm.loadavg")
+		}},
+		{NameMemory, func(ctx context.Context) (string, error) {
+			return runParse(ctx, parseDarwinMemory, "sysctl", "-n", "hw.memsize", "kern.memorystatus_vm_pressure_level", "vm.swapusage")
+		}},
+		{NameDisk, func(ctx context.Context) (string, error) {
+			return runParse(ctx, parseIostat, "iostat", "-d", "-c", "2", "-w", "1")
+		}},
+		{NameThermal, func(ctx context.Context) (string, error) {
+			return runParse(ctx, parsePmsetTherm, "pmset", "-g", "therm")
+		}},
+	},
+}
+
+// relevantRe matches questions about speed, load, or resource use
+var relevantRe = regexp.MustCompile(`(?i)\b(slow\w*|sluggish|lag\w*|hang\w*|freez\w*|frozen|stuck|perf|performance|cpu|memory|ram|swap\w*|oom|load|hot|overheat\w*|thermal|throttl\w*|fans?|disk|i/?o|takes? forever|taking forever|speed\w*|faster)\b`)
+
+// Relevant reports whether query asks about performance, like "why is my
+// build slow", so probe readings are worth sending with it
+func Relevant(query string) bool {
+	return relevantRe.MatchString(query)
+}
+
+// Gather takes every probe for this OS concurrently. Probes that fail or
+// outlast Timeout are left out.
+func Gather(ctx context.Context) []ai.ProbeReading {
+	list := probes[runtime.GOOS]
+	if len(list) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	values := make([]string, len(list))
+	var wg sync.WaitGroup
+	for i, p := range list {
+		wg.Add(1)
+		go func(i int, p probe) {
+			defer wg.Done()
+			value, err := p.read(ctx)
+			if err != nil {
+				debug.Logf("probe %s failed: %v", p.name, err)
+				return
+			}
+			values[i] = value
+		}(i, p)
+	}
+	wg.Wait()
+
+	var readings []ai.ProbeReading
+	for i, p := range list {
+		if values[i] != "" {
+			readings = append(readings, ai.ProbeReading{Name: p.name, Value: values[i]})
+		}
+	}
+	return readings
+}
+
+// readFile parses a file with parse
+func readFile(path string, parse func(string) (string, error)) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return parse(string(data))
+}
+
+// runParse runs a command and parses its output with parse
+func runParse(ctx context.Context, parse func(string) (string, error), name string, args ...string) (string, error) {
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return parse(string(out))
+}
+
+// parseLoadavg formats the 1, 5, and 15 minute load averages from
+// /proc/loadavg ("0.52 0.58 0.59 1/1234 5678") or macOS's vm.loadavg
+// ("{ 0.52 0.58 0.59 }") against the number of CPUs
+func parseLoadavg(out string) (string, error) {
+	fields := strings.Fields(strings.Trim(strings.TrimSpace(out), "{}"))
+	if len(fields) < 3 {
+		return "", fmt.Errorf("unexpected load average %q", out)
+	}
+	for _, f := range fields[:3] {
+		if _, err := strconv.ParseFloat(f, 64); err != nil {
+			return "", fmt.Errorf("unexpected load average %q", out)
+		}
+	}
+	return fmt.Sprintf("%s %s %s (1, 5, 15 min) on %d CPUs", fields[0], fields[1], fields[2], runtime.NumCPU()), nil
+}
+
+// readLinuxMemory reads available memory and swap, and the kernel's
+// memory pressure stall information where it is enabled
+func readLinuxMemory(context.Context) (string, error) {
+	value, err := readFile("/proc/meminfo", parseMeminfo)
+	if err != nil {
+		return "", err
+	}
+	if data, err := os.ReadFile("/proc/pressure/memory"); err == nil {
+		if stall := parsePressure(string(data)); stall != "" {
+			value += ", " + stall
+		}
+	}
+	return value, nil
+}
+
+// parseMeminfo formats available memory and swap in use from /proc/meminfo
+func parseMeminfo(out string) (string, error) {
+	kb := make(map[string]int64)
+	for _, line := range strings.Split(out, "\n") {
+		name, rest, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		if n, err := strconv.ParseInt(fields[0], 10, 64); err == nil {
+			kb[name] = n
+		}
+	}
+	total, ok := kb["MemTotal"]
+	if !ok || total == 0 {
+		return "", fmt.Errorf("no MemTotal in meminfo")
+	}
+	available, ok := kb["MemAvailable"]
+	if !ok {
+		available = kb["MemFree"] + kb["Buffers"] + kb["Cached"]
+	}
+	value := fmt.Sprintf("%s available of %s", units.Bytes(available*1024), units.Bytes(total*1024))
+	if swap := kb["SwapTotal"] - kb["SwapFree"]; swap > 0 {
+		value += fmt.Sprintf(", %s swap in use", units.Bytes(swap*1024))
+	}
+	return value, nil
+}
+
+// parsePressure formats the share of the last 10 seconds tasks spent
+// stalled on memory, from /proc/pressure/memory
+func parsePressure(out string) string {
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "some" {
+			continue
+		}
+		if avg, ok := strings.CutPrefix(fields[1], "avg10="); ok {
+			return "stalled on memory " + avg + "% of the last 10s"
+		}
+	}
+	return ""
+}
+
+// parseDarwinMemory formats sysctl's hw.memsize, memory pressure level,
+// and vm.swapusage lines
+func parseDarwinMemory(out string) (string, error) {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) < 3 {
+		return "", fmt.Errorf("unexpected sysctl output %q", out)
+	}
+	total, err := strconv.ParseInt(strings.TrimSpace(lines[0]), 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("unexpected hw.memsize %q", lines[0])
+	}
+	level := "normal"
+	switch strings.TrimSpace(lines[1]) {
+	case "2":
+		level = "warning"
+	case "4":
+		level = "critical"
+	}
+	value := fmt.Sprintf("pressure %s, %s installed", level, units.Bytes(total))
+	// total = 2048.00M  used = 1024.50M  free = 1023.50M  (encrypted)
+	swap := strings.Fields(lines[2])
+	for i := 0; i+2 < len(swap); i++ {
+		if swap[i] != "used" || swap[i+1] != "=" {
+			continue
+		}
+		if used, err := strconv.ParseFloat(strings.TrimSuffix(swap[i+2], "M"), 64); err == nil && used > 0 {
+			value += fmt.Sprintf(", %s swap in use", units.Bytes(int64(used*1024*1024)))
+		}
+	}
+	return value, nil
+}
+
+// diskCounters are a device's cumulative counters from /proc/diskstats
+type diskCounters struct {
+	sectorsRead, sectorsWritten, msBusy int64
+}
+
+// readLinuxDisk samples /proc/diskstats twice to report each busy disk's
+// throughput and utilization
+func readLinuxDisk(ctx context.Context) (string, error) {
+	before, err := os.ReadFile("/proc/diskstats")
+	if err != nil {
+		return "", err
+	}
+	start := time.Now()
+	select {
+	case <-time.After(diskSampleInterval):
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	after, err := os.ReadFile("/proc/diskstats")
+	if err != nil {
+		return "", err
+	}
+	return diskActivity(parseDiskstats(string(before)), parseDiskstats(string(after)), time.Since(start)), nil
+}
+
+// parseDiskstats reads the counters of whole disks from /proc/diskstats,
+// skipping loop and RAM devices and partitions of disks listed before them
+func parseDiskstats(out string) map[string]diskCounters {
+	disks := make(map[string]diskCounters)
+	var names []string
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 13 {
+			continue
+		}
+		name := fields[2]
+		if strings.HasPrefix(name, "loop") || strings.HasPrefix(name, "ram") || strings.HasPrefix(name, "zram") {
+			continue
+		}
+		partition := false
+		for _, disk := range names {
+			if strings.HasPrefix(name, disk) {
+				partition = true
+				break
+			}
+		}
+		if partition {
+			continue
+		}
+		names = append(names, name)
+		read, _ := strconv.ParseInt(fields[5], 10, 64)
+		written, _ := strconv.ParseInt(fields[9], 10, 64)
+		busy, _ := strconv.ParseInt(fields[12], 10, 64)
+		disks[name] = diskCounters{read, written, busy}
+	}
+	return disks
+}
+
+// diskActivity formats the disks that did IO between two samples, busiest
+// first
+func diskActivity(before, after map[string]diskCounters, elapsed time.Duration) string {
+	type activity struct {
+		name        string
+		read, write float64 // Bytes per second
+		busy        float64 // Percent of the interval with IO in flight
+	}
+	seconds := elapsed.Seconds()
+	if seconds <= 0 {
+		return "idle"
+	}
+	var active []activity
+	for name, a := range after {
+		b, ok := before[name]
+		if !ok {
+			continue
+		}
+		act := activity{
+			name:  name,
+			read:  float64(a.sectorsRead-b.sectorsRead) * 512 / seconds,
+			write: float64(a.sectorsWritten-b.sectorsWritten) * 512 / seconds,
+			busy:  min(100, float64(a.msBusy-b.msBusy)/10/seconds),
+		}
+		if act.read > 0 || act.write > 0 || act.busy > 0 {
+			active = append(active, act)
+		}
+	}
+	if len(active) == 0 {
+		return "idle"
+	}
+	sort.Slice(active, func(i, j int) bool {
+		if active[i].busy != active[j].busy {
+			return active[i].busy > active[j].busy
+		}
+		return active[i].name < active[j].name
+	})
+	var parts []string
+	for _, act := range active {
+		parts = append(parts, fmt.Sprintf("%s read %s/s, write %s/s, %.0f%% busy",
+			act.name, units.Bytes(int64(act.read)), units.Bytes(int64(act.write)), act.busy))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// parseIostat formats the last sample of macOS iostat -d, whose columns
+// are KB/t, tps, and MB/s for each disk named in the header
+func parseIostat(out string) (string, error) {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) < 3 {
+		return "", fmt.Errorf("unexpected iostat output %q", out)
+	}
+	disks := strings.Fields(lines[0])
+	sample := strings.Fields(lines[len(lines)-1])
+	if len(sample) != 3*len(disks) {
+		return "", fmt.Errorf("unexpected iostat output %q", out)
+	}
+	var parts []string
+	for i, disk := range disks {
+		tps, mbs := sample[3*i+1], sample[3*i+2]
+		if tps == "0" {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s %s transfers/s, %s MB/s", disk, tps, mbs))
+	}
+	if len(parts) == 0 {
+		return "idle", nil
+	}
+	return strings.Join(parts, "; "), nil
+}
+
+// parsePmsetTherm formats pmset -g therm: whether the CPU is held below
+// full speed and any thermal or performance warning recorded
+func parsePmsetTherm(out string) (string, error) {
+	var parts []string
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		name, value, ok := strings.Cut(line, "=")
+		if ok && strings.TrimSpace(name) == "CPU_Speed_Limit" {
+			if limit := strings.TrimSpace(value); limit != "100" {
+				parts = append(parts, "CPU speed limited to "+limit+"%")
+			}
+			continue
+		}
+		lower := strings.ToLower(line)
+		if strings.Contains(lower, "warning level") && !strings.Contains(lower, "no ") {
+			parts = append(parts, line)
+		}
+	}
+	if len(parts) == 0 {
+		return "not throttled", nil
+	}
+	return strings.Join(parts, "; "), nil
+}
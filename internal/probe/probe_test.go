@@ -0,0 +1,104 @@
+package probe
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestRelevant(t *testing.T) {
+	for query, want := range map[string]bool{
+		"why is my build slow":          true,
+		"what's using all the memory":   true,
+		"the fans are loud and it lags": true,
+		"tests take forever":            true,
+		"list files modified yesterday": false,
+		"show the git log":              false,
+		"download the release notes":    false,
+	} {
+		if got := Relevant(query); got != want {
+			t.Errorf("Relevant(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
+
+func TestParseLoadavg(t *testing.T) {
+	want := fmt.Sprintf("0.52 0.58 0.59 (1, 5, 15 min) on %d CPUs", runtime.NumCPU())
+	for _, out := range []string{"0.52 0.58 0.59 1/1234 5678\n", "{ 0.52 0.58 0.59 }\n"} {
+		if got, err := parseLoadavg(out); err != nil || got != want {
+			t.Errorf("parseLoadavg(%q) = %q, %v; want %q", out, got, err, want)
+		}
+	}
+	if _, err := parseLoadavg("garbage"); err == nil {
+		t.Error("parseLoadavg() of garbage succeeded")
+	}
+}
+
+func TestParseMeminfo(t *testing.T) {
+	out := "MemTotal:       16384000 kB\nMemFree:          512000 kB\nMemAvailable:    2048000 kB\nSwapTotal:       4194304 kB\nSwapFree:        3145728 kB\n"
+	got, err := parseMeminfo(out)
+	if want := "2.0GB available of 15.6GB, 1.0GB swap in use"; err != nil || got != want {
+		t.Errorf("parseMeminfo() = %q, %v; want %q", got, err, want)
+	}
+	pressure := "some avg10=12.50 avg60=3.00 avg300=1.00 total=123\nfull avg10=4.00 avg60=1.00 avg300=0.30 total=45\n"
+	if got := parsePressure(pressure); got != "stalled on memory 12.50% of the last 10s" {
+		t.Errorf("parsePressure() = %q", got)
+	}
+}
+
+func TestParseDarwinMemory(t *testing.T) {
+	out := "17179869184\n2\ntotal = 2048.00M  used = 1024.00M  free = 1024.00M  (encrypted)\n"
+	got, err := parseDarwinMemory(out)
+	if want := "pressure warning, 16.0GB installed, 1.0GB swap in use"; err != nil || got != want {
+		t.Errorf("parseDarwinMemory() = %q, %v; want %q", got, err, want)
+	}
+	out = "17179869184\n1\ntotal = 0.00M  used = 0.00M  free = 0.00M\n"
+	if got, _ := parseDarwinMemory(out); got != "pressure normal, 16.0GB installed" {
+		t.Errorf("parseDarwinMemory() without swap = %q", got)
+	}
+}
+
+func TestDiskActivity(t *testing.T) {
+	before := parseDiskstats(`   7       0 loop0 100 0 2000 10 0 0 0 0 0 10 10
+ 259       0 nvme0n1 1000 0 20000 500 2000 0 40000 900 0 1000 1400
+ 259       1 nvme0n1p1 900 0 18000 450 1900 0 38000 850 0 950 1300
+   8       0 sda 10 0 100 5 0 0 0 0 0 5 5
+`)
+	if _, ok := before["nvme0n1p1"]; ok || len(before) != 2 {
+		t.Fatalf("parseDiskstats() = %v, want whole disks without loop devices", before)
+	}
+	after := map[string]diskCounters{
+		"nvme0n1": {sectorsRead: 20000 + 4096, sectorsWritten: 40000 + 2048, msBusy: 1000 + 250},
+		"sda":     before["sda"],
+	}
+	got := diskActivity(before, after, 500*time.Millisecond)
+	if want := "nvme0n1 read 4.0MB/s, write 2.0MB/s, 50% busy"; got != want {
+		t.Errorf("diskActivity() = %q, want %q", got, want)
+	}
+	if got := diskActivity(before, before, time.Second); got != "idle" {
+		t.Errorf("diskActivity() without IO = %q, want idle", got)
+	}
+}
+
+func TestParseIostat(t *testing.T) {
+	out := `              disk0               disk4
+    KB/t  tps  MB/s     KB/t  tps  MB/s
+   24.45   47  1.12     0.00    0  0.00
+   16.00   12  0.19     0.00    0  0.00
+`
+	if got, err := parseIostat(out); err != nil || got != "disk0 12 transfers/s, 0.19 MB/s" {
+		t.Errorf("parseIostat() = %q, %v", got, err)
+	}
+}
+
+func TestParsePmsetTherm(t *testing.T) {
+	idle := "Note: No thermal warning level has been recorded\nNote: No performance warning level has been recorded\n"
+	if got, _ := parsePmsetTherm(idle); got != "not throttled" {
+		t.Errorf("parsePmsetTherm() idle = %q", got)
+	}
+	hot := "CPU_Scheduler_Limit \t= 100\nCPU_Available_CPUs \t= 8\nCPU_Speed_Limit \t= 70\nThermal warning level set to 2.\n"
+	if got, _ := parsePmsetTherm(hot); got != "CPU speed limited to 70%; Thermal warning level set to 2." {
+		t.Errorf("parsePmsetTherm() throttled = %q", got)
+	}
+}
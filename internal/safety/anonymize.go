@@ -0,0 +1,131 @@
+package safety
+
+import (
+	"encoding/json"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// Placeholders that stand in for the real identity in prompts. They are
+// unusual enough that restoring them in replies doesn't touch other text.
+const (
+	PlaceholderUser = "bast-user"
+	PlaceholderHost = "bast-host"
+)
+
+// genericUsers are account names shared by many machines. They identify no
+// one, and replacing them as words would garble text such as "root cause".
+var genericUsers = map[string]bool{
+	"root": true, "admin": true, "administrator": true, "user": true,
+	"ubuntu": true, "ec2-user": true, "vagrant": true, "runner": true, "nobody": true,
+}
+
+// replacement maps a real value to its placeholder
+type replacement struct {
+	real        string
+	placeholder string
+	pattern     *regexp.Regexp // Matches real in outgoing text
+}
+
+// Anonymizer swaps the username, hostname, and home directory for stable
+// placeholders in outgoing text, and swaps them back in replies
+type Anonymizer struct {
+	replacements []replacement
+}
+
+// NewAnonymizer returns an anonymizer for the given identity. Empty values
+// are skipped.
+func NewAnonymizer(username, hostname, home string) *Anonymizer {
+	a := &Anonymizer{}
+
+	// The home directory goes first, since it usually contains the username
+	home = strings.TrimRight(home, `/\`)
+	if home != "" {
+		placeholderHome := placeholderHome()
+		a.add(home, placeholderHome, regexp.QuoteMeta(home)+`\b`)
+		// Windows paths appear with escaped backslashes in JSON bodies
+		if escaped := jsonEscape(home); escaped != home {
+			a.add(escaped, jsonEscape(placeholderHome), regexp.QuoteMeta(escaped)+`\b`)
+		}
+	}
+
+	if username != "" && !genericUsers[strings.ToLower(username)] {
+		a.add(username, PlaceholderUser, `\b`+regexp.QuoteMeta(username)+`\b`)
+	}
+
+	if hostname != "" && hostname != "localhost" {
+		a.add(hostname, PlaceholderHost, `(?i)\b`+regexp.QuoteMeta(hostname)+`\b`)
+		if short, _, ok := strings.Cut(hostname, "."); ok && short != "" {
+			a.add(short, PlaceholderHost, `(?i)\b`+regexp.QuoteMeta(short)+`\b`)
+		}
+	}
+	return a
+}
+
+// CurrentAnonymizer returns an anonymizer for the user running bast
+func CurrentAnonymizer() *Anonymizer {
+	username := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		username = u.Username
+	}
+	hostname, _ := os.Hostname()
+	home, _ := os.UserHomeDir()
+	return NewAnonymizer(username, hostname, home)
+}
+
+func (a *Anonymizer) add(real, placeholder, pattern string) {
+	a.replacements = append(a.replacements, replacement{
+		real:        real,
+		placeholder: placeholder,
+		pattern:     regexp.MustCompile(pattern),
+	})
+}
+
+// Anonymize replaces the real identity in text with placeholders
+func (a *Anonymizer) Anonymize(text string) string {
+	if a == nil {
+		return text
+	}
+	for _, r := range a.replacements {
+		text = r.pattern.ReplaceAllLiteralString(text, r.placeholder)
+	}
+	return text
+}
+
+// Restore replaces placeholders in text with the real identity. The short
+// hostname is not restored, since its placeholder restores to the full name.
+func (a *Anonymizer) Restore(text string) string {
+	if a == nil {
+		return text
+	}
+	restored := map[string]bool{}
+	for _, r := range a.replacements {
+		if restored[r.placeholder] {
+			continue
+		}
+		restored[r.placeholder] = true
+		text = strings.ReplaceAll(text, r.placeholder, r.real)
+	}
+	return text
+}
+
+// placeholderHome is the home directory shown in place of the real one
+func placeholderHome() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "/Users/" + PlaceholderUser
+	case "windows":
+		return filepath.Join(`C:\Users`, PlaceholderUser)
+	}
+	return "/home/" + PlaceholderUser
+}
+
+// jsonEscape returns s as it appears inside a JSON string
+func jsonEscape(s string) string {
+	quoted, _ := json.Marshal(s)
+	return strings.Trim(string(quoted), `"`)
+}
@@ -0,0 +1,52 @@
+package safety
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestAnonymizer(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("placeholder home is platform specific")
+	}
+	a := NewAnonymizer("alice", "alices-laptop.corp.example", "/home/alice")
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"home path", "cat /home/alice/src/main.go", "cat /home/bast-user/src/main.go"},
+		{"another user's home", "ls /home/alice2", "ls /home/alice2"},
+		{"username", "User: alice", "User: bast-user"},
+		{"username inside word", "malice", "malice"},
+		{"full hostname", "ssh alices-laptop.corp.example", "ssh bast-host"},
+		{"short hostname", "alice@Alices-Laptop:~$", "bast-user@bast-host:~$"},
+		{"unrelated text", "go test ./...", "go test ./..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := a.Anonymize(tt.in); got != tt.want {
+				t.Errorf("Anonymize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+
+	reply := "Run `cd /home/bast-user/src` as bast-user on bast-host"
+	want := "Run `cd /home/alice/src` as alice on alices-laptop.corp.example"
+	if got := a.Restore(reply); got != want {
+		t.Errorf("Restore() = %q, want %q", got, want)
+	}
+}
+
+func TestAnonymizerSkipsGenericUsers(t *testing.T) {
+	a := NewAnonymizer("root", "localhost", "")
+	if got := a.Anonymize("the root cause on localhost"); got != "the root cause on localhost" {
+		t.Errorf("Anonymize() = %q, want unchanged", got)
+	}
+
+	var none *Anonymizer
+	if got := none.Restore("bast-user"); got != "bast-user" {
+		t.Errorf("nil Restore() = %q, want unchanged", got)
+	}
+}
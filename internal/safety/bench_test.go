@@ -0,0 +1,52 @@
+package safety
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bastio-ai/bast/internal/perf"
+)
+
+// benchCommand is a long pipeline with no dangerous patterns, the worst
+// case since every pattern is tried
+var benchCommand = strings.Repeat("find . -name '*.log' -mtime +7 | xargs grep -l ERROR | sort | uniq -c && ", 30) + "echo done"
+
+// benchOutput is command output with a credential near the end
+var benchOutput = strings.Repeat("2024-01-01 12:00:00 INFO request handled in 12ms path=/api/v1/items\n", 500) +
+	"export AWS_SECRET_ACCESS_KEY=wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY\n"
+
+func BenchmarkIsDangerousCommand(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		IsDangerousCommand(benchCommand)
+	}
+}
+
+func BenchmarkRedactSecrets(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		RedactSecrets(benchOutput)
+	}
+}
+
+func BenchmarkDetectInjection(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		DetectInjection(benchOutput)
+	}
+}
+
+func BenchmarkAnonymize(b *testing.B) {
+	anon := NewAnonymizer("alice", "alice-laptop.local", "/home/alice")
+	text := strings.Repeat("/home/alice/src/app ran on alice-laptop as alice\n", 200)
+	for i := 0; i < b.N; i++ {
+		anon.Anonymize(text)
+	}
+}
+
+func TestPerfBudgets(t *testing.T) {
+	perf.Check(t, []perf.Budget{
+		{Name: "IsDangerousCommand", Bench: BenchmarkIsDangerousCommand, Max: 2 * time.Millisecond},
+		{Name: "RedactSecrets", Bench: BenchmarkRedactSecrets, Max: 50 * time.Millisecond},
+		{Name: "DetectInjection", Bench: BenchmarkDetectInjection, Max: 100 * time.Millisecond},
+		{Name: "Anonymize", Bench: BenchmarkAnonymize, Max: 10 * time.Millisecond},
+	})
+}
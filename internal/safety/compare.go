@@ -0,0 +1,21 @@
+package safety
+
+// RiskChanges lists how the risk of after differs from before according to
+// the local checks: destructive patterns and sudo. It returns nil when both
+// commands carry the same risk.
+func RiskChanges(before, after string) []string {
+	var changes []string
+	switch before, after := IsDangerousCommand(before), IsDangerousCommand(after); {
+	case !before && after:
+		changes = append(changes, "now matches a destructive pattern")
+	case before && !after:
+		changes = append(changes, "no longer matches a destructive pattern")
+	}
+	switch before, after := NeedsSudo(before), NeedsSudo(after); {
+	case !before && after:
+		changes = append(changes, "now runs with sudo")
+	case before && !after:
+		changes = append(changes, "no longer runs with sudo")
+	}
+	return changes
+}
@@ -0,0 +1,29 @@
+package safety
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRiskChanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   []string
+	}{
+		{"same risk", "ls -la", "ls -lah", nil},
+		{"becomes destructive", "rm -r build", "rm -rf /", []string{"now matches a destructive pattern"}},
+		{"adds sudo", "apt update", "sudo apt update", []string{"now runs with sudo"}},
+		{"drops sudo", "sudo make install", "make install", []string{"no longer runs with sudo"}},
+		{"both", "ls /", "sudo rm -rf /", []string{"now matches a destructive pattern", "now runs with sudo"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RiskChanges(tt.before, tt.after); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RiskChanges(%q, %q) = %v, want %v", tt.before, tt.after, got, tt.want)
+			}
+		})
+	}
+}
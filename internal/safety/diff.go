@@ -0,0 +1,66 @@
+package safety
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DiffFinding is a credential found on an added line of a diff
+type DiffFinding struct {
+	File  string
+	Line  int      // Line number in the new version of the file
+	Kinds []string // Credential kinds, as from DetectSecrets
+}
+
+// privateKeyHeader matches the first line of a PEM private key. The full
+// private_key pattern spans lines, so a diff is checked for the header.
+var privateKeyHeader = regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)
+
+// ScanDiff finds credentials on the lines a unified diff adds. Removed and
+// context lines are ignored, so deleting a leaked key is never flagged.
+func ScanDiff(diff string) []DiffFinding {
+	var findings []DiffFinding
+	file := ""
+	line := 0
+	inHeader := false
+	for _, text := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(text, "diff --git "):
+			inHeader = true
+		case inHeader && strings.HasPrefix(text, "+++ "):
+			file = strings.TrimPrefix(strings.TrimPrefix(text, "+++ "), "b/")
+		case strings.HasPrefix(text, "@@"):
+			inHeader = false
+			line = hunkStart(text)
+		case inHeader:
+		case strings.HasPrefix(text, "+"):
+			kinds := DetectSecrets(text[1:])
+			if len(kinds) == 0 && privateKeyHeader.MatchString(text) {
+				kinds = []string{"private_key"}
+			}
+			if len(kinds) > 0 {
+				findings = append(findings, DiffFinding{File: file, Line: line, Kinds: kinds})
+			}
+			line++
+		case strings.HasPrefix(text, " "):
+			line++
+		}
+	}
+	return findings
+}
+
+// hunkStart reads the new-file start line from a hunk header such as
+// "@@ -10,2 +12,3 @@"
+func hunkStart(header string) int {
+	i := strings.Index(header, " +")
+	if i < 0 {
+		return 0
+	}
+	rest := header[i+2:]
+	if end := strings.IndexAny(rest, ", "); end >= 0 {
+		rest = rest[:end]
+	}
+	n, _ := strconv.Atoi(rest)
+	return n
+}
@@ -0,0 +1,29 @@
+package safety
+
+import (
+	"regexp"
+)
+
+// injectionPatterns match text in files, command output, or tool results that
+// tries to instruct the model instead of informing it
+var injectionPatterns = []secretPattern{
+	{"override_instructions", regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override)\s+(all\s+)?(of\s+)?(the\s+|your\s+|any\s+)?(previous|prior|above|earlier|preceding|system)\s+(instructions|prompts?|rules|messages|directions)`)},
+	{"role_reassignment", regexp.MustCompile(`(?i)\byou are now (an?|in|the)\b|\bfrom now on,? you (are|will|must)\b|\bact as an? (unrestricted|unfiltered|jailbroken)\b`)},
+	{"fake_role_header", regexp.MustCompile(`(?im)^\s*(#+\s*)?(system|assistant)\s*(prompt)?\s*:\s*\S|\bnew (system )?instructions\s*:`)},
+	{"chat_template_token", regexp.MustCompile(`<\|(im_start|im_end|system|endoftext|eot_id|start_header_id)\|>|\[/?INST\]|<</?SYS>>`)},
+	{"delimiter_escape", regexp.MustCompile(`(?i)</?(untrusted|tool_result|function_results|system|instructions)\b[^>]*>`)},
+	{"addressed_to_model", regexp.MustCompile(`(?i)\b(note|message|instructions?) (to|for) (the )?(ai|assistant|llm|language model|agent|claude)\b|\bif you are an? (ai|assistant|llm|language model)\b`)},
+	{"exfiltration_request", regexp.MustCompile(`(?i)\b(send|post|upload|exfiltrate|leak|email)\b[^\n]{0,60}\b(api[_ ]?keys?|secrets?|credentials|passwords?|tokens?|\.env|ssh keys?|private keys?)\b`)},
+}
+
+// DetectInjection returns the kinds of prompt injection markers found in
+// content, in pattern order, or nil when it looks like plain data
+func DetectInjection(content string) []string {
+	var found []string
+	for _, ip := range injectionPatterns {
+		if ip.pattern.MatchString(content) {
+			found = append(found, ip.name)
+		}
+	}
+	return found
+}
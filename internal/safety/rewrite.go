@@ -0,0 +1,103 @@
+package safety
+
+import (
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// Rewrite is a safer version of a command's destructive file operations
+type Rewrite struct {
+	Original string
+	Command  string
+	Reasons  []string // What was changed, for display
+}
+
+// separatorPattern matches the operators between simple commands
+var separatorPattern = regexp.MustCompile(`\s*(&&|\|\||;|\|)\s*`)
+
+// rmFlags are rm options that trash tools do not need
+var rmFlags = regexp.MustCompile(`^-[rRfvdI]+$|^--(recursive|force|verbose|dir)$`)
+
+// DetectTrash returns the trash command available on PATH, or "" if none:
+// trash (macOS 14+, trash-cli), trash-put (trash-cli), or gio trash (GNOME)
+func DetectTrash() string {
+	for _, name := range []string{"trash", "trash-put"} {
+		if _, err := exec.LookPath(name); err == nil {
+			return name
+		}
+	}
+	if _, err := exec.LookPath("gio"); err == nil {
+		return "gio trash"
+	}
+	return ""
+}
+
+// SafeRewrite rewrites destructive file operations in command: rm moves
+// files to the trash using trashCmd (skipped when trashCmd is empty), and mv
+// asks before overwriting. Commands run through sudo are left alone.
+// Returns nil when nothing was rewritten.
+func SafeRewrite(command, trashCmd string) *Rewrite {
+	var out strings.Builder
+	var reasons []string
+	last := 0
+	for _, loc := range append(separatorPattern.FindAllStringIndex(command, -1), []int{len(command), len(command)}) {
+		segment := command[last:loc[0]]
+		rewritten, reason := rewriteSegment(segment, trashCmd)
+		out.WriteString(rewritten)
+		out.WriteString(command[loc[0]:loc[1]])
+		if reason != "" {
+			reasons = append(reasons, reason)
+		}
+		last = loc[1]
+	}
+	if len(reasons) == 0 {
+		return nil
+	}
+	return &Rewrite{Original: command, Command: out.String(), Reasons: reasons}
+}
+
+// rewriteSegment rewrites one simple command; reason is empty when unchanged
+func rewriteSegment(segment, trashCmd string) (string, string) {
+	fields := strings.Fields(segment)
+	if len(fields) < 2 {
+		return segment, ""
+	}
+
+	switch fields[0] {
+	case "rm":
+		if trashCmd == "" {
+			return segment, ""
+		}
+		var paths []string
+		for _, f := range fields[1:] {
+			if f == "--" || rmFlags.MatchString(f) {
+				continue
+			}
+			if strings.HasPrefix(f, "-") {
+				// An option we do not understand; keep the original
+				return segment, ""
+			}
+			paths = append(paths, f)
+		}
+		if len(paths) == 0 {
+			return segment, ""
+		}
+		return trashCmd + " " + strings.Join(paths, " "), "rm → " + trashCmd + " (recoverable from the trash)"
+
+	case "mv":
+		args := fields[1:]
+		var kept []string
+		for _, f := range args {
+			switch f {
+			case "-i", "--interactive", "-n", "--no-clobber":
+				return segment, ""
+			case "-f", "--force":
+				continue
+			}
+			kept = append(kept, f)
+		}
+		return "mv -i " + strings.Join(kept, " "), "mv → mv -i (asks before overwriting)"
+	}
+	return segment, ""
+}
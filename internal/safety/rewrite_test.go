@@ -0,0 +1,44 @@
+package safety
+
+import "testing"
+
+func TestSafeRewrite(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  string
+		trash    string
+		expected string // empty when no rewrite is expected
+	}{
+		{"rm to trash", "rm -rf build", "trash", "trash build"},
+		{"rm with gio", "rm -r -f dist out", "gio trash", "gio trash dist out"},
+		{"rm without trash tool", "rm -rf build", "", ""},
+		{"rm unknown option", "rm --one-file-system -rf /mnt/x", "trash", ""},
+		{"sudo rm untouched", "sudo rm -rf /var/cache/foo", "trash", ""},
+		{"mv gets -i", "mv a.txt b.txt", "", "mv -i a.txt b.txt"},
+		{"mv force replaced", "mv -f a b", "", "mv -i a b"},
+		{"mv already interactive", "mv -n a b", "", ""},
+		{"chained", "make clean && rm -rf build; mv out dist", "trash-put", "make clean && trash-put build; mv -i out dist"},
+		{"unrelated", "ls -la | grep rm", "trash", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SafeRewrite(tt.command, tt.trash)
+			if tt.expected == "" {
+				if got != nil {
+					t.Errorf("SafeRewrite(%q) = %q, want no rewrite", tt.command, got.Command)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("SafeRewrite(%q) = nil, want %q", tt.command, tt.expected)
+			}
+			if got.Command != tt.expected {
+				t.Errorf("SafeRewrite(%q) = %q, want %q", tt.command, got.Command, tt.expected)
+			}
+			if got.Original != tt.command || len(got.Reasons) == 0 {
+				t.Errorf("SafeRewrite(%q) = %+v, want original and reasons recorded", tt.command, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package safety
+
+import (
+	"encoding/json"
+	"regexp"
+)
+
+// secretPattern pairs a detector name with the regex that matches the secret
+type secretPattern struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// secretPatterns defines regex patterns for credentials that should never
+// leave the machine in tool output.
+var secretPatterns = []secretPattern{
+	{"private_key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+	{"aws_access_key", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"github_token", regexp.MustCompile(`\b(ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36,}\b`)},
+	{"github_pat", regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{22,}\b`)},
+	{"anthropic_key", regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_-]{20,}`)},
+	{"openai_key", regexp.MustCompile(`\bsk-(proj-)?[A-Za-z0-9]{32,}\b`)},
+	{"slack_token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}\b`)},
+	{"bearer_token", regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/-]{20,}=*`)},
+}
+
+// DetectSecrets returns the kinds of credentials found in content, e.g. a
+// token the model echoed into a command from its context
+func DetectSecrets(content string) []string {
+	var found []string
+	for _, sp := range secretPatterns {
+		if sp.pattern.MatchString(content) {
+			found = append(found, sp.name)
+		}
+	}
+	return found
+}
+
+// RedactSecrets replaces known credential formats in content with
+// [REDACTED:<kind>] markers. Returns the redacted content and the kinds found.
+func RedactSecrets(content string) (string, []string) {
+	var found []string
+	for _, sp := range secretPatterns {
+		if !sp.pattern.MatchString(content) {
+			continue
+		}
+		found = append(found, sp.name)
+		content = sp.pattern.ReplaceAllString(content, "[REDACTED:"+sp.name+"]")
+	}
+	return content, found
+}
+
+// RedactSecretsJSON redacts a JSON document such as a tool call's input.
+// A marker can swallow JSON punctuation around a secret, so when the result
+// no longer parses it is returned as a JSON string instead.
+func RedactSecretsJSON(data json.RawMessage) json.RawMessage {
+	redacted, found := RedactSecrets(string(data))
+	if len(found) == 0 {
+		return data
+	}
+	if !json.Valid([]byte(redacted)) {
+		quoted, _ := json.Marshal(redacted)
+		return quoted
+	}
+	return json.RawMessage(redacted)
+}
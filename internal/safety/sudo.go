@@ -0,0 +1,118 @@
+package safety
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrSudoForbidden is returned when the sudo policy rejects a command
+var ErrSudoForbidden = errors.New("command needs sudo, which is forbidden by the sudo policy")
+
+// ErrSudoUnstrippable is returned by the strip policy for a command whose
+// sudo does more than run the rest of the line as root
+var ErrSudoUnstrippable = errors.New("command uses sudo for a root shell, sudoedit, or its own bookkeeping, so sudo can't be stripped")
+
+// sudoPattern matches sudo at the start of a command or after a separator
+var sudoPattern = regexp.MustCompile(`(^|[;&|(]\s*)sudo(\s|$)`)
+
+// sudoArgOptions are sudo flags that consume the following word
+var sudoArgOptions = map[string]bool{
+	"-u": true, "-g": true, "-p": true, "-C": true, "-D": true,
+	"-h": true, "-r": true, "-t": true, "-U": true, "-T": true,
+}
+
+// sudoModeOptions are sudo flags after which the words that follow aren't a
+// command to run as is: -s and -i start a root shell (running any command
+// through it), -e edits files, and the rest list, refresh, or drop cached
+// credentials. Removing sudo from these changes what the line does.
+var sudoModeOptions = map[string]bool{
+	"-s": true, "--shell": true, "-i": true, "--login": true,
+	"-e": true, "--edit": true, "-l": true, "--list": true,
+	"-v": true, "--validate": true, "-k": true, "--reset-timestamp": true,
+	"-K": true, "--remove-timestamp": true,
+}
+
+// NeedsSudo reports whether any command in the line runs through sudo
+func NeedsSudo(command string) bool {
+	return sudoPattern.MatchString(strings.TrimSpace(command))
+}
+
+// ApplySudoPolicy enforces a sudo policy on command: "strip" removes sudo,
+// "forbid" returns ErrSudoForbidden, and anything else leaves it unchanged
+func ApplySudoPolicy(command, policy string) (string, error) {
+	if !NeedsSudo(command) {
+		return command, nil
+	}
+	switch policy {
+	case "strip":
+		stripped := StripSudo(command)
+		if NeedsSudo(stripped) {
+			return "", fmt.Errorf("%w: %s", ErrSudoUnstrippable, command)
+		}
+		return stripped, nil
+	case "forbid":
+		return "", fmt.Errorf("%w: %s", ErrSudoForbidden, command)
+	}
+	return command, nil
+}
+
+// StripSudo removes sudo and its options from every command in the line,
+// e.g. "sudo -u www apt update && sudo ls" becomes "apt update && ls". A
+// line where sudo opens a shell, edits files, or manages its credentials
+// (sudo -s, sudo -i, sudo -e, ...) is returned unchanged, since it has no
+// plain command to keep.
+func StripSudo(command string) string {
+	command = strings.TrimSpace(command)
+	original := command
+	for {
+		loc := sudoPattern.FindStringSubmatchIndex(command)
+		if loc == nil {
+			return command
+		}
+		// loc[3] is the end of the separator group, where "sudo" starts
+		start := loc[3]
+		rest := strings.TrimLeft(command[start+len("sudo"):], " \t")
+
+		// Drop sudo's own options so the real command comes first
+		for strings.HasPrefix(rest, "-") {
+			word, after, _ := strings.Cut(rest, " ")
+			after = strings.TrimLeft(after, " \t")
+			if word == "--" {
+				rest = after
+				break
+			}
+			if sudoModeOption(word) {
+				return original
+			}
+			if sudoArgOptions[word] {
+				_, after, _ = strings.Cut(after, " ")
+				after = strings.TrimLeft(after, " \t")
+			}
+			rest = after
+		}
+		command = command[:start] + rest
+	}
+}
+
+// sudoModeOption reports whether word is one of sudoModeOptions, alone or
+// bundled with other short flags as in "-iu" or "-Es"
+func sudoModeOption(word string) bool {
+	if sudoModeOptions[word] {
+		return true
+	}
+	if strings.HasPrefix(word, "--") {
+		return false
+	}
+	for _, r := range strings.TrimPrefix(word, "-") {
+		if sudoModeOptions["-"+string(r)] {
+			return true
+		}
+		// A flag taking an argument ends the bundle; the rest is its value
+		if sudoArgOptions["-"+string(r)] {
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package safety
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNeedsSudo(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  string
+		expected bool
+	}{
+		{"leading sudo", "sudo apt update", true},
+		{"sudo after &&", "apt update && sudo apt upgrade", true},
+		{"sudo after pipe", "echo hi | sudo tee /etc/motd", true},
+		{"sudo in subshell", "(sudo ls /root)", true},
+		{"bare sudo", "sudo", true},
+		{"no sudo", "ls -la", false},
+		{"sudo inside word", "echo pseudocode", false},
+		{"sudoers argument", "grep sudoers /etc/group", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NeedsSudo(tt.command); got != tt.expected {
+				t.Errorf("NeedsSudo(%q) = %v, want %v", tt.command, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStripSudo(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  string
+		expected string
+	}{
+		{"leading sudo", "sudo apt update", "apt update"},
+		{"flags", "sudo -E -H make install", "make install"},
+		{"option with argument", "sudo -u www-data ls /var/www", "ls /var/www"},
+		{"double dash", "sudo -- rm -f lock", "rm -f lock"},
+		{"multiple commands", "sudo apt update && sudo apt upgrade -y", "apt update && apt upgrade -y"},
+		{"after pipe", "echo hi | sudo tee /etc/motd", "echo hi | tee /etc/motd"},
+		{"no sudo", "ls -la", "ls -la"},
+		{"sudo inside word", "echo pseudocode", "echo pseudocode"},
+		{"root shell", "sudo -s", "sudo -s"},
+		{"login shell command", "sudo -i systemctl restart nginx", "sudo -i systemctl restart nginx"},
+		{"bundled login flag", "sudo -iu postgres psql", "sudo -iu postgres psql"},
+		{"sudoedit", "sudo -e /etc/hosts", "sudo -e /etc/hosts"},
+		{"long shell flag", "apt update && sudo --shell", "apt update && sudo --shell"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripSudo(tt.command); got != tt.expected {
+				t.Errorf("StripSudo(%q) = %q, want %q", tt.command, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestApplySudoPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		command   string
+		policy    string
+		expected  string
+		forbidden bool
+		rejected  bool
+	}{
+		{"confirm keeps sudo", "sudo apt update", "confirm", "sudo apt update", false, false},
+		{"empty policy keeps sudo", "sudo apt update", "", "sudo apt update", false, false},
+		{"strip removes sudo", "sudo apt update", "strip", "apt update", false, false},
+		{"forbid rejects sudo", "sudo apt update", "forbid", "", true, false},
+		{"forbid allows plain commands", "ls -la", "forbid", "ls -la", false, false},
+		{"strip rejects a root shell", "sudo -s", "strip", "", false, true},
+		{"strip rejects a login shell command", "sudo -i make install", "strip", "", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplySudoPolicy(tt.command, tt.policy)
+			if errors.Is(err, ErrSudoForbidden) != tt.forbidden || errors.Is(err, ErrSudoUnstrippable) != tt.rejected {
+				t.Fatalf("ApplySudoPolicy(%q, %q) error = %v, forbidden %v, rejected %v", tt.command, tt.policy, err, tt.forbidden, tt.rejected)
+			}
+			if got != tt.expected {
+				t.Errorf("ApplySudoPolicy(%q, %q) = %q, want %q", tt.command, tt.policy, got, tt.expected)
+			}
+		})
+	}
+}
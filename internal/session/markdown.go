@@ -0,0 +1,118 @@
+package session
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bastio-ai/bast/internal/ai"
+	"github.com/bastio-ai/bast/internal/safety"
+)
+
+// maxToolOutputLines caps each tool output in a shared transcript; the
+// head usually says enough for a teammate to follow along
+const maxToolOutputLines = 40
+
+// Markdown renders s as a transcript for sharing. Every piece of text has
+// secrets redacted and, through a, the username, hostname, and home
+// directory replaced with placeholders.
+func Markdown(s *Session, a *safety.Anonymizer) string {
+	redact := func(text string) string {
+		text, _ = safety.RedactSecrets(text)
+		return a.Anonymize(text)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# bast session %s\n\n", ShortID(s.ID))
+	if s.Model != "" {
+		fmt.Fprintf(&b, "- Model: %s\n", s.Model)
+	}
+	fmt.Fprintf(&b, "- Started: %s\n", s.Started.UTC().Format("2006-01-02 15:04 MST"))
+	fmt.Fprintf(&b, "- Turns: %d\n", len(s.Turns))
+
+	for i, t := range s.Turns {
+		if t.Branch > 0 {
+			fmt.Fprintf(&b, "\n## %d. %s (branch %d)\n\n", i+1, t.Mode, t.Branch)
+		} else {
+			fmt.Fprintf(&b, "\n## %d. %s\n\n", i+1, t.Mode)
+		}
+		b.WriteString(quote(redact(t.Query)))
+		for _, tool := range t.Tools {
+			status := ""
+			if tool.IsError {
+				status = " (failed)"
+			}
+			fmt.Fprintf(&b, "\n**Tool: %s**%s\n\n", tool.Name, status)
+			if len(tool.Input) > 0 {
+				b.WriteString(fence("json", redact(string(tool.Input))))
+			}
+			if tool.Output != "" {
+				b.WriteString(fence("", redact(truncateLines(tool.Output, maxToolOutputLines))))
+			}
+		}
+		if t.Threats != nil {
+			b.WriteString("\n" + threatLine(*t.Threats) + "\n")
+		}
+		if t.Response != "" {
+			b.WriteString("\n" + strings.TrimSpace(redact(t.Response)) + "\n")
+		}
+	}
+	return b.String()
+}
+
+// threatLine summarizes what the security guardrails did during a turn,
+// like the agent view's security line
+func threatLine(s ai.ThreatSummary) string {
+	parts := []string{fmt.Sprintf("%d checked", s.Checked)}
+	if s.Blocked > 0 {
+		parts = append(parts, fmt.Sprintf("%d blocked", s.Blocked))
+	}
+	if s.Sanitized > 0 {
+		parts = append(parts, fmt.Sprintf("%d sanitized", s.Sanitized))
+	}
+	if s.Warnings > 0 {
+		parts = append(parts, fmt.Sprintf("%d warned", s.Warnings))
+	}
+	if s.MaxRiskScore > 0 {
+		parts = append(parts, fmt.Sprintf("max risk %.2f", s.MaxRiskScore))
+	}
+	if s.Blocked == 0 && s.Sanitized == 0 && s.Warnings == 0 {
+		parts = append(parts, "no issues")
+	}
+	line := "**Security:** " + strings.Join(parts, " • ")
+	if len(s.Threats) > 0 {
+		line += " [" + strings.Join(s.Threats, ", ") + "]"
+	}
+	return line
+}
+
+// quote renders text as a Markdown blockquote
+func quote(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("> "+line, " ")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+var backtickRun = regexp.MustCompile("`{3,}")
+
+// fence renders text as a code block, with a fence longer than any run of
+// backticks inside it
+func fence(lang, text string) string {
+	n := 3
+	for _, run := range backtickRun.FindAllString(text, -1) {
+		n = max(n, len(run)+1)
+	}
+	marker := strings.Repeat("`", n)
+	return marker + lang + "\n" + strings.TrimRight(text, "\n") + "\n" + marker + "\n"
+}
+
+// truncateLines keeps the first n lines of text
+func truncateLines(text string, n int) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(lines) <= n {
+		return text
+	}
+	return strings.Join(lines[:n], "\n") + fmt.Sprintf("\n... (%d more lines)", len(lines)-n)
+}
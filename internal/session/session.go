@@ -0,0 +1,178 @@
+// Package session keeps a local record of chat and agent sessions, so a
+// session can be listed and shared after the TUI has closed
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bastio-ai/bast/internal/ai"
+	"github.com/bastio-ai/bast/internal/safety"
+)
+
+// DirName is the directory under ~/.config/bast that holds one JSON file
+// per session
+const DirName = "sessions"
+
+// Session is the record of one TUI session
+type Session struct {
+	ID      string    `json:"id"`
+	Model   string    `json:"model,omitempty"`
+	Started time.Time `json:"started"`
+	Updated time.Time `json:"updated"`
+	Turns   []Turn    `json:"turns"`
+}
+
+// Turn is one request and the answer to it
+type Turn struct {
+	Time     time.Time `json:"time"`
+	Mode     string    `json:"mode"` // "chat" or "agent"
+	Query    string    `json:"query"`
+	Response string    `json:"response"`
+	Tools    []Tool    `json:"tools,omitempty"`
+
+	// Branch is the conversation branch the turn was asked on; 0 is the
+	// original conversation and each edit-and-resend starts a new one
+	Branch int `json:"branch,omitempty"`
+
+	// Threats is what the security guardrails did during an agent turn;
+	// nil when no validator looked at a tool call
+	Threats *ai.ThreatSummary `json:"threats,omitempty"`
+}
+
+// Tool is one tool call the agent made during a turn
+type Tool struct {
+	Name    string          `json:"name"`
+	Input   json.RawMessage `json:"input,omitempty"`
+	Output  string          `json:"output,omitempty"`
+	IsError bool            `json:"is_error,omitempty"`
+}
+
+// Redact replaces credentials in the turn's text and tool calls with
+// [REDACTED:<kind>] placeholders, so they never reach disk
+func (t *Turn) Redact() {
+	t.Query, _ = safety.RedactSecrets(t.Query)
+	t.Response, _ = safety.RedactSecrets(t.Response)
+	for i := range t.Tools {
+		tool := &t.Tools[i]
+		tool.Input = safety.RedactSecretsJSON(tool.Input)
+		tool.Output, _ = safety.RedactSecrets(tool.Output)
+	}
+}
+
+// Title is the first query of the session, for listings
+func (s *Session) Title() string {
+	if len(s.Turns) == 0 {
+		return "(empty)"
+	}
+	title, _, _ := strings.Cut(strings.TrimSpace(s.Turns[0].Query), "\n")
+	return title
+}
+
+// ShortID abbreviates a session UUID the way the status bar shows it
+func ShortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+// DefaultDir returns the default sessions directory
+func DefaultDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", "bast", DirName), nil
+}
+
+// Save writes s to dir, replacing any earlier copy of the same session
+func Save(dir string, s *Session) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create sessions directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+	// Write to a temp file and rename, so a crash never leaves half a session
+	tmp, err := os.CreateTemp(dir, s.ID+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, s.ID+".json")); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	return nil
+}
+
+// List returns the sessions in dir, most recently updated first.
+// A missing directory yields no sessions; unreadable files are skipped.
+func List(dir string) []*Session {
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	var sessions []*Session
+	for _, path := range paths {
+		if s, err := read(path); err == nil {
+			sessions = append(sessions, s)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Updated.After(sessions[j].Updated)
+	})
+	return sessions
+}
+
+// Load returns the session in dir whose ID is id or starts with it, such as
+// the short ID in the status bar. A prefix matching several sessions is an error.
+func Load(dir, id string) (*Session, error) {
+	id = strings.TrimSpace(id)
+	if id == "" || strings.ContainsAny(id, `/\`) {
+		return nil, fmt.Errorf("invalid session ID %q", id)
+	}
+	if s, err := read(filepath.Join(dir, id+".json")); err == nil {
+		return s, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	var match *Session
+	for _, s := range List(dir) {
+		if !strings.HasPrefix(s.ID, id) {
+			continue
+		}
+		if match != nil {
+			return nil, fmt.Errorf("session ID %q is ambiguous: use more characters", id)
+		}
+		match = s
+	}
+	if match == nil {
+		return nil, fmt.Errorf("no session %q: run 'bast sessions list' to see saved sessions", id)
+	}
+	return match, nil
+}
+
+func read(path string) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &s, nil
+}
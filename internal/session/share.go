@@ -0,0 +1,116 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bastio-ai/bast/internal/debug"
+)
+
+// gistAPIURL is the GitHub endpoint that creates gists; tests point it elsewhere
+var gistAPIURL = "https://api.github.com/gists"
+
+// uploadClient doesn't follow redirects, so a paste service answering 303
+// See Other is read for its Location rather than the page it points to
+var uploadClient = &http.Client{
+	Timeout:   30 * time.Second,
+	Transport: &debug.Transport{Base: http.DefaultTransport},
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// GitHubToken returns the token used to create gists, from GITHUB_TOKEN or
+// GH_TOKEN
+func GitHubToken() string {
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		return token
+	}
+	return os.Getenv("GH_TOKEN")
+}
+
+// UploadPaste posts markdown to a paste service and returns the URL of the
+// paste. The service may answer with the URL in a Location header, as JSON
+// with a "url" field, or as the plain response body.
+func UploadPaste(ctx context.Context, pasteURL, markdown string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", pasteURL, strings.NewReader(markdown))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/markdown; charset=utf-8")
+
+	resp, err := uploadClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("upload failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 && resp.StatusCode != http.StatusSeeOther {
+		return "", fmt.Errorf("paste service rejected the upload: %s", resp.Status)
+	}
+
+	if location := resp.Header.Get("Location"); location != "" {
+		return location, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	var parsed struct {
+		URL string `json:"url"`
+	}
+	if json.Unmarshal(body, &parsed) == nil && parsed.URL != "" {
+		return parsed.URL, nil
+	}
+	if url := strings.TrimSpace(string(body)); strings.HasPrefix(url, "http") && !strings.ContainsAny(url, " \n") {
+		return url, nil
+	}
+	return "", fmt.Errorf("paste service did not return a URL")
+}
+
+// UploadGist creates a secret GitHub gist holding markdown as filename and
+// returns its URL
+func UploadGist(ctx context.Context, token, filename, description, markdown string) (string, error) {
+	if token == "" {
+		return "", fmt.Errorf("set GITHUB_TOKEN (with the gist scope) to upload a gist")
+	}
+	body, err := json.Marshal(map[string]any{
+		"description": description,
+		"public":      false,
+		"files":       map[string]any{filename: map[string]string{"content": markdown}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", gistAPIURL, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := uploadClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("upload failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("GitHub rejected the gist: %s", resp.Status)
+	}
+
+	var gist struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&gist); err != nil || gist.HTMLURL == "" {
+		return "", fmt.Errorf("GitHub did not return a gist URL")
+	}
+	return gist.HTMLURL, nil
+}
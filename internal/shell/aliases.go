@@ -0,0 +1,116 @@
+package shell
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/bastio-ai/bast/internal/ai"
+)
+
+// maxAliasDumpBytes bounds how much of the alias dump is read
+const maxAliasDumpBytes = 256 * 1024
+
+// AddAliasContext fills in the user's aliases and shell functions from the
+// dump the shell hook writes to $BAST_ALIASES_FILE before launching bast
+func AddAliasContext(ctx ai.ShellContext) ai.ShellContext {
+	path := os.Getenv("BAST_ALIASES_FILE")
+	if path == "" {
+		return ctx
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Size() > maxAliasDumpBytes {
+		return ctx
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ctx
+	}
+
+	ctx.Aliases = ParseAliases(string(data))
+	for i := range ctx.Aliases {
+		// An alias or function named like a real binary changes what it does
+		if _, err := exec.LookPath(ctx.Aliases[i].Name); err == nil {
+			ctx.Aliases[i].Shadows = true
+		}
+	}
+	return ctx
+}
+
+// ParseAliases parses `alias` output from bash (alias ll='ls -la') or zsh
+// (ll='ls -la'), plus "function name" lines for shell functions
+func ParseAliases(dump string) []ai.ShellAlias {
+	var aliases []ai.ShellAlias
+	scanner := bufio.NewScanner(strings.NewReader(dump))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if name, ok := strings.CutPrefix(line, "function "); ok {
+			if name = strings.TrimSpace(name); name != "" {
+				aliases = append(aliases, ai.ShellAlias{Name: name, Function: true})
+			}
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "alias ")
+		name, value, ok := strings.Cut(line, "=")
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			continue
+		}
+		aliases = append(aliases, ai.ShellAlias{Name: name, Expansion: unquoteAlias(value)})
+	}
+	return aliases
+}
+
+// unquoteAlias removes the single quoting shells use when printing aliases
+func unquoteAlias(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") {
+		value = value[1 : len(value)-1]
+		value = strings.ReplaceAll(value, `'\''`, "'")
+	}
+	return value
+}
+
+// commandWord returns the word a shell would look up as an alias, skipping
+// env assignments. Paths and escaped words (\ls) bypass aliases.
+func commandWord(segment string) string {
+	for _, field := range strings.Fields(segment) {
+		if strings.Contains(field, "=") {
+			continue
+		}
+		if strings.ContainsAny(field, `/\`) {
+			return ""
+		}
+		return field
+	}
+	return ""
+}
+
+// AliasWarnings describes the aliases and functions that the commands in
+// command would run through instead of the binaries they name
+func AliasWarnings(command string, aliases []ai.ShellAlias) []string {
+	if len(aliases) == 0 {
+		return nil
+	}
+	byName := make(map[string]ai.ShellAlias, len(aliases))
+	for _, a := range aliases {
+		byName[a.Name] = a
+	}
+
+	var warnings []string
+	seen := make(map[string]bool)
+	for _, segment := range segmentSeparators.Split(command, -1) {
+		word := commandWord(segment)
+		a, ok := byName[word]
+		if !ok || seen[word] {
+			continue
+		}
+		seen[word] = true
+		if a.Function {
+			warnings = append(warnings, word+" is a shell function in your shell")
+		} else {
+			warnings = append(warnings, word+" is aliased to '"+a.Expansion+"' in your shell")
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,58 @@
+package shell
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bastio-ai/bast/internal/ai"
+)
+
+func TestParseAliases(t *testing.T) {
+	dump := `alias ll='ls -la'
+alias q='echo '\''hi'\'''
+ls='eza --icons'
+gs=git
+function myfn
+not an alias
+`
+	want := []ai.ShellAlias{
+		{Name: "ll", Expansion: "ls -la"},
+		{Name: "q", Expansion: "echo 'hi'"},
+		{Name: "ls", Expansion: "eza --icons"},
+		{Name: "gs", Expansion: "git"},
+		{Name: "myfn", Function: true},
+	}
+	if got := ParseAliases(dump); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseAliases() = %+v, want %+v", got, want)
+	}
+}
+
+func TestAliasWarnings(t *testing.T) {
+	aliases := []ai.ShellAlias{
+		{Name: "ls", Expansion: "eza --icons"},
+		{Name: "mkcd", Function: true},
+	}
+
+	tests := []struct {
+		name    string
+		command string
+		want    []string
+	}{
+		{"aliased", "ls -la --time-style=long-iso", []string{"ls is aliased to 'eza --icons' in your shell"}},
+		{"in pipeline", "cat x | ls && mkcd foo", []string{
+			"ls is aliased to 'eza --icons' in your shell",
+			"mkcd is a shell function in your shell",
+		}},
+		{"path bypasses alias", "/bin/ls -la", nil},
+		{"escape bypasses alias", `\ls -la`, nil},
+		{"not aliased", "grep foo", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AliasWarnings(tt.command, aliases); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AliasWarnings(%q) = %v, want %v", tt.command, got, tt.want)
+			}
+		})
+	}
+}
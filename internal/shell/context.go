@@ -8,17 +8,52 @@ import (
 	"strconv"
 
 	"github.com/bastio-ai/bast/internal/ai"
+	"github.com/bastio-ai/bast/internal/direnv"
 	"github.com/bastio-ai/bast/internal/git"
+	"github.com/bastio-ai/bast/internal/stdin"
+	"github.com/bastio-ai/bast/internal/workspace"
 )
 
 // GetContext retrieves the current shell context from environment variables
 func GetContext() ai.ShellContext {
+	return AddProjectContext(GetBaseContext())
+}
+
+// AddProjectContext fills in the parts of the context that run subprocesses
+// or read project files: git, direnv, aliases, tool versions, and language
+// environments. Sources turned off with Configure are skipped.
+func AddProjectContext(ctx ai.ShellContext) ai.ShellContext {
+	if Enabled(SourceGit) {
+		ctx = AddGitContext(ctx)
+	}
+	ctx = AddAliasContext(ctx)
+	if !Enabled(SourceProject) {
+		return ctx
+	}
+	ctx.Project = ai.DetectProject(ctx.CWD)
+	ctx = AddDirenvContext(ctx)
+	ctx = AddWorkspaceContext(ctx)
+	ctx = AddToolVersions(ctx)
+	return AddRuntimeEnvs(ctx)
+}
+
+// GetBaseContext retrieves the shell context that needs no subprocesses or
+// filesystem walks, so it is safe to call on the startup path
+func GetBaseContext() ai.ShellContext {
 	cwd := getCWD()
 	ctx := ai.ShellContext{
-		CWD:   cwd,
-		OS:    runtime.GOOS,
-		Shell: getShell(),
-		User:  getUser(),
+		CWD:      cwd,
+		OS:       runtime.GOOS,
+		Shell:    getShell(),
+		User:     "unknown",
+		Timezone: getTimezone(),
+		Locale:   getLocale(),
+	}
+	if Enabled(SourceIdentity) {
+		ctx.User = getUser()
+	}
+	if !Enabled(SourceHistory) {
+		return ctx
 	}
 
 	// Get last command and exit status from environment (set by shell hook)
@@ -32,8 +67,12 @@ func GetContext() ai.ShellContext {
 		}
 	}
 
-	// Get git context if in a repository
-	gitCtx := git.GetContext(cwd)
+	return ctx
+}
+
+// AddGitContext fills in git repository context for ctx.CWD
+func AddGitContext(ctx ai.ShellContext) ai.ShellContext {
+	gitCtx := git.GetContext(ctx.CWD)
 	if gitCtx.IsRepo {
 		ctx.Git = &ai.GitContext{
 			IsRepo:           gitCtx.IsRepo,
@@ -43,6 +82,11 @@ func GetContext() ai.ShellContext {
 			HasStaged:        gitCtx.HasStaged,
 			MergeInProgress:  gitCtx.MergeInProgress,
 			RebaseInProgress: gitCtx.RebaseInProgress,
+			Detached:         gitCtx.Detached,
+			HeadSHA:          gitCtx.HeadSHA,
+			Worktree:         gitCtx.Worktree,
+			IsSubmodule:      gitCtx.IsSubmodule,
+			DirtySubmodules:  gitCtx.DirtySubmodules,
 			Summary:          gitCtx.Summary(),
 		}
 	}
@@ -50,6 +94,36 @@ func GetContext() ai.ShellContext {
 	return ctx
 }
 
+// AddWorkspaceContext fills in the monorepo workspace ctx.CWD belongs to, if any
+func AddWorkspaceContext(ctx ai.ShellContext) ai.ShellContext {
+	ws := workspace.Find(ctx.CWD)
+	if ws == nil {
+		return ctx
+	}
+	ctx.Workspace = &ai.WorkspaceContext{Root: ws.Root, Kind: string(ws.Kind)}
+	for _, m := range ws.Members {
+		ctx.Workspace.Members = append(ctx.Workspace.Members, m.Path)
+	}
+	if current := ws.Current(ctx.CWD); current != nil {
+		ctx.Workspace.Current = current.Path
+	}
+	return ctx
+}
+
+// AddDirenvContext fills in the .envrc that applies to ctx.CWD, if any
+func AddDirenvContext(ctx ai.ShellContext) ai.ShellContext {
+	if envCtx := direnv.GetContext(ctx.CWD); envCtx != nil {
+		ctx.Direnv = &ai.DirenvContext{
+			Path:      envCtx.Path,
+			Status:    string(envCtx.Status),
+			Loaded:    envCtx.Loaded,
+			Variables: envCtx.Variables,
+			Uses:      envCtx.Uses,
+		}
+	}
+	return ctx
+}
+
 func getCWD() string {
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -76,18 +150,37 @@ func getUser() string {
 
 // GetContextWithHistory returns shell context with history included
 func GetContextWithHistory() ai.ShellContext {
-	ctx := GetContext()
-	ctx.History = GetHistory(ctx.Shell, 20)
+	return AddHistoryContext(GetContext())
+}
+
+// AddHistoryContext fills in recent history and the last command's output.
+// It runs no subprocesses, so callers holding a context gathered earlier
+// (git, direnv, aliases, tool versions) can add history without paying for
+// those again.
+func AddHistoryContext(ctx ai.ShellContext) ai.ShellContext {
+	if Enabled(SourceHistory) {
+		ctx.History = GetHistory(ctx.Shell, 20)
+	}
+
+	ctx.LastOutput, ctx.LastError = LastOutput()
+
+	return ctx
+}
 
-	// Read last output/error from env vars (set by shell hook)
+// LastOutput returns the summarized stdout and stderr of the last command,
+// read from env vars set by the shell hook. Both are empty when the
+// last_output source is off.
+func LastOutput() (stdout, stderr string) {
+	if !Enabled(SourceLastOutput) {
+		return "", ""
+	}
 	if lastOutput := os.Getenv("BAST_LAST_OUTPUT"); lastOutput != "" {
-		ctx.LastOutput = truncate(lastOutput, 2000)
+		stdout = truncate(stdin.Summarize(lastOutput, 2000), 2000)
 	}
 	if lastError := os.Getenv("BAST_LAST_ERROR"); lastError != "" {
-		ctx.LastError = truncate(lastError, 2000)
+		stderr = truncate(stdin.Summarize(lastError, 2000), 2000)
 	}
-
-	return ctx
+	return stdout, stderr
 }
 
 // truncate limits a string to maxLen characters
@@ -0,0 +1,88 @@
+package shell
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// dateCallRe matches a call to date, including ones inside $(...) or
+// backticks, and captures its arguments up to the end of the command
+var dateCallRe = regexp.MustCompile("(?:^|[\\s;&|(`/])date((?:\\s+[^;&|)`]*)?)")
+
+// dateFlag is a date flag only one flavour of date understands
+type dateFlag struct {
+	flag    string // Short flag letter, or a long flag with its dashes
+	warning string
+}
+
+// gnuDateFlags are GNU date flags that BSD date on macOS rejects or reads
+// differently
+var gnuDateFlags = []dateFlag{
+	{"d", "date -d is GNU syntax; macOS date shifts dates with -v (date -v-1d for yesterday), or use gdate from coreutils"},
+	{"--date", "date --date is GNU syntax; macOS date shifts dates with -v (date -v-1d for yesterday), or use gdate from coreutils"},
+	{"--iso-8601", "date --iso-8601 is GNU syntax; on macOS use date +%Y-%m-%dT%H:%M:%S%z"},
+	{"--rfc-3339", "date --rfc-3339 is GNU syntax; on macOS use date '+%Y-%m-%d %H:%M:%S%z'"},
+	{"--utc", "date --utc is GNU syntax; on macOS use date -u"},
+}
+
+// bsdDateFlags are BSD date flags that GNU date on Linux rejects
+var bsdDateFlags = []dateFlag{
+	{"v", "date -v is BSD syntax; GNU date on Linux shifts dates with -d (date -d yesterday)"},
+	{"j", "date -j is BSD syntax; GNU date on Linux parses dates with -d (date -d '2024-01-31 12:00')"},
+}
+
+// DateWarnings flags date options in command that the date on goos
+// doesn't support, such as GNU date -d on macOS or BSD date -v on Linux.
+// Generated commands often assume one or the other, and a wrong flag fails
+// or, worse, silently uses the current time.
+func DateWarnings(command, goos string) []string {
+	var flags []dateFlag
+	switch goos {
+	case "darwin", "freebsd", "openbsd", "netbsd":
+		flags = gnuDateFlags
+	case "linux":
+		flags = bsdDateFlags
+	default:
+		return nil
+	}
+
+	var warnings []string
+	for _, match := range dateCallRe.FindAllStringSubmatch(command, -1) {
+		for _, word := range strings.Fields(match[1]) {
+			word = strings.Trim(word, `"'`)
+			for _, f := range flags {
+				if hasDateFlag(word, f.flag) && !slices.Contains(warnings, f.warning) {
+					warnings = append(warnings, f.warning)
+				}
+			}
+		}
+	}
+	return warnings
+}
+
+// hasDateFlag reports whether word passes flag: a long flag, possibly with
+// =value, or a short flag letter alone or leading a group like -jf or -v-1d
+func hasDateFlag(word, flag string) bool {
+	if strings.HasPrefix(flag, "--") {
+		return word == flag || strings.HasPrefix(word, flag+"=")
+	}
+	if !strings.HasPrefix(word, "-") || strings.HasPrefix(word, "--") || len(word) < 2 {
+		return false
+	}
+	// Short flags that take a value end the group, so only letters before
+	// the value count; BSD adjustments like -v-1d start with the flag
+	group := word[1:]
+	if group[0:1] == flag {
+		return true
+	}
+	for _, c := range group {
+		if c < 'a' || c > 'z' {
+			return false
+		}
+		if string(c) == flag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package shell
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDateWarnings(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		goos    string
+		want    []string
+	}{
+		{"GNU -d on macOS", `find . -newermt "$(date -d yesterday +%F)"`, "darwin", []string{gnuDateFlags[0].warning}},
+		{"GNU -d with value attached", "date -d@1700000000", "darwin", []string{gnuDateFlags[0].warning}},
+		{"GNU long flags on macOS", "date --date='1 day ago' --iso-8601=seconds", "darwin", []string{gnuDateFlags[1].warning, gnuDateFlags[2].warning}},
+		{"BSD -v on macOS", "date -v-1d +%Y-%m-%d", "darwin", nil},
+		{"BSD -v on Linux", "ls -l $(date -v-1d +%F).log", "linux", []string{bsdDateFlags[0].warning}},
+		{"BSD -jf group on Linux", `date -jf "%Y-%m-%d" 2024-01-31 +%s`, "linux", []string{bsdDateFlags[1].warning}},
+		{"GNU -d on Linux", "TZ=UTC date -d 'last monday'", "linux", nil},
+		{"path to date", "/bin/date -d tomorrow", "darwin", []string{gnuDateFlags[0].warning}},
+		{"gdate is GNU everywhere", "gdate -d yesterday", "darwin", nil},
+		{"other commands", "git log --date=iso -d && touch -d 2024-01-01 f", "darwin", nil},
+		{"reported once", "date -d a; date -d b", "darwin", []string{gnuDateFlags[0].warning}},
+		{"unknown OS", "date -d yesterday", "windows", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DateWarnings(tt.command, tt.goos); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DateWarnings(%q, %s) = %q, want %q", tt.command, tt.goos, got, tt.want)
+			}
+		})
+	}
+}
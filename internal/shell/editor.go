@@ -0,0 +1,55 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ResolveEditor returns the editor to open files with: the configured one,
+// then $VISUAL, then $EDITOR, falling back to vi
+func ResolveEditor(configured string) string {
+	for _, editor := range []string{configured, os.Getenv("VISUAL"), os.Getenv("EDITOR")} {
+		if strings.TrimSpace(editor) != "" {
+			return editor
+		}
+	}
+	return "vi"
+}
+
+// EditorCommand builds the argv that opens path at line (1-based, 0 for
+// unknown) in editor. An editor containing {path} is used as a template
+// with {line} substituted too; otherwise the line flag is chosen from the
+// editor's name.
+func EditorCommand(editor, path string, line int) []string {
+	if line < 1 {
+		line = 1
+	}
+	fields := strings.Fields(editor)
+	if len(fields) == 0 {
+		fields = []string{"vi"}
+	}
+
+	if strings.Contains(editor, "{path}") {
+		replacer := strings.NewReplacer("{path}", path, "{line}", strconv.Itoa(line))
+		args := make([]string, len(fields))
+		for i, f := range fields {
+			args[i] = replacer.Replace(f)
+		}
+		return args
+	}
+
+	at := path + ":" + strconv.Itoa(line)
+	switch filepath.Base(fields[0]) {
+	case "code", "code-insiders", "codium", "cursor", "windsurf":
+		return append(fields, "-g", at)
+	case "subl", "zed", "hx", "helix":
+		return append(fields, at)
+	case "idea", "goland", "pycharm", "webstorm":
+		return append(fields, "--line", strconv.Itoa(line), path)
+	default:
+		// vi, vim, nvim, nano, emacs, micro, kak, and most terminal editors
+		return append(fields, "+"+strconv.Itoa(line), path)
+	}
+}
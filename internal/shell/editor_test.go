@@ -0,0 +1,42 @@
+package shell
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEditorCommand(t *testing.T) {
+	tests := []struct {
+		editor string
+		line   int
+		want   []string
+	}{
+		{"nvim", 12, []string{"nvim", "+12", "/src/main.go"}},
+		{"emacs -nw", 0, []string{"emacs", "-nw", "+1", "/src/main.go"}},
+		{"/usr/local/bin/code --wait", 7, []string{"/usr/local/bin/code", "--wait", "-g", "/src/main.go:7"}},
+		{"hx", 3, []string{"hx", "/src/main.go:3"}},
+		{"goland", 5, []string{"goland", "--line", "5", "/src/main.go"}},
+		{"myedit --at {line} {path}", 9, []string{"myedit", "--at", "9", "/src/main.go"}},
+		{"", 2, []string{"vi", "+2", "/src/main.go"}},
+	}
+	for _, tt := range tests {
+		if got := EditorCommand(tt.editor, "/src/main.go", tt.line); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("EditorCommand(%q, %d) = %q, want %q", tt.editor, tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestResolveEditor(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "nano")
+	if got := ResolveEditor(""); got != "nano" {
+		t.Errorf("ResolveEditor() = %q, want $EDITOR", got)
+	}
+	if got := ResolveEditor("code"); got != "code" {
+		t.Errorf("ResolveEditor(code) = %q, want configured editor", got)
+	}
+	t.Setenv("EDITOR", "")
+	if got := ResolveEditor(""); got != "vi" {
+		t.Errorf("ResolveEditor() = %q, want vi fallback", got)
+	}
+}
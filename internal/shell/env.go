@@ -0,0 +1,56 @@
+package shell
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bastio-ai/bast/internal/target"
+)
+
+// envNameRe matches a valid environment variable name
+var envNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// compoundKeywords start commands an assignment prefix can't go in front of
+var compoundKeywords = map[string]bool{
+	"if": true, "for": true, "while": true, "until": true, "case": true,
+	"function": true, "{": true, "[[": true, "!": true, "time": true,
+}
+
+// ParseEnvAssignment splits "NAME=value" and checks NAME is a valid variable
+// name. Quotes around the whole value are removed.
+func ParseEnvAssignment(arg string) (name, value string, err error) {
+	name, value, ok := strings.Cut(strings.TrimSpace(arg), "=")
+	if !ok {
+		return "", "", fmt.Errorf("expected NAME=value, got %q", arg)
+	}
+	if !envNameRe.MatchString(name) {
+		return "", "", fmt.Errorf("invalid variable name %q", name)
+	}
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	}
+	return name, value, nil
+}
+
+// WithEnv makes command run with the NAME=value entries in env set. A simple
+// command gets them as assignment prefixes; anything else (pipelines, lists,
+// compound commands, and commands expanding variables, which a prefix
+// doesn't reach) exports them in a subshell, so the user's shell keeps its
+// own environment either way.
+func WithEnv(command string, env []string) string {
+	if len(env) == 0 {
+		return command
+	}
+	assignments := make([]string, len(env))
+	for i, entry := range env {
+		name, value, _ := strings.Cut(entry, "=")
+		assignments[i] = name + "=" + target.Quote(value)
+	}
+
+	fields := strings.Fields(command)
+	if strings.ContainsAny(command, ";&|\n()$`") || len(fields) == 0 || compoundKeywords[fields[0]] {
+		return fmt.Sprintf("(export %s; %s)", strings.Join(assignments, " "), command)
+	}
+	return strings.Join(assignments, " ") + " " + command
+}
@@ -0,0 +1,58 @@
+package shell
+
+import "testing"
+
+func TestParseEnvAssignment(t *testing.T) {
+	tests := []struct {
+		arg       string
+		wantName  string
+		wantValue string
+		wantErr   bool
+	}{
+		{"API_URL=http://localhost:8080", "API_URL", "http://localhost:8080", false},
+		{"  DEBUG=1 ", "DEBUG", "1", false},
+		{"EMPTY=", "EMPTY", "", false},
+		{`GREETING="hello world"`, "GREETING", "hello world", false},
+		{"QUERY=a=b", "QUERY", "a=b", false},
+		{"NOVALUE", "", "", true},
+		{"1BAD=x", "", "", true},
+		{"BAD-NAME=x", "", "", true},
+	}
+
+	for _, tt := range tests {
+		name, value, err := ParseEnvAssignment(tt.arg)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseEnvAssignment(%q) error = %v, wantErr %v", tt.arg, err, tt.wantErr)
+			continue
+		}
+		if name != tt.wantName || value != tt.wantValue {
+			t.Errorf("ParseEnvAssignment(%q) = %q, %q, want %q, %q", tt.arg, name, value, tt.wantName, tt.wantValue)
+		}
+	}
+}
+
+func TestWithEnv(t *testing.T) {
+	env := []string{"API_URL=http://localhost:8080", "GREETING=hello world"}
+
+	tests := []struct {
+		name    string
+		command string
+		env     []string
+		want    string
+	}{
+		{"no overrides", "curl $API_URL", nil, "curl $API_URL"},
+		{"simple command", "npm test", env, "API_URL=http://localhost:8080 GREETING='hello world' npm test"},
+		{"expands a variable", "curl $API_URL", env[:1], "(export API_URL=http://localhost:8080; curl $API_URL)"},
+		{"pipeline", "curl -s $API_URL | jq .", env, "(export API_URL=http://localhost:8080 GREETING='hello world'; curl -s $API_URL | jq .)"},
+		{"list", "make && make test", env[:1], "(export API_URL=http://localhost:8080; make && make test)"},
+		{"compound", "for f in *; do echo $f; done", env[:1], "(export API_URL=http://localhost:8080; for f in *; do echo $f; done)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WithEnv(tt.command, tt.env); got != tt.want {
+				t.Errorf("WithEnv() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
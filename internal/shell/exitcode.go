@@ -0,0 +1,118 @@
+package shell
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// signalNames maps common POSIX signal numbers to names and typical causes
+var signalNames = map[int]string{
+	1:  "SIGHUP (terminal closed or hangup)",
+	2:  "SIGINT (interrupted, usually Ctrl+C)",
+	3:  "SIGQUIT (quit, usually Ctrl+\\)",
+	4:  "SIGILL (illegal instruction)",
+	6:  "SIGABRT (aborted, often a failed assertion)",
+	8:  "SIGFPE (arithmetic error, e.g. division by zero)",
+	9:  "SIGKILL (force-killed, often by the out-of-memory killer or a container limit)",
+	11: "SIGSEGV (segmentation fault, invalid memory access)",
+	13: "SIGPIPE (wrote to a pipe whose reader exited)",
+	14: "SIGALRM (timer expired)",
+	15: "SIGTERM (asked to terminate, e.g. by kill or a shutdown)",
+}
+
+// toolExitCodes holds exit code conventions for tools that do not follow
+// the usual 0 = success, 1 = failure pattern
+var toolExitCodes = map[string]map[int]string{
+	"curl": {
+		3:  "the URL is malformed",
+		6:  "could not resolve the host (DNS lookup failed)",
+		7:  "failed to connect to the host (refused or unreachable)",
+		22: "the server returned an HTTP error (400 or above) and --fail was set",
+		28: "the operation timed out",
+		35: "the TLS/SSL handshake failed",
+		47: "too many redirects",
+		52: "the server returned an empty reply",
+		56: "failure receiving network data (connection reset)",
+		60: "the server's certificate could not be verified",
+	},
+	"rsync": {
+		1:  "syntax or usage error",
+		2:  "protocol incompatibility between client and server",
+		3:  "errors selecting input/output files or directories",
+		5:  "error starting the client-server protocol",
+		10: "error in socket I/O",
+		11: "error in file I/O",
+		12: "error in the rsync protocol data stream (often a remote shell problem)",
+		23: "partial transfer: some files could not be transferred",
+		24: "partial transfer: some source files vanished during the transfer",
+		30: "timeout in data send/receive",
+		35: "timeout waiting for the daemon connection",
+	},
+	"grep": {
+		1: "no lines matched (not an error)",
+		2: "an error occurred, such as an unreadable file or bad pattern",
+	},
+	"diff": {
+		1: "the inputs differ (not an error)",
+		2: "an error occurred, such as a missing file",
+	},
+	"timeout": {
+		124: "the command timed out",
+		125: "timeout itself failed",
+	},
+	"ssh": {
+		255: "ssh itself failed, e.g. connection refused or authentication failed",
+	},
+	"git": {
+		128: "git hit a fatal error, e.g. not a repository or a bad ref",
+	},
+}
+
+// DescribeExitCode explains an exit status locally, using the conventions
+// of the tool that ran (the first word of command) where they are known.
+// Returns false when the meaning depends on a tool convention bast does not know.
+func DescribeExitCode(code int, command string) (string, bool) {
+	tool := exitCodeTool(command)
+	if codes, ok := toolExitCodes[tool]; ok {
+		if desc, ok := codes[code]; ok {
+			return fmt.Sprintf("%s: %s", tool, desc), true
+		}
+	}
+
+	switch {
+	case code == 0:
+		return "success", true
+	case code == 126:
+		return "the command was found but could not be executed (permission denied or not executable)", true
+	case code == 127:
+		return "command not found (check the spelling and your PATH)", true
+	case code > 128 && code < 160:
+		signal := code - 128
+		if name, ok := signalNames[signal]; ok {
+			return fmt.Sprintf("terminated by signal %d, %s", signal, name), true
+		}
+		return fmt.Sprintf("terminated by signal %d", signal), true
+	case code == 255:
+		return "exit status out of range, or the tool's catch-all fatal error", true
+	case tool != "" && code > 2:
+		// Tool-specific code we have no table for
+		return "", false
+	case code == 1:
+		return "general failure", true
+	case code == 2:
+		return "usage error or misuse of a shell builtin", true
+	}
+	return "", false
+}
+
+// exitCodeTool returns the tool name from command, skipping sudo and env assignments
+func exitCodeTool(command string) string {
+	for _, field := range strings.Fields(command) {
+		if field == "sudo" || strings.Contains(field, "=") {
+			continue
+		}
+		return filepath.Base(field)
+	}
+	return ""
+}
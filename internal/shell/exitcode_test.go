@@ -0,0 +1,45 @@
+package shell
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDescribeExitCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    int
+		command string
+		want    string // substring of the description, empty when unknown
+	}{
+		{"success", 0, "", "success"},
+		{"not found", 127, "", "command not found"},
+		{"not executable", 126, "./run.sh", "could not be executed"},
+		{"sigkill", 137, "", "signal 9, SIGKILL"},
+		{"sigint", 130, "make", "SIGINT"},
+		{"unnamed signal", 135, "", "signal 7"},
+		{"curl resolve", 6, "curl https://example.invalid", "could not resolve the host"},
+		{"curl via sudo and path", 28, "sudo /usr/bin/curl -s x", "timed out"},
+		{"rsync partial", 23, "rsync -a src/ dst/", "partial transfer"},
+		{"grep no match", 1, "grep foo file", "no lines matched"},
+		{"env assignment", 1, "LC_ALL=C grep foo", "no lines matched"},
+		{"generic failure", 1, "", "general failure"},
+		{"unknown tool code", 42, "mytool --flag", ""},
+		{"unknown code", 42, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := DescribeExitCode(tt.code, tt.command)
+			if tt.want == "" {
+				if ok {
+					t.Errorf("DescribeExitCode(%d, %q) = %q, want unknown", tt.code, tt.command, got)
+				}
+				return
+			}
+			if !ok || !strings.Contains(got, tt.want) {
+				t.Errorf("DescribeExitCode(%d, %q) = %q, %v, want %q", tt.code, tt.command, got, ok, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package shell
+
+import (
+	"strings"
+	"testing"
+)
+
+func FuzzParseHistoryLine(f *testing.F) {
+	for _, seed := range []string{
+		": 1700000000:0;git status",
+		": 1700000000:0;",
+		": no-semicolon",
+		"#1700000000",
+		"  ls -la  ",
+		"",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, line string) {
+		for _, shell := range []string{"zsh", "bash", "fish"} {
+			if got := parseHistoryLine(line, shell); !strings.Contains(line, got) {
+				t.Errorf("parseHistoryLine(%q, %s) = %q, not part of the line", line, shell, got)
+			}
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package shell
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bastio-ai/bast/internal/safety"
+)
+
+// Handoff format versions for the file the shell hooks read after the TUI exits
+const (
+	// HandoffV1 is "BAST_COMMAND:<cmd>", which cannot carry metadata
+	HandoffV1 = 1
+	// HandoffV2 is a JSON object (see Handoff)
+	HandoffV2 = 2
+)
+
+// LegacyHandoffPrefix prefixes the command in the v1 format
+const LegacyHandoffPrefix = "BAST_COMMAND:"
+
+// Handoff describes the command passed back to the shell
+type Handoff struct {
+	Version   int    `json:"version"`
+	Command   string `json:"command"`
+	Dangerous bool   `json:"dangerous"`  // Matched a dangerous command pattern
+	NeedsSudo bool   `json:"needs_sudo"` // Runs sudo somewhere in the pipeline
+	Dir       string `json:"cwd"`        // Directory the command was generated for
+	Edited    bool   `json:"edited"`     // The user edited the command before accepting it
+
+	// WorkDir is the directory the command runs in when it isn't the one
+	// the shell is in. The hook changes to it before offering Command.
+	WorkDir string `json:"working_dir,omitempty"`
+}
+
+// NewHandoff creates a v2 handoff for command
+func NewHandoff(command, dir string, dangerous, edited bool) Handoff {
+	return Handoff{
+		Version:   HandoffV2,
+		Command:   command,
+		Dangerous: dangerous,
+		NeedsSudo: safety.NeedsSudo(command),
+		Dir:       dir,
+		Edited:    edited,
+	}
+}
+
+// Encode renders the handoff in the given format version
+func (h Handoff) Encode(version int) ([]byte, error) {
+	if version < HandoffV2 {
+		return []byte(LegacyHandoffPrefix + h.Command), nil
+	}
+	h.Version = HandoffV2
+	return json.Marshal(h)
+}
+
+// WriteHandoff writes the handoff to path in the given format version
+func WriteHandoff(path string, version int, h Handoff) error {
+	data, err := h.Encode(version)
+	if err != nil {
+		return fmt.Errorf("failed to encode handoff: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write handoff: %w", err)
+	}
+	return nil
+}
+
+// ParseHandoff decodes either handoff format
+func ParseHandoff(data []byte) (*Handoff, error) {
+	text := string(data)
+	if strings.HasPrefix(text, LegacyHandoffPrefix) {
+		return &Handoff{
+			Version: HandoffV1,
+			Command: strings.TrimSuffix(strings.TrimPrefix(text, LegacyHandoffPrefix), "\n"),
+		}, nil
+	}
+
+	var h Handoff
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, fmt.Errorf("failed to parse handoff: %w", err)
+	}
+	if h.Version < HandoffV2 {
+		return nil, fmt.Errorf("unsupported handoff version: %d", h.Version)
+	}
+	return &h, nil
+}
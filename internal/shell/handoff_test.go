@@ -0,0 +1,79 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewHandoffNeedsSudo(t *testing.T) {
+	tests := []struct {
+		command  string
+		expected bool
+	}{
+		{"sudo apt update", true},
+		{"apt update && sudo apt upgrade", true},
+		{"echo hi | sudo tee /etc/motd", true},
+		{"ls -la", false},
+		{"echo pseudocode", false},
+		{"grep sudoers /etc/group", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			h := NewHandoff(tt.command, "/tmp", false, false)
+			if h.NeedsSudo != tt.expected {
+				t.Errorf("NeedsSudo for %q = %v, want %v", tt.command, h.NeedsSudo, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHandoffRoundTrip(t *testing.T) {
+	command := "for f in *.log; do\n  gzip \"$f\"\ndone"
+	h := NewHandoff(command, "/home/user/logs", true, true)
+	h.WorkDir = "/home/user/logs/archive"
+
+	t.Run("v2 preserves multi-line command and metadata", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "out")
+		if err := WriteHandoff(path, HandoffV2, h); err != nil {
+			t.Fatalf("WriteHandoff failed: %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile failed: %v", err)
+		}
+
+		got, err := ParseHandoff(data)
+		if err != nil {
+			t.Fatalf("ParseHandoff failed: %v", err)
+		}
+		if *got != h {
+			t.Errorf("round trip = %+v, want %+v", *got, h)
+		}
+	})
+
+	t.Run("v1 writes the legacy prefix", func(t *testing.T) {
+		data, err := h.Encode(HandoffV1)
+		if err != nil {
+			t.Fatalf("Encode failed: %v", err)
+		}
+		if string(data) != LegacyHandoffPrefix+command {
+			t.Errorf("unexpected v1 output: %q", data)
+		}
+
+		got, err := ParseHandoff(data)
+		if err != nil {
+			t.Fatalf("ParseHandoff failed: %v", err)
+		}
+		if got.Version != HandoffV1 || got.Command != command {
+			t.Errorf("unexpected v1 parse: %+v", got)
+		}
+	})
+
+	t.Run("rejects garbage", func(t *testing.T) {
+		if _, err := ParseHandoff([]byte("not a handoff")); err == nil {
+			t.Error("expected error for invalid handoff")
+		}
+	})
+}
@@ -1,6 +1,13 @@
 package shell
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/bastio-ai/bast/internal/ai"
+)
 
 func TestParseHistoryLine(t *testing.T) {
 	tests := []struct {
@@ -71,3 +78,32 @@ func TestTruncate(t *testing.T) {
 		})
 	}
 }
+
+func TestAddHistoryContextKeepsGatheredContext(t *testing.T) {
+	histFile := filepath.Join(t.TempDir(), "history")
+	if err := os.WriteFile(histFile, []byte("make build\nmake test\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HISTFILE", histFile)
+	t.Setenv("BAST_LAST_OUTPUT", "")
+	t.Setenv("BAST_LAST_ERROR", "undefined: foo")
+
+	gathered := ai.ShellContext{
+		Shell:   "bash",
+		Git:     &ai.GitContext{IsRepo: true, Branch: "main"},
+		Direnv:  &ai.DirenvContext{Path: ".envrc"},
+		Aliases: []ai.ShellAlias{{Name: "gs", Expansion: "git status"}},
+		Tools:   []ai.ToolVersion{{Name: "go", Version: "1.24.1"}},
+	}
+	ctx := AddHistoryContext(gathered)
+
+	if !slices.Equal(ctx.History, []string{"make build", "make test"}) {
+		t.Errorf("History = %v, want the history file's commands", ctx.History)
+	}
+	if ctx.LastError != "undefined: foo" {
+		t.Errorf("LastError = %q, want the last command's stderr", ctx.LastError)
+	}
+	if ctx.Git != gathered.Git || ctx.Direnv != gathered.Direnv || len(ctx.Aliases) != 1 || len(ctx.Tools) != 1 {
+		t.Errorf("context gathered earlier was replaced: %+v", ctx)
+	}
+}
@@ -0,0 +1,63 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// getTimezone returns the system timezone with its current UTC offset,
+// like "Europe/Berlin (UTC+02:00)". The IANA name comes from $TZ or the
+// /etc/localtime link, falling back to the zone abbreviation.
+func getTimezone() string {
+	name, offset := time.Now().Zone()
+	if zone := zoneName(os.Getenv("TZ"), "/etc/localtime"); zone != "" {
+		name = zone
+	}
+	return name + " (" + formatOffset(offset) + ")"
+}
+
+// zoneName returns the IANA name of the zone set by tz, or else the one
+// the localtime link points into the zoneinfo database
+func zoneName(tz, localtime string) string {
+	tz = strings.TrimPrefix(tz, ":")
+	if tz != "" {
+		if _, rest, ok := strings.Cut(tz, "zoneinfo/"); ok {
+			return rest
+		}
+		if !filepath.IsAbs(tz) {
+			return tz
+		}
+	}
+	target, err := os.Readlink(localtime)
+	if err != nil {
+		return ""
+	}
+	if _, rest, ok := strings.Cut(target, "zoneinfo/"); ok {
+		return rest
+	}
+	return ""
+}
+
+// formatOffset formats seconds east of UTC as UTC+hh:mm
+func formatOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	return fmt.Sprintf("UTC%s%02d:%02d", sign, seconds/3600, seconds%3600/60)
+}
+
+// getLocale returns the locale dates and numbers are formatted in, from
+// the first of LC_ALL, LC_TIME, and LANG that is set
+func getLocale() string {
+	for _, name := range []string{"LC_ALL", "LC_TIME", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
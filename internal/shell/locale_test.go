@@ -0,0 +1,39 @@
+package shell
+
+import (
+	"os"
+	"testing"
+)
+
+func TestZoneName(t *testing.T) {
+	dir := t.TempDir()
+	link := dir + "/localtime"
+	if err := os.Symlink("/usr/share/zoneinfo/America/New_York", link); err != nil {
+		t.Skip(err)
+	}
+	tests := []struct {
+		tz   string
+		want string
+	}{
+		{"", "America/New_York"},
+		{"Europe/Berlin", "Europe/Berlin"},
+		{":Asia/Tokyo", "Asia/Tokyo"},
+		{"/usr/share/zoneinfo/UTC", "UTC"},
+	}
+	for _, tt := range tests {
+		if got := zoneName(tt.tz, link); got != tt.want {
+			t.Errorf("zoneName(%q) = %q, want %q", tt.tz, got, tt.want)
+		}
+	}
+	if got := zoneName("", dir+"/missing"); got != "" {
+		t.Errorf("zoneName() without a link = %q, want empty", got)
+	}
+}
+
+func TestFormatOffset(t *testing.T) {
+	for seconds, want := range map[int]string{0: "UTC+00:00", 7200: "UTC+02:00", -16200: "UTC-04:30", 20700: "UTC+05:45"} {
+		if got := formatOffset(seconds); got != want {
+			t.Errorf("formatOffset(%d) = %q, want %q", seconds, got, want)
+		}
+	}
+}
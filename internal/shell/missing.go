@@ -0,0 +1,124 @@
+package shell
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/bastio-ai/bast/internal/safety"
+)
+
+// MissingBinaries is the install step for binaries a command needs but the
+// user does not have on PATH
+type MissingBinaries struct {
+	Binaries []string // Binaries not found on PATH
+	Install  string   // Install command, empty when there is no known package manager
+	Command  string   // Install followed by the original command
+}
+
+// binaryPackages maps binaries to the package that provides them, where the
+// names differ. Per-manager entries override the "" default.
+var binaryPackages = map[string]map[string]string{
+	"rg":      {"": "ripgrep"},
+	"fd":      {"": "fd", "apt": "fd-find", "dnf": "fd-find"},
+	"http":    {"": "httpie"},
+	"convert": {"": "imagemagick", "dnf": "ImageMagick"},
+	"aws":     {"": "awscli"},
+	"delta":   {"": "git-delta"},
+	"btm":     {"": "bottom"},
+	"ag":      {"": "the_silver_searcher", "apt": "silversearcher-ag"},
+	"nc":      {"": "netcat", "apt": "netcat-openbsd", "dnf": "nmap-ncat"},
+	"dig":     {"": "bind", "apt": "dnsutils", "dnf": "bind-utils"},
+	"python3": {"": "python3", "brew": "python"},
+	"pip3":    {"": "python3-pip", "brew": "python"},
+	"node":    {"": "nodejs", "brew": "node"},
+	"npm":     {"": "npm", "brew": "node"},
+}
+
+// shellBuiltins are words that are never looked up on PATH
+var shellBuiltins = map[string]bool{
+	"cd": true, "export": true, "unset": true, "source": true, ".": true, "alias": true,
+	"echo": true, "printf": true, "read": true, "set": true, "eval": true, "exec": true,
+	"exit": true, "return": true, "test": true, "[": true, "[[": true, "true": true, "false": true,
+	"if": true, "then": true, "else": true, "fi": true, "for": true, "while": true, "do": true,
+	"done": true, "case": true, "esac": true, "function": true, "time": true, "type": true,
+	"command": true, "builtin": true, "local": true, "pushd": true, "popd": true, "ulimit": true,
+	"umask": true, "wait": true, "trap": true, "shift": true, "history": true, "jobs": true,
+}
+
+// segmentSeparators splits a command line into simple commands
+var segmentSeparators = regexp.MustCompile(`&&|\|\||[;|]`)
+
+// FindMissingBinaries checks the binaries a command runs against PATH and
+// suggests installing the missing ones with the package manager for goos.
+// pkgManager overrides detection. Returns nil when nothing is missing.
+func FindMissingBinaries(command, goos, pkgManager string) *MissingBinaries {
+	var missing []string
+	for _, bin := range CommandBinaries(command) {
+		if _, err := exec.LookPath(bin); err != nil {
+			missing = append(missing, bin)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	result := &MissingBinaries{Binaries: missing}
+	if pkgManager == "" {
+		pkgManager = detectPackageManager(goos)
+	}
+	manager, ok := packageManagers[pkgManager]
+	if !ok {
+		return result
+	}
+
+	parts := []string{pkgManager, manager.ops["install"]}
+	if manager.sudo {
+		parts = append([]string{"sudo"}, parts...)
+		parts = append(parts, "-y")
+	}
+	for _, bin := range missing {
+		parts = append(parts, packageFor(bin, pkgManager))
+	}
+	result.Install = strings.Join(parts, " ")
+	result.Command = fmt.Sprintf("%s && %s", result.Install, command)
+	return result
+}
+
+// CommandBinaries returns the binaries a command runs, in order and without
+// duplicates, skipping sudo, builtins, and paths
+func CommandBinaries(command string) []string {
+	var bins []string
+	seen := make(map[string]bool)
+	for _, segment := range segmentSeparators.Split(command, -1) {
+		bin := exitCodeTool(safety.StripSudo(strings.TrimSpace(segment)))
+		if bin == "" || seen[bin] || !isLookupCandidate(bin) {
+			continue
+		}
+		seen[bin] = true
+		bins = append(bins, bin)
+	}
+	return bins
+}
+
+// isLookupCandidate reports whether word names a binary to look up on PATH
+func isLookupCandidate(word string) bool {
+	if shellBuiltins[word] {
+		return false
+	}
+	// Paths, variables, subshells, and quoted words are not PATH lookups
+	return !strings.ContainsAny(word, "/$`'\"(){}")
+}
+
+// packageFor returns the package that provides bin under pkgManager
+func packageFor(bin, pkgManager string) string {
+	names, ok := binaryPackages[bin]
+	if !ok {
+		return bin
+	}
+	if name, ok := names[pkgManager]; ok {
+		return name
+	}
+	return names[""]
+}
@@ -0,0 +1,80 @@
+package shell
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindMissingBinaries(t *testing.T) {
+	tests := []struct {
+		name       string
+		command    string
+		pkgManager string
+		binaries   []string
+		install    string
+	}{
+		{"all present", "ls -la | sort", "brew", nil, ""},
+		{"builtins ignored", "cd /tmp && echo hi", "brew", nil, ""},
+		{"missing with brew", "bast-missing-rg foo | wc -l", "brew", []string{"bast-missing-rg"}, "brew install bast-missing-rg"},
+		{"missing with apt", "sudo bast-missing-x --y", "apt", []string{"bast-missing-x"}, "sudo apt install -y bast-missing-x"},
+		{"env prefix skipped", "FOO=1 bast-missing-y run", "dnf", []string{"bast-missing-y"}, "sudo dnf install -y bast-missing-y"},
+		{"no package manager", "bast-missing-z", "none", []string{"bast-missing-z"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FindMissingBinaries(tt.command, "linux", tt.pkgManager)
+			if tt.binaries == nil {
+				if got != nil {
+					t.Fatalf("FindMissingBinaries(%q) = %+v, want nil", tt.command, got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("FindMissingBinaries(%q) = nil, want %v", tt.command, tt.binaries)
+			}
+			if !reflect.DeepEqual(got.Binaries, tt.binaries) {
+				t.Errorf("Binaries = %v, want %v", got.Binaries, tt.binaries)
+			}
+			if got.Install != tt.install {
+				t.Errorf("Install = %q, want %q", got.Install, tt.install)
+			}
+			if tt.install != "" && got.Command != tt.install+" && "+tt.command {
+				t.Errorf("Command = %q, want install prepended", got.Command)
+			}
+		})
+	}
+}
+
+func TestCommandBinaries(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"ls -la | sort", []string{"ls", "sort"}},
+		{"sudo docker ps && docker logs web", []string{"docker"}},
+		{"cd /tmp && echo done", nil},
+		{"FOO=1 go test ./...", []string{"go"}},
+	}
+	for _, tt := range tests {
+		if got := CommandBinaries(tt.command); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("CommandBinaries(%q) = %v, want %v", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestPackageFor(t *testing.T) {
+	tests := []struct{ bin, pm, want string }{
+		{"rg", "apt", "ripgrep"},
+		{"fd", "apt", "fd-find"},
+		{"fd", "brew", "fd"},
+		{"jq", "brew", "jq"},
+		{"node", "brew", "node"},
+		{"node", "apt", "nodejs"},
+	}
+	for _, tt := range tests {
+		if got := packageFor(tt.bin, tt.pm); got != tt.want {
+			t.Errorf("packageFor(%q, %q) = %q, want %q", tt.bin, tt.pm, got, tt.want)
+		}
+	}
+}
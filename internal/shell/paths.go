@@ -0,0 +1,223 @@
+package shell
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Bounds on the search for a file a missing path may have meant. The search
+// runs while the confirm view is drawn, so it stays small enough to finish
+// well within a frame even in a large checkout.
+const (
+	suggestMaxDepth   = 3
+	suggestMaxEntries = 500
+)
+
+// suggestSkipDirs are directories never searched for suggestions
+var suggestSkipDirs = map[string]bool{
+	".git": true, "node_modules": true, "vendor": true, ".venv": true, "venv": true,
+	"__pycache__": true, "target": true, "dist": true, "build": true,
+}
+
+// pathCreators are commands whose arguments are paths they create
+var pathCreators = map[string]bool{"mkdir": true, "touch": true, "tee": true}
+
+// destinationLast are commands whose last argument is a destination that
+// need not exist yet
+var destinationLast = map[string]bool{
+	"cp": true, "mv": true, "ln": true, "rsync": true, "scp": true, "install": true,
+}
+
+// remotePrograms run their arguments somewhere else, where local paths
+// mean nothing
+var remotePrograms = map[string]bool{
+	"ssh": true, "docker": true, "podman": true, "kubectl": true, "vagrant": true, "adb": true,
+}
+
+// outputFlags take a path that the command writes
+var outputFlags = map[string]bool{"-o": true, "--output": true, "-O": true, "--out": true}
+
+// fileExtRe matches a file name with an extension, like deploy.sh
+var fileExtRe = regexp.MustCompile(`[^/]\.[A-Za-z0-9]{1,8}$`)
+
+// MissingPath is a path a command reads that doesn't exist
+type MissingPath struct {
+	Path       string // As written in the command
+	Suggestion string // A file with the same name elsewhere in the directory, relative to it; empty if none
+}
+
+// Warning describes the missing path for display
+func (p MissingPath) Warning() string {
+	if p.Suggestion != "" {
+		return p.Path + " not found - did you mean " + p.Suggestion + "?"
+	}
+	return p.Path + " not found"
+}
+
+// MissingPaths returns the paths command reads that don't exist when it
+// runs in dir. Only words that clearly look like paths are checked: ones
+// starting with ./, ../, ~/, or /, or containing a / and ending in a file
+// extension. Paths the command creates (mkdir and touch arguments,
+// redirection and -o targets, copy destinations) are skipped, along with
+// anything under them later in the command, and checking stops at a cd,
+// after which relative paths can't be resolved.
+func MissingPaths(command, dir string) []MissingPath {
+	var missing []MissingPath
+	var written []string // Paths earlier commands create
+	seen := make(map[string]bool)
+	for _, segment := range segmentSeparators.Split(command, -1) {
+		words := strings.Fields(segment)
+		for len(words) > 0 && (strings.Contains(words[0], "=") || words[0] == "sudo") {
+			words = words[1:]
+		}
+		if len(words) == 0 {
+			continue
+		}
+		if words[0] == "cd" || words[0] == "pushd" {
+			break
+		}
+		reads, writes := pathArgs(words)
+		for _, path := range reads {
+			if seen[path] || under(path, written) {
+				continue
+			}
+			seen[path] = true
+			if _, err := os.Lstat(resolvePath(path, dir)); err == nil {
+				continue
+			}
+			missing = append(missing, MissingPath{Path: path, Suggestion: suggestPath(path, dir)})
+		}
+		written = append(written, writes...)
+	}
+	return missing
+}
+
+// pathArgs returns the words of a simple command that look like paths,
+// split into those it reads, the program itself included, and those it writes
+func pathArgs(words []string) (reads, writes []string) {
+	program := filepath.Base(words[0])
+	if remotePrograms[program] {
+		return nil, nil
+	}
+	last := len(words) - 1
+	if !destinationLast[program] && !(program == "git" && slices.Contains(words, "clone")) {
+		last = -1
+	}
+	creatingArchive := program == "tar" && len(words) > 1 &&
+		(words[1] == "--create" || !strings.HasPrefix(words[1], "--") && strings.Contains(words[1], "c"))
+
+	for i, word := range words {
+		path, ok := pathWord(word)
+		if !ok {
+			continue
+		}
+		switch {
+		case pathCreators[program] && i > 0,
+			i == last,
+			i > 0 && (outputFlags[words[i-1]] || isRedirect(words[i-1])),
+			// The archive tar creates follows its f flag
+			creatingArchive && i > 0 && strings.HasPrefix(words[i-1], "-") && strings.HasSuffix(words[i-1], "f"):
+			writes = append(writes, path)
+		default:
+			reads = append(reads, path)
+		}
+	}
+	return reads, writes
+}
+
+// under reports whether path is one of dirs or inside one
+func under(path string, dirs []string) bool {
+	path = strings.TrimSuffix(path, "/")
+	for _, dir := range dirs {
+		dir = strings.TrimSuffix(dir, "/")
+		if path == dir || strings.HasPrefix(path, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// isRedirect reports whether word is an output redirection operator
+func isRedirect(word string) bool {
+	return strings.HasSuffix(word, ">") || strings.HasSuffix(word, ">>") || word == ">|"
+}
+
+// pathWord returns word unquoted if it clearly looks like a local path
+func pathWord(word string) (string, bool) {
+	if len(word) >= 2 && (word[0] == '\'' || word[0] == '"') && word[len(word)-1] == word[0] {
+		word = word[1 : len(word)-1]
+	}
+	// Flags, URLs, and words the shell expands can't be checked
+	if word == "" || strings.HasPrefix(word, "-") || strings.Contains(word, "://") ||
+		strings.ContainsAny(word, "$`'\"*?[]{}()<>|&;=:\\") {
+		return "", false
+	}
+	switch {
+	case strings.HasPrefix(word, "./"), strings.HasPrefix(word, "../"), strings.HasPrefix(word, "~/"):
+		return word, true
+	case strings.HasPrefix(word, "/"):
+		// Virtual filesystems come and go
+		for _, prefix := range []string{"/dev/", "/proc/", "/sys/", "/tmp/"} {
+			if strings.HasPrefix(word, prefix) {
+				return "", false
+			}
+		}
+		return word, true
+	case strings.Contains(strings.TrimSuffix(word, "/"), "/") && fileExtRe.MatchString(word):
+		return word, true
+	}
+	return "", false
+}
+
+// resolvePath returns path as an absolute path, relative to dir
+func resolvePath(path, dir string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// suggestPath looks under dir for a file or directory with the same name
+// as path and returns the shallowest, relative to dir
+func suggestPath(path, dir string) string {
+	name := filepath.Base(strings.TrimSuffix(path, "/"))
+	if name == "." || name == ".." || name == "/" {
+		return ""
+	}
+	var best string
+	entries := 0
+	filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		entries++
+		if entries > suggestMaxEntries {
+			return filepath.SkipAll
+		}
+		rel, relErr := filepath.Rel(dir, p)
+		if relErr != nil || rel == "." {
+			return nil
+		}
+		depth := strings.Count(rel, string(filepath.Separator)) + 1
+		if d.IsDir() && (suggestSkipDirs[d.Name()] || depth >= suggestMaxDepth) {
+			if d.Name() == name && best == "" {
+				best = rel
+			}
+			return filepath.SkipDir
+		}
+		if d.Name() == name && (best == "" || depth < strings.Count(best, string(filepath.Separator))+1) {
+			best = rel
+		}
+		return nil
+	})
+	return filepath.ToSlash(best)
+}
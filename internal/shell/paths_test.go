@@ -0,0 +1,77 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMissingPaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, path := range []string{"scripts/deploy.sh", "src/main.go", "deep/a/b/c/d/deploy.sh", "node_modules/x/config.yaml"} {
+		full := filepath.Join(dir, path)
+		os.MkdirAll(filepath.Dir(full), 0755)
+		os.WriteFile(full, nil, 0644)
+	}
+
+	tests := []struct {
+		name    string
+		command string
+		want    []MissingPath
+	}{
+		{"existing paths", "cat src/main.go ./scripts/deploy.sh", nil},
+		{"missing script with suggestion", "./deploy.sh --prod", []MissingPath{{"./deploy.sh", "scripts/deploy.sh"}}},
+		{"missing without suggestion", "go run cmd/server/app.go", []MissingPath{{"cmd/server/app.go", ""}}},
+		{"suggestion by name", "go run cmd/main.go", []MissingPath{{"cmd/main.go", "src/main.go"}}},
+		{"skipped dirs are not suggested", "vim ./config.yaml", []MissingPath{{"./config.yaml", ""}}},
+		{"quoted path", `cat "./notes.txt"`, []MissingPath{{"./notes.txt", ""}}},
+		{"bare words are not paths", "grep -r TODO main.go internal", nil},
+		{"flags, urls, and globs", "curl -o out.json https://x.io/a.json && ls ./*.go $HOME/x.txt", nil},
+		{"redirect and output targets", "sort ./scripts/deploy.sh > ./sorted.txt && gcc -o ./bin/app src/main.go", nil},
+		{"copy destination", "cp src/main.go ./backup/main.go", nil},
+		{"created earlier in the command", "mkdir -p ./out && touch ./out/log.txt && cat ./out/log.txt", nil},
+		{"tar archive being created", "tar -czf ./release.tgz src/main.go", nil},
+		{"tar archive being read", "tar -xzf ./release.tgz", []MissingPath{{"./release.tgz", ""}}},
+		{"remote commands", "ssh host ./remote.sh && docker exec web /app/run.sh", nil},
+		{"stops at cd", "cd sub && ./build.sh", nil},
+		{"virtual filesystems", "cat /proc/cpuinfo /dev/null", nil},
+		{"reported once", "./missing.sh; ./missing.sh", []MissingPath{{"./missing.sh", ""}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MissingPaths(tt.command, dir); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MissingPaths(%q) = %+v, want %+v", tt.command, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMissingPathWarning(t *testing.T) {
+	if got := (MissingPath{"./deploy.sh", "scripts/deploy.sh"}).Warning(); got != "./deploy.sh not found - did you mean scripts/deploy.sh?" {
+		t.Errorf("Warning() = %q", got)
+	}
+	if got := (MissingPath{Path: "./deploy.sh"}).Warning(); got != "./deploy.sh not found" {
+		t.Errorf("Warning() = %q", got)
+	}
+}
+
+func TestSuggestPathBounded(t *testing.T) {
+	deep := t.TempDir()
+	os.MkdirAll(filepath.Join(deep, "a", "b", "c"), 0755)
+	os.WriteFile(filepath.Join(deep, "a", "b", "c", "deploy.sh"), nil, 0644)
+	if got := suggestPath("./deploy.sh", deep); got != "" {
+		t.Errorf("suggestPath() = %q, want nothing below the depth limit", got)
+	}
+
+	wide := t.TempDir()
+	for i := 0; i <= suggestMaxEntries; i++ {
+		os.WriteFile(filepath.Join(wide, fmt.Sprintf("file%04d.txt", i)), nil, 0644)
+	}
+	os.MkdirAll(filepath.Join(wide, "scripts"), 0755)
+	os.WriteFile(filepath.Join(wide, "scripts", "deploy.sh"), nil, 0644)
+	if got := suggestPath("./deploy.sh", wide); got != "" {
+		t.Errorf("suggestPath() = %q, want the search to stop after %d entries", got, suggestMaxEntries)
+	}
+}
@@ -0,0 +1,269 @@
+package shell
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bastio-ai/bast/internal/ai"
+	"github.com/bastio-ai/bast/internal/target"
+)
+
+// venvDirs are where projects usually keep their virtualenv
+var venvDirs = []string{".venv", "venv", "env"}
+
+// nodeVersionFiles pin the Node version a project expects
+var nodeVersionFiles = []string{".nvmrc", ".node-version"}
+
+// condaEnvFiles define a project's conda environment
+var condaEnvFiles = []string{"environment.yml", "environment.yaml"}
+
+// nvmBinVersionRe finds the version in $NVM_BIN, e.g. ~/.nvm/versions/node/v20.11.0/bin
+var nvmBinVersionRe = regexp.MustCompile(`/v(\d+(?:\.\d+)*)/bin/?$`)
+
+// AddRuntimeEnvs fills in the Python virtualenv, conda env, and Node version
+// the project in ctx.CWD uses, and whether the user's shell has them active.
+// It reads the environment the shell hook launched bast with, and should run
+// after AddToolVersions, whose node version it falls back on.
+func AddRuntimeEnvs(ctx ai.ShellContext) ai.ShellContext {
+	if env := detectVirtualenv(ctx.CWD, ctx.Shell); env != nil {
+		ctx.Runtimes = append(ctx.Runtimes, *env)
+	}
+	if env := detectConda(ctx.CWD); env != nil {
+		ctx.Runtimes = append(ctx.Runtimes, *env)
+	}
+	if env := detectNode(ctx.CWD, ctx.Tools); env != nil {
+		ctx.Runtimes = append(ctx.Runtimes, *env)
+	}
+	return ctx
+}
+
+// detectVirtualenv finds the project's virtualenv, or the active one when
+// the project has none
+func detectVirtualenv(cwd, shellName string) *ai.RuntimeEnv {
+	active := os.Getenv("VIRTUAL_ENV")
+	path := findUp(cwd, func(dir string) string {
+		for _, name := range venvDirs {
+			venv := filepath.Join(dir, name)
+			if _, err := os.Stat(filepath.Join(venv, "pyvenv.cfg")); err == nil {
+				return venv
+			}
+		}
+		return ""
+	})
+	if path == "" {
+		if active == "" {
+			return nil
+		}
+		return &ai.RuntimeEnv{Kind: ai.RuntimeVirtualenv, Name: active, Active: true, Bin: filepath.Join(active, "bin")}
+	}
+
+	env := &ai.RuntimeEnv{Kind: ai.RuntimeVirtualenv, Name: path, Bin: filepath.Join(path, "bin")}
+	if active != "" && sameDir(active, path) {
+		env.Active = true
+		return env
+	}
+	env.Current = active
+	script := filepath.Join(relativeTo(cwd, path), "bin", "activate")
+	switch shellName {
+	case "fish":
+		script += ".fish"
+	case "csh", "tcsh":
+		script += ".csh"
+	}
+	env.Activate = "source " + target.Quote(script)
+	return env
+}
+
+// detectConda finds the conda env the project's environment.yml names, or
+// the active env when there is no such file
+func detectConda(cwd string) *ai.RuntimeEnv {
+	active := os.Getenv("CONDA_DEFAULT_ENV")
+	name := findUp(cwd, func(dir string) string {
+		for _, file := range condaEnvFiles {
+			if name := condaEnvName(filepath.Join(dir, file)); name != "" {
+				return name
+			}
+		}
+		return ""
+	})
+	if name == "" {
+		if active == "" || active == "base" {
+			return nil
+		}
+		return &ai.RuntimeEnv{Kind: ai.RuntimeConda, Name: active, Active: true, Bin: condaBin()}
+	}
+
+	env := &ai.RuntimeEnv{Kind: ai.RuntimeConda, Name: name}
+	if active == name {
+		env.Active = true
+		env.Bin = condaBin()
+		return env
+	}
+	env.Current = active
+	env.Activate = "conda activate " + target.Quote(name)
+	return env
+}
+
+// condaEnvName reads the name: line of a conda environment file
+func condaEnvName(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "name:"); ok {
+			return strings.Trim(strings.TrimSpace(value), `"'`)
+		}
+	}
+	return ""
+}
+
+// condaBin is the executables directory of the active conda env
+func condaBin() string {
+	if prefix := os.Getenv("CONDA_PREFIX"); prefix != "" {
+		return filepath.Join(prefix, "bin")
+	}
+	return ""
+}
+
+// detectNode finds the Node version the project pins in .nvmrc or
+// .node-version, and whether the one in use matches
+func detectNode(cwd string, tools []ai.ToolVersion) *ai.RuntimeEnv {
+	wanted := findUp(cwd, func(dir string) string {
+		for _, file := range nodeVersionFiles {
+			if data, err := os.ReadFile(filepath.Join(dir, file)); err == nil {
+				if line, _, _ := strings.Cut(strings.TrimSpace(string(data)), "\n"); line != "" {
+					return strings.TrimSpace(line)
+				}
+			}
+		}
+		return ""
+	})
+	if wanted == "" {
+		return nil
+	}
+
+	current := ""
+	if m := nvmBinVersionRe.FindStringSubmatch(os.Getenv("NVM_BIN")); m != nil {
+		current = m[1]
+	} else {
+		for _, tool := range tools {
+			if tool.Name == "node" {
+				current = tool.Version
+			}
+		}
+	}
+
+	env := &ai.RuntimeEnv{Kind: ai.RuntimeNode, Name: wanted}
+	switch {
+	case !versionPinned(wanted):
+		// Aliases like lts/* can't be checked against a version
+		env.Active = true
+	case current == "":
+		// No node found, so nothing to compare
+	case nodeVersionMatches(wanted, current):
+		env.Active = true
+	default:
+		env.Current = current
+		env.Activate = "nvm use"
+	}
+	return env
+}
+
+// versionPinned reports whether a version file names a version number
+// rather than an alias like lts/iron
+func versionPinned(wanted string) bool {
+	wanted = strings.TrimPrefix(wanted, "v")
+	return wanted != "" && wanted[0] >= '0' && wanted[0] <= '9'
+}
+
+// nodeVersionMatches reports whether current satisfies wanted, which may
+// give only a major or major.minor version
+func nodeVersionMatches(wanted, current string) bool {
+	wanted = strings.TrimPrefix(wanted, "v")
+	current = strings.TrimPrefix(current, "v")
+	return current == wanted || strings.HasPrefix(current, wanted+".")
+}
+
+// findUp calls find on dir and its parents, stopping at the repository
+// root, and returns the first non-empty result
+func findUp(dir string, find func(dir string) string) string {
+	for {
+		if found := find(dir); found != "" {
+			return found
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// sameDir reports whether a and b are the same directory
+func sameDir(a, b string) bool {
+	if filepath.Clean(a) == filepath.Clean(b) {
+		return true
+	}
+	ra, errA := filepath.EvalSymlinks(a)
+	rb, errB := filepath.EvalSymlinks(b)
+	return errA == nil && errB == nil && ra == rb
+}
+
+// relativeTo returns path relative to dir when it is inside it
+func relativeTo(dir, path string) string {
+	if rel, err := filepath.Rel(dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// runtimeCommands are the executables each kind of environment provides
+var runtimeCommands = map[string][]string{
+	ai.RuntimeVirtualenv: {"python", "python3", "pip", "pip3", "pytest", "uvicorn", "django-admin", "flask", "black", "ruff", "mypy"},
+	ai.RuntimeConda:      {"python", "python3", "pip", "pip3", "pytest", "jupyter", "ipython"},
+	ai.RuntimeNode:       {"node", "npm", "npx", "yarn", "pnpm"},
+}
+
+// RuntimeWarnings describes the environments a command needs that aren't
+// active in the user's shell, such as running pip outside the project's
+// virtualenv
+func RuntimeWarnings(command string, runtimes []ai.RuntimeEnv) []string {
+	var warnings []string
+	for _, env := range runtimes {
+		if env.Active || env.Activate == "" || strings.Contains(command, env.Activate) || !usesRuntime(command, env.Kind) {
+			continue
+		}
+		switch env.Kind {
+		case ai.RuntimeVirtualenv:
+			warnings = append(warnings, "The project virtualenv isn't active; run `"+env.Activate+"` first")
+		case ai.RuntimeConda:
+			warnings = append(warnings, "The project's conda env "+env.Name+" isn't active; run `"+env.Activate+"` first")
+		case ai.RuntimeNode:
+			warnings = append(warnings, "The project wants Node "+env.Name+" but "+env.Current+" is in use; run `"+env.Activate+"` first")
+		}
+	}
+	return warnings
+}
+
+// usesRuntime reports whether any command in the pipeline runs one of the
+// environment's executables by name
+func usesRuntime(command, kind string) bool {
+	for _, segment := range segmentSeparators.Split(command, -1) {
+		word := commandWord(segment)
+		for _, name := range runtimeCommands[kind] {
+			if word == name {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/bastio-ai/bast/internal/ai"
+)
+
+// newRuntimeProject creates a repository root with a sub-directory to work in
+func newRuntimeProject(t *testing.T) (root, sub string) {
+	t.Helper()
+	root = t.TempDir()
+	os.Mkdir(filepath.Join(root, ".git"), 0755)
+	sub = filepath.Join(root, "src")
+	os.Mkdir(sub, 0755)
+	for _, name := range []string{"VIRTUAL_ENV", "CONDA_DEFAULT_ENV", "CONDA_PREFIX", "NVM_BIN"} {
+		t.Setenv(name, "")
+	}
+	return root, sub
+}
+
+func TestDetectVirtualenv(t *testing.T) {
+	root, sub := newRuntimeProject(t)
+	if env := detectVirtualenv(sub, "bash"); env != nil {
+		t.Fatalf("detectVirtualenv() without a venv = %+v, want nil", env)
+	}
+
+	venv := filepath.Join(root, ".venv")
+	os.MkdirAll(filepath.Join(venv, "bin"), 0755)
+	os.WriteFile(filepath.Join(venv, "pyvenv.cfg"), []byte("home = /usr/bin\n"), 0644)
+
+	env := detectVirtualenv(root, "bash")
+	want := &ai.RuntimeEnv{Kind: ai.RuntimeVirtualenv, Name: venv, Activate: "source .venv/bin/activate", Bin: filepath.Join(venv, "bin")}
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("detectVirtualenv() inactive = %+v, want %+v", env, want)
+	}
+	if env := detectVirtualenv(root, "fish"); env.Activate != "source .venv/bin/activate.fish" {
+		t.Errorf("fish Activate = %q", env.Activate)
+	}
+	// From a sub-directory the venv is found above, and named by full path
+	if env := detectVirtualenv(sub, "zsh"); env.Activate != "source "+filepath.Join(venv, "bin", "activate") {
+		t.Errorf("Activate from sub-directory = %q", env.Activate)
+	}
+
+	t.Setenv("VIRTUAL_ENV", venv)
+	if env := detectVirtualenv(sub, "bash"); !env.Active || env.Activate != "" {
+		t.Errorf("detectVirtualenv() active = %+v, want active", env)
+	}
+}
+
+func TestDetectConda(t *testing.T) {
+	root, sub := newRuntimeProject(t)
+	t.Setenv("CONDA_DEFAULT_ENV", "base")
+	if env := detectConda(sub); env != nil {
+		t.Fatalf("detectConda() in base without environment.yml = %+v, want nil", env)
+	}
+
+	os.WriteFile(filepath.Join(root, "environment.yml"), []byte("name: ml-project\nchannels:\n  - conda-forge\n"), 0644)
+	env := detectConda(sub)
+	want := &ai.RuntimeEnv{Kind: ai.RuntimeConda, Name: "ml-project", Current: "base", Activate: "conda activate ml-project"}
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("detectConda() = %+v, want %+v", env, want)
+	}
+
+	t.Setenv("CONDA_DEFAULT_ENV", "ml-project")
+	t.Setenv("CONDA_PREFIX", "/opt/conda/envs/ml-project")
+	if env := detectConda(sub); !env.Active || env.Bin != "/opt/conda/envs/ml-project/bin" {
+		t.Errorf("detectConda() active = %+v", env)
+	}
+}
+
+func TestDetectNode(t *testing.T) {
+	root, sub := newRuntimeProject(t)
+	if env := detectNode(sub, nil); env != nil {
+		t.Fatalf("detectNode() without .nvmrc = %+v, want nil", env)
+	}
+
+	os.WriteFile(filepath.Join(root, ".nvmrc"), []byte("v20\n"), 0644)
+	tests := []struct {
+		name   string
+		nvmBin string
+		tools  []ai.ToolVersion
+		want   ai.RuntimeEnv
+	}{
+		{"matching nvm version", "/home/u/.nvm/versions/node/v20.11.0/bin", nil, ai.RuntimeEnv{Kind: ai.RuntimeNode, Name: "v20", Active: true}},
+		{"other nvm version", "/home/u/.nvm/versions/node/v18.19.1/bin", nil, ai.RuntimeEnv{Kind: ai.RuntimeNode, Name: "v20", Current: "18.19.1", Activate: "nvm use"}},
+		{"probed version", "", []ai.ToolVersion{{Name: "node", Version: "20.1.0"}}, ai.RuntimeEnv{Kind: ai.RuntimeNode, Name: "v20", Active: true}},
+		{"no node", "", nil, ai.RuntimeEnv{Kind: ai.RuntimeNode, Name: "v20"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NVM_BIN", tt.nvmBin)
+			if env := detectNode(sub, tt.tools); !reflect.DeepEqual(*env, tt.want) {
+				t.Errorf("detectNode() = %+v, want %+v", *env, tt.want)
+			}
+		})
+	}
+
+	os.WriteFile(filepath.Join(root, ".nvmrc"), []byte("lts/iron\n"), 0644)
+	if env := detectNode(sub, []ai.ToolVersion{{Name: "node", Version: "18.0.0"}}); !env.Active {
+		t.Errorf("detectNode() with an alias = %+v, want it left unchecked", env)
+	}
+}
+
+func TestRuntimeWarnings(t *testing.T) {
+	runtimes := []ai.RuntimeEnv{
+		{Kind: ai.RuntimeVirtualenv, Name: "/p/.venv", Activate: "source .venv/bin/activate"},
+		{Kind: ai.RuntimeNode, Name: "20", Current: "18.19.1", Activate: "nvm use"},
+		{Kind: ai.RuntimeConda, Name: "ml", Active: true},
+	}
+
+	tests := []struct {
+		command string
+		want    int
+	}{
+		{"pip install -r requirements.txt", 1},
+		{"source .venv/bin/activate && pip install requests", 0},
+		{"npm ci && pytest -q", 2},
+		{"ls -la", 0},
+		{".venv/bin/python manage.py migrate", 0},
+	}
+	for _, tt := range tests {
+		if got := RuntimeWarnings(tt.command, runtimes); len(got) != tt.want {
+			t.Errorf("RuntimeWarnings(%q) = %v, want %d warnings", tt.command, got, tt.want)
+		}
+	}
+}
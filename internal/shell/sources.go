@@ -0,0 +1,61 @@
+package shell
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Context sources that can be turned off with context.disable or --no-context
+const (
+	SourceHistory    = "history"     // Shell history and the last command
+	SourceGit        = "git"         // Repository state
+	SourceLastOutput = "last_output" // Output captured from the last command
+	SourceProject    = "project"     // Project type and layout, workspace, direnv, tool versions
+	SourceIdentity   = "identity"    // Username
+)
+
+// Sources lists every context source in the order they are shown
+var Sources = []string{SourceHistory, SourceGit, SourceLastOutput, SourceProject, SourceIdentity}
+
+var disabled = map[string]bool{}
+
+// Configure turns off the named context sources for this run. Unknown
+// names are an error, so a typo doesn't silently leave a source on.
+func Configure(disable []string) error {
+	off := map[string]bool{}
+	for _, name := range disable {
+		name = strings.TrimSpace(name)
+		if !isSource(name) {
+			return fmt.Errorf("unknown context source %q (want one of %s)", name, strings.Join(Sources, ", "))
+		}
+		off[name] = true
+	}
+	disabled = off
+	return nil
+}
+
+// Enabled reports whether source is gathered for prompts
+func Enabled(source string) bool {
+	return !disabled[source]
+}
+
+// ActiveSources returns the enabled and disabled sources, in Sources order
+func ActiveSources() (on, off []string) {
+	for _, source := range Sources {
+		if Enabled(source) {
+			on = append(on, source)
+		} else {
+			off = append(off, source)
+		}
+	}
+	return on, off
+}
+
+func isSource(name string) bool {
+	for _, source := range Sources {
+		if name == source {
+			return true
+		}
+	}
+	return false
+}
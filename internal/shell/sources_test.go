@@ -0,0 +1,49 @@
+package shell
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfigureRejectsUnknownSource(t *testing.T) {
+	t.Cleanup(func() { Configure(nil) })
+
+	err := Configure([]string{"git", "histroy"})
+	if err == nil || !strings.Contains(err.Error(), `"histroy"`) {
+		t.Fatalf("Configure() error = %v, want unknown source", err)
+	}
+	if !Enabled(SourceGit) {
+		t.Error("a failed Configure changed the enabled sources")
+	}
+}
+
+func TestDisabledSourcesAreNotGathered(t *testing.T) {
+	t.Cleanup(func() { Configure(nil) })
+	t.Setenv("BAST_LAST_CMD", "make test")
+	t.Setenv("BAST_EXIT_STATUS", "2")
+	t.Setenv("BAST_LAST_OUTPUT", "FAIL: TestParse")
+
+	if err := Configure([]string{SourceHistory, SourceLastOutput, SourceIdentity}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := GetBaseContext()
+	if ctx.LastCommand != "" || ctx.ExitStatus != 0 {
+		t.Errorf("last command gathered with history off: %q (%d)", ctx.LastCommand, ctx.ExitStatus)
+	}
+	if ctx.User != "unknown" {
+		t.Errorf("User = %q with identity off, want unknown", ctx.User)
+	}
+	if stdout, _ := LastOutput(); stdout != "" {
+		t.Errorf("LastOutput() = %q with last_output off", stdout)
+	}
+
+	on, off := ActiveSources()
+	if strings.Join(on, ",") != "git,project" || strings.Join(off, ",") != "history,last_output,identity" {
+		t.Errorf("ActiveSources() = %v, %v", on, off)
+	}
+
+	Configure(nil)
+	if ctx := GetBaseContext(); ctx.LastCommand != "make test" {
+		t.Errorf("LastCommand = %q with history on", ctx.LastCommand)
+	}
+}
@@ -0,0 +1,97 @@
+package shell
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// syntaxTimeout bounds a syntax check
+const syntaxTimeout = time.Second
+
+// syntaxCheckArgs are the parse-only invocations of each shell, with its
+// startup files skipped. Shells not listed aren't checked.
+var syntaxCheckArgs = map[string][]string{
+	"bash": {"bash", "--norc", "--noprofile", "-n", "-c"},
+	"zsh":  {"zsh", "-f", "-n", "-c"},
+	"fish": {"fish", "--no-config", "--no-execute", "-c"},
+	"sh":   {"sh", "-n", "-c"},
+	"dash": {"dash", "-n", "-c"},
+	"ksh":  {"ksh", "-n", "-c"},
+	"mksh": {"mksh", "-n", "-c"},
+}
+
+// syntaxPrefixRe matches the location prefix shells put on parse errors,
+// e.g. "bash: -c: line 1: " or "zsh:1: "
+var syntaxPrefixRe = regexp.MustCompile(`^(?:\S+?:\s*)?(?:-c:\s*)?(?:line \d+:\s*|\d+:\s*)?`)
+
+// SyntaxError is a command the user's shell can't parse
+type SyntaxError struct {
+	Shell   string
+	Message string // The shell's error, without its location prefix
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("doesn't parse in %s: %s", e.Shell, e.Message)
+}
+
+// CheckSyntax parses command with the named shell without running it, like
+// sh -n, and returns a *SyntaxError when it doesn't parse. Shells without a
+// parse-only mode, or that aren't installed, aren't checked.
+func CheckSyntax(shellName, command string) error {
+	args, ok := syntaxCheckArgs[shellName]
+	if !ok {
+		return nil
+	}
+	if _, err := exec.LookPath(args[0]); err != nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), syntaxTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, args[0], append(args[1:], command)...)
+	// Non-interactive shells source these, which could run something
+	cmd.Env = withoutVars(os.Environ(), "BASH_ENV", "ENV")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if err == nil || ctx.Err() != nil || !errors.As(err, &exitErr) {
+		return nil
+	}
+	return &SyntaxError{Shell: shellName, Message: syntaxMessage(stderr.String())}
+}
+
+// syntaxMessage returns the first line of a shell's parse error, without
+// its location prefix
+func syntaxMessage(stderr string) string {
+	for _, line := range strings.Split(stderr, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return syntaxPrefixRe.ReplaceAllString(line, "")
+		}
+	}
+	return "syntax error"
+}
+
+// withoutVars returns env without the named variables
+func withoutVars(env []string, names ...string) []string {
+	var kept []string
+	for _, entry := range env {
+		name, _, _ := strings.Cut(entry, "=")
+		drop := false
+		for _, n := range names {
+			drop = drop || name == n
+		}
+		if !drop {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,74 @@
+package shell
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestCheckSyntax(t *testing.T) {
+	for _, shellName := range []string{"bash", "sh"} {
+		if _, err := exec.LookPath(shellName); err != nil {
+			continue
+		}
+		t.Run(shellName, func(t *testing.T) {
+			valid := []string{
+				"echo ok && ls -la",
+				"for f in *.log; do\n  gzip \"$f\"\ndone",
+				"cat <<EOF > notes.txt\nit's here\nEOF",
+			}
+			for _, command := range valid {
+				if err := CheckSyntax(shellName, command); err != nil {
+					t.Errorf("CheckSyntax(%q) = %v, want nil", command, err)
+				}
+			}
+
+			invalid := []string{"echo 'unterminated", "for f in *; do echo $f", "ls |", "if true; then echo"}
+			for _, command := range invalid {
+				err := CheckSyntax(shellName, command)
+				var syntaxErr *SyntaxError
+				if !errors.As(err, &syntaxErr) {
+					t.Errorf("CheckSyntax(%q) = %v, want a SyntaxError", command, err)
+					continue
+				}
+				if syntaxErr.Shell != shellName || syntaxErr.Message == "" {
+					t.Errorf("CheckSyntax(%q) = %+v", command, syntaxErr)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckSyntax_NothingRuns(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not installed")
+	}
+	marker := t.TempDir() + "/ran"
+	if err := CheckSyntax("sh", "touch "+marker); err != nil {
+		t.Fatalf("CheckSyntax() = %v", err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("CheckSyntax ran the command")
+	}
+}
+
+func TestCheckSyntax_UnknownShell(t *testing.T) {
+	if err := CheckSyntax("nu", "echo 'unterminated"); err != nil {
+		t.Errorf("CheckSyntax(nu) = %v, want nil for shells it can't check", err)
+	}
+}
+
+func TestSyntaxMessage(t *testing.T) {
+	tests := []struct{ stderr, want string }{
+		{"bash: -c: line 1: unexpected EOF while looking for matching `''\n", "unexpected EOF while looking for matching `''"},
+		{"sh: 1: Syntax error: Unterminated quoted string\n", "Syntax error: Unterminated quoted string"},
+		{"zsh:1: unmatched '\n", "unmatched '"},
+		{"", "syntax error"},
+	}
+	for _, tt := range tests {
+		if got := syntaxMessage(tt.stderr); got != tt.want {
+			t.Errorf("syntaxMessage(%q) = %q, want %q", tt.stderr, got, tt.want)
+		}
+	}
+}
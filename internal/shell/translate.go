@@ -0,0 +1,176 @@
+package shell
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/bastio-ai/bast/internal/safety"
+)
+
+// Translation is a rewrite of a generated command for the user's OS
+type Translation struct {
+	Original string
+	Command  string
+	Reason   string // Why the rewrite was suggested, for display
+}
+
+// packageManager describes how to spell common operations for one tool
+type packageManager struct {
+	sudo bool              // Operations need root
+	ops  map[string]string // Operation -> subcommand
+}
+
+// packageManagers is the translation table; operations are install, remove,
+// refresh (update the package index), upgrade, and search
+var packageManagers = map[string]packageManager{
+	"apt": {sudo: true, ops: map[string]string{
+		"install": "install", "remove": "remove", "refresh": "update", "upgrade": "upgrade", "search": "search",
+	}},
+	"dnf": {sudo: true, ops: map[string]string{
+		"install": "install", "remove": "remove", "refresh": "check-update", "upgrade": "upgrade", "search": "search",
+	}},
+	"brew": {sudo: false, ops: map[string]string{
+		"install": "install", "remove": "uninstall", "refresh": "update", "upgrade": "upgrade", "search": "search",
+	}},
+}
+
+// packageManagerAliases maps other spellings onto a table entry
+var packageManagerAliases = map[string]string{
+	"apt-get": "apt",
+	"yum":     "dnf",
+}
+
+// subcommandOps maps every known subcommand back to its operation
+var subcommandOps = map[string]string{
+	"install": "install", "remove": "remove", "uninstall": "remove", "rm": "remove", "purge": "remove",
+	"update": "refresh", "check-update": "refresh", "upgrade": "upgrade", "search": "search",
+}
+
+// yesFlags are auto-confirm flags that brew does not accept
+var yesFlags = map[string]bool{"-y": true, "--yes": true, "--assume-yes": true, "--assumeyes": true}
+
+// serviceOps maps systemctl verbs to launchctl and back
+var (
+	systemctlToLaunchctl = map[string]string{"start": "start", "stop": "stop", "status": "list"}
+	launchctlToSystemctl = map[string]string{"start": "start", "stop": "stop", "list": "status"}
+)
+
+// TranslateCommand rewrites package manager and service commands meant for a
+// different OS. pkgManager is the target's package manager; empty detects it.
+// Returns nil when the command needs no translation.
+func TranslateCommand(command, goos, pkgManager string) *Translation {
+	if pkgManager == "" {
+		pkgManager = detectPackageManager(goos)
+	}
+
+	segments := strings.Split(command, "&&")
+	var reasons []string
+	changed := false
+	for i, segment := range segments {
+		translated, reason := translateSegment(strings.TrimSpace(segment), goos, pkgManager)
+		if reason == "" {
+			continue
+		}
+		segments[i] = translated
+		reasons = append(reasons, reason)
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+
+	for i := range segments {
+		segments[i] = strings.TrimSpace(segments[i])
+	}
+	return &Translation{
+		Original: command,
+		Command:  strings.Join(segments, " && "),
+		Reason:   strings.Join(reasons, "; "),
+	}
+}
+
+// translateSegment translates one command; reason is empty when unchanged
+func translateSegment(segment, goos, pkgManager string) (string, string) {
+	fields := strings.Fields(safety.StripSudo(segment))
+	if len(fields) < 2 {
+		return segment, ""
+	}
+	tool := fields[0]
+	if alias, ok := packageManagerAliases[tool]; ok {
+		tool = alias
+	}
+
+	// Service management
+	switch {
+	case tool == "systemctl" && goos == "darwin":
+		if verb, ok := systemctlToLaunchctl[fields[1]]; ok && len(fields) == 3 {
+			cmd := fmt.Sprintf("launchctl %s %s", verb, fields[2])
+			return cmd, "systemctl is not available on macOS; using launchctl"
+		}
+		return segment, ""
+	case tool == "launchctl" && goos == "linux":
+		if verb, ok := launchctlToSystemctl[fields[1]]; ok && len(fields) == 3 {
+			cmd := fmt.Sprintf("sudo systemctl %s %s", verb, fields[2])
+			return cmd, "launchctl is not available on Linux; using systemctl"
+		}
+		return segment, ""
+	}
+
+	// Package management
+	source, ok := packageManagers[tool]
+	if !ok || pkgManager == "" || tool == pkgManager {
+		return segment, ""
+	}
+	target := packageManagers[pkgManager]
+	op, ok := subcommandOps[fields[1]]
+	if !ok || source.ops[op] == "" {
+		return segment, ""
+	}
+
+	var args []string
+	for _, arg := range fields[2:] {
+		if !yesFlags[arg] {
+			args = append(args, arg)
+		}
+	}
+
+	parts := []string{pkgManager, target.ops[op]}
+	if target.sudo {
+		parts = append([]string{"sudo"}, parts...)
+		// Keep installs non-interactive like the brew original
+		if op == "install" || op == "remove" || op == "upgrade" {
+			parts = append(parts, "-y")
+		}
+	}
+	parts = append(parts, args...)
+	reason := fmt.Sprintf("%s is not the package manager on %s; using %s", fields[0], osName(goos), pkgManager)
+	return strings.Join(parts, " "), reason
+}
+
+// detectPackageManager returns the package manager available on goos
+func detectPackageManager(goos string) string {
+	if goos == "darwin" {
+		return "brew"
+	}
+	if goos != "linux" {
+		return ""
+	}
+	for _, name := range []string{"apt", "dnf"} {
+		if _, err := exec.LookPath(name); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// osName returns a display name for a GOOS value
+func osName(goos string) string {
+	switch goos {
+	case "darwin":
+		return "macOS"
+	case "linux":
+		return "Linux"
+	}
+	return goos
+}
@@ -0,0 +1,61 @@
+package shell
+
+import "testing"
+
+func TestTranslateCommand(t *testing.T) {
+	tests := []struct {
+		name       string
+		command    string
+		goos       string
+		pkgManager string
+		expected   string // empty means no translation
+	}{
+		// Linux commands on macOS
+		{"apt install on macOS", "sudo apt install -y jq", "darwin", "brew", "brew install jq"},
+		{"apt-get remove on macOS", "sudo apt-get remove htop", "darwin", "brew", "brew uninstall htop"},
+		{"apt update and upgrade on macOS", "sudo apt update && sudo apt upgrade -y", "darwin", "brew", "brew update && brew upgrade"},
+		{"dnf search on macOS", "dnf search ripgrep", "darwin", "brew", "brew search ripgrep"},
+		{"yum install on macOS", "sudo yum install -y git", "darwin", "brew", "brew install git"},
+		{"systemctl start on macOS", "sudo systemctl start nginx", "darwin", "brew", "launchctl start nginx"},
+		{"systemctl status on macOS", "systemctl status postgresql", "darwin", "brew", "launchctl list postgresql"},
+
+		// macOS commands on Linux
+		{"brew install on debian", "brew install jq", "linux", "apt", "sudo apt install -y jq"},
+		{"brew uninstall on fedora", "brew uninstall htop", "linux", "dnf", "sudo dnf remove -y htop"},
+		{"brew update on fedora", "brew update", "linux", "dnf", "sudo dnf check-update"},
+		{"launchctl stop on linux", "launchctl stop nginx", "linux", "apt", "sudo systemctl stop nginx"},
+
+		// Between Linux package managers
+		{"apt on fedora", "sudo apt install curl", "linux", "dnf", "sudo dnf install -y curl"},
+
+		// Already correct or unknown
+		{"brew on macOS", "brew install jq", "darwin", "brew", ""},
+		{"apt on debian", "sudo apt install jq", "linux", "apt", ""},
+		{"no package manager known", "brew install jq", "freebsd", "", ""},
+		{"unrelated command", "ls -la", "darwin", "brew", ""},
+		{"unknown apt subcommand", "apt-cache policy jq", "darwin", "brew", ""},
+		{"systemctl without unit", "systemctl list-units", "darwin", "brew", ""},
+		{"mixed with other commands", "cd /tmp && ls", "darwin", "brew", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TranslateCommand(tt.command, tt.goos, tt.pkgManager)
+			if tt.expected == "" {
+				if got != nil {
+					t.Errorf("TranslateCommand(%q) = %q, want no translation", tt.command, got.Command)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("TranslateCommand(%q) = nil, want %q", tt.command, tt.expected)
+			}
+			if got.Command != tt.expected {
+				t.Errorf("TranslateCommand(%q) = %q, want %q", tt.command, got.Command, tt.expected)
+			}
+			if got.Original != tt.command || got.Reason == "" {
+				t.Errorf("unexpected translation metadata: %+v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package shell
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/bastio-ai/bast/internal/ai"
+)
+
+// versionTimeout bounds all version probes together
+const versionTimeout = 400 * time.Millisecond
+
+// versionProbe describes how to find a tool's version and when it is relevant
+type versionProbe struct {
+	name    string   // Display name
+	args    []string // Command that prints the version
+	markers []string // Project files that make the tool relevant
+}
+
+// versionProbes are the tools whose versions change which flags are valid.
+// docker compose and docker-compose are probed separately so the prompt
+// says which spelling is installed.
+var versionProbes = []versionProbe{
+	{"go", []string{"go", "env", "GOVERSION"}, []string{"go.mod", "go.work"}},
+	{"node", []string{"node", "--version"}, []string{"package.json", ".nvmrc"}},
+	{"python", []string{"python3", "--version"}, []string{"pyproject.toml", "requirements.txt", "setup.py", "Pipfile"}},
+	{"docker", []string{"docker", "--version"}, dockerMarkers},
+	{"docker compose", []string{"docker", "compose", "version"}, dockerMarkers},
+	{"docker-compose", []string{"docker-compose", "--version"}, dockerMarkers},
+	{"kubectl", []string{"kubectl", "version", "--client"}, []string{"kustomization.yaml", "Chart.yaml", "skaffold.yaml", "k8s"}},
+}
+
+var dockerMarkers = []string{"Dockerfile", "compose.yaml", "compose.yml", "docker-compose.yml", "docker-compose.yaml"}
+
+var versionRe = regexp.MustCompile(`\d+\.\d+(?:\.\d+)?`)
+
+// AddToolVersions fills in the versions of tools the project in ctx.CWD uses.
+// Probes run concurrently; any still running at the timeout are dropped.
+func AddToolVersions(ctx ai.ShellContext) ai.ShellContext {
+	probes := relevantProbes(ctx.CWD)
+	if len(probes) == 0 {
+		return ctx
+	}
+
+	runCtx, cancel := context.WithTimeout(context.Background(), versionTimeout)
+	defer cancel()
+
+	versions := make([]string, len(probes))
+	var wg sync.WaitGroup
+	for i, probe := range probes {
+		if _, err := exec.LookPath(probe.args[0]); err != nil {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, probe versionProbe) {
+			defer wg.Done()
+			cmd := exec.CommandContext(runCtx, probe.args[0], probe.args[1:]...)
+			cmd.Dir = ctx.CWD
+			out, err := cmd.Output()
+			if err != nil {
+				return
+			}
+			versions[i] = parseVersion(string(out))
+		}(i, probe)
+	}
+	wg.Wait()
+
+	for i, probe := range probes {
+		if versions[i] != "" {
+			ctx.Tools = append(ctx.Tools, ai.ToolVersion{Name: probe.name, Version: versions[i]})
+		}
+	}
+	return ctx
+}
+
+// relevantProbes returns the probes whose marker files exist in dir or one
+// of its parents, stopping at the repository root
+func relevantProbes(dir string) []versionProbe {
+	var probes []versionProbe
+	for _, probe := range versionProbes {
+		if hasMarker(dir, probe.markers) {
+			probes = append(probes, probe)
+		}
+	}
+	return probes
+}
+
+// hasMarker reports whether any marker exists between dir and the repository root
+func hasMarker(dir string, markers []string) bool {
+	for {
+		for _, marker := range markers {
+			if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+				return true
+			}
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return false
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return false
+		}
+		dir = parent
+	}
+}
+
+// parseVersion extracts the first version number from a tool's version output
+func parseVersion(out string) string {
+	return versionRe.FindString(out)
+}
@@ -0,0 +1,47 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct{ out, want string }{
+		{"go1.24.1\n", "1.24.1"},
+		{"v20.11.0\n", "20.11.0"},
+		{"Python 3.12.2\n", "3.12.2"},
+		{"Docker version 27.0.3, build 7d4bcd8\n", "27.0.3"},
+		{"Docker Compose version v2.27.0\n", "2.27.0"},
+		{"Client Version: v1.30.1\nKustomize Version: v5.0.4\n", "1.30.1"},
+		{"no version here", ""},
+	}
+	for _, tt := range tests {
+		if got := parseVersion(tt.out); got != tt.want {
+			t.Errorf("parseVersion(%q) = %q, want %q", tt.out, got, tt.want)
+		}
+	}
+}
+
+func TestRelevantProbes(t *testing.T) {
+	root := t.TempDir()
+	os.Mkdir(filepath.Join(root, ".git"), 0755)
+	os.WriteFile(filepath.Join(root, "go.mod"), []byte("module x\n"), 0644)
+	os.WriteFile(filepath.Join(root, "Dockerfile"), []byte("FROM scratch\n"), 0644)
+	sub := filepath.Join(root, "cmd", "app")
+	os.MkdirAll(sub, 0755)
+
+	var names []string
+	for _, p := range relevantProbes(sub) {
+		names = append(names, p.name)
+	}
+	want := []string{"go", "docker", "docker compose", "docker-compose"}
+	if len(names) != len(want) {
+		t.Fatalf("relevantProbes() = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("relevantProbes()[%d] = %q, want %q", i, names[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bastio-ai/bast/internal/target"
+)
+
+// ChangeDir makes dir the working directory for context gathering, file
+// reading, and tools. A leading ~ is expanded and relative paths resolve
+// against the current working directory. Returns the absolute path.
+func ChangeDir(dir string) (string, error) {
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to find home directory: %w", err)
+		}
+		dir = filepath.Join(home, dir[1:])
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("invalid directory %q: %w", dir, err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", fmt.Errorf("cannot use directory %q: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("not a directory: %s", abs)
+	}
+	if err := os.Chdir(abs); err != nil {
+		return "", fmt.Errorf("failed to change to %s: %w", abs, err)
+	}
+	return abs, nil
+}
+
+// ResolveWorkDir resolves a working directory a generated command asked for,
+// relative to cwd unless absolute, and checks it is a directory
+func ResolveWorkDir(cwd, dir string) (string, error) {
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(cwd, dir)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", fmt.Errorf("cannot run in %s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("not a directory: %s", dir)
+	}
+	return filepath.Clean(dir), nil
+}
+
+// InDir wraps command so the user's shell, which is still in from, runs it
+// in dir. The subshell leaves the shell's own directory unchanged.
+func InDir(command, dir, from string) string {
+	if dir == "" || dir == from {
+		return command
+	}
+	return fmt.Sprintf("(cd %s && %s)", target.Quote(dir), command)
+}
+
+// JoinArgs renders args as a command line, quoting each as needed, to show
+// a command that runs without a shell
+func JoinArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = target.Quote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,89 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestChangeDir(t *testing.T) {
+	orig, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(orig) })
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "file"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ChangeDir(dir)
+	if err != nil {
+		t.Fatalf("ChangeDir(%q) error: %v", dir, err)
+	}
+	if got != dir {
+		t.Errorf("ChangeDir(%q) = %q", dir, got)
+	}
+
+	// Relative paths resolve against the new directory
+	got, err = ChangeDir("sub")
+	if err != nil {
+		t.Fatalf("ChangeDir(sub) error: %v", err)
+	}
+	if want := filepath.Join(dir, "sub"); got != want {
+		t.Errorf("ChangeDir(sub) = %q, want %q", got, want)
+	}
+
+	if _, err := ChangeDir(filepath.Join(dir, "file")); err == nil {
+		t.Error("ChangeDir on a file should fail")
+	}
+	if _, err := ChangeDir(filepath.Join(dir, "missing")); err == nil {
+		t.Error("ChangeDir on a missing path should fail")
+	}
+}
+
+func TestResolveWorkDir(t *testing.T) {
+	cwd := t.TempDir()
+	sub := filepath.Join(cwd, "packages", "api")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(cwd, "file"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := ResolveWorkDir(cwd, "packages/api/"); err != nil || got != sub {
+		t.Errorf("ResolveWorkDir(relative) = %q, %v, want %q", got, err, sub)
+	}
+	if got, err := ResolveWorkDir(cwd, sub); err != nil || got != sub {
+		t.Errorf("ResolveWorkDir(absolute) = %q, %v, want %q", got, err, sub)
+	}
+	for _, dir := range []string{"missing", "file"} {
+		if _, err := ResolveWorkDir(cwd, dir); err == nil {
+			t.Errorf("ResolveWorkDir(%q) succeeded, want error", dir)
+		}
+	}
+}
+
+func TestInDir(t *testing.T) {
+	tests := []struct {
+		name, command, dir, from, want string
+	}{
+		{"same directory", "ls", "/src", "/src", "ls"},
+		{"no directory", "ls", "", "/src", "ls"},
+		{"other directory", "make test", "/src/api", "/src", "(cd /src/api && make test)"},
+		{"quoted", "ls", "/tmp/my dir", "/src", "(cd '/tmp/my dir' && ls)"},
+		{"single quote", "ls", "/tmp/it's", "/src", `(cd '/tmp/it'\''s' && ls)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InDir(tt.command, tt.dir, tt.from); got != tt.want {
+				t.Errorf("InDir() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
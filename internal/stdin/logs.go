@@ -0,0 +1,350 @@
+package stdin
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxDigestGroups caps the notable entries listed in a log digest
+const maxDigestGroups = 25
+
+// maxDigestMessage caps each message shown in a log digest
+const maxDigestMessage = 160
+
+// logEntry is one parsed log line
+type logEntry struct {
+	Time    string
+	Level   string // Normalized: fatal, error, warn, info, debug, or empty
+	Source  string // Logger, unit, or Kubernetes object
+	Message string
+}
+
+// logFormat recognizes one structured log format
+type logFormat struct {
+	name  string
+	parse func(lines []string) ([]logEntry, bool)
+}
+
+// logFormats are tried in order; the first that parses the input wins
+var logFormats = []logFormat{
+	{"Kubernetes events", parseKubeEvents},
+	{"JSON lines", parseJSONLines},
+	{"journald", parseJournald},
+	{"logfmt", parseLogfmt},
+}
+
+// notableEvent matches messages worth surfacing whatever their level
+var notableEvent = regexp.MustCompile(`(?i)\b(oom\w*|out of memory|restart\w*|back-?off|crashloop\w*|killed|killing|evicted|unhealthy|panic\w*)\b`)
+
+// DigestLogs detects structured logs (JSON lines, logfmt, journald, or
+// kubectl get events) in piped input and returns a compact digest: the
+// format, counts by level, and the notable entries grouped by message.
+// Returns "" when the input is not a recognized log format.
+func DigestLogs(input string) string {
+	var lines []string
+	for _, line := range strings.Split(input, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) < 2 {
+		return ""
+	}
+
+	for _, format := range logFormats {
+		if entries, ok := format.parse(lines); ok {
+			return formatDigest(format.name, entries)
+		}
+	}
+	return ""
+}
+
+// mostlyParsed reports whether enough lines parsed to trust a format
+func mostlyParsed(parsed, total int) bool {
+	return parsed > 0 && parsed*5 >= total*4
+}
+
+// parseJSONLines parses one JSON object per line, including journalctl -o json
+func parseJSONLines(lines []string) ([]logEntry, bool) {
+	var entries []logEntry
+	for _, line := range lines {
+		var fields map[string]any
+		if json.Unmarshal([]byte(strings.TrimSpace(line)), &fields) != nil {
+			continue
+		}
+		entry := logEntry{
+			Time:    pickField(fields, "time", "ts", "timestamp", "@timestamp", "__REALTIME_TIMESTAMP"),
+			Level:   normalizeLevel(pickField(fields, "level", "lvl", "severity", "log.level", "PRIORITY")),
+			Source:  pickField(fields, "logger", "component", "caller", "_SYSTEMD_UNIT", "SYSLOG_IDENTIFIER"),
+			Message: pickField(fields, "msg", "message", "MESSAGE", "log"),
+		}
+		if err := pickField(fields, "error", "err"); err != "" {
+			entry.Message = strings.TrimSpace(entry.Message + ": " + err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, mostlyParsed(len(entries), len(lines))
+}
+
+// logfmtPair matches key=value pairs, with quoted values
+var logfmtPair = regexp.MustCompile(`([\w.\-]+)=("(?:[^"\\]|\\.)*"|\S*)`)
+
+// parseLogfmt parses key=value lines such as those written by logrus and Go's slog
+func parseLogfmt(lines []string) ([]logEntry, bool) {
+	var entries []logEntry
+	for _, line := range lines {
+		pairs := logfmtPair.FindAllStringSubmatch(line, -1)
+		if len(pairs) < 2 {
+			continue
+		}
+		fields := make(map[string]any, len(pairs))
+		for _, pair := range pairs {
+			value := pair[2]
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				value = unquoted
+			}
+			fields[pair[1]] = value
+		}
+		entry := logEntry{
+			Time:    pickField(fields, "time", "ts", "timestamp"),
+			Level:   normalizeLevel(pickField(fields, "level", "lvl", "severity")),
+			Source:  pickField(fields, "logger", "component", "caller", "source"),
+			Message: pickField(fields, "msg", "message"),
+		}
+		if err := pickField(fields, "error", "err"); err != "" {
+			entry.Message = strings.TrimSpace(entry.Message + ": " + err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, mostlyParsed(len(entries), len(lines))
+}
+
+// journaldLine matches journalctl's short and short-iso output formats
+var journaldLine = regexp.MustCompile(`^(\w{3} [ \d]\d \d{2}:\d{2}:\d{2}|\d{4}-\d{2}-\d{2}T\S+) (\S+) ([^\s:\[]+)(?:\[\d+\])?: (.*)$`)
+
+// parseJournald parses journalctl output, inferring levels from the messages
+func parseJournald(lines []string) ([]logEntry, bool) {
+	var entries []logEntry
+	for _, line := range lines {
+		m := journaldLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		entries = append(entries, logEntry{Time: m[1], Source: m[3], Message: m[4]})
+	}
+	return entries, mostlyParsed(len(entries), len(lines))
+}
+
+// kubeEventColumns are the columns of kubectl get events, in any order
+var kubeEventColumns = []string{"NAMESPACE", "LAST SEEN", "TYPE", "REASON", "OBJECT", "MESSAGE"}
+
+// parseKubeEvents parses the table printed by kubectl get events
+func parseKubeEvents(lines []string) ([]logEntry, bool) {
+	header := lines[0]
+	if !strings.Contains(header, "REASON") || !strings.Contains(header, "MESSAGE") {
+		return nil, false
+	}
+
+	type column struct {
+		name  string
+		start int
+	}
+	var columns []column
+	for _, name := range kubeEventColumns {
+		if i := strings.Index(header, name); i >= 0 {
+			columns = append(columns, column{name, i})
+		}
+	}
+	sort.Slice(columns, func(i, j int) bool { return columns[i].start < columns[j].start })
+
+	var entries []logEntry
+	for _, line := range lines[1:] {
+		cells := make(map[string]string, len(columns))
+		for i, col := range columns {
+			if col.start >= len(line) {
+				break
+			}
+			end := len(line)
+			if i+1 < len(columns) && columns[i+1].start < end {
+				end = columns[i+1].start
+			}
+			cells[col.name] = strings.TrimSpace(line[col.start:end])
+		}
+		if cells["REASON"] == "" {
+			continue
+		}
+		entries = append(entries, logEntry{
+			Time:    cells["LAST SEEN"],
+			Level:   normalizeLevel(cells["TYPE"]),
+			Source:  cells["OBJECT"],
+			Message: cells["REASON"] + ": " + cells["MESSAGE"],
+		})
+	}
+	return entries, mostlyParsed(len(entries), len(lines)-1)
+}
+
+// pickField returns the first of keys present in fields, as a string
+func pickField(fields map[string]any, keys ...string) string {
+	for _, key := range keys {
+		switch v := fields[key].(type) {
+		case nil:
+			continue
+		case string:
+			return v
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			return fmt.Sprint(v)
+		}
+	}
+	return ""
+}
+
+// normalizeLevel maps level names, syslog priorities, and Kubernetes event
+// types onto fatal, error, warn, info, and debug
+func normalizeLevel(level string) string {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "":
+		return ""
+	case "fatal", "panic", "crit", "critical", "emerg", "emergency", "alert", "0", "1", "2":
+		return "fatal"
+	case "error", "err", "3":
+		return "error"
+	case "warn", "warning", "4":
+		return "warn"
+	case "info", "notice", "normal", "5", "6":
+		return "info"
+	case "debug", "trace", "7":
+		return "debug"
+	default:
+		return strings.ToLower(level)
+	}
+}
+
+// severity orders levels for the digest, most severe first
+func severity(level string) int {
+	switch level {
+	case "fatal":
+		return 0
+	case "error":
+		return 1
+	case "warn":
+		return 2
+	default:
+		return 3
+	}
+}
+
+// logGroup is a set of notable entries sharing a message shape
+type logGroup struct {
+	first logEntry
+	count int
+}
+
+// formatDigest summarizes parsed entries for a prompt
+func formatDigest(format string, entries []logEntry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Parsed as %s: %d entries", format, len(entries))
+
+	var first, last string
+	for _, e := range entries {
+		if e.Time != "" {
+			if first == "" {
+				first = e.Time
+			}
+			last = e.Time
+		}
+	}
+	if first != "" && first != last {
+		fmt.Fprintf(&b, ", %s to %s", first, last)
+	}
+	b.WriteString("\n")
+
+	// Infer missing levels from the message before counting
+	levels := make(map[string]int)
+	for i, e := range entries {
+		if e.Level == "" && errorLine.MatchString(e.Message) {
+			entries[i].Level = "error"
+		}
+		if entries[i].Level != "" {
+			levels[entries[i].Level]++
+		}
+	}
+	if len(levels) > 0 {
+		names := make([]string, 0, len(levels))
+		for name := range levels {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if severity(names[i]) != severity(names[j]) {
+				return severity(names[i]) < severity(names[j])
+			}
+			return names[i] < names[j]
+		})
+		counts := make([]string, len(names))
+		for i, name := range names {
+			counts[i] = fmt.Sprintf("%s %d", name, levels[name])
+		}
+		fmt.Fprintf(&b, "Levels: %s\n", strings.Join(counts, ", "))
+	}
+
+	// Group notable entries by source and message shape
+	var groups []*logGroup
+	byShape := make(map[string]*logGroup)
+	for _, e := range entries {
+		if severity(e.Level) > 2 && !notableEvent.MatchString(e.Message) {
+			continue
+		}
+		shape := e.Source + "\x00" + digits.ReplaceAllString(e.Message, "#")
+		if g, ok := byShape[shape]; ok {
+			g.count++
+			continue
+		}
+		g := &logGroup{first: e, count: 1}
+		byShape[shape] = g
+		groups = append(groups, g)
+	}
+	if len(groups) == 0 {
+		b.WriteString("No errors, warnings, restarts, or OOM kills found.")
+		return b.String()
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		si, sj := severity(groups[i].first.Level), severity(groups[j].first.Level)
+		if si != sj {
+			return si < sj
+		}
+		return groups[i].count > groups[j].count
+	})
+
+	b.WriteString("Notable entries (count, level, source, first seen, message):\n")
+	for i, g := range groups {
+		if i == maxDigestGroups {
+			fmt.Fprintf(&b, "... and %d more\n", len(groups)-maxDigestGroups)
+			break
+		}
+		e := g.first
+		message := e.Message
+		if len(message) > maxDigestMessage {
+			message = message[:maxDigestMessage] + "..."
+		}
+		level := e.Level
+		if level == "" {
+			level = "-"
+		}
+		fmt.Fprintf(&b, "  x%d  %s  %s  %s  %s\n", g.count, level, orDash(e.Source), orDash(e.Time), message)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// orDash returns s, or "-" when it is empty
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
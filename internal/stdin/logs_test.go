@@ -0,0 +1,71 @@
+package stdin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDigestLogs(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name: "json lines",
+			input: `{"time":"2024-05-01T10:00:00Z","level":"info","msg":"started"}
+{"time":"2024-05-01T10:00:01Z","level":"error","msg":"query failed","error":"timeout after 30s"}
+{"time":"2024-05-01T10:00:02Z","level":"error","msg":"query failed","error":"timeout after 31s"}
+{"time":"2024-05-01T10:00:03Z","level":"info","msg":"done"}`,
+			want: []string{
+				"Parsed as JSON lines: 4 entries, 2024-05-01T10:00:00Z to 2024-05-01T10:00:03Z",
+				"Levels: error 2, info 2",
+				"x2  error  -  2024-05-01T10:00:01Z  query failed: timeout after 30s",
+			},
+		},
+		{
+			name: "logfmt",
+			input: `time=2024-05-01T10:00:00Z level=INFO msg="server listening" addr=:8080
+time=2024-05-01T10:00:05Z level=WARN msg="slow request" path=/api took=2.1s`,
+			want: []string{"Parsed as logfmt: 2 entries", "Levels: warn 1, info 1", "x1  warn  -  2024-05-01T10:00:05Z  slow request"},
+		},
+		{
+			name: "journald",
+			input: `May 01 10:00:00 web1 systemd[1]: Started api.service.
+May 01 10:00:09 web1 kernel: Out of memory: Killed process 4242 (api)
+May 01 10:00:10 web1 systemd[1]: api.service: Scheduled restart job, restart counter is at 3.`,
+			want: []string{"Parsed as journald: 3 entries", "kernel  May 01 10:00:09  Out of memory: Killed process 4242 (api)", "restart counter is at 3"},
+		},
+		{
+			name: "kubernetes events",
+			input: `LAST SEEN   TYPE      REASON      OBJECT          MESSAGE
+2m          Normal    Pulled      pod/api-7d9f    Container image "api:1.2" already present
+90s         Warning   BackOff     pod/api-7d9f    Back-off restarting failed container
+60s         Warning   OOMKilling  node/worker-1   Memory cgroup out of memory: Killed process 311`,
+			want: []string{"Parsed as Kubernetes events: 3 entries", "Levels: warn 2, info 1", "x1  warn  pod/api-7d9f  90s  BackOff: Back-off restarting failed container", "node/worker-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DigestLogs(tt.input)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("digest missing %q:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestDigestLogsPlainText(t *testing.T) {
+	for _, input := range []string{
+		"total 8\ndrwxr-xr-x 2 me me 4096 May 1 10:00 .\n-rw-r--r-- 1 me me 12 May 1 10:00 notes",
+		"PATH=/usr/bin\nHOME=/home/me",
+		`{"single": "object"}`,
+	} {
+		if got := DigestLogs(input); got != "" {
+			t.Errorf("DigestLogs(%q) = %q, want no digest", input, got)
+		}
+	}
+}
@@ -7,11 +7,18 @@ import (
 	"io"
 	"os"
 	"strings"
+	"unicode/utf8"
+
+	"github.com/bastio-ai/bast/internal/files"
 )
 
 // MaxInputSize is the maximum size of input to process (100KB)
 const MaxInputSize = 100 * 1024
 
+// MaxReadSize is how much piped input is read before it is summarized down
+// to MaxInputSize (16MB)
+const MaxReadSize = 16 * 1024 * 1024
+
 // HeadSize is how much to keep from the beginning when truncating
 const HeadSize = 40 * 1024
 
@@ -27,37 +34,82 @@ func IsPiped() bool {
 	return (stat.Mode() & os.ModeCharDevice) == 0
 }
 
-// Read reads all content from stdin up to MaxInputSize
-func Read() (string, error) {
+// binarySampleSize is how much of the input is checked for binary content
+const binarySampleSize = 8 * 1024
+
+// Read reads all content from stdin up to MaxReadSize. truncated reports
+// whether there was more input past the limit.
+func Read() (content string, truncated bool, err error) {
 	return ReadFrom(os.Stdin)
 }
 
-// ReadFrom reads all content from the given reader up to MaxInputSize
-func ReadFrom(r io.Reader) (string, error) {
+// ReadFrom reads all content from the given reader up to MaxReadSize.
+// truncated reports whether there was more input past the limit.
+func ReadFrom(r io.Reader) (content string, truncated bool, err error) {
 	var sb strings.Builder
 	reader := bufio.NewReader(r)
 	buf := make([]byte, 4096)
 	totalRead := 0
 
-	for totalRead < MaxInputSize {
+	for totalRead < MaxReadSize {
 		n, err := reader.Read(buf)
 		if n > 0 {
 			// Don't exceed max size
-			if totalRead+n > MaxInputSize {
-				n = MaxInputSize - totalRead
+			if totalRead+n > MaxReadSize {
+				n = MaxReadSize - totalRead
+				truncated = true
 			}
 			sb.Write(buf[:n])
 			totalRead += n
 		}
 		if err != nil {
 			if err == io.EOF {
-				break
+				return sb.String(), truncated, nil
 			}
-			return sb.String(), err
+			return sb.String(), truncated, err
 		}
 	}
 
-	return sb.String(), nil
+	// At the limit: anything left means the input was cut
+	if _, err := reader.Peek(1); err == nil {
+		truncated = true
+	}
+	return sb.String(), truncated, nil
+}
+
+// IsBinary reports whether piped content looks binary, judging by its start
+func IsBinary(content string) bool {
+	cut := len(content)
+	if cut > binarySampleSize {
+		cut = binarySampleSize
+		// Don't split a multi-byte character and mistake it for binary
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+	}
+	return files.IsBinary(content[:cut])
+}
+
+// TailLines returns the last n lines of content
+func TailLines(content string, n int) string {
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if n <= 0 || len(lines) <= n {
+		return content
+	}
+	return strings.Join(lines[len(lines)-n:], "\n") + "\n"
+}
+
+// TailBytes returns the last n bytes of content, starting at a character
+// boundary
+func TailBytes(content string, n int) string {
+	if n <= 0 || len(content) <= n {
+		return content
+	}
+	start := len(content) - n
+	for start < len(content) && !utf8.RuneStart(content[start]) {
+		start++
+	}
+	return content[start:]
 }
 
 // Truncate intelligently truncates content to fit within maxSize
@@ -0,0 +1,44 @@
+package stdin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadFromTruncated(t *testing.T) {
+	content, truncated, err := ReadFrom(strings.NewReader("short input"))
+	if err != nil || truncated || content != "short input" {
+		t.Errorf("ReadFrom(short) = %q, %v, %v", content, truncated, err)
+	}
+
+	content, truncated, err = ReadFrom(strings.NewReader(strings.Repeat("x", MaxReadSize+10)))
+	if err != nil || !truncated || len(content) != MaxReadSize {
+		t.Errorf("ReadFrom(oversized) = %d bytes, %v, %v; want %d bytes, truncated", len(content), truncated, err, MaxReadSize)
+	}
+}
+
+func TestIsBinary(t *testing.T) {
+	if IsBinary("plain text\nwith lines\n") {
+		t.Error("text reported as binary")
+	}
+	if !IsBinary("\x7fELF\x02\x01\x01\x00\x00") {
+		t.Error("ELF header not reported as binary")
+	}
+	// A multi-byte character straddling the sample boundary is still text
+	text := strings.Repeat("a", binarySampleSize-1) + "é and more"
+	if IsBinary(text) {
+		t.Error("character split by the sample reported as binary")
+	}
+}
+
+func TestTail(t *testing.T) {
+	if got := TailLines("one\ntwo\nthree\n", 2); got != "two\nthree\n" {
+		t.Errorf("TailLines() = %q", got)
+	}
+	if got := TailLines("one\ntwo\n", 5); got != "one\ntwo\n" {
+		t.Errorf("TailLines() = %q, want unchanged", got)
+	}
+	if got := TailBytes("héllo", 4); got != "llo" {
+		t.Errorf("TailBytes() = %q, want %q", got, "llo")
+	}
+}
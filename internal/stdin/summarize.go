@@ -0,0 +1,106 @@
+package stdin
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// errorLine matches log lines worth keeping when the middle of a large
+// input is dropped
+var errorLine = regexp.MustCompile(`(?i)\b(error|errors|fail|failed|failure|fatal|panic|exception|traceback|critical|denied|refused|timeout|timed out|killed|oom)\b`)
+
+// digits matches the parts of a line that vary between otherwise repeated
+// log lines (timestamps, counters, ids, durations)
+var digits = regexp.MustCompile(`[0-9]+`)
+
+// Summarize shrinks content to about maxSize bytes for a prompt. Unlike
+// Truncate it understands logs: runs of lines that differ only in numbers
+// are collapsed to one line with a repeat count, and when that is not enough
+// the head and tail are kept along with the error lines from the middle.
+func Summarize(content string, maxSize int) string {
+	if len(content) <= maxSize {
+		return content
+	}
+
+	lines := collapseRepeats(strings.Split(content, "\n"))
+	if collapsed := strings.Join(lines, "\n"); len(collapsed) <= maxSize {
+		return collapsed
+	}
+
+	// A third of the budget goes to errors, the rest to head and tail
+	errBudget := maxSize / 3
+	headBudget := (maxSize - errBudget) / 2
+	tailBudget := maxSize - errBudget - headBudget
+
+	keep := make([]bool, len(lines))
+	head := 0
+	for used := 0; head < len(lines) && used+len(lines[head])+1 <= headBudget; head++ {
+		used += len(lines[head]) + 1
+		keep[head] = true
+	}
+	tail := len(lines)
+	for used := 0; tail > head && used+len(lines[tail-1])+1 <= tailBudget; tail-- {
+		used += len(lines[tail-1]) + 1
+		keep[tail-1] = true
+	}
+
+	// Errors from the middle, skipping ones already seen in another form
+	seen := make(map[string]bool)
+	used := 0
+	for i := head; i < tail; i++ {
+		if !errorLine.MatchString(lines[i]) {
+			continue
+		}
+		shape := digits.ReplaceAllString(lines[i], "#")
+		if seen[shape] || used+len(lines[i])+1 > errBudget {
+			continue
+		}
+		seen[shape] = true
+		used += len(lines[i]) + 1
+		keep[i] = true
+	}
+
+	if head == 0 && tail == len(lines) {
+		// A single huge line; fall back to byte truncation
+		return Truncate(content, maxSize)
+	}
+
+	var b strings.Builder
+	omitted := 0
+	for i, line := range lines {
+		if !keep[i] {
+			omitted++
+			continue
+		}
+		if omitted > 0 {
+			fmt.Fprintf(&b, "[... %d lines omitted ...]\n", omitted)
+			omitted = 0
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	if omitted > 0 {
+		fmt.Fprintf(&b, "[... %d lines omitted ...]\n", omitted)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// collapseRepeats replaces each run of consecutive lines that differ only
+// in their numbers with the first line and a count of the rest
+func collapseRepeats(lines []string) []string {
+	var out []string
+	for i := 0; i < len(lines); {
+		shape := digits.ReplaceAllString(lines[i], "#")
+		j := i + 1
+		for j < len(lines) && digits.ReplaceAllString(lines[j], "#") == shape {
+			j++
+		}
+		out = append(out, lines[i])
+		if n := j - i - 1; n > 0 {
+			out = append(out, fmt.Sprintf("[... repeated %d more times ...]", n))
+		}
+		i = j
+	}
+	return out
+}
@@ -0,0 +1,50 @@
+package stdin
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSummarizeCollapsesRepeats(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("starting\n")
+	for i := 0; i < 500; i++ {
+		fmt.Fprintf(&b, "2024-01-01T00:00:%02d GET /health 200 %dms\n", i%60, i)
+	}
+	b.WriteString("done")
+
+	got := Summarize(b.String(), 1000)
+	want := "starting\n2024-01-01T00:00:00 GET /health 200 0ms\n[... repeated 499 more times ...]\ndone"
+	if got != want {
+		t.Errorf("Summarize() = %q, want %q", got, want)
+	}
+}
+
+func TestSummarizeKeepsErrors(t *testing.T) {
+	var lines []string
+	for i := 0; i < 2000; i++ {
+		lines = append(lines, fmt.Sprintf("worker %d processed item %x", i%7, i*7919))
+	}
+	lines[1000] = "worker 3 ERROR: connection refused to db:5432"
+	lines[1001] = "worker 4 ERROR: connection refused to db:5432"
+
+	got := Summarize(strings.Join(lines, "\n"), 2000)
+	if len(got) > 2200 {
+		t.Errorf("summary is %d bytes, want about 2000", len(got))
+	}
+	for _, want := range []string{lines[0], lines[1999], lines[1000], "lines omitted"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("summary missing %q", want)
+		}
+	}
+	if strings.Contains(got, lines[1001]) {
+		t.Error("summary repeated an error that differs only in numbers")
+	}
+}
+
+func TestSummarizeShortInput(t *testing.T) {
+	if got := Summarize("ok", 10); got != "ok" {
+		t.Errorf("Summarize() = %q, want unchanged", got)
+	}
+}
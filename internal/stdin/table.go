@@ -0,0 +1,297 @@
+package stdin
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxTableColumns caps the columns described in a table digest
+const maxTableColumns = 40
+
+// dateLayouts are the date formats recognized in table cells
+var dateLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02", "01/02/2006"}
+
+// tableFormat is a delimiter that piped tables may use
+type tableFormat struct {
+	name  string
+	comma rune
+}
+
+// tableFormats are tried in order; tabs first, since TSV cells often contain commas
+var tableFormats = []tableFormat{{"TSV", '\t'}, {"CSV", ','}}
+
+// DigestTable detects CSV or TSV in piped input and returns a digest of it:
+// the row and column counts, each column's inferred type, and per column
+// statistics (min, max, mean and outliers for numbers, range for dates,
+// most common values for text). Returns "" when the input is not a table.
+func DigestTable(input string) string {
+	for _, format := range tableFormats {
+		if rows, ok := parseTable(input, format.comma); ok {
+			return formatTableDigest(format.name, rows)
+		}
+	}
+	return ""
+}
+
+// parseTable reads input as delimited rows, requiring a header of at least
+// two columns and nearly every row to have the same width
+func parseTable(input string, comma rune) ([][]string, bool) {
+	r := csv.NewReader(strings.NewReader(input))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	rows, err := r.ReadAll()
+	if err != nil || len(rows) < 2 || len(rows[0]) < 2 {
+		return nil, false
+	}
+	width := len(rows[0])
+	consistent := 0
+	for _, row := range rows[1:] {
+		if len(row) == width {
+			consistent++
+		}
+	}
+	if consistent*10 < (len(rows)-1)*9 {
+		return nil, false
+	}
+	return rows, true
+}
+
+// columnStats accumulates what is known about one column
+type columnStats struct {
+	name    string
+	missing int
+
+	numbers []float64
+	rows    []int // Data row of each number, for locating outliers
+	dates   []time.Time
+	counts  map[string]int
+	ints    bool
+	total   int // Non-empty cells
+}
+
+// formatTableDigest summarizes parsed rows for a prompt
+func formatTableDigest(format string, rows [][]string) string {
+	header, data := rows[0], rows[1:]
+	if !looksLikeHeader(header) {
+		header = make([]string, len(rows[0]))
+		for i := range header {
+			header[i] = fmt.Sprintf("column %d", i+1)
+		}
+		data = rows
+	}
+
+	columns := make([]*columnStats, len(header))
+	for i, name := range header {
+		columns[i] = &columnStats{name: strings.TrimSpace(name), counts: make(map[string]int), ints: true}
+	}
+
+	seen := make(map[string]bool)
+	duplicates := 0
+	for r, row := range data {
+		key := strings.Join(row, "\x00")
+		if seen[key] {
+			duplicates++
+		}
+		seen[key] = true
+
+		for i, col := range columns {
+			cell := ""
+			if i < len(row) {
+				cell = strings.TrimSpace(row[i])
+			}
+			col.add(cell, r+1)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Parsed as %s: %d rows, %d columns\n", format, len(data), len(columns))
+	for i, col := range columns {
+		if i == maxTableColumns {
+			fmt.Fprintf(&b, "... and %d more columns\n", len(columns)-maxTableColumns)
+			break
+		}
+		b.WriteString("  " + col.describe() + "\n")
+	}
+	if duplicates > 0 {
+		fmt.Fprintf(&b, "Duplicate rows: %d\n", duplicates)
+	}
+	if short := len(data) - countWidth(data, len(header)); short > 0 {
+		fmt.Fprintf(&b, "Rows with a different number of fields: %d\n", short)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// looksLikeHeader reports whether the first row names the columns: every
+// cell is distinct, non-empty, and not a number
+func looksLikeHeader(header []string) bool {
+	names := make(map[string]bool)
+	for _, cell := range header {
+		cell = strings.TrimSpace(cell)
+		if _, err := strconv.ParseFloat(cell, 64); err == nil || cell == "" || names[cell] {
+			return false
+		}
+		names[cell] = true
+	}
+	return true
+}
+
+// countWidth returns how many rows have exactly width fields
+func countWidth(rows [][]string, width int) int {
+	n := 0
+	for _, row := range rows {
+		if len(row) == width {
+			n++
+		}
+	}
+	return n
+}
+
+// add records one cell of the column
+func (c *columnStats) add(cell string, row int) {
+	if cell == "" {
+		c.missing++
+		return
+	}
+	c.total++
+	c.counts[cell]++
+	if n, err := strconv.ParseFloat(strings.ReplaceAll(cell, "_", ""), 64); err == nil && !math.IsNaN(n) && !math.IsInf(n, 0) {
+		c.numbers = append(c.numbers, n)
+		c.rows = append(c.rows, row)
+		if n != math.Trunc(n) || strings.ContainsAny(cell, ".eE") {
+			c.ints = false
+		}
+		return
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, cell); err == nil {
+			c.dates = append(c.dates, t)
+			return
+		}
+	}
+}
+
+// describe renders the column's type and statistics on one line
+func (c *columnStats) describe() string {
+	var parts []string
+	kind := "text"
+
+	switch {
+	case c.total > 0 && len(c.numbers)*10 >= c.total*9:
+		kind = "number"
+		if c.ints {
+			kind = "integer"
+		}
+		parts = append(parts, c.numberStats()...)
+	case c.total > 0 && len(c.dates)*10 >= c.total*9:
+		kind = "date"
+		sort.Slice(c.dates, func(i, j int) bool { return c.dates[i].Before(c.dates[j]) })
+		parts = append(parts, fmt.Sprintf("%s to %s", formatDate(c.dates[0]), formatDate(c.dates[len(c.dates)-1])))
+	case c.total > 0:
+		parts = append(parts, fmt.Sprintf("%d distinct", len(c.counts)))
+		if len(c.counts) < c.total {
+			parts = append(parts, "top: "+topValues(c.counts, 3))
+		}
+	}
+
+	// A few stray values in a typed column are often the anomaly
+	if odd := c.total - len(c.numbers); kind == "number" || kind == "integer" {
+		if odd > 0 {
+			parts = append(parts, fmt.Sprintf("%d non-numeric", odd))
+		}
+	} else if odd := c.total - len(c.dates); kind == "date" && odd > 0 {
+		parts = append(parts, fmt.Sprintf("%d not dates", odd))
+	}
+	if c.missing > 0 {
+		parts = append(parts, fmt.Sprintf("%d missing", c.missing))
+	}
+
+	line := fmt.Sprintf("%s (%s)", c.name, kind)
+	if len(parts) > 0 {
+		line += ": " + strings.Join(parts, ", ")
+	}
+	return line
+}
+
+// numberStats returns min, max, and mean, and flags values more than three
+// standard deviations from the mean
+func (c *columnStats) numberStats() []string {
+	lo, hi, sum := c.numbers[0], c.numbers[0], 0.0
+	for _, n := range c.numbers {
+		lo, hi, sum = math.Min(lo, n), math.Max(hi, n), sum+n
+	}
+	mean := sum / float64(len(c.numbers))
+	stats := []string{
+		"min " + formatNumber(lo),
+		"max " + formatNumber(hi),
+		"mean " + formatNumber(math.Round(mean*100)/100),
+	}
+
+	variance := 0.0
+	for _, n := range c.numbers {
+		variance += (n - mean) * (n - mean)
+	}
+	stddev := math.Sqrt(variance / float64(len(c.numbers)))
+	if stddev == 0 || len(c.numbers) < 10 {
+		return stats
+	}
+
+	outliers, worst := 0, -1
+	for i, n := range c.numbers {
+		if math.Abs(n-mean) > 3*stddev {
+			outliers++
+			if worst < 0 || math.Abs(n-mean) > math.Abs(c.numbers[worst]-mean) {
+				worst = i
+			}
+		}
+	}
+	if outliers > 0 {
+		stats = append(stats, fmt.Sprintf("%d outliers beyond 3σ (most extreme %s at row %d)",
+			outliers, formatNumber(c.numbers[worst]), c.rows[worst]))
+	}
+	return stats
+}
+
+// topValues lists the n most common values with their counts
+func topValues(counts map[string]int, n int) string {
+	values := make([]string, 0, len(counts))
+	for v := range counts {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if counts[values[i]] != counts[values[j]] {
+			return counts[values[i]] > counts[values[j]]
+		}
+		return values[i] < values[j]
+	})
+	if len(values) > n {
+		values = values[:n]
+	}
+	top := make([]string, len(values))
+	for i, v := range values {
+		if len(v) > 40 {
+			v = v[:40] + "..."
+		}
+		top[i] = fmt.Sprintf("%s %d", v, counts[values[i]])
+	}
+	return strings.Join(top, ", ")
+}
+
+// formatNumber prints n without a trailing fraction when it is whole
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+// formatDate prints t as a date, with the time only when it has one
+func formatDate(t time.Time) string {
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 {
+		return t.Format("2006-01-02")
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,58 @@
+package stdin
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDigestTable(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("id,amount,status,created\n")
+	for i := 1; i <= 30; i++ {
+		amount := fmt.Sprintf("%d.50", 10+i%5)
+		if i == 17 {
+			amount = "99999"
+		}
+		status := "paid"
+		if i%10 == 0 {
+			status = "refunded"
+		}
+		fmt.Fprintf(&b, "%d,%s,%s,2024-01-%02d\n", i, amount, status, i)
+	}
+
+	got := DigestTable(b.String())
+	for _, want := range []string{
+		"Parsed as CSV: 30 rows, 4 columns",
+		"id (integer): min 1, max 30, mean 15.5",
+		"amount (number): min 10.5, max 99999",
+		"1 outliers beyond 3σ (most extreme 99999 at row 17)",
+		"status (text): 2 distinct, top: paid 27, refunded 3",
+		"created (date): 2024-01-01 to 2024-01-30",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("digest missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestDigestTableTSV(t *testing.T) {
+	input := "name\tnote\nalpha\tone, two\nbeta\t\nbeta\t\n"
+	got := DigestTable(input)
+	for _, want := range []string{"Parsed as TSV: 3 rows, 2 columns", "note (text): 1 distinct, 2 missing", "Duplicate rows: 1"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("digest missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestDigestTableNotATable(t *testing.T) {
+	for _, input := range []string{
+		"hello world\nthis is prose, with a comma\nand another line",
+		"just one line, with, commas",
+	} {
+		if got := DigestTable(input); got != "" {
+			t.Errorf("DigestTable(%q) = %q, want no digest", input, got)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// Package target runs commands on an execution target: the local machine, a
+// docker container, or a host over ssh. Only commands move to the target;
+// context is still gathered on the local machine.
+package target
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Target kinds, as written before the colon in a spec like "docker:web"
+const (
+	KindLocal  = "local"
+	KindDocker = "docker"
+	KindSSH    = "ssh"
+)
+
+// Executor builds the processes that run shell commands on a target
+type Executor interface {
+	// Name identifies the target, e.g. "local" or "ssh:dev-box"
+	Name() string
+
+	// Local reports whether commands run on this machine, where local
+	// working directories mean something
+	Local() bool
+
+	// Command returns the process that runs command in dir on the target.
+	// env is the filtered local environment the process starts with; set
+	// holds the session's variable overrides, the only ones a remote target
+	// receives. An empty dir uses the target's default.
+	Command(ctx context.Context, command, dir string, env, set []string) *exec.Cmd
+
+	// Handoff rewrites command to run on the target when typed into the
+	// user's shell. The local executor returns it unchanged.
+	Handoff(command, dir string, set []string) string
+}
+
+// Local returns the executor for this machine
+func Local() Executor {
+	return localExecutor{}
+}
+
+// Parse reads a target spec: "local", "docker:<container>", or "ssh:<host>"
+func Parse(spec string) (Executor, error) {
+	if spec == KindLocal {
+		return Local(), nil
+	}
+	kind, name, ok := strings.Cut(spec, ":")
+	if !ok || name == "" {
+		return nil, fmt.Errorf("invalid target %q: use local, docker:<container>, or ssh:<host>", spec)
+	}
+	// docker and ssh would read a leading dash as an option, such as
+	// ssh's -oProxyCommand, which runs a local command
+	if strings.HasPrefix(name, "-") {
+		return nil, fmt.Errorf("invalid target %q: a container or host name can't start with '-'", spec)
+	}
+	switch kind {
+	case KindDocker:
+		return dockerExecutor{container: name}, nil
+	case KindSSH:
+		return sshExecutor{host: name}, nil
+	}
+	return nil, fmt.Errorf("unknown target kind %q: use local, docker, or ssh", kind)
+}
+
+// Resolve returns the target called name in configured, which maps names to
+// specs, or else parses name as a spec itself
+func Resolve(name string, configured map[string]string) (Executor, error) {
+	if spec, ok := configured[name]; ok {
+		ex, err := Parse(spec)
+		if err != nil {
+			return nil, fmt.Errorf("targets.%s: %w", name, err)
+		}
+		return ex, nil
+	}
+	return Parse(name)
+}
+
+type localExecutor struct{}
+
+func (localExecutor) Name() string { return KindLocal }
+func (localExecutor) Local() bool  { return true }
+
+func (localExecutor) Command(ctx context.Context, command, dir string, env, set []string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	cmd.Env = env // Already carries the overrides
+	return cmd
+}
+
+func (localExecutor) Handoff(command, dir string, set []string) string {
+	return command
+}
+
+// dockerExecutor runs commands in a running container with docker exec
+type dockerExecutor struct {
+	container string
+}
+
+func (d dockerExecutor) Name() string { return KindDocker + ":" + d.container }
+func (d dockerExecutor) Local() bool  { return false }
+
+func (d dockerExecutor) Command(ctx context.Context, command, dir string, env, set []string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "docker", d.args("-i", command, dir, set)...)
+	cmd.Env = env
+	return cmd
+}
+
+func (d dockerExecutor) Handoff(command, dir string, set []string) string {
+	return joinQuoted(append([]string{"docker"}, d.args("-it", command, dir, set)...))
+}
+
+// args builds the docker exec arguments; mode is -i without a terminal
+// and -it with one
+func (d dockerExecutor) args(mode, command, dir string, set []string) []string {
+	args := []string{"exec", mode}
+	if dir != "" {
+		args = append(args, "-w", dir)
+	}
+	for _, kv := range set {
+		args = append(args, "-e", kv)
+	}
+	return append(args, d.container, "sh", "-c", command)
+}
+
+// sshExecutor runs commands on a host with ssh. The host may be an alias
+// from ~/.ssh/config.
+type sshExecutor struct {
+	host string
+}
+
+func (s sshExecutor) Name() string { return KindSSH + ":" + s.host }
+func (s sshExecutor) Local() bool  { return false }
+
+func (s sshExecutor) Command(ctx context.Context, command, dir string, env, set []string) *exec.Cmd {
+	// BatchMode fails instead of prompting for a password nobody can type
+	cmd := exec.CommandContext(ctx, "ssh", "-o", "BatchMode=yes", s.host, "--", remoteScript(command, dir, set))
+	cmd.Env = env
+	return cmd
+}
+
+func (s sshExecutor) Handoff(command, dir string, set []string) string {
+	return joinQuoted([]string{"ssh", "-t", s.host, "--", remoteScript(command, dir, set)})
+}
+
+// remoteScript is the line ssh hands to the remote login shell: change to
+// dir, set the overrides, and run command under sh whatever that shell is
+func remoteScript(command, dir string, set []string) string {
+	var parts []string
+	if dir != "" {
+		parts = append(parts, "cd "+Quote(dir)+" &&")
+	}
+	if len(set) > 0 {
+		parts = append(parts, "env")
+		for _, kv := range set {
+			parts = append(parts, Quote(kv))
+		}
+	}
+	parts = append(parts, "sh", "-c", Quote(command))
+	return strings.Join(parts, " ")
+}
+
+// joinQuoted joins args into a command line, quoting each as needed
+func joinQuoted(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = Quote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// Quote single-quotes s for POSIX shells when it contains special characters
+func Quote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n'\"\\$`!*?[]{}()<>|&;#~=") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,118 @@
+package target
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec    string
+		name    string
+		local   bool
+		wantErr bool
+	}{
+		{"local", "local", true, false},
+		{"docker:web", "docker:web", false, false},
+		{"ssh:dev-box", "ssh:dev-box", false, false},
+		{"ssh:", "", false, true},
+		{"ssh:-oProxyCommand=touch /tmp/x", "", false, true},
+		{"docker:--privileged", "", false, true},
+		{"vm:box", "", false, true},
+		{"web", "", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			ex, err := Parse(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if ex.Name() != tt.name || ex.Local() != tt.local {
+				t.Errorf("Parse(%q) = %s (local %v), want %s (local %v)", tt.spec, ex.Name(), ex.Local(), tt.name, tt.local)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	configured := map[string]string{"dev": "ssh:dev.internal", "broken": "nope"}
+
+	ex, err := Resolve("dev", configured)
+	if err != nil || ex.Name() != "ssh:dev.internal" {
+		t.Errorf("Resolve(dev) = %v, %v; want ssh:dev.internal", ex, err)
+	}
+	if ex, err := Resolve("docker:api", configured); err != nil || ex.Name() != "docker:api" {
+		t.Errorf("Resolve(docker:api) = %v, %v", ex, err)
+	}
+	if _, err := Resolve("broken", configured); err == nil || !strings.Contains(err.Error(), "targets.broken") {
+		t.Errorf("Resolve(broken) error = %v, want it to name the config key", err)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"ls", "ls"},
+		{"", "''"},
+		{"my dir", "'my dir'"},
+		{"it's", `'it'\''s'`},
+		{"A=b", "'A=b'"},
+		{"$HOME", "'$HOME'"},
+	}
+	for _, tt := range tests {
+		if got := Quote(tt.in); got != tt.want {
+			t.Errorf("Quote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDockerExecutor(t *testing.T) {
+	ex, _ := Parse("docker:web")
+	set := []string{"API_URL=http://localhost:8080"}
+
+	cmd := ex.Command(context.Background(), "npm test", "/app", []string{"PATH=/usr/bin"}, set)
+	want := []string{"docker", "exec", "-i", "-w", "/app", "-e", "API_URL=http://localhost:8080", "web", "sh", "-c", "npm test"}
+	if !slices.Equal(cmd.Args, want) {
+		t.Errorf("Command args = %q, want %q", cmd.Args, want)
+	}
+	if cmd.Dir != "" {
+		t.Errorf("Command dir = %q, want the local default", cmd.Dir)
+	}
+
+	got := ex.Handoff("ls -la | head", "", nil)
+	if got != "docker exec -it web sh -c 'ls -la | head'" {
+		t.Errorf("Handoff() = %q", got)
+	}
+}
+
+func TestSSHExecutor(t *testing.T) {
+	ex, _ := Parse("ssh:dev-box")
+
+	cmd := ex.Command(context.Background(), "echo 'hi'", "/srv/app", nil, []string{"DEBUG=1"})
+	want := []string{"ssh", "-o", "BatchMode=yes", "dev-box", "--", `cd /srv/app && env 'DEBUG=1' sh -c 'echo '\''hi'\'''`}
+	if !slices.Equal(cmd.Args, want) {
+		t.Errorf("Command args = %q, want %q", cmd.Args, want)
+	}
+
+	got := ex.Handoff("uptime", "", nil)
+	if got != "ssh -t dev-box -- 'sh -c uptime'" {
+		t.Errorf("Handoff() = %q", got)
+	}
+}
+
+func TestLocalExecutor(t *testing.T) {
+	out, err := Local().Command(context.Background(), "echo $GREETING", t.TempDir(), []string{"GREETING=hello"}, nil).Output()
+	if err != nil || strings.TrimSpace(string(out)) != "hello" {
+		t.Errorf("local command output = %q, %v", out, err)
+	}
+	if got := Local().Handoff("ls", "/tmp", []string{"A=1"}); got != "ls" {
+		t.Errorf("local Handoff() = %q, want the command unchanged", got)
+	}
+}
@@ -0,0 +1,100 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// AuditLogFileName is the file tool call audit records are appended to
+const AuditLogFileName = "audit.log"
+
+// AuditLogger is a SecurityValidator that never blocks; it appends a JSON
+// line per tool call and per output scan so agent activity can be reviewed.
+type AuditLogger struct {
+	mu   sync.Mutex
+	path string
+}
+
+// auditRecord is one line in the audit log
+type auditRecord struct {
+	Time      time.Time `json:"time"`
+	Stage     string    `json:"stage"`
+	Tool      string    `json:"tool"`
+	CallID    string    `json:"call_id,omitempty"`
+	InputHash string    `json:"input_hash,omitempty"`
+	Bytes     int       `json:"bytes,omitempty"`
+}
+
+// DefaultAuditLogPath returns the default path for the audit log
+func DefaultAuditLogPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", "bast", AuditLogFileName), nil
+}
+
+// NewAuditLogger creates an audit logger that appends to path.
+func NewAuditLogger(path string) *AuditLogger {
+	return &AuditLogger{path: path}
+}
+
+// ValidateToolCall records the call and always allows it.
+func (a *AuditLogger) ValidateToolCall(ctx context.Context, call Call) (*ValidationResult, error) {
+	sum := sha256.Sum256(call.Input)
+	if err := a.write(auditRecord{
+		Time:      time.Now(),
+		Stage:     "validate",
+		Tool:      call.Name,
+		CallID:    call.ID,
+		InputHash: hex.EncodeToString(sum[:]),
+	}); err != nil {
+		return nil, err
+	}
+	return &ValidationResult{Action: ActionAllow}, nil
+}
+
+// ScanContent records the output size and always allows it.
+func (a *AuditLogger) ScanContent(ctx context.Context, toolName string, content string) (*ScanResult, error) {
+	if err := a.write(auditRecord{
+		Time:  time.Now(),
+		Stage: "scan",
+		Tool:  toolName,
+		Bytes: len(content),
+	}); err != nil {
+		return nil, err
+	}
+	return &ScanResult{Action: ScanActionAllow}, nil
+}
+
+// write appends a record to the audit log
+func (a *AuditLogger) write(record auditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(a.path), 0755); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
@@ -6,10 +6,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
-	"os"
+	"net/http/httptrace"
+	"sync"
 	"time"
+
+	"github.com/bastio-ai/bast/internal/debug"
+	"github.com/bastio-ai/bast/internal/useragent"
 )
 
 // BastioSecurityClient handles tool call validation and content scanning
@@ -19,7 +22,29 @@ type BastioSecurityClient struct {
 	proxyID   string
 	apiKey    string
 	sessionID string
+	deviceID  string
 	client    *http.Client
+
+	mu      sync.Mutex
+	metrics SecurityMetrics
+}
+
+// securityHTTPClient is shared by every BastioSecurityClient so that validate
+// and scan calls across agent runs reuse warm keep-alive connections.
+var securityHTTPClient = &http.Client{
+	Timeout:   30 * time.Second,
+	Transport: &debug.Transport{Base: newSecurityTransport()},
+}
+
+// newSecurityTransport tunes the default transport for many small sequential
+// requests to a single host; the default keeps only 2 idle connections per host.
+func newSecurityTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConns = 32
+	t.MaxIdleConnsPerHost = 16
+	t.IdleConnTimeout = 90 * time.Second
+	t.ForceAttemptHTTP2 = true
+	return t
 }
 
 // NewBastioSecurityClient creates a new Bastio security client.
@@ -29,10 +54,68 @@ func NewBastioSecurityClient(baseURL, proxyID, apiKey, sessionID string) *Bastio
 		proxyID:   proxyID,
 		apiKey:    apiKey,
 		sessionID: sessionID,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
+		client:    securityHTTPClient,
+	}
+}
+
+// SecurityMetrics records the round-trip overhead of security API calls
+type SecurityMetrics struct {
+	Calls       int           // Requests sent
+	ReusedConns int           // Requests that reused a keep-alive connection
+	Total       time.Duration // Total time spent waiting on the API
+	Max         time.Duration // Slowest single call
+}
+
+// Average returns the mean per-call overhead
+func (m SecurityMetrics) Average() time.Duration {
+	if m.Calls == 0 {
+		return 0
+	}
+	return m.Total / time.Duration(m.Calls)
+}
+
+// Metrics returns a snapshot of the client's per-call overhead. The running
+// totals are also written to the debug log (--debug) after every call.
+func (c *BastioSecurityClient) Metrics() SecurityMetrics {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.metrics
+}
+
+// do sends req, recording its latency and whether the connection was reused
+func (c *BastioSecurityClient) do(req *http.Request) (*http.Response, error) {
+	var reused bool
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			reused = info.Reused
 		},
 	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	elapsed := time.Since(start)
+
+	c.mu.Lock()
+	c.metrics.Calls++
+	if reused {
+		c.metrics.ReusedConns++
+	}
+	c.metrics.Total += elapsed
+	if elapsed > c.metrics.Max {
+		c.metrics.Max = elapsed
+	}
+	c.mu.Unlock()
+
+	m := c.Metrics()
+	debug.Logf("bastio %s took %s (connection reused: %t); session so far: %d calls, %d reused, avg %s, max %s",
+		req.URL.Path, elapsed, reused, m.Calls, m.ReusedConns, m.Average(), m.Max)
+	return resp, err
+}
+
+// SetDeviceID sets the device ID reported in the User-Agent header
+func (c *BastioSecurityClient) SetDeviceID(deviceID string) {
+	c.deviceID = deviceID
 }
 
 // ValidationAction represents the action Bastio wants us to take
@@ -52,6 +135,7 @@ type ValidationResult struct {
 	ThreatsDetected []string         `json:"threats_detected"`
 	Message         string           `json:"message"`
 	ApprovalID      string           `json:"approval_id,omitempty"`
+	Verdicts        []Verdict        `json:"-"` // Per-validator decisions when chained
 }
 
 // toolCallRequest is the request body for tool validation
@@ -96,11 +180,6 @@ func (c *BastioSecurityClient) ValidateToolCall(ctx context.Context, call Call)
 
 	url := fmt.Sprintf("%s/v1/guard/%s/agent/validate", c.baseURL, c.proxyID)
 
-	// Debug output
-	if os.Getenv("BAST_DEBUG_HTTP") == "1" {
-		fmt.Fprintf(os.Stderr, "DEBUG SECURITY: ValidateToolCall URL=%s\n", url)
-		fmt.Fprintf(os.Stderr, "DEBUG SECURITY: ValidateToolCall Body=%s\n", string(body))
-	}
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -108,8 +187,9 @@ func (c *BastioSecurityClient) ValidateToolCall(ctx context.Context, call Call)
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	req.Header.Set("User-Agent", useragent.String(c.deviceID))
 
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -149,6 +229,7 @@ type ScanResult struct {
 	ThreatsDetected  []string   `json:"threats_detected"`
 	RiskScore        float64    `json:"risk_score"`
 	Message          string     `json:"message"`
+	Verdicts         []Verdict  `json:"-"` // Per-validator decisions when chained
 }
 
 // contentScanRequest is the request body for output scanning
@@ -175,12 +256,6 @@ func (c *BastioSecurityClient) ScanContent(ctx context.Context, toolName string,
 
 	url := fmt.Sprintf("%s/v1/guard/%s/agent/scan-output", c.baseURL, c.proxyID)
 
-	// Debug output
-	if os.Getenv("BAST_DEBUG_HTTP") == "1" {
-		fmt.Fprintf(os.Stderr, "DEBUG SECURITY: ScanContent URL=%s\n", url)
-		fmt.Fprintf(os.Stderr, "DEBUG SECURITY: ScanContent Body=%s\n", string(body))
-	}
-
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -188,8 +263,9 @@ func (c *BastioSecurityClient) ScanContent(ctx context.Context, toolName string,
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	req.Header.Set("User-Agent", useragent.String(c.deviceID))
 
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -212,11 +288,12 @@ func (c *BastioSecurityClient) ScanContent(ctx context.Context, toolName string,
 	return &result, nil
 }
 
-// LogWarning logs a security warning (used for warn actions)
+// LogWarning logs a security warning (used for warn actions) to the debug
+// log rather than stderr, which would write over the TUI
 func LogWarning(toolName string, message string, threats []string) {
 	if len(threats) > 0 {
-		log.Printf("Security warning for %s: %s (threats: %v)", toolName, message, threats)
+		debug.Logf("Security warning for %s: %s (threats: %v)", toolName, message, threats)
 	} else {
-		log.Printf("Security warning for %s: %s", toolName, message)
+		debug.Logf("Security warning for %s: %s", toolName, message)
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -20,6 +21,9 @@ func TestBastioSecurityClient_ValidateToolCall(t *testing.T) {
 			if r.Header.Get("Authorization") != "Bearer test-key" {
 				t.Errorf("unexpected auth header: %s", r.Header.Get("Authorization"))
 			}
+			if ua := r.Header.Get("User-Agent"); !strings.HasPrefix(ua, "bastio-cli/") || !strings.HasSuffix(ua, "device/device-1") {
+				t.Errorf("unexpected user agent: %s", ua)
+			}
 
 			resp := ValidationResult{
 				Action:    ActionAllow,
@@ -31,6 +35,7 @@ func TestBastioSecurityClient_ValidateToolCall(t *testing.T) {
 		defer server.Close()
 
 		client := NewBastioSecurityClient(server.URL, "test-proxy", "test-key", "session-123")
+		client.SetDeviceID("device-1")
 		call := Call{
 			ID:    "call-1",
 			Name:  "run_command",
@@ -514,3 +519,36 @@ func TestRegistryWithSecurity(t *testing.T) {
 		}
 	})
 }
+
+func TestBastioSecurityClient_ReusesConnections(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ValidationResult{Action: ActionAllow})
+	}))
+	defer server.Close()
+
+	call := Call{ID: "call-1", Name: "read_file", Input: json.RawMessage(`{"path": "go.mod"}`)}
+
+	// Separate clients share one HTTP client, as successive agent runs do
+	first := NewBastioSecurityClient(server.URL, "test-proxy", "test-key", "session-1")
+	if _, err := first.ValidateToolCall(context.Background(), call); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	second := NewBastioSecurityClient(server.URL, "test-proxy", "test-key", "session-2")
+	for i := 0; i < 3; i++ {
+		if _, err := second.ValidateToolCall(context.Background(), call); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	metrics := second.Metrics()
+	if metrics.Calls != 3 {
+		t.Errorf("expected 3 calls, got %d", metrics.Calls)
+	}
+	if metrics.ReusedConns != 3 {
+		t.Errorf("expected all connections reused, got %d", metrics.ReusedConns)
+	}
+	if metrics.Average() <= 0 || metrics.Max < metrics.Average() {
+		t.Errorf("unexpected timings: avg %s, max %s", metrics.Average(), metrics.Max)
+	}
+}
@@ -0,0 +1,32 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bastio-ai/bast/internal/perf"
+)
+
+// benchStdout is noisy command output well over the stdout budget
+var benchStdout = strings.Repeat("building internal/pkg/component ... done in 0.42s\n", 2000)
+
+func BenchmarkFormatStreams(b *testing.B) {
+	stderr := strings.Repeat("warning: deprecated flag\n", 1000)
+	for i := 0; i < b.N; i++ {
+		formatStreams(benchStdout, stderr, 1)
+	}
+}
+
+func BenchmarkSummarizeOutput(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		SummarizeOutput(benchStdout, 0)
+	}
+}
+
+func TestPerfBudgets(t *testing.T) {
+	perf.Check(t, []perf.Budget{
+		{Name: "FormatStreams", Bench: BenchmarkFormatStreams, Max: time.Millisecond},
+		{Name: "SummarizeOutput", Bench: BenchmarkSummarizeOutput, Max: 80 * time.Millisecond},
+	})
+}
@@ -1,14 +1,20 @@
 package tools
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
+	"unicode/utf8"
+
+	"github.com/bastio-ai/bast/internal/files"
+	"github.com/bastio-ai/bast/internal/target"
 )
 
 // MaxOutputSize is the maximum size of tool output in bytes
@@ -18,14 +24,31 @@ const MaxOutputSize = 10000
 type RunCommandTool struct {
 	// AllowedDir restricts command execution to this directory (optional)
 	AllowedDir string
+
+	env      EnvPolicy
+	executor target.Executor // nil runs commands locally
 }
 
 func (t *RunCommandTool) Name() string {
 	return "run_command"
 }
 
+// SetEnvPolicy filters the environment commands inherit
+func (t *RunCommandTool) SetEnvPolicy(policy EnvPolicy) {
+	t.env = policy
+}
+
+// SetExecutor sets the target commands run on
+func (t *RunCommandTool) SetExecutor(executor target.Executor) {
+	t.executor = executor
+}
+
 func (t *RunCommandTool) Description() string {
-	return "Execute a shell command and return its output. Use this to run commands, check results, or gather information from the system."
+	desc := "Execute a shell command and return its output. Use this to run commands, check results, or gather information from the system. When the command writes to stderr or fails, the result is split into stdout:, stderr:, and exit: sections. Commands run without a terminal or stdin, so use non-interactive forms (git commit -m, npm init -y, sudo -n, ssh -o BatchMode=yes); editors, pagers, and REPLs are refused."
+	if t.executor != nil && !t.executor.Local() {
+		desc += fmt.Sprintf(" Commands run on %s, not on the machine the other tools read and write; working_dir is a path there and defaults to its default directory.", t.executor.Name())
+	}
+	return desc
 }
 
 func (t *RunCommandTool) InputSchema() InputSchema {
@@ -60,9 +83,23 @@ func (t *RunCommandTool) Execute(ctx context.Context, input json.RawMessage) (*R
 		return &Result{Output: "command is required", IsError: true}, nil
 	}
 
-	// Set working directory
+	// Commands that wait on a terminal would hang until the timeout
+	if program, hint, ok := interactiveHint(params.Command); ok {
+		return &Result{
+			Output:  fmt.Sprintf("refused: %s is interactive and run_command has no terminal to answer it. %s", program, hint),
+			IsError: true,
+		}, nil
+	}
+
+	executor := t.executor
+	if executor == nil {
+		executor = target.Local()
+	}
+
+	// Set working directory. Local paths mean nothing on a remote target,
+	// which starts in its own default directory.
 	workDir := params.WorkingDir
-	if workDir == "" {
+	if workDir == "" && executor.Local() {
 		var err error
 		workDir, err = os.Getwd()
 		if err != nil {
@@ -71,7 +108,7 @@ func (t *RunCommandTool) Execute(ctx context.Context, input json.RawMessage) (*R
 	}
 
 	// If AllowedDir is set, validate the working directory
-	if t.AllowedDir != "" {
+	if t.AllowedDir != "" && executor.Local() {
 		absAllowed, _ := filepath.Abs(t.AllowedDir)
 		absWork, _ := filepath.Abs(workDir)
 		if !strings.HasPrefix(absWork, absAllowed) {
@@ -84,29 +121,94 @@ func (t *RunCommandTool) Execute(ctx context.Context, input json.RawMessage) (*R
 	defer cancel()
 
 	// Execute command
-	cmd := exec.CommandContext(execCtx, "sh", "-c", params.Command)
-	cmd.Dir = workDir
+	cmd := executor.Command(execCtx, params.Command, workDir, append(t.env.Environ(), "GIT_TERMINAL_PROMPT=0"), t.env.Set)
+
+	// Prompts that slip past interactiveHint fail fast: stdin is /dev/null,
+	// and without a controlling terminal /dev/tty can't be opened either
+	cmd.Stdin = nil
+	detachTerminal(cmd)
+
+	// Capture the streams separately so errors can be told from output
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if fn := progressFrom(ctx); fn != nil {
+		progress := newProgressWriter(fn)
+		cmd.Stdout = io.MultiWriter(&stdout, progress)
+		cmd.Stderr = io.MultiWriter(&stderr, progress)
+	}
+	err := cmd.Run()
+	size := stdout.Len() + stderr.Len()
+
+	if err != nil && execCtx.Err() == context.DeadlineExceeded {
+		return &Result{Output: "command timed out after 30 seconds", IsError: true, Bytes: size}, nil
+	}
+
+	exitCode := exitCodeOf(err)
+	return &Result{
+		Output:   formatStreams(stdout.String(), stderr.String(), *exitCode),
+		IsError:  err != nil,
+		ExitCode: exitCode,
+		Bytes:    size,
+	}, nil
+}
 
-	output, err := cmd.CombinedOutput()
+// Output budgets for run_command, truncated independently so a noisy stdout
+// can't crowd out the stderr that explains a failure. These bound what the
+// TUI shows; the model gets a shorter SummarizeOutput of the same text.
+const (
+	MaxStdoutSize = 32000
+	MaxStderrSize = 16000
+)
 
-	// Truncate output if too large
-	outputStr := string(output)
-	if len(outputStr) > MaxOutputSize {
-		outputStr = outputStr[:MaxOutputSize] + "\n... (output truncated)"
+// formatStreams structures command output as stdout, stderr, and exit
+// sections. A clean run with nothing on stderr returns stdout as is.
+func formatStreams(stdout, stderr string, exitCode int) string {
+	stdout = truncateStream(stdout, MaxStdoutSize)
+	if stderr == "" && exitCode == 0 {
+		return stdout
 	}
+	stderr = truncateStream(stderr, MaxStderrSize)
 
-	if err != nil {
-		if execCtx.Err() == context.DeadlineExceeded {
-			return &Result{Output: "command timed out after 30 seconds", IsError: true}, nil
+	var b strings.Builder
+	for _, stream := range []struct{ name, text string }{{"stdout", stdout}, {"stderr", stderr}} {
+		b.WriteString(stream.name + ":\n")
+		if stream.text == "" {
+			b.WriteString("(empty)\n")
+			continue
+		}
+		b.WriteString(stream.text)
+		if !strings.HasSuffix(stream.text, "\n") {
+			b.WriteString("\n")
 		}
-		// Include output even on error (often contains useful error messages)
-		return &Result{
-			Output:  fmt.Sprintf("%s\nExit error: %v", outputStr, err),
-			IsError: true,
-		}, nil
 	}
+	fmt.Fprintf(&b, "exit: %d", exitCode)
+	return b.String()
+}
 
-	return &Result{Output: outputStr}, nil
+// truncateStream cuts s to max bytes with a marker noting the cut,
+// backing up to a rune boundary so the model never sees invalid UTF-8
+func truncateStream(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max] + "\n... (output truncated)"
+}
+
+// exitCodeOf extracts the process exit code from a command error.
+// Returns 0 for nil and -1 when the process did not exit normally.
+func exitCodeOf(err error) *int {
+	code := 0
+	if err != nil {
+		code = -1
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			code = exitErr.ExitCode()
+		}
+	}
+	return &code
 }
 
 // ReadFileTool reads file contents
@@ -157,13 +259,16 @@ func (t *ReadFileTool) Execute(ctx context.Context, input json.RawMessage) (*Res
 		path = filepath.Join(cwd, path)
 	}
 
-	// If AllowedDir is set, validate the path
+	// If AllowedDir is set, validate the path and where its symlinks lead
 	if t.AllowedDir != "" {
 		absAllowed, _ := filepath.Abs(t.AllowedDir)
 		absPath, _ := filepath.Abs(path)
 		if !strings.HasPrefix(absPath, absAllowed) {
 			return &Result{Output: "file path outside allowed directory", IsError: true}, nil
 		}
+		if _, err := files.ResolveInside(absAllowed, absPath); err != nil && !os.IsNotExist(err) {
+			return &Result{Output: fmt.Sprintf("cannot access file: %v", err), IsError: true}, nil
+		}
 	}
 
 	// Check if file exists
@@ -176,6 +281,18 @@ func (t *ReadFileTool) Execute(ctx context.Context, input json.RawMessage) (*Res
 		return &Result{Output: "path is a directory, not a file", IsError: true}, nil
 	}
 
+	// Notebooks, PDFs, and docx files are read as their text
+	if files.IsDocument(path) {
+		text, err := files.ExtractText(path)
+		if err != nil {
+			return &Result{Output: fmt.Sprintf("failed to read file: %v", err), IsError: true}, nil
+		}
+		if len(text) > MaxOutputSize {
+			text = files.TruncateUTF8(text, MaxOutputSize) + "\n... (file truncated)"
+		}
+		return &Result{Output: text, Bytes: int(info.Size())}, nil
+	}
+
 	// Read file
 	content, err := os.ReadFile(path)
 	if err != nil {
@@ -188,7 +305,7 @@ func (t *ReadFileTool) Execute(ctx context.Context, input json.RawMessage) (*Res
 		outputStr = outputStr[:MaxOutputSize] + "\n... (file truncated)"
 	}
 
-	return &Result{Output: outputStr}, nil
+	return &Result{Output: outputStr, Bytes: len(content)}, nil
 }
 
 // ListDirectoryTool lists directory contents
@@ -393,6 +510,13 @@ func (t *DoctorTool) Execute(ctx context.Context, input json.RawMessage) (*Resul
 	return &Result{Output: "🩺 Doctor to the rescue!"}, nil
 }
 
+// RegisterReadOnlyBuiltins registers only the built-in tools that read:
+// read_file and list_directory. Agents that must not change anything use it.
+func RegisterReadOnlyBuiltins(registry *Registry, allowedDir string) {
+	registry.Register(&ReadFileTool{AllowedDir: allowedDir})
+	registry.Register(&ListDirectoryTool{AllowedDir: allowedDir})
+}
+
 // RegisterBuiltins registers all built-in tools with the given registry
 func RegisterBuiltins(registry *Registry, allowedDir string) {
 	registry.Register(&RunCommandTool{AllowedDir: allowedDir})
@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
 func TestRunCommandTool(t *testing.T) {
@@ -47,6 +49,46 @@ func TestRunCommandTool(t *testing.T) {
 			t.Error("expected error for failed command")
 		}
 	})
+
+	t.Run("separates stdout and stderr", func(t *testing.T) {
+		input, _ := json.Marshal(map[string]string{"command": "echo out; echo err >&2; exit 2"})
+		result, err := tool.Execute(context.Background(), input)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := "stdout:\nout\nstderr:\nerr\nexit: 2"
+		if result.Output != want {
+			t.Errorf("expected %q, got: %q", want, result.Output)
+		}
+		if result.ExitCode == nil || *result.ExitCode != 2 {
+			t.Errorf("expected exit code 2, got: %v", result.ExitCode)
+		}
+	})
+}
+
+func TestFormatStreams(t *testing.T) {
+	if got := formatStreams("ok\n", "", 0); got != "ok\n" {
+		t.Errorf("clean run = %q, want stdout as is", got)
+	}
+	if got := formatStreams("", "warning: x", 0); got != "stdout:\n(empty)\nstderr:\nwarning: x\nexit: 0" {
+		t.Errorf("stderr only = %q", got)
+	}
+
+	got := formatStreams(strings.Repeat("o", MaxStdoutSize+10), "boom", 1)
+	if !strings.Contains(got, "(output truncated)\nstderr:\nboom\nexit: 1") {
+		t.Errorf("long stdout crowded out stderr: %q", got[len(got)-80:])
+	}
+}
+
+func TestTruncateStreamRuneBoundary(t *testing.T) {
+	// "é" is two bytes, so a 5-byte cut lands inside the third one
+	got := truncateStream(strings.Repeat("é", 4), 5)
+	if !utf8.ValidString(got) {
+		t.Fatalf("truncateStream split a rune: %q", got)
+	}
+	if want := "éé\n... (output truncated)"; got != want {
+		t.Errorf("truncateStream = %q, want %q", got, want)
+	}
 }
 
 func TestReadFileTool(t *testing.T) {
@@ -92,6 +134,28 @@ func TestReadFileTool(t *testing.T) {
 			t.Error("expected error for empty path")
 		}
 	})
+
+	t.Run("cuts a long document on a rune boundary", func(t *testing.T) {
+		nb, _ := json.Marshal(map[string]any{
+			"cells": []map[string]any{
+				{"cell_type": "markdown", "source": "x" + strings.Repeat("é", MaxOutputSize)},
+			},
+		})
+		path := filepath.Join(tmpDir, "long.ipynb")
+		os.WriteFile(path, nb, 0644)
+
+		input, _ := json.Marshal(map[string]string{"path": path})
+		result, err := tool.Execute(context.Background(), input)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.HasSuffix(result.Output, "(file truncated)") {
+			t.Errorf("expected a truncation marker, got: %q", result.Output[len(result.Output)-40:])
+		}
+		if !utf8.ValidString(result.Output) {
+			t.Error("document cut split a rune")
+		}
+	})
 }
 
 func TestListDirectoryTool(t *testing.T) {
@@ -247,3 +311,50 @@ func TestRegisterDefaultPlugins(t *testing.T) {
 		}
 	})
 }
+
+// recordingExecutor stands in for a remote target and records what it was asked to run
+type recordingExecutor struct {
+	command, dir string
+	set          []string
+}
+
+func (r *recordingExecutor) Name() string { return "ssh:test-box" }
+func (r *recordingExecutor) Local() bool  { return false }
+
+func (r *recordingExecutor) Command(ctx context.Context, command, dir string, env, set []string) *exec.Cmd {
+	r.command, r.dir, r.set = command, dir, set
+	return exec.CommandContext(ctx, "echo", "ran remotely")
+}
+
+func (r *recordingExecutor) Handoff(command, dir string, set []string) string {
+	return command
+}
+
+func TestRunCommandToolExecutor(t *testing.T) {
+	registry := NewRegistry()
+	remote := &recordingExecutor{}
+	registry.SetExecutor(remote)
+	RegisterBuiltins(registry, t.TempDir())
+	registry.SetEnvPolicy(EnvPolicy{Set: []string{"DEBUG=1"}})
+
+	tool, _ := registry.Get("run_command")
+	if !strings.Contains(tool.Description(), "ssh:test-box") {
+		t.Error("description should tell the model where commands run")
+	}
+	result, err := tool.Execute(context.Background(), []byte(`{"command": "uptime", "working_dir": "/srv/app"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError || result.Output != "ran remotely\n" {
+		t.Errorf("result = %+v, want the executor's output", result)
+	}
+	// A remote path is passed through, not checked against the local AllowedDir
+	if remote.command != "uptime" || remote.dir != "/srv/app" || len(remote.set) != 1 {
+		t.Errorf("executor got command %q in %q with %v", remote.command, remote.dir, remote.set)
+	}
+
+	tool.Execute(context.Background(), []byte(`{"command": "uptime"}`))
+	if remote.dir != "" {
+		t.Errorf("dir = %q, want the target's default rather than the local cwd", remote.dir)
+	}
+}
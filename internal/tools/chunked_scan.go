@@ -0,0 +1,162 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultScanChunkSize is the chunk size used when scanning large outputs
+	DefaultScanChunkSize = 16 * 1024
+	// DefaultScanThreshold is the output size above which only samples are scanned
+	DefaultScanThreshold = 64 * 1024
+	// DefaultScanSampleChunks is how many middle chunks are sampled above the threshold
+	DefaultScanSampleChunks = 2
+	// DefaultScanOverlap is how much of the previous chunk is scanned again
+	// with each chunk, so a secret or injection cut by a boundary is still seen whole
+	DefaultScanOverlap = 256
+)
+
+// ChunkedScanner wraps a SecurityValidator so large outputs are scanned in
+// chunks, stopping at the first block. Outputs above Threshold are only
+// partially scanned: the first and last chunk plus SampleChunks evenly spaced
+// chunks from the middle. Each chunk is scanned with the last Overlap bytes
+// of the one before it. Tool call validation is passed through unchanged.
+type ChunkedScanner struct {
+	Validator    SecurityValidator
+	ChunkSize    int
+	Threshold    int
+	SampleChunks int
+	Overlap      int
+}
+
+// NewChunkedScanner wraps validator with the given chunking policy.
+// Zero values fall back to the package defaults.
+func NewChunkedScanner(validator SecurityValidator, chunkSize, threshold int) *ChunkedScanner {
+	if chunkSize <= 0 {
+		chunkSize = DefaultScanChunkSize
+	}
+	if threshold <= 0 {
+		threshold = DefaultScanThreshold
+	}
+	return &ChunkedScanner{
+		Validator:    validator,
+		ChunkSize:    chunkSize,
+		Threshold:    threshold,
+		SampleChunks: DefaultScanSampleChunks,
+		Overlap:      DefaultScanOverlap,
+	}
+}
+
+// ValidateToolCall delegates to the wrapped validator.
+func (s *ChunkedScanner) ValidateToolCall(ctx context.Context, call Call) (*ValidationResult, error) {
+	return s.Validator.ValidateToolCall(ctx, call)
+}
+
+// ScanContent scans content chunk by chunk. Small content is sent as-is.
+func (s *ChunkedScanner) ScanContent(ctx context.Context, toolName string, content string) (*ScanResult, error) {
+	if len(content) <= s.ChunkSize {
+		return s.Validator.ScanContent(ctx, toolName, content)
+	}
+
+	chunks := splitChunks(content, s.ChunkSize)
+	verdict := &ScanResult{Action: ScanActionAllow}
+	var messages []string
+
+	for _, i := range s.chunksToScan(len(content), len(chunks)) {
+		var overlap string
+		if i > 0 {
+			overlap = tail(chunks[i-1], s.Overlap)
+		}
+		result, err := s.Validator.ScanContent(ctx, toolName, overlap+chunks[i])
+		if err != nil {
+			return nil, err
+		}
+
+		if result.RiskScore > verdict.RiskScore {
+			verdict.RiskScore = result.RiskScore
+		}
+		verdict.ThreatsDetected = append(verdict.ThreatsDetected, result.ThreatsDetected...)
+		verdict.Verdicts = append(verdict.Verdicts, result.Verdicts...)
+
+		switch result.Action {
+		case ScanActionBlock:
+			// No point scanning the rest once any chunk is blocked
+			verdict.Action = ScanActionBlock
+			verdict.Message = result.Message
+			return verdict, nil
+		case ScanActionSanitize:
+			verdict.Action = ScanActionSanitize
+			if i > 0 {
+				// The sanitized window includes the overlap, so it replaces it
+				chunks[i-1] = strings.TrimSuffix(chunks[i-1], overlap)
+			}
+			chunks[i] = result.ProcessedContent
+			messages = append(messages, result.Message)
+		case ScanActionWarn:
+			if verdict.Action == ScanActionAllow {
+				verdict.Action = ScanActionWarn
+			}
+			messages = append(messages, result.Message)
+		}
+	}
+
+	if verdict.Action == ScanActionSanitize {
+		verdict.ProcessedContent = strings.Join(chunks, "")
+	}
+	verdict.Message = strings.Join(messages, "; ")
+	return verdict, nil
+}
+
+// chunksToScan returns the indexes of chunks to scan, in order
+func (s *ChunkedScanner) chunksToScan(size, count int) []int {
+	if size <= s.Threshold || count <= s.SampleChunks+2 {
+		indexes := make([]int, count)
+		for i := range indexes {
+			indexes[i] = i
+		}
+		return indexes
+	}
+
+	// Head, evenly spaced middle samples, then tail
+	indexes := []int{0}
+	for n := 1; n <= s.SampleChunks; n++ {
+		indexes = append(indexes, n*(count-1)/(s.SampleChunks+1))
+	}
+	return append(indexes, count-1)
+}
+
+// splitChunks splits content into pieces of at most size bytes,
+// never cutting a UTF-8 sequence in half
+func splitChunks(content string, size int) []string {
+	var chunks []string
+	for len(content) > size {
+		cut := size
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		if cut == 0 {
+			cut = size
+		}
+		chunks = append(chunks, content[:cut])
+		content = content[cut:]
+	}
+	return append(chunks, content)
+}
+
+// tail returns the last n bytes of s or fewer, never starting inside a
+// UTF-8 sequence
+func tail(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if n >= len(s) {
+		return s
+	}
+	start := len(s) - n
+	for start < len(s) && !utf8.RuneStart(s[start]) {
+		start++
+	}
+	return s[start:]
+}
@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"os"
+	"path"
+	"strings"
+)
+
+// Environment filtering modes for commands spawned by tools
+const (
+	EnvModeDenylist  = "denylist"
+	EnvModeAllowlist = "allowlist"
+)
+
+// defaultEnvDeny matches variables that commonly hold credentials
+var defaultEnvDeny = []string{
+	"AWS_*", "AZURE_*", "GOOGLE_APPLICATION_CREDENTIALS", "GCLOUD_*",
+	"GITHUB_TOKEN", "GH_TOKEN", "GITLAB_TOKEN", "NPM_TOKEN", "DOCKER_PASSWORD",
+	"ANTHROPIC_API_KEY", "OPENAI_API_KEY", "BASTIO_API_KEY",
+	"DATABASE_URL", "VAULT_TOKEN",
+	"*_TOKEN", "*_SECRET", "*_SECRET_*", "*_PASSWORD", "*_PASSWD",
+	"*_API_KEY", "*_APIKEY", "*_ACCESS_KEY", "*_PRIVATE_KEY", "*_CREDENTIALS",
+}
+
+// baseEnvAllow is kept in allowlist mode so commands still run normally
+var baseEnvAllow = []string{
+	"PATH", "HOME", "USER", "LOGNAME", "SHELL", "TERM", "TMPDIR", "TZ", "PWD",
+	"LANG", "LC_*",
+}
+
+// EnvPolicy filters the environment inherited by commands that tools run.
+// The zero value removes credential-like variables (denylist mode).
+type EnvPolicy struct {
+	Mode  string   // EnvModeDenylist (default) or EnvModeAllowlist
+	Deny  []string // Extra name patterns removed in denylist mode
+	Allow []string // Name patterns kept in allowlist mode, besides the basics
+
+	// Set holds NAME=value entries the user set for the session (/env set).
+	// They replace inherited values and are never filtered.
+	Set []string
+}
+
+// Environ returns the current process environment filtered by the policy,
+// with the session's variables set
+func (p EnvPolicy) Environ() []string {
+	env := p.Filter(os.Environ())
+	if len(p.Set) == 0 {
+		return env
+	}
+	set := make(map[string]bool, len(p.Set))
+	for _, entry := range p.Set {
+		name, _, _ := strings.Cut(entry, "=")
+		set[name] = true
+	}
+	kept := env[:0]
+	for _, entry := range env {
+		if name, _, _ := strings.Cut(entry, "="); !set[name] {
+			kept = append(kept, entry)
+		}
+	}
+	return append(kept, p.Set...)
+}
+
+// Filter returns the NAME=value entries of env the policy lets through.
+// Patterns are shell globs matched case-insensitively against the name.
+func (p EnvPolicy) Filter(env []string) []string {
+	var kept []string
+	for _, entry := range env {
+		name, _, _ := strings.Cut(entry, "=")
+		if p.allows(name) {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+// allows reports whether the variable name passes the policy
+func (p EnvPolicy) allows(name string) bool {
+	if p.Mode == EnvModeAllowlist {
+		return matchesEnv(name, baseEnvAllow) || matchesEnv(name, p.Allow)
+	}
+	return !matchesEnv(name, defaultEnvDeny) && !matchesEnv(name, p.Deny)
+}
+
+// matchesEnv reports whether name matches any of the glob patterns
+func matchesEnv(name string, patterns []string) bool {
+	name = strings.ToUpper(name)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToUpper(pattern), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// envFiltered is implemented by tools that spawn processes
+type envFiltered interface {
+	SetEnvPolicy(policy EnvPolicy)
+}
@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEnvPolicyFilter(t *testing.T) {
+	env := []string{
+		"PATH=/usr/bin",
+		"HOME=/home/me",
+		"LC_ALL=C",
+		"AWS_SECRET_ACCESS_KEY=abc",
+		"GITHUB_TOKEN=ghp_x",
+		"STRIPE_API_KEY=sk_live",
+		"EDITOR=vim",
+		"GOPATH=/go",
+		"MY_SERVICE_URL=http://localhost",
+	}
+
+	tests := []struct {
+		name   string
+		policy EnvPolicy
+		want   []string
+	}{
+		{
+			name:   "default denylist",
+			policy: EnvPolicy{},
+			want:   []string{"PATH=/usr/bin", "HOME=/home/me", "LC_ALL=C", "EDITOR=vim", "GOPATH=/go", "MY_SERVICE_URL=http://localhost"},
+		},
+		{
+			name:   "extra deny patterns",
+			policy: EnvPolicy{Deny: []string{"my_service_*"}},
+			want:   []string{"PATH=/usr/bin", "HOME=/home/me", "LC_ALL=C", "EDITOR=vim", "GOPATH=/go"},
+		},
+		{
+			name:   "allowlist keeps basics and listed names",
+			policy: EnvPolicy{Mode: EnvModeAllowlist, Allow: []string{"GO*"}},
+			want:   []string{"PATH=/usr/bin", "HOME=/home/me", "LC_ALL=C", "GOPATH=/go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Filter(env); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Filter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunCommandToolScrubsEnv(t *testing.T) {
+	t.Setenv("BAST_TEST_TOKEN", "hunter2")
+	t.Setenv("BAST_TEST_VISIBLE", "yes")
+
+	registry := NewRegistry()
+	RegisterBuiltins(registry, "")
+	registry.SetEnvPolicy(EnvPolicy{})
+
+	tool, _ := registry.Get("run_command")
+	result, err := tool.Execute(t.Context(), []byte(`{"command": "echo token=$BAST_TEST_TOKEN visible=$BAST_TEST_VISIBLE"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Output != "token= visible=yes\n" {
+		t.Errorf("output = %q, want the token scrubbed", result.Output)
+	}
+}
+
+func TestRunCommandToolSessionEnv(t *testing.T) {
+	t.Setenv("BAST_TEST_URL", "https://api.example.com")
+
+	registry := NewRegistry()
+	RegisterBuiltins(registry, "")
+	registry.SetEnvPolicy(EnvPolicy{Set: []string{"BAST_TEST_URL=http://localhost:8080", "BAST_TEST_TOKEN=dev"}})
+
+	tool, _ := registry.Get("run_command")
+	result, err := tool.Execute(t.Context(), []byte(`{"command": "echo url=$BAST_TEST_URL token=$BAST_TEST_TOKEN"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Output != "url=http://localhost:8080 token=dev\n" {
+		t.Errorf("output = %q, want the session variables set", result.Output)
+	}
+}
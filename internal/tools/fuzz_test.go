@@ -0,0 +1,35 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func FuzzLoadPlugin(f *testing.F) {
+	for _, seed := range []string{
+		"name: hello\ndescription: Say hello\ncommand: echo hello\n",
+		"name: x\ndescription: y\nscript: run.sh\nenv:\n  - name: TOKEN\n    secret: true\n",
+		"name: [unclosed",
+		"env:\n  - {}\n",
+		"---\n---\n",
+		"",
+	} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, manifest []byte) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "plugin.yaml")
+		if err := os.WriteFile(path, manifest, 0644); err != nil {
+			t.Fatal(err)
+		}
+		plugin, err := loadPlugin(path, dir)
+		if err != nil {
+			return
+		}
+		if plugin.Name() == "" || plugin.Description() == "" {
+			t.Errorf("loaded a plugin without a name or description from %q", manifest)
+		}
+		plugin.InputSchema()
+	})
+}
@@ -0,0 +1,148 @@
+package tools
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// commandSeparators splits a command line into the commands it runs
+var commandSeparators = regexp.MustCompile(`\|\||&&|[;|&\n]`)
+
+// interactiveRule flags a program that waits for terminal input, unless
+// invoked with arguments that make it run unattended
+type interactiveRule struct {
+	programs    []string
+	interactive func(args []string) bool
+	readsStdin  bool // Piped or redirected input answers it instead of a terminal
+	hint        string
+}
+
+// always treats every invocation as interactive
+func always([]string) bool { return true }
+
+// noArgs treats the bare program (a REPL or prompt) as interactive
+func noArgs(args []string) bool { return len(args) == 0 }
+
+// lacks treats an invocation as interactive unless it has one of flags,
+// alone, with "=value", or (for short flags) with the value attached or
+// bundled with other short flags, as in git commit -am
+func lacks(flags ...string) func([]string) bool {
+	return func(args []string) bool {
+		for _, arg := range args {
+			for _, flag := range flags {
+				if arg == flag || strings.HasPrefix(arg, flag+"=") {
+					return false
+				}
+				if len(flag) == 2 && flag[0] == '-' && flag[1] != '-' && inShortGroup(arg, flag[1]) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+}
+
+// inShortGroup reports whether arg is a group of short flags such as -am
+// that includes letter. The flag may be followed by its value, as in
+// -mfix, so letters count up to the first one that isn't alphabetic.
+func inShortGroup(arg string, letter byte) bool {
+	if len(arg) < 2 || arg[0] != '-' || arg[1] == '-' {
+		return false
+	}
+	for i := 1; i < len(arg); i++ {
+		c := arg[i]
+		if c == letter {
+			return true
+		}
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			return false
+		}
+	}
+	return false
+}
+
+// has treats an invocation as interactive when it has one of flags
+func has(flags ...string) func([]string) bool {
+	return func(args []string) bool {
+		return slices.ContainsFunc(args, func(arg string) bool { return slices.Contains(flags, arg) })
+	}
+}
+
+// subcommand applies check to invocations of the given subcommand
+func subcommand(name string, check func([]string) bool) func([]string) bool {
+	return func(args []string) bool {
+		return len(args) > 0 && args[0] == name && check(args[1:])
+	}
+}
+
+// sshPrompts treats ssh as interactive unless BatchMode is on, so it fails
+// instead of asking for a password or host key confirmation
+func sshPrompts(args []string) bool {
+	return !slices.ContainsFunc(args, func(arg string) bool { return strings.Contains(arg, "BatchMode=yes") })
+}
+
+var interactiveRules = []interactiveRule{
+	{[]string{"vi", "vim", "nvim", "nano", "emacs", "pico", "micro"}, lacks("--batch", "-batch", "-es", "-Es"), false, "Use write_file to change files, or sed for small edits."},
+	{[]string{"less", "more", "most"}, always, false, "Use cat, head, or tail instead of a pager."},
+	{[]string{"top", "htop", "btop"}, lacks("-b", "-l"), false, "Use ps aux, or top -b -n 1 (top -l 1 on macOS), for a snapshot."},
+	{[]string{"watch"}, always, false, "Run the command once instead of watching it."},
+	{[]string{"tmux", "screen"}, always, false, "Run the command directly."},
+	{[]string{"ssh"}, sshPrompts, false, "Pass -o BatchMode=yes so ssh fails instead of asking for a password, and give the remote command as an argument."},
+	{[]string{"sudo"}, lacks("-n", "--non-interactive"), false, "Use sudo -n so it fails instead of asking for a password, or ask the user to run the command."},
+	{[]string{"passwd", "visudo"}, always, false, "Ask the user to run this command themselves."},
+	{[]string{"crontab"}, has("-e"), false, "Write the crontab to a file and install it with crontab <file>."},
+	{[]string{"python", "python3", "node", "irb", "ghci", "lua"}, noArgs, true, "Pass a script file, or the code with -c (python) or -e (node, ruby)."},
+	{[]string{"psql"}, lacks("-c", "-f", "--command", "--file"), true, "Pass the query with -c or a file with -f."},
+	{[]string{"mysql"}, lacks("-e", "--execute"), true, "Pass the query with -e."},
+	{[]string{"sqlite3"}, func(args []string) bool { return len(args) < 2 }, true, "Pass the SQL as the second argument: sqlite3 app.db 'select ...'."},
+	{[]string{"npm", "yarn", "pnpm"}, subcommand("init", lacks("-y", "--yes")), false, "Use init -y to accept the defaults."},
+	{[]string{"git"}, subcommand("commit", lacks("-m", "-F", "--message", "--file", "--no-edit", "-C")), false, "Use git commit -m \"message\"."},
+	{[]string{"git"}, subcommand("rebase", has("-i", "--interactive")), false, "Rebase non-interactively, or ask the user to run the interactive rebase."},
+	{[]string{"git"}, subcommand("add", has("-p", "-i", "--patch", "--interactive")), false, "Stage whole files with git add <path>."},
+}
+
+// interactiveHint reports whether command runs a program that waits for
+// terminal input, naming it and suggesting a non-interactive alternative
+func interactiveHint(command string) (program, hint string, ok bool) {
+	start, piped := 0, false
+	bounds := append(commandSeparators.FindAllStringIndex(command, -1), []int{len(command), len(command)})
+	for _, sep := range bounds {
+		segment := command[start:sep[0]]
+		stdin := piped || strings.Contains(segment, "<")
+		piped = command[sep[0]:sep[1]] == "|"
+		start = sep[1]
+
+		words := commandWords(segment)
+		if len(words) == 0 {
+			continue
+		}
+		name := words[0]
+		if i := strings.LastIndex(name, "/"); i >= 0 {
+			name = name[i+1:]
+		}
+		for _, rule := range interactiveRules {
+			if slices.Contains(rule.programs, name) && !(rule.readsStdin && stdin) && rule.interactive(words[1:]) {
+				return name, rule.hint, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// commandWords splits a simple command into words, skipping leading
+// VAR=value assignments and wrappers that don't change the program
+func commandWords(segment string) []string {
+	words := strings.Fields(segment)
+	for len(words) > 0 {
+		switch {
+		case strings.Contains(words[0], "=") && !strings.HasPrefix(words[0], "-"):
+			words = words[1:]
+		case slices.Contains([]string{"env", "command", "exec", "time", "nohup"}, words[0]):
+			words = words[1:]
+		default:
+			return words
+		}
+	}
+	return words
+}
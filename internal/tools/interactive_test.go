@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestInteractiveHint(t *testing.T) {
+	tests := []struct {
+		command string
+		program string // empty when the command runs unattended
+	}{
+		{"vim main.go", "vim"},
+		{"cat README.md | less", "less"},
+		{"ssh prod uptime", "ssh"},
+		{"ssh -o BatchMode=yes prod uptime", ""},
+		{"sudo apt update", "sudo"},
+		{"sudo -n apt update", ""},
+		{"python3", "python3"},
+		{"python3 script.py", ""},
+		{"echo 'print(1)' | python3", ""},
+		{"psql -d app", "psql"},
+		{"psql -d app -c 'select 1'", ""},
+		{"psql -d app < schema.sql", ""},
+		{"npm init", "npm"},
+		{"npm init -y", ""},
+		{"git add . && git commit", "git"},
+		{"git commit -m 'fix'", ""},
+		{"git commit -am 'fix'", ""},
+		{"git commit -mfix", ""},
+		{"git commit -a", "git"},
+		{"git commit --amend", "git"},
+		{"ssh -o BatchMode=yes host uptime", ""},
+		{"ssh host uptime", "ssh"},
+		{"git rebase -i HEAD~3", "git"},
+		{"git status", ""},
+		{"EDITOR=vim crontab -e", "crontab"},
+		{"top -b -n 1", ""},
+		{"go test ./... 2>&1 | tail -20", ""},
+	}
+
+	for _, tt := range tests {
+		program, hint, ok := interactiveHint(tt.command)
+		if program != tt.program || ok != (tt.program != "") {
+			t.Errorf("interactiveHint(%q) = %q, %v, want %q", tt.command, program, ok, tt.program)
+		}
+		if ok && hint == "" {
+			t.Errorf("interactiveHint(%q) gave no hint", tt.command)
+		}
+	}
+}
+
+func TestRunCommandToolRefusesInteractive(t *testing.T) {
+	tool := &RunCommandTool{}
+	input, _ := json.Marshal(map[string]string{"command": "vim notes.txt"})
+	result, err := tool.Execute(context.Background(), input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.IsError || !strings.Contains(result.Output, "interactive") || !strings.Contains(result.Output, "write_file") {
+		t.Errorf("expected refusal with a hint, got: %s", result.Output)
+	}
+}
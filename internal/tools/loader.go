@@ -1,10 +1,12 @@
 package tools
 
 import (
+	"bytes"
 	"context"
 	"embed"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -25,6 +27,7 @@ type PluginManifest struct {
 	Script      string              `yaml:"script"`       // Or path to script file
 	Parameters  []PluginParameter   `yaml:"parameters"`
 	Timeout     int                 `yaml:"timeout"`      // Timeout in seconds (default 30)
+	Env         []PluginEnvVar      `yaml:"env"`          // Environment variables resolved locally at execution time
 }
 
 // PluginParameter defines a parameter for a user-defined tool
@@ -38,14 +41,22 @@ type PluginParameter struct {
 
 // PluginTool wraps a user-defined tool from a YAML manifest
 type PluginTool struct {
-	manifest PluginManifest
-	basePath string // Directory containing the manifest
+	manifest    PluginManifest
+	basePath    string // Directory containing the manifest
+	secretsPath string // Secrets file override (defaults to DefaultSecretsPath)
+	env         EnvPolicy
+	shipped     bool // Embedded default plugin rather than a user manifest
 }
 
 func (t *PluginTool) Name() string {
 	return t.manifest.Name
 }
 
+// SetEnvPolicy filters the environment the plugin command inherits
+func (t *PluginTool) SetEnvPolicy(policy EnvPolicy) {
+	t.env = policy
+}
+
 func (t *PluginTool) Description() string {
 	return t.manifest.Description
 }
@@ -94,6 +105,12 @@ func (t *PluginTool) Execute(ctx context.Context, input json.RawMessage) (*Resul
 		return &Result{Output: "tool has no command or script defined", IsError: true}, nil
 	}
 
+	// Resolve declared env vars before running anything
+	pluginEnv, err := t.resolveEnv()
+	if err != nil {
+		return &Result{Output: err.Error(), IsError: true}, nil
+	}
+
 	// Substitute parameters in command using $PARAM_NAME format
 	for name, value := range params {
 		envKey := strings.ToUpper(name)
@@ -115,14 +132,30 @@ func (t *PluginTool) Execute(ctx context.Context, input json.RawMessage) (*Resul
 	cmd.Dir = t.basePath
 
 	// Set parameters as environment variables
-	cmd.Env = os.Environ()
+	cmd.Env = t.env.Environ()
 	for name, value := range params {
 		envKey := "BAST_PARAM_" + strings.ToUpper(name)
 		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%v", envKey, value))
 	}
+	for name, value := range pluginEnv {
+		cmd.Env = append(cmd.Env, name+"="+value)
+	}
 
-	output, err := cmd.CombinedOutput()
-	outputStr := string(output)
+	var buf bytes.Buffer
+	cmd.Stdout = &buf
+	if fn := progressFrom(ctx); fn != nil {
+		progress := newProgressWriter(func(tail []string) {
+			for i, line := range tail {
+				tail[i] = redactSecrets(line, pluginEnv)
+			}
+			fn(tail)
+		})
+		cmd.Stdout = io.MultiWriter(&buf, progress)
+	}
+	cmd.Stderr = cmd.Stdout
+	err = cmd.Run()
+	output := buf.Bytes()
+	outputStr := redactSecrets(string(output), pluginEnv)
 
 	if len(outputStr) > MaxOutputSize {
 		outputStr = outputStr[:MaxOutputSize] + "\n... (output truncated)"
@@ -130,15 +163,40 @@ func (t *PluginTool) Execute(ctx context.Context, input json.RawMessage) (*Resul
 
 	if err != nil {
 		if execCtx.Err() == context.DeadlineExceeded {
-			return &Result{Output: "command timed out", IsError: true}, nil
+			return &Result{Output: "command timed out", IsError: true, Bytes: len(output)}, nil
 		}
 		return &Result{
-			Output:  fmt.Sprintf("%s\nExit error: %v", outputStr, err),
-			IsError: true,
+			Output:   fmt.Sprintf("%s\nExit error: %v", outputStr, err),
+			IsError:  true,
+			ExitCode: exitCodeOf(err),
+			Bytes:    len(output),
 		}, nil
 	}
 
-	return &Result{Output: outputStr}, nil
+	return &Result{Output: outputStr, ExitCode: exitCodeOf(nil), Bytes: len(output)}, nil
+}
+
+// resolveEnv resolves the manifest's env declarations from the environment and secrets file
+func (t *PluginTool) resolveEnv() (map[string]string, error) {
+	if len(t.manifest.Env) == 0 {
+		return nil, nil
+	}
+
+	secretsPath := t.secretsPath
+	if secretsPath == "" {
+		var err error
+		secretsPath, err = DefaultSecretsPath()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	secrets, err := LoadSecrets(secretsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolvePluginEnv(t.manifest.Name, t.manifest.Env, secrets)
 }
 
 // LoadPlugins loads all user-defined tools from a directory
@@ -213,6 +271,11 @@ func loadPlugin(manifestPath, basePath string) (*PluginTool, error) {
 	if manifest.Command == "" && manifest.Script == "" {
 		return nil, fmt.Errorf("manifest must have either command or script")
 	}
+	for _, v := range manifest.Env {
+		if v.Name == "" {
+			return nil, fmt.Errorf("manifest env entry missing required field: name")
+		}
+	}
 
 	return &PluginTool{
 		manifest: manifest,
@@ -297,6 +360,7 @@ func RegisterDefaultPlugins(registry *Registry, cwd string) error {
 		plugin := &PluginTool{
 			manifest: manifest,
 			basePath: cwd, // Use current working directory for default plugins
+			shipped:  true,
 		}
 
 		if err := registry.Register(plugin); err != nil {
@@ -306,3 +370,25 @@ func RegisterDefaultPlugins(registry *Registry, cwd string) error {
 
 	return nil
 }
+
+// RegisterAgentTools registers everything the agent can use: the builtins,
+// the default plugins, and (with userPlugins) the user's plugins. Plugin
+// load failures are reported as warnings.
+func RegisterAgentTools(registry *Registry, cwd string, userPlugins bool) {
+	RegisterBuiltins(registry, cwd)
+
+	// Load default plugins (shipped with bast)
+	if err := RegisterDefaultPlugins(registry, cwd); err != nil {
+		// Log warning but continue
+		fmt.Fprintf(os.Stderr, "Warning: failed to load default plugins: %v\n", err)
+	}
+
+	// Load user plugins (can override defaults)
+	if !userPlugins {
+		return
+	}
+	if err := RegisterUserPlugins(registry); err != nil {
+		// Log warning but continue
+		fmt.Fprintf(os.Stderr, "Warning: failed to load user plugins: %v\n", err)
+	}
+}
@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bastio-ai/bast/internal/safety"
+)
+
+// LocalSecurityClient validates tool calls and scans output on-device using
+// the internal/safety patterns. It needs no credentials, so direct-mode
+// agents still get a guard when Bastio isn't configured.
+type LocalSecurityClient struct{}
+
+// NewLocalSecurityClient creates a new local security client.
+func NewLocalSecurityClient() *LocalSecurityClient {
+	return &LocalSecurityClient{}
+}
+
+// ValidateToolCall flags shell commands matching known dangerous patterns.
+func (c *LocalSecurityClient) ValidateToolCall(ctx context.Context, call Call) (*ValidationResult, error) {
+	if call.Name != "run_command" {
+		return &ValidationResult{Action: ActionAllow}, nil
+	}
+
+	var params runCommandInput
+	if err := json.Unmarshal(call.Input, &params); err != nil {
+		// Let the tool itself report malformed input
+		return &ValidationResult{Action: ActionAllow}, nil
+	}
+
+	// Credentials in arguments would leave the machine with the command
+	if found := safety.DetectSecrets(params.Command); len(found) > 0 {
+		return &ValidationResult{
+			Action:          ActionBlock,
+			RiskScore:       1.0,
+			ThreatsDetected: found,
+			Message:         fmt.Sprintf("command contains a credential (%s); use an environment variable instead", strings.Join(found, ", ")),
+		}, nil
+	}
+
+	if safety.IsDangerousCommand(params.Command) {
+		return &ValidationResult{
+			Action:          ActionRequireApproval,
+			RiskScore:       1.0,
+			ThreatsDetected: []string{"dangerous_command"},
+			Message:         fmt.Sprintf("command matches a dangerous pattern: %s", params.Command),
+		}, nil
+	}
+
+	return &ValidationResult{Action: ActionAllow}, nil
+}
+
+// ScanContent redacts credentials found in tool output before it reaches the
+// model, and warns about text that tries to give the model instructions.
+func (c *LocalSecurityClient) ScanContent(ctx context.Context, toolName string, content string) (*ScanResult, error) {
+	injection := safety.DetectInjection(content)
+	redacted, found := safety.RedactSecrets(content)
+	if len(found) > 0 {
+		message := fmt.Sprintf("redacted secrets: %s", strings.Join(found, ", "))
+		if len(injection) > 0 {
+			message += fmt.Sprintf("; possible prompt injection: %s", strings.Join(injection, ", "))
+		}
+		return &ScanResult{
+			Action:           ScanActionSanitize,
+			ProcessedContent: redacted,
+			ThreatsDetected:  append(found, injection...),
+			RiskScore:        1.0,
+			Message:          message,
+		}, nil
+	}
+
+	if len(injection) > 0 {
+		return &ScanResult{
+			Action:          ScanActionWarn,
+			ThreatsDetected: injection,
+			RiskScore:       0.5,
+			Message:         fmt.Sprintf("possible prompt injection: %s", strings.Join(injection, ", ")),
+		}, nil
+	}
+
+	return &ScanResult{Action: ScanActionAllow}, nil
+}
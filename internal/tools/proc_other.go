@@ -0,0 +1,8 @@
+//go:build !unix
+
+package tools
+
+import "os/exec"
+
+// detachTerminal is a no-op where sessions aren't available
+func detachTerminal(cmd *exec.Cmd) {}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package tools
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detachTerminal starts cmd in a new session with no controlling terminal.
+// The session is also a process group, so cancelling cmd kills the whole
+// group: children it backgrounded can't outlive the timeout.
+func detachTerminal(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
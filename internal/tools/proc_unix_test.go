@@ -0,0 +1,29 @@
+//go:build unix
+
+package tools
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestDetachTerminalKillsGroup(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	// The backgrounded sleep holds stdout open; only killing the whole
+	// group lets Run return at the timeout
+	cmd := exec.CommandContext(ctx, "sh", "-c", "sleep 30 & sleep 30")
+	detachTerminal(cmd)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+
+	start := time.Now()
+	cmd.Run()
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Run returned after %v; background child outlived the timeout", elapsed)
+	}
+}
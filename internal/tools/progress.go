@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxProgressLines is how many of the latest output lines are reported
+	maxProgressLines = 5
+
+	// maxProgressLineBytes caps an unterminated line held between writes
+	maxProgressLineBytes = 512
+
+	// progressInterval throttles progress reports
+	progressInterval = 200 * time.Millisecond
+)
+
+// ProgressFunc receives the latest output lines of a running tool
+type ProgressFunc func(tail []string)
+
+type progressKey struct{}
+
+// WithProgress returns a context that makes process-running tools report
+// their output to fn while they run
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// progressFrom returns the progress callback in ctx, or nil
+func progressFrom(ctx context.Context) ProgressFunc {
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return fn
+}
+
+// progressWriter keeps the last lines written to it in a ring buffer and
+// reports them at most once per progressInterval
+type progressWriter struct {
+	mu      sync.Mutex
+	fn      ProgressFunc
+	ring    [maxProgressLines]string
+	next    int // Ring slot for the next complete line
+	count   int // Complete lines held, up to maxProgressLines
+	partial string
+	last    time.Time
+}
+
+func newProgressWriter(fn ProgressFunc) *progressWriter {
+	return &progressWriter{fn: fn}
+}
+
+// Write records p and reports the tail when the interval has passed
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	text := w.partial + string(p)
+	for {
+		line, rest, found := strings.Cut(text, "\n")
+		if !found {
+			break
+		}
+		w.push(line)
+		text = rest
+	}
+	if len(text) > maxProgressLineBytes {
+		text = text[len(text)-maxProgressLineBytes:]
+	}
+	w.partial = text
+
+	if time.Since(w.last) >= progressInterval {
+		w.last = time.Now()
+		w.fn(w.tail())
+	}
+	return len(p), nil
+}
+
+// push adds a complete line to the ring, keeping only what follows the last
+// carriage return so progress bars show their latest state
+func (w *progressWriter) push(line string) {
+	if i := strings.LastIndex(line, "\r"); i >= 0 {
+		line = line[i+1:]
+	}
+	w.ring[w.next] = line
+	w.next = (w.next + 1) % maxProgressLines
+	if w.count < maxProgressLines {
+		w.count++
+	}
+}
+
+// tail returns the held lines oldest first, ending with the unterminated
+// line when there is one
+func (w *progressWriter) tail() []string {
+	lines := make([]string, 0, maxProgressLines+1)
+	for i := 0; i < w.count; i++ {
+		lines = append(lines, w.ring[(w.next-w.count+i+maxProgressLines)%maxProgressLines])
+	}
+	if partial := w.partial; partial != "" {
+		if i := strings.LastIndex(partial, "\r"); i >= 0 {
+			partial = partial[i+1:]
+		}
+		lines = append(lines, partial)
+		if len(lines) > maxProgressLines {
+			lines = lines[1:]
+		}
+	}
+	return lines
+}
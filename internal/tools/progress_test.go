@@ -0,0 +1,53 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestProgressWriterTail(t *testing.T) {
+	var reports [][]string
+	w := newProgressWriter(func(tail []string) { reports = append(reports, tail) })
+
+	w.Write([]byte("one\ntwo\nthree\nfour\nfive\nsix\nsev"))
+	want := []string{"three", "four", "five", "six", "sev"}
+	if len(reports) != 1 || !reflect.DeepEqual(reports[0], want) {
+		t.Fatalf("reports = %q, want one report of %q", reports, want)
+	}
+
+	// Reports within the interval are skipped, but the ring keeps up
+	w.Write([]byte("en\n 10%\r 50%\r100%\n"))
+	if len(reports) != 1 {
+		t.Errorf("reported again within the interval: %q", reports)
+	}
+	want = []string{"four", "five", "six", "seven", "100%"}
+	if got := w.tail(); !reflect.DeepEqual(got, want) {
+		t.Errorf("tail() = %q, want %q", got, want)
+	}
+}
+
+func TestRunCommandToolReportsProgress(t *testing.T) {
+	var mu sync.Mutex
+	var reports [][]string
+	ctx := WithProgress(context.Background(), func(tail []string) {
+		mu.Lock()
+		defer mu.Unlock()
+		reports = append(reports, tail)
+	})
+
+	input, _ := json.Marshal(map[string]string{"command": "echo building; echo done"})
+	result, err := (&RunCommandTool{}).Execute(ctx, input)
+	if err != nil || result.IsError {
+		t.Fatalf("unexpected failure: %v %s", err, result.Output)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reports) == 0 || !strings.Contains(strings.Join(reports[0], "\n"), "building") {
+		t.Errorf("expected a progress report with output, got %q", reports)
+	}
+}
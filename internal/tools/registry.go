@@ -5,13 +5,35 @@ import (
 	"encoding/json"
 	"fmt"
 	"sync"
+	"time"
+
+	"github.com/bastio-ai/bast/internal/target"
 )
 
+// CallHook runs user code around each tool call, such as the before_tool
+// and after_tool lifecycle hooks
+type CallHook interface {
+	// BeforeCall runs before validation. An error blocks the call and is
+	// returned to the model as the reason.
+	BeforeCall(ctx context.Context, call Call) error
+
+	// AfterCall runs with the result the model will see
+	AfterCall(ctx context.Context, call Call, result CallResult)
+}
+
+// WarnConfirmFunc asks the user whether a tool call flagged with a warn
+// verdict should proceed. Returning false skips the call.
+type WarnConfirmFunc func(ctx context.Context, call Call, result *ValidationResult) bool
+
 // Registry manages the collection of available tools
 type Registry struct {
-	mu       sync.RWMutex
-	tools    map[string]Tool
-	security *BastioSecurityClient // Optional - nil if not using Bastio
+	mu          sync.RWMutex
+	tools       map[string]Tool
+	security    SecurityValidator // Optional - nil if no validator configured
+	confirmWarn WarnConfirmFunc   // Optional - nil proceeds on warnings
+	envPolicy   *EnvPolicy        // Optional - nil leaves each tool's default
+	executor    target.Executor   // Optional - nil runs commands locally
+	hook        CallHook          // Optional - nil runs no hooks around calls
 }
 
 // NewRegistry creates a new tool registry
@@ -31,6 +53,12 @@ func (r *Registry) Register(tool Tool) error {
 		return fmt.Errorf("tool %q already registered", name)
 	}
 
+	if f, ok := tool.(envFiltered); ok && r.envPolicy != nil {
+		f.SetEnvPolicy(*r.envPolicy)
+	}
+	if t, ok := tool.(targeted); ok && r.executor != nil {
+		t.SetExecutor(r.executor)
+	}
 	r.tools[name] = tool
 	return nil
 }
@@ -85,41 +113,128 @@ func (r *Registry) Execute(ctx context.Context, name string, input json.RawMessa
 	return tool.Execute(ctx, input)
 }
 
-// SetSecurityClient configures optional Bastio security validation
-func (r *Registry) SetSecurityClient(client *BastioSecurityClient) {
+// SetSecurityClient configures optional security validation.
+// Use NewChainValidator to combine several validators.
+func (r *Registry) SetSecurityClient(client SecurityValidator) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.security = client
 }
 
+// SetEnvPolicy filters the environment of commands spawned by registered
+// tools, including ones registered later
+func (r *Registry) SetEnvPolicy(policy EnvPolicy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.envPolicy = &policy
+	for _, tool := range r.tools {
+		if f, ok := tool.(envFiltered); ok {
+			f.SetEnvPolicy(policy)
+		}
+	}
+}
+
+// targeted is implemented by tools that run commands on an execution target
+type targeted interface {
+	SetExecutor(executor target.Executor)
+}
+
+// SetExecutor sets the target that registered tools, including ones
+// registered later, run commands on
+func (r *Registry) SetExecutor(executor target.Executor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.executor = executor
+	for _, tool := range r.tools {
+		if t, ok := tool.(targeted); ok {
+			t.SetExecutor(executor)
+		}
+	}
+}
+
+// SetWarnConfirm configures a prompt for tool calls that get a warn verdict
+func (r *Registry) SetWarnConfirm(confirm WarnConfirmFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.confirmWarn = confirm
+}
+
+// SetCallHook configures hooks that run around every tool call
+func (r *Registry) SetCallHook(hook CallHook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hook = hook
+}
+
 // ExecuteCall executes a tool call and returns the result
 func (r *Registry) ExecuteCall(ctx context.Context, call Call) CallResult {
-	// If Bastio security is configured, validate the tool call first
+	r.mu.RLock()
+	hook := r.hook
+	r.mu.RUnlock()
+	if hook == nil {
+		return r.executeCall(ctx, call)
+	}
+
+	var result CallResult
+	if err := hook.BeforeCall(ctx, call); err != nil {
+		result = CallResult{
+			CallID:  call.ID,
+			Content: fmt.Sprintf("Blocked by before_tool hook: %v", err),
+			IsError: true,
+		}
+	} else {
+		result = r.executeCall(ctx, call)
+	}
+	hook.AfterCall(ctx, call, result)
+	return result
+}
+
+// executeCall validates, runs, and scans a tool call
+func (r *Registry) executeCall(ctx context.Context, call Call) CallResult {
+	// If security is configured, validate the tool call first
 	r.mu.RLock()
 	security := r.security
+	confirmWarn := r.confirmWarn
 	r.mu.RUnlock()
 
+	var verdicts []Verdict
+	var warnings, threats []string
 	if security != nil {
 		validationResult, err := security.ValidateToolCall(ctx, call)
 		if err != nil {
 			// Log validation error but don't block execution
 			LogWarning(call.Name, fmt.Sprintf("validation failed: %v", err), nil)
 		} else {
+			verdicts = append(verdicts, validationResult.Verdicts...)
 			switch validationResult.Action {
 			case ActionBlock:
 				return CallResult{
-					CallID:  call.ID,
-					Content: fmt.Sprintf("Blocked by security policy: %s", validationResult.Message),
-					IsError: true,
+					CallID:   call.ID,
+					Content:  fmt.Sprintf("Blocked by security policy: %s", validationResult.Message),
+					IsError:  true,
+					Verdicts: verdicts,
 				}
 			case ActionRequireApproval:
 				return CallResult{
-					CallID:  call.ID,
-					Content: fmt.Sprintf("Requires human approval: %s", validationResult.Message),
-					IsError: true,
+					CallID:   call.ID,
+					Content:  fmt.Sprintf("Requires human approval: %s", validationResult.Message),
+					IsError:  true,
+					Verdicts: verdicts,
 				}
 			case ActionWarn:
 				LogWarning(call.Name, validationResult.Message, validationResult.ThreatsDetected)
+				warnings = append(warnings, validationResult.Message)
+				threats = append(threats, validationResult.ThreatsDetected...)
+				if confirmWarn != nil && !confirmWarn(ctx, call, validationResult) {
+					return CallResult{
+						CallID:   call.ID,
+						Content:  fmt.Sprintf("Skipped by user after security warning: %s", validationResult.Message),
+						IsError:  true,
+						Verdicts: verdicts,
+						Warnings: warnings,
+						Threats:  threats,
+					}
+				}
 				// Continue to execution
 			// ActionAllow - continue to execution
 			}
@@ -127,42 +242,69 @@ func (r *Registry) ExecuteCall(ctx context.Context, call Call) CallResult {
 	}
 
 	// Execute the tool
+	start := time.Now()
 	result, err := r.Execute(ctx, call.Name, call.Input)
+	duration := time.Since(start)
 	if err != nil {
 		return CallResult{
-			CallID:  call.ID,
-			Content: fmt.Sprintf("error executing tool: %v", err),
-			IsError: true,
+			CallID:   call.ID,
+			Content:  fmt.Sprintf("error executing tool: %v", err),
+			IsError:  true,
+			Duration: duration,
+			Verdicts: verdicts,
+			Warnings: warnings,
+			Threats:  threats,
 		}
 	}
 
-	// If security is configured and we have output, scan it
-	if security != nil && result.Output != "" && !result.IsError {
+	outputBytes := result.Bytes
+	if outputBytes == 0 {
+		outputBytes = len(result.Output)
+	}
+
+	// If security is configured and we have output, scan it. Failed
+	// commands are scanned too: their output can hold secrets just the same.
+	if security != nil && result.Output != "" {
 		scanResult, err := security.ScanContent(ctx, call.Name, result.Output)
 		if err != nil {
 			// Log scan error but don't fail - output scanning is best-effort
 			LogWarning(call.Name, fmt.Sprintf("content scan failed: %v", err), nil)
 		} else {
+			verdicts = append(verdicts, scanResult.Verdicts...)
 			switch scanResult.Action {
 			case ScanActionBlock:
 				return CallResult{
-					CallID:  call.ID,
-					Content: fmt.Sprintf("Output blocked by security policy: %s", scanResult.Message),
-					IsError: true,
+					CallID:   call.ID,
+					Content:  fmt.Sprintf("Output blocked by security policy: %s", scanResult.Message),
+					IsError:  true,
+					ExitCode: result.ExitCode,
+					Duration: duration,
+					Bytes:    outputBytes,
+					Verdicts: verdicts,
+					Warnings: warnings,
+					Threats:  threats,
 				}
 			case ScanActionSanitize:
 				result.Output = scanResult.ProcessedContent
 			case ScanActionWarn:
 				LogWarning(call.Name, fmt.Sprintf("content warning: %s", scanResult.Message), scanResult.ThreatsDetected)
+				warnings = append(warnings, fmt.Sprintf("content warning: %s", scanResult.Message))
+				threats = append(threats, scanResult.ThreatsDetected...)
 			// ScanActionAllow - use output as-is
 			}
 		}
 	}
 
 	return CallResult{
-		CallID:  call.ID,
-		Content: result.Output,
-		IsError: result.IsError,
+		CallID:   call.ID,
+		Content:  result.Output,
+		IsError:  result.IsError,
+		ExitCode: result.ExitCode,
+		Duration: duration,
+		Bytes:    outputBytes,
+		Verdicts: verdicts,
+		Warnings: warnings,
+		Threats:  threats,
 	}
 }
 
@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"sort"
+)
+
+// JSONSchemaDialect is the JSON Schema version tool input schemas follow
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Tool sources reported in a schema export
+const (
+	SourceBuiltin       = "builtin"
+	SourceDefaultPlugin = "default_plugin"
+	SourcePlugin        = "plugin"
+)
+
+// SchemaExport describes every tool available to the agent, for external
+// policy editors and documentation generators
+type SchemaExport struct {
+	Version string       `json:"bast_version"`
+	Tools   []ToolSchema `json:"tools"`
+}
+
+// ToolSchema is one tool's definition with a standalone input schema
+type ToolSchema struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Source      string      `json:"source"`
+	InputSchema InputSchema `json:"input_schema"`
+}
+
+// ExportSchema lists the registry's tools sorted by name. Each input
+// schema carries a $schema keyword so it can be validated on its own.
+func (r *Registry) ExportSchema(version string) SchemaExport {
+	export := SchemaExport{Version: version, Tools: []ToolSchema{}}
+	for _, tool := range r.List() {
+		schema := tool.InputSchema()
+		schema.Schema = JSONSchemaDialect
+		if schema.Properties == nil {
+			schema.Properties = map[string]Property{}
+		}
+		export.Tools = append(export.Tools, ToolSchema{
+			Name:        tool.Name(),
+			Description: tool.Description(),
+			Source:      toolSource(tool),
+			InputSchema: schema,
+		})
+	}
+	sort.Slice(export.Tools, func(i, j int) bool {
+		return export.Tools[i].Name < export.Tools[j].Name
+	})
+	return export
+}
+
+// toolSource reports where a tool came from
+func toolSource(tool Tool) string {
+	plugin, ok := tool.(*PluginTool)
+	switch {
+	case !ok:
+		return SourceBuiltin
+	case plugin.shipped:
+		return SourceDefaultPlugin
+	default:
+		return SourcePlugin
+	}
+}
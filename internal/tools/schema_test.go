@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExportSchema(t *testing.T) {
+	registry := NewRegistry()
+	RegisterBuiltins(registry, t.TempDir())
+	if err := RegisterDefaultPlugins(registry, t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	registry.Register(&PluginTool{manifest: PluginManifest{Name: "deploy", Description: "Deploy"}})
+
+	export := registry.ExportSchema("1.2.3")
+	if export.Version != "1.2.3" || len(export.Tools) != len(registry.List()) {
+		t.Fatalf("export = %d tools, version %q", len(export.Tools), export.Version)
+	}
+
+	sources := map[string]string{}
+	for i, tool := range export.Tools {
+		if i > 0 && export.Tools[i-1].Name > tool.Name {
+			t.Errorf("tools not sorted: %s before %s", export.Tools[i-1].Name, tool.Name)
+		}
+		if tool.InputSchema.Schema != JSONSchemaDialect || tool.InputSchema.Properties == nil {
+			t.Errorf("%s: input schema = %+v", tool.Name, tool.InputSchema)
+		}
+		sources[tool.Name] = tool.Source
+	}
+	for name, want := range map[string]string{
+		"run_command": SourceBuiltin,
+		"git_summary": SourceDefaultPlugin,
+		"deploy":      SourcePlugin,
+	} {
+		if sources[name] != want {
+			t.Errorf("source of %s = %q, want %q", name, sources[name], want)
+		}
+	}
+
+	data, err := json.Marshal(export)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"$schema":"`+JSONSchemaDialect+`"`) {
+		t.Errorf("JSON missing $schema: %s", data)
+	}
+
+	// Definitions sent to the model carry no $schema keyword
+	for _, def := range registry.GetDefinitions() {
+		if def.InputSchema.Schema != "" {
+			t.Errorf("%s: definition has $schema", def.Name)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SecretsFileName is the name of the local secrets file used for plugin env injection
+const SecretsFileName = "secrets.yaml"
+
+// PluginEnvVar declares an environment variable a plugin needs at execution time.
+// Values are resolved locally and never included in prompts or tool schemas.
+type PluginEnvVar struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Optional    bool   `yaml:"optional"` // Missing optional vars are skipped instead of failing
+}
+
+// DefaultSecretsPath returns the path to the local secrets file
+func DefaultSecretsPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", "bast", SecretsFileName), nil
+}
+
+// LoadSecrets reads a flat NAME: value map from a YAML secrets file.
+// A missing file is not an error and yields an empty map.
+func LoadSecrets(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("failed to read secrets file: %w", err)
+	}
+
+	secrets := make(map[string]string)
+	if err := yaml.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets file: %w", err)
+	}
+	return secrets, nil
+}
+
+// MissingEnvError is returned when required plugin env vars cannot be resolved
+type MissingEnvError struct {
+	Tool    string
+	Missing []string
+}
+
+func (e *MissingEnvError) Error() string {
+	return fmt.Sprintf("tool %s requires environment variable(s) %s; set them in your environment or in ~/.config/bast/%s",
+		e.Tool, strings.Join(e.Missing, ", "), SecretsFileName)
+}
+
+// resolvePluginEnv resolves declared env vars from the process environment first,
+// then from the secrets map. Returns NAME=value pairs ready for exec.Cmd.Env.
+func resolvePluginEnv(toolName string, vars []PluginEnvVar, secrets map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string)
+	var missing []string
+
+	for _, v := range vars {
+		if value, ok := os.LookupEnv(v.Name); ok && value != "" {
+			resolved[v.Name] = value
+			continue
+		}
+		if value, ok := secrets[v.Name]; ok && value != "" {
+			resolved[v.Name] = value
+			continue
+		}
+		if !v.Optional {
+			missing = append(missing, v.Name)
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, &MissingEnvError{Tool: toolName, Missing: missing}
+	}
+	return resolved, nil
+}
+
+// redactSecrets replaces any resolved secret values in output so they are never
+// echoed back to the model
+func redactSecrets(output string, env map[string]string) string {
+	for name, value := range env {
+		if value == "" {
+			continue
+		}
+		output = strings.ReplaceAll(output, value, "[REDACTED:"+name+"]")
+	}
+	return output
+}
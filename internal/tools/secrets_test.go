@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPluginEnvInjection(t *testing.T) {
+	tmpDir := t.TempDir()
+	secretsPath := filepath.Join(tmpDir, SecretsFileName)
+	os.WriteFile(secretsPath, []byte("BAST_TEST_TOKEN: s3cr3t-value\n"), 0600)
+
+	newPlugin := func(env []PluginEnvVar) *PluginTool {
+		return &PluginTool{
+			manifest: PluginManifest{
+				Name:        "env_test",
+				Description: "prints a token",
+				Command:     `echo "token=$BAST_TEST_TOKEN"`,
+				Env:         env,
+			},
+			basePath:    tmpDir,
+			secretsPath: secretsPath,
+		}
+	}
+
+	t.Run("injects value from secrets file and redacts it", func(t *testing.T) {
+		tool := newPlugin([]PluginEnvVar{{Name: "BAST_TEST_TOKEN"}})
+		result, err := tool.Execute(context.Background(), json.RawMessage(`{}`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("expected success, got error: %s", result.Output)
+		}
+		if strings.Contains(result.Output, "s3cr3t-value") {
+			t.Errorf("secret value leaked into output: %s", result.Output)
+		}
+		if !strings.Contains(result.Output, "[REDACTED:BAST_TEST_TOKEN]") {
+			t.Errorf("expected redaction marker, got: %s", result.Output)
+		}
+	})
+
+	t.Run("environment takes precedence over secrets file", func(t *testing.T) {
+		t.Setenv("BAST_TEST_TOKEN", "from-env")
+		env, err := resolvePluginEnv("env_test", []PluginEnvVar{{Name: "BAST_TEST_TOKEN"}}, map[string]string{"BAST_TEST_TOKEN": "from-file"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if env["BAST_TEST_TOKEN"] != "from-env" {
+			t.Errorf("expected env value, got: %s", env["BAST_TEST_TOKEN"])
+		}
+	})
+
+	t.Run("fails with clear message when required var missing", func(t *testing.T) {
+		tool := newPlugin([]PluginEnvVar{{Name: "BAST_TEST_MISSING"}})
+		result, err := tool.Execute(context.Background(), json.RawMessage(`{}`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !result.IsError {
+			t.Fatal("expected error for missing env var")
+		}
+		if !strings.Contains(result.Output, "BAST_TEST_MISSING") {
+			t.Errorf("expected missing var name in output, got: %s", result.Output)
+		}
+	})
+
+	t.Run("skips missing optional var", func(t *testing.T) {
+		tool := newPlugin([]PluginEnvVar{{Name: "BAST_TEST_MISSING", Optional: true}})
+		result, err := tool.Execute(context.Background(), json.RawMessage(`{}`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.IsError {
+			t.Errorf("expected success, got error: %s", result.Output)
+		}
+	})
+}
@@ -0,0 +1,182 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SecurityValidator validates tool calls before execution and scans their
+// output afterwards. Implementations include BastioSecurityClient (remote
+// Agent Security API) and LocalSecurityClient (on-device patterns); other
+// policy engines or DLP services can be plugged in the same way.
+type SecurityValidator interface {
+	ValidateToolCall(ctx context.Context, call Call) (*ValidationResult, error)
+	ScanContent(ctx context.Context, toolName string, content string) (*ScanResult, error)
+}
+
+// FailMode controls what a chain does when a validator errors
+type FailMode string
+
+const (
+	FailOpen   FailMode = "open"   // Log the error and continue with the next validator
+	FailClosed FailMode = "closed" // Treat the error as a block verdict
+)
+
+// ChainStage is one named validator in a ChainValidator
+type ChainStage struct {
+	Name      string
+	Validator SecurityValidator
+	FailMode  FailMode
+}
+
+// Verdict records one validator's decision for a tool call or its output
+type Verdict struct {
+	Validator string   `json:"validator"`
+	Stage     string   `json:"stage"` // "validate" or "scan"
+	Action    string   `json:"action"`
+	RiskScore float64  `json:"risk_score,omitempty"`
+	Threats   []string `json:"threats,omitempty"`
+	Message   string   `json:"message,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// ChainValidator runs several validators in order. Validation stops at the
+// first block or approval verdict; scanning feeds sanitized output from one
+// validator into the next and stops on block.
+type ChainValidator struct {
+	stages []ChainStage
+}
+
+// NewChainValidator creates a validator that runs the given stages in order.
+// Stages without a validator are skipped; an empty FailMode means FailOpen.
+func NewChainValidator(stages ...ChainStage) *ChainValidator {
+	chain := &ChainValidator{}
+	for _, stage := range stages {
+		if stage.Validator == nil {
+			continue
+		}
+		if stage.FailMode == "" {
+			stage.FailMode = FailOpen
+		}
+		chain.stages = append(chain.stages, stage)
+	}
+	return chain
+}
+
+// ValidateToolCall runs each validator until one blocks or requires approval.
+func (c *ChainValidator) ValidateToolCall(ctx context.Context, call Call) (*ValidationResult, error) {
+	verdict := &ValidationResult{Action: ActionAllow}
+	var warnings []string
+
+	for _, stage := range c.stages {
+		result, err := stage.Validator.ValidateToolCall(ctx, call)
+		if err != nil {
+			verdict.Verdicts = append(verdict.Verdicts, Verdict{
+				Validator: stage.Name,
+				Stage:     "validate",
+				Action:    string(failAction(stage.FailMode, ActionAllow, ActionBlock)),
+				Error:     err.Error(),
+			})
+			if stage.FailMode == FailClosed {
+				verdict.Action = ActionBlock
+				verdict.Message = fmt.Sprintf("%s validator unavailable: %v", stage.Name, err)
+				return verdict, nil
+			}
+			LogWarning(call.Name, fmt.Sprintf("%s validation failed: %v", stage.Name, err), nil)
+			continue
+		}
+
+		verdict.Verdicts = append(verdict.Verdicts, Verdict{
+			Validator: stage.Name,
+			Stage:     "validate",
+			Action:    string(result.Action),
+			RiskScore: result.RiskScore,
+			Threats:   result.ThreatsDetected,
+			Message:   result.Message,
+		})
+		if result.RiskScore > verdict.RiskScore {
+			verdict.RiskScore = result.RiskScore
+		}
+		verdict.ThreatsDetected = append(verdict.ThreatsDetected, result.ThreatsDetected...)
+
+		switch result.Action {
+		case ActionBlock, ActionRequireApproval:
+			verdict.Action = result.Action
+			verdict.Message = fmt.Sprintf("%s (%s)", result.Message, stage.Name)
+			verdict.ApprovalID = result.ApprovalID
+			return verdict, nil
+		case ActionWarn:
+			verdict.Action = ActionWarn
+			warnings = append(warnings, result.Message)
+		}
+	}
+
+	verdict.Message = strings.Join(warnings, "; ")
+	return verdict, nil
+}
+
+// ScanContent passes content through each validator in turn.
+func (c *ChainValidator) ScanContent(ctx context.Context, toolName string, content string) (*ScanResult, error) {
+	verdict := &ScanResult{Action: ScanActionAllow, ProcessedContent: content}
+	var messages []string
+
+	for _, stage := range c.stages {
+		result, err := stage.Validator.ScanContent(ctx, toolName, verdict.ProcessedContent)
+		if err != nil {
+			verdict.Verdicts = append(verdict.Verdicts, Verdict{
+				Validator: stage.Name,
+				Stage:     "scan",
+				Action:    string(failAction(stage.FailMode, ScanActionAllow, ScanActionBlock)),
+				Error:     err.Error(),
+			})
+			if stage.FailMode == FailClosed {
+				verdict.Action = ScanActionBlock
+				verdict.Message = fmt.Sprintf("%s scanner unavailable: %v", stage.Name, err)
+				return verdict, nil
+			}
+			LogWarning(toolName, fmt.Sprintf("%s content scan failed: %v", stage.Name, err), nil)
+			continue
+		}
+
+		verdict.Verdicts = append(verdict.Verdicts, Verdict{
+			Validator: stage.Name,
+			Stage:     "scan",
+			Action:    string(result.Action),
+			RiskScore: result.RiskScore,
+			Threats:   result.ThreatsDetected,
+			Message:   result.Message,
+		})
+		if result.RiskScore > verdict.RiskScore {
+			verdict.RiskScore = result.RiskScore
+		}
+		verdict.ThreatsDetected = append(verdict.ThreatsDetected, result.ThreatsDetected...)
+
+		switch result.Action {
+		case ScanActionBlock:
+			verdict.Action = ScanActionBlock
+			verdict.Message = fmt.Sprintf("%s (%s)", result.Message, stage.Name)
+			return verdict, nil
+		case ScanActionSanitize:
+			verdict.Action = ScanActionSanitize
+			verdict.ProcessedContent = result.ProcessedContent
+			messages = append(messages, result.Message)
+		case ScanActionWarn:
+			if verdict.Action == ScanActionAllow {
+				verdict.Action = ScanActionWarn
+			}
+			messages = append(messages, result.Message)
+		}
+	}
+
+	verdict.Message = strings.Join(messages, "; ")
+	return verdict, nil
+}
+
+// failAction picks the recorded action for a validator error
+func failAction[T any](mode FailMode, open, closed T) T {
+	if mode == FailClosed {
+		return closed
+	}
+	return open
+}
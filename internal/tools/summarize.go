@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultPromptOutputLines is how many lines of a command's output the
+// model sees when no budget is configured
+const DefaultPromptOutputLines = 60
+
+// maxSummaryLineLen caps each line kept in a summary
+const maxSummaryLineLen = 300
+
+// notableLine matches lines worth keeping from the middle of long output:
+// errors and failures, and the section headers of formatStreams
+var notableLine = regexp.MustCompile(`(?i)\b(error|errors|fail|failed|failure|fatal|panic|exception|traceback|denied|not found)\b|^(stdout|stderr|exit):`)
+
+// SummarizeOutput shortens output to about maxLines lines for a prompt. It
+// keeps the head and tail, which carry the command's framing and its final
+// result, and fills the rest of the budget with error lines from the middle.
+// Skipped runs are replaced with a marker counting the omitted lines.
+func SummarizeOutput(output string, maxLines int) string {
+	if maxLines <= 0 {
+		maxLines = DefaultPromptOutputLines
+	}
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) <= maxLines {
+		return output
+	}
+
+	head := maxLines / 4
+	tail := maxLines / 2
+	keep := make([]bool, len(lines))
+	for i := 0; i < head; i++ {
+		keep[i] = true
+	}
+	for i := len(lines) - tail; i < len(lines); i++ {
+		keep[i] = true
+	}
+	budget := maxLines - head - tail
+	for i := head; i < len(lines)-tail && budget > 0; i++ {
+		if notableLine.MatchString(lines[i]) {
+			keep[i] = true
+			budget--
+		}
+	}
+
+	var b strings.Builder
+	omitted := 0
+	for i, line := range lines {
+		if !keep[i] {
+			omitted++
+			continue
+		}
+		if omitted > 0 {
+			fmt.Fprintf(&b, "... (%d lines omitted)\n", omitted)
+			omitted = 0
+		}
+		if len(line) > maxSummaryLineLen {
+			line = line[:maxSummaryLineLen] + "..."
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,33 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSummarizeOutput(t *testing.T) {
+	short := "one\ntwo\nthree"
+	if got := SummarizeOutput(short, 10); got != short {
+		t.Errorf("short output changed: %q", got)
+	}
+
+	var lines []string
+	for i := 1; i <= 100; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	lines[49] = "main.go:12: undefined: foo (error)"
+	got := SummarizeOutput(strings.Join(lines, "\n"), 20)
+
+	for _, want := range []string{"line 1\n", "line 5\n", "main.go:12: undefined: foo", "line 100", "lines omitted"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("summary missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "line 6\n") || strings.Contains(got, "line 80\n") {
+		t.Errorf("summary kept lines outside the budget:\n%s", got)
+	}
+	if n := strings.Count(got, "\n") + 1; n > 20+3 {
+		t.Errorf("summary has %d lines, want about 20", n)
+	}
+}
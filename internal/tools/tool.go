@@ -3,6 +3,7 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"time"
 )
 
 // Tool defines the interface that all tools must implement
@@ -22,6 +23,7 @@ type Tool interface {
 
 // InputSchema defines the JSON schema for tool input parameters
 type InputSchema struct {
+	Schema     string              `json:"$schema,omitempty"` // Set only in schema exports
 	Type       string              `json:"type"`
 	Properties map[string]Property `json:"properties"`
 	Required   []string            `json:"required,omitempty"`
@@ -36,8 +38,10 @@ type Property struct {
 
 // Result represents the output of a tool execution
 type Result struct {
-	Output  string `json:"output"`            // The tool's output
-	IsError bool   `json:"is_error,omitempty"` // True if this represents an error
+	Output   string `json:"output"`              // The tool's output
+	IsError  bool   `json:"is_error,omitempty"`  // True if this represents an error
+	ExitCode *int   `json:"exit_code,omitempty"` // Process exit code (nil for tools that don't run processes)
+	Bytes    int    `json:"bytes,omitempty"`     // Raw output size before truncation (0 means len(Output))
 }
 
 // Definition represents a tool definition for the AI API
@@ -56,7 +60,13 @@ type Call struct {
 
 // CallResult represents the result of executing a tool call
 type CallResult struct {
-	CallID  string `json:"call_id"`
-	Content string `json:"content"`
-	IsError bool   `json:"is_error,omitempty"`
+	CallID   string        `json:"call_id"`
+	Content  string        `json:"content"`
+	IsError  bool          `json:"is_error,omitempty"`
+	ExitCode *int          `json:"exit_code,omitempty"` // Process exit code, if the tool ran one
+	Duration time.Duration `json:"duration,omitempty"`  // Wall-clock execution time
+	Bytes    int           `json:"bytes,omitempty"`     // Raw output size in bytes
+	Verdicts []Verdict     `json:"verdicts,omitempty"`  // Security decisions, in validator order
+	Warnings []string      `json:"warnings,omitempty"`  // Security warnings raised for this call
+	Threats  []string      `json:"threats,omitempty"`   // Threat tags behind the warnings
 }
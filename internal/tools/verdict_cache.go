@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// DefaultVerdictTTL is how long an allow verdict is reused for an identical call
+const DefaultVerdictTTL = 30 * time.Second
+
+// readOnlyTools are built-in tools whose calls have no side effects.
+// Only these are eligible for verdict caching; anything else may mutate
+// state between identical calls and is always revalidated.
+var readOnlyTools = map[string]bool{
+	"read_file":      true,
+	"list_directory": true,
+}
+
+// IsReadOnly reports whether name is a built-in tool whose calls have no
+// side effects
+func IsReadOnly(name string) bool {
+	return readOnlyTools[name]
+}
+
+// VerdictCache wraps a SecurityValidator and reuses allow verdicts for
+// identical read-only tool calls within a session. Content scans are always
+// delegated, since output can change between identical calls.
+type VerdictCache struct {
+	Validator SecurityValidator
+	TTL       time.Duration
+
+	mu      sync.Mutex
+	entries map[string]time.Time // cache key -> expiry
+	now     func() time.Time
+}
+
+// NewVerdictCache wraps validator with a session-scoped allow cache.
+// A zero ttl falls back to DefaultVerdictTTL.
+func NewVerdictCache(validator SecurityValidator, ttl time.Duration) *VerdictCache {
+	if ttl <= 0 {
+		ttl = DefaultVerdictTTL
+	}
+	return &VerdictCache{
+		Validator: validator,
+		TTL:       ttl,
+		entries:   make(map[string]time.Time),
+		now:       time.Now,
+	}
+}
+
+// ValidateToolCall returns a cached allow verdict when available,
+// otherwise validates and caches allow verdicts for read-only tools.
+func (c *VerdictCache) ValidateToolCall(ctx context.Context, call Call) (*ValidationResult, error) {
+	if !readOnlyTools[call.Name] {
+		return c.Validator.ValidateToolCall(ctx, call)
+	}
+
+	key := verdictCacheKey(call)
+	c.mu.Lock()
+	expiry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && c.now().Before(expiry) {
+		return &ValidationResult{Action: ActionAllow, Message: "cached verdict"}, nil
+	}
+
+	result, err := c.Validator.ValidateToolCall(ctx, call)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if result.Action == ActionAllow {
+		c.entries[key] = c.now().Add(c.TTL)
+	} else {
+		delete(c.entries, key)
+	}
+	c.mu.Unlock()
+
+	return result, nil
+}
+
+// ScanContent delegates to the wrapped validator.
+func (c *VerdictCache) ScanContent(ctx context.Context, toolName string, content string) (*ScanResult, error) {
+	return c.Validator.ScanContent(ctx, toolName, content)
+}
+
+// verdictCacheKey identifies a call by tool name and input hash
+func verdictCacheKey(call Call) string {
+	sum := sha256.Sum256(call.Input)
+	return call.Name + ":" + hex.EncodeToString(sum[:])
+}
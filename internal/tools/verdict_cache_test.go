@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestVerdictCache(t *testing.T) {
+	readCall := Call{ID: "call-1", Name: "read_file", Input: json.RawMessage(`{"path": "main.go"}`)}
+
+	t.Run("reuses allow verdict for identical read-only call", func(t *testing.T) {
+		inner := &stubValidator{validate: &ValidationResult{Action: ActionAllow}}
+		cache := NewVerdictCache(inner, time.Minute)
+
+		for i := 0; i < 3; i++ {
+			if _, err := cache.ValidateToolCall(context.Background(), readCall); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if inner.calls != 1 {
+			t.Errorf("expected 1 validation round-trip, got %d", inner.calls)
+		}
+	})
+
+	t.Run("revalidates after ttl expires", func(t *testing.T) {
+		inner := &stubValidator{validate: &ValidationResult{Action: ActionAllow}}
+		cache := NewVerdictCache(inner, time.Minute)
+		now := time.Now()
+		cache.now = func() time.Time { return now }
+
+		cache.ValidateToolCall(context.Background(), readCall)
+		now = now.Add(2 * time.Minute)
+		cache.ValidateToolCall(context.Background(), readCall)
+		if inner.calls != 2 {
+			t.Errorf("expected revalidation after ttl, got %d calls", inner.calls)
+		}
+	})
+
+	t.Run("always revalidates mutating tools", func(t *testing.T) {
+		inner := &stubValidator{validate: &ValidationResult{Action: ActionAllow}}
+		cache := NewVerdictCache(inner, time.Minute)
+		call := Call{ID: "call-2", Name: "run_command", Input: json.RawMessage(`{"command": "ls"}`)}
+
+		cache.ValidateToolCall(context.Background(), call)
+		cache.ValidateToolCall(context.Background(), call)
+		if inner.calls != 2 {
+			t.Errorf("expected 2 validations, got %d", inner.calls)
+		}
+	})
+
+	t.Run("does not cache non-allow verdicts", func(t *testing.T) {
+		inner := &stubValidator{validate: &ValidationResult{Action: ActionWarn}}
+		cache := NewVerdictCache(inner, time.Minute)
+
+		cache.ValidateToolCall(context.Background(), readCall)
+		cache.ValidateToolCall(context.Background(), readCall)
+		if inner.calls != 2 {
+			t.Errorf("expected 2 validations, got %d", inner.calls)
+		}
+	})
+}
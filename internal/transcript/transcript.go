@@ -0,0 +1,291 @@
+// Package transcript writes agent runs to disk as they happen, in a
+// versioned JSON Lines format that other tools can read
+package transcript
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bastio-ai/bast/internal/ai"
+	"github.com/bastio-ai/bast/internal/safety"
+	"github.com/bastio-ai/bast/internal/tools"
+)
+
+// SchemaVersion is the version of the record format. It changes only when
+// a field is removed or changes meaning; new fields may appear at any time.
+const SchemaVersion = 1
+
+// DirName is the directory under ~/.config/bast that holds one transcript
+// per TUI session, named after the session ID
+const DirName = "transcripts"
+
+// Record types, in the order they appear for a run
+const (
+	TypeStart    = "start"     // The run began: query and config snapshot
+	TypeStep     = "step"      // An API round-trip: the model's text and token usage
+	TypeToolCall = "tool_call" // A tool call finished
+	TypeEnd      = "end"       // The run finished or failed
+)
+
+// Record is one line of a transcript. Every record carries the schema
+// version, the session and run it belongs to, and its type; the other
+// fields are set according to the type.
+type Record struct {
+	Version   int       `json:"v"`
+	Type      string    `json:"type"`
+	Time      time.Time `json:"time"`
+	SessionID string    `json:"session_id"`
+	Run       int       `json:"run"` // Counts agent runs within the session from 1
+
+	Query  string  `json:"query,omitempty"`  // start
+	Config *Config `json:"config,omitempty"` // start
+
+	Step *Step `json:"step,omitempty"` // step
+
+	Tool *ToolCall `json:"tool,omitempty"` // tool_call
+
+	Outcome *Outcome `json:"outcome,omitempty"` // end
+}
+
+// Config is the snapshot of settings a run started with
+type Config struct {
+	Model         string   `json:"model"`
+	Gateway       string   `json:"gateway,omitempty"` // "bastio" or "direct"
+	SafetyMode    string   `json:"safety_mode,omitempty"`
+	Target        string   `json:"target,omitempty"` // Where commands run; empty for this machine
+	CWD           string   `json:"cwd"`
+	MaxIterations int      `json:"max_iterations"`
+	Validators    []string `json:"validators,omitempty"`
+	Tools         []string `json:"tools"`
+}
+
+// Usage counts the tokens of one API call or a whole run
+type Usage struct {
+	Input      int64 `json:"input_tokens"` // Uncached
+	CacheWrite int64 `json:"cache_write_tokens"`
+	CacheRead  int64 `json:"cache_read_tokens"`
+	Output     int64 `json:"output_tokens"`
+}
+
+// Step is one API round-trip
+type Step struct {
+	Iteration int    `json:"iteration"`
+	Text      string `json:"text,omitempty"`
+	ToolUses  int    `json:"tool_uses"`
+	Usage     Usage  `json:"usage"`
+}
+
+// ToolCall is a finished tool call with its result and security verdicts
+type ToolCall struct {
+	ID         string          `json:"id"`
+	Name       string          `json:"name"`
+	Input      json.RawMessage `json:"input,omitempty"`
+	Output     string          `json:"output"`
+	IsError    bool            `json:"is_error,omitempty"`
+	ExitCode   *int            `json:"exit_code,omitempty"`
+	DurationMS int64           `json:"duration_ms"`
+	Bytes      int             `json:"bytes"`
+	Repeated   bool            `json:"repeated,omitempty"` // Answered from an identical earlier call
+	Verdicts   []tools.Verdict `json:"verdicts,omitempty"`
+	Warnings   []string        `json:"warnings,omitempty"`
+	Threats    []string        `json:"threats,omitempty"`
+}
+
+// Outcome is how a run ended
+type Outcome struct {
+	Status     string   `json:"status,omitempty"` // The agent's verdict: success, partial, or failed
+	Summary    string   `json:"summary,omitempty"`
+	Response   string   `json:"response,omitempty"`
+	Error      string   `json:"error,omitempty"` // Set when the run itself failed
+	Iterations int      `json:"iterations"`
+	ToolCalls  int      `json:"tool_calls"`
+	Usage      Usage    `json:"usage"`
+	FollowUps  []string `json:"follow_ups,omitempty"`
+}
+
+// DefaultDir returns the default transcripts directory
+func DefaultDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", "bast", DirName), nil
+}
+
+// Path returns the transcript file for a session in dir
+func Path(dir, sessionID string) string {
+	return filepath.Join(dir, sessionID+".jsonl")
+}
+
+// runPath returns the file beside a session's transcript that holds the
+// number of its last run, so starting a run doesn't read the transcript
+func runPath(dir, sessionID string) string {
+	return filepath.Join(dir, sessionID+".run")
+}
+
+// nextRun claims the next run number of a session. The counter is saved
+// before the run writes anything, so a crash never reuses a number.
+func nextRun(dir, sessionID string) (int, error) {
+	path := runPath(dir, sessionID)
+	last := 0
+	if data, err := os.ReadFile(path); err == nil {
+		last, _ = strconv.Atoi(strings.TrimSpace(string(data)))
+	}
+	if err := os.WriteFile(path, []byte(strconv.Itoa(last+1)+"\n"), 0600); err != nil {
+		return 0, fmt.Errorf("failed to save transcript run counter: %w", err)
+	}
+	return last + 1, nil
+}
+
+// Writer appends the records of one agent run to a session's transcript.
+// Each record is written as soon as it happens, so a crash loses at most
+// the step in flight, and credentials in its text are redacted first.
+// It is safe for concurrent use.
+type Writer struct {
+	mu        sync.Mutex
+	file      *os.File
+	sessionID string
+	run       int
+	toolCalls int
+	usage     Usage
+}
+
+// Open starts the next run of a session, appending to its transcript in dir
+func Open(dir, sessionID string) (*Writer, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create transcripts directory: %w", err)
+	}
+	run, err := nextRun(dir, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(Path(dir, sessionID), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open transcript: %w", err)
+	}
+	return &Writer{file: f, sessionID: sessionID, run: run}, nil
+}
+
+// Start records the query and the settings the run uses
+func (w *Writer) Start(query string, cfg Config) error {
+	query, _ = safety.RedactSecrets(query)
+	return w.write(Record{Type: TypeStart, Query: query, Config: &cfg})
+}
+
+// Step records an API round-trip
+func (w *Writer) Step(step ai.AgentStep) error {
+	usage := Usage{
+		Input:      step.Cache.Input,
+		CacheWrite: step.Cache.Written,
+		CacheRead:  step.Cache.Read,
+		Output:     step.Output,
+	}
+	w.mu.Lock()
+	w.usage.Input += usage.Input
+	w.usage.CacheWrite += usage.CacheWrite
+	w.usage.CacheRead += usage.CacheRead
+	w.usage.Output += usage.Output
+	w.mu.Unlock()
+	text, _ := safety.RedactSecrets(step.Text)
+	return w.write(Record{Type: TypeStep, Step: &Step{
+		Iteration: step.Iteration,
+		Text:      text,
+		ToolUses:  step.ToolUses,
+		Usage:     usage,
+	}})
+}
+
+// ToolCall records a finished tool call
+func (w *Writer) ToolCall(call ai.ToolCall) error {
+	w.mu.Lock()
+	w.toolCalls++
+	w.mu.Unlock()
+	output, _ := safety.RedactSecrets(call.Output)
+	return w.write(Record{Type: TypeToolCall, Tool: &ToolCall{
+		ID:         call.ID,
+		Name:       call.Name,
+		Input:      safety.RedactSecretsJSON(call.Input),
+		Output:     output,
+		IsError:    call.IsError,
+		ExitCode:   call.ExitCode,
+		DurationMS: call.Duration.Milliseconds(),
+		Bytes:      call.Bytes,
+		Repeated:   call.Repeated,
+		Verdicts:   call.Verdicts,
+		Warnings:   call.Warnings,
+		Threats:    call.Threats,
+	}})
+}
+
+// End records how the run ended, from its result or the error that
+// stopped it, and closes the transcript. result may be nil.
+func (w *Writer) End(result *ai.AgentResult, runErr error) error {
+	w.mu.Lock()
+	outcome := Outcome{ToolCalls: w.toolCalls, Usage: w.usage}
+	w.mu.Unlock()
+	if result != nil {
+		outcome.Status = string(result.Status)
+		outcome.Summary, _ = safety.RedactSecrets(result.Summary)
+		outcome.Response, _ = safety.RedactSecrets(result.Response)
+		outcome.Iterations = result.Iterations
+		outcome.FollowUps = result.FollowUps
+	}
+	if runErr != nil {
+		outcome.Error, _ = safety.RedactSecrets(runErr.Error())
+	}
+	err := w.write(Record{Type: TypeEnd, Outcome: &outcome})
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// write appends one record as a line
+func (w *Writer) write(r Record) error {
+	r.Version = SchemaVersion
+	r.Time = time.Now()
+	r.SessionID = w.sessionID
+	r.Run = w.run
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to marshal transcript record: %w", err)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
+	}
+	return nil
+}
+
+// Read returns the records in the transcript at path. Lines cut short by
+// a crash are skipped; records from a newer schema version are an error.
+func Read(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var r Record
+		if json.Unmarshal(scanner.Bytes(), &r) != nil {
+			continue
+		}
+		if r.Version > SchemaVersion {
+			return nil, fmt.Errorf("%s uses transcript version %d; this bast reads up to %d", path, r.Version, SchemaVersion)
+		}
+		records = append(records, r)
+	}
+	return records, scanner.Err()
+}
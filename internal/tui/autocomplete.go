@@ -1,6 +1,8 @@
 package tui
 
 import (
+	"strings"
+
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/bastio-ai/bast/internal/files"
@@ -47,12 +49,31 @@ func (m Model) checkForMention() (Model, tea.Cmd) {
 	return m, m.searchFiles(mentionText)
 }
 
-// searchFiles returns a command that searches for files matching the prefix
+// searchFiles returns a command that searches for files matching the prefix.
+// Once the prefix contains a slash, it lists the directory it points into.
+// The prefetched index answers when it is current; otherwise the tree is
+// searched live and the index rebuilt.
 func (m Model) searchFiles(prefix string) tea.Cmd {
 	cwd := m.shellCtx.CWD
+	index := m.fileIndex
 	return func() tea.Msg {
+		if strings.Contains(prefix, "/") {
+			return SuggestionsMsg{Suggestions: files.ListDir(cwd, prefix, files.MaxSuggestions)}
+		}
+		if index.Usable(cwd) {
+			return SuggestionsMsg{Suggestions: index.Search(prefix, files.MaxSuggestions)}
+		}
 		results := files.ListFiles(cwd, prefix, files.MaxSuggestions)
-		return SuggestionsMsg{Suggestions: results}
+		return SuggestionsMsg{Suggestions: results, StaleIndex: index != nil}
+	}
+}
+
+// prefetchFileIndex returns a command that loads or builds the @mention
+// suggestion index for the current directory
+func (m Model) prefetchFileIndex() tea.Cmd {
+	cwd := m.shellCtx.CWD
+	return func() tea.Msg {
+		return FileIndexMsg{Index: files.PrefetchIndex(cwd)}
 	}
 }
 
@@ -81,5 +102,10 @@ func (m Model) insertSuggestion() (tea.Model, tea.Cmd) {
 	m.suggestions = nil
 	m.lastMentionText = ""
 
+	// Picking a directory descends into it
+	if strings.HasSuffix(selected, "/") {
+		model, search := m.checkForMention()
+		return model, search
+	}
 	return m, nil
 }
@@ -0,0 +1,138 @@
+package tui
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/bastio-ai/bast/internal/ai"
+)
+
+// chatModel returns a model in the chat view holding a two-exchange history
+func chatModel() Model {
+	ti := textinput.New()
+	ti.Focus()
+	return Model{
+		mode:      ModeChat,
+		textInput: ti,
+		conversationHistory: []ai.ConversationMessage{
+			{Role: "user", Content: "list files"},
+			{Role: "assistant", Content: "Run `ls -la`"},
+			{Role: "user", Content: "only hidden ones"},
+			{Role: "assistant", Content: "Run `ls -d .*`"},
+		},
+	}
+}
+
+func TestEditSelectNavigatesUserMessages(t *testing.T) {
+	m := chatModel()
+	next, _ := m.handleChatModeKey(tea.KeyMsg{Type: tea.KeyCtrlE})
+	m = next.(Model)
+	if !m.editSelecting || m.editCursor != 2 {
+		t.Fatalf("editSelecting = %v, cursor = %d; want the last user message picked", m.editSelecting, m.editCursor)
+	}
+
+	for _, step := range []struct {
+		key  tea.KeyMsg
+		want int
+	}{
+		{tea.KeyMsg{Type: tea.KeyUp}, 0},
+		{tea.KeyMsg{Type: tea.KeyUp}, 0}, // no earlier user message
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}}, 2},
+		{tea.KeyMsg{Type: tea.KeyDown}, 2}, // no later user message
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}}, 0},
+	} {
+		next, _ = m.handleEditSelectKey(step.key)
+		m = next.(Model)
+		if m.editCursor != step.want {
+			t.Fatalf("after %q cursor = %d, want %d", step.key.String(), m.editCursor, step.want)
+		}
+	}
+
+	next, _ = m.handleEditSelectKey(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+	if m.editSelecting || !m.editing || m.editIndex != 0 {
+		t.Fatalf("editSelecting = %v, editing = %v, editIndex = %d; want message 0 loaded for editing", m.editSelecting, m.editing, m.editIndex)
+	}
+	if got := m.textInput.Value(); got != "list files" {
+		t.Errorf("input = %q, want the picked message", got)
+	}
+}
+
+func TestEditSelectEscCancels(t *testing.T) {
+	m := chatModel()
+	m.editSelecting = true
+	m.editCursor = 2
+
+	next, _ := m.handleEditSelectKey(tea.KeyMsg{Type: tea.KeyEsc})
+	m = next.(Model)
+	if m.editSelecting || m.editing || m.textInput.Value() != "" {
+		t.Errorf("editSelecting = %v, editing = %v, input = %q; want the pick cancelled", m.editSelecting, m.editing, m.textInput.Value())
+	}
+	if len(m.conversationHistory) != 4 {
+		t.Errorf("history has %d messages, want it untouched", len(m.conversationHistory))
+	}
+}
+
+func TestBranchAtArchivesAndTruncates(t *testing.T) {
+	m := chatModel()
+	original := slices.Clone(m.conversationHistory)
+	m.editing = true
+
+	m = m.branchAt(2)
+	if len(m.conversationHistory) != 2 {
+		t.Fatalf("history has %d messages, want 2", len(m.conversationHistory))
+	}
+	if m.editing {
+		t.Error("still editing after branching")
+	}
+	if len(m.chatBranches) != 1 || !slices.Equal(m.chatBranches[0], original) {
+		t.Fatalf("branches = %v, want the full original history archived", m.chatBranches)
+	}
+	if m.branch != 1 || !slices.Equal(m.branchIDs, []int{0}) {
+		t.Errorf("branch = %d, branchIDs = %v; want branch 1 with the original archived as 0", m.branch, m.branchIDs)
+	}
+
+	// The new branch must not write over the archived one
+	m.conversationHistory = append(m.conversationHistory, ai.ConversationMessage{Role: "user", Content: "only directories"})
+	if !slices.Equal(m.chatBranches[0], original) {
+		t.Errorf("archived branch changed to %v", m.chatBranches[0])
+	}
+}
+
+func TestRestoreBranchCycles(t *testing.T) {
+	m := chatModel()
+	original := slices.Clone(m.conversationHistory)
+	m = m.branchAt(2)
+	m.conversationHistory = append(m.conversationHistory,
+		ai.ConversationMessage{Role: "user", Content: "only directories"},
+		ai.ConversationMessage{Role: "assistant", Content: "Run `ls -d */`"},
+	)
+	edited := slices.Clone(m.conversationHistory)
+
+	m = m.restoreBranch()
+	if !slices.Equal(m.conversationHistory, original) || m.branch != 0 {
+		t.Fatalf("history = %v, branch = %d; want the original restored", m.conversationHistory, m.branch)
+	}
+	if !slices.Equal(m.answerCommands, []string{"ls -d .*"}) {
+		t.Errorf("answerCommands = %v, want the restored answer's commands", m.answerCommands)
+	}
+
+	m = m.restoreBranch()
+	if !slices.Equal(m.conversationHistory, edited) || m.branch != 1 {
+		t.Errorf("history = %v, branch = %d; want the edited branch back", m.conversationHistory, m.branch)
+	}
+	if len(m.chatBranches) != 1 || len(m.branchIDs) != 1 {
+		t.Errorf("%d branches, %d IDs; want one of each", len(m.chatBranches), len(m.branchIDs))
+	}
+}
+
+func TestRestoreBranchWithoutBranches(t *testing.T) {
+	m := chatModel()
+	m = m.restoreBranch()
+	if len(m.conversationHistory) != 4 || m.branch != 0 {
+		t.Errorf("history has %d messages, branch = %d; want nothing changed", len(m.conversationHistory), m.branch)
+	}
+}
@@ -0,0 +1,158 @@
+package tui
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/bastio-ai/bast/internal/safety"
+	"github.com/bastio-ai/bast/internal/shell"
+)
+
+// Ranking weights for candidate commands: safety first, then fit with the
+// user's machine and project
+const (
+	dangerousPenalty = 100
+	sudoPenalty      = 20
+	missingPenalty   = 10
+	aliasPenalty     = 5
+	projectToolBonus = 3
+)
+
+// candidate is a generated command with the directory it must run in
+type candidate struct {
+	command string
+	workDir string // Absolute, as Model.workDir; empty to run it where bast is
+}
+
+// newCandidate resolves a generated command's working_dir hint. A directory
+// on the local machine that doesn't exist is kept in the command as a cd,
+// so the shell reports it; one on a remote target can't be checked here.
+func (m Model) newCandidate(command, dir string) candidate {
+	if dir == "" {
+		return candidate{command: command}
+	}
+	if m.remoteTarget() {
+		return candidate{command: command, workDir: dir}
+	}
+	workDir, err := shell.ResolveWorkDir(m.shellCtx.CWD, dir)
+	if err != nil {
+		return candidate{command: shell.InDir(command, dir, "")}
+	}
+	return candidate{command: command, workDir: workDir}
+}
+
+// rankCandidates applies the sudo policy to each generated command and orders
+// the ones it allows by candidateScore, keeping the model's order for ties.
+// It fails only when the policy rejects every candidate.
+func (m Model) rankCandidates(candidates []candidate) ([]candidate, error) {
+	var allowed []candidate
+	var firstErr error
+	seen := make(map[candidate]bool)
+	for _, c := range candidates {
+		command, err := m.applySudoPolicy(c.command)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		c.command = command
+		if !seen[c] {
+			seen[c] = true
+			allowed = append(allowed, c)
+		}
+	}
+	if len(allowed) == 0 {
+		return nil, firstErr
+	}
+
+	scores := make(map[candidate]int, len(allowed))
+	for _, c := range allowed {
+		scores[c] = m.candidateScore(c.command)
+	}
+	sort.SliceStable(allowed, func(i, j int) bool {
+		return scores[allowed[i]] > scores[allowed[j]]
+	})
+	return allowed, nil
+}
+
+// candidateScore rates a command by how safe it is and how well it fits: it
+// loses points for destructive patterns, sudo, binaries missing from PATH,
+// date flags this OS's date doesn't take, and words shadowed by aliases, and
+// gains points for tools the project uses
+func (m Model) candidateScore(command string) int {
+	score := 0
+	if isDangerousCommand(command) {
+		score -= dangerousPenalty
+	}
+	if safety.NeedsSudo(command) {
+		score -= sudoPenalty
+	}
+	if missing := shell.FindMissingBinaries(command, m.shellCtx.OS, ""); missing != nil {
+		score -= missingPenalty * len(missing.Binaries)
+	}
+	score -= missingPenalty * len(shell.DateWarnings(command, m.shellCtx.OS))
+	score -= aliasPenalty * len(shell.AliasWarnings(command, m.shellCtx.Aliases))
+
+	tools := make(map[string]bool, len(m.shellCtx.Tools))
+	for _, tool := range m.shellCtx.Tools {
+		if fields := strings.Fields(tool.Name); len(fields) > 0 {
+			tools[fields[0]] = true
+		}
+	}
+	for _, bin := range shell.CommandBinaries(command) {
+		if tools[bin] {
+			score += projectToolBonus
+		}
+	}
+	return score
+}
+
+// showCandidate makes the i-th ranked candidate the pending command, to
+// run in that candidate's directory
+func (m Model) showCandidate(i int) Model {
+	command := m.candidates[i].command
+	m.candidateIndex = i
+	m.workDir = m.candidates[i].workDir
+	m.rewrite = nil
+	if m.safeRewrite {
+		if m.rewrite = safety.SafeRewrite(command, safety.DetectTrash()); m.rewrite != nil {
+			command = m.rewrite.Command
+		}
+	}
+	m.command = command
+	m.generatedCommand = command
+	m.syntaxErr = nil
+	m.explanation = ""
+	m.isDangerous = isDangerousCommand(command)
+	m.dangerConfirmed = false
+	m.needsSudo = safety.NeedsSudo(command)
+	m.sudoAcknowledged = false
+	m.translation = shell.TranslateCommand(command, m.shellCtx.OS, "")
+	m.missing = shell.FindMissingBinaries(command, m.shellCtx.OS, "")
+	m.aliasWarnings = m.commandWarnings(command)
+	return m
+}
+
+// commandWarnings describes what in the user's shell will make command
+// behave differently than it reads: aliases and functions, language
+// environments it needs that aren't active, date flags for another OS's date,
+// and paths it reads that don't exist, which usually means the model made
+// them up
+func (m Model) commandWarnings(command string) []string {
+	warnings := shell.AliasWarnings(command, m.shellCtx.Aliases)
+	warnings = append(warnings, shell.RuntimeWarnings(command, m.shellCtx.Runtimes)...)
+	if m.remoteTarget() {
+		// The paths and date are on the target
+		return warnings
+	}
+	warnings = append(warnings, shell.DateWarnings(command, m.shellCtx.OS)...)
+	dir := m.workDir
+	if dir == "" {
+		dir = m.shellCtx.CWD
+	}
+	for _, path := range shell.MissingPaths(command, dir) {
+		warnings = append(warnings, path.Warning())
+	}
+	return warnings
+}
@@ -0,0 +1,85 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/bastio-ai/bast/internal/ai"
+	"github.com/bastio-ai/bast/internal/shell"
+)
+
+func TestCandidatesKeepTheirOwnWorkDir(t *testing.T) {
+	cwd := t.TempDir()
+	web := filepath.Join(cwd, "web")
+	if err := os.Mkdir(web, 0755); err != nil {
+		t.Fatal(err)
+	}
+	m := confirmModel("")
+	m.shellCtx.CWD = cwd
+
+	// The first candidate is dangerous, so the safer one is ranked first
+	next, _ := m.update(CommandGeneratedMsg{
+		Query: "clean the build",
+		Result: &ai.CommandResult{
+			Command:      "rm -rf /",
+			WorkingDir:   "web",
+			Alternatives: []ai.Candidate{{Command: "npm run clean", WorkingDir: "web"}, {Command: "make clean"}},
+		},
+	})
+	m = next.(Model)
+	if m.command != "npm run clean" || m.workDir != web {
+		t.Fatalf("command = %q in %q, want npm run clean in %q", m.command, m.workDir, web)
+	}
+
+	next, _ = m.handleConfirmModeKey(tea.KeyMsg{Type: tea.KeyTab})
+	m = next.(Model)
+	if m.command != "make clean" || m.workDir != "" {
+		t.Errorf("command = %q in %q, want make clean where bast is", m.command, m.workDir)
+	}
+
+	next, _ = m.handleConfirmModeKey(tea.KeyMsg{Type: tea.KeyTab})
+	m = next.(Model)
+	if m.command != "rm -rf /" || m.workDir != web {
+		t.Errorf("command = %q in %q, want rm -rf / in %q", m.command, m.workDir, web)
+	}
+}
+
+func TestNewCandidateMissingDirStaysInCommand(t *testing.T) {
+	m := confirmModel("")
+	m.shellCtx.CWD = t.TempDir()
+
+	c := m.newCandidate("npm test", "no-such-dir")
+	if c.workDir != "" || c.command == "npm test" {
+		t.Errorf("candidate = %+v, want the cd kept in the command", c)
+	}
+}
+
+func TestHandoffWorkDir(t *testing.T) {
+	m := confirmModel("make test")
+	m.launchDir = "/src"
+	m.shellCtx.CWD = "/src"
+	m.workDir = "/src/api"
+
+	// A v2 hook changes directory itself, so the command is left alone
+	m.handoffVersion = shell.HandoffV2
+	h := m.handoff()
+	if h.Command != "make test" || h.WorkDir != "/src/api" {
+		t.Errorf("v2 handoff = %q in %q, want make test in /src/api", h.Command, h.WorkDir)
+	}
+
+	// A v1 line can only carry the directory in the command
+	m.handoffVersion = shell.HandoffV1
+	if h := m.handoff(); h.Command != "(cd /src/api && make test)" {
+		t.Errorf("v1 handoff command = %q, want the cd in a subshell", h.Command)
+	}
+
+	// Running where the shell already is needs neither
+	m.workDir = ""
+	m.handoffVersion = shell.HandoffV2
+	if h := m.handoff(); h.Command != "make test" || h.WorkDir != "" {
+		t.Errorf("handoff = %q in %q, want make test with no working_dir", h.Command, h.WorkDir)
+	}
+}
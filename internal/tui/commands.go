@@ -4,79 +4,83 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/google/uuid"
 
 	"github.com/bastio-ai/bast/internal/ai"
 	"github.com/bastio-ai/bast/internal/auth"
 	"github.com/bastio-ai/bast/internal/config"
+	"github.com/bastio-ai/bast/internal/debug"
 	"github.com/bastio-ai/bast/internal/files"
+	"github.com/bastio-ai/bast/internal/lifecycle"
 	"github.com/bastio-ai/bast/internal/safety"
 	"github.com/bastio-ai/bast/internal/shell"
 	"github.com/bastio-ai/bast/internal/tools"
+	"github.com/bastio-ai/bast/internal/transcript"
 )
 
+// prewarmTimeout bounds the background connection prewarm
+const prewarmTimeout = 5 * time.Second
+
 // classifyIntent returns a command that classifies the user's intent
 func (m Model) classifyIntent(query string) tea.Cmd {
+	overrides := m.intentOverrides
+	forced := m.forcedIntent
+	threshold := m.intentThreshold
 	return func() tea.Msg {
+		// A mode forced at launch skips classification entirely
+		if forced != "" {
+			return IntentClassifiedMsg{
+				Result: &ai.IntentResult{Intent: forced, Confidence: 1.0, Reasoning: "mode forced at launch"},
+				Query:  query,
+			}
+		}
+
 		cleanQuery := files.StripMentions(query)
 		result, err := m.provider.ClassifyIntent(context.Background(), cleanQuery)
 		if err != nil {
 			return ErrorMsg{Err: err}
 		}
+
+		// When the classifier is unsure, use the user's choice for similar
+		// queries instead of asking again. A confident result wins, so one
+		// remembered choice can't misroute everything that starts alike.
+		if result.Confidence < threshold {
+			if intent, ok := overrides.Lookup(query); ok {
+				result = &ai.IntentResult{Intent: intent, Confidence: 1.0, Reasoning: "remembered user choice", NeedsHistory: result.NeedsHistory}
+			}
+		}
 		return IntentClassifiedMsg{Result: result, Query: query}
 	}
 }
 
 // chat returns a command that generates a chat response
 func (m Model) chat(query string, intentResult *ai.IntentResult) tea.Cmd {
-	shellCtx := m.shellCtx
+	lazyCtx := m.lazyCtx
+	pinned := m.pinned
 	conversationHistory := m.conversationHistory
+	injectionAck := m.injectionAck
+	probes := m.probesEnabled()
 	return func() tea.Msg {
+		shellCtx := withPinned(lazyCtx.Get(), pinned)
+
 		// Use history context if auto-detected from intent classification
 		var ctx ai.ShellContext
 		if intentResult != nil && intentResult.NeedsHistory {
-			ctx = shell.GetContextWithHistory()
+			ctx = shell.AddHistoryContext(shellCtx)
 		} else {
 			ctx = shellCtx
 		}
+		ctx = withProbes(ctx, probes, query)
 
-		// Parse explicit @file mentions
-		mentions := files.ParseMentions(query)
-
-		// Detect implicit file references (e.g., "the readme")
-		refs := files.DetectFileReferences(query)
-
-		// Collect all unique file paths
-		seen := make(map[string]bool)
-		var paths []string
-
-		// Add explicit mentions first
-		for _, mention := range mentions {
-			if !seen[mention] {
-				seen[mention] = true
-				paths = append(paths, mention)
-			}
+		fileContents := chatFiles(shellCtx, query)
+		if findings := injectionFindings(fileContents); len(findings) > 0 && !injectionAck {
+			return InjectionFlaggedMsg{Query: query, Intent: intentResult, Findings: findings}
 		}
 
-		// Add detected references (resolve to actual files)
-		for _, ref := range refs {
-			if seen[ref] {
-				continue
-			}
-			// Try to find the actual file
-			if path, err := files.FindFile(shellCtx.CWD, ref); err == nil {
-				if !seen[path] {
-					seen[path] = true
-					paths = append(paths, path)
-				}
-			}
-		}
-
-		// Read files (max 100KB total)
-		fileContents := files.ReadFiles(shellCtx.CWD, paths, files.MaxTotalFileBytes)
-
 		chatCtx := ai.ChatContext{
 			Files:   fileContents,
 			History: conversationHistory,
@@ -91,24 +95,72 @@ func (m Model) chat(query string, intentResult *ai.IntentResult) tea.Cmd {
 	}
 }
 
+// chatFiles reads the files a chat query mentions or implies, plus files
+// from tests that failed in the last command
+func chatFiles(shellCtx ai.ShellContext, query string) []files.FileContent {
+	// Explicit @mentions and implicit references (e.g., "the readme")
+	paths := files.ReferencedPaths(shellCtx.CWD, query)
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		seen[path] = true
+	}
+
+	// Attach files from tests that failed in the last command
+	for _, path := range failingTestFiles(shellCtx) {
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+
+	// Read files (max 100KB total)
+	return files.ReadFiles(shellCtx.CWD, paths, files.MaxTotalFileBytes)
+}
+
 // generateCommand returns a command that generates a shell command
 func (m Model) generateCommand(query string) tea.Cmd {
-	shellCtx := m.shellCtx
+	lazyCtx := m.lazyCtx
+	pinned := m.pinned
 	return func() tea.Msg {
+		shellCtx := withPinned(lazyCtx.Get(), pinned)
 		cleanQuery := files.StripMentions(query)
+		shellCtx.Examples = feedbackExamples(cleanQuery, shellCtx.CWD)
 		result, err := m.provider.GenerateCommand(context.Background(), cleanQuery, shellCtx)
 		if err != nil {
 			return ErrorMsg{Err: err}
 		}
-		return CommandGeneratedMsg{Result: result}
+		result, syntaxErr := m.correctSyntax(cleanQuery, shellCtx, result)
+		return CommandGeneratedMsg{Result: result, Query: cleanQuery, Context: ai.PreviewCommand(cleanQuery, shellCtx), SyntaxErr: syntaxErr}
+	}
+}
+
+// correctSyntax checks that a generated command parses in the user's shell,
+// asking once for a corrected command when it doesn't. It returns the error
+// for the command it keeps, if that still doesn't parse.
+func (m Model) correctSyntax(query string, shellCtx ai.ShellContext, result *ai.CommandResult) (*ai.CommandResult, error) {
+	syntaxErr := shell.CheckSyntax(shellCtx.Shell, result.Command)
+	if syntaxErr == nil {
+		return result, nil
+	}
+	retry := fmt.Sprintf("%s\n\nThis command you generated %s. Reply with a corrected command:\n%s", query, syntaxErr, result.Command)
+	fixed, err := m.provider.GenerateCommand(context.Background(), retry, shellCtx)
+	if err != nil {
+		return result, syntaxErr
 	}
+	if err := shell.CheckSyntax(shellCtx.Shell, fixed.Command); err != nil {
+		return result, syntaxErr
+	}
+	return fixed, nil
 }
 
 // chatAboutCommand returns a command that generates a chat response about a specific command
 func (m Model) chatAboutCommand(query string, command string) tea.Cmd {
-	shellCtx := m.shellCtx
+	lazyCtx := m.lazyCtx
+	pinned := m.pinned
 	conversationHistory := m.conversationHistory
 	return func() tea.Msg {
+		shellCtx := withPinned(lazyCtx.Get(), pinned)
+
 		// Add context about the generated command to conversation
 		historyWithCommand := append(conversationHistory,
 			ai.ConversationMessage{Role: "assistant", Content: fmt.Sprintf("I generated this command: %s", command)},
@@ -137,6 +189,62 @@ func (m Model) explainCommand(command string) tea.Cmd {
 	}
 }
 
+// compareCommands returns a command that explains how after differs from
+// before, leading with the risk changes the local checks found
+func (m Model) compareCommands(before, after string) tea.Cmd {
+	return func() tea.Msg {
+		risk := safety.RiskChanges(before, after)
+		explanation, err := m.provider.CompareCommands(context.Background(), before, after, risk)
+		if err != nil {
+			return ErrorMsg{Err: err}
+		}
+		if len(risk) > 0 {
+			explanation = fmt.Sprintf("Risk: %s\n\n%s", strings.Join(risk, "; "), explanation)
+		}
+		return CommandExplainedMsg{Explanation: explanation}
+	}
+}
+
+// originalCommand returns the command the pending one was derived from: the
+// one the user edited, or the generated one before a rewrite, translation,
+// or install step. It returns "" when the pending command is unchanged.
+func (m Model) originalCommand() string {
+	if m.editedFrom != "" && m.editedFrom != m.command {
+		return m.editedFrom
+	}
+	if len(m.candidates) > 0 && m.candidates[m.candidateIndex].command != m.command {
+		return m.candidates[m.candidateIndex].command
+	}
+	return ""
+}
+
+// prewarmProvider returns a command that opens the provider connection in the
+// background, or nil when prewarming is off or unsupported
+func (m Model) prewarmProvider() tea.Cmd {
+	if m.prewarm != config.PrewarmConnect && m.prewarm != config.PrewarmPing {
+		return nil
+	}
+	prewarmer, ok := m.provider.(ai.Prewarmer)
+	if !ok {
+		return nil
+	}
+	ping := m.prewarm == config.PrewarmPing
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), prewarmTimeout)
+		defer cancel()
+		// Best-effort: a failure here only means the first request pays for setup
+		if err := prewarmer.Prewarm(ctx, ping); err != nil {
+			debug.Logf("prewarm failed: %v", err)
+		}
+		return nil
+	}
+}
+
+// applySudoPolicy strips sudo from command or rejects it, per the configured policy
+func (m Model) applySudoPolicy(command string) (string, error) {
+	return safety.ApplySudoPolicy(command, m.sudoPolicy)
+}
+
 // isDangerousCommand checks if a command matches any dangerous patterns
 func isDangerousCommand(command string) bool {
 	return safety.IsDangerousCommand(command)
@@ -149,6 +257,9 @@ func (m Model) selectModel(modelID string) (tea.Model, tea.Cmd) {
 		if err != nil {
 			return ErrorMsg{Err: err}
 		}
+		if err := cfg.CheckModel(modelID); err != nil {
+			return ErrorMsg{Err: err}
+		}
 		cfg.Model = modelID
 		if err := config.Save(cfg); err != nil {
 			return ErrorMsg{Err: err}
@@ -157,12 +268,35 @@ func (m Model) selectModel(modelID string) (tea.Model, tea.Cmd) {
 	}
 }
 
+// allowedModelOptions returns the models offered by /model. When the system
+// config restricts models, only those are offered.
+func allowedModelOptions(cfg *config.Config) []ai.ModelOption {
+	options := ai.GetModelsForProvider(cfg.Provider)
+	if len(cfg.Managed.AllowedModels) == 0 {
+		return options
+	}
+	var allowed []ai.ModelOption
+	for _, id := range cfg.Managed.AllowedModels {
+		option := ai.ModelOption{ID: id, Name: id}
+		for _, known := range options {
+			if known.ID == id {
+				option = known
+			}
+		}
+		allowed = append(allowed, option)
+	}
+	return allowed
+}
+
 // fixCommand returns a command that analyzes and fixes a failed command
 func (m Model) fixCommand() tea.Cmd {
-	shellCtx := m.shellCtx
+	lazyCtx := m.lazyCtx
+	pinned := m.pinned
 	return func() tea.Msg {
-		// Get context with history to access last command and error
-		ctx := shell.GetContextWithHistory()
+		shellCtx := withPinned(lazyCtx.Get(), pinned)
+
+		// Add history to access last command and error
+		ctx := shell.AddHistoryContext(shellCtx)
 
 		failedCmd := ctx.LastCommand
 		errorOutput := ctx.LastError
@@ -174,99 +308,251 @@ func (m Model) fixCommand() tea.Cmd {
 			return ErrorMsg{Err: fmt.Errorf("no failed command found. Run a command first, then use /fix")}
 		}
 
-		result, err := m.provider.FixCommand(context.Background(), failedCmd, errorOutput, shellCtx)
+		frames := files.ReadStackFrames(shellCtx.CWD, files.ParseStackTrace(errorOutput))
+		result, err := m.provider.FixCommand(context.Background(), failedCmd, errorOutput, frames, shellCtx)
 		if err != nil {
 			return ErrorMsg{Err: err}
 		}
-		return FixResultMsg{Result: result, FailedCmd: failedCmd}
+		var exitMeaning string
+		if ctx.ExitStatus != 0 {
+			if desc, ok := shell.DescribeExitCode(ctx.ExitStatus, failedCmd); ok {
+				exitMeaning = fmt.Sprintf("Exit %d: %s", ctx.ExitStatus, desc)
+			}
+		}
+		return FixResultMsg{Result: result, FailedCmd: failedCmd, ExitMeaning: exitMeaning}
+	}
+}
+
+// confirmOverChannel returns a warn-confirm hook that asks the TUI through prompts
+func confirmOverChannel(prompts chan SecurityConfirmMsg) tools.WarnConfirmFunc {
+	return func(ctx context.Context, call tools.Call, result *tools.ValidationResult) bool {
+		reply := make(chan bool, 1)
+		prompts <- SecurityConfirmMsg{
+			Call:    call,
+			Message: result.Message,
+			Threats: result.ThreatsDetected,
+			reply:   reply,
+			prompts: prompts,
+		}
+		select {
+		case ok := <-reply:
+			return ok
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// waitForSecurityPrompt returns a command that waits for the next confirmation
+// request from a running agent. Returns nil once the agent has finished.
+func waitForSecurityPrompt(prompts chan SecurityConfirmMsg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-prompts
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+// waitForAgentUpdate returns a command that waits for the next tool call or
+// progress update from a running agent. Returns nil once the agent has
+// finished.
+func waitForAgentUpdate(updates chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-updates
+		if !ok {
+			return nil
+		}
+		return msg
 	}
 }
 
 // runAgent returns a command that runs an agentic task with tool use
-func (m Model) runAgent(query string, sendUpdates func(tea.Msg)) tea.Cmd {
-	shellCtx := m.shellCtx
+func (m Model) runAgent(query string) tea.Cmd {
+	lazyCtx := m.lazyCtx
+	pinned := m.pinned
 	conversationHistory := m.conversationHistory
-	return func() tea.Msg {
-		// Create tool registry with built-in tools
-		registry := tools.NewRegistry()
-		cwd, _ := os.Getwd()
-		tools.RegisterBuiltins(registry, cwd)
+	injectionAck := m.injectionAck
+	promptOutputLines := m.promptOutputLines
+	sessionEnv := m.sessionEnv
+	sessionID := m.sessionID
+	executor := m.executor
+	saveTranscript := m.saveSession
+	probes := m.probesEnabled()
+	snapshot := transcript.Config{
+		Model:         m.currentModel,
+		Gateway:       m.gateway,
+		SafetyMode:    m.safetyMode,
+		MaxIterations: agentMaxIterations,
+	}
+	if m.remoteTarget() {
+		snapshot.Target = executor.Name()
+	}
 
-		// Load default plugins (shipped with bast)
-		if err := tools.RegisterDefaultPlugins(registry, cwd); err != nil {
-			// Log warning but continue
-			fmt.Fprintf(os.Stderr, "Warning: failed to load default plugins: %v\n", err)
+	var securityCfg config.SecurityConfig
+	var hooksCfg config.HooksConfig
+	userPlugins := true
+	if m.isolated {
+		securityCfg.Validators = []config.ValidatorConfig{{Name: "local"}}
+		userPlugins = false
+		saveTranscript = false
+	} else if cfg, err := config.Load(); err == nil {
+		if err := cfg.CheckFeature(config.FeatureAgent); err != nil {
+			return func() tea.Msg { return ErrorMsg{Err: err} }
+		}
+		securityCfg = cfg.Security
+		hooksCfg = cfg.Hooks
+		for _, v := range cfg.Security.Validators {
+			snapshot.Validators = append(snapshot.Validators, v.Name)
 		}
+		userPlugins = cfg.FeatureEnabled(config.FeaturePlugins)
+	}
+
+	// Warned tool calls are confirmed by the user through this channel
+	var prompts chan SecurityConfirmMsg
+	if securityCfg.ConfirmWarnings {
+		prompts = make(chan SecurityConfirmMsg)
+	}
+
+	// Tool calls and the output of running tools stream back through this
+	// channel while the agent works
+	updates := make(chan tea.Msg, 16)
 
-		// Load user plugins (can override defaults)
-		if err := tools.RegisterUserPlugins(registry); err != nil {
-			// Log warning but continue
-			fmt.Fprintf(os.Stderr, "Warning: failed to load user plugins: %v\n", err)
+	agent := func() tea.Msg {
+		defer close(updates)
+		if prompts != nil {
+			defer close(prompts)
 		}
+		shellCtx := withProbes(withPinned(lazyCtx.Get(), pinned), probes, query)
 
-		// Configure Bastio Agent Security if credentials are available
-		if securityCfg := auth.GetBastioSecurityConfig(); securityCfg != nil {
-			// Generate a new session ID for this agent invocation
-			sessionID := uuid.New().String()
+		// Create tool registry with built-in tools and plugins
+		registry := tools.NewRegistry()
+		cwd, _ := os.Getwd()
+		tools.RegisterAgentTools(registry, cwd, userPlugins)
+
+		// Keep credentials in the environment away from spawned commands,
+		// and apply the session's /env overrides
+		registry.SetEnvPolicy(tools.EnvPolicy{
+			Mode:  securityCfg.Env.Mode,
+			Deny:  securityCfg.Env.Deny,
+			Allow: securityCfg.Env.Allow,
+			Set:   sessionEnv,
+		})
+		if executor != nil {
+			registry.SetExecutor(executor)
+		}
 
-			securityClient := tools.NewBastioSecurityClient(
-				securityCfg.BaseURL,
-				securityCfg.ProxyID,
-				securityCfg.APIKey,
-				sessionID,
-			)
-			registry.SetSecurityClient(securityClient)
+		// Guard tool calls with the configured validator chain
+		validator, err := auth.NewSessionSecurityValidator(securityCfg, sessionID)
+		if err != nil {
+			return ErrorMsg{Err: err}
+		}
+		registry.SetSecurityClient(validator)
+		if prompts != nil {
+			registry.SetWarnConfirm(confirmOverChannel(prompts))
 		}
 
-		// Parse file mentions
-		mentions := files.ParseMentions(query)
-		refs := files.DetectFileReferences(query)
+		// Run the user's lifecycle hooks around the task and each tool call
+		hooks := lifecycle.New(hooksCfg, sessionID, shellCtx.CWD)
+		if hooks != nil {
+			registry.SetCallHook(hooks)
+		}
 
+		// Explicit @mentions and implicit references (e.g., "the readme")
+		paths := files.ReferencedPaths(shellCtx.CWD, query)
 		seen := make(map[string]bool)
-		var paths []string
-		for _, mention := range mentions {
-			if !seen[mention] {
-				seen[mention] = true
-				paths = append(paths, mention)
-			}
+		for _, path := range paths {
+			seen[path] = true
 		}
-		for _, ref := range refs {
-			if seen[ref] {
-				continue
-			}
-			if path, err := files.FindFile(shellCtx.CWD, ref); err == nil {
-				if !seen[path] {
-					seen[path] = true
-					paths = append(paths, path)
-				}
+
+		// Attach files from tests that failed in the last command
+		for _, path := range failingTestFiles(shellCtx) {
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
 			}
 		}
 
 		fileContents := files.ReadFiles(shellCtx.CWD, paths, files.MaxTotalFileBytes)
+		if findings := injectionFindings(fileContents); len(findings) > 0 && !injectionAck {
+			return InjectionFlaggedMsg{Query: query, Intent: &ai.IntentResult{Intent: ai.IntentAgent}, Findings: findings}
+		}
 
 		chatCtx := ai.ChatContext{
 			Files:   fileContents,
 			History: conversationHistory,
 		}
 
-		// Callback to send tool call updates to the TUI
+		// Completed calls are always delivered; progress is dropped when the
+		// TUI falls behind since a newer tail will follow
 		onToolCall := func(call ai.ToolCall) {
-			if sendUpdates != nil {
-				sendUpdates(ToolCallMsg{Call: call})
+			updates <- ToolCallMsg{Call: call, updates: updates}
+		}
+		onToolProgress := func(call ai.ToolCall, tail []string) {
+			select {
+			case updates <- ToolProgressMsg{Call: call, Tail: tail, updates: updates}:
+			default:
 			}
 		}
 
 		agentCfg := ai.AgentConfig{
-			MaxIterations: 10,
-			Registry:      registry,
-			OnToolCall:    onToolCall,
+			MaxIterations:     agentMaxIterations,
+			Registry:          registry,
+			OnToolCall:        onToolCall,
+			OnToolProgress:    onToolProgress,
+			PromptOutputLines: promptOutputLines,
 		}
 
+		ctx := context.Background()
 		cleanQuery := files.StripMentions(query)
-		result, err := m.provider.RunAgent(context.Background(), cleanQuery, shellCtx, chatCtx, agentCfg)
+
+		var result *ai.AgentResult
+
+		// Write the run to the session's transcript as it happens
+		if saveTranscript {
+			snapshot.CWD = shellCtx.CWD
+			for _, tool := range registry.List() {
+				snapshot.Tools = append(snapshot.Tools, tool.Name())
+			}
+			sort.Strings(snapshot.Tools)
+			if w := openTranscript(sessionID, cleanQuery, snapshot); w != nil {
+				agentCfg.OnToolCall = func(call ai.ToolCall) {
+					logTranscriptErr(w.ToolCall(call))
+					onToolCall(call)
+				}
+				agentCfg.OnStep = func(step ai.AgentStep) { logTranscriptErr(w.Step(step)) }
+				defer func() { logTranscriptErr(w.End(result, err)) }()
+			}
+		}
+
+		hooks.SessionStart(ctx, cleanQuery)
+		result, err = m.provider.RunAgent(ctx, cleanQuery, shellCtx, chatCtx, agentCfg)
 		if err != nil {
+			hooks.SessionEnd(ctx, cleanQuery, lifecycle.Outcome{Error: err.Error()})
 			return ErrorMsg{Err: err}
 		}
+		hooks.SessionEnd(ctx, cleanQuery, lifecycle.Outcome{
+			Status:     string(result.Status),
+			Summary:    result.Summary,
+			ToolCalls:  len(result.ToolCalls),
+			Iterations: result.Iterations,
+		})
 		return AgentResponseMsg{Result: result, Query: query}
 	}
+
+	if prompts == nil {
+		return tea.Batch(agent, waitForAgentUpdate(updates))
+	}
+	return tea.Batch(agent, waitForAgentUpdate(updates), waitForSecurityPrompt(prompts))
+}
+
+// failingTestFiles returns the files of tests that failed in the last
+// command, so "fix the failing test" works without mentioning them
+func failingTestFiles(shellCtx ai.ShellContext) []string {
+	if shellCtx.ExitStatus == 0 {
+		return nil
+	}
+	stdout, stderr := shell.LastOutput()
+	return files.FailingTestFiles(shellCtx.CWD, stdout+"\n"+stderr)
 }
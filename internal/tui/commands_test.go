@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/bastio-ai/bast/internal/ai"
+	"github.com/bastio-ai/bast/internal/tools"
+)
+
+// classifyProvider answers ClassifyIntent with a fixed result
+type classifyProvider struct {
+	ai.Provider
+	result ai.IntentResult
+}
+
+func (p classifyProvider) ClassifyIntent(ctx context.Context, query string) (*ai.IntentResult, error) {
+	result := p.result
+	return &result, nil
+}
+
+func TestClassifyIntentRemembersOnlyWhenUnsure(t *testing.T) {
+	overrides := ai.LoadIntentOverrides(filepath.Join(t.TempDir(), ai.IntentOverridesFileName))
+	if err := overrides.Remember("how do I list files", ai.IntentChat); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		confidence float64
+		want       ai.Intent
+	}{
+		{"confident classifier wins", 0.9, ai.IntentCommand},
+		{"unsure classifier uses the choice", 0.4, ai.IntentChat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Model{
+				provider:        classifyProvider{result: ai.IntentResult{Intent: ai.IntentCommand, Confidence: tt.confidence}},
+				intentOverrides: overrides,
+				intentThreshold: 0.6,
+			}
+			msg, ok := m.classifyIntent("how do you list files here")().(IntentClassifiedMsg)
+			if !ok {
+				t.Fatal("classifyIntent() did not return IntentClassifiedMsg")
+			}
+			if msg.Result.Intent != tt.want {
+				t.Errorf("Intent = %q, want %q", msg.Result.Intent, tt.want)
+			}
+		})
+	}
+}
+
+// agentProvider runs one list_directory call through the agent's registry
+type agentProvider struct {
+	ai.Provider
+	result *tools.CallResult
+}
+
+func (p *agentProvider) RunAgent(ctx context.Context, query string, shellCtx ai.ShellContext, chatCtx ai.ChatContext, cfg ai.AgentConfig) (*ai.AgentResult, error) {
+	input, _ := json.Marshal(map[string]string{"path": "."})
+	result := cfg.Registry.ExecuteCall(ctx, tools.Call{ID: "1", Name: "list_directory", Input: input})
+	p.result = &result
+	return &ai.AgentResult{Response: "done"}, nil
+}
+
+func TestIsolatedAgentIgnoresUserConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	marker := filepath.Join(home, "hook-ran")
+	configDir := filepath.Join(home, ".config", "bast")
+	os.MkdirAll(configDir, 0755)
+	// Without isolation this config blocks every call (bastio is required
+	// but not logged in) and runs a hook
+	os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(`version: 1
+security:
+  validators:
+    - name: bastio
+      fail_mode: closed
+hooks:
+  on_session_start: ["touch `+marker+`"]
+`), 0644)
+
+	lazyCtx := newLazyShellContext(ai.ShellContext{CWD: t.TempDir()})
+	close(lazyCtx.ready)
+	lazyCtx.full = lazyCtx.base
+
+	provider := &agentProvider{}
+	m := Model{provider: provider, lazyCtx: lazyCtx, sessionID: "test"}
+	m.SetIsolated(true)
+
+	batch, ok := m.runAgent("list files")().(tea.BatchMsg)
+	if !ok || len(batch) == 0 {
+		t.Fatal("runAgent() did not return a batch")
+	}
+	if msg, ok := batch[0]().(AgentResponseMsg); !ok {
+		t.Fatalf("agent returned %T, want AgentResponseMsg", msg)
+	}
+	if provider.result == nil || provider.result.IsError {
+		t.Errorf("tool call = %+v, want it allowed by the local validator", provider.result)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Error("the user's session start hook ran in an isolated model")
+	}
+}
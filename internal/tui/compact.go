@@ -0,0 +1,120 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/bastio-ai/bast/internal/ai"
+)
+
+const (
+	// contextWarnPercent is the context use at which the meter suggests /compact
+	contextWarnPercent = 80
+
+	// compactKeep is how many of the latest messages /compact keeps verbatim
+	compactKeep = 2
+
+	// contextMeterWidth is the number of cells in the meter bar
+	contextMeterWidth = 10
+
+	compactPrompt = "Summarize our conversation so far for your own future reference. " +
+		"Keep every fact, decision, file name, command, and open question that later " +
+		"messages may rely on. Reply with the summary only."
+)
+
+// contextTokens estimates the prompt size the next chat or agent request will use
+func (m Model) contextTokens() int {
+	var extra []string
+	if m.mode == ModeAgent {
+		toolCalls := m.agentToolCalls
+		if m.agentResult != nil {
+			toolCalls = m.agentResult.ToolCalls
+		}
+		for _, call := range toolCalls {
+			extra = append(extra, string(call.Input), call.Output)
+		}
+	}
+	return ai.EstimateRequestTokens(m.conversationHistory, m.pinned, extra...)
+}
+
+// renderContextMeter renders how much of the context window the conversation
+// uses, suggesting /compact when it is nearly full
+func (m Model) renderContextMeter(contentWidth int) string {
+	tokens := m.contextTokens()
+	percent := tokens * 100 / ai.ContextWindowTokens
+	filled := min(contextMeterWidth, (percent*contextMeterWidth+50)/100)
+	bar := strings.Repeat("▰", filled) + strings.Repeat("▱", contextMeterWidth-filled)
+	meter := fmt.Sprintf("Context %s %d%% (~%s of %s tokens)",
+		bar, percent, formatTokens(tokens), formatTokens(ai.ContextWindowTokens))
+
+	width := lipgloss.NewStyle().Width(contentWidth)
+	if percent < contextWarnPercent {
+		return width.Render(HelpStyle.Render(meter))
+	}
+	return width.Render(WarningStyle.Render(meter + " • /compact to summarize older messages"))
+}
+
+// formatTokens renders a token count compactly (e.g. "850", "12k")
+func formatTokens(n int) string {
+	if n < 1000 {
+		return fmt.Sprint(n)
+	}
+	return fmt.Sprintf("%dk", (n+500)/1000)
+}
+
+// compact replaces all but the latest exchange of the conversation with a
+// summary written by the model
+func (m Model) compact() (tea.Model, tea.Cmd) {
+	if len(m.conversationHistory) <= compactKeep {
+		m.err = fmt.Errorf("nothing to compact yet")
+		return m, nil
+	}
+	older := m.conversationHistory[:len(m.conversationHistory)-compactKeep]
+	returnMode := m.mode
+	if returnMode != ModeAgent {
+		returnMode = ModeChat
+	}
+
+	m.mode = ModeLoading
+	m.loadingMessage = "Summarizing conversation..."
+	m.textInput.SetValue("")
+	m.err = nil
+
+	lazyCtx := m.lazyCtx
+	pinned := m.pinned
+	summarize := func() tea.Msg {
+		shellCtx := withPinned(lazyCtx.Get(), pinned)
+		result, err := m.provider.Chat(context.Background(), compactPrompt, shellCtx, ai.ChatContext{History: older})
+		if err != nil {
+			return ErrorMsg{Err: err}
+		}
+		return CompactedMsg{Summary: result.Response, Replaced: len(older), Mode: returnMode}
+	}
+	return m, tea.Batch(m.spinner.Tick, summarize)
+}
+
+// applyCompaction swaps the summarized messages for the summary
+func (m Model) applyCompaction(msg CompactedMsg) Model {
+	history := []ai.ConversationMessage{
+		{Role: "user", Content: "Summarize our conversation so far."},
+		{Role: "assistant", Content: msg.Summary},
+	}
+	m.conversationHistory = append(history, m.conversationHistory[msg.Replaced:]...)
+	m.mode = msg.Mode
+	m.editing = false
+	m.editSelecting = false
+	m.textInput.Focus()
+	if m.viewportReady {
+		if m.mode == ModeAgent {
+			m.chatViewport.SetContent(m.renderAgentContent())
+		} else {
+			m.chatViewport.SetContent(m.renderConversationContent())
+		}
+		m.chatViewport.GotoBottom()
+	}
+	return m
+}
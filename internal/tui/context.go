@@ -0,0 +1,41 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/bastio-ai/bast/internal/ai"
+	"github.com/bastio-ai/bast/internal/shell"
+)
+
+// lazyShellContext gathers the slow parts of the shell context (git, direnv,
+// aliases, tool versions) off the startup path. Commands that need the full
+// context wait for it to finish, and add history on top with
+// shell.AddHistoryContext rather than gathering everything again.
+type lazyShellContext struct {
+	base  ai.ShellContext
+	full  ai.ShellContext
+	ready chan struct{}
+}
+
+// newLazyShellContext creates a loader seeded with the cheap base context
+func newLazyShellContext(base ai.ShellContext) *lazyShellContext {
+	return &lazyShellContext{
+		base:  base,
+		ready: make(chan struct{}),
+	}
+}
+
+// load returns a command that gathers the full context and reports it to the model
+func (l *lazyShellContext) load() tea.Cmd {
+	return func() tea.Msg {
+		l.full = shell.AddProjectContext(l.base)
+		close(l.ready)
+		return ShellContextLoadedMsg{Context: l.full}
+	}
+}
+
+// Get blocks until the full context is available
+func (l *lazyShellContext) Get() ai.ShellContext {
+	<-l.ready
+	return l.full
+}
@@ -0,0 +1,121 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/bastio-ai/bast/internal/files"
+	"github.com/bastio-ai/bast/internal/shell"
+)
+
+// maxOpenRefs caps how many files the open picker lists
+const maxOpenRefs = 20
+
+// references returns the files the current conversation points at, most
+// relevant first: citations and paths in the latest answer, files the agent
+// touched, then @mentions in the latest question
+func (m Model) references() []fileRef {
+	var refs []fileRef
+	seen := make(map[string]bool)
+	add := func(ref fileRef) {
+		key := ref.path + ":" + strconv.Itoa(ref.line)
+		if seen[key] || len(refs) == maxOpenRefs {
+			return
+		}
+		seen[key] = true
+		refs = append(refs, ref)
+	}
+
+	var answer, question string
+	for i := len(m.conversationHistory) - 1; i >= 0; i-- {
+		msg := m.conversationHistory[i]
+		if msg.Role == "user" && question == "" {
+			question = msg.Content
+		} else if msg.Role != "user" && answer == "" {
+			answer = msg.Content
+		}
+	}
+	if m.mode == ModeAgent && m.agentResult != nil {
+		answer = m.agentResult.Response
+	}
+
+	for _, c := range files.ParseCitations(answer) {
+		if path, ok := m.existingFile(c.Path); ok {
+			add(fileRef{raw: c.Raw, path: path, line: c.Start})
+		}
+	}
+	for _, ref := range m.fileRefs(answer) {
+		add(ref)
+	}
+
+	toolCalls := m.agentToolCalls
+	if m.agentResult != nil {
+		toolCalls = m.agentResult.ToolCalls
+	}
+	for _, call := range toolCalls {
+		var input struct {
+			Path string `json:"path"`
+		}
+		if json.Unmarshal(call.Input, &input) != nil || input.Path == "" {
+			continue
+		}
+		if path, ok := m.existingFile(input.Path); ok {
+			add(fileRef{raw: input.Path, path: path})
+		}
+	}
+
+	for _, mention := range files.ParseMentions(question) {
+		if path, ok := m.existingFile(mention); ok {
+			add(fileRef{raw: "@" + mention, path: path})
+		}
+	}
+	return refs
+}
+
+// openReference opens the referenced file, or lets the user pick one when
+// the conversation references several
+func (m Model) openReference() (tea.Model, tea.Cmd) {
+	refs := m.references()
+	switch len(refs) {
+	case 0:
+		m.err = fmt.Errorf("no files referenced in this conversation to open")
+		return m, nil
+	case 1:
+		return m, m.openInEditor(refs[0])
+	}
+	m.openRefs = refs
+	m.openCursor = 0
+	m.openReturn = m.mode
+	m.mode = ModeOpenSelect
+	m.err = nil
+	return m, nil
+}
+
+// openInEditor suspends the TUI and opens ref in the configured editor,
+// $VISUAL, or $EDITOR
+func (m Model) openInEditor(ref fileRef) tea.Cmd {
+	args := shell.EditorCommand(shell.ResolveEditor(m.editor), ref.path, ref.line)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = m.shellCtx.CWD
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return EditorClosedMsg{Path: ref.path, Err: err}
+	})
+}
+
+// relativeRef formats ref for display relative to the working directory
+func (m Model) relativeRef(ref fileRef) string {
+	path := ref.path
+	if rel, err := filepath.Rel(m.shellCtx.CWD, path); err == nil && !strings.HasPrefix(rel, "..") {
+		path = rel
+	}
+	if ref.line > 0 {
+		path += ":" + strconv.Itoa(ref.line)
+	}
+	return path
+}
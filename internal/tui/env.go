@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bastio-ai/bast/internal/shell"
+)
+
+// envUsage explains the /env subcommands
+const envUsage = "usage: /env set NAME=value | /env unset [NAME]"
+
+// setEnv handles /env. "set NAME=value" overrides a variable for the rest
+// of the session, in handed-off commands and agent commands alike; "unset
+// NAME" drops one override, and "unset" alone drops them all.
+func (m Model) setEnv(arg string) (Model, error) {
+	sub, rest, _ := strings.Cut(strings.TrimSpace(arg), " ")
+	rest = strings.TrimSpace(rest)
+	switch sub {
+	case "set":
+		name, value, err := shell.ParseEnvAssignment(rest)
+		if err != nil {
+			return m, fmt.Errorf("%w; %s", err, envUsage)
+		}
+		env := withoutEnv(m.sessionEnv, name)
+		env = append(env, name+"="+value)
+		slices.Sort(env)
+		m.sessionEnv = env
+		return m, nil
+
+	case "unset":
+		if rest == "" {
+			m.sessionEnv = nil
+			return m, nil
+		}
+		env := withoutEnv(m.sessionEnv, rest)
+		if len(env) == len(m.sessionEnv) {
+			return m, fmt.Errorf("%s is not set for this session", rest)
+		}
+		m.sessionEnv = env
+		return m, nil
+	}
+	return m, fmt.Errorf(envUsage)
+}
+
+// withoutEnv returns the NAME=value entries of env other than name's
+func withoutEnv(env []string, name string) []string {
+	var kept []string
+	for _, entry := range env {
+		if !strings.HasPrefix(entry, name+"=") {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+// envNames lists the names of the session's variable overrides. Values
+// aren't shown, since they may be credentials.
+func envNames(env []string) []string {
+	names := make([]string, len(env))
+	for i, entry := range env {
+		names[i], _, _ = strings.Cut(entry, "=")
+	}
+	return names
+}
@@ -0,0 +1,117 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/bastio-ai/bast/internal/ai"
+	"github.com/bastio-ai/bast/internal/auth"
+	"github.com/bastio-ai/bast/internal/debug"
+	"github.com/bastio-ai/bast/internal/feedback"
+)
+
+// maxFeedbackExamples caps how many past generations are sent as examples
+const maxFeedbackExamples = 3
+
+// feedbackExamples returns past generations similar to query: commands
+// accepted in the project containing cwd and commands rated good
+func feedbackExamples(query, cwd string) []ai.CommandExample {
+	feedbackPath, err := feedback.DefaultPath()
+	if err != nil {
+		return nil
+	}
+	acceptedPath, err := feedback.DefaultAcceptedPath()
+	if err != nil {
+		return nil
+	}
+	project := feedback.ProjectRoot(cwd)
+	entries := feedback.Load(feedbackPath)
+	accepted := feedback.LoadAccepted(acceptedPath, project)
+
+	var examples []ai.CommandExample
+	for _, e := range feedback.Examples(entries, accepted, project, query, maxFeedbackExamples) {
+		examples = append(examples, ai.CommandExample{Query: e.Query, Command: e.Command})
+	}
+	return examples
+}
+
+// recordAccepted stores the command the user is about to run as an example
+// for similar queries in this project. Failures are not worth interrupting for.
+func (m Model) recordAccepted() {
+	if m.generatedQuery == "" || m.command == "" {
+		return
+	}
+	path, err := feedback.DefaultAcceptedPath()
+	if err != nil {
+		return
+	}
+	err = feedback.RecordAccepted(path, feedback.Accepted{
+		Time:    time.Now(),
+		Project: feedback.ProjectRoot(m.shellCtx.CWD),
+		Query:   m.generatedQuery,
+		Command: m.command,
+	})
+	if err != nil {
+		debug.Logf("failed to record accepted command: %v", err)
+	}
+}
+
+// rate records feedback on the most recent generation. When sharing is
+// enabled and Bastio credentials exist, the returned command sends it.
+func (m Model) rate(rating feedback.Rating, comment string) (Model, tea.Cmd, error) {
+	command := m.command
+	if command == "" {
+		command = m.generatedCommand
+	}
+	if command == "" {
+		return m, nil, fmt.Errorf("no generated command to give feedback on yet")
+	}
+	if rating == feedback.RatingNote && comment == "" {
+		return m, nil, fmt.Errorf("usage: /feedback <what was wrong or right>")
+	}
+
+	path, err := feedback.DefaultPath()
+	if err != nil {
+		return m, nil, err
+	}
+	entry := feedback.Entry{
+		Time:    time.Now(),
+		Query:   m.generatedQuery,
+		Command: command,
+		Dir:     m.shellCtx.CWD,
+		Rating:  rating,
+		Comment: comment,
+	}
+	if err := feedback.Append(path, entry); err != nil {
+		return m, nil, err
+	}
+
+	switch rating {
+	case feedback.RatingGood:
+		m.feedbackNotice = "Rated good: it will be used as an example for similar requests"
+	case feedback.RatingBad:
+		m.feedbackNotice = "Rated bad: it won't be suggested as an example again"
+	default:
+		m.feedbackNotice = "Feedback recorded"
+	}
+
+	if !m.feedbackShare {
+		return m, nil, nil
+	}
+	securityCfg := auth.GetBastioSecurityConfig()
+	if securityCfg == nil {
+		return m, nil, nil
+	}
+	share := func() tea.Msg {
+		// Sharing is best-effort; the local record is what matters
+		if err := feedback.Share(context.Background(), securityCfg.BaseURL, securityCfg.ProxyID,
+			securityCfg.APIKey, securityCfg.DeviceID, entry); err != nil {
+			debug.Logf("feedback share failed: %v", err)
+		}
+		return nil
+	}
+	return m, share, nil
+}
@@ -0,0 +1,39 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/bastio-ai/bast/internal/ai"
+	"github.com/bastio-ai/bast/internal/feedback"
+)
+
+func TestEditedCommandIsLearnedForTheOriginalQuery(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	m := confirmModel("du -ah . | sort -rh | head")
+	m.generatedQuery = "largest files here"
+
+	// Edit the command; Enter resubmits it as a query
+	next, _ := m.handleConfirmModeKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	m = next.(Model)
+	next, _ = m.update(CommandGeneratedMsg{
+		Query:  "du -ah . | sort -rh | head -5",
+		Result: &ai.CommandResult{Command: "du -ah . | sort -rh | head -5"},
+	})
+	m = next.(Model)
+	if m.mode != ModeConfirm {
+		t.Fatalf("mode = %v, want the edited command confirmed", m.mode)
+	}
+
+	next, _ = m.acceptCommand(SyntaxCheckedMsg{Command: m.command, Mode: m.mode})
+	m = next.(Model)
+	path, err := feedback.DefaultAcceptedPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := feedback.LoadAccepted(path, feedback.ProjectRoot(m.shellCtx.CWD))
+	if len(got) != 1 || got[0].Query != "largest files here" || got[0].Command != "du -ah . | sort -rh | head -5" {
+		t.Errorf("accepted = %+v, want the edited command under the original query", got)
+	}
+}
@@ -0,0 +1,60 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// followUpFor returns the suggested follow-up Alt+1-3 picks. Plain digits
+// are left to the input, so a query can start with one.
+func (m Model) followUpFor(msg tea.KeyMsg) (string, bool) {
+	if !msg.Alt || msg.Type != tea.KeyRunes || len(msg.Runes) != 1 {
+		return "", false
+	}
+	r := msg.Runes[0]
+	if r < '1' || r > '9' {
+		return "", false
+	}
+	i := int(r - '1')
+	if i >= len(m.followUps) {
+		return "", false
+	}
+	return m.followUps[i], true
+}
+
+// pickFollowUp loads a suggested follow-up into the input. It is not sent:
+// suggestions can be steered by files and command output, so the user reads
+// it and presses Enter.
+func (m Model) pickFollowUp(followUp string) (tea.Model, tea.Cmd) {
+	m.textInput.SetValue(followUp)
+	m.textInput.CursorEnd()
+	return m, nil
+}
+
+// renderFollowUps renders the suggested follow-ups as numbered quick-picks
+func (m Model) renderFollowUps(contentWidth int) string {
+	if len(m.followUps) == 0 || m.textInput.Value() != "" {
+		return ""
+	}
+	var b strings.Builder
+	for i, followUp := range m.followUps {
+		b.WriteString(KeyStyle.Render(fmt.Sprintf("Alt+%d", i+1)))
+		b.WriteString(" ")
+		b.WriteString(DescStyle.MaxWidth(contentWidth - 2).Render(followUp))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// followUpsHelp is the help bar entry for picking a follow-up, when one is offered
+func (m Model) followUpsHelp() string {
+	if len(m.followUps) == 0 || m.textInput.Value() != "" {
+		return ""
+	}
+	if len(m.followUps) == 1 {
+		return " • Alt+1: follow-up"
+	}
+	return fmt.Sprintf(" • Alt+1-%d: follow-up", len(m.followUps))
+}
@@ -2,7 +2,7 @@ package tui
 
 import (
 	"fmt"
-	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/textinput"
@@ -10,10 +10,19 @@ import (
 
 	"github.com/bastio-ai/bast/internal/ai"
 	"github.com/bastio-ai/bast/internal/config"
+	"github.com/bastio-ai/bast/internal/feedback"
+	"github.com/bastio-ai/bast/internal/safety"
+	"github.com/bastio-ai/bast/internal/shell"
+	"github.com/bastio-ai/bast/internal/workspace"
 )
 
 // handleKeyMsg handles keyboard input based on current mode
 func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Ctrl+Z suspends to the shell in every mode, like any job-control program
+	if msg.String() == "ctrl+z" {
+		return m, tea.Suspend
+	}
+
 	switch m.mode {
 	case ModeInput:
 		return m.handleInputModeKey(msg)
@@ -29,6 +38,20 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleAgentModeKey(msg)
 	case ModeFix:
 		return m.handleFixModeKey(msg)
+	case ModeIntentChoice:
+		return m.handleIntentChoiceModeKey(msg)
+	case ModeWorkspaceSelect:
+		return m.handleWorkspaceSelectModeKey(msg)
+	case ModeOpenSelect:
+		return m.handleOpenSelectModeKey(msg)
+	case ModeSecurityConfirm:
+		return m.handleSecurityConfirmModeKey(msg)
+	case ModeInjectionFlag:
+		return m.handleInjectionFlagModeKey(msg)
+	case ModeContextPreview:
+		return m.handleContextPreviewModeKey(msg)
+	case ModeSteps:
+		return m.handleStepsModeKey(msg)
 	}
 
 	// Update text input for unhandled modes
@@ -86,6 +109,11 @@ func (m Model) handleInputModeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "ctrl+c":
 		return m, tea.Quit
+	case "ctrl+n":
+		// New conversation - clear history (e.g. after a context-too-long error)
+		m.conversationHistory = nil
+		m.err = nil
+		return m, nil
 	case "esc":
 		if m.showSlashMenu {
 			m.showSlashMenu = false
@@ -109,15 +137,8 @@ func (m Model) handleInputModeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if query == "" {
 			return m, nil
 		}
-		// Intercept slash commands before intent classification
-		if strings.HasPrefix(query, "/") {
-			return m.handleSlashCommand(query)
-		}
-		m.mode = ModeLoading
-		m.loadingMessage = "Classifying intent..."
-		m.pendingQuery = query
-		m.err = nil
-		return m, tea.Batch(m.spinner.Tick, m.classifyIntent(query))
+		m.rateLimitRetries = 0
+		return m.submit(query)
 	}
 
 	// Let textinput handle the key first
@@ -139,8 +160,26 @@ func (m Model) handleInputModeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// submit runs a query from input mode: slash commands directly, anything
+// else after intent classification
+func (m Model) submit(query string) (tea.Model, tea.Cmd) {
+	m.lastInput = query
+	// Intercept slash commands before intent classification
+	if strings.HasPrefix(query, "/") {
+		return m.handleSlashCommand(query)
+	}
+	m.mode = ModeLoading
+	m.loadingMessage = "Classifying intent..."
+	m.pendingQuery = query
+	m.err = nil
+	return m, tea.Batch(m.spinner.Tick, m.classifyIntent(query))
+}
+
 // handleLoadingModeKey handles keys in loading mode
 func (m Model) handleLoadingModeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.rateLimitErr != nil {
+		return m.handleRateLimitKey(msg)
+	}
 	switch msg.String() {
 	case "ctrl+c", "esc":
 		return m, tea.Quit
@@ -151,7 +190,14 @@ func (m Model) handleLoadingModeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 // handleConfirmModeKey handles keys in confirm mode
 func (m Model) handleConfirmModeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
-	case "ctrl+c", "esc":
+	case "esc":
+		// Stop running checklist commands and go back to the list
+		if m.stepRun {
+			return m.stopSteps(nil)
+		}
+		return m, tea.Quit
+
+	case "ctrl+c":
 		return m, tea.Quit
 
 	case "enter", "y":
@@ -169,6 +215,7 @@ func (m Model) handleConfirmModeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.loadingMessage = "Getting response..."
 				m.pendingQuery = query
 				m.textInput.SetValue("")
+				m.lastInput = "" // Resubmitting would lose the command context
 				return m, tea.Batch(m.spinner.Tick, m.chatAboutCommand(query, m.command))
 			}
 			// Empty enter on dangerous command - do nothing
@@ -181,27 +228,122 @@ func (m Model) handleConfirmModeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.loadingMessage = "Getting response..."
 			m.pendingQuery = query
 			m.textInput.SetValue("")
+			m.lastInput = "" // Resubmitting would lose the command context
 			return m, tea.Batch(m.spinner.Tick, m.chatAboutCommand(query, m.command))
 		}
 
-		// No text - execute the command
-		if m.outputFile != "" {
-			os.WriteFile(m.outputFile, []byte("BAST_COMMAND:"+m.command), 0600)
-		} else {
-			fmt.Printf("BAST_COMMAND:%s\n", m.command)
+		// Never run a command carrying a credential; it has to be edited out
+		if len(safety.DetectSecrets(m.command)) > 0 {
+			return m, nil
+		}
+
+		// Elevated commands need an explicit acknowledgment first
+		if m.needsSudo && !m.sudoAcknowledged {
+			m.sudoAcknowledged = true
+			return m, nil
 		}
-		return m, tea.Quit
+
+		// Never hand the shell a command it can't parse; it has to be edited.
+		// The command runs once the check comes back (acceptCommand).
+		return m.checkSyntax()
 
 	case "e":
 		// Edit mode - go back to input with command as value
 		m.mode = ModeInput
 		m.textInput.SetValue(m.command)
 		m.textInput.Focus()
+		m.editedFrom = m.command
 		m.command = ""
 		m.explanation = ""
+		m.commandEdited = true
 		m.resetAutocomplete()
 		return m, textinput.Blink
 
+	case "t":
+		// Use the command translated for this OS, unless a follow-up question is being typed
+		if m.translation != nil && m.textInput.Value() == "" {
+			return m.useTranslation()
+		}
+		var cmd tea.Cmd
+		m.textInput, cmd = m.textInput.Update(msg)
+		return m, cmd
+
+	case "o":
+		// Keep the original command instead of the safer rewrite, unless a follow-up question is being typed
+		if m.rewrite != nil && m.textInput.Value() == "" {
+			return m.keepOriginal()
+		}
+		var cmd tea.Cmd
+		m.textInput, cmd = m.textInput.Update(msg)
+		return m, cmd
+
+	case "i":
+		// Prepend the install command for missing binaries, unless a follow-up question is being typed
+		if m.missing != nil && m.missing.Install != "" && m.textInput.Value() == "" {
+			return m.useInstall()
+		}
+		var cmd tea.Cmd
+		m.textInput, cmd = m.textInput.Update(msg)
+		return m, cmd
+
+	case "g", "b":
+		// Rate the generation, unless a follow-up question is being typed
+		if m.textInput.Value() == "" {
+			rating := feedback.RatingGood
+			if msg.String() == "b" {
+				rating = feedback.RatingBad
+			}
+			model, send, err := m.rate(rating, "")
+			model.err = err
+			return model, send
+		}
+		var cmd tea.Cmd
+		m.textInput, cmd = m.textInput.Update(msg)
+		return m, cmd
+
+	case "tab", "shift+tab":
+		// Page through alternative commands when several were generated
+		if len(m.candidates) < 2 {
+			return m, nil
+		}
+		step := 1
+		if msg.String() == "shift+tab" {
+			step = len(m.candidates) - 1
+		}
+		m = m.showCandidate((m.candidateIndex + step) % len(m.candidates))
+		m.feedbackNotice = ""
+		return m, nil
+
+	case "w":
+		// Run the command here rather than in the suggested directory, unless typing
+		if m.workDir != "" && m.textInput.Value() == "" {
+			m.workDir = ""
+			m.aliasWarnings = m.commandWarnings(m.command) // Paths now resolve from here
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.textInput, cmd = m.textInput.Update(msg)
+		return m, cmd
+
+	case "d":
+		// Compare with the command before edits or rewrites, unless typing
+		if original := m.originalCommand(); original != "" && m.textInput.Value() == "" {
+			return m, m.compareCommands(original, m.command)
+		}
+		var cmd tea.Cmd
+		m.textInput, cmd = m.textInput.Update(msg)
+		return m, cmd
+
+	case "s":
+		// Show what the request sent, unless a follow-up question is being typed
+		if m.sentContext != nil && m.textInput.Value() == "" {
+			m.showSentContext = !m.showSentContext
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.textInput, cmd = m.textInput.Update(msg)
+		return m, cmd
+
 	case "c":
 		// Copy to clipboard (placeholder - would need clipboard library)
 		return m, nil
@@ -221,7 +363,10 @@ func (m Model) handleConfirmModeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.textInput.SetValue("")
 		m.textInput.Focus()
 		m.command = ""
+		m.workDir = ""
 		m.explanation = ""
+		m.commandEdited = false
+		m.editedFrom = ""
 		m.resetAutocomplete()
 		return m, textinput.Blink
 
@@ -233,6 +378,62 @@ func (m Model) handleConfirmModeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	}
 }
 
+// useTranslation replaces the pending command with its OS-specific rewrite
+func (m Model) useTranslation() (tea.Model, tea.Cmd) {
+	command, err := m.applySudoPolicy(m.translation.Command)
+	if err != nil {
+		return m, func() tea.Msg { return ErrorMsg{Err: err} }
+	}
+	m.command = command
+	m.explanation = ""
+	m.syntaxErr = nil
+	m.isDangerous = isDangerousCommand(command)
+	m.dangerConfirmed = false
+	m.needsSudo = safety.NeedsSudo(command)
+	m.sudoAcknowledged = false
+	m.translation = nil
+	m.rewrite = nil
+	m.missing = shell.FindMissingBinaries(command, m.shellCtx.OS, "")
+	m.aliasWarnings = m.commandWarnings(command)
+	return m, nil
+}
+
+// useInstall prepends the install command for missing binaries
+func (m Model) useInstall() (tea.Model, tea.Cmd) {
+	command, err := m.applySudoPolicy(m.missing.Command)
+	if err != nil {
+		return m, func() tea.Msg { return ErrorMsg{Err: err} }
+	}
+	m.command = command
+	m.explanation = ""
+	m.syntaxErr = nil
+	m.isDangerous = isDangerousCommand(command)
+	m.dangerConfirmed = false
+	m.needsSudo = safety.NeedsSudo(command)
+	m.sudoAcknowledged = false
+	m.missing = nil
+	m.rewrite = nil
+	m.aliasWarnings = m.commandWarnings(command)
+	return m, nil
+}
+
+// keepOriginal replaces the safer rewrite with the command as generated
+func (m Model) keepOriginal() (tea.Model, tea.Cmd) {
+	command := m.rewrite.Original
+	m.command = command
+	m.explanation = ""
+	m.syntaxErr = nil
+	m.isDangerous = isDangerousCommand(command)
+	m.dangerConfirmed = false
+	m.needsSudo = safety.NeedsSudo(command)
+	m.sudoAcknowledged = false
+	m.rewrite = nil
+	m.translation = shell.TranslateCommand(command, m.shellCtx.OS, "")
+	m.missing = shell.FindMissingBinaries(command, m.shellCtx.OS, "")
+	m.aliasWarnings = m.commandWarnings(command)
+	return m, nil
+}
+
 // handleChatModeKey handles keys in chat mode
 func (m Model) handleChatModeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// Handle slash command menu navigation when visible
@@ -279,6 +480,16 @@ func (m Model) handleChatModeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// Picking an earlier message to edit
+	if m.editSelecting {
+		return m.handleEditSelectKey(msg)
+	}
+
+	// Load a suggested follow-up to send with Enter
+	if followUp, ok := m.followUpFor(msg); ok {
+		return m.pickFollowUp(followUp)
+	}
+
 	switch msg.String() {
 	case "ctrl+c":
 		return m, tea.Quit
@@ -287,13 +498,38 @@ func (m Model) handleChatModeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// If input has text, clear it; otherwise quit
 		if m.textInput.Value() != "" {
 			m.textInput.SetValue("")
+			m.editing = false
 			return m, nil
 		}
 		return m, tea.Quit
 
+	case "ctrl+e":
+		// Pick an earlier message to edit and resend
+		if i := previousUserMessage(m.conversationHistory, len(m.conversationHistory)); i >= 0 {
+			m.editSelecting = true
+			m.editCursor = i
+			m.refreshConversation()
+		}
+		return m, nil
+
+	case "ctrl+r":
+		// Restore the most recently abandoned branch
+		return m.restoreBranch(), nil
+
+	case "ctrl+o":
+		// Open a referenced file in the editor
+		return m.openReference()
+
+	case "ctrl+x":
+		// Pick commands from the answer to run
+		return m.reviewAnswerCommands()
+
 	case "ctrl+n":
 		// New conversation - clear history and go to input mode
 		m.conversationHistory = nil
+		m.followUps = nil
+		m.answerCommands = nil
+		m.editing = false
 		m.chatResponse = ""
 		m.mode = ModeInput
 		m.textInput.SetValue("")
@@ -332,6 +568,20 @@ func (m Model) handleChatModeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if query == "" {
 			return m, nil
 		}
+		// Check for slash commands
+		if strings.HasPrefix(query, "/") {
+			m.lastInput = query
+			return m.handleSlashCommand(query)
+		}
+		// Resending an edited message starts a new branch from that point
+		if m.editing {
+			m = m.branchAt(m.editIndex)
+		}
+		m.lastInput = query
+		m.rateLimitRetries = 0
+		m.err = nil
+		m.followUps = nil
+		m.answerCommands = nil
 		m.mode = ModeLoading
 		m.loadingMessage = "Classifying intent..."
 		m.textInput.SetValue("")
@@ -356,6 +606,122 @@ func (m Model) handleChatModeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// handleEditSelectKey handles keys while picking a message to edit
+func (m Model) handleEditSelectKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc", "ctrl+e":
+		m.editSelecting = false
+		m.refreshConversation()
+		return m, nil
+
+	case "up", "k":
+		if i := previousUserMessage(m.conversationHistory, m.editCursor); i >= 0 {
+			m.editCursor = i
+			m.refreshConversation()
+		}
+		return m, nil
+
+	case "down", "j":
+		if i := nextUserMessage(m.conversationHistory, m.editCursor); i >= 0 {
+			m.editCursor = i
+			m.refreshConversation()
+		}
+		return m, nil
+
+	case "enter":
+		// Load the message into the input; sending it creates the branch
+		m.editSelecting = false
+		m.editing = true
+		m.editIndex = m.editCursor
+		m.textInput.SetValue(m.conversationHistory[m.editCursor].Content)
+		m.textInput.CursorEnd()
+		m.textInput.Focus()
+		m.refreshConversation()
+		return m, textinput.Blink
+	}
+	return m, nil
+}
+
+// branchAt archives the current conversation and truncates it before index
+func (m Model) branchAt(index int) Model {
+	archived := make([]ai.ConversationMessage, len(m.conversationHistory))
+	copy(archived, m.conversationHistory)
+	m.chatBranches = append(m.chatBranches, archived)
+	m.branchIDs = append(m.branchIDs, m.branch)
+	m.lastBranch++
+	m.branch = m.lastBranch
+	m.conversationHistory = m.conversationHistory[:index:index]
+	m.editing = false
+	m.refreshConversation()
+	return m
+}
+
+// restoreBranch swaps the current conversation with the most recently
+// abandoned branch. The current one moves to the back of the queue, so
+// repeated presses cycle through every branch.
+func (m Model) restoreBranch() Model {
+	if len(m.chatBranches) == 0 {
+		return m
+	}
+	last := len(m.chatBranches) - 1
+	restored, restoredID := m.chatBranches[last], m.branchIDs[last]
+	branches, ids := m.chatBranches[:last:last], m.branchIDs[:last:last]
+	if len(m.conversationHistory) > 0 {
+		branches = append([][]ai.ConversationMessage{m.conversationHistory}, branches...)
+		ids = append([]int{m.branch}, ids...)
+	}
+	m.chatBranches, m.branchIDs = branches, ids
+	m.conversationHistory, m.branch = restored, restoredID
+	m.editing = false
+	m.followUps = nil
+	m.answerCommands = nil
+	if n := len(restored); n > 0 && restored[n-1].Role == "assistant" {
+		m.answerCommands = ai.ExtractCommands(restored[n-1].Content)
+	}
+	m.refreshConversation()
+	if m.viewportReady {
+		m.chatViewport.GotoBottom()
+	}
+	return m
+}
+
+// refreshConversation re-renders the chat viewport, keeping the message
+// being picked for editing in view
+func (m *Model) refreshConversation() {
+	if !m.viewportReady {
+		return
+	}
+	m.chatViewport.SetContent(m.renderConversationContent())
+	if m.editSelecting {
+		m.chatViewport.SetYOffset(m.conversationLineOffset(m.editCursor))
+	}
+}
+
+// previousUserMessage returns the index of the last user message before
+// index, or -1 if there is none
+func previousUserMessage(history []ai.ConversationMessage, index int) int {
+	for i := index - 1; i >= 0; i-- {
+		if history[i].Role == "user" {
+			return i
+		}
+	}
+	return -1
+}
+
+// nextUserMessage returns the index of the first user message after
+// index, or -1 if there is none
+func nextUserMessage(history []ai.ConversationMessage, index int) int {
+	for i := index + 1; i < len(history); i++ {
+		if history[i].Role == "user" {
+			return i
+		}
+	}
+	return -1
+}
+
 // checkForSlashCommand checks if input starts with "/" and shows the command menu
 func (m Model) checkForSlashCommand() Model {
 	val := m.textInput.Value()
@@ -380,10 +746,10 @@ func (m Model) checkForSlashCommand() Model {
 func (m Model) executeSlashCommand(cmdName string) (tea.Model, tea.Cmd) {
 	m.showSlashMenu = false
 
-	// Commands that require arguments: set prefix and let user continue typing
-	if cmdName == "/agent" {
-		m.textInput.SetValue("/agent ")
-		m.textInput.SetCursor(len("/agent "))
+	// Commands that take arguments: set prefix and let user continue typing
+	if cmdName == "/mode" || cmdName == "/agent" || cmdName == "/pin" || cmdName == "/unpin" || cmdName == "/cd" || cmdName == "/env" || cmdName == "/target" || cmdName == "/feedback" {
+		m.textInput.SetValue(cmdName + " ")
+		m.textInput.SetCursor(len(cmdName) + 1)
 		return m, nil
 	}
 
@@ -412,13 +778,19 @@ func (m Model) handleFixModeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 
-			// Output the fixed command
-			if m.outputFile != "" {
-				os.WriteFile(m.outputFile, []byte("BAST_COMMAND:"+m.command), 0600)
-			} else {
-				fmt.Printf("BAST_COMMAND:%s\n", m.command)
+			// Never run a command carrying a credential
+			if len(safety.DetectSecrets(m.command)) > 0 {
+				return m, nil
 			}
-			return m, tea.Quit
+
+			// Elevated commands need an explicit acknowledgment first
+			if m.needsSudo && !m.sudoAcknowledged {
+				m.sudoAcknowledged = true
+				return m, nil
+			}
+
+			// Output the fixed command once it parses (acceptCommand)
+			return m.checkSyntax()
 		}
 		return m, nil
 
@@ -450,13 +822,21 @@ func (m Model) handleSlashCommand(query string) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		m.currentModel = cfg.Model
-		m.modelOptions = ai.GetModelsForProvider(cfg.Provider)
+		m.modelOptions = allowedModelOptions(cfg)
 		m.modelCursor = 0
 		m.customModelInput = false
 		m.mode = ModeModelSelect
 		m.textInput.SetValue("")
 		m.err = nil
 		return m, nil
+	case strings.HasPrefix(query, "/mode"):
+		var err error
+		m, err = m.setMode(strings.TrimPrefix(query, "/mode"))
+		m.err = err
+		if err == nil {
+			m.textInput.SetValue("")
+		}
+		return m, nil
 	case strings.HasPrefix(query, "/agent"):
 		// Extract query after /agent command
 		agentQuery := strings.TrimSpace(strings.TrimPrefix(query, "/agent"))
@@ -468,11 +848,84 @@ func (m Model) handleSlashCommand(query string) (tea.Model, tea.Cmd) {
 		m.loadingMessage = "Running agent..."
 		m.pendingQuery = agentQuery
 		m.agentToolCalls = nil // Reset tool calls
+		m.agentProgress = nil
 		m.agentResult = nil
 		m.err = nil
-		// Note: We can't easily send updates during execution in the current architecture.
-		// Tool calls will be shown in the final result.
-		return m, tea.Batch(m.spinner.Tick, m.runAgent(agentQuery, nil))
+		return m, tea.Batch(m.spinner.Tick, m.runAgent(agentQuery))
+	case strings.HasPrefix(query, "/pin"):
+		var err error
+		m, err = m.pin(strings.TrimSpace(strings.TrimPrefix(query, "/pin")))
+		m.err = err
+		if err == nil {
+			m.textInput.SetValue("")
+		}
+		return m, nil
+	case strings.HasPrefix(query, "/unpin"):
+		var err error
+		m, err = m.unpin(strings.TrimSpace(strings.TrimPrefix(query, "/unpin")))
+		m.err = err
+		if err == nil {
+			m.textInput.SetValue("")
+		}
+		return m, nil
+	case strings.HasPrefix(query, "/cd"):
+		model, load, err := m.changeDir(strings.TrimSpace(strings.TrimPrefix(query, "/cd")))
+		model.err = err
+		if err == nil {
+			model.textInput.SetValue("")
+		}
+		return model, load
+	case strings.HasPrefix(query, "/target"):
+		var err error
+		m, err = m.setTarget(strings.TrimPrefix(query, "/target"))
+		m.err = err
+		if err == nil {
+			m.textInput.SetValue("")
+		}
+		return m, nil
+	case strings.HasPrefix(query, "/env"):
+		var err error
+		m, err = m.setEnv(strings.TrimPrefix(query, "/env"))
+		m.err = err
+		if err == nil {
+			m.textInput.SetValue("")
+		}
+		return m, nil
+	case strings.HasPrefix(query, "/feedback"):
+		model, send, err := m.rate(feedback.RatingNote, strings.Trim(strings.TrimSpace(strings.TrimPrefix(query, "/feedback")), `"`))
+		model.err = err
+		if err == nil {
+			model.textInput.SetValue("")
+		}
+		return model, send
+	case strings.HasPrefix(query, "/compact"):
+		return m.compact()
+	case strings.HasPrefix(query, "/context"):
+		preview := m.previewContext(strings.TrimSpace(strings.TrimPrefix(query, "/context")))
+		m.mode = ModeLoading
+		m.loadingMessage = "Gathering context..."
+		m.textInput.SetValue("")
+		m.err = nil
+		return m, tea.Batch(m.spinner.Tick, preview)
+	case strings.HasPrefix(query, "/workspace"):
+		ws := workspace.Find(m.shellCtx.CWD)
+		if ws == nil || len(ws.Members) == 0 {
+			m.err = fmt.Errorf("no go.work, pnpm-workspace.yaml, or Cargo workspace found above %s", m.shellCtx.CWD)
+			return m, nil
+		}
+		m.workspace = ws
+		m.workspaceCursor = 0
+		if current := ws.Current(m.shellCtx.CWD); current != nil {
+			for i, member := range ws.Members {
+				if member.Path == current.Path {
+					m.workspaceCursor = i + 1
+				}
+			}
+		}
+		m.mode = ModeWorkspaceSelect
+		m.textInput.SetValue("")
+		m.err = nil
+		return m, nil
 	case strings.HasPrefix(query, "/fix"):
 		m.mode = ModeLoading
 		m.loadingMessage = "Analyzing error..."
@@ -532,6 +985,11 @@ func (m Model) handleAgentModeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// Load a suggested follow-up to run as the next task with Enter
+	if followUp, ok := m.followUpFor(msg); ok {
+		return m.pickFollowUp(followUp)
+	}
+
 	switch msg.String() {
 	case "ctrl+c":
 		return m, tea.Quit
@@ -544,11 +1002,17 @@ func (m Model) handleAgentModeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, tea.Quit
 
+	case "ctrl+o":
+		// Open a referenced file in the editor
+		return m.openReference()
+
 	case "ctrl+n":
 		// New conversation - clear history and go to input mode
 		m.conversationHistory = nil
+		m.followUps = nil
 		m.agentResult = nil
 		m.agentToolCalls = nil
+		m.agentProgress = nil
 		m.mode = ModeInput
 		m.textInput.SetValue("")
 		m.textInput.Focus()
@@ -588,15 +1052,21 @@ func (m Model) handleAgentModeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		// Check for slash commands
 		if strings.HasPrefix(query, "/") {
+			m.lastInput = query
 			return m.handleSlashCommand(query)
 		}
+		m.lastInput = "/agent " + query
+		m.rateLimitRetries = 0
 		// Run another agent task
+		m.err = nil
 		m.mode = ModeLoading
 		m.loadingMessage = "Running agent..."
 		m.agentToolCalls = nil
+		m.agentProgress = nil
 		m.agentResult = nil
+		m.followUps = nil
 		m.textInput.SetValue("")
-		return m, tea.Batch(m.spinner.Tick, m.runAgent(query, nil))
+		return m, tea.Batch(m.spinner.Tick, m.runAgent(query))
 	}
 
 	// Pass key to text input for typing
@@ -617,6 +1087,76 @@ func (m Model) handleAgentModeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// handleIntentChoiceModeKey handles keys when asking the user to pick command vs chat
+func (m Model) handleIntentChoiceModeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		m.intentChoiceCursor = 0
+		return m, nil
+	case "down", "j":
+		m.intentChoiceCursor = 1
+		return m, nil
+	case "1", "c":
+		return m.chooseIntent(ai.IntentCommand)
+	case "2", "a":
+		return m.chooseIntent(ai.IntentChat)
+	case "enter":
+		if m.intentChoiceCursor == 0 {
+			return m.chooseIntent(ai.IntentCommand)
+		}
+		return m.chooseIntent(ai.IntentChat)
+	case "esc":
+		m.mode = ModeInput
+		m.pendingIntent = nil
+		m.textInput.SetValue(m.pendingQuery)
+		m.textInput.Focus()
+		return m, textinput.Blink
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// chooseIntent routes the pending query with the user's choice and remembers it
+func (m Model) chooseIntent(intent ai.Intent) (tea.Model, tea.Cmd) {
+	result := &ai.IntentResult{Intent: intent, Confidence: 1.0, Reasoning: "user choice"}
+	if m.pendingIntent != nil {
+		result.NeedsHistory = m.pendingIntent.NeedsHistory
+	}
+	m.pendingIntent = nil
+
+	// Best-effort: failing to persist the choice shouldn't block the query
+	_ = m.intentOverrides.Remember(m.pendingQuery, intent)
+
+	next, cmd := m.routeIntent(m.pendingQuery, result)
+	return next, tea.Batch(m.spinner.Tick, cmd)
+}
+
+// handleSecurityConfirmModeKey handles the y/n prompt for a warned tool call
+func (m Model) handleSecurityConfirmModeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "enter":
+		return m.answerSecurityPrompt(true)
+	case "n", "esc":
+		return m.answerSecurityPrompt(false)
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// answerSecurityPrompt resumes the paused agent with the user's decision
+func (m Model) answerSecurityPrompt(proceed bool) (tea.Model, tea.Cmd) {
+	prompt := m.securityPrompt
+	m.securityPrompt = nil
+	m.mode = ModeLoading
+	if prompt == nil {
+		return m, nil
+	}
+	prompt.reply <- proceed
+	return m, waitForSecurityPrompt(prompt.prompts)
+}
+
 // handleModelSelectModeKey handles keys in model selection mode
 func (m Model) handleModelSelectModeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if m.customModelInput {
@@ -670,3 +1210,95 @@ func (m Model) handleModelSelectModeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	}
 	return m, nil
 }
+
+// handleWorkspaceSelectModeKey handles keys in the workspace picker
+func (m Model) handleWorkspaceSelectModeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.workspaceCursor > 0 {
+			m.workspaceCursor--
+		}
+	case "down", "j":
+		if m.workspaceCursor < len(m.workspace.Members) { // +1 for the root
+			m.workspaceCursor++
+		}
+	case "enter":
+		dir := m.workspace.Root
+		if m.workspaceCursor > 0 {
+			dir = filepath.Join(dir, m.workspace.Members[m.workspaceCursor-1].Path)
+		}
+		model, load, err := m.changeDir(dir)
+		model.err = err
+		model.mode = ModeInput
+		model.workspace = nil
+		return model, tea.Batch(load, textinput.Blink)
+	case "esc":
+		m.mode = ModeInput
+		m.workspace = nil
+		return m, textinput.Blink
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// handleOpenSelectModeKey handles keys in the referenced file picker
+func (m Model) handleOpenSelectModeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.openCursor > 0 {
+			m.openCursor--
+		}
+	case "down", "j":
+		if m.openCursor < len(m.openRefs)-1 {
+			m.openCursor++
+		}
+	case "enter":
+		ref := m.openRefs[m.openCursor]
+		m.mode = m.openReturn
+		m.openRefs = nil
+		return m, m.openInEditor(ref)
+	case "esc":
+		m.mode = m.openReturn
+		m.openRefs = nil
+		return m, nil
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// checkSyntax parses the accepted command with the user's shell in the
+// background, since that runs a process, and keeps further accepts out
+// until the result arrives
+func (m Model) checkSyntax() (tea.Model, tea.Cmd) {
+	if m.checkingSyntax {
+		return m, nil
+	}
+	m.checkingSyntax = true
+	shellName, command, mode := m.shellCtx.Shell, m.command, m.mode
+	return m, func() tea.Msg {
+		return SyntaxCheckedMsg{Command: command, Mode: mode, Err: shell.CheckSyntax(shellName, command)}
+	}
+}
+
+// acceptCommand runs or hands off the accepted command once it parses. A
+// result for a command the user has since changed or left is dropped.
+func (m Model) acceptCommand(msg SyntaxCheckedMsg) (tea.Model, tea.Cmd) {
+	m.checkingSyntax = false
+	if msg.Command != m.command || msg.Mode != m.mode {
+		return m, nil
+	}
+	if m.syntaxErr = msg.Err; m.syntaxErr != nil {
+		return m, nil
+	}
+
+	if m.mode == ModeConfirm {
+		m.recordAccepted()
+		if m.stepRun {
+			return m, m.runStep()
+		}
+	}
+	m.handOffCommand()
+	return m, tea.Quit
+}
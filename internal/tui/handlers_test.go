@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/bastio-ai/bast/internal/ai"
+	"github.com/bastio-ai/bast/internal/safety"
+	"github.com/bastio-ai/bast/internal/shell"
+)
+
+// confirmModel returns a model showing command in the confirm view
+func confirmModel(command string) Model {
+	ti := textinput.New()
+	ti.Focus()
+	return Model{
+		mode:      ModeConfirm,
+		command:   command,
+		textInput: ti,
+		shellCtx:  ai.ShellContext{OS: "linux", Shell: "bash"},
+	}
+}
+
+// typeKeys sends each rune of s to the confirm view
+func typeKeys(t *testing.T, m Model, s string) Model {
+	t.Helper()
+	for _, r := range s {
+		next, _ := m.handleConfirmModeKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = next.(Model)
+	}
+	return m
+}
+
+func TestConfirmTranslationKeyTypesInFollowUp(t *testing.T) {
+	m := confirmModel("apt-get install jq")
+	m.translation = &shell.Translation{Original: m.command, Command: "brew install jq"}
+
+	m = typeKeys(t, m, "what")
+	if got := m.textInput.Value(); got != "what" {
+		t.Errorf("follow-up = %q, want %q", got, "what")
+	}
+	if m.command != "apt-get install jq" {
+		t.Errorf("command = %q, translation applied while typing", m.command)
+	}
+}
+
+func TestConfirmKeepOriginalKeyTypesInFollowUp(t *testing.T) {
+	m := confirmModel("trash old.txt")
+	m.rewrite = &safety.Rewrite{Original: "rm old.txt", Command: m.command}
+
+	m = typeKeys(t, m, "how")
+	if got := m.textInput.Value(); got != "how" {
+		t.Errorf("follow-up = %q, want %q", got, "how")
+	}
+	if m.command != "trash old.txt" {
+		t.Errorf("command = %q, original kept while typing", m.command)
+	}
+}
+
+func TestConfirmInstallKeyTypesInFollowUp(t *testing.T) {
+	m := confirmModel("jq . data.json")
+	m.missing = &shell.MissingBinaries{Binaries: []string{"jq"}, Install: "sudo apt install jq", Command: "sudo apt install jq && jq . data.json"}
+
+	m = typeKeys(t, m, "jq via apk")
+	if got := m.textInput.Value(); got != "jq via apk" {
+		t.Errorf("follow-up = %q, want %q", got, "jq via apk")
+	}
+	if m.command != "jq . data.json" {
+		t.Errorf("command = %q, install prepended while typing", m.command)
+	}
+}
+
+func TestConfirmChecksSyntaxInBackground(t *testing.T) {
+	m := confirmModel("echo hi")
+	m.outputFile = filepath.Join(t.TempDir(), "handoff")
+
+	next, cmd := m.handleConfirmModeKey(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+	if cmd == nil || !m.checkingSyntax {
+		t.Fatal("enter should start a background syntax check")
+	}
+	if _, again := m.handleConfirmModeKey(tea.KeyMsg{Type: tea.KeyEnter}); again != nil {
+		t.Error("a second enter while checking should do nothing")
+	}
+
+	msg, ok := cmd().(SyntaxCheckedMsg)
+	if !ok || msg.Command != "echo hi" {
+		t.Fatalf("check returned %#v, want SyntaxCheckedMsg for the command", msg)
+	}
+	if _, quit := m.acceptCommand(msg); quit == nil {
+		t.Error("a command that parses should be handed off")
+	}
+}
+
+func TestAcceptCommandBlocksSyntaxErrors(t *testing.T) {
+	m := confirmModel("echo 'unterminated")
+	m.checkingSyntax = true
+
+	parseErr := &shell.SyntaxError{Shell: "bash", Message: "unexpected EOF"}
+	next, cmd := m.acceptCommand(SyntaxCheckedMsg{Command: m.command, Mode: ModeConfirm, Err: parseErr})
+	m = next.(Model)
+	if cmd != nil || m.syntaxErr == nil || m.checkingSyntax {
+		t.Errorf("cmd = %v, syntaxErr = %v; want the handoff blocked", cmd, m.syntaxErr)
+	}
+
+	// A result for a command that has since changed is dropped
+	m.syntaxErr = nil
+	m.command = "echo fixed"
+	if _, cmd := m.acceptCommand(SyntaxCheckedMsg{Command: "echo 'unterminated", Mode: ModeConfirm}); cmd != nil {
+		t.Error("a stale check should not hand off the current command")
+	}
+}
@@ -0,0 +1,94 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/bastio-ai/bast/internal/ai"
+	"github.com/bastio-ai/bast/internal/files"
+	"github.com/bastio-ai/bast/internal/safety"
+)
+
+// injectionFindings describes the attached files that contain text
+// addressed to the model, one "path: marker, marker" entry per file
+func injectionFindings(contents []files.FileContent) []string {
+	var findings []string
+	for _, f := range contents {
+		if f.Error != "" {
+			continue
+		}
+		if markers := safety.DetectInjection(f.Content); len(markers) > 0 {
+			findings = append(findings, fmt.Sprintf("%s: %s", f.Path, strings.Join(markers, ", ")))
+		}
+	}
+	return findings
+}
+
+// handleInjectionFlagModeKey handles the send/edit prompt for flagged files
+func (m Model) handleInjectionFlagModeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "enter":
+		return m.answerInjectionFlag(true)
+	case "n", "esc":
+		return m.answerInjectionFlag(false)
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// answerInjectionFlag sends the held-back query with its files, or returns
+// it to the input so the user can drop the mention
+func (m Model) answerInjectionFlag(send bool) (tea.Model, tea.Cmd) {
+	flag := m.injectionFlag
+	m.injectionFlag = nil
+	if flag == nil {
+		m.mode = ModeInput
+		return m, nil
+	}
+
+	if !send {
+		query := flag.Query
+		if flag.Intent.Intent == ai.IntentAgent {
+			query = "/agent " + query
+		}
+		m.mode = ModeInput
+		m.textInput.SetValue(query)
+		m.textInput.CursorEnd()
+		m.textInput.Focus()
+		return m, textinput.Blink
+	}
+
+	m.injectionAck = true
+	next, cmd := m.routeIntent(flag.Query, flag.Intent)
+	model := next.(Model)
+	model.injectionAck = false
+	return model, tea.Batch(m.spinner.Tick, cmd)
+}
+
+// renderInjectionFlagMode renders the prompt for files that look like prompt injection
+func (m Model) renderInjectionFlagMode(contentWidth int) string {
+	var b strings.Builder
+	flag := m.injectionFlag
+	if flag == nil {
+		return ""
+	}
+
+	b.WriteString(WarningStyle.Render("⚠  Attached files contain text addressed to the model"))
+	b.WriteString("\n\n")
+	for _, finding := range flag.Findings {
+		b.WriteString(lipgloss.NewStyle().Width(contentWidth).Render("  " + finding))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(lipgloss.NewStyle().Width(contentWidth).Render(DescStyle.Render(
+		"They are sent marked as data, not instructions, but could still steer the answer.")))
+	b.WriteString("\n\n")
+	b.WriteString(HelpStyle.Render("Send anyway? y/Enter: send • n/Esc: edit the query"))
+
+	return b.String()
+}
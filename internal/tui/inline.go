@@ -0,0 +1,27 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// SetInline renders the TUI compactly beneath the prompt, without the
+// alternate screen or frame, until a chat or agent session needs the room.
+// The program must then be started without tea.WithAltScreen.
+func (m *Model) SetInline(inline bool) {
+	m.inline = inline
+}
+
+// isInline reports whether the view is currently drawn inline
+func (m Model) isInline() bool {
+	return m.inline && !m.fullscreen
+}
+
+// enterFullscreen moves an inline session to the alternate screen once it
+// becomes a chat or agent session, whose scrolling output needs the room
+func (m Model) enterFullscreen(cmd tea.Cmd) (tea.Model, tea.Cmd) {
+	if !m.isInline() || (m.mode != ModeChat && m.mode != ModeAgent) {
+		return m, cmd
+	}
+	m.fullscreen = true
+	return m, tea.Batch(cmd, tea.EnterAltScreen)
+}
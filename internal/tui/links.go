@@ -0,0 +1,213 @@
+package tui
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bastio-ai/bast/internal/config"
+	"github.com/bastio-ai/bast/internal/files"
+)
+
+// linkSpan is text in an answer that renders as a link
+type linkSpan struct {
+	raw      string // Text as it appears in the answer
+	target   string // Link target, empty to only style the text
+	citation bool   // [path:lines] citation, highlighted even without links
+}
+
+var (
+	urlRe = regexp.MustCompile("https?://[^\\s<>()\\[\\]\"'`]+")
+
+	// pathLineRe splits an optional :line suffix off a path
+	pathLineRe = regexp.MustCompile(`^(.+?)(?::(\d+))?$`)
+)
+
+// renderLinkedMarkdown renders chat or agent output with its [path:lines]
+// citations highlighted, and citations, URLs, and existing file paths turned
+// into terminal hyperlinks when enabled. Citations to files that do not
+// exist are left plain.
+func (m Model) renderLinkedMarkdown(content string) (string, error) {
+	spans := m.linkSpans(content)
+	if len(spans) == 0 {
+		return m.markdownRenderer.Render(content)
+	}
+
+	// The renderer styles and wraps punctuation separately, so each span is
+	// swapped for a same-width word and restored afterwards. Longer spans go
+	// first so a path inside a longer path is not replaced on its own.
+	sort.SliceStable(spans, func(i, j int) bool { return len(spans[i].raw) > len(spans[j].raw) })
+	placeholders := make([]string, len(spans))
+	for i, span := range spans {
+		placeholders[i] = linkPlaceholder(i, len(span.raw))
+		content = strings.ReplaceAll(content, span.raw, placeholders[i])
+	}
+
+	rendered, err := m.markdownRenderer.Render(content)
+	if err != nil {
+		return "", err
+	}
+
+	for i, span := range spans {
+		text := span.raw
+		if span.citation {
+			text = CitationStyle.Render(text)
+		}
+		if span.target != "" {
+			text = hyperlink(span.target, text)
+		}
+		rendered = strings.ReplaceAll(rendered, placeholders[i], text)
+	}
+	return rendered, nil
+}
+
+// linkSpans finds the citations, URLs, and file paths in content worth linking
+func (m Model) linkSpans(content string) []linkSpan {
+	var spans []linkSpan
+	seen := make(map[string]bool)
+
+	for _, c := range files.ParseCitations(content) {
+		path, ok := m.existingFile(c.Path)
+		if !ok {
+			continue
+		}
+		seen[c.Raw] = true
+		span := linkSpan{raw: c.Raw, citation: true}
+		if m.hyperlinks {
+			span.target = m.fileURL(path, c.Start)
+		}
+		spans = append(spans, span)
+	}
+	if !m.hyperlinks {
+		return spans
+	}
+
+	for _, raw := range urlRe.FindAllString(content, -1) {
+		raw = strings.TrimRight(raw, ".,;:!?")
+		if !seen[raw] {
+			seen[raw] = true
+			spans = append(spans, linkSpan{raw: raw, target: raw})
+		}
+	}
+
+	for _, ref := range m.fileRefs(content) {
+		if !seen[ref.raw] {
+			seen[ref.raw] = true
+			spans = append(spans, linkSpan{raw: ref.raw, target: m.fileURL(ref.path, ref.line)})
+		}
+	}
+	return spans
+}
+
+// fileRef is a file mentioned in output, with the line when known
+type fileRef struct {
+	raw  string // Text as it appears in the output
+	path string // Absolute path
+	line int    // 1-based, 0 when unknown
+}
+
+// fileRefs returns the existing files that content mentions by path
+// (optionally with a :line suffix), in order of first mention
+func (m Model) fileRefs(content string) []fileRef {
+	var refs []fileRef
+	seen := make(map[string]bool)
+	for _, word := range strings.Fields(content) {
+		raw := strings.Trim(word, "`'\"()[]<>*,;!?")
+		raw = strings.TrimRight(raw, ".:")
+		if seen[raw] || strings.Contains(raw, "://") || !strings.ContainsAny(raw, "/.") {
+			continue
+		}
+		seen[raw] = true
+		parts := pathLineRe.FindStringSubmatch(raw)
+		path, ok := m.existingFile(parts[1])
+		if !ok {
+			continue
+		}
+		line, _ := strconv.Atoi(parts[2])
+		refs = append(refs, fileRef{raw: raw, path: path, line: line})
+	}
+	return refs
+}
+
+// existingFile resolves path against the working directory and reports
+// whether it names a regular file
+func (m Model) existingFile(path string) (string, bool) {
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", false
+		}
+		path = filepath.Join(home, path[2:])
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.shellCtx.CWD, path)
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return "", false
+	}
+	return path, true
+}
+
+// fileURL links to path, at line when known, using the configured editor
+// URL template or a file:// URL
+func (m Model) fileURL(path string, line int) string {
+	if m.editorURL == "" {
+		return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+	}
+	if line == 0 {
+		line = 1
+	}
+	return strings.NewReplacer(
+		"{path}", filepath.ToSlash(path),
+		"{line}", fmt.Sprint(line),
+	).Replace(m.editorURL)
+}
+
+// linkPlaceholder returns a letters-only word of the given width that is
+// unlikely to appear in an answer
+func linkPlaceholder(index, width int) string {
+	id := "Zq" + string(rune('a'+index/26%26)) + string(rune('a'+index%26))
+	if width > len(id) {
+		id += strings.Repeat("q", width-len(id))
+	}
+	return id
+}
+
+// hyperlink wraps text in an OSC 8 terminal hyperlink to target
+func hyperlink(target, text string) string {
+	return "\x1b]8;;" + target + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
+
+// hyperlinksEnabled resolves the hyperlinks setting ("auto", "on", "off")
+func hyperlinksEnabled(mode string) bool {
+	switch strings.ToLower(mode) {
+	case config.HyperlinksOn, "1", "true":
+		return true
+	case config.HyperlinksOff, "0", "false":
+		return false
+	}
+	return hyperlinksSupported()
+}
+
+// hyperlinksSupported reports whether the terminal is known to render OSC 8 hyperlinks
+func hyperlinksSupported() bool {
+	switch os.Getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "ghostty", "Hyper", "rio":
+		return true
+	}
+	if os.Getenv("WT_SESSION") != "" || os.Getenv("KITTY_WINDOW_ID") != "" || os.Getenv("KONSOLE_VERSION") != "" {
+		return true
+	}
+	// GNOME Terminal and other VTE terminals since 0.50
+	if vte, err := strconv.Atoi(os.Getenv("VTE_VERSION")); err == nil && vte >= 5000 {
+		return true
+	}
+	term := os.Getenv("TERM")
+	return term == "xterm-kitty" || term == "foot" || strings.HasPrefix(term, "alacritty")
+}
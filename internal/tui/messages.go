@@ -1,12 +1,22 @@
 package tui
 
 import (
+	tea "github.com/charmbracelet/bubbletea"
+
 	"github.com/bastio-ai/bast/internal/ai"
+	"github.com/bastio-ai/bast/internal/files"
+	"github.com/bastio-ai/bast/internal/tools"
 )
 
 // CommandGeneratedMsg is sent when the AI generates a command
 type CommandGeneratedMsg struct {
-	Result *ai.CommandResult
+	Result  *ai.CommandResult
+	Query   string
+	Context *ai.ContextPreview // What the request sent, shown with s in confirm mode
+
+	// SyntaxErr is set when the command still doesn't parse in the user's
+	// shell after one correction was asked for
+	SyntaxErr error
 }
 
 // CommandExplainedMsg is sent when the AI explains a command
@@ -38,6 +48,12 @@ func (e ErrorMsg) Error() string {
 // SuggestionsMsg is sent when file search results are ready
 type SuggestionsMsg struct {
 	Suggestions []string
+	StaleIndex  bool // The file index was out of date and should be rebuilt
+}
+
+// FileIndexMsg is sent when the @mention suggestion index is ready
+type FileIndexMsg struct {
+	Index *files.Index
 }
 
 // ModelSelectedMsg is sent when a model is selected
@@ -53,11 +69,82 @@ type AgentResponseMsg struct {
 
 // ToolCallMsg is sent during agentic execution for each tool call
 type ToolCallMsg struct {
-	Call ai.ToolCall
+	Call    ai.ToolCall
+	updates chan tea.Msg
+}
+
+// ToolProgressMsg is sent while a tool runs with its latest output lines
+type ToolProgressMsg struct {
+	Call    ai.ToolCall
+	Tail    []string
+	updates chan tea.Msg
+}
+
+// SecurityConfirmMsg is sent when the agent is paused on a tool call that a
+// validator flagged with a warning, waiting for the user to approve it
+type SecurityConfirmMsg struct {
+	Call    tools.Call
+	Message string
+	Threats []string
+	reply   chan bool
+	prompts chan SecurityConfirmMsg
+}
+
+// InjectionFlaggedMsg is sent instead of a request when files attached to
+// the query contain text that looks like instructions for the model
+type InjectionFlaggedMsg struct {
+	Query    string
+	Intent   *ai.IntentResult // Routing for the query once the user decides
+	Findings []string         // "path: marker, marker" per flagged file
+}
+
+// ShellContextLoadedMsg is sent when background context gathering completes
+type ShellContextLoadedMsg struct {
+	Context ai.ShellContext
 }
 
 // FixResultMsg is sent when fix command analysis completes
 type FixResultMsg struct {
-	Result    *ai.FixResult
-	FailedCmd string
+	Result      *ai.FixResult
+	FailedCmd   string
+	ExitMeaning string // Local explanation of the exit status, if known
+}
+
+// SyntaxCheckedMsg is sent when an accepted command has been parsed by the
+// user's shell
+type SyntaxCheckedMsg struct {
+	Command string
+	Mode    Mode // View the command was accepted from
+	Err     error
+}
+
+// StepRanMsg is sent when a command from an answer's checklist exits
+type StepRanMsg struct {
+	Command string
+	Err     error
+}
+
+// EditorClosedMsg is sent when the editor opened on a referenced file exits
+type EditorClosedMsg struct {
+	Path string
+	Err  error
+}
+
+// RateLimitTickMsg advances the countdown before retrying a rate-limited request
+type RateLimitTickMsg struct {
+	Wait int // The countdown this tick belongs to
+}
+
+// CompactedMsg is sent when /compact has summarized the older conversation
+type CompactedMsg struct {
+	Summary  string
+	Replaced int  // Number of leading history messages the summary replaces
+	Mode     Mode // Mode to return to
+}
+
+// ContextPreviewMsg is sent when /context has assembled what a request would send
+type ContextPreviewMsg struct {
+	Preview *ai.ContextPreview
+	Kind    string // "command" or "chat"
+	Mode    Mode   // Mode to return to
 }
@@ -1,15 +1,26 @@
 package tui
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
+	"github.com/google/uuid"
 
 	"github.com/bastio-ai/bast/internal/ai"
+	"github.com/bastio-ai/bast/internal/config"
 	"github.com/bastio-ai/bast/internal/files"
+	"github.com/bastio-ai/bast/internal/notify"
+	"github.com/bastio-ai/bast/internal/safety"
+	"github.com/bastio-ai/bast/internal/session"
 	"github.com/bastio-ai/bast/internal/shell"
+	"github.com/bastio-ai/bast/internal/target"
+	"github.com/bastio-ai/bast/internal/workspace"
 )
 
 // Mode represents the current TUI mode
@@ -19,10 +30,17 @@ const (
 	ModeInput Mode = iota
 	ModeLoading
 	ModeConfirm
-	ModeChat        // Display chat response
-	ModeModelSelect // Model selection menu
-	ModeAgent       // Agentic task execution
-	ModeFix         // Fix failed command
+	ModeChat            // Display chat response
+	ModeModelSelect     // Model selection menu
+	ModeAgent           // Agentic task execution
+	ModeFix             // Fix failed command
+	ModeIntentChoice    // Ask user to pick command vs chat for low-confidence intents
+	ModeSecurityConfirm // Ask user to approve a tool call flagged with a warning
+	ModeWorkspaceSelect // Pick the workspace member to scope bast to
+	ModeOpenSelect      // Pick which referenced file to open in the editor
+	ModeInjectionFlag   // Ask before sending attached files that look like prompt injection
+	ModeContextPreview  // Show what the next request would send (/context)
+	ModeSteps           // Pick commands from a chat answer to run
 )
 
 // Model is the main Bubble Tea model
@@ -32,6 +50,7 @@ type Model struct {
 	spinner   spinner.Model
 	provider  ai.Provider
 	shellCtx  ai.ShellContext
+	lazyCtx   *lazyShellContext // Full context, gathered after the first render
 
 	// Command state
 	command         string
@@ -42,6 +61,42 @@ type Model struct {
 	isDangerous     bool   // True if current command matches dangerous patterns
 	dangerConfirmed bool   // True if user has confirmed a dangerous command
 
+	// Sudo handling
+	needsSudo        bool   // True if current command runs through sudo
+	sudoAcknowledged bool   // True if user has acknowledged elevated privileges
+	sudoPolicy       string // config.SudoConfirm, SudoStrip, or SudoForbid
+
+	// Suggested rewrite of the command for this OS, applied only on request
+	translation *shell.Translation
+
+	// Safer rewrite of rm/mv, applied automatically when enabled in config
+	safeRewrite bool
+	rewrite     *safety.Rewrite
+
+	// Binaries the command needs that are not on PATH, with an install step
+	missing *shell.MissingBinaries
+
+	// Set when the command doesn't parse in the user's shell, which blocks the handoff
+	syntaxErr      error
+	checkingSyntax bool // An accepted command is being parsed before the handoff
+
+	// Aliases or functions the command's words resolve to in the user's
+	// shell, environments it needs that aren't active, date flags for
+	// another OS, and paths it reads that don't exist (see commandWarnings)
+	aliasWarnings []string
+
+	// What the last generation sent, expanded with s in confirm mode
+	sentContext     *ai.ContextPreview
+	showSentContext bool
+
+	// Directory the generated command must run in (absolute), from the
+	// generator's working_dir hint; empty to run it where bast is
+	workDir string
+
+	// Ranked alternatives for the last generation, paged through with Tab
+	candidates     []candidate
+	candidateIndex int
+
 	// Display dimensions
 	width  int
 	height int
@@ -50,22 +105,91 @@ type Model struct {
 	initialQuery string
 	outputFile   string // Path to write BAST_COMMAND output (for shell integration)
 
+	// Shell handoff state
+	handoffVersion int    // Output file format understood by the shell hook
+	commandEdited  bool   // True once the user edited a generated command
+	editedFrom     string // Command the user started editing, compared with d
+
+	// Directory the user's shell is in; set by --dir and /cd apart from shellCtx.CWD
+	launchDir string
+
+	// NAME=value overrides set with /env for handed-off and agent commands, sorted
+	sessionEnv []string
+
+	// Saved record of this session's chat and agent turns (sessions.save)
+	saveSession bool
+	transcript  *session.Session // Nil until the first turn
+
+	// Attach machine load readings to performance questions (context.probes)
+	probes bool
+
+	// Most recent generation, rated with g/b or /feedback
+	generatedQuery   string
+	generatedCommand string
+	feedbackShare    bool   // Also send ratings to Bastio (feedback.share)
+	feedbackNotice   string // Confirmation shown after feedback is recorded
+
+	// OSC 8 links for paths and URLs in chat and agent output
+	hyperlinks bool
+	editorURL  string // e.g. "vscode://file/{path}:{line}"; empty for file:// links
+
+	// Bell and frame flash when bast needs attention
+	notifier *notify.Notifier
+	flashing bool // True while the frame flashes
+	flash    int  // Counts flashes, so only the latest one's FlashEndMsg ends it
+
+	// Inline rendering beneath the prompt, switching to the full screen for
+	// chat and agent sessions
+	inline     bool
+	fullscreen bool // True once an inline session has entered the alternate screen
+
+	// Shown in the status bar
+	sessionID  string          // Groups this session's agent tool calls in Bastio
+	executor   target.Executor // Where commands run; nil for this machine
+	gateway    string          // GatewayBastio or GatewayDirect
+	safetyMode string          // "safe" or "yolo"
+
 	// Loading state
 	loadingMessage string // Current operation being performed
 
 	// Autocomplete state
-	showSuggestions  bool
-	suggestions      []string
-	selectedIndex    int
-	mentionStart     int    // Position of "@" in input
-	lastMentionText  string // Last searched mention text (to avoid duplicate searches)
-	searchingFiles   bool   // True while file search is in progress
+	showSuggestions bool
+	suggestions     []string
+	selectedIndex   int
+	mentionStart    int          // Position of "@" in input
+	lastMentionText string       // Last searched mention text (to avoid duplicate searches)
+	searchingFiles  bool         // True while file search is in progress
+	fileIndex       *files.Index // Prefetched suggestion index, nil until built
+	indexingFiles   bool         // True while the suggestion index is being built
 
 	// Conversation history for multi-turn chat
 	conversationHistory []ai.ConversationMessage
+	followUps           []string // Suggested next requests from the last answer, picked with Alt+1-3
+
+	// Commands the last chat answer told the user to run (Ctrl+X)
+	answerCommands []string
+	stepCursor     int
+	stepChecked    []bool
+	stepRun        bool     // The confirm view shows a command from the checklist
+	stepQueue      []string // Checked commands still to confirm and run, in order
+
+	// Conversation branching: edit an earlier message and resend it
+	editSelecting bool                       // True while picking a user message to edit
+	editCursor    int                        // History index of the highlighted user message
+	editing       bool                       // True while the input holds an edited message
+	editIndex     int                        // History index of the message being edited
+	chatBranches  [][]ai.ConversationMessage // Abandoned branches, kept for recovery
+	branchIDs     []int                      // IDs of chatBranches, in the same order
+	branch        int                        // ID of the current branch; 0 is the original
+	lastBranch    int                        // Highest branch ID handed out so far
+
+	// Pinned context included in every prompt for the rest of the session
+	pinned []ai.PinnedItem
 
 	// Markdown renderer for chat responses
 	markdownRenderer *glamour.TermRenderer
+	rendererWidth    int // Word-wrap width markdownRenderer was built for; 0 until the first resize
+	resizes          int // Counts resizes, so only the last of a burst rebuilds the renderer
 
 	// Viewport for scrollable chat content
 	chatViewport  viewport.Model
@@ -77,17 +201,71 @@ type Model struct {
 	customModelInput bool   // true when typing custom model ID
 	currentModel     string // loaded from config on init
 
+	// Workspace picker state; cursor 0 is the workspace root
+	workspace       *workspace.Workspace
+	workspaceCursor int
+
 	// Slash command menu state
 	showSlashMenu bool
 	slashCommands []SlashCommand
 	slashCursor   int
 
 	// Agent mode state
-	agentResult    *ai.AgentResult // Result of agentic execution
-	agentToolCalls []ai.ToolCall   // Live tool calls during execution
+	agentResult    *ai.AgentResult  // Result of agentic execution
+	agentToolCalls []ai.ToolCall    // Live tool calls during execution
+	agentProgress  *ToolProgressMsg // Latest output of the running tool
+
+	// Line budgets for tool output sent to the model and shown per call
+	promptOutputLines  int
+	displayOutputLines int
 
 	// Fix mode state
-	fixResult *ai.FixResult // Result of fix command analysis
+	fixResult      *ai.FixResult // Result of fix command analysis
+	fixExitMeaning string        // What the failed command's exit status means
+
+	// Intent routing state
+	intentThreshold    float64             // Below this confidence, ask the user instead of guessing
+	intentOverrides    *ai.IntentOverrides // Remembered user choices for similar queries
+	intentChoiceCursor int                 // 0 = run a command, 1 = just answer
+	pendingIntent      *ai.IntentResult    // Low-confidence classification awaiting user choice
+
+	// Intent every query is routed to without classification; empty classifies
+	forcedIntent ai.Intent
+
+	// Ignore the user's config for agent runs: local validation only, no
+	// hooks, plugins, or transcripts (the demo)
+	isolated bool
+
+	// Provider connection prewarm mode (config.PrewarmOff, PrewarmConnect, or PrewarmPing)
+	prewarm string
+
+	// Last submitted input, restored for retry after transient errors
+	lastInput string
+
+	// Rate limit countdown shown in the loading view before retrying lastInput
+	rateLimitErr     error     // The 429 being waited out; nil when not waiting
+	rateLimitUntil   time.Time // When the automatic retry fires
+	rateLimitWait    int       // Identifies the current countdown so stale ticks are dropped
+	rateLimitRetries int       // Automatic retries since the user last submitted
+	fallbackModel    string    // Smaller model offered while waiting; empty if none
+
+	// Agent tool call awaiting approval after a security warning
+	securityPrompt *SecurityConfirmMsg
+
+	// Query held back because its attached files look like prompt injection
+	injectionFlag *InjectionFlaggedMsg
+	injectionAck  bool // Send the next query's files even when flagged
+
+	// Open-in-editor state
+	editor     string    // Configured editor command; empty uses $VISUAL/$EDITOR
+	openRefs   []fileRef // Files offered by the open picker
+	openCursor int
+	openReturn Mode // Mode to return to after picking
+
+	// /context state
+	contextPreview *ai.ContextPreview
+	contextKind    string // "command" or "chat"
+	contextReturn  Mode   // Mode to return to when dismissed
 
 }
 
@@ -105,7 +283,8 @@ func NewModel(provider ai.Provider, initialQuery string, outputFile string) Mode
 	s.Spinner = spinner.Dot
 	s.Style = SpinnerStyle
 
-	shellCtx := shell.GetContext()
+	// Git context is gathered in the background so the input renders immediately
+	lazyCtx := newLazyShellContext(shell.GetBaseContext())
 
 	// Initialize markdown renderer with dark style
 	// Note: WithAutoStyle() sends OSC escape sequences that conflict with Bubble Tea
@@ -115,21 +294,33 @@ func NewModel(provider ai.Provider, initialQuery string, outputFile string) Mode
 		glamour.WithWordWrap(80),
 	)
 
+	// Load remembered intent choices (best-effort)
+	var overrides *ai.IntentOverrides
+	if path, err := ai.DefaultIntentOverridesPath(); err == nil {
+		overrides = ai.LoadIntentOverrides(path)
+	}
+
 	m := Model{
 		mode:             ModeInput,
 		textInput:        ti,
 		spinner:          s,
 		provider:         provider,
-		shellCtx:         shellCtx,
+		shellCtx:         lazyCtx.base,
+		lazyCtx:          lazyCtx,
 		initialQuery:     initialQuery,
 		outputFile:       outputFile,
+		handoffVersion:   shell.HandoffV1,
 		markdownRenderer: renderer,
+		intentThreshold:  config.DefaultIntentThreshold,
+		intentOverrides:  overrides,
+		sessionID:        uuid.New().String(),
 	}
 
 	// If initial query provided, set it and prepare loading message
 	if initialQuery != "" {
 		ti.SetValue(initialQuery)
 		m.textInput = ti
+		m.lastInput = initialQuery
 		m.loadingMessage = "Classifying intent..."
 	}
 
@@ -138,7 +329,10 @@ func NewModel(provider ai.Provider, initialQuery string, outputFile string) Mode
 
 // Init implements tea.Model
 func (m Model) Init() tea.Cmd {
-	cmds := []tea.Cmd{textinput.Blink}
+	cmds := []tea.Cmd{textinput.Blink, m.lazyCtx.load(), m.prefetchFileIndex()}
+	if cmd := m.prewarmProvider(); cmd != nil {
+		cmds = append(cmds, cmd)
+	}
 
 	// If we have an initial query, start classifying intent immediately
 	if m.initialQuery != "" {
@@ -151,49 +345,70 @@ func (m Model) Init() tea.Cmd {
 
 // Update implements tea.Model
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	if nm, ok := next.(Model); ok {
+		return nm.enterFullscreen(cmd)
+	}
+	return next, cmd
+}
+
+// update handles a message; Update wraps it with work common to every message
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		return m.handleKeyMsg(msg)
 
 	case tea.WindowSizeMsg:
-		m.width = msg.Width
-		m.height = msg.Height
-		// Re-create markdown renderer with new width
-		contentWidth := ContentWidth(msg.Width)
-		renderer, _ := glamour.NewTermRenderer(
-			glamour.WithStylePath("dark"),
-			glamour.WithWordWrap(contentWidth),
-		)
-		m.markdownRenderer = renderer
-
-		// Calculate viewport height (total - frame border/padding - header - input area)
-		viewportHeight := msg.Height - 12 // Approximate: 2 border + 4 padding + 3 header + 3 input
-		if viewportHeight < 1 {
-			viewportHeight = 1
-		}
+		return m.resize(msg)
 
-		if !m.viewportReady {
-			m.chatViewport = viewport.New(contentWidth, viewportHeight)
-			m.viewportReady = true
-		} else {
-			m.chatViewport.Width = contentWidth
-			m.chatViewport.Height = viewportHeight
+	case ResizeSettledMsg:
+		if msg.Resize != m.resizes {
+			return m, nil
 		}
+		return m.rebuildRenderer(), nil
 
-		if m.mode == ModeChat {
-			m.chatViewport.SetContent(m.renderConversationContent())
-		}
-		return m, nil
+	case tea.ResumeMsg:
+		return m.resume()
 
 	case CommandGeneratedMsg:
+		generated := m.newCandidate(msg.Result.Command, msg.Result.WorkingDir)
+		generatedCandidates := []candidate{generated}
+		for _, alt := range msg.Result.Alternatives {
+			generatedCandidates = append(generatedCandidates, m.newCandidate(alt.Command, alt.WorkingDir))
+		}
+		candidates, err := m.rankCandidates(generatedCandidates)
+		if err != nil {
+			return m, func() tea.Msg { return ErrorMsg{Err: err} }
+		}
+		m.candidates = candidates
+		m = m.showCandidate(0)
+		if !m.commandEdited {
+			m.editedFrom = ""
+		}
+		// A newly generated command ends any checklist run
+		m.stepRun = false
+		m.stepQueue = nil
 		m.mode = ModeConfirm
-		m.command = msg.Result.Command
-		m.explanation = msg.Result.Explanation
-		m.isDangerous = isDangerousCommand(msg.Result.Command)
-		m.dangerConfirmed = false
+		// An edited command comes back as a new query; keep the query the
+		// edit started from, so what is run is learned for that query
+		if !m.commandEdited || m.generatedQuery == "" {
+			m.generatedQuery = msg.Query
+		}
+		m.sentContext = msg.Context
+		m.showSentContext = false
+		m.feedbackNotice = ""
+		if candidates[0].command == generated.command {
+			m.explanation = msg.Result.Explanation
+			m.syntaxErr = msg.SyntaxErr
+		}
 		m.textInput.SetValue("") // Clear any previous input
 		m.textInput.Focus()      // Ready for follow-up questions
 		m.resetAutocomplete()
+		if m.isDangerous {
+			var alert tea.Cmd
+			m, alert = m.alert(notify.EventDanger)
+			return m, tea.Batch(textinput.Blink, alert)
+		}
 		return m, textinput.Blink
 
 	case CommandExplainedMsg:
@@ -201,23 +416,30 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case IntentClassifiedMsg:
-		if msg.Result.Intent == ai.IntentChat {
-			// Route to chat handler, passing intent result for history detection
-			m.loadingMessage = "Getting response..."
-			return m, m.chat(msg.Query, msg.Result)
+		// Ask instead of guessing when the classifier is unsure
+		if msg.Result.Confidence < m.intentThreshold {
+			m.mode = ModeIntentChoice
+			m.pendingQuery = msg.Query
+			m.pendingIntent = msg.Result
+			m.intentChoiceCursor = 0
+			if msg.Result.Intent == ai.IntentChat {
+				m.intentChoiceCursor = 1
+			}
+			return m, nil
 		}
-		// Default to command generation
-		m.loadingMessage = "Generating command..."
-		return m, m.generateCommand(msg.Query)
+		return m.routeIntent(msg.Query, msg.Result)
 
 	case ChatResponseMsg:
 		m.mode = ModeChat
 		m.chatResponse = msg.Result.Response
+		m.followUps = msg.Result.FollowUps
+		m.answerCommands = ai.ExtractCommands(msg.Result.Response)
 		// Append to conversation history (strip mentions to avoid policy violations in future context)
 		m.conversationHistory = append(m.conversationHistory,
 			ai.ConversationMessage{Role: "user", Content: files.StripMentions(msg.Query)},
 			ai.ConversationMessage{Role: "assistant", Content: msg.Result.Response},
 		)
+		save := m.recordTurn("chat", msg.Query, msg.Result.Response, nil, ai.ThreatSummary{})
 		m.textInput.SetValue("") // Clear input for follow-up
 		m.textInput.Focus()      // Ready for follow-up
 		m.resetAutocomplete()
@@ -226,11 +448,23 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.chatViewport.SetContent(m.renderConversationContent())
 			m.chatViewport.GotoBottom()
 		}
-		return m, textinput.Blink
+		return m, tea.Batch(textinput.Blink, save)
 
 	case ErrorMsg:
+		// Wait out rate limits and retry instead of giving up
+		var rateLimited *ai.ErrRateLimited
+		if errors.As(msg.Err, &rateLimited) && m.lastInput != "" && m.rateLimitRetries < maxRateLimitRetries {
+			return m.waitForRateLimit(rateLimited)
+		}
 		m.err = msg.Err
 		m.mode = ModeInput
+		m.agentToolCalls = nil
+		m.agentProgress = nil
+		// Pre-fill the failed input so Enter retries it
+		if ai.IsRetryable(msg.Err) && m.textInput.Value() == "" {
+			m.textInput.SetValue(m.lastInput)
+			m.textInput.CursorEnd()
+		}
 		return m, nil
 
 	case SuggestionsMsg:
@@ -238,6 +472,17 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.selectedIndex = 0
 		m.showSuggestions = len(msg.Suggestions) > 0
 		m.searchingFiles = false
+		if msg.StaleIndex && !m.indexingFiles {
+			m.indexingFiles = true
+			return m, m.prefetchFileIndex()
+		}
+		return m, nil
+
+	case FileIndexMsg:
+		m.indexingFiles = false
+		if msg.Index.Root == m.shellCtx.CWD {
+			m.fileIndex = msg.Index
+		}
 		return m, nil
 
 	case ModelSelectedMsg:
@@ -250,24 +495,73 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.textInput.Focus()
 		return m, textinput.Blink
 
+	case ShellContextLoadedMsg:
+		// Ignore context gathered for a directory left with /cd
+		if msg.Context.CWD != m.lazyCtx.base.CWD {
+			return m, nil
+		}
+		m.shellCtx = msg.Context
+		return m, nil
+
+	case SyntaxCheckedMsg:
+		return m.acceptCommand(msg)
+
+	case StepRanMsg:
+		return m.stepRan(msg)
+
+	case EditorClosedMsg:
+		if msg.Err != nil {
+			m.err = fmt.Errorf("editor failed on %s: %w", msg.Path, msg.Err)
+		}
+		return m, nil
+
+	case RateLimitTickMsg:
+		return m.rateLimitTicked(msg)
+
+	case SecurityConfirmMsg:
+		m.mode = ModeSecurityConfirm
+		m.securityPrompt = &msg
+		return m.alert(notify.EventApproval)
+
+	case InjectionFlaggedMsg:
+		m.mode = ModeInjectionFlag
+		m.injectionFlag = &msg
+		return m.alert(notify.EventApproval)
+
+	case FlashEndMsg:
+		if msg.Flash == m.flash {
+			m.flashing = false
+		}
+		return m, nil
+
 	case ToolCallMsg:
 		// Append tool call to live list during agent execution
 		m.agentToolCalls = append(m.agentToolCalls, msg.Call)
+		m.agentProgress = nil
 		// Update viewport content with new tool call
 		if m.viewportReady {
 			m.chatViewport.SetContent(m.renderAgentContent())
 			m.chatViewport.GotoBottom()
 		}
-		return m, nil
+		return m, waitForAgentUpdate(msg.updates)
+
+	case ToolProgressMsg:
+		if m.mode == ModeLoading {
+			m.agentProgress = &msg
+		}
+		return m, waitForAgentUpdate(msg.updates)
 
 	case AgentResponseMsg:
 		m.mode = ModeAgent
 		m.agentResult = msg.Result
+		m.agentProgress = nil
+		m.followUps = msg.Result.FollowUps
 		// Append to conversation history
 		m.conversationHistory = append(m.conversationHistory,
 			ai.ConversationMessage{Role: "user", Content: msg.Query},
 			ai.ConversationMessage{Role: "assistant", Content: msg.Result.Response},
 		)
+		save := m.recordTurn("agent", msg.Query, msg.Result.Response, msg.Result.ToolCalls, msg.Result.Threats)
 		m.textInput.SetValue("")
 		m.textInput.Focus()
 		m.resetAutocomplete()
@@ -276,17 +570,38 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.chatViewport.SetContent(m.renderAgentContent())
 			m.chatViewport.GotoBottom()
 		}
-		return m, textinput.Blink
+		var alert tea.Cmd
+		m, alert = m.alert(notify.EventDone)
+		return m, tea.Batch(textinput.Blink, alert, save)
+
+	case CompactedMsg:
+		return m.applyCompaction(msg), textinput.Blink
+
+	case ContextPreviewMsg:
+		m.contextPreview = msg.Preview
+		m.contextKind = msg.Kind
+		m.contextReturn = msg.Mode
+		m.mode = ModeContextPreview
+		return m, nil
 
 	case FixResultMsg:
-		m.mode = ModeFix
-		m.fixResult = msg.Result
 		// If a fix was found, set it as the pending command
 		if msg.Result.WasFixed && msg.Result.FixedCommand != "" {
-			m.command = msg.Result.FixedCommand
-			m.isDangerous = isDangerousCommand(msg.Result.FixedCommand)
+			command, err := m.applySudoPolicy(msg.Result.FixedCommand)
+			if err != nil {
+				return m, func() tea.Msg { return ErrorMsg{Err: err} }
+			}
+			m.command = command
+			m.workDir = ""
+			m.syntaxErr = nil
+			m.isDangerous = isDangerousCommand(command)
 			m.dangerConfirmed = false
+			m.needsSudo = safety.NeedsSudo(command)
+			m.sudoAcknowledged = false
 		}
+		m.mode = ModeFix
+		m.fixResult = msg.Result
+		m.fixExitMeaning = msg.ExitMeaning
 		m.textInput.SetValue("")
 		m.textInput.Focus()
 		m.resetAutocomplete()
@@ -304,6 +619,59 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 }
 
+// routeIntent dispatches a classified query to chat or command generation
+func (m Model) routeIntent(query string, result *ai.IntentResult) (tea.Model, tea.Cmd) {
+	m.mode = ModeLoading
+	if result.Intent == ai.IntentChat {
+		// Route to chat handler, passing intent result for history detection
+		m.loadingMessage = "Getting response..."
+		return m, m.chat(query, result)
+	}
+	if result.Intent == ai.IntentAgent {
+		m.loadingMessage = "Running agent..."
+		m.pendingQuery = query
+		m.agentToolCalls = nil
+		m.agentProgress = nil
+		m.agentResult = nil
+		return m, m.runAgent(query)
+	}
+	// Default to command generation
+	m.loadingMessage = "Generating command..."
+	return m, m.generateCommand(query)
+}
+
+// SetIntentThreshold sets the minimum confidence for automatic intent routing
+func (m *Model) SetIntentThreshold(threshold float64) {
+	m.intentThreshold = threshold
+}
+
+// SetForcedIntent routes every query to intent without classifying it.
+// An empty intent restores automatic classification.
+func (m *Model) SetForcedIntent(intent ai.Intent) {
+	m.forcedIntent = intent
+}
+
+// SetIsolated runs agent tasks without the user's config: only the local
+// validator, and no lifecycle hooks, user plugins, or transcripts. The demo
+// uses it so a canned run never reaches the user's hooks or Bastio.
+func (m *Model) SetIsolated(isolated bool) {
+	m.isolated = isolated
+}
+
+// SetFallbackModel sets the smaller model offered while rate limited.
+// Nothing is offered when it is the model already in use.
+func (m *Model) SetFallbackModel(model, current string) {
+	if model == current {
+		model = ""
+	}
+	m.fallbackModel = model
+}
+
+// SetPrewarm sets how the provider connection is opened before the first request
+func (m *Model) SetPrewarm(mode string) {
+	m.prewarm = mode
+}
+
 // resetAutocomplete clears all autocomplete state
 func (m *Model) resetAutocomplete() {
 	m.showSuggestions = false
@@ -318,3 +686,104 @@ func (m *Model) resetAutocomplete() {
 func (m Model) SelectedCommand() string {
 	return m.command
 }
+
+// SetHyperlinks sets whether paths and URLs in output are terminal links
+// ("auto", "on", or "off") and the editor URL template files open with
+func (m *Model) SetHyperlinks(mode, editorURL string) {
+	m.hyperlinks = hyperlinksEnabled(mode)
+	m.editorURL = editorURL
+}
+
+// SetToolOutputLines sets how many lines of agent command output go to the
+// model and how many are shown per tool call. 0 uses the defaults.
+func (m *Model) SetToolOutputLines(prompt, display int) {
+	m.promptOutputLines = prompt
+	m.displayOutputLines = display
+}
+
+// SetEditor sets the editor command referenced files open in
+func (m *Model) SetEditor(editor string) {
+	m.editor = editor
+}
+
+// SetFeedbackSharing sets whether ratings are also sent to Bastio
+func (m *Model) SetFeedbackSharing(share bool) {
+	m.feedbackShare = share
+}
+
+// SetSafeRewrite enables rewriting rm and mv in generated commands to safer forms
+func (m *Model) SetSafeRewrite(enabled bool) {
+	m.safeRewrite = enabled
+}
+
+// SetSudoPolicy sets how generated commands that need sudo are handled
+func (m *Model) SetSudoPolicy(policy string) {
+	m.sudoPolicy = policy
+}
+
+// SetHandoffVersion sets the output format written for the shell hook
+func (m *Model) SetHandoffVersion(version int) {
+	m.handoffVersion = version
+}
+
+// SetLaunchDir sets the directory the user's shell runs handed-off commands
+// from. Commands generated for another directory (--dir, /cd) cd into it first.
+func (m *Model) SetLaunchDir(dir string) {
+	m.launchDir = dir
+}
+
+// changeDir moves bast to dir and regathers the shell context there.
+// An empty dir returns to the directory bast was started in.
+func (m Model) changeDir(dir string) (Model, tea.Cmd, error) {
+	if dir == "" {
+		dir = m.launchDir
+	}
+	if _, err := shell.ChangeDir(dir); err != nil {
+		return m, nil, err
+	}
+	m.lazyCtx = newLazyShellContext(shell.GetBaseContext())
+	m.shellCtx = m.lazyCtx.base
+	m.fileIndex = nil
+	return m, tea.Batch(m.lazyCtx.load(), m.prefetchFileIndex()), nil
+}
+
+// handOffCommand passes the accepted command back to the shell hook
+func (m Model) handOffCommand() {
+	m.writeHandoff(m.handoff())
+}
+
+// handoff builds the handoff for the accepted command, as the user's shell
+// runs it from launchDir. A v2 hook changes to working_dir itself, so the
+// command is left as the user saw it; a v1 line can only carry the change
+// of directory in the command.
+func (m Model) handoff() shell.Handoff {
+	if m.remoteTarget() {
+		command := m.executor.Handoff(m.command, m.workDir, m.sessionEnv)
+		return shell.NewHandoff(command, m.shellCtx.CWD, m.isDangerous, m.commandEdited)
+	}
+
+	dir := m.shellCtx.CWD
+	if m.workDir != "" {
+		dir = m.workDir
+	}
+	command := shell.WithEnv(m.command, m.sessionEnv)
+	if m.handoffVersion < shell.HandoffV2 {
+		command = shell.InDir(command, dir, m.launchDir)
+	}
+	h := shell.NewHandoff(command, m.shellCtx.CWD, m.isDangerous, m.commandEdited)
+	if dir != m.launchDir {
+		h.WorkDir = dir
+	}
+	return h
+}
+
+// writeHandoff passes h to the shell hook through the output file, or
+// prints it when there is none
+func (m Model) writeHandoff(h shell.Handoff) {
+	if m.outputFile != "" {
+		shell.WriteHandoff(m.outputFile, m.handoffVersion, h)
+		return
+	}
+	data, _ := h.Encode(m.handoffVersion)
+	fmt.Printf("%s\n", data)
+}
@@ -0,0 +1,46 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/bastio-ai/bast/internal/notify"
+)
+
+// flashDuration is how long the frame flashes for a visual bell
+const flashDuration = 300 * time.Millisecond
+
+// FlashEndMsg ends a frame flash. Flash is the flash it ends, so an older
+// flash doesn't cut a newer one short.
+type FlashEndMsg struct {
+	Flash int
+}
+
+// SetNotifier sets how the user is alerted when bast needs attention.
+// A nil notifier never alerts.
+func (m *Model) SetNotifier(n *notify.Notifier) {
+	m.notifier = n
+}
+
+// alert signals ev with the bell or a frame flash, as configured
+func (m Model) alert(ev notify.Event) (Model, tea.Cmd) {
+	alert := m.notifier.For(ev, time.Now())
+
+	var cmds []tea.Cmd
+	if alert.Audible {
+		cmds = append(cmds, func() tea.Msg {
+			notify.Ring() // Best effort; a missing bell isn't worth an error
+			return nil
+		})
+	}
+	if alert.Visual {
+		m.flash++
+		m.flashing = true
+		flash := m.flash
+		cmds = append(cmds, tea.Tick(flashDuration, func(time.Time) tea.Msg {
+			return FlashEndMsg{Flash: flash}
+		}))
+	}
+	return m, tea.Batch(cmds...)
+}
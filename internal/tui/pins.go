@@ -0,0 +1,108 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bastio-ai/bast/internal/ai"
+	"github.com/bastio-ai/bast/internal/files"
+	"github.com/bastio-ai/bast/internal/safety"
+	"github.com/bastio-ai/bast/internal/shell"
+)
+
+// pinnedBudget is the maximum total size of pinned context, in bytes.
+// Pinned items are sent with every prompt, so they are kept small.
+const pinnedBudget = 8 * 1024
+
+// pinnedSize returns the total size of pinned content in bytes
+func pinnedSize(pinned []ai.PinnedItem) int {
+	size := 0
+	for _, item := range pinned {
+		size += len(item.Content)
+	}
+	return size
+}
+
+// withPinned returns ctx with the session's pinned context attached
+func withPinned(ctx ai.ShellContext, pinned []ai.PinnedItem) ai.ShellContext {
+	ctx.Pinned = pinned
+	return ctx
+}
+
+// pin adds an item to the pinned context. arg selects what to pin:
+//
+//	(empty)   the last AI response in the conversation
+//	output    the last command's output
+//	@path     the contents of a file
+//	text      the text itself, as a note
+func (m Model) pin(arg string) (Model, error) {
+	var item ai.PinnedItem
+	switch {
+	case arg == "":
+		for i := len(m.conversationHistory) - 1; i >= 0; i-- {
+			if m.conversationHistory[i].Role == "assistant" {
+				item = ai.PinnedItem{Label: "AI response", Content: m.conversationHistory[i].Content}
+				break
+			}
+		}
+		if item.Content == "" {
+			return m, fmt.Errorf("nothing to pin yet; usage: /pin [note | @file | output]")
+		}
+
+	case arg == "output":
+		stdout, stderr := shell.LastOutput()
+		content := strings.TrimSpace(stdout + "\n" + stderr)
+		if content == "" {
+			return m, fmt.Errorf("no output from the last command (is the shell hook installed?)")
+		}
+		item = ai.PinnedItem{Label: "output of last command", Content: content, Untrusted: true}
+
+	case strings.HasPrefix(arg, "@"):
+		path := strings.TrimPrefix(arg, "@")
+		fc := files.ReadFiles(m.lazyCtx.base.CWD, []string{path}, pinnedBudget)
+		if len(fc) == 0 {
+			return m, fmt.Errorf("could not read %s", path)
+		}
+		if fc[0].Error != "" {
+			return m, fmt.Errorf("could not read %s: %s", path, fc[0].Error)
+		}
+		item = ai.PinnedItem{Label: fc[0].Path, Content: fc[0].Content, Untrusted: true}
+
+	default:
+		item = ai.PinnedItem{Label: "note", Content: arg}
+	}
+
+	// A pin goes into every later prompt, so instructions hidden in a file
+	// or command output are refused here rather than asked about each time
+	if item.Untrusted {
+		if markers := safety.DetectInjection(item.Content); len(markers) > 0 {
+			return m, fmt.Errorf("not pinning %s: it looks like prompt injection (%s); mention it with @ to send it once",
+				item.Label, strings.Join(markers, ", "))
+		}
+	}
+
+	if used := pinnedSize(m.pinned); used+len(item.Content) > pinnedBudget {
+		return m, fmt.Errorf("pinned context is limited to %d bytes (%d in use); /unpin to free space",
+			pinnedBudget, used)
+	}
+	m.pinned = append(m.pinned, item)
+	return m, nil
+}
+
+// unpin removes the pinned item at the 1-based position in arg, or every
+// pinned item when arg is empty
+func (m Model) unpin(arg string) (Model, error) {
+	if arg == "" {
+		m.pinned = nil
+		return m, nil
+	}
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 || n > len(m.pinned) {
+		return m, fmt.Errorf("usage: /unpin [1-%d]", len(m.pinned))
+	}
+	pinned := make([]ai.PinnedItem, 0, len(m.pinned)-1)
+	pinned = append(pinned, m.pinned[:n-1]...)
+	m.pinned = append(pinned, m.pinned[n:]...)
+	return m, nil
+}
@@ -0,0 +1,111 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/bastio-ai/bast/internal/ai"
+	"github.com/bastio-ai/bast/internal/files"
+	"github.com/bastio-ai/bast/internal/units"
+)
+
+// previewContext returns a command that assembles what query would send,
+// without sending it. Mentioned files, an ongoing conversation, or a
+// performance question with probes on preview a chat request; anything
+// else previews command generation.
+func (m Model) previewContext(query string) tea.Cmd {
+	lazyCtx := m.lazyCtx
+	pinned := m.pinned
+	conversationHistory := m.conversationHistory
+	returnMode := m.mode
+	probes := m.probesEnabled()
+	return func() tea.Msg {
+		shellCtx := withPinned(lazyCtx.Get(), pinned)
+		cleanQuery := files.StripMentions(query)
+
+		fileContents := chatFiles(shellCtx, query)
+		chatShellCtx := withProbes(shellCtx, probes, cleanQuery)
+		if len(conversationHistory) > 0 || len(fileContents) > 0 || len(chatShellCtx.Probes) > 0 {
+			chatCtx := ai.ChatContext{Files: fileContents, History: conversationHistory}
+			return ContextPreviewMsg{Preview: ai.PreviewChat(cleanQuery, chatShellCtx, chatCtx), Kind: "chat", Mode: returnMode}
+		}
+
+		shellCtx.Examples = feedbackExamples(cleanQuery, shellCtx.CWD)
+		return ContextPreviewMsg{Preview: ai.PreviewCommand(cleanQuery, shellCtx), Kind: "command", Mode: returnMode}
+	}
+}
+
+// handleContextPreviewModeKey handles keys while /context is shown
+func (m Model) handleContextPreviewModeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "enter", "q":
+		m.mode = m.contextReturn
+		m.contextPreview = nil
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// renderContextPreviewMode renders the /context view
+func (m Model) renderContextPreviewMode(contentWidth int) string {
+	var b strings.Builder
+
+	b.WriteString(DescStyle.Render(fmt.Sprintf("Context for the next %s request", m.contextKind)))
+	b.WriteString("\n\n")
+	b.WriteString(renderContextPreview(m.contextPreview, contentWidth))
+	b.WriteString("\n\n")
+	b.WriteString(HelpStyle.Render("Nothing has been sent • Esc back"))
+
+	return b.String()
+}
+
+// renderContextPreview lists the system prompt sections, files, and
+// conversation history a request sends, with sizes and redactions
+func renderContextPreview(preview *ai.ContextPreview, contentWidth int) string {
+	width := lipgloss.NewStyle().Width(contentWidth)
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("System prompt: %s in %d sections\n", units.Bytes(int64(preview.SystemBytes)), len(preview.Sections)))
+	for _, s := range preview.Sections {
+		line := fmt.Sprintf("  %-30s %s", s.Name, units.Bytes(int64(len(s.Text))))
+		if len(s.Redacted) > 0 {
+			line += " • redacted " + strings.Join(s.Redacted, ", ")
+		}
+		b.WriteString(width.Render(HelpStyle.Render(line)))
+		b.WriteString("\n")
+	}
+
+	if len(preview.Files) > 0 {
+		b.WriteString(fmt.Sprintf("Files: %d\n", len(preview.Files)))
+		for _, f := range preview.Files {
+			line := fmt.Sprintf("  %-30s %s", f.Path, units.Bytes(int64(f.Bytes)))
+			if f.Error != "" {
+				line = fmt.Sprintf("  %-30s not included: %s", f.Path, f.Error)
+			}
+			b.WriteString(width.Render(HelpStyle.Render(line)))
+			b.WriteString("\n")
+		}
+	}
+
+	if len(preview.Probes) > 0 {
+		b.WriteString(width.Render(WarningStyle.Render("Probes: " + strings.Join(preview.Probes, ", ") + " (context.probes)")))
+		b.WriteString("\n")
+	}
+
+	if preview.HistoryMessages > 0 {
+		b.WriteString(fmt.Sprintf("Conversation: %d earlier messages, %s\n", preview.HistoryMessages, units.Bytes(int64(preview.HistoryBytes))))
+	}
+	b.WriteString(fmt.Sprintf("Query: %s\n", units.Bytes(int64(preview.QueryBytes))))
+
+	if redactions := preview.Redactions(); len(redactions) > 0 {
+		b.WriteString(width.Render(WarningStyle.Render("Redacted before sending: " + strings.Join(redactions, ", "))))
+	} else {
+		b.WriteString(HelpStyle.Render("Redacted before sending: nothing"))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,29 @@
+package tui
+
+import (
+	"context"
+
+	"github.com/bastio-ai/bast/internal/ai"
+	"github.com/bastio-ai/bast/internal/probe"
+)
+
+// SetProbes sets whether chat and agent requests about performance carry a
+// snapshot of CPU, memory, disk, and thermal state (context.probes)
+func (m *Model) SetProbes(enabled bool) {
+	m.probes = enabled
+}
+
+// probesEnabled reports whether probe readings may be attached. They
+// describe this machine, so not when commands run on another target.
+func (m Model) probesEnabled() bool {
+	return m.probes && !m.remoteTarget()
+}
+
+// withProbes adds probe readings to ctx when enabled and query asks about
+// performance
+func withProbes(ctx ai.ShellContext, enabled bool, query string) ai.ShellContext {
+	if enabled && probe.Relevant(query) {
+		ctx.Probes = probe.Gather(context.Background())
+	}
+	return ctx
+}
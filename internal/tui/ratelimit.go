@@ -0,0 +1,123 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/bastio-ai/bast/internal/ai"
+)
+
+const (
+	// maxRateLimitRetries bounds automatic retries of one request
+	maxRateLimitRetries = 3
+
+	// defaultRateLimitWait is used when the API doesn't send Retry-After
+	defaultRateLimitWait = 10 * time.Second
+
+	// maxRateLimitWait caps the countdown, so a huge or bogus Retry-After
+	// doesn't leave the TUI waiting for hours
+	maxRateLimitWait = 2 * time.Minute
+)
+
+// waitForRateLimit starts a countdown in the loading view that retries the
+// last input once the wait the API asked for has passed
+func (m Model) waitForRateLimit(err *ai.ErrRateLimited) (tea.Model, tea.Cmd) {
+	wait := err.RetryAfter
+	if wait <= 0 {
+		wait = defaultRateLimitWait
+	}
+	wait = min(wait, maxRateLimitWait)
+	m.mode = ModeLoading
+	m.rateLimitErr = err
+	m.rateLimitUntil = time.Now().Add(wait)
+	m.rateLimitWait++
+	return m, rateLimitTick(m.rateLimitWait)
+}
+
+// rateLimitTick schedules the next countdown update
+func rateLimitTick(wait int) tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return RateLimitTickMsg{Wait: wait}
+	})
+}
+
+// rateLimitTicked updates the countdown, retrying once the wait is over.
+// Ticks from an earlier wait, or after the wait was cancelled, are dropped.
+func (m Model) rateLimitTicked(msg RateLimitTickMsg) (tea.Model, tea.Cmd) {
+	if msg.Wait != m.rateLimitWait || m.rateLimitErr == nil {
+		return m, nil
+	}
+	if time.Now().Before(m.rateLimitUntil) {
+		return m, rateLimitTick(m.rateLimitWait)
+	}
+	return m.retryAfterRateLimit()
+}
+
+// retryAfterRateLimit ends the countdown and resubmits the last input
+func (m Model) retryAfterRateLimit() (tea.Model, tea.Cmd) {
+	m.rateLimitErr = nil
+	m.rateLimitRetries++
+	return m.submit(m.lastInput)
+}
+
+// handleRateLimitKey handles keys while waiting out a rate limit
+func (m Model) handleRateLimitKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		// Give up waiting; Enter in input mode retries manually
+		m.err = m.rateLimitErr
+		m.rateLimitErr = nil
+		m.mode = ModeInput
+		if m.textInput.Value() == "" {
+			m.textInput.SetValue(m.lastInput)
+			m.textInput.CursorEnd()
+		}
+		return m, nil
+	case "enter":
+		return m.retryAfterRateLimit()
+	case "f":
+		if m.fallbackModel == "" {
+			return m, nil
+		}
+		m.provider.SetModel(m.fallbackModel)
+		m.currentModel = m.fallbackModel
+		m.fallbackModel = ""
+		return m.retryAfterRateLimit()
+	}
+	return m, nil
+}
+
+// renderRateLimitWait renders the countdown shown while rate limited
+func (m Model) renderRateLimitWait() string {
+	seconds := int(math.Ceil(time.Until(m.rateLimitUntil).Seconds()))
+	if seconds < 0 {
+		seconds = 0
+	}
+	status := fmt.Sprintf("Rate limited. Retrying in %ds", seconds)
+	if m.rateLimitRetries > 0 {
+		status += fmt.Sprintf(" (retry %d of %d)", m.rateLimitRetries+1, maxRateLimitRetries)
+	}
+
+	help := "Enter: retry now"
+	if m.fallbackModel != "" {
+		help += " • f: switch to " + modelName(m.fallbackModel)
+	}
+	help += " • Esc: cancel"
+
+	return m.spinner.View() + " " + WarningStyle.Render(status) + "\n\n" + HelpStyle.Render(help)
+}
+
+// modelName returns the display name of a known model, or its ID
+func modelName(id string) string {
+	for _, opt := range ai.AnthropicModels {
+		if opt.ID == id {
+			return opt.Name
+		}
+	}
+	return id
+}
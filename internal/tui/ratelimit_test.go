@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/bastio-ai/bast/internal/ai"
+)
+
+// modelProvider records the model it was switched to
+type modelProvider struct {
+	ai.Provider
+	model *string
+}
+
+func (p modelProvider) SetModel(model string) {
+	*p.model = model
+}
+
+// rateLimitedModel returns a model waiting out a rate limit on query
+func rateLimitedModel(query string, retryAfter time.Duration) Model {
+	ti := textinput.New()
+	ti.Focus()
+	m := Model{lastInput: query, textInput: ti}
+	next, _ := m.waitForRateLimit(&ai.ErrRateLimited{RetryAfter: retryAfter, Err: errors.New("429")})
+	return next.(Model)
+}
+
+func TestWaitForRateLimitCapsRetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter time.Duration
+		want       time.Duration
+	}{
+		{"as asked", 7 * time.Second, 7 * time.Second},
+		{"not sent", 0, defaultRateLimitWait},
+		{"too long", 6 * time.Hour, maxRateLimitWait},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			m := rateLimitedModel("list files", tt.retryAfter)
+			if m.mode != ModeLoading || m.rateLimitErr == nil {
+				t.Fatalf("mode = %v, rateLimitErr = %v; want the countdown", m.mode, m.rateLimitErr)
+			}
+			if got := m.rateLimitUntil.Sub(start); got < tt.want || got > tt.want+time.Second {
+				t.Errorf("wait = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimitTickCountsDown(t *testing.T) {
+	m := rateLimitedModel("list files", 30*time.Second)
+
+	next, cmd := m.rateLimitTicked(RateLimitTickMsg{Wait: m.rateLimitWait})
+	m = next.(Model)
+	if cmd == nil || m.rateLimitErr == nil || m.rateLimitRetries != 0 {
+		t.Errorf("cmd = %v, rateLimitErr = %v, retries = %d; want the countdown to go on", cmd, m.rateLimitErr, m.rateLimitRetries)
+	}
+
+	m.rateLimitUntil = time.Now().Add(-time.Second)
+	next, cmd = m.rateLimitTicked(RateLimitTickMsg{Wait: m.rateLimitWait})
+	m = next.(Model)
+	if cmd == nil || m.rateLimitErr != nil || m.rateLimitRetries != 1 {
+		t.Errorf("cmd = %v, rateLimitErr = %v, retries = %d; want the query retried", cmd, m.rateLimitErr, m.rateLimitRetries)
+	}
+	if m.pendingQuery != "list files" {
+		t.Errorf("pendingQuery = %q, want the last input resubmitted", m.pendingQuery)
+	}
+}
+
+func TestRateLimitTickIgnoresStaleTicks(t *testing.T) {
+	m := rateLimitedModel("list files", 30*time.Second)
+	m.rateLimitUntil = time.Now().Add(-time.Second)
+
+	// A tick left over from an earlier wait
+	next, cmd := m.rateLimitTicked(RateLimitTickMsg{Wait: m.rateLimitWait - 1})
+	if cmd != nil || next.(Model).rateLimitRetries != 0 {
+		t.Error("a tick from an earlier wait retried the query")
+	}
+
+	// A tick after Esc cancelled the wait
+	next, _ = m.handleRateLimitKey(tea.KeyMsg{Type: tea.KeyEsc})
+	m = next.(Model)
+	next, cmd = m.rateLimitTicked(RateLimitTickMsg{Wait: m.rateLimitWait})
+	if cmd != nil || next.(Model).mode != ModeInput {
+		t.Error("a tick after cancelling retried the query")
+	}
+	if m.textInput.Value() != "list files" {
+		t.Errorf("input = %q, want the last input back for a manual retry", m.textInput.Value())
+	}
+}
+
+func TestRateLimitFallbackSwitchesModel(t *testing.T) {
+	var model string
+	m := rateLimitedModel("list files", 30*time.Second)
+	m.provider = modelProvider{model: &model}
+	m.currentModel = "claude-opus-4-6"
+	m.fallbackModel = "claude-sonnet-4-5-20250929"
+
+	next, cmd := m.handleRateLimitKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	m = next.(Model)
+	if model != "claude-sonnet-4-5-20250929" || m.currentModel != "claude-sonnet-4-5-20250929" {
+		t.Errorf("provider model = %q, currentModel = %q; want the fallback", model, m.currentModel)
+	}
+	if m.fallbackModel != "" {
+		t.Errorf("fallbackModel = %q, want it used up", m.fallbackModel)
+	}
+	if cmd == nil || m.rateLimitErr != nil || m.rateLimitRetries != 1 {
+		t.Errorf("cmd = %v, rateLimitErr = %v, retries = %d; want the query retried", cmd, m.rateLimitErr, m.rateLimitRetries)
+	}
+
+	// Without a fallback left, f does nothing
+	m = rateLimitedModel("list files", 30*time.Second)
+	next, cmd = m.handleRateLimitKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	if cmd != nil || next.(Model).rateLimitErr == nil {
+		t.Error("f without a fallback model ended the wait")
+	}
+}
@@ -1,21 +1,44 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/bastio-ai/bast/internal/ai"
+	"github.com/bastio-ai/bast/internal/safety"
+	"github.com/bastio-ai/bast/internal/shell"
+	"github.com/bastio-ai/bast/internal/units"
 )
 
 // View implements tea.Model
 func (m Model) View() string {
 	contentWidth := ContentWidth(m.width)
+	if m.isInline() {
+		contentWidth = m.width
+	}
 	var b strings.Builder
 
-	b.WriteString(HeaderStyle.Render("bast"))
-	b.WriteString(" ")
-	b.WriteString(DescStyle.Render("AI Shell Assistant"))
-	b.WriteString("\n\n")
+	if !m.isInline() {
+		b.WriteString(HeaderStyle.Render("bast"))
+		b.WriteString(" ")
+		b.WriteString(DescStyle.Render("AI Shell Assistant"))
+		b.WriteString("\n\n")
+	}
+
+	if len(m.pinned) > 0 {
+		b.WriteString(m.renderPinned(contentWidth))
+		b.WriteString("\n\n")
+	}
+
+	if len(m.sessionEnv) > 0 {
+		line := "Session env: " + strings.Join(envNames(m.sessionEnv), ", ")
+		b.WriteString(lipgloss.NewStyle().Width(contentWidth).Render(HelpStyle.Render(line)))
+		b.WriteString("\n\n")
+	}
 
 	switch m.mode {
 	case ModeInput:
@@ -32,9 +55,78 @@ func (m Model) View() string {
 		b.WriteString(m.renderAgentMode(contentWidth))
 	case ModeFix:
 		b.WriteString(m.renderFixMode(contentWidth))
+	case ModeIntentChoice:
+		b.WriteString(m.renderIntentChoiceMode(contentWidth))
+	case ModeSecurityConfirm:
+		b.WriteString(m.renderSecurityConfirmMode(contentWidth))
+	case ModeWorkspaceSelect:
+		b.WriteString(m.renderWorkspaceSelectMode(contentWidth))
+	case ModeOpenSelect:
+		b.WriteString(m.renderOpenSelectMode(contentWidth))
+	case ModeInjectionFlag:
+		b.WriteString(m.renderInjectionFlagMode(contentWidth))
+	case ModeContextPreview:
+		b.WriteString(m.renderContextPreviewMode(contentWidth))
+	case ModeSteps:
+		b.WriteString(m.renderStepsMode(contentWidth))
+	}
+
+	status := m.renderStatusBar(contentWidth)
+	if m.isInline() {
+		return b.String() + "\n" + status
+	}
+
+	// Keep the status bar on the frame's bottom line (inside 2 border + 2 padding rows)
+	body := b.String()
+	if gap := m.height - 4 - lipgloss.Height(body) - 1; gap > 0 {
+		body += strings.Repeat("\n", gap)
+	}
+	body += "\n" + status
+
+	frame := FrameStyle(m.width, m.height)
+	if m.flashing {
+		frame = frame.BorderForeground(warningColor)
 	}
+	return frame.Render(body)
+}
 
-	return FrameStyle(m.width, m.height).Render(b.String())
+// renderPinned renders a one-line summary of the pinned context
+func (m Model) renderPinned(contentWidth int) string {
+	labels := make([]string, len(m.pinned))
+	for i, item := range m.pinned {
+		labels[i] = fmt.Sprintf("%d. %s", i+1, item.Label)
+	}
+	summary := fmt.Sprintf("Pinned: %s (%d/%d bytes)",
+		strings.Join(labels, ", "), pinnedSize(m.pinned), pinnedBudget)
+	return lipgloss.NewStyle().Width(contentWidth).Render(HelpStyle.Render(summary))
+}
+
+// contextSourcesLine lists the context sources sent with prompts when some
+// are turned off, and is empty when all are on
+func contextSourcesLine() string {
+	on, off := shell.ActiveSources()
+	if len(off) == 0 {
+		return ""
+	}
+	active := "none"
+	if len(on) > 0 {
+		active = strings.Join(on, ", ")
+	}
+	return fmt.Sprintf("Context: %s • off: %s", active, strings.Join(off, ", "))
+}
+
+// direnvNotice warns when the directory's .envrc is not being loaded
+func direnvNotice(env *ai.DirenvContext) string {
+	if env == nil || env.Loaded {
+		return ""
+	}
+	switch env.Status {
+	case "not allowed":
+		return fmt.Sprintf("direnv: %s is not allowed; run `direnv allow` to load it", env.Path)
+	case "denied":
+		return fmt.Sprintf("direnv: %s is denied, so its environment is not loaded", env.Path)
+	}
+	return ""
 }
 
 // renderInputMode renders the input mode view
@@ -55,11 +147,40 @@ func (m Model) renderInputMode(contentWidth int) string {
 		b.WriteString("\n")
 	}
 
+	if m.launchDir != "" && m.shellCtx.CWD != m.launchDir {
+		b.WriteString(lipgloss.NewStyle().Width(contentWidth).Render(HelpStyle.Render("Working in " + m.shellCtx.CWD)))
+		b.WriteString("\n")
+	}
+
+	if m.forcedIntent != "" {
+		b.WriteString(HelpStyle.Render(fmt.Sprintf("Mode: %s (every query goes here)", m.forcedIntent)))
+		b.WriteString("\n")
+	}
+
+	if line := contextSourcesLine(); line != "" {
+		b.WriteString(lipgloss.NewStyle().Width(contentWidth).Render(HelpStyle.Render(line)))
+		b.WriteString("\n")
+	}
+
+	if m.feedbackNotice != "" {
+		b.WriteString(HelpStyle.Render(m.feedbackNotice))
+		b.WriteString("\n")
+	}
+
+	if notice := direnvNotice(m.shellCtx.Direnv); notice != "" {
+		b.WriteString(lipgloss.NewStyle().Width(contentWidth).Render(WarningStyle.Render(notice)))
+		b.WriteString("\n")
+	}
+
 	if m.err != nil {
 		wrapped := lipgloss.NewStyle().Width(contentWidth).Render(
 			ErrorStyle.Render(fmt.Sprintf("Error: %s", m.err.Error())))
 		b.WriteString(wrapped)
 		b.WriteString("\n")
+		if guidance := errorGuidance(m.err); guidance != "" {
+			b.WriteString(lipgloss.NewStyle().Width(contentWidth).Render(HelpStyle.Render(guidance)))
+			b.WriteString("\n")
+		}
 	}
 
 	if m.showSlashMenu && len(m.slashCommands) > 0 {
@@ -75,6 +196,10 @@ func (m Model) renderInputMode(contentWidth int) string {
 
 // renderLoadingMode renders the loading mode view
 func (m Model) renderLoadingMode() string {
+	if m.rateLimitErr != nil {
+		return m.renderRateLimitWait()
+	}
+
 	var b strings.Builder
 
 	b.WriteString(m.spinner.View())
@@ -85,9 +210,41 @@ func (m Model) renderLoadingMode() string {
 		b.WriteString(DescStyle.Render("Processing..."))
 	}
 
+	if m.agentResult == nil && (len(m.agentToolCalls) > 0 || m.agentProgress != nil) {
+		b.WriteString("\n\n")
+		b.WriteString(m.renderAgentProgress(ContentWidth(m.width)))
+	}
+
 	return b.String()
 }
 
+// renderAgentProgress renders the tool calls of a running agent, ending with
+// the latest output of the tool still running
+func (m Model) renderAgentProgress(contentWidth int) string {
+	var b strings.Builder
+	line := lipgloss.NewStyle().Width(contentWidth)
+
+	for _, call := range m.agentToolCalls {
+		mark := HelpStyle.Render("✓")
+		if call.IsError {
+			mark = ErrorStyle.Render("✗")
+		}
+		b.WriteString(line.Render(fmt.Sprintf("  %s %s%s", mark, KeyStyle.Render(call.Name), HelpStyle.Render(formatToolMeta(call)))))
+		b.WriteString("\n")
+	}
+
+	if p := m.agentProgress; p != nil {
+		b.WriteString(line.Render(fmt.Sprintf("  %s %s %s", HelpStyle.Render("…"), KeyStyle.Render(p.Call.Name), string(p.Call.Input))))
+		b.WriteString("\n")
+		for _, out := range p.Tail {
+			b.WriteString(HelpStyle.MaxWidth(contentWidth).Render("    " + out))
+			b.WriteString("\n")
+		}
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
 // renderConfirmMode renders the confirm mode view
 func (m Model) renderConfirmMode(contentWidth int) string {
 	var b strings.Builder
@@ -99,24 +256,121 @@ func (m Model) renderConfirmMode(contentWidth int) string {
 		b.WriteString("\n\n")
 	}
 
-	b.WriteString(DescStyle.Render("Generated command:"))
+	if m.stepRun {
+		b.WriteString(DescStyle.Render("Command from the answer:"))
+		if len(m.stepQueue) > 0 {
+			b.WriteString(HelpStyle.Render(fmt.Sprintf(" (%d more checked after this, Esc stops)", len(m.stepQueue))))
+		}
+	} else {
+		b.WriteString(DescStyle.Render("Generated command:"))
+	}
+	if len(m.candidates) > 1 {
+		b.WriteString(HelpStyle.Render(fmt.Sprintf(" (alternative %d/%d)", m.candidateIndex+1, len(m.candidates))))
+	}
 	b.WriteString("\n")
 	wrapped := lipgloss.NewStyle().Width(contentWidth).Render(CommandStyle.Render(m.command))
 	b.WriteString(wrapped)
 	b.WriteString("\n")
 
+	if m.remoteTarget() {
+		b.WriteString(DescStyle.Render("Runs on: "))
+		b.WriteString(CommandStyle.Render(m.executor.Name()))
+		b.WriteString("\n")
+	}
+
+	// Where the command runs, when the generator asked for another directory
+	if m.workDir != "" {
+		dir := m.workDir
+		if rel, err := filepath.Rel(m.shellCtx.CWD, dir); err == nil && !strings.HasPrefix(rel, "..") {
+			dir = rel
+		}
+		b.WriteString(DescStyle.Render("Runs in: "))
+		b.WriteString(CommandStyle.Render(dir))
+		b.WriteString(HelpStyle.Render(" (press w to run it here instead)"))
+		b.WriteString("\n")
+	}
+
 	if m.explanation != "" {
 		wrappedExplanation := ExplanationStyle.Width(contentWidth).Render(m.explanation)
 		b.WriteString(wrappedExplanation)
 		b.WriteString("\n")
 	}
 
+	if m.showSentContext && m.sentContext != nil {
+		b.WriteString("\n")
+		b.WriteString(renderContextPreview(m.sentContext, contentWidth))
+		b.WriteString("\n")
+	}
+
+	// Show what the safety rewrite changed, with a way back
+	if m.rewrite != nil {
+		b.WriteString("\n")
+		b.WriteString(WarningStyle.Render("Rewritten for safety: " + strings.Join(m.rewrite.Reasons, "; ")))
+		b.WriteString("\n")
+		b.WriteString(DescStyle.Render("Original: "))
+		b.WriteString(CommandStyle.Render(m.rewrite.Original))
+		b.WriteString("\n")
+		b.WriteString(HelpStyle.Render("Press o to keep the original"))
+		b.WriteString("\n")
+	}
+
+	// Offer the OS-specific rewrite without applying it
+	if m.translation != nil {
+		b.WriteString("\n")
+		b.WriteString(WarningStyle.Render(m.translation.Reason))
+		b.WriteString("\n")
+		b.WriteString(DescStyle.Render("Suggested: "))
+		b.WriteString(CommandStyle.Render(m.translation.Command))
+		b.WriteString("\n")
+		b.WriteString(HelpStyle.Render("Press t to use it"))
+		b.WriteString("\n")
+	}
+
+	// Flag words that the user's shell will expand to something else,
+	// environments the command needs activated, date flags for another OS,
+	// and missing paths
+	for _, warning := range m.aliasWarnings {
+		b.WriteString("\n")
+		b.WriteString(lipgloss.NewStyle().Width(contentWidth).Render(WarningStyle.Render(warning)))
+	}
+	if len(m.aliasWarnings) > 0 {
+		b.WriteString("\n")
+	}
+
+	// Flag binaries that are not installed
+	if m.missing != nil {
+		b.WriteString("\n")
+		b.WriteString(WarningStyle.Render(fmt.Sprintf("Not found on PATH: %s", strings.Join(m.missing.Binaries, ", "))))
+		b.WriteString("\n")
+		if m.missing.Install != "" {
+			b.WriteString(DescStyle.Render("Install with: "))
+			b.WriteString(CommandStyle.Render(m.missing.Install))
+			b.WriteString("\n")
+			b.WriteString(HelpStyle.Render("Press i to prepend it"))
+			b.WriteString("\n")
+		}
+	}
+
 	b.WriteString("\n")
-	if m.isDangerous && !m.dangerConfirmed {
+	if secrets := safety.DetectSecrets(m.command); len(secrets) > 0 {
+		b.WriteString(renderSecretBlock(secrets, contentWidth))
+		b.WriteString("\n")
+		b.WriteString(HelpStyle.Render("e edit • n new • Esc cancel"))
+	} else if m.syntaxErr != nil {
+		b.WriteString(renderSyntaxBlock(m.syntaxErr, contentWidth))
+		b.WriteString("\n")
+		b.WriteString(HelpStyle.Render("e edit • n new • Esc cancel"))
+	} else if m.isDangerous && !m.dangerConfirmed {
 		b.WriteString(ErrorStyle.Render("Type 'yes' to confirm execution of this dangerous command"))
+	} else if m.needsSudo && !m.sudoAcknowledged {
+		b.WriteString(renderSudoNotice())
 	} else {
 		b.WriteString(m.renderHelp())
 	}
+	if m.feedbackNotice != "" {
+		b.WriteString("\n")
+		b.WriteString(HelpStyle.Render(m.feedbackNotice))
+	}
 	b.WriteString("\n\n")
 	b.WriteString(m.textInput.View())
 	b.WriteString("\n")
@@ -156,14 +410,34 @@ func (m Model) renderChatMode(contentWidth int) string {
 	} else if m.showSuggestions && len(m.suggestions) > 0 {
 		b.WriteString(m.renderSuggestions(contentWidth))
 		b.WriteString("\n")
+	} else {
+		b.WriteString(m.renderFollowUps(contentWidth))
 	}
 
-	if m.showSlashMenu && len(m.slashCommands) > 0 {
+	if m.err != nil {
+		wrapped := lipgloss.NewStyle().Width(contentWidth).Render(
+			ErrorStyle.Render(fmt.Sprintf("Error: %s", m.err.Error())))
+		b.WriteString(wrapped)
+		b.WriteString("\n")
+	}
+
+	if len(m.conversationHistory) > 0 {
+		b.WriteString(m.renderContextMeter(contentWidth))
+		b.WriteString("\n")
+	}
+
+	if m.editSelecting {
+		b.WriteString(HelpStyle.Render("↑↓ pick a message • Enter: edit • Esc: cancel"))
+	} else if m.editing {
+		b.WriteString(HelpStyle.Render("Enter: resend as a new branch (the current one is kept, Ctrl+R restores it) • Esc: cancel"))
+	} else if m.showSlashMenu && len(m.slashCommands) > 0 {
 		b.WriteString(HelpStyle.Render("↑↓ navigate • Tab/Enter select • Esc cancel"))
 	} else if m.showSuggestions && len(m.suggestions) > 0 {
 		b.WriteString(HelpStyle.Render("↑↓ navigate • Tab/Enter select • Esc cancel"))
+	} else if len(m.chatBranches) > 0 {
+		b.WriteString(HelpStyle.Render(fmt.Sprintf("Enter: send%s%s • Ctrl+E: edit • Ctrl+R: other branches (%d) • Ctrl+O: open file • Ctrl+N: new • Esc: quit", m.followUpsHelp(), m.answerCommandsHelp(), len(m.chatBranches))))
 	} else {
-		b.WriteString(HelpStyle.Render("Enter: send • ↑↓: scroll • Ctrl+N: new • Esc: quit"))
+		b.WriteString(HelpStyle.Render(fmt.Sprintf("Enter: send%s%s • ↑↓: scroll • Ctrl+E: edit • Ctrl+O: open file • Ctrl+N: new • Esc: quit", m.followUpsHelp(), m.answerCommandsHelp())))
 	}
 
 	return b.String()
@@ -171,29 +445,44 @@ func (m Model) renderChatMode(contentWidth int) string {
 
 // renderConversationContent renders conversation history for the viewport
 func (m Model) renderConversationContent() string {
-	if len(m.conversationHistory) == 0 {
-		return ""
-	}
+	return strings.Join(m.renderConversationBlocks(), "\n\n")
+}
+
+// renderConversationBlocks renders each conversation message separately
+func (m Model) renderConversationBlocks() []string {
 	contentWidth := ContentWidth(m.width)
-	var b strings.Builder
+	blocks := make([]string, 0, len(m.conversationHistory))
 	for i, msg := range m.conversationHistory {
+		var b strings.Builder
 		if msg.Role == "user" {
-			b.WriteString(PromptStyle.Render("You: "))
+			// Highlight the message being picked for editing
+			if m.editSelecting && i == m.editCursor {
+				b.WriteString(WarningStyle.Render("▶ You: "))
+			} else {
+				b.WriteString(PromptStyle.Render("You: "))
+			}
 			b.WriteString(msg.Content)
 		} else {
 			b.WriteString(DescStyle.Render("AI: "))
-			styled, err := m.markdownRenderer.Render(msg.Content)
+			styled, err := m.renderLinkedMarkdown(msg.Content)
 			if err != nil {
 				styled = lipgloss.NewStyle().Width(contentWidth).Render(msg.Content)
 			}
 			styled = strings.TrimSuffix(styled, "\n")
 			b.WriteString(styled)
 		}
-		if i < len(m.conversationHistory)-1 {
-			b.WriteString("\n\n")
-		}
+		blocks = append(blocks, b.String())
 	}
-	return b.String()
+	return blocks
+}
+
+// conversationLineOffset returns the viewport line where message index starts
+func (m Model) conversationLineOffset(index int) int {
+	offset := 0
+	for _, block := range m.renderConversationBlocks()[:index] {
+		offset += lipgloss.Height(block) + 1 // blank line between messages
+	}
+	return offset
 }
 
 // renderSlashMenu renders the slash command menu dropdown
@@ -234,16 +523,27 @@ func (m Model) renderSuggestions(contentWidth int) string {
 
 // renderHelp renders the help bar for confirm mode
 func (m Model) renderHelp() string {
-	keys := []struct {
+	type helpKey struct {
 		key  string
 		desc string
-	}{
+	}
+	keys := []helpKey{
 		{"Enter", "execute"},
 		{"e", "edit"},
 		{"?", "explain"},
+		{"g/b", "rate"},
 		{"n", "new"},
-		{"Esc", "cancel"},
 	}
+	if len(m.candidates) > 1 {
+		keys = append(keys, helpKey{"Tab", "alternatives"})
+	}
+	if m.originalCommand() != "" {
+		keys = append(keys, helpKey{"d", "diff"})
+	}
+	if m.sentContext != nil {
+		keys = append(keys, helpKey{"s", "context"})
+	}
+	keys = append(keys, helpKey{"Esc", "cancel"})
 
 	var parts []string
 	for _, k := range keys {
@@ -287,6 +587,20 @@ func (m Model) renderAgentMode(contentWidth int) string {
 	} else if m.showSuggestions && len(m.suggestions) > 0 {
 		b.WriteString(m.renderSuggestions(contentWidth))
 		b.WriteString("\n")
+	} else {
+		b.WriteString(m.renderFollowUps(contentWidth))
+	}
+
+	if m.err != nil {
+		wrapped := lipgloss.NewStyle().Width(contentWidth).Render(
+			ErrorStyle.Render(fmt.Sprintf("Error: %s", m.err.Error())))
+		b.WriteString(wrapped)
+		b.WriteString("\n")
+	}
+
+	if len(m.conversationHistory) > 0 || len(m.agentToolCalls) > 0 {
+		b.WriteString(m.renderContextMeter(contentWidth))
+		b.WriteString("\n")
 	}
 
 	if m.showSlashMenu && len(m.slashCommands) > 0 {
@@ -294,15 +608,23 @@ func (m Model) renderAgentMode(contentWidth int) string {
 	} else if m.showSuggestions && len(m.suggestions) > 0 {
 		b.WriteString(HelpStyle.Render("↑↓ navigate • Tab/Enter select • Esc cancel"))
 	} else {
-		b.WriteString(HelpStyle.Render("Enter: send • ↑↓: scroll • Ctrl+N: new • Esc: quit"))
+		b.WriteString(HelpStyle.Render(fmt.Sprintf("Enter: send%s • ↑↓: scroll • Ctrl+O: open file • Ctrl+N: new • Esc: quit", m.followUpsHelp())))
 	}
 
 	return b.String()
 }
 
+// defaultDisplayOutputLines is how many lines of output are shown per tool
+// call when no budget is configured
+const defaultDisplayOutputLines = 40
+
 // renderAgentContent renders the agent execution content for the viewport
 func (m Model) renderAgentContent() string {
 	contentWidth := ContentWidth(m.width)
+	displayLines := m.displayOutputLines
+	if displayLines <= 0 {
+		displayLines = defaultDisplayOutputLines
+	}
 	var b strings.Builder
 
 	// Show tool calls
@@ -315,26 +637,34 @@ func (m Model) renderAgentContent() string {
 		b.WriteString(DescStyle.Render("Tool Calls:"))
 		b.WriteString("\n")
 		for _, call := range toolCalls {
-			// Tool name and input
-			toolLine := fmt.Sprintf("  %s %s", KeyStyle.Render(call.Name), string(call.Input))
+			// Tool name, execution metadata, and input
+			toolLine := fmt.Sprintf("  %s%s %s", KeyStyle.Render(call.Name), HelpStyle.Render(formatToolMeta(call)), string(call.Input))
 			wrapped := lipgloss.NewStyle().Width(contentWidth).Render(toolLine)
 			b.WriteString(wrapped)
 			b.WriteString("\n")
 
-			// Tool output (truncated if too long)
-			output := call.Output
-			if len(output) > 500 {
-				output = output[:500] + "..."
+			// Security warnings raised for this call
+			for _, warning := range call.Warnings {
+				b.WriteString(lipgloss.NewStyle().Width(contentWidth).Render(
+					WarningStyle.Render("    ⚠ "+warning) + formatThreats(call.Threats)))
+				b.WriteString("\n")
 			}
-			if call.IsError {
-				b.WriteString(ErrorStyle.Render("    Error: " + output))
-			} else if output != "" {
+
+			// Tool output, cut to the display budget; the viewport scrolls
+			output := strings.TrimRight(call.Output, "\n")
+			if output != "" {
 				outputLines := strings.Split(output, "\n")
-				if len(outputLines) > 5 {
-					outputLines = append(outputLines[:5], "...")
+				if len(outputLines) > displayLines {
+					omitted := len(outputLines) - displayLines
+					outputLines = append(outputLines[:displayLines], fmt.Sprintf("... (%d more lines)", omitted))
+				}
+				style := HelpStyle
+				if call.IsError {
+					style = ErrorStyle
+					outputLines[0] = "Error: " + outputLines[0]
 				}
 				for _, line := range outputLines {
-					b.WriteString(HelpStyle.Render("    " + line))
+					b.WriteString(style.MaxWidth(contentWidth).Render("    " + line))
 					b.WriteString("\n")
 				}
 			}
@@ -342,12 +672,31 @@ func (m Model) renderAgentContent() string {
 		}
 	}
 
+	// Show what the security guardrails did
+	if m.agentResult != nil && !m.agentResult.Threats.IsEmpty() {
+		b.WriteString(lipgloss.NewStyle().Width(contentWidth).Render(renderThreatSummary(m.agentResult.Threats)))
+		b.WriteString("\n")
+	}
+
+	// Show how much of the run's input came from the prompt cache
+	if m.agentResult != nil && m.agentResult.Cache.Read > 0 {
+		b.WriteString(lipgloss.NewStyle().Width(contentWidth).Render(renderCacheSummary(m.agentResult.Cache)))
+		b.WriteString("\n")
+	}
+
+	// Show how the agent says the task went
+	if m.agentResult != nil && m.agentResult.Status != "" {
+		b.WriteString("\n")
+		b.WriteString(lipgloss.NewStyle().Width(contentWidth).Render(renderAgentStatus(m.agentResult)))
+		b.WriteString("\n")
+	}
+
 	// Show final response
 	if m.agentResult != nil && m.agentResult.Response != "" {
 		b.WriteString("\n")
 		b.WriteString(DescStyle.Render("Response:"))
 		b.WriteString("\n")
-		styled, err := m.markdownRenderer.Render(m.agentResult.Response)
+		styled, err := m.renderLinkedMarkdown(m.agentResult.Response)
 		if err != nil {
 			styled = lipgloss.NewStyle().Width(contentWidth).Render(m.agentResult.Response)
 		}
@@ -363,6 +712,53 @@ func (m Model) renderAgentContent() string {
 	return b.String()
 }
 
+// renderAgentStatus renders the agent's own verdict on its task, with its
+// summary: a green check for success, yellow for partial, red for failed
+func renderAgentStatus(result *ai.AgentResult) string {
+	var line string
+	switch result.Status {
+	case ai.AgentStatusSuccess:
+		line = SuccessStyle.Render("✓ Done")
+	case ai.AgentStatusPartial:
+		line = WarningStyle.Render("◐ Partly done")
+	case ai.AgentStatusFailed:
+		line = ErrorStyle.Render("✗ Failed")
+	default:
+		return ""
+	}
+	if result.Summary != "" {
+		line += " " + result.Summary
+	}
+	return line
+}
+
+// formatToolMeta formats tool execution metadata, e.g. " • exit 1 • 2.3s • 4.1KB"
+func formatToolMeta(call ai.ToolCall) string {
+	var parts []string
+	if call.Repeated {
+		parts = append(parts, "repeated, not re-run")
+	}
+	if call.ExitCode != nil {
+		parts = append(parts, fmt.Sprintf("exit %d", *call.ExitCode))
+	}
+	if call.Duration > 0 {
+		parts = append(parts, units.Duration(call.Duration))
+	}
+	if call.Bytes > 0 {
+		parts = append(parts, units.Bytes(int64(call.Bytes)))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " • " + strings.Join(parts, " • ")
+}
+
+// renderSudoNotice renders the acknowledgment prompt for commands that need sudo
+func renderSudoNotice() string {
+	return WarningStyle.Render("This command needs elevated privileges (sudo).") + "\n" +
+		HelpStyle.Render("Your shell asks for the password; bast never sees or stores it. Press Enter to acknowledge.")
+}
+
 // renderFixMode renders the fix mode view
 func (m Model) renderFixMode(contentWidth int) string {
 	var b strings.Builder
@@ -372,6 +768,11 @@ func (m Model) renderFixMode(contentWidth int) string {
 		return b.String()
 	}
 
+	if m.fixExitMeaning != "" {
+		b.WriteString(lipgloss.NewStyle().Width(contentWidth).Render(HelpStyle.Render(m.fixExitMeaning)))
+		b.WriteString("\n\n")
+	}
+
 	// Show the analysis result
 	if m.fixResult.WasFixed && m.fixResult.FixedCommand != "" {
 		// Show danger warning if the fixed command is dangerous
@@ -395,8 +796,14 @@ func (m Model) renderFixMode(contentWidth int) string {
 		}
 
 		b.WriteString("\n")
-		if m.isDangerous && !m.dangerConfirmed {
+		if secrets := safety.DetectSecrets(m.command); len(secrets) > 0 {
+			b.WriteString(renderSecretBlock(secrets, contentWidth))
+		} else if m.syntaxErr != nil {
+			b.WriteString(renderSyntaxBlock(m.syntaxErr, contentWidth))
+		} else if m.isDangerous && !m.dangerConfirmed {
 			b.WriteString(ErrorStyle.Render("Type 'yes' to confirm execution of this command"))
+		} else if m.needsSudo && !m.sudoAcknowledged {
+			b.WriteString(renderSudoNotice())
 		} else {
 			b.WriteString(m.renderFixHelp())
 		}
@@ -442,6 +849,105 @@ func (m Model) renderFixHelp() string {
 	return lipgloss.JoinHorizontal(lipgloss.Top, strings.Join(parts, "  "))
 }
 
+// renderSecurityConfirmMode renders the approval prompt for a warned tool call
+func (m Model) renderSecurityConfirmMode(contentWidth int) string {
+	var b strings.Builder
+	prompt := m.securityPrompt
+	if prompt == nil {
+		return ""
+	}
+
+	b.WriteString(WarningStyle.Render("⚠  Security warning"))
+	b.WriteString(formatThreats(prompt.Threats))
+	b.WriteString("\n\n")
+	b.WriteString(lipgloss.NewStyle().Width(contentWidth).Render(prompt.Message))
+	b.WriteString("\n\n")
+
+	toolLine := fmt.Sprintf("%s %s", KeyStyle.Render(prompt.Call.Name), string(prompt.Call.Input))
+	b.WriteString(lipgloss.NewStyle().Width(contentWidth).Render(toolLine))
+	b.WriteString("\n")
+
+	b.WriteString(HelpStyle.Render("Run this tool call? y/Enter: proceed • n/Esc: skip"))
+
+	return b.String()
+}
+
+// renderSecretBlock explains why a command carrying a credential won't run
+func renderSecretBlock(kinds []string, contentWidth int) string {
+	msg := fmt.Sprintf("Blocked: this command contains what looks like a credential (%s). "+
+		"Running it would expose the secret in arguments, shell history, and process lists. "+
+		"Edit it to read the value from an environment variable instead.", strings.Join(kinds, ", "))
+	return lipgloss.NewStyle().Width(contentWidth).Render(ErrorStyle.Render(msg))
+}
+
+// renderSyntaxBlock explains why a command that doesn't parse isn't handed off
+func renderSyntaxBlock(err error, contentWidth int) string {
+	msg := fmt.Sprintf("Blocked: this command %s, so it would fail in your prompt. Edit it to fix it.", err)
+	return lipgloss.NewStyle().Width(contentWidth).Render(ErrorStyle.Render(msg))
+}
+
+// renderThreatSummary renders a one-line summary of security verdicts for an agent run
+func renderThreatSummary(summary ai.ThreatSummary) string {
+	parts := []string{fmt.Sprintf("%d checked", summary.Checked)}
+	if summary.Blocked > 0 {
+		parts = append(parts, ErrorStyle.Render(fmt.Sprintf("%d blocked", summary.Blocked)))
+	}
+	if summary.Sanitized > 0 {
+		parts = append(parts, WarningStyle.Render(fmt.Sprintf("%d sanitized", summary.Sanitized)))
+	}
+	if summary.Warnings > 0 {
+		parts = append(parts, WarningStyle.Render(fmt.Sprintf("%d warned", summary.Warnings)))
+	}
+	if summary.MaxRiskScore > 0 {
+		parts = append(parts, fmt.Sprintf("max risk %.2f", summary.MaxRiskScore))
+	}
+	if summary.Blocked == 0 && summary.Sanitized == 0 && summary.Warnings == 0 {
+		parts = append(parts, "no issues")
+	}
+
+	return DescStyle.Render("Security: ") + strings.Join(parts, DescStyle.Render(" • ")) + formatThreats(summary.Threats)
+}
+
+// renderCacheSummary renders a one-line summary of prompt cache use for an agent run
+func renderCacheSummary(cache ai.PromptCache) string {
+	total := cache.Total()
+	return DescStyle.Render("Prompt cache: ") + fmt.Sprintf("%s of %s input tokens cached, ~%.0f%% input cost saved",
+		formatTokens(int(cache.Read)), formatTokens(int(total)), max(cache.Savings(), 0)*100)
+}
+
+// formatThreats renders threat tags like " [shell_injection, exfiltration]"
+func formatThreats(threats []string) string {
+	if len(threats) == 0 {
+		return ""
+	}
+	return HelpStyle.UnsetMarginTop().Render(" [" + strings.Join(threats, ", ") + "]")
+}
+
+// renderIntentChoiceMode renders the command-vs-chat prompt for low-confidence intents
+func (m Model) renderIntentChoiceMode(contentWidth int) string {
+	var b strings.Builder
+
+	b.WriteString(DescStyle.Render("Not sure what you meant:"))
+	b.WriteString("\n")
+	b.WriteString(lipgloss.NewStyle().Width(contentWidth).Render(m.pendingQuery))
+	b.WriteString("\n\n")
+
+	options := []string{"1. Run a command", "2. Just answer"}
+	for i, opt := range options {
+		if i == m.intentChoiceCursor {
+			b.WriteString(SuggestionSelectedStyle.Width(contentWidth).Render("> " + opt))
+		} else {
+			b.WriteString(SuggestionStyle.Width(contentWidth).Render("  " + opt))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(HelpStyle.Render("↑↓ navigate • Enter/1/2 select • Esc edit query"))
+
+	return b.String()
+}
+
 // renderModelSelectMode renders the model selection menu
 func (m Model) renderModelSelectMode(contentWidth int) string {
 	var b strings.Builder
@@ -497,3 +1003,90 @@ func (m Model) renderModelSelectMode(contentWidth int) string {
 
 	return b.String()
 }
+
+// renderWorkspaceSelectMode renders the workspace member picker
+func (m Model) renderWorkspaceSelectMode(contentWidth int) string {
+	var b strings.Builder
+
+	b.WriteString(DescStyle.Render(fmt.Sprintf("Select Workspace Member (%s)", m.workspace.Kind)))
+	b.WriteString("\n\n")
+
+	current := m.workspace.Current(m.shellCtx.CWD)
+	lines := []string{"(root) " + m.workspace.Root}
+	if current == nil && m.shellCtx.CWD == m.workspace.Root {
+		lines[0] += " (current)"
+	}
+	for _, member := range m.workspace.Members {
+		line := member.Path
+		if member.Name != member.Path {
+			line += " - " + member.Name
+		}
+		if current != nil && current.Path == member.Path {
+			line += " (current)"
+		}
+		lines = append(lines, line)
+	}
+
+	for i, line := range lines {
+		if i == m.workspaceCursor {
+			b.WriteString(SuggestionSelectedStyle.Width(contentWidth).Render("> " + line))
+		} else {
+			b.WriteString(SuggestionStyle.Width(contentWidth).Render("  " + line))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(HelpStyle.Render("↑↓ navigate • Enter select • Esc back"))
+
+	return b.String()
+}
+
+// renderOpenSelectMode renders the picker for files referenced in the conversation
+func (m Model) renderOpenSelectMode(contentWidth int) string {
+	var b strings.Builder
+
+	b.WriteString(DescStyle.Render("Open in Editor"))
+	b.WriteString("\n\n")
+
+	for i, ref := range m.openRefs {
+		line := m.relativeRef(ref)
+		if i == m.openCursor {
+			b.WriteString(SuggestionSelectedStyle.Width(contentWidth).Render("> " + line))
+		} else {
+			b.WriteString(SuggestionStyle.Width(contentWidth).Render("  " + line))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(HelpStyle.Render("↑↓ navigate • Enter open • Esc back"))
+
+	return b.String()
+}
+
+// errorGuidance returns a hint for recovering from typed provider errors
+func errorGuidance(err error) string {
+	var rateLimited *ai.ErrRateLimited
+	var authErr *ai.ErrAuth
+	var tooLong *ai.ErrContextTooLong
+	var overloaded *ai.ErrOverloaded
+	var network *ai.ErrNetwork
+
+	switch {
+	case errors.As(err, &authErr):
+		return "Your API key was rejected. Run 'bast init' or 'bast auth login', or set ANTHROPIC_API_KEY."
+	case errors.As(err, &rateLimited):
+		if rateLimited.RetryAfter > 0 {
+			return fmt.Sprintf("Rate limit reached. Wait %s, then press Enter to retry.", units.Duration(rateLimited.RetryAfter))
+		}
+		return "Rate limit reached. Wait a moment, then press Enter to retry."
+	case errors.As(err, &tooLong):
+		return "Too much context for the model. Press Ctrl+N to clear the conversation, or mention fewer files."
+	case errors.As(err, &overloaded):
+		return "The API is temporarily overloaded. Press Enter to retry."
+	case errors.As(err, &network):
+		return "Could not reach the API. Check your connection, then press Enter to retry."
+	}
+	return ""
+}
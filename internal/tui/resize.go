@@ -0,0 +1,76 @@
+package tui
+
+import (
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+)
+
+// resizeDebounce is how long the size must hold still before the markdown
+// renderer is rebuilt. Dragging a tmux pane sends dozens of resizes a second,
+// and rebuilding glamour for each one makes the drag stutter.
+const resizeDebounce = 120 * time.Millisecond
+
+// ResizeSettledMsg fires once the terminal size has held still. Resize is the
+// resize it settles, so an earlier one is ignored if more arrived since.
+type ResizeSettledMsg struct {
+	Resize int
+}
+
+// resize applies a new terminal size. Layout follows at once; the markdown
+// renderer is rebuilt only after the size settles, except the first time.
+func (m Model) resize(msg tea.WindowSizeMsg) (Model, tea.Cmd) {
+	m.width = msg.Width
+	m.height = msg.Height
+	contentWidth := ContentWidth(msg.Width)
+
+	// Calculate viewport height (total - frame border/padding - header - input area)
+	viewportHeight := msg.Height - 14 // Approximate: 2 border + 4 padding + 3 header + 3 input + 1 context meter + 1 status bar
+	if viewportHeight < 1 {
+		viewportHeight = 1
+	}
+
+	if !m.viewportReady {
+		m.chatViewport = viewport.New(contentWidth, viewportHeight)
+		m.viewportReady = true
+	} else {
+		m.chatViewport.Width = contentWidth
+		m.chatViewport.Height = viewportHeight
+	}
+
+	if m.rendererWidth == 0 {
+		return m.rebuildRenderer(), nil
+	}
+	m.resizes++
+	resize := m.resizes
+	return m, tea.Tick(resizeDebounce, func(time.Time) tea.Msg {
+		return ResizeSettledMsg{Resize: resize}
+	})
+}
+
+// rebuildRenderer re-creates the markdown renderer for the current width
+// and re-renders the conversation with it
+func (m Model) rebuildRenderer() Model {
+	contentWidth := ContentWidth(m.width)
+	if contentWidth != m.rendererWidth {
+		renderer, _ := glamour.NewTermRenderer(
+			glamour.WithStylePath("dark"),
+			glamour.WithWordWrap(contentWidth),
+		)
+		m.markdownRenderer = renderer
+		m.rendererWidth = contentWidth
+	}
+	if m.mode == ModeChat {
+		m.chatViewport.SetContent(m.renderConversationContent())
+	}
+	return m
+}
+
+// resume redraws from scratch after the process was suspended with Ctrl+Z.
+// Whatever ran in the foreground meanwhile has scribbled over the screen,
+// and the terminal may have been resized while we were stopped.
+func (m Model) resume() (Model, tea.Cmd) {
+	return m, tea.Batch(tea.ClearScreen, tea.WindowSize())
+}
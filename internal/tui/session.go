@@ -0,0 +1,105 @@
+package tui
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/bastio-ai/bast/internal/ai"
+	"github.com/bastio-ai/bast/internal/debug"
+	"github.com/bastio-ai/bast/internal/session"
+	"github.com/bastio-ai/bast/internal/transcript"
+)
+
+// SetSessionSaving sets whether chat and agent turns are saved for
+// bast sessions list and share (sessions.save)
+func (m *Model) SetSessionSaving(save bool) {
+	m.saveSession = save
+}
+
+// sessionSaves orders session writes, which run in the background: a
+// write never replaces a copy holding more turns than it does
+var sessionSaves struct {
+	sync.Mutex
+	turns map[string]int // Turns in the last copy written, by session ID
+}
+
+// recordTurn adds a chat or agent turn to this session's saved transcript
+// and returns the command that writes it. Failures are not worth
+// interrupting for.
+func (m *Model) recordTurn(mode, query, response string, toolCalls []ai.ToolCall, threats ai.ThreatSummary) tea.Cmd {
+	if !m.saveSession {
+		return nil
+	}
+	dir, err := session.DefaultDir()
+	if err != nil {
+		return nil
+	}
+	now := time.Now()
+	if m.transcript == nil {
+		m.transcript = &session.Session{ID: m.sessionID, Started: now}
+	}
+	turn := session.Turn{Time: now, Mode: mode, Query: query, Response: response, Branch: m.branch}
+	for _, call := range toolCalls {
+		turn.Tools = append(turn.Tools, session.Tool{
+			Name:    call.Name,
+			Input:   call.Input,
+			Output:  call.Output,
+			IsError: call.IsError,
+		})
+	}
+	if !threats.IsEmpty() {
+		turn.Threats = &threats
+	}
+	turn.Redact()
+	m.transcript.Model = m.currentModel
+	m.transcript.Updated = now
+	m.transcript.Turns = append(m.transcript.Turns, turn)
+
+	snapshot := *m.transcript
+	snapshot.Turns = slices.Clone(m.transcript.Turns)
+	return func() tea.Msg {
+		sessionSaves.Lock()
+		defer sessionSaves.Unlock()
+		if len(snapshot.Turns) <= sessionSaves.turns[snapshot.ID] {
+			return nil
+		}
+		if err := session.Save(dir, &snapshot); err != nil {
+			debug.Logf("failed to save session: %v", err)
+			return nil
+		}
+		if sessionSaves.turns == nil {
+			sessionSaves.turns = make(map[string]int)
+		}
+		sessionSaves.turns[snapshot.ID] = len(snapshot.Turns)
+		return nil
+	}
+}
+
+// agentMaxIterations is how many API round-trips an agent task may take
+const agentMaxIterations = 10
+
+// openTranscript starts writing an agent run to the session's transcript,
+// or returns nil if it can't be opened
+func openTranscript(sessionID, query string, snapshot transcript.Config) *transcript.Writer {
+	dir, err := transcript.DefaultDir()
+	if err != nil {
+		return nil
+	}
+	w, err := transcript.Open(dir, sessionID)
+	if err != nil {
+		debug.Logf("failed to open transcript: %v", err)
+		return nil
+	}
+	logTranscriptErr(w.Start(query, snapshot))
+	return w
+}
+
+// logTranscriptErr logs a failed transcript write. The run goes on.
+func logTranscriptErr(err error) {
+	if err != nil {
+		debug.Logf("transcript: %v", err)
+	}
+}
@@ -11,8 +11,18 @@ type SlashCommand struct {
 // AvailableCommands is the list of all available slash commands
 var AvailableCommands = []SlashCommand{
 	{Name: "/model", Description: "Change AI model"},
+	{Name: "/mode", Description: "Route every query to command, chat, or agent (or auto)"},
 	{Name: "/agent", Description: "Run agentic task with tools"},
 	{Name: "/fix", Description: "Fix last failed command"},
+	{Name: "/pin", Description: "Pin a note, @file, output, or the last answer as context"},
+	{Name: "/unpin", Description: "Remove pinned context (all, or by number)"},
+	{Name: "/cd", Description: "Work in another directory (no argument: back to the start)"},
+	{Name: "/target", Description: "Run commands in a container or on a host (local, docker:<name>, ssh:<host>)"},
+	{Name: "/env", Description: "Set or unset a variable for commands this session (set NAME=value)"},
+	{Name: "/feedback", Description: "Tell bast what was wrong (or right) with the last command"},
+	{Name: "/workspace", Description: "Scope to a sub-project of this monorepo"},
+	{Name: "/compact", Description: "Summarize older messages to free up context"},
+	{Name: "/context", Description: "Show what a query would send, without sending it"},
 }
 
 // FilterCommands returns commands matching the prefix
@@ -0,0 +1,81 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/bastio-ai/bast/internal/ai"
+)
+
+// Gateways the provider can reach the model through
+const (
+	GatewayBastio = "bastio"
+	GatewayDirect = "direct"
+)
+
+// modeUsage explains /mode
+const modeUsage = "usage: /mode auto|command|chat|agent"
+
+// SetSessionInfo sets what the status bar shows about the session: the
+// model in use, the gateway (GatewayBastio or GatewayDirect), and the
+// safety mode from config
+func (m *Model) SetSessionInfo(model, gateway, safety string) {
+	m.currentModel = model
+	m.gateway = gateway
+	m.safetyMode = safety
+}
+
+// setMode handles /mode, which changes where queries are routed for the
+// rest of the session, like --mode does at launch
+func (m Model) setMode(arg string) (Model, error) {
+	switch arg = strings.TrimSpace(arg); arg {
+	case "auto":
+		m.forcedIntent = ""
+	case string(ai.IntentCommand), string(ai.IntentChat), string(ai.IntentAgent):
+		m.forcedIntent = ai.Intent(arg)
+	default:
+		return m, fmt.Errorf(modeUsage)
+	}
+	return m, nil
+}
+
+// renderStatusBar renders the one-line summary of the session kept at the
+// bottom of the view
+func (m Model) renderStatusBar(width int) string {
+	var parts []string
+	if m.currentModel != "" {
+		parts = append(parts, m.currentModel)
+	}
+	if m.gateway != "" {
+		parts = append(parts, "via "+m.gateway)
+	}
+	if m.safetyMode != "" {
+		parts = append(parts, m.safetyMode+" mode")
+	}
+	route := "auto"
+	if m.forcedIntent != "" {
+		route = string(m.forcedIntent)
+	}
+	parts = append(parts, "route "+route)
+	if m.remoteTarget() {
+		parts = append(parts, "on "+m.executor.Name())
+	}
+	if usage, ok := m.provider.(ai.UsageReporter); ok {
+		parts = append(parts, formatTokens(int(usage.Usage().Total()))+" tokens")
+	}
+	if m.sessionID != "" {
+		parts = append(parts, "session "+shortSessionID(m.sessionID))
+	}
+	return lipgloss.NewStyle().MaxWidth(width).Render(HelpStyle.Render(strings.Join(parts, " · ")))
+}
+
+// shortSessionID abbreviates a session UUID the way it is shown in the
+// Bastio dashboard
+func shortSessionID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
@@ -0,0 +1,174 @@
+package tui
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/bastio-ai/bast/internal/notify"
+)
+
+// reviewAnswerCommands lists the commands the last chat answer told the
+// user to run, to pick from and run through the confirm view
+func (m Model) reviewAnswerCommands() (tea.Model, tea.Cmd) {
+	if len(m.answerCommands) == 0 {
+		m.err = fmt.Errorf("no commands in this answer to run")
+		return m, nil
+	}
+	m.stepCursor = 0
+	m.stepChecked = make([]bool, len(m.answerCommands))
+	m.mode = ModeSteps
+	m.err = nil
+	return m, nil
+}
+
+// handleStepsModeKey handles keys in the answer command checklist
+func (m Model) handleStepsModeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.stepCursor > 0 {
+			m.stepCursor--
+		}
+	case "down", "j":
+		if m.stepCursor < len(m.answerCommands)-1 {
+			m.stepCursor++
+		}
+	case " ", "x":
+		m.stepChecked[m.stepCursor] = !m.stepChecked[m.stepCursor]
+	case "enter":
+		return m.runAnswerCommands()
+	case "esc":
+		m.mode = ModeChat
+		return m, nil
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// runAnswerCommands confirms and runs the checked commands one at a time,
+// or else the selected one. Each goes through the confirm view on its own,
+// so it gets the same danger, sudo, and credential checks as a generated
+// command, and a failure stops the rest.
+func (m Model) runAnswerCommands() (tea.Model, tea.Cmd) {
+	var commands []string
+	for i, checked := range m.stepChecked {
+		if checked {
+			commands = append(commands, m.answerCommands[i])
+		}
+	}
+	if len(commands) == 0 {
+		commands = []string{m.answerCommands[m.stepCursor]}
+	}
+	m.stepQueue = commands
+	return m.confirmNextStep()
+}
+
+// confirmNextStep shows the next queued checklist command in the confirm view
+func (m Model) confirmNextStep() (tea.Model, tea.Cmd) {
+	command := m.stepQueue[0]
+	m.stepQueue = m.stepQueue[1:]
+
+	candidates, err := m.rankCandidates([]candidate{{command: command}})
+	if err != nil {
+		return m.stopSteps(err)
+	}
+	m.candidates = candidates
+	m = m.showCandidate(0)
+	m.editedFrom = ""
+	m.commandEdited = false
+	m.generatedQuery = "" // Not generated for a query, so not kept as an example
+	m.sentContext = nil
+	m.showSentContext = false
+	m.feedbackNotice = ""
+	m.stepRun = true
+	m.mode = ModeConfirm
+	m.textInput.SetValue("")
+	m.resetAutocomplete()
+	if m.isDangerous {
+		return m.alert(notify.EventDanger)
+	}
+	return m, nil
+}
+
+// runStep suspends the TUI and runs the confirmed checklist command in the
+// user's shell, the way the shell hook would run a handed-off command
+func (m Model) runStep() tea.Cmd {
+	command := m.command
+	sh := m.shellCtx.Shell
+	if sh == "" {
+		sh = "sh"
+	}
+	cmd := exec.Command(sh, "-c", m.handoff().Command)
+	cmd.Dir = m.launchDir
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return StepRanMsg{Command: command, Err: err}
+	})
+}
+
+// stepRan moves on to the next checked command, or back to the checklist
+// once they have all run or one failed
+func (m Model) stepRan(msg StepRanMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		err := fmt.Errorf("%s failed: %w", msg.Command, msg.Err)
+		if len(m.stepQueue) > 0 {
+			err = fmt.Errorf("%w; skipped the remaining %d", err, len(m.stepQueue))
+		}
+		return m.stopSteps(err)
+	}
+	if len(m.stepQueue) > 0 {
+		return m.confirmNextStep()
+	}
+	return m.stopSteps(nil)
+}
+
+// stopSteps ends a checklist run and returns to the list, reporting err
+func (m Model) stopSteps(err error) (tea.Model, tea.Cmd) {
+	m.stepRun = false
+	m.stepQueue = nil
+	m.command = ""
+	m.err = err
+	m.mode = ModeSteps
+	return m, nil
+}
+
+// renderStepsMode renders the answer command checklist
+func (m Model) renderStepsMode(contentWidth int) string {
+	var b strings.Builder
+
+	b.WriteString(DescStyle.Render("Commands in this answer"))
+	b.WriteString("\n\n")
+
+	for i, command := range m.answerCommands {
+		box := "[ ]"
+		if m.stepChecked[i] {
+			box = "[x]"
+		}
+		line := box + " " + command
+		if isDangerousCommand(command) {
+			line += " ⚠"
+		}
+		if i == m.stepCursor {
+			b.WriteString(SuggestionSelectedStyle.Width(contentWidth).Render("> " + line))
+		} else {
+			b.WriteString(SuggestionStyle.Width(contentWidth).Render("  " + line))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(HelpStyle.Render("↑↓ navigate • Space check • Enter run checked (or selected) • Esc back"))
+
+	return b.String()
+}
+
+// answerCommandsHelp is the chat help bar entry for the checklist, when the
+// answer has commands
+func (m Model) answerCommandsHelp() string {
+	if len(m.answerCommands) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" • Ctrl+X: run commands (%d)", len(m.answerCommands))
+}
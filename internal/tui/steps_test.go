@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// stepsModel returns a model showing the checklist with every command checked
+func stepsModel(commands ...string) Model {
+	m := confirmModel("")
+	m.mode = ModeSteps
+	m.answerCommands = commands
+	m.stepChecked = make([]bool, len(commands))
+	for i := range m.stepChecked {
+		m.stepChecked[i] = true
+	}
+	return m
+}
+
+func TestRunAnswerCommandsConfirmsEachSeparately(t *testing.T) {
+	m := stepsModel("npm ci", "npm test")
+
+	next, _ := m.runAnswerCommands()
+	m = next.(Model)
+	if m.mode != ModeConfirm || m.command != "npm ci" {
+		t.Fatalf("mode = %v, command = %q; want the first command alone in the confirm view", m.mode, m.command)
+	}
+
+	next, _ = m.stepRan(StepRanMsg{Command: "npm ci"})
+	m = next.(Model)
+	if m.mode != ModeConfirm || m.command != "npm test" {
+		t.Fatalf("mode = %v, command = %q; want the second command next", m.mode, m.command)
+	}
+
+	next, _ = m.stepRan(StepRanMsg{Command: "npm test"})
+	m = next.(Model)
+	if m.mode != ModeSteps || m.stepRun || m.err != nil {
+		t.Errorf("mode = %v, stepRun = %v, err = %v; want back at the list", m.mode, m.stepRun, m.err)
+	}
+}
+
+func TestRunAnswerCommandsStopsOnFailure(t *testing.T) {
+	m := stepsModel("make build", "make deploy")
+
+	next, _ := m.runAnswerCommands()
+	next, _ = next.(Model).stepRan(StepRanMsg{Command: "make build", Err: errors.New("exit status 2")})
+	m = next.(Model)
+	if m.mode != ModeSteps || len(m.stepQueue) != 0 {
+		t.Fatalf("mode = %v, queue = %v; want the run stopped", m.mode, m.stepQueue)
+	}
+	if m.err == nil {
+		t.Error("want the failure reported")
+	}
+}
+
+func TestConfirmEscStopsChecklistRun(t *testing.T) {
+	m := stepsModel("npm ci", "npm test")
+
+	next, _ := m.runAnswerCommands()
+	next, cmd := next.(Model).handleConfirmModeKey(tea.KeyMsg{Type: tea.KeyEsc})
+	m = next.(Model)
+	if cmd != nil || m.mode != ModeSteps || m.stepRun {
+		t.Errorf("mode = %v, stepRun = %v; want Esc to return to the list without quitting", m.mode, m.stepRun)
+	}
+}
@@ -7,6 +7,7 @@ var (
 	primaryColor   = lipgloss.Color("#7C3AED") // Purple
 	secondaryColor = lipgloss.Color("#10B981") // Green
 	errorColor     = lipgloss.Color("#EF4444") // Red
+	warningColor   = lipgloss.Color("#F59E0B") // Amber
 	mutedColor     = lipgloss.Color("#6B7280") // Gray
 	textColor      = lipgloss.Color("#F9FAFB") // Light
 
@@ -43,6 +44,16 @@ var (
 			Foreground(errorColor).
 			Bold(true)
 
+	// Success messages
+	SuccessStyle = lipgloss.NewStyle().
+			Foreground(secondaryColor).
+			Bold(true)
+
+	// Security warnings
+	WarningStyle = lipgloss.NewStyle().
+			Foreground(warningColor).
+			Bold(true)
+
 	// Spinner
 	SpinnerStyle = lipgloss.NewStyle().
 			Foreground(primaryColor)
@@ -85,6 +96,11 @@ var (
 				Background(lipgloss.Color("#064E3B")).
 				Padding(0, 1).
 				Bold(true)
+
+	// File citations in chat answers, e.g. [main.go:10-20]
+	CitationStyle = lipgloss.NewStyle().
+			Foreground(primaryColor).
+			Underline(true)
 )
 
 // FrameStyle returns a style for the main TUI frame
@@ -0,0 +1,41 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bastio-ai/bast/internal/config"
+	"github.com/bastio-ai/bast/internal/target"
+)
+
+// targetUsage explains /target
+const targetUsage = "usage: /target local | <name from targets config> | docker:<container> | ssh:<host>"
+
+// setTarget handles /target, which moves where commands run for the rest of
+// the session: handed-off commands are wrapped to run there, and agent
+// commands run there directly. Context is still gathered locally.
+func (m Model) setTarget(arg string) (Model, error) {
+	arg = strings.TrimSpace(arg)
+	if arg == "" {
+		return m, fmt.Errorf(targetUsage)
+	}
+
+	var configured map[string]string
+	if cfg, err := config.Load(); err == nil {
+		configured = cfg.Targets
+	}
+	executor, err := target.Resolve(arg, configured)
+	if err != nil {
+		return m, fmt.Errorf("%w; %s", err, targetUsage)
+	}
+	if executor.Local() {
+		executor = nil
+	}
+	m.executor = executor
+	return m, nil
+}
+
+// remoteTarget reports whether commands run somewhere other than this machine
+func (m Model) remoteTarget() bool {
+	return m.executor != nil && !m.executor.Local()
+}
@@ -0,0 +1,30 @@
+// Package units renders byte counts and durations compactly for display.
+package units
+
+import (
+	"fmt"
+	"time"
+)
+
+// Bytes renders a byte count with a binary multiple (e.g. "512B", "4.1KB",
+// "1.2MB", "15.6GB")
+func Bytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// Duration renders a duration compactly (e.g. "850ms", "2.3s")
+func Duration(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
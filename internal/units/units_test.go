@@ -0,0 +1,48 @@
+package units
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0B"},
+		{1023, "1023B"},
+		{1024, "1.0KB"},
+		{1536, "1.5KB"},
+		{1023 * 1024, "1023.0KB"},
+		{1024 * 1024, "1.0MB"},
+		{16 * 1024 * 1024, "16.0MB"},
+		{1024 * 1024 * 1024, "1.0GB"},
+		{1 << 40, "1.0TB"},
+		{1 << 62, "4.0EB"},
+	}
+	for _, tt := range tests {
+		if got := Bytes(tt.n); got != tt.want {
+			t.Errorf("Bytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0ms"},
+		{850 * time.Millisecond, "850ms"},
+		{time.Second - time.Microsecond, "999ms"},
+		{time.Second, "1.0s"},
+		{2300 * time.Millisecond, "2.3s"},
+		{90 * time.Second, "90.0s"},
+	}
+	for _, tt := range tests {
+		if got := Duration(tt.d); got != tt.want {
+			t.Errorf("Duration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
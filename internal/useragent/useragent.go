@@ -0,0 +1,32 @@
+// Package useragent builds the User-Agent header shared by bast's HTTP clients.
+package useragent
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// version is the CLI version reported to Bastio, set from the build's
+// version by SetVersion
+var version = "dev"
+
+// SetVersion sets the CLI version reported to Bastio
+func SetVersion(v string) {
+	version = v
+}
+
+// Version returns the CLI version reported to Bastio
+func Version() string {
+	return version
+}
+
+// String returns the User-Agent for this install, e.g.
+// "bastio-cli/1.0.0 (darwin; arm64) device/3f2a9c0d1e4b5a67".
+// The device segment is omitted when deviceID is empty.
+func String(deviceID string) string {
+	ua := fmt.Sprintf("bastio-cli/%s (%s; %s)", version, runtime.GOOS, runtime.GOARCH)
+	if deviceID != "" {
+		ua += " device/" + deviceID
+	}
+	return ua
+}
@@ -0,0 +1,289 @@
+// Package workspace detects monorepo workspace definitions and their members
+package workspace
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Kind is the tool that defines a workspace
+type Kind string
+
+const (
+	KindGo    Kind = "go.work"
+	KindPnpm  Kind = "pnpm"
+	KindCargo Kind = "cargo"
+)
+
+// maxMembers bounds how many members are listed for very large workspaces
+const maxMembers = 200
+
+// Workspace is a monorepo root and the sub-projects it declares
+type Workspace struct {
+	Root    string   // Directory holding the workspace definition
+	Kind    Kind     // Tool that defines the workspace
+	Members []Member // Sub-projects, sorted by path
+}
+
+// Member is one sub-project of a workspace
+type Member struct {
+	Name string // Module, package, or crate name; the path when unknown
+	Path string // Path relative to the workspace root
+}
+
+// Current returns the member containing cwd, or nil when cwd is outside
+// every member (e.g. at the workspace root)
+func (w *Workspace) Current(cwd string) *Member {
+	var best *Member
+	for i, m := range w.Members {
+		dir := filepath.Join(w.Root, m.Path)
+		if cwd != dir && !strings.HasPrefix(cwd, dir+string(filepath.Separator)) {
+			continue
+		}
+		// Prefer the deepest member for nested layouts
+		if best == nil || len(m.Path) > len(best.Path) {
+			best = &w.Members[i]
+		}
+	}
+	return best
+}
+
+// Find returns the workspace that cwd belongs to, searching parent
+// directories. Returns nil when cwd is not inside a workspace.
+func Find(cwd string) *Workspace {
+	dir := cwd
+	for {
+		if ws := detect(dir); ws != nil {
+			return ws
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+		dir = parent
+	}
+}
+
+// detect checks dir for a workspace definition
+func detect(dir string) *Workspace {
+	var kind Kind
+	var paths []string
+	switch {
+	case exists(filepath.Join(dir, "go.work")):
+		kind, paths = KindGo, parseGoWork(filepath.Join(dir, "go.work"))
+	case exists(filepath.Join(dir, "pnpm-workspace.yaml")):
+		kind = KindPnpm
+		paths = expandPatterns(dir, parsePnpmWorkspace(filepath.Join(dir, "pnpm-workspace.yaml")), "package.json")
+	case exists(filepath.Join(dir, "Cargo.toml")):
+		patterns, ok := parseCargoWorkspace(filepath.Join(dir, "Cargo.toml"))
+		if !ok {
+			return nil
+		}
+		kind, paths = KindCargo, expandPatterns(dir, patterns, "Cargo.toml")
+	default:
+		return nil
+	}
+
+	ws := &Workspace{Root: dir, Kind: kind}
+	seen := make(map[string]bool)
+	for _, p := range paths {
+		p = filepath.Clean(p)
+		if seen[p] || len(ws.Members) >= maxMembers {
+			continue
+		}
+		seen[p] = true
+		ws.Members = append(ws.Members, Member{Name: memberName(kind, filepath.Join(dir, p), p), Path: p})
+	}
+	sort.Slice(ws.Members, func(i, j int) bool { return ws.Members[i].Path < ws.Members[j].Path })
+	return ws
+}
+
+// parseGoWork returns the directories in go.work use directives, both the
+// single-line and block forms
+func parseGoWork(path string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var dirs []string
+	inBlock := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(stripComment(scanner.Text(), "//"))
+		switch {
+		case inBlock && line == ")":
+			inBlock = false
+		case inBlock && line != "":
+			dirs = append(dirs, strings.Trim(line, `"`))
+		case line == "use (":
+			inBlock = true
+		case strings.HasPrefix(line, "use "):
+			dirs = append(dirs, strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "use ")), `"`))
+		}
+	}
+	return dirs
+}
+
+// parsePnpmWorkspace returns the package globs under "packages:" in
+// pnpm-workspace.yaml. Exclusions (!pattern) are dropped.
+func parsePnpmWorkspace(path string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var patterns []string
+	inPackages := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		raw := stripComment(scanner.Text(), "#")
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(raw, " ") && !strings.HasPrefix(raw, "-") {
+			inPackages = line == "packages:"
+			continue
+		}
+		if !inPackages || !strings.HasPrefix(line, "-") {
+			continue
+		}
+		pattern := strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "-")), `"'`)
+		if pattern != "" && !strings.HasPrefix(pattern, "!") {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+var (
+	cargoSectionRe = regexp.MustCompile(`^\[([^\]]+)\]$`)
+	cargoQuotedRe  = regexp.MustCompile(`"([^"]*)"`)
+)
+
+// parseCargoWorkspace returns the members globs of a Cargo.toml
+// [workspace] section. ok is false when the manifest has no workspace.
+func parseCargoWorkspace(path string) (members []string, ok bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+
+	section := ""
+	inMembers := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(stripComment(scanner.Text(), "#"))
+		if m := cargoSectionRe.FindStringSubmatch(line); m != nil {
+			section = m[1]
+			ok = ok || section == "workspace"
+			inMembers = false
+			continue
+		}
+		if section != "workspace" {
+			continue
+		}
+		if !inMembers {
+			key, value, found := strings.Cut(line, "=")
+			if !found || strings.TrimSpace(key) != "members" {
+				continue
+			}
+			line = value
+			inMembers = true
+		}
+		for _, m := range cargoQuotedRe.FindAllStringSubmatch(line, -1) {
+			members = append(members, m[1])
+		}
+		if strings.Contains(line, "]") {
+			inMembers = false
+		}
+	}
+	return members, ok
+}
+
+// expandPatterns resolves member globs relative to root, keeping only
+// directories that contain manifest
+func expandPatterns(root string, patterns []string, manifest string) []string {
+	var dirs []string
+	for _, pattern := range patterns {
+		// pnpm's "**" matches any depth; one level covers the common layouts
+		pattern = strings.ReplaceAll(pattern, "**", "*")
+		matches, err := filepath.Glob(filepath.Join(root, pattern))
+		if err != nil {
+			continue
+		}
+		for _, match := range matches {
+			if !exists(filepath.Join(match, manifest)) {
+				continue
+			}
+			if rel, err := filepath.Rel(root, match); err == nil {
+				dirs = append(dirs, rel)
+			}
+		}
+	}
+	return dirs
+}
+
+var (
+	goModuleRe  = regexp.MustCompile(`(?m)^module\s+"?([^"\s]+)"?`)
+	cargoNameRe = regexp.MustCompile(`(?m)^name\s*=\s*"([^"]+)"`)
+)
+
+// maxNameBytes bounds how much of a member's manifest is read for its name
+const maxNameBytes = 64 * 1024
+
+// memberName reads the sub-project's own name from its manifest
+func memberName(kind Kind, dir, fallback string) string {
+	switch kind {
+	case KindGo:
+		if m := goModuleRe.FindStringSubmatch(readHead(filepath.Join(dir, "go.mod"))); m != nil {
+			return m[1]
+		}
+	case KindPnpm:
+		var pkg struct {
+			Name string `json:"name"`
+		}
+		if json.Unmarshal([]byte(readHead(filepath.Join(dir, "package.json"))), &pkg) == nil && pkg.Name != "" {
+			return pkg.Name
+		}
+	case KindCargo:
+		if m := cargoNameRe.FindStringSubmatch(readHead(filepath.Join(dir, "Cargo.toml"))); m != nil {
+			return m[1]
+		}
+	}
+	return fallback
+}
+
+// readHead reads up to maxNameBytes of a manifest, or "" on error
+func readHead(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	buf := make([]byte, maxNameBytes)
+	n, _ := f.Read(buf)
+	return string(buf[:n])
+}
+
+// stripComment removes a trailing comment starting with marker
+func stripComment(line, marker string) string {
+	if i := strings.Index(line, marker); i >= 0 {
+		return line[:i]
+	}
+	return line
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
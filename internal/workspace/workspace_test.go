@@ -0,0 +1,131 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFiles creates files (relative path → content) under dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		kind    Kind
+		members []Member
+	}{
+		{
+			name: "go.work",
+			files: map[string]string{
+				"go.work":         "go 1.24\n\nuse (\n\t./api // service\n\t./tools/lint\n)\nuse ./web\n",
+				"api/go.mod":      "module example.com/api\n",
+				"web/go.mod":      "module example.com/web\n",
+				"tools/lint/x.go": "package lint\n",
+			},
+			kind: KindGo,
+			members: []Member{
+				{Name: "example.com/api", Path: "api"},
+				{Name: "tools/lint", Path: "tools/lint"},
+				{Name: "example.com/web", Path: "web"},
+			},
+		},
+		{
+			name: "pnpm",
+			files: map[string]string{
+				"pnpm-workspace.yaml":      "packages:\n  - 'packages/*'\n  - \"apps/*\" # apps\n  - '!packages/legacy'\ncatalog:\n  - ignored\n",
+				"packages/ui/package.json": `{"name": "@acme/ui"}`,
+				"packages/docs/README.md":  "no manifest",
+				"apps/site/package.json":   `{}`,
+			},
+			kind: KindPnpm,
+			members: []Member{
+				{Name: "apps/site", Path: "apps/site"},
+				{Name: "@acme/ui", Path: "packages/ui"},
+			},
+		},
+		{
+			name: "cargo",
+			files: map[string]string{
+				"Cargo.toml":             "[workspace]\nmembers = [\n  \"crates/*\",\n  \"cli\", # binary\n]\n\n[workspace.dependencies]\nserde = \"1\"\n",
+				"crates/core/Cargo.toml": "[package]\nname = \"acme-core\"\n",
+				"cli/Cargo.toml":         "[package]\nname = \"acme\"\n",
+			},
+			kind: KindCargo,
+			members: []Member{
+				{Name: "acme", Path: "cli"},
+				{Name: "acme-core", Path: filepath.Join("crates", "core")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, tt.files)
+
+			// Searching from inside a member finds the root
+			ws := Find(filepath.Join(root, tt.members[0].Path))
+			if ws == nil {
+				t.Fatal("Find() = nil")
+			}
+			if ws.Root != root || ws.Kind != tt.kind {
+				t.Errorf("Find() = %s %s, want %s %s", ws.Kind, ws.Root, tt.kind, root)
+			}
+			for i := range tt.members {
+				tt.members[i].Path = filepath.FromSlash(tt.members[i].Path)
+			}
+			if !reflect.DeepEqual(ws.Members, tt.members) {
+				t.Errorf("Members = %+v, want %+v", ws.Members, tt.members)
+			}
+		})
+	}
+}
+
+func TestFindNoWorkspace(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"Cargo.toml": "[package]\nname = \"solo\"\n"})
+	if ws := Find(root); ws != nil && ws.Root == root {
+		t.Errorf("Find() = %+v, want no workspace at a plain crate", ws)
+	}
+}
+
+func TestCurrent(t *testing.T) {
+	ws := &Workspace{
+		Root: "/repo",
+		Members: []Member{
+			{Name: "api", Path: "api"},
+			{Name: "api-v2", Path: "api-v2"},
+			{Name: "nested", Path: "api/plugins"},
+		},
+	}
+	tests := []struct{ cwd, want string }{
+		{"/repo", ""},
+		{"/repo/api", "api"},
+		{"/repo/api/cmd", "api"},
+		{"/repo/api-v2", "api-v2"},
+		{"/repo/api/plugins/x", "nested"},
+	}
+	for _, tt := range tests {
+		got := ""
+		if m := ws.Current(tt.cwd); m != nil {
+			got = m.Name
+		}
+		if got != tt.want {
+			t.Errorf("Current(%q) = %q, want %q", tt.cwd, got, tt.want)
+		}
+	}
+}